@@ -0,0 +1,2 @@
+-- Expected session length for a block, in minutes --------------------------
+ALTER TABLE program_blocks ADD COLUMN expected_minutes INTEGER;
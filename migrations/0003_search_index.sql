@@ -0,0 +1,9 @@
+-- Full-text search over names, descriptions and notes -----------------------
+-- Rebuilt from the source tables by `lazarus search`, so it never drifts.
+CREATE VIRTUAL TABLE search_index USING fts5(
+    kind  UNINDEXED,                    -- exercise | program | block | program-note | session-note
+    title,
+    body,
+    jump  UNINDEXED,                    -- command that shows the hit
+    tokenize = 'porter unicode61'
+);
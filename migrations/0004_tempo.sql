@@ -0,0 +1,7 @@
+-- Tempo ("3-1-1-0") and per-set pause notation ------------------------------
+ALTER TABLE program_exercises ADD COLUMN tempo TEXT;
+ALTER TABLE program_exercises ADD COLUMN pause TEXT;   -- comma separated, one per set
+
+-- Snapshot of the prescription at the time the set was logged
+ALTER TABLE exercise_sets ADD COLUMN tempo TEXT;
+ALTER TABLE exercise_sets ADD COLUMN pause TEXT;
@@ -0,0 +1,2 @@
+-- AMRAP ("8+") sets, flagged when logged so progression can be tracked -----
+ALTER TABLE exercise_sets ADD COLUMN amrap INTEGER DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Bands and chains (accommodating resistance) ------------------------------
+ALTER TABLE exercise_sets ADD COLUMN band TEXT;           -- band color/name
+ALTER TABLE exercise_sets ADD COLUMN band_tension REAL;   -- estimated kg at lockout
+ALTER TABLE exercise_sets ADD COLUMN chain_weight REAL;   -- kg of chain
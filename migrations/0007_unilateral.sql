@@ -0,0 +1,4 @@
+-- Unilateral exercises ------------------------------------------------------
+ALTER TABLE exercises ADD COLUMN unilateral INTEGER DEFAULT 0;
+-- 'L' / 'R' for one side, NULL when both sides were worked together
+ALTER TABLE exercise_sets ADD COLUMN side TEXT CHECK (side IN ('L', 'R'));
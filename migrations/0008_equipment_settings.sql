@@ -0,0 +1,9 @@
+-- Per-exercise machine settings (seat height, pin position, ...) -------------
+CREATE TABLE equipment_settings (
+    exercise_id TEXT NOT NULL,          -- → exercises.id (uuid)
+    key         TEXT NOT NULL COLLATE NOCASE,
+    value       TEXT NOT NULL,
+    updated_at  TEXT NOT NULL,
+    PRIMARY KEY (exercise_id, key),
+    FOREIGN KEY (exercise_id) REFERENCES exercises(id) ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+-- Gym profiles and exercise equipment --------------------------------------
+ALTER TABLE exercises ADD COLUMN equipment TEXT;           -- barbell, dumbbell, machine, ...
+ALTER TABLE program_exercises ADD COLUMN options TEXT;     -- CSV of alternative exercise names
+
+CREATE TABLE gyms (
+    id            TEXT PRIMARY KEY,
+    name          TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    barbell       INTEGER NOT NULL DEFAULT 1,
+    dumbbells     INTEGER NOT NULL DEFAULT 1,
+    dumbbell_min  REAL,                                    -- kg, NULL = no limit
+    dumbbell_max  REAL,
+    machines      INTEGER NOT NULL DEFAULT 1,
+    cables        INTEGER NOT NULL DEFAULT 1,
+    kettlebells   INTEGER NOT NULL DEFAULT 1,
+    created_at    TEXT NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Macrocycle week a block belongs to (NULL = every week) ----------------------
+ALTER TABLE program_blocks ADD COLUMN week INTEGER;
@@ -0,0 +1,15 @@
+-- Warm-up / back-off set groups ----------------------------------------------
+-- CSV of "<fraction of top set>:<reps>" entries, one per set, e.g. "0.9:5,0.9:5"
+ALTER TABLE program_exercises ADD COLUMN warmup TEXT;
+ALTER TABLE program_exercises ADD COLUMN backoff TEXT;
+
+-- Expanded at session start; weights are resolved against the top set when shown
+CREATE TABLE session_set_targets (
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_exercise_id TEXT NOT NULL,  -- → training_session_exercises.id
+    kind                TEXT NOT NULL CHECK (kind IN ('warmup', 'backoff')),
+    position            INTEGER NOT NULL,
+    percent             REAL NOT NULL,  -- fraction of the top set, e.g. 0.9
+    reps                TEXT,
+    FOREIGN KEY (session_exercise_id) REFERENCES training_session_exercises(id) ON DELETE CASCADE
+);
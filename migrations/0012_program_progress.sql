@@ -0,0 +1,7 @@
+-- Which week of its macrocycle each program is in -----------------------------
+CREATE TABLE program_progress (
+    program_id      TEXT PRIMARY KEY,   -- → programs.id
+    current_week    INTEGER NOT NULL DEFAULT 1,
+    week_started_at TEXT NOT NULL,
+    FOREIGN KEY (program_id) REFERENCES programs(id) ON DELETE CASCADE
+);
@@ -0,0 +1,12 @@
+-- Several sessions may be open at once (e.g. a morning run and an evening
+-- lift); the tag tells them apart. ---------------------------------------------
+ALTER TABLE training_sessions ADD COLUMN tag TEXT;
+
+-- With more than one open session, "current" is the most recently started.
+DROP VIEW current_session;
+CREATE VIEW current_session AS
+SELECT *
+FROM training_sessions
+WHERE end_time IS NULL
+ORDER BY start_time DESC
+LIMIT 1;
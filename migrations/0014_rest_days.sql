@@ -0,0 +1,9 @@
+-- Intentional rest: a week with a rest day doesn't break the week streak. ------
+CREATE TABLE rest_days (
+    date        TEXT PRIMARY KEY,      -- YYYY-MM-DD
+    reason      TEXT,
+    created_at  TEXT NOT NULL
+);
+
+-- Program weeks that are planned off (CSV of week numbers, e.g. "4,8").
+ALTER TABLE programs ADD COLUMN off_weeks TEXT;
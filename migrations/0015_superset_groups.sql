@@ -0,0 +1,6 @@
+-- Named superset groups ("A", "B", ...) replace the numeric technique_group.
+ALTER TABLE program_exercises ADD COLUMN superset TEXT;
+
+UPDATE program_exercises
+SET superset = char(64 + technique_group)
+WHERE technique_group BETWEEN 1 AND 26;
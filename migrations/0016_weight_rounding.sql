@@ -0,0 +1,2 @@
+-- Per-exercise rounding profile for calculated weights ("1kg", "lb", ...).
+ALTER TABLE exercises ADD COLUMN rounding TEXT;
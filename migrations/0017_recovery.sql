@@ -0,0 +1,8 @@
+-- Daily sleep and HRV imported from wearables (Fitbit, Oura). ----------------
+CREATE TABLE recovery (
+    date           TEXT PRIMARY KEY,   -- YYYY-MM-DD, the morning the night ended
+    sleep_minutes  INTEGER,
+    hrv            REAL,               -- rMSSD in ms
+    source         TEXT,
+    imported_at    TEXT NOT NULL
+);
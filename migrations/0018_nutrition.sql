@@ -0,0 +1,7 @@
+-- Daily calorie/protein totals; just the numbers, no meal tracking. ----------
+CREATE TABLE nutrition (
+    date        TEXT PRIMARY KEY,      -- YYYY-MM-DD
+    calories    INTEGER NOT NULL,
+    protein     REAL,                  -- grams
+    updated_at  TEXT NOT NULL
+);
@@ -0,0 +1,10 @@
+-- Strength goals, e.g. "Squat 180x1 by 2025-12-01". --------------------------
+CREATE TABLE goals (
+    id           TEXT PRIMARY KEY,
+    exercise_id  TEXT NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    weight       REAL NOT NULL,
+    reps         INTEGER NOT NULL,
+    target_date  TEXT NOT NULL,        -- YYYY-MM-DD
+    created_at   TEXT NOT NULL,
+    done_at      TEXT
+);
@@ -0,0 +1,7 @@
+-- Paused stretches of a session; they don't count toward its duration. -------
+CREATE TABLE session_pauses (
+    training_session_id  TEXT NOT NULL REFERENCES training_sessions(id) ON DELETE CASCADE,
+    paused_at            TEXT NOT NULL,
+    resumed_at           TEXT,              -- NULL while paused
+    PRIMARY KEY (training_session_id, paused_at)
+);
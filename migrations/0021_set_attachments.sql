@@ -0,0 +1,8 @@
+-- Photos/videos attached to a logged set. Files are copied into the media dir;
+-- `path` is where the copy lives. ----------------------------------------------
+CREATE TABLE set_attachments (
+    exercise_set_id  TEXT NOT NULL REFERENCES exercise_sets(id) ON DELETE CASCADE,
+    path             TEXT NOT NULL,
+    created_at       TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (exercise_set_id, path)
+);
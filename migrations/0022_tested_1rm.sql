@@ -0,0 +1,2 @@
+-- Records from an actual max test (`test-1rm`) rather than an estimate. -------
+ALTER TABLE personal_records ADD COLUMN verified INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+-- Body circumferences in cm, one reading per site per day. --------------------
+CREATE TABLE measurements (
+    date        TEXT NOT NULL,         -- YYYY-MM-DD
+    site        TEXT NOT NULL,         -- waist, hips, chest, arm, thigh, calf, neck
+    value       REAL NOT NULL,
+    updated_at  TEXT NOT NULL,
+    PRIMARY KEY (date, site)
+);
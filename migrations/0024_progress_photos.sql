@@ -0,0 +1,10 @@
+-- Progress photos; files live in the media dir, this is just the index. -------
+CREATE TABLE progress_photos (
+    id          TEXT PRIMARY KEY,
+    date        TEXT NOT NULL,         -- YYYY-MM-DD
+    tag         TEXT NOT NULL,         -- front, side, back, ...
+    path        TEXT NOT NULL,
+    created_at  TEXT NOT NULL
+);
+
+CREATE INDEX idx_progress_photos_tag_date ON progress_photos(tag, date);
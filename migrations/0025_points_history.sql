@@ -0,0 +1,9 @@
+-- Daily snapshot of the squat/bench/deadlift total and its points. ------------
+CREATE TABLE points_history (
+    date        TEXT PRIMARY KEY,      -- YYYY-MM-DD
+    bodyweight  REAL NOT NULL,
+    total       REAL NOT NULL,
+    wilks       REAL NOT NULL,
+    dots        REAL NOT NULL,
+    ipf_gl      REAL NOT NULL
+);
@@ -0,0 +1,4 @@
+-- Rotating through an exercise's options every few weeks. -------------------
+ALTER TABLE program_exercises ADD COLUMN rotate_weeks INTEGER;          -- NULL = no rotation
+ALTER TABLE training_session_exercises ADD COLUMN rotated_from TEXT     -- program exercise the variation stands in for
+    REFERENCES exercises(id);
@@ -0,0 +1,2 @@
+-- Prescribed rest between sets. ----------------------------------------------
+ALTER TABLE program_exercises ADD COLUMN rest_secs INTEGER;   -- NULL = not prescribed
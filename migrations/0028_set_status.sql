@@ -0,0 +1,2 @@
+-- How a logged set went. Unlogged (pending) sets have no row. ----------------
+ALTER TABLE exercise_sets ADD COLUMN status TEXT NOT NULL DEFAULT 'completed';   -- completed, failed or skipped
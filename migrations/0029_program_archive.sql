@@ -0,0 +1,2 @@
+-- Finished programs are archived, not deleted, so their history stays. ------
+ALTER TABLE programs ADD COLUMN archived_at TEXT;   -- NULL while the program is active
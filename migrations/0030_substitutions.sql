@@ -0,0 +1,11 @@
+-- Program-wide substitution groups ("horizontal press": bench, DB bench, …). --
+CREATE TABLE program_substitutions (
+    program_id  TEXT NOT NULL,          -- → programs.id
+    group_name  TEXT NOT NULL,
+    exercises   TEXT NOT NULL,          -- CSV of exercise names
+    PRIMARY KEY (program_id, group_name),
+    FOREIGN KEY (program_id) REFERENCES programs(id) ON DELETE CASCADE
+);
+
+-- Session exercises swapped in by hand (rotated_from holds what they replace).
+ALTER TABLE training_session_exercises ADD COLUMN swapped INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+-- Free-form labels on finished sessions ("cut", "home-gym"). Unrelated to ---
+-- training_sessions.tag, which only tells open sessions apart.
+CREATE TABLE session_tags (
+    training_session_id TEXT NOT NULL,  -- → training_sessions.id
+    tag                 TEXT NOT NULL COLLATE NOCASE,
+    PRIMARY KEY (training_session_id, tag),
+    FOREIGN KEY (training_session_id) REFERENCES training_sessions(id) ON DELETE CASCADE
+);
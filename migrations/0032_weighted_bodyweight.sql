@@ -0,0 +1,4 @@
+-- Weighted bodyweight sets (dips, pull-ups with a belt). ----------------------
+-- On a bodyweight set `weight` is the added load (0 for plain bodyweight) and
+-- body_mass the bodyweight it was done at, so the total load can be counted.
+ALTER TABLE exercise_sets ADD COLUMN body_mass REAL;                 -- kg, NULL when unknown
@@ -0,0 +1,3 @@
+-- Accessory pools: a block picks `pool_choose` of its pool exercises per session.
+ALTER TABLE program_blocks ADD COLUMN pool_choose INTEGER;                 -- NULL = no pool
+ALTER TABLE program_exercises ADD COLUMN pool INTEGER NOT NULL DEFAULT 0;   -- 1 = in the block's accessory pool
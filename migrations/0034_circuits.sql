@@ -0,0 +1,36 @@
+-- EMOMs and circuits: movements done as timed rounds. -------------------------
+CREATE TABLE program_circuits (
+    id               TEXT PRIMARY KEY,
+    program_block_id TEXT NOT NULL,      -- → program_blocks.id
+    position         INTEGER NOT NULL,   -- 1-based, as in `session circuit <n>`
+    kind             TEXT NOT NULL CHECK (kind IN ('emom', 'circuit')),
+    rounds           INTEGER NOT NULL,
+    interval_secs    INTEGER,            -- EMOM: length of a round; circuit: rest between rounds
+    FOREIGN KEY (program_block_id) REFERENCES program_blocks(id) ON DELETE CASCADE
+);
+
+CREATE TABLE program_circuit_movements (
+    circuit_id  TEXT NOT NULL,           -- → program_circuits.id
+    position    INTEGER NOT NULL,
+    exercise_id TEXT NOT NULL,           -- → exercises.id
+    reps        INTEGER NOT NULL,
+    weight      REAL,                    -- NULL = bodyweight
+    PRIMARY KEY (circuit_id, position),
+    FOREIGN KEY (circuit_id)  REFERENCES program_circuits(id) ON DELETE CASCADE,
+    FOREIGN KEY (exercise_id) REFERENCES exercises(id)
+);
+
+-- Rounds done in a session; their sets point back here. circuit_id has no
+-- foreign key so the history outlives a program re-import.
+CREATE TABLE circuit_rounds (
+    id                  TEXT PRIMARY KEY,
+    training_session_id TEXT NOT NULL,   -- → training_sessions.id
+    circuit_id          TEXT NOT NULL,   -- program_circuits.id at the time
+    round               INTEGER NOT NULL,
+    started_at          TEXT NOT NULL,
+    finished_at         TEXT NOT NULL,
+    UNIQUE (training_session_id, circuit_id, round),
+    FOREIGN KEY (training_session_id) REFERENCES training_sessions(id) ON DELETE CASCADE
+);
+
+ALTER TABLE exercise_sets ADD COLUMN circuit_round_id TEXT;   -- → circuit_rounds.id, NULL for straight sets
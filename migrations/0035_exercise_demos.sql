@@ -0,0 +1,3 @@
+-- Demo images and coaching cues for `exercise show --image`. -----------------
+ALTER TABLE exercises ADD COLUMN demo_path TEXT;   -- copy in the media folder
+ALTER TABLE exercises ADD COLUMN cues TEXT;        -- ';'-separated, shown when the image can't be
@@ -0,0 +1,48 @@
+-- Workout order of a session's exercises and of the sets within each. -------
+-- Both used to be implied by rowid and timestamp, which a VACUUM, a restore or
+-- two sets logged in the same second could shuffle.
+ALTER TABLE training_session_exercises ADD COLUMN order_index INTEGER;  -- 0-based, `move-ex` rewrites it
+ALTER TABLE exercise_sets ADD COLUMN set_index INTEGER;                 -- 0-based within the session exercise, both sides
+
+UPDATE training_session_exercises
+SET order_index = (
+    SELECT COUNT(*) FROM training_session_exercises o
+    WHERE o.training_session_id = training_session_exercises.training_session_id
+    AND o.rowid < training_session_exercises.rowid
+);
+
+UPDATE exercise_sets
+SET set_index = (
+    SELECT COUNT(*) FROM exercise_sets o
+    WHERE o.session_exercise_id = exercise_sets.session_exercise_id
+    AND (o.timestamp < exercise_sets.timestamp
+         OR (o.timestamp = exercise_sets.timestamp AND o.rowid < exercise_sets.rowid))
+);
+
+-- Rows inserted without a position go after the ones already there.
+CREATE TRIGGER training_session_exercises_order_index
+AFTER INSERT ON training_session_exercises
+WHEN NEW.order_index IS NULL
+BEGIN
+    UPDATE training_session_exercises
+    SET order_index = (
+        SELECT COALESCE(MAX(order_index), -1) + 1 FROM training_session_exercises
+        WHERE training_session_id = NEW.training_session_id AND id != NEW.id
+    )
+    WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER exercise_sets_set_index
+AFTER INSERT ON exercise_sets
+WHEN NEW.set_index IS NULL
+BEGIN
+    UPDATE exercise_sets
+    SET set_index = (
+        SELECT COALESCE(MAX(set_index), -1) + 1 FROM exercise_sets
+        WHERE session_exercise_id = NEW.session_exercise_id AND id != NEW.id
+    )
+    WHERE id = NEW.id;
+END;
+
+CREATE INDEX idx_training_session_exercises_order ON training_session_exercises(training_session_id, order_index);
+CREATE INDEX idx_exercise_sets_order ON exercise_sets(session_exercise_id, set_index);
@@ -0,0 +1,9 @@
+-- Pre-session checklist (`checklist` config key) and what was ticked off with
+-- `session start --check`, so a PR can be told apart as belted or not. ------
+CREATE TABLE session_checklist (
+    training_session_id TEXT NOT NULL,     -- → training_sessions.id
+    item                TEXT NOT NULL COLLATE NOCASE,
+    checked             INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (training_session_id, item),
+    FOREIGN KEY (training_session_id) REFERENCES training_sessions(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+-- Gear worn for a set (`session edit --belt --straps`), comma separated in
+-- SET_FLAGS order, e.g. "belt,straps". NULL = none recorded. ----------------
+ALTER TABLE exercise_sets ADD COLUMN flags TEXT;
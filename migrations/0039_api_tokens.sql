@@ -0,0 +1,10 @@
+-- Bearer tokens for the HTTP server (`lazarus token create`). Only a SHA-256 of
+-- each token is kept; the token itself is shown once, when it's created. ----
+CREATE TABLE api_tokens (
+    id           TEXT PRIMARY KEY,
+    name         TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    token_hash   TEXT NOT NULL UNIQUE,                       -- hex SHA-256
+    scope        TEXT NOT NULL CHECK (scope IN ('read', 'write')),
+    created_at   TEXT NOT NULL,
+    last_used_at TEXT                                        -- NULL until first used
+);
@@ -0,0 +1,84 @@
+-- What a program prescribed before it changed (`p add-ex`, `p rm-ex`, a new
+-- training max...), so a past session's log shows the targets it was trained
+-- against. Only blocks that already have sessions keep history. -------------
+ALTER TABLE program_exercises ADD COLUMN valid_from TEXT;   -- NULL = since the block was created
+
+CREATE TABLE program_exercise_versions (
+    program_exercise_id TEXT NOT NULL,       -- → program_exercises.id (may be gone)
+    program_block_id    TEXT NOT NULL,       -- → program_blocks.id
+    exercise_id         TEXT NOT NULL,       -- → exercises.id
+    sets                INTEGER NOT NULL,
+    reps                TEXT,
+    target_rpe          TEXT,
+    target_rm_percent   TEXT,
+    notes               TEXT,
+    program_1rm         REAL,
+    technique           TEXT,
+    technique_group     INTEGER,
+    order_index         INTEGER,
+    tempo               TEXT,
+    pause               TEXT,
+    options             TEXT,
+    warmup              TEXT,
+    backoff             TEXT,
+    superset            TEXT,
+    rotate_weeks        INTEGER,
+    rest_secs           INTEGER,
+    pool                INTEGER NOT NULL DEFAULT 0,
+    valid_from          TEXT,                -- NULL = since the block was created
+    valid_to            TEXT NOT NULL,       -- when it was changed or removed
+    PRIMARY KEY (program_exercise_id, valid_to)
+);
+
+-- A row added to a block that was already trained didn't apply to the
+-- sessions before it.
+CREATE TRIGGER program_exercises_valid_from
+AFTER INSERT ON program_exercises
+WHEN NEW.valid_from IS NULL
+AND EXISTS (SELECT 1 FROM training_sessions WHERE program_block_id = NEW.program_block_id)
+BEGIN
+    UPDATE program_exercises SET valid_from = datetime('now') WHERE id = NEW.id;
+END;
+
+-- Reordering doesn't change a prescription, so `order_index` isn't watched.
+-- Two changes in the same second keep the version from before the first.
+CREATE TRIGGER program_exercises_version_update
+AFTER UPDATE OF exercise_id, sets, reps, target_rpe, target_rm_percent, notes, program_1rm, technique,
+    technique_group, tempo, pause, options, warmup, backoff, superset, rotate_weeks, rest_secs, pool
+ON program_exercises
+WHEN EXISTS (SELECT 1 FROM training_sessions WHERE program_block_id = OLD.program_block_id)
+BEGIN
+    INSERT OR IGNORE INTO program_exercise_versions
+    VALUES (OLD.id, OLD.program_block_id, OLD.exercise_id, OLD.sets, OLD.reps, OLD.target_rpe, OLD.target_rm_percent,
+            OLD.notes, OLD.program_1rm, OLD.technique, OLD.technique_group, OLD.order_index, OLD.tempo, OLD.pause,
+            OLD.options, OLD.warmup, OLD.backoff, OLD.superset, OLD.rotate_weeks, OLD.rest_secs, OLD.pool,
+            OLD.valid_from, datetime('now'));
+    UPDATE program_exercises SET valid_from = datetime('now') WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER program_exercises_version_delete
+AFTER DELETE ON program_exercises
+WHEN EXISTS (SELECT 1 FROM training_sessions WHERE program_block_id = OLD.program_block_id)
+BEGIN
+    INSERT OR IGNORE INTO program_exercise_versions
+    VALUES (OLD.id, OLD.program_block_id, OLD.exercise_id, OLD.sets, OLD.reps, OLD.target_rpe, OLD.target_rm_percent,
+            OLD.notes, OLD.program_1rm, OLD.technique, OLD.technique_group, OLD.order_index, OLD.tempo, OLD.pause,
+            OLD.options, OLD.warmup, OLD.backoff, OLD.superset, OLD.rotate_weeks, OLD.rest_secs, OLD.pool,
+            OLD.valid_from, datetime('now'));
+END;
+
+-- Each session's block prescription as it stood when the session started.
+CREATE VIEW session_program_exercises AS
+SELECT ts.id AS training_session_id, pe.program_block_id, pe.exercise_id, pe.sets, pe.reps, pe.target_rpe,
+       pe.target_rm_percent, pe.notes, pe.program_1rm, pe.technique, pe.technique_group, pe.order_index,
+       pe.tempo, pe.pause, pe.options, pe.warmup, pe.backoff, pe.superset, pe.rotate_weeks, pe.rest_secs, pe.pool
+FROM training_sessions ts
+JOIN program_exercises pe ON pe.program_block_id = ts.program_block_id
+WHERE pe.valid_from IS NULL OR pe.valid_from <= ts.start_time
+UNION ALL
+SELECT ts.id, v.program_block_id, v.exercise_id, v.sets, v.reps, v.target_rpe,
+       v.target_rm_percent, v.notes, v.program_1rm, v.technique, v.technique_group, v.order_index,
+       v.tempo, v.pause, v.options, v.warmup, v.backoff, v.superset, v.rotate_weeks, v.rest_secs, v.pool
+FROM training_sessions ts
+JOIN program_exercise_versions v ON v.program_block_id = ts.program_block_id
+WHERE (v.valid_from IS NULL OR v.valid_from <= ts.start_time) AND v.valid_to > ts.start_time;
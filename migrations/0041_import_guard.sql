@@ -0,0 +1,17 @@
+-- `db import`/`db merge` write `valid_from` as it was exported; an imported
+-- row is not a program edit, even when its block already has sessions.
+-- Both hold the row below for the length of their transaction. ----------------
+CREATE TABLE import_in_progress (
+    id INTEGER PRIMARY KEY CHECK (id = 1)
+);
+
+DROP TRIGGER program_exercises_valid_from;
+
+CREATE TRIGGER program_exercises_valid_from
+AFTER INSERT ON program_exercises
+WHEN NEW.valid_from IS NULL
+AND NOT EXISTS (SELECT 1 FROM import_in_progress)
+AND EXISTS (SELECT 1 FROM training_sessions WHERE program_block_id = NEW.program_block_id)
+BEGIN
+    UPDATE program_exercises SET valid_from = datetime('now') WHERE id = NEW.id;
+END;
@@ -0,0 +1,19 @@
+-- Pauses were keyed by (session, paused_at), and paused_at only has seconds:
+-- pausing again within a second of resuming hit the key. Each pause now has
+-- its own id. ----------------------------------------------------------------
+CREATE TABLE session_pauses_new (
+    id                   INTEGER PRIMARY KEY,
+    training_session_id  TEXT NOT NULL REFERENCES training_sessions(id) ON DELETE CASCADE,
+    paused_at            TEXT NOT NULL,
+    resumed_at           TEXT               -- NULL while paused
+);
+
+INSERT INTO session_pauses_new (training_session_id, paused_at, resumed_at)
+SELECT training_session_id, paused_at, resumed_at
+FROM session_pauses
+ORDER BY training_session_id, paused_at;
+
+DROP TABLE session_pauses;
+ALTER TABLE session_pauses_new RENAME TO session_pauses;
+
+CREATE INDEX idx_session_pauses_session ON session_pauses(training_session_id, paused_at);
@@ -1,22 +1,75 @@
-use clap::{Args, Parser, Subcommand};
+use clap::{Args, Parser, Subcommand, ValueEnum};
 
 #[derive(Parser)]
 #[command(name = "lazarus", version, about = "CLI training app")]
-#[command(arg_required_else_help = true)]
 pub struct Cli {
     /// Emit machine-readable JSON instead of colorful text.
     #[arg(global = true, long)]
     pub json: bool,
 
+    /// Disable colors (same as setting NO_COLOR or `color = false`).
+    #[arg(global = true, long)]
+    pub no_color: bool,
+
+    /// Drop `ok:`/`info:` chatter; data, warnings and errors still print.
+    #[arg(global = true, long, short)]
+    pub quiet: bool,
+
+    /// Without a command, show today's dashboard.
     #[command(subcommand)]
-    pub cmd: Commands,
+    pub cmd: Option<Commands>,
 }
 
 #[derive(Subcommand)]
 pub enum Commands {
+    /// Dashboard: open session, next block, last session, streak and goals
+    #[command(visible_alias = "t")]
+    Today,
+
     /// Session-scoped commands
-    #[command(subcommand, visible_alias = "s")]
-    Session(SessionCmd),
+    #[command(visible_alias = "s")]
+    Session(SessionArgs),
+
+    /// Pause the current session's clock (same as `session pause`)
+    Pause {
+        /// Tag of the active session to use when more than one is open
+        #[arg(long)]
+        session: Option<String>,
+    },
+
+    /// Resume a paused session (same as `session resume`)
+    Resume {
+        /// Tag of the active session to use when more than one is open
+        #[arg(long)]
+        session: Option<String>,
+    },
+
+    /// Move an exercise of the current session (same as `session move-ex`)
+    MoveEx {
+        /// 1-based index of the exercise (same order shown in `session show`)
+        exercise: usize,
+
+        /// New 1-based position
+        to: usize,
+
+        /// Tag of the active session to use when more than one is open
+        #[arg(long)]
+        session: Option<String>,
+    },
+
+    /// Drop an exercise from the current session (same as `session remove-ex`)
+    RemoveEx {
+        /// 1-based index of the exercise (same order shown in `session show`)
+        exercise: usize,
+
+        /// Also delete the sets already logged for it
+        #[arg(long, short)]
+        force: bool,
+
+        /// Tag of the active session to use when more than one is open
+        #[arg(long)]
+        session: Option<String>,
+    },
 
     /// Exercise management
     #[command(subcommand, visible_alias = "ex")]
@@ -26,10 +79,18 @@ pub enum Commands {
     #[command(subcommand)]
     Config(ConfigCmd),
 
+    /// Guided first run: write the config, create the database, add starter exercises and a first program
+    Setup,
+
     /// Program management
     #[command(subcommand, visible_alias = "p")]
     Program(ProgramCmd),
 
+    /// Look at a session, a program or an exercise (`session show`, `session log`,
+    /// `program show` and `exercise show` keep working)
+    #[command(subcommand)]
+    Show(ShowCmd),
+
     /// Show training sessions in a calendar view
     #[command(visible_alias = "cal")]
     Calendar {
@@ -40,6 +101,22 @@ pub enum Commands {
         /// Month to show (1-12, defaults to current month)
         #[arg(short, long)]
         month: Option<u32>,
+
+        /// Show the month of this date instead, e.g. "3 weeks ago" or "last month"
+        #[arg(long, conflicts_with_all = ["year", "month"])]
+        date: Option<String>,
+
+        /// Only sessions with this tag (from `session end --tag`)
+        #[arg(long)]
+        tag: Option<String>,
+
+        /// Show the whole year as a heatmap instead of a month
+        #[arg(long)]
+        heatmap: bool,
+
+        /// Write the calendar to an image (.svg, or .png with rsvg-convert or ImageMagick installed)
+        #[arg(long, value_name = "FILE")]
+        export: Option<String>,
     },
 
     /// Show global progression and training status
@@ -48,18 +125,542 @@ pub enum Commands {
         #[arg(short, long)]
         muscle: Option<String>,
 
-        /// Time period in weeks (defaults to 12)
-        #[arg(short, long, default_value = "12")]
+        /// Time period in weeks, or a span like 30d, 3m, 1y (defaults to 12)
+        #[arg(short, long, default_value = "12", value_parser = crate::dates::parse_weeks)]
         weeks: u32,
 
         /// Show graph instead of summary
         #[arg(short, long)]
         graph: bool,
+
+        /// Count sets per muscle over the last 7 days instead of since Monday
+        #[arg(long)]
+        rolling: bool,
     },
 
     /// Db operations
     #[command(subcommand)]
     Db(DbCmd),
+
+    /// Gym profiles (which equipment a gym has)
+    #[command(subcommand)]
+    Gym(GymCmd),
+
+    /// Strength goals and how they're coming along
+    #[command(subcommand)]
+    Goal(GoalCmd),
+
+    /// Dated progress photos
+    #[command(subcommand)]
+    Photo(PhotoCmd),
+
+    /// Check the database for broken references and stale sessions; exits 3 while problems are left
+    Doctor {
+        /// Delete orphaned rows and close stale sessions
+        #[arg(long)]
+        repair: bool,
+    },
+
+    /// Full-text search over exercises, programs and notes
+    Search {
+        /// Words to look for
+        #[arg(required = true)]
+        query: Vec<String>,
+
+        /// Maximum number of hits
+        #[arg(short, long, default_value = "20")]
+        limit: u32,
+    },
+
+    /// Compare two sessions set by set (defaults to the latest vs the previous of its block)
+    CompareSessions {
+        /// Older session id (or unique prefix); alone, it is compared with its block's previous session
+        first: Option<String>,
+
+        /// Newer session id (or unique prefix)
+        second: Option<String>,
+    },
+
+    /// Short text summary of a session for chat apps (defaults to the latest finished one)
+    Share {
+        /// Session id (or unique prefix)
+        session: Option<String>,
+
+        /// Also copy it to the clipboard
+        #[arg(long, short)]
+        clipboard: bool,
+    },
+
+    /// Year-in-review statistics
+    Wrapped {
+        /// Year to review (defaults to the current year)
+        year: Option<i32>,
+
+        /// Print as Markdown (easy to paste or screenshot)
+        #[arg(long)]
+        markdown: bool,
+    },
+
+    /// Mark a planned rest day (or week) so it doesn't break the week streak
+    RestDay {
+        /// Date as YYYY-MM-DD, DD-MM-YYYY, DD/MM/YY or e.g. "yesterday", "last friday" (defaults to today)
+        date: Option<String>,
+
+        /// Why (deload, vacation, sick, ...)
+        #[arg(long)]
+        reason: Option<String>,
+
+        /// Mark the whole week the date falls in
+        #[arg(long)]
+        week: bool,
+
+        /// Remove the rest day(s) instead
+        #[arg(long, conflicts_with = "reason")]
+        remove: bool,
+
+        /// List rest days of the last 30 days and upcoming ones
+        #[arg(long, conflicts_with_all = ["date", "reason", "week", "remove"])]
+        list: bool,
+    },
+
+    /// Log a day's calories (and protein); logging again replaces the day
+    LogCal {
+        /// Total kcal for the day
+        calories: u32,
+
+        /// Protein in grams
+        #[arg(long)]
+        protein: Option<f32>,
+
+        /// Date as YYYY-MM-DD, DD-MM-YYYY, DD/MM/YY or e.g. "yesterday", "last friday" (defaults to today)
+        #[arg(long)]
+        date: Option<String>,
+    },
+
+    /// Log body measurements in cm; without any, show recent readings
+    Measure {
+        #[arg(long)]
+        waist: Option<f32>,
+        #[arg(long)]
+        hips: Option<f32>,
+        #[arg(long)]
+        chest: Option<f32>,
+        #[arg(long)]
+        arm: Option<f32>,
+        #[arg(long)]
+        thigh: Option<f32>,
+        #[arg(long)]
+        calf: Option<f32>,
+        #[arg(long)]
+        neck: Option<f32>,
+
+        /// Date as YYYY-MM-DD, DD-MM-YYYY, DD/MM/YY or e.g. "yesterday", "last friday" (defaults to today)
+        #[arg(long)]
+        date: Option<String>,
+
+        /// Weeks of history to show, or a span like 30d, 3m (defaults to 12)
+        #[arg(short, long, default_value = "12", value_parser = crate::dates::parse_weeks)]
+        weeks: u32,
+    },
+
+    /// Import a training log kept in a spreadsheet (.csv), one set or set group per row
+    ImportCsv {
+        /// Path to the .csv file
+        file: String,
+
+        /// Columns as field=column, e.g. "date=A,exercise=B,weight=D,reps=E" (asked interactively when omitted).
+        /// Fields: date, exercise, weight, reps, sets, rpe, notes, muscle
+        #[arg(long)]
+        map: Option<String>,
+
+        /// chrono format of the date column, e.g. "%m/%d/%Y" (ISO and day-first dates work without it)
+        #[arg(long)]
+        date_format: Option<String>,
+    },
+
+    /// Import daily sleep and HRV from a Fitbit .csv or Oura .json export
+    ImportRecovery {
+        /// Path to the export
+        file: String,
+    },
+
+    /// Compare squat/bench/deadlift/press e1RMs to strength standards (needs `bodyweight` and `sex` in config)
+    Standards,
+
+    /// Wilks, DOTS and IPF GL points from current squat/bench/deadlift e1RMs
+    Points {
+        /// Bodyweight in kg (defaults to `bodyweight` from config)
+        #[arg(long)]
+        bw: Option<f32>,
+    },
+
+    /// Plan a 1RM test ramp, or record its result with --result
+    #[command(name = "test-1rm")]
+    Test1rm {
+        /// Exercise name or index
+        exercise: String,
+
+        /// Heaviest single completed; records it as a tested 1RM
+        #[arg(long, short = 'r')]
+        result: Option<f32>,
+
+        /// Plan around this max (kg) instead of the current e1RM
+        #[arg(long)]
+        from: Option<f32>,
+
+        /// Training max as a percentage of the tested 1RM
+        #[arg(long, default_value = "100")]
+        tm: f32,
+    },
+
+    /// How often each exercise and muscle was trained per week, across all programs
+    Freq {
+        /// Window to look at: weeks ("12w") or days ("30d")
+        #[arg(long, default_value = "12w")]
+        last: String,
+    },
+
+    /// Weekly set range per muscle after which its e1RMs climbed fastest, and the weeks beyond it
+    VolumeLandmarks {
+        /// Weeks of history to look at, or a span like 90d, 6m, 1y
+        #[arg(short, long, default_value = "52", value_parser = crate::dates::parse_weeks)]
+        weeks: u32,
+
+        /// Only this muscle
+        #[arg(long)]
+        muscle: Option<String>,
+    },
+
+    /// Every e1RM record of an exercise with the gain and days between them
+    PrTimeline {
+        /// Exercise name or index
+        exercise: String,
+
+        /// Only sets done with this gear (belt, straps, sleeves, wraps)
+        #[arg(long, value_name = "FLAG")]
+        with: Option<String>,
+
+        /// Only sets done without this gear, e.g. `--without belt` for beltless records
+        #[arg(long, value_name = "FLAG", conflicts_with = "with")]
+        without: Option<String>,
+    },
+
+    /// RPE × reps table of loads from an exercise's e1RM (or training max)
+    RpeChart {
+        /// Exercise name or index
+        exercise: String,
+
+        /// Use the program's training max instead of the e1RM
+        #[arg(long)]
+        tm: bool,
+
+        /// Build the table off this max (kg)
+        #[arg(long)]
+        from: Option<f32>,
+    },
+
+    /// Attach a photo or video to a logged set
+    #[command(override_usage = "attach <EXERCISE> --set <SET> --file <FILE>")]
+    Attach {
+        /// Exercise index in the session (same order shown in `session show`)
+        exercise: usize,
+
+        /// Set number of that exercise
+        #[arg(long, short = 's')]
+        set: usize,
+
+        /// File to copy into the media directory
+        #[arg(long, short = 'f')]
+        file: String,
+
+        /// Tag of the active session to use when more than one is open
+        #[arg(long)]
+        session: Option<String>,
+
+        /// Attach to the session finished on this date instead of the open one
+        #[arg(long, short = 'd')]
+        date: Option<String>,
+    },
+
+    /// Export a month of sessions as a Markdown or HTML training journal
+    ExportLog {
+        /// Month as YYYY-MM, or a date in it such as "last month" (defaults to the current month)
+        #[arg(long)]
+        month: Option<String>,
+
+        /// Output format
+        #[arg(long, value_enum, default_value = "md")]
+        format: LogFormat,
+
+        /// Write to this file instead of stdout
+        #[arg(short, long)]
+        out: Option<String>,
+
+        /// Only sessions with this tag (from `session end --tag`)
+        #[arg(long)]
+        tag: Option<String>,
+    },
+
+    /// Bundle the last weeks for a remote coach: training log, sets CSV, program TOML and compliance in one .zip
+    CoachExport {
+        /// Weeks to include, or a span like 30d or 1m
+        #[arg(long, default_value = "4w", value_parser = crate::dates::parse_weeks)]
+        last: u32,
+
+        /// Archive to write (defaults to coach-review-<date>.zip)
+        #[arg(short, long)]
+        out: Option<String>,
+    },
+
+    /// Printable logging sheet for a block: targets per set with blank columns to fill in
+    Sheet {
+        /// Program index (from `p list`) or name
+        #[arg(long)]
+        program: String,
+
+        /// Block index (as in `p show`) or name
+        #[arg(long)]
+        block: String,
+
+        /// Write to this .pdf or .md file instead of printing Markdown
+        #[arg(short, long)]
+        out: Option<String>,
+    },
+
+    /// Notify at a set time when no session was started that day
+    Remind {
+        /// Time of day, e.g. 18:00
+        #[arg(long)]
+        at: Option<String>,
+
+        /// Days to check, e.g. mon,wed,fri (default: every day)
+        #[arg(long)]
+        days: Option<String>,
+
+        /// Uninstall the reminder
+        #[arg(long, conflicts_with_all = ["at", "days"])]
+        remove: bool,
+
+        /// Check now and notify if needed (what the installed timer runs)
+        #[arg(long, hide = true)]
+        check: bool,
+    },
+
+    /// Serve JSON-RPC on a unix socket for companion apps
+    Daemon {
+        /// Socket path (default: $XDG_RUNTIME_DIR/lazarus.sock)
+        #[arg(long)]
+        socket: Option<String>,
+    },
+
+    /// Serve Prometheus/OpenMetrics metrics (and JSON-RPC, with a token) over HTTP
+    Metrics {
+        /// Address to listen on; a bare port like ":9104" is loopback only, use e.g. "0.0.0.0:9104" for the LAN
+        #[arg(long, default_value = ":9104")]
+        listen: String,
+    },
+
+    /// API tokens for the HTTP server
+    #[command(subcommand)]
+    Token(TokenCmd),
+
+    /// Propose a deload or a variation for stalled lifts
+    Suggest {
+        /// Weeks without an e1RM PR that count as a stall (default: `stall_weeks`, 6)
+        #[arg(short, long)]
+        weeks: Option<u32>,
+    },
+
+    /// Compare performance and volume by time of day or day of week
+    Analyze {
+        /// How to split sessions
+        #[arg(long, value_enum, default_value = "hour")]
+        by: AnalyzeBy,
+
+        /// Weeks of sessions to look at, or a span like 90d, 6m, 1y
+        #[arg(short, long, default_value = "26", value_parser = crate::dates::parse_weeks)]
+        weeks: u32,
+
+        /// Only sessions with this tag (from `session end --tag`)
+        #[arg(long)]
+        tag: Option<String>,
+    },
+
+    /// Rest between sets and density of a session against the program's rest targets
+    AnalyzeRest {
+        /// Session id (or unique prefix), defaults to the latest session
+        session: Option<String>,
+    },
+
+    /// Summarize every finished session of a program block
+    BlockStats {
+        /// Program index (from `p list`) or name
+        #[arg(long)]
+        program: String,
+
+        /// Block index (as in `p show`) or name
+        #[arg(long)]
+        block: String,
+    },
+
+    /// Jump a program to a given week (it normally advances on its own)
+    SetWeek {
+        /// Program index (from `p list`) or name
+        #[arg(long)]
+        program: String,
+
+        /// Week number, starting at 1
+        #[arg(long)]
+        week: u32,
+    },
+
+    /// List unfinished sessions, or rebuild one left half-saved
+    RecoverSession {
+        /// Session id (or unique prefix) to recover
+        session: Option<String>,
+    },
+
+    /// Summary of a completed program: main-lift e1RMs, sessions, tonnage, adherence and PRs
+    FinishProgram {
+        /// Program index (from `p list`) or name
+        program: String,
+
+        /// Also archive the program
+        #[arg(long)]
+        archive: bool,
+    },
+
+    /// Sets and tonnage per muscle and exercise between two weeks, biggest drops first
+    VolumeDiff {
+        /// A date in each week to compare, e.g. `--week "2 weeks ago" --week "last week"`
+        /// (defaults to last week vs this week; one week is compared with the week before it)
+        #[arg(long = "week", value_name = "DATE")]
+        weeks: Vec<String>,
+    },
+
+    /// Move a finished program to the past programs (it stays in history)
+    ArchiveProgram {
+        /// Program index (from `p list`) or name
+        program: String,
+
+        /// Make an archived program active again
+        #[arg(long)]
+        restore: bool,
+    },
+
+    /// Remember machine settings for an exercise (seat height, pin position, ...)
+    SetEquip {
+        /// Exercise index in the current session, or global index/name
+        exercise: String,
+
+        /// Settings as "key value" pairs, e.g. "seat 4, handles B"
+        #[arg(short, long)]
+        note: Option<String>,
+
+        /// Forget all saved settings first
+        #[arg(long)]
+        clear: bool,
+    },
+
+    /// Export the exercise library (muscles, equipment, aliases, cues) as TOML, without any training history
+    ExportExercises {
+        /// Write to this file instead of stdout
+        #[arg(short, long)]
+        out: Option<String>,
+    },
+
+    /// Import an exercise library written by `export-exercises`
+    ImportExercises {
+        /// Path to the .toml file
+        file: String,
+
+        /// Combine with exercises that already exist (by name or alias) instead of stopping
+        #[arg(long)]
+        merge: bool,
+    },
+
+    /// Import exercises from a public exercise database
+    ImportExdb {
+        /// Exercise database to pull from
+        #[arg(short, long, value_enum, default_value = "wger")]
+        source: ExdbSource,
+
+        /// Only import exercises for this muscle ("all" for everything)
+        #[arg(short, long, default_value = "all")]
+        muscle: String,
+
+        /// Read a saved JSON dump instead of fetching it over the network
+        #[arg(short, long)]
+        file: Option<String>,
+    },
+}
+
+#[derive(Clone, Copy, PartialEq, ValueEnum)]
+pub enum Side {
+    /// Left side only
+    L,
+    /// Right side only
+    R,
+    /// Both sides with the same weight and reps
+    Both,
+}
+
+#[derive(Clone, Copy, ValueEnum)]
+pub enum LogFormat {
+    /// Markdown
+    Md,
+    /// Standalone HTML page
+    Html,
+}
+
+#[derive(Clone, Copy, ValueEnum)]
+pub enum AnalyzeBy {
+    /// Hour the session started
+    Hour,
+    /// Day of the week
+    Weekday,
+}
+
+#[derive(Clone, Copy, PartialEq, ValueEnum)]
+pub enum TokenScope {
+    /// `/metrics` and read-only JSON-RPC methods
+    Read,
+    /// Everything, including `run`
+    Write,
+}
+
+/// Top-level tables of a `db export` dump.
+#[derive(Clone, Copy, PartialEq, ValueEnum)]
+#[value(rename_all = "snake_case")]
+pub enum DumpTable {
+    /// Exercise library
+    Exercises,
+    /// Programs with their blocks, exercises and circuits
+    Programs,
+    /// Sessions with their exercises, sets, pauses, tags and checklist
+    Sessions,
+    /// Personal records (recomputed from the sets when the dump has none)
+    PersonalRecords,
+    EquipmentSettings,
+    Gyms,
+    ProgramProgress,
+    RestDays,
+    Recovery,
+    Nutrition,
+    Goals,
+    Measurements,
+    ProgressPhotos,
+    PointsHistory,
+    /// Other names exercises answer to
+    ExerciseAliases,
+    /// Warm-up and back-off sets expanded at session start
+    SessionSetTargets,
+}
+
+#[derive(Clone, Copy, ValueEnum)]
+pub enum ExdbSource {
+    /// https://wger.de
+    Wger,
 }
 
 //
@@ -76,29 +677,101 @@ pub enum SessionCmd {
     #[command(visible_alias = "c")]
     Cancel,
 
+    /// List every open session with its tag
+    #[command(visible_alias = "la")]
+    ListActive,
+
     /// Show current session details
     #[command(visible_alias = "i")]
     Show,
 
     /// End the current session
     // #[command(visible_alias = "e")]
-    End,
+    End {
+        /// Label the session for later filtering, e.g. "cut" or "home-gym" (repeatable)
+        #[arg(long = "tag", value_name = "TAG")]
+        tags: Vec<String>,
+    },
+
+    /// Pause the session clock; paused time doesn't count toward its duration
+    #[command(visible_alias = "p")]
+    Pause,
+
+    /// Resume a paused session
+    #[command(visible_alias = "r")]
+    Resume,
+
+    /// Run an EMOM or circuit of the block with a timer, logging each round (lists them without a number)
+    Circuit {
+        /// Circuit number, as listed in `session show`
+        circuit: Option<usize>,
+
+        /// Log this many rounds as done, without the timer
+        #[arg(long)]
+        done: Option<u32>,
+    },
+
+    /// Work up to a top single/double at a target RPE, logging each attempt, then print the back-off sets
+    TopSet {
+        /// Exercise index
+        exercise: usize,
+
+        /// RPE the top set should land on
+        #[arg(long, default_value_t = 8.0)]
+        rpe: f32,
+
+        /// Reps per attempt (1-3)
+        #[arg(long, default_value_t = 1)]
+        reps: u32,
+
+        /// First attempt in kg (default: two RPE below the target off the e1RM)
+        #[arg(long)]
+        from: Option<f32>,
+
+        /// Back-off drop from the top set in percent, when the program has no back-off sets
+        #[arg(long, default_value_t = 10.0)]
+        drop: f32,
+
+        /// Number of back-off sets, when the program has none
+        #[arg(long, default_value_t = 3)]
+        sets: u32,
+    },
 
     /// Edit a set in the current session - Usage: session edit EXERCISE WEIGHT REPS
     #[command(visible_alias = "e")]
-    #[command(override_usage = "session edit <EXERCISE> <WEIGHT> <REPS>")]
+    #[command(override_usage = "session edit <EXERCISE> <WEIGHT> <REPS>\n       session edit <EXERCISE> -w <WEIGHT> -r <REPS>\n       session edit <EXERCISE> --skip")]
     Edit {
         /// Exercise index
         #[arg(value_name = "EXERCISE")]
         exercise: usize,
 
         /// Weight in kg (use "bw" for bodyweight exercises)
-        #[arg(value_name = "WEIGHT")]
-        weight: String,
+        #[arg(value_name = "WEIGHT", required_unless_present_any = ["skip", "weight_flag"])]
+        weight: Option<String>,
+
+        /// Weight as a flag, e.g. `es 3 -w 100 -r 8`
+        #[arg(short = 'w', long = "weight", value_name = "WEIGHT", conflicts_with_all = ["weight", "skip"])]
+        weight_flag: Option<String>,
+
+        /// Weight added to a bodyweight set (belt, vest, dumbbell) in kg; use with "bw"
+        #[arg(long, conflicts_with = "skip")]
+        added_weight: Option<f32>,
 
         /// Number of reps
-        #[arg(value_name = "REPS")]
-        reps: i32,
+        #[arg(value_name = "REPS", required_unless_present_any = ["skip", "reps_flag"])]
+        reps: Option<i32>,
+
+        /// Reps as a flag
+        #[arg(short = 'r', long = "reps", value_name = "REPS", conflicts_with_all = ["reps", "skip"])]
+        reps_flag: Option<i32>,
+
+        /// Mark the set as skipped (no weight or reps needed)
+        #[arg(long, conflicts_with_all = ["weight", "reps", "failed"])]
+        skip: bool,
+
+        /// Mark the set as failed: the reps given are the ones completed
+        #[arg(long)]
+        failed: bool,
 
         /// Specific set index to edit (defaults to next unlogged set)
         #[arg(long, short = 's')]
@@ -107,22 +780,67 @@ pub enum SessionCmd {
         /// Add a new set even if all sets are already logged
         #[arg(long, short = 'n')]
         new: bool,
+
+        /// Band used (color or name, e.g. "red")
+        #[arg(long)]
+        band: Option<String>,
+
+        /// Band tension at lockout in kg (estimated from the color if omitted)
+        #[arg(long)]
+        band_tension: Option<f32>,
+
+        /// Chain weight in kg
+        #[arg(long)]
+        chains: Option<f32>,
+
+        /// Side for unilateral exercises (defaults to both)
+        #[arg(long, value_enum)]
+        side: Option<Side>,
+
+        /// Done with a belt
+        #[arg(long, conflicts_with = "skip")]
+        belt: bool,
+
+        /// Done with lifting straps
+        #[arg(long, conflicts_with = "skip")]
+        straps: bool,
+
+        /// Done with knee or elbow sleeves
+        #[arg(long, conflicts_with = "skip")]
+        sleeves: bool,
+
+        /// Done with knee or wrist wraps
+        #[arg(long, conflicts_with = "skip")]
+        wraps: bool,
     },
 
-    /// Swap an exercise in the current session with another - Usage: session swap EXERCISE NEW_EXERCISE
+    /// Swap an exercise in the current session with another - Usage: session swap EXERCISE [NEW_EXERCISE]
     #[command(visible_alias = "sw")]
     Swap {
         /// Exercise index in the current session to replace
         #[arg(value_name = "EXERCISE")]
         exercise: usize,
 
-        /// New exercise index or name to swap in
+        /// New exercise index or name to swap in; without it, list the program's substitutes
         #[arg(value_name = "NEW_EXERCISE")]
-        new_exercise: String,
+        new_exercise: Option<String>,
     },
 
     /// Add an exercise to the current session
-    AddEx { exercise: String, sets: i32 },
+    AddEx {
+        /// Exercise index or name; an unknown name lists the closest ones
+        exercise: String,
+
+        sets: i32,
+
+        /// Create the exercise when no exercise has that name
+        #[arg(long, requires = "muscle")]
+        create: bool,
+
+        /// Primary muscle of the exercise created with `--create`
+        #[arg(short, long)]
+        muscle: Option<String>,
+    },
 
     #[command(visible_alias = "n")]
     #[command(override_usage = "session note <EX_IDX> <NOTE_STRING>")]
@@ -136,11 +854,35 @@ pub enum SessionCmd {
         note: String,
     },
 
+    /// Move an exercise of the current session to another position - Usage: session move-ex EXERCISE TO
+    MoveEx {
+        /// 1-based index of the exercise (same order shown in `session show`)
+        exercise: usize,
+
+        /// New 1-based position
+        to: usize,
+    },
+
+    /// Drop an exercise from the current session - Usage: session remove-ex EXERCISE
+    #[command(visible_alias = "rm-ex")]
+    RemoveEx {
+        /// 1-based index of the exercise (same order shown in `session show`)
+        exercise: usize,
+
+        /// Also delete the sets already logged for it
+        #[arg(long, short)]
+        force: bool,
+    },
+
     /// Show details of a completed session from a specific date
     Log {
-        /// Date in DD-MM-YYYY format
+        /// Date as DD-MM-YYYY, YYYY-MM-DD, DD/MM or e.g. "yesterday", "last friday", "3 days ago"
         #[arg(short, long)]
         date: String,
+
+        /// Compare each set's reps, weight and RPE with the program's targets
+        #[arg(long)]
+        vs_target: bool,
     },
 }
 
@@ -159,6 +901,35 @@ pub enum ExerciseCmd {
         /// Exercise description
         #[arg(short, long)]
         desc: Option<String>,
+
+        /// Each side is trained separately (sets record L/R)
+        #[arg(short, long)]
+        unilateral: bool,
+
+        /// Equipment needed (barbell, dumbbell, machine, cable, kettlebell, bodyweight, band)
+        #[arg(short, long)]
+        equipment: Option<String>,
+    },
+
+    /// Mark an exercise as unilateral (or back to bilateral with --off)
+    #[command(visible_alias = "u")]
+    Unilateral {
+        /// Exercise index or name
+        exercise: String,
+
+        /// Mark it bilateral again
+        #[arg(long)]
+        off: bool,
+    },
+
+    /// Set how calculated weights are rounded for an exercise
+    #[command(visible_alias = "r")]
+    Rounding {
+        /// Exercise index or name
+        exercise: String,
+
+        /// barbell, dumbbell, lb, or a step like 2, 1.25kg, 10lb; omit to use the config default
+        profile: Option<String>,
     },
 
     /// Import exercises from a TOML file
@@ -194,6 +965,73 @@ pub enum ExerciseCmd {
         /// Show progression graph
         #[arg(short, long)]
         graph: bool,
+
+        /// Show the demo image inline (kitty, Ghostty, iTerm2, WezTerm or sixel terminals), else the cues
+        #[arg(long)]
+        image: bool,
+    },
+
+    /// Set a demo image and/or coaching cues for an exercise (shows them without either)
+    Demo {
+        /// Exercise index or name
+        exercise: String,
+
+        /// Image or GIF to copy into the media folder
+        #[arg(long)]
+        image: Option<String>,
+
+        /// Cues separated by ';', e.g. "brace; knees out; drive up"
+        #[arg(long)]
+        cues: Option<String>,
+    },
+}
+
+#[derive(Subcommand)]
+pub enum ShowCmd {
+    /// The open session, or the one finished on `--date` (same as `session show` / `session log`)
+    #[command(visible_alias = "s")]
+    Session {
+        /// Date of a finished session: DD-MM-YYYY, YYYY-MM-DD, DD/MM or e.g. "yesterday", "last friday"
+        #[arg(short, long)]
+        date: Option<String>,
+
+        /// Tag of the active session to use when more than one is open
+        #[arg(long, conflicts_with = "date")]
+        session: Option<String>,
+
+        /// With `--date`, compare each set's reps, weight and RPE with the program's targets
+        #[arg(long, requires = "date")]
+        vs_target: bool,
+    },
+
+    /// A program in detail (same as `program show`)
+    #[command(visible_alias = "p")]
+    Program {
+        /// Program index (from `p list`) or exact name
+        program: String,
+
+        /// Compact week × day grid of the main lift in every block
+        #[arg(short, long)]
+        matrix: bool,
+
+        /// Two weeks to put side by side, e.g. "3,4": each block's targets with what changed
+        #[arg(long, value_name = "A,B", conflicts_with = "matrix")]
+        compare_weeks: Option<String>,
+    },
+
+    /// An exercise's records and history (same as `exercise show`)
+    #[command(visible_alias = "exercise")]
+    Ex {
+        /// Exercise index or name
+        exercise: Vec<String>,
+
+        /// Show progression graph
+        #[arg(short, long)]
+        graph: bool,
+
+        /// Show the demo image inline, else the cues
+        #[arg(long)]
+        image: bool,
     },
 }
 
@@ -210,13 +1048,26 @@ pub enum ConfigCmd {
 
     /// Remove a key
     Unset { key: String },
+
+    /// Show the short command aliases in effect (built-in and configured)
+    Aliases,
 }
 
 #[derive(Subcommand)]
 pub enum ProgramCmd {
     /// Import one or more programs
     #[command(visible_alias = "i")]
-    Import { files: Vec<String> },
+    Import {
+        files: Vec<String>,
+
+        /// Only (re)import the blocks of these weeks, e.g. "5-8" or "3"; other weeks are left as they are
+        #[arg(long)]
+        weeks: Option<String>,
+
+        /// Fill in missing `program_1rm` values from each exercise's training max or e1RM
+        #[arg(long)]
+        autofill_1rm: bool,
+    },
 
     /// List programs
     #[command(visible_alias = "l")]
@@ -227,6 +1078,14 @@ pub enum ProgramCmd {
     Show {
         /// Program index (from `p list`) or exact name
         program: String,
+
+        /// Compact week × day grid of the main lift in every block
+        #[arg(short, long)]
+        matrix: bool,
+
+        /// Two weeks to put side by side, e.g. "3,4": each block's targets with what changed
+        #[arg(long, value_name = "A,B", conflicts_with = "matrix")]
+        compare_weeks: Option<String>,
     },
 
     /// Delete a program
@@ -235,6 +1094,74 @@ pub enum ProgramCmd {
         /// Program index (from `p list`) or exact name
         program: String,
     },
+
+    /// Add an exercise to a program block
+    AddEx {
+        /// Program index (from `p list`) or exact name
+        program: String,
+
+        /// Block index or name
+        #[arg(short, long)]
+        block: String,
+
+        /// Exercise index (from `ex list`) or name
+        #[arg(short, long)]
+        exercise: String,
+
+        /// Number of sets
+        #[arg(short, long)]
+        sets: u32,
+
+        /// Target reps: one value for every set ("8-12") or one per set ("5,5,3")
+        #[arg(short, long)]
+        reps: Option<String>,
+
+        /// 1-based position in the block (defaults to the end)
+        #[arg(long)]
+        at: Option<usize>,
+    },
+
+    /// Remove an exercise from a program block
+    RmEx {
+        /// Program index (from `p list`) or exact name
+        program: String,
+
+        /// Block index or name
+        #[arg(short, long)]
+        block: String,
+
+        /// Position in the block (from `p show`) or exercise name
+        #[arg(short, long)]
+        exercise: String,
+    },
+
+    /// Move an exercise to another position inside its block
+    MoveEx {
+        /// Program index (from `p list`) or exact name
+        program: String,
+
+        /// Block index or name
+        #[arg(short, long)]
+        block: String,
+
+        /// Position in the block (from `p show`) or exercise name
+        #[arg(short, long)]
+        exercise: String,
+
+        /// New 1-based position
+        #[arg(short, long)]
+        to: usize,
+    },
+}
+
+#[derive(Args)]
+pub struct SessionArgs {
+    /// Tag of the active session to use when more than one is open
+    #[arg(long, global = true)]
+    pub session: Option<String>,
+
+    #[command(subcommand)]
+    pub cmd: SessionCmd,
 }
 
 #[derive(Args)]
@@ -242,6 +1169,163 @@ pub struct StartArgs {
     pub program: String,
     pub block: String,
     pub week: Option<i32>,
+
+    /// Train at this gym and get swap suggestions for missing equipment
+    #[arg(long)]
+    pub gym: Option<String>,
+
+    /// Name this session so it can run alongside others (e.g. "morning")
+    #[arg(long)]
+    pub tag: Option<String>,
+
+    /// Accessories to run from the block's pool, comma separated (defaults to the least recently trained)
+    #[arg(long)]
+    pub accessories: Option<String>,
+
+    /// Fill in the block's missing `program_1rm` values from each exercise's training max or e1RM
+    #[arg(long)]
+    pub autofill_1rm: bool,
+
+    /// Tick off an item of the pre-session checklist, e.g. "belt" (repeatable)
+    #[arg(long = "check", value_name = "ITEM")]
+    pub checks: Vec<String>,
+
+    /// The `checklist` config key
+    #[arg(skip)]
+    pub checklist: Vec<String>,
+}
+
+#[derive(Subcommand)]
+pub enum PhotoCmd {
+    /// Copy a progress photo into the media folder, e.g. `photo add front.jpg --tag front`
+    #[command(visible_alias = "a")]
+    Add {
+        /// Image file
+        file: String,
+
+        /// Pose or angle, e.g. front, side, back
+        #[arg(long, short = 't')]
+        tag: String,
+
+        /// Date as YYYY-MM-DD, DD-MM-YYYY, DD/MM/YY or e.g. "yesterday", "last friday" (defaults to today)
+        #[arg(long)]
+        date: Option<String>,
+    },
+
+    /// List photos oldest first, or write them to an HTML contact sheet
+    #[command(visible_alias = "t")]
+    Timeline {
+        /// Only photos with this tag
+        #[arg(long, short = 't')]
+        tag: Option<String>,
+
+        /// Write a contact sheet to this file
+        #[arg(long)]
+        html: Option<String>,
+    },
+
+    /// Delete a photo and its copy
+    #[command(visible_alias = "rm")]
+    Remove {
+        /// Index from `photo timeline` (without --tag)
+        photo: usize,
+    },
+}
+
+#[derive(Subcommand)]
+pub enum GoalCmd {
+    /// Set a goal, e.g. `goal add "Squat 180x1 by 2025-12-01"`
+    #[command(visible_alias = "a")]
+    Add {
+        /// "<exercise> <weight>x<reps> by <date>"
+        #[arg(required = true, num_args = 1..)]
+        spec: Vec<String>,
+    },
+
+    /// List open goals with their progress
+    #[command(visible_alias = "l")]
+    List {
+        /// Include finished goals
+        #[arg(long)]
+        all: bool,
+    },
+
+    /// Mark a goal as done
+    #[command(visible_alias = "d")]
+    Done {
+        /// Goal index (from `goal list`) or exercise name
+        goal: String,
+    },
+}
+
+#[derive(Subcommand)]
+pub enum GymCmd {
+    /// Add (or replace) a gym profile; everything is available unless turned off
+    #[command(visible_alias = "a")]
+    Add {
+        /// Gym name
+        name: String,
+
+        /// No barbell/rack
+        #[arg(long)]
+        no_barbell: bool,
+
+        /// No dumbbells at all
+        #[arg(long, conflicts_with = "dumbbells")]
+        no_dumbbells: bool,
+
+        /// Dumbbell range, e.g. "2-30kg" or "30kg"
+        #[arg(long, value_name = "RANGE")]
+        dumbbells: Option<String>,
+
+        /// No machines
+        #[arg(long)]
+        no_machines: bool,
+
+        /// No cable stations
+        #[arg(long)]
+        no_cables: bool,
+
+        /// No kettlebells
+        #[arg(long)]
+        no_kettlebells: bool,
+    },
+
+    /// List gym profiles
+    #[command(visible_alias = "l")]
+    List,
+
+    /// Delete a gym profile
+    #[command(visible_alias = "d")]
+    Delete {
+        /// Gym name
+        name: String,
+    },
+}
+
+#[derive(Subcommand)]
+pub enum TokenCmd {
+    /// Create a token; it's printed once and can't be shown again
+    #[command(visible_alias = "c")]
+    Create {
+        /// Name to tell it apart by, e.g. "grafana" or "phone"
+        name: String,
+
+        /// What it may do
+        #[arg(long, value_enum, default_value = "read")]
+        scope: TokenScope,
+    },
+
+    /// List tokens with their scope and when they were last used
+    #[command(visible_alias = "l")]
+    List,
+
+    /// Revoke a token; requests using it are refused from then on
+    #[command(visible_alias = "r")]
+    Revoke {
+        /// Token name
+        name: String,
+    },
 }
 
 #[derive(Subcommand)]
@@ -251,12 +1335,31 @@ pub enum DbCmd {
         /// Output file path (defaults to dump.toml)
         #[arg(short, long)]
         file: Option<String>,
+
+        /// Drop notes and other free text and replace ids, keeping every number (for sharing)
+        #[arg(long)]
+        anonymize: bool,
+
+        /// Leave out what `db import` rebuilds from the sets (estimated PRs, each exercise's e1RM), so a dump kept in git only changes with the training itself
+        #[arg(long)]
+        canonical: bool,
     },
 
     /// Import database from a TOML file
     Import {
         /// Input TOML file path
         file: String,
+
+        /// Only import these tables of the dump, e.g. "sessions,personal_records"
+        #[arg(long, value_enum, value_delimiter = ',', value_name = "TABLE")]
+        tables: Vec<DumpTable>,
+    },
+
+    /// Merge a TOML dump into the database, keeping newer local rows (safe on a live DB)
+    #[command(visible_alias = "merge-db")]
+    Merge {
+        /// Input TOML file path
+        file: String,
     },
 
     /// Migrate an *old* lazaro.db into the current one
@@ -264,4 +1367,10 @@ pub enum DbCmd {
         /// path to the old lazaro.db (source)
         old_db: String,
     },
+
+    /// Size, row counts, index sizes and the session date range
+    Stats,
+
+    /// Check integrity, then VACUUM and ANALYZE
+    Maintain,
 }
@@ -0,0 +1,172 @@
+use anyhow::Result;
+use chrono::Weekday;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    cli::AnalyzeBy,
+    formula,
+    i18n::{tf, tr, weekday_name},
+    types::{OutputFmt, SET_LOAD, emit},
+    ui::{self, Themed},
+};
+
+const WEEKDAYS: [Weekday; 7] = [
+    Weekday::Mon,
+    Weekday::Tue,
+    Weekday::Wed,
+    Weekday::Thu,
+    Weekday::Fri,
+    Weekday::Sat,
+    Weekday::Sun,
+];
+
+#[derive(Serialize)]
+struct Segment {
+    label: String,
+    sessions: usize,
+    /// Mean of each session's top e1RM per exercise as % of that exercise's
+    /// best in the window.
+    relative_e1rm_pct: Option<f64>,
+    avg_tonnage_kg: f64,
+}
+
+/// Finished sessions of the last `weeks` weeks, only those tagged `tag` when
+/// given, as (local hour, local weekday with Monday = 0, relative e1RM, tonnage).
+async fn load_sessions(pool: &SqlitePool, weeks: u32, tag: Option<&str>) -> Result<Vec<(i64, i64, Option<f64>, f64)>> {
+    Ok(sqlx::query_as(
+        &format!(r#"
+        WITH recent AS (
+            SELECT id, start_time
+            FROM training_sessions ts
+            WHERE end_time IS NOT NULL
+            AND start_time >= datetime('now', '-' || ?1 || ' days')
+            AND (?2 IS NULL OR EXISTS (SELECT 1 FROM session_tags st WHERE st.training_session_id = ts.id AND st.tag = ?2))
+        ),
+        set_e1rm AS (
+            SELECT r.id AS session_id, tse.exercise_id, {e1rm} AS e1rm
+            FROM recent r
+            JOIN training_session_exercises tse ON tse.training_session_id = r.id
+            JOIN exercise_sets es ON es.session_exercise_id = tse.id
+            WHERE es.weight > 0 AND es.bodyweight = 0 AND COALESCE(es.ignore_for_one_rm, 0) = 0
+        ),
+        top AS (
+            SELECT session_id, exercise_id, MAX(e1rm) AS e1rm
+            FROM set_e1rm
+            GROUP BY session_id, exercise_id
+        ),
+        best AS (
+            SELECT exercise_id, MAX(e1rm) AS e1rm
+            FROM top
+            GROUP BY exercise_id
+        )
+        SELECT CAST(strftime('%H', r.start_time, 'localtime') AS INTEGER),
+               (CAST(strftime('%w', r.start_time, 'localtime') AS INTEGER) + 6) % 7,
+               (SELECT AVG(t.e1rm / b.e1rm)
+                FROM top t JOIN best b ON b.exercise_id = t.exercise_id
+                WHERE t.session_id = r.id),
+               CAST(COALESCE((SELECT SUM({load} * es.reps)
+                              FROM training_session_exercises tse
+                              JOIN exercise_sets es ON es.session_exercise_id = tse.id
+                              WHERE tse.training_session_id = r.id), 0) AS REAL)
+        FROM recent r
+        ORDER BY r.start_time
+        "#, e1rm = formula::e1rm_sql("es.weight", "es.reps"), load = SET_LOAD),
+    )
+    .bind(weeks * 7)
+    .bind(tag)
+    .fetch_all(pool)
+    .await?)
+}
+
+fn mean(values: impl Iterator<Item = f64>) -> Option<f64> {
+    let (sum, n) = values.fold((0.0, 0), |(s, n), v| (s + v, n + 1));
+    (n > 0).then(|| sum / n as f64)
+}
+
+pub async fn handle(pool: &SqlitePool, by: AnalyzeBy, weeks: u32, tag: Option<String>, fmt: OutputFmt) -> Result<()> {
+    let sessions = load_sessions(pool, weeks, tag.as_deref()).await?;
+    if sessions.is_empty() {
+        match &tag {
+            Some(tag) => ui::info(tf("no finished sessions tagged `{}` in the last {} weeks", &[&tag, &weeks])),
+            None => ui::info(tf("no finished sessions in the last {} weeks", &[&weeks])),
+        }
+        return Ok(());
+    }
+
+    let key = |(hour, weekday, _, _): &(i64, i64, Option<f64>, f64)| match by {
+        AnalyzeBy::Hour => *hour,
+        AnalyzeBy::Weekday => *weekday,
+    };
+    let mut keys: Vec<i64> = sessions.iter().map(key).collect();
+    keys.sort();
+    keys.dedup();
+
+    let segments: Vec<Segment> = keys
+        .into_iter()
+        .map(|k| {
+            let group: Vec<_> = sessions.iter().filter(|s| key(s) == k).collect();
+            Segment {
+                label: match by {
+                    AnalyzeBy::Hour => format!("{:02}:00", k),
+                    AnalyzeBy::Weekday => weekday_name(WEEKDAYS[k as usize % 7]).to_string(),
+                },
+                sessions: group.len(),
+                relative_e1rm_pct: mean(group.iter().filter_map(|s| s.2)).map(|r| r * 100.0),
+                avg_tonnage_kg: mean(group.iter().map(|s| s.3)).unwrap_or_default(),
+            }
+        })
+        .collect();
+
+    let overall = mean(sessions.iter().filter_map(|s| s.2)).map(|r| r * 100.0);
+
+    emit(fmt, &segments, || {
+        println!(
+            "{} {}",
+            tr("Performance by time:").heading().bold(),
+            tf("(last {} weeks, {} sessions)", &[&weeks, &sessions.len()]).dimmed()
+        );
+        if let Some(tag) = &tag {
+            println!("{}", tf("tagged `{}` only", &[&tag]).dimmed());
+        }
+        println!(
+            "  {:<14} {:>8} {:>9} {:>7} {:>10}",
+            "",
+            tr("sessions"),
+            tr("% of best"),
+            "",
+            tr("tonnage")
+        );
+        for s in &segments {
+            let pct = s.relative_e1rm_pct.map(|p| format!("{:.1}%", p)).unwrap_or_else(|| "–".into());
+            // Padded before coloring so the escape codes don't break the columns.
+            let delta = match (s.relative_e1rm_pct, overall) {
+                (Some(p), Some(o)) => {
+                    let text = format!("{:>7}", format!("{:+.1}", p - o));
+                    match p - o {
+                        d if d >= 0.5 => text.good().to_string(),
+                        d if d <= -0.5 => text.bad().to_string(),
+                        _ => text.dimmed().to_string(),
+                    }
+                }
+                _ => " ".repeat(7),
+            };
+            println!(
+                "  {:<14} {:>8} {:>9} {} {:>10}",
+                s.label.bold(),
+                s.sessions,
+                pct,
+                delta,
+                format!("{:.0} kg", s.avg_tonnage_kg)
+            );
+        }
+        println!(
+            "{}",
+            tr("% of best: each session's top set per exercise against the best in the window; the delta is against your average.")
+                .dimmed()
+        );
+    });
+
+    Ok(())
+}
@@ -0,0 +1,136 @@
+use std::{fs, path::PathBuf};
+
+use anyhow::{Context, Result};
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::session::active_session,
+    dates::{DATE_FORMATS, parse_date},
+    errors::AppError,
+    i18n::{short_date, tf, tr},
+    ui::{self, Themed},
+};
+
+/// Managed folder that attached files and progress photos are copied to.
+pub fn media_dir() -> Result<PathBuf> {
+    Ok(dirs::data_dir().context("no data dir")?.join("lazarus").join("media"))
+}
+
+/// Attachments of a session exercise as (1-based set number, path), in set order.
+pub async fn attachments_of(pool: &SqlitePool, session_exercise_id: &str) -> Result<Vec<(i64, String)>> {
+    Ok(sqlx::query_as(
+        r#"
+        WITH numbered AS (
+            SELECT id, ROW_NUMBER() OVER (ORDER BY set_index) AS n
+            FROM exercise_sets
+            WHERE session_exercise_id = ?
+        )
+        SELECT numbered.n, sa.path
+        FROM set_attachments sa
+        JOIN numbered ON numbered.id = sa.exercise_set_id
+        ORDER BY numbered.n, sa.created_at
+        "#,
+    )
+    .bind(session_exercise_id)
+    .fetch_all(pool)
+    .await?)
+}
+
+/// Print one "MEDIA:" line per attachment, indented under an exercise.
+pub async fn print_attachments(pool: &SqlitePool, session_exercise_id: &str) -> Result<()> {
+    for (set, path) in attachments_of(pool, session_exercise_id).await? {
+        ui::outln!("    {} {} {}", tr("MEDIA:").info().bold(), tf("set {}", &[&set]).dimmed(), path);
+    }
+    Ok(())
+}
+
+/// The open session, or the latest finished one on `date`.
+async fn target_session(pool: &SqlitePool, session: Option<&str>, date: Option<&str>) -> Result<String> {
+    let Some(date) = date else {
+        return active_session(pool, session).await;
+    };
+
+    let Some(day) = parse_date(Some(date)) else {
+        return Err(AppError::Invalid(tf("invalid date `{}` (expected {})", &[&date, &DATE_FORMATS])).into());
+    };
+
+    let id: Option<String> = sqlx::query_scalar(
+        r#"
+        SELECT id FROM training_sessions
+        WHERE date(start_time) = ? AND end_time IS NOT NULL
+        ORDER BY start_time DESC
+        LIMIT 1
+        "#,
+    )
+    .bind(day.format("%Y-%m-%d").to_string())
+    .fetch_optional(pool)
+    .await?;
+
+    id.ok_or_else(|| AppError::NotFound(tf("no completed session found for {}", &[&short_date(day)])).into())
+}
+
+pub async fn handle(
+    pool: &SqlitePool,
+    exercise: usize,
+    set: usize,
+    file: PathBuf,
+    session: Option<String>,
+    date: Option<String>,
+) -> Result<()> {
+    if !file.is_file() {
+        return Err(AppError::NotFound(tf("no file at `{}`", &[&file.display()]).into()).into());
+    }
+
+    let session_id = target_session(pool, session.as_deref(), date.as_deref()).await?;
+
+    let session_exercise_id: Option<String> = sqlx::query_scalar(
+        r#"
+        SELECT id FROM training_session_exercises
+        WHERE training_session_id = ?
+        ORDER BY order_index
+        LIMIT 1 OFFSET ?
+        "#,
+    )
+    .bind(&session_id)
+    .bind(exercise.saturating_sub(1) as i64)
+    .fetch_optional(pool)
+    .await?;
+
+    let Some(session_exercise_id) = session_exercise_id.filter(|_| exercise > 0) else {
+        return Err(AppError::NotFound(tf("no exercise at index {}", &[&exercise]).into()).into());
+    };
+
+    let set_id: Option<String> = sqlx::query_scalar(
+        "SELECT id FROM exercise_sets WHERE session_exercise_id = ? ORDER BY set_index LIMIT 1 OFFSET ?",
+    )
+    .bind(&session_exercise_id)
+    .bind(set.saturating_sub(1) as i64)
+    .fetch_optional(pool)
+    .await?;
+
+    let Some(set_id) = set_id.filter(|_| set > 0) else {
+        return Err(AppError::Invalid(tf("set {} of exercise {} is not logged yet", &[&set, &exercise]).into()).into());
+    };
+
+    // Prefix with the set id so two "pr.mp4" never clash.
+    let dir = media_dir()?;
+    fs::create_dir_all(&dir).with_context(|| format!("could not create `{}`", dir.display()))?;
+    let name = file
+        .file_name()
+        .map(|n| n.to_string_lossy().into_owned())
+        .unwrap_or_else(|| "attachment".to_string());
+    let dest = dir.join(format!("{}-{}", &set_id[..8], name));
+    fs::copy(&file, &dest).with_context(|| format!("could not copy `{}`", file.display()))?;
+
+    let path = dest.to_string_lossy().into_owned();
+    sqlx::query("INSERT OR IGNORE INTO set_attachments (exercise_set_id, path) VALUES (?, ?)")
+        .bind(&set_id)
+        .bind(&path)
+        .execute(pool)
+        .await?;
+
+    ui::ok(tf("attached to set {} of exercise {}: {}", &[&set, &exercise, &path]));
+
+    Ok(())
+}
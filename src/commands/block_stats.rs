@@ -0,0 +1,256 @@
+use std::collections::BTreeMap;
+
+use anyhow::Result;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::{
+        program::{resolve_block, resolve_program},
+        recovery::min_reps,
+    },
+    formula,
+    i18n::{kg, tf, tr},
+    types::{OutputFmt, SET_LOAD, emit},
+    ui::Themed,
+};
+
+#[derive(Serialize)]
+struct BlockStats {
+    program: String,
+    block: String,
+    sessions: i64,
+    first: Option<String>,
+    last: Option<String>,
+    avg_minutes: Option<f64>,
+    avg_tonnage_kg: Option<f64>,
+    exercises: Vec<ExerciseTrend>,
+    most_missed: Option<MissedTarget>,
+}
+
+/// Best set of the first and latest session an exercise was done in.
+#[derive(Serialize)]
+struct ExerciseTrend {
+    exercise: String,
+    sessions: i64,
+    first_e1rm: Option<f64>,
+    last_e1rm: Option<f64>,
+    first_top: Option<(f64, i64)>,
+    last_top: Option<(f64, i64)>,
+}
+
+#[derive(Serialize)]
+struct MissedTarget {
+    exercise: String,
+    set: usize,
+    target: String,
+    missed: i64,
+    sessions: i64,
+}
+
+async fn collect(pool: &SqlitePool, program: String, block: String, block_id: &str) -> Result<BlockStats> {
+    let (sessions, first, last, avg_minutes): (i64, Option<String>, Option<String>, Option<f64>) = sqlx::query_as(
+        r#"
+        SELECT CAST(COUNT(*) AS INTEGER),
+               MIN(date(start_time)),
+               MAX(date(start_time)),
+               AVG((julianday(end_time) - julianday(start_time)) * 24 * 60
+                   - COALESCE((SELECT SUM(julianday(sp.resumed_at) - julianday(sp.paused_at))
+                               FROM session_pauses sp
+                               WHERE sp.training_session_id = training_sessions.id), 0) * 24 * 60)
+        FROM training_sessions
+        WHERE program_block_id = ? AND end_time IS NOT NULL
+        "#,
+    )
+    .bind(block_id)
+    .fetch_one(pool)
+    .await?;
+
+    let avg_tonnage_kg: Option<f64> = sqlx::query_scalar(
+        &format!(r#"
+        SELECT AVG(tonnage) FROM (
+            SELECT COALESCE(SUM({load} * es.reps), 0) AS tonnage
+            FROM training_sessions ts
+            LEFT JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+            LEFT JOIN exercise_sets es ON es.session_exercise_id = tse.id
+            WHERE ts.program_block_id = ? AND ts.end_time IS NOT NULL
+            GROUP BY ts.id
+        )
+        "#, load = SET_LOAD),
+    )
+    .bind(block_id)
+    .fetch_one(pool)
+    .await?;
+
+    // Program exercises in block order, with their per-set rep targets.
+    let planned: Vec<(String, String, i32, Option<String>)> = sqlx::query_as(
+        r#"
+        SELECT e.id, e.name, pe.sets, pe.reps
+        FROM program_exercises pe
+        JOIN exercises e ON e.id = pe.exercise_id
+        WHERE pe.program_block_id = ?
+        ORDER BY pe.order_index
+        "#,
+    )
+    .bind(block_id)
+    .fetch_all(pool)
+    .await?;
+
+    let mut exercises = Vec::new();
+    let mut most_missed: Option<MissedTarget> = None;
+    for (ex_id, name, planned_sets, reps) in planned {
+        // Every logged set of this exercise in this block's sessions, oldest session first.
+        let sets: Vec<(String, f64, i64, bool)> = sqlx::query_as(
+            r#"
+            SELECT ts.id, es.weight, es.reps, es.bodyweight
+            FROM training_sessions ts
+            JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+            JOIN exercise_sets es ON es.session_exercise_id = tse.id
+            WHERE ts.program_block_id = ? AND ts.end_time IS NOT NULL
+            AND tse.exercise_id = ?
+            ORDER BY ts.start_time, es.timestamp
+            "#,
+        )
+        .bind(block_id)
+        .bind(&ex_id)
+        .fetch_all(pool)
+        .await?;
+
+        let mut by_session: Vec<(String, Vec<(f64, i64, bool)>)> = Vec::new();
+        for (session, w, r, bw) in sets {
+            match by_session.last_mut() {
+                Some((s, v)) if *s == session => v.push((w, r, bw)),
+                _ => by_session.push((session, vec![(w, r, bw)])),
+            }
+        }
+
+        let top = |sets: &[(f64, i64, bool)]| {
+            sets.iter()
+                .filter(|(w, _, bw)| *w > 0.0 && !bw)
+                .map(|(w, r, _)| (formula::e1rm(*w, *r as f64), (*w, *r)))
+                .max_by(|a, b| a.0.total_cmp(&b.0))
+        };
+        let first_top = by_session.first().and_then(|(_, s)| top(s.as_slice()));
+        let last_top = by_session.last().and_then(|(_, s)| top(s.as_slice()));
+        exercises.push(ExerciseTrend {
+            exercise: name.clone(),
+            sessions: by_session.len() as i64,
+            first_e1rm: first_top.map(|t| t.0),
+            last_e1rm: last_top.map(|t| t.0),
+            first_top: first_top.map(|t| t.1),
+            last_top: last_top.map(|t| t.1),
+        });
+
+        // A planned set counts as missed when it wasn't logged or fell short of its rep target.
+        let targets: Vec<&str> = reps.as_deref().map(|r| r.split(',').map(str::trim).collect()).unwrap_or_default();
+        let mut missed: BTreeMap<usize, i64> = BTreeMap::new();
+        for (_, logged) in &by_session {
+            for i in 0..planned_sets.max(0) as usize {
+                let target = targets.get(i).or(targets.last()).and_then(|t| min_reps(t));
+                let short = match logged.get(i) {
+                    None => true,
+                    Some((_, r, _)) => target.is_some_and(|t| (*r as i32) < t),
+                };
+                if short {
+                    *missed.entry(i).or_default() += 1;
+                }
+            }
+        }
+        for (set, n) in missed {
+            if most_missed.as_ref().is_none_or(|m| n > m.missed) {
+                most_missed = Some(MissedTarget {
+                    exercise: name.clone(),
+                    set: set + 1,
+                    target: targets.get(set).or(targets.last()).map(|t| t.to_string()).unwrap_or_default(),
+                    missed: n,
+                    sessions: by_session.len() as i64,
+                });
+            }
+        }
+    }
+
+    Ok(BlockStats {
+        program,
+        block,
+        sessions,
+        first,
+        last,
+        avg_minutes,
+        avg_tonnage_kg,
+        exercises,
+        most_missed,
+    })
+}
+
+fn print_pretty(s: &BlockStats) {
+    println!("{} {} — {}", tr("Block:").heading().bold(), s.block.bold(), s.program.dimmed());
+    if s.sessions == 0 {
+        println!("{}", tr("  (no finished sessions of this block yet)").dimmed());
+        return;
+    }
+
+    println!(
+        "{} {}",
+        tr("Sessions:").heading().bold(),
+        tf("{} ({} → {})", &[&s.sessions, &s.first.clone().unwrap_or_default(), &s.last.clone().unwrap_or_default()])
+    );
+    if let Some(m) = s.avg_minutes {
+        println!("{} {:.0} min", tr("Avg duration:").heading().bold(), m);
+    }
+    if let Some(t) = s.avg_tonnage_kg {
+        println!("{} {:.0} kg", tr("Avg tonnage:").heading().bold(), t);
+    }
+
+    println!("\n{}", tr("Progression:").heading().bold());
+    for e in &s.exercises {
+        let set = |t: Option<(f64, i64)>| t.map(|(w, r)| format!("{}kg × {}", kg(w), r)).unwrap_or_else(|| "—".to_string());
+        let change = match (e.first_e1rm, e.last_e1rm) {
+            (Some(a), Some(b)) if a > 0.0 => {
+                let pct = (b - a) / a * 100.0;
+                let s = format!("{:+.1}% e1RM", pct);
+                if pct > 0.0 {
+                    s.good().to_string()
+                } else if pct < 0.0 {
+                    s.bad().to_string()
+                } else {
+                    s.dimmed().to_string()
+                }
+            }
+            _ => String::new(),
+        };
+        println!(
+            "  • {} {} → {} {} {}",
+            e.exercise.bold(),
+            set(e.first_top).dimmed(),
+            set(e.last_top),
+            change,
+            tf("({} sessions)", &[&e.sessions]).dimmed()
+        );
+    }
+
+    if let Some(m) = &s.most_missed {
+        println!(
+            "\n{} {}",
+            tr("Most missed:").heading().bold(),
+            tf("{} set {} ({} reps): missed {}/{} sessions", &[&m.exercise.bold(), &m.set, &m.target, &m.missed, &m.sessions])
+        );
+    }
+}
+
+pub async fn handle(pool: &SqlitePool, program: String, block: String, fmt: OutputFmt) -> Result<()> {
+    let prog_id = resolve_program(pool, &program).await?;
+    let block_id = resolve_block(pool, &prog_id, &block).await?;
+
+    let (program, block): (String, String) = sqlx::query_as(
+        "SELECT p.name, pb.name FROM program_blocks pb JOIN programs p ON p.id = pb.program_id WHERE pb.id = ?",
+    )
+    .bind(&block_id)
+    .fetch_one(pool)
+    .await?;
+
+    let stats = collect(pool, program, block, &block_id).await?;
+    emit(fmt, &stats, || print_pretty(&stats));
+
+    Ok(())
+}
@@ -1,27 +1,56 @@
-use anyhow::Result;
+use std::{fs, path::Path, process::Command};
+
+use anyhow::{Context, Result};
 use chrono::{Datelike, NaiveDate, DateTime, Utc, NaiveDateTime, Local};
 use colored::Colorize;
 use sqlx::SqlitePool;
 
-pub async fn handle(pool: &SqlitePool, year: Option<i32>, month: Option<u32>) -> Result<()> {
+use crate::{
+    commands::journal::escape_html,
+    dates::{DATE_FORMATS, parse_date},
+    errors::AppError,
+    i18n::{long_date, month_name, month_year, tf, tr, weekday_header},
+    ui::{self, Themed},
+};
+
+/// Fill colors of the exported calendar, one per program in order of first session.
+const PALETTE: [&str; 8] = ["#2e7d32", "#1565c0", "#ef6c00", "#6a1b9a", "#c62828", "#00838f", "#9e9d24", "#4e342e"];
+const EMPTY: &str = "#ebedf0";
+
+pub async fn handle(
+    pool: &SqlitePool,
+    year: Option<i32>,
+    month: Option<u32>,
+    date: Option<String>,
+    tag: Option<String>,
+    heatmap: bool,
+    export: Option<String>,
+) -> Result<()> {
     // Get current date if year/month not specified
-    let now = chrono::Local::now();
+    let now = match date {
+        Some(date) => match parse_date(Some(&date)) {
+            Some(day) => day,
+            None => return Err(AppError::Invalid(tf("invalid date `{}` (expected {})", &[&date, &DATE_FORMATS])).into()),
+        },
+        None => chrono::Local::now().date_naive(),
+    };
     let year = year.unwrap_or(now.year());
     let month = month.unwrap_or(now.month());
 
     // Validate month
     if month < 1 || month > 12 {
-        println!("{} month must be between 1 and 12", "error:".red().bold());
-        return Ok(());
+        return Err(AppError::Invalid(tr("month must be between 1 and 12").into()).into());
     }
 
-    // Get first and last day of the month
-    let first_day = NaiveDate::from_ymd_opt(year, month, 1).unwrap();
-    let last_day = if month == 12 {
-        NaiveDate::from_ymd_opt(year + 1, 1, 1).unwrap()
+    // Get first and last day of the month, or of the year for the heatmap
+    let first_day = NaiveDate::from_ymd_opt(year, if heatmap { 1 } else { month }, 1).unwrap();
+    let last_day = if heatmap {
+        NaiveDate::from_ymd_opt(year, 12, 31).unwrap()
+    } else if month == 12 {
+        NaiveDate::from_ymd_opt(year + 1, 1, 1).unwrap().pred_opt().unwrap()
     } else {
-        NaiveDate::from_ymd_opt(year, month + 1, 1).unwrap()
-    }.pred_opt().unwrap();
+        NaiveDate::from_ymd_opt(year, month + 1, 1).unwrap().pred_opt().unwrap()
+    };
 
     // Get all sessions in the month
     let sessions = sqlx::query_as::<_, (String, String, Option<String>, Option<String>, String, String)>(
@@ -30,19 +59,41 @@ pub async fn handle(pool: &SqlitePool, year: Option<i32>, month: Option<u32>) ->
         FROM training_sessions ts
         JOIN program_blocks pb ON pb.id = ts.program_block_id
         JOIN programs p ON p.id = pb.program_id
-        WHERE ts.start_time >= ? AND ts.start_time < ?
+        WHERE ts.start_time >= ?1 AND ts.start_time < ?2
+        AND (?3 IS NULL OR EXISTS (SELECT 1 FROM session_tags st WHERE st.training_session_id = ts.id AND st.tag = ?3))
         ORDER BY ts.start_time
         "#,
     )
     .bind(first_day.and_hms_opt(0, 0, 0).unwrap().format("%Y-%m-%d %H:%M:%S").to_string())
     .bind(last_day.and_hms_opt(23, 59, 59).unwrap().format("%Y-%m-%d %H:%M:%S").to_string())
+    .bind(&tag)
     .fetch_all(pool)
     .await?;
 
+    let trained: Vec<(NaiveDate, &str)> = sessions
+        .iter()
+        .filter_map(|s| Some((parse_any_datetime(&s.1)?.date(), s.4.as_str())))
+        .collect();
+
+    if let Some(path) = export {
+        let svg = if heatmap {
+            render_year_svg(year, &trained)
+        } else {
+            render_month_svg(first_day, last_day, &trained)
+        };
+        write_image(Path::new(&path), &svg)?;
+        ui::ok(tf("calendar written to {}", &[&path]));
+        return Ok(());
+    }
+    if heatmap {
+        print_heatmap(year, &trained);
+        return Ok(());
+    }
+
     // Print calendar header
-    let month_name = first_day.format("%B %Y").to_string();
-    println!("\n{}", month_name.bold().cyan());
-    println!("{}", "Su Mo Tu We Th Fr Sa".dimmed());
+    let month_name = month_year(first_day);
+    println!("\n{}", month_name.bold().heading());
+    println!("{}", weekday_header().dimmed());
 
     // Get the day of week for the first day (0 = Sunday)
     let first_weekday = first_day.weekday().num_days_from_sunday() as usize;
@@ -66,7 +117,7 @@ pub async fn handle(pool: &SqlitePool, year: Option<i32>, month: Option<u32>) ->
         // Print day number
         if let Some(_sessions) = sessions_by_day.get(&day_num) {
             // Day has sessions - print in green
-            print!("{:2} ", day.to_string().green().bold());
+            print!("{:2} ", day.to_string().good().bold());
         } else {
             // Regular day
             print!("{:2} ", day);
@@ -81,7 +132,7 @@ pub async fn handle(pool: &SqlitePool, year: Option<i32>, month: Option<u32>) ->
 
     // Print session details
     if !sessions.is_empty() {
-        println!("{}", "Sessions:".bold().cyan());
+        println!("{}", tr("Sessions:").bold().heading());
         for session in sessions {
             let start = parse_any_datetime(&session.1)
                 .unwrap();
@@ -94,7 +145,7 @@ pub async fn handle(pool: &SqlitePool, year: Option<i32>, month: Option<u32>) ->
             let duration = end - start;
             
             println!("  {} - {} ({}) | {} - {}", 
-                start.format("%a %b %d %H:%M").to_string().green(),
+                format!("{} {}", long_date(start.date()), start.format("%H:%M")).good(),
                 end.format("%H:%M").to_string(),
                 format_duration(duration),
                 session.4.bold(), // program name
@@ -112,6 +163,191 @@ pub async fn handle(pool: &SqlitePool, year: Option<i32>, month: Option<u32>) ->
     Ok(())
 }
 
+/// Programs in order of their first session, each with its color and session count.
+fn legend<'a>(trained: &[(NaiveDate, &'a str)]) -> Vec<(&'a str, &'static str, usize)> {
+    let mut programs: Vec<(&str, &str, usize)> = Vec::new();
+    for (_, program) in trained {
+        match programs.iter_mut().find(|(p, _, _)| p == program) {
+            Some(entry) => entry.2 += 1,
+            None => programs.push((program, PALETTE[programs.len() % PALETTE.len()], 1)),
+        }
+    }
+    programs
+}
+
+/// Color of a day: its first session's program, or the empty color.
+fn day_color(day: NaiveDate, trained: &[(NaiveDate, &str)], legend: &[(&str, &'static str, usize)]) -> &'static str {
+    trained
+        .iter()
+        .find(|(d, _)| *d == day)
+        .and_then(|(_, program)| legend.iter().find(|(p, _, _)| p == program))
+        .map_or(EMPTY, |(_, color, _)| color)
+}
+
+fn svg_legend(out: &mut String, legend: &[(&str, &str, usize)], x: u32, mut y: u32) {
+    for (program, color, count) in legend {
+        out.push_str(&format!(
+            "<rect x=\"{x}\" y=\"{}\" width=\"12\" height=\"12\" rx=\"2\" fill=\"{color}\"/>\n<text x=\"{}\" y=\"{y}\" font-size=\"12\">{} ({})</text>\n",
+            y - 10,
+            x + 18,
+            escape_html(program),
+            escape_html(&tf("{} sessions", &[count]))
+        ));
+        y += 18;
+    }
+}
+
+fn svg_open(width: u32, height: u32, title: &str) -> String {
+    format!(
+        "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"{width}\" height=\"{height}\" viewBox=\"0 0 {width} {height}\" font-family=\"sans-serif\">\n\
+         <rect width=\"100%\" height=\"100%\" fill=\"#ffffff\"/>\n\
+         <text x=\"20\" y=\"36\" font-size=\"22\" font-weight=\"bold\">{}</text>\n",
+        escape_html(title)
+    )
+}
+
+/// The month as a 7-column grid, training days filled with their program's color.
+fn render_month_svg(first_day: NaiveDate, last_day: NaiveDate, trained: &[(NaiveDate, &str)]) -> String {
+    const CELL: u32 = 56;
+    let legend = legend(trained);
+    let first_weekday = first_day.weekday().num_days_from_sunday();
+    let weeks = (first_weekday + last_day.day()).div_ceil(7);
+    let grid_top = 76;
+    let width = 40 + CELL * 7;
+    let height = grid_top + CELL * weeks + 20 + 18 * legend.len() as u32;
+
+    let mut out = svg_open(width, height, &month_year(first_day));
+    for (i, name) in weekday_header().split_whitespace().enumerate() {
+        out.push_str(&format!(
+            "<text x=\"{}\" y=\"{}\" font-size=\"12\" fill=\"#666666\" text-anchor=\"middle\">{}</text>\n",
+            20 + CELL * i as u32 + CELL / 2,
+            grid_top - 8,
+            escape_html(name)
+        ));
+    }
+    for day in first_day.iter_days().take_while(|d| *d <= last_day) {
+        let slot = first_weekday + day.day() - 1;
+        let (x, y) = (20 + CELL * (slot % 7), grid_top + CELL * (slot / 7));
+        let color = day_color(day, trained, &legend);
+        let text = if color == EMPTY { "#333333" } else { "#ffffff" };
+        out.push_str(&format!(
+            "<rect x=\"{}\" y=\"{}\" width=\"{}\" height=\"{}\" rx=\"6\" fill=\"{color}\"/>\n\
+             <text x=\"{}\" y=\"{}\" font-size=\"14\" fill=\"{text}\">{}</text>\n",
+            x + 2,
+            y + 2,
+            CELL - 4,
+            CELL - 4,
+            x + 8,
+            y + 20,
+            day.day()
+        ));
+    }
+    svg_legend(&mut out, &legend, 20, grid_top + CELL * weeks + 24);
+    out.push_str("</svg>\n");
+    out
+}
+
+/// The year as a week-by-weekday heatmap, training days in their program's color.
+fn render_year_svg(year: i32, trained: &[(NaiveDate, &str)]) -> String {
+    const CELL: u32 = 14;
+    let legend = legend(trained);
+    let jan1 = NaiveDate::from_ymd_opt(year, 1, 1).unwrap();
+    let dec31 = NaiveDate::from_ymd_opt(year, 12, 31).unwrap();
+    let offset = jan1.weekday().num_days_from_sunday();
+    let weeks = (offset + dec31.ordinal()).div_ceil(7);
+    let (left, grid_top) = (44, 76);
+    let width = left + CELL * weeks + 20;
+    let height = grid_top + CELL * 7 + 24 + 18 * legend.len() as u32;
+
+    let days = trained.iter().map(|(d, _)| *d).collect::<std::collections::HashSet<_>>().len();
+    let mut out = svg_open(width, height, &format!("{} · {}", year, tf("{} days trained", &[&days])));
+    for (i, name) in weekday_header().split_whitespace().enumerate().filter(|(i, _)| i % 2 == 1) {
+        out.push_str(&format!(
+            "<text x=\"20\" y=\"{}\" font-size=\"10\" fill=\"#666666\">{}</text>\n",
+            grid_top + CELL * i as u32 + 10,
+            escape_html(name)
+        ));
+    }
+    for day in jan1.iter_days().take_while(|d| *d <= dec31) {
+        let slot = offset + day.ordinal0();
+        let (x, y) = (left + CELL * (slot / 7), grid_top + CELL * (slot % 7));
+        if day.day() == 1 {
+            let name: String = month_name(day.month()).chars().take(3).collect();
+            out.push_str(&format!(
+                "<text x=\"{x}\" y=\"{}\" font-size=\"10\" fill=\"#666666\">{}</text>\n",
+                grid_top - 6,
+                escape_html(&name)
+            ));
+        }
+        out.push_str(&format!(
+            "<rect x=\"{x}\" y=\"{y}\" width=\"{}\" height=\"{}\" rx=\"2\" fill=\"{}\"><title>{}</title></rect>\n",
+            CELL - 3,
+            CELL - 3,
+            day_color(day, trained, &legend),
+            day
+        ));
+    }
+    svg_legend(&mut out, &legend, left, grid_top + CELL * 7 + 28);
+    out.push_str("</svg>\n");
+    out
+}
+
+/// Write the SVG, or for a `.png` path convert it with the first of
+/// rsvg-convert or ImageMagick that is installed.
+fn write_image(path: &Path, svg: &str) -> Result<()> {
+    let is_png = path.extension().is_some_and(|e| e.eq_ignore_ascii_case("png"));
+    if !is_png {
+        return fs::write(path, svg).with_context(|| format!("could not write `{}`", path.display()));
+    }
+
+    let tmp = std::env::temp_dir().join(format!("lazarus-calendar-{}.svg", std::process::id()));
+    fs::write(&tmp, svg).with_context(|| format!("could not write `{}`", tmp.display()))?;
+    let (svg_arg, png_arg) = (tmp.to_string_lossy().into_owned(), path.to_string_lossy().into_owned());
+    let tools: [(&str, Vec<&str>); 3] = [
+        ("rsvg-convert", vec!["-o", &png_arg, &svg_arg]),
+        ("magick", vec![&svg_arg, &png_arg]),
+        ("convert", vec![&svg_arg, &png_arg]),
+    ];
+    let converted = tools
+        .iter()
+        .any(|(cmd, args)| Command::new(cmd).args(args).status().is_ok_and(|s| s.success()));
+    let _ = fs::remove_file(&tmp);
+
+    if !converted {
+        return Err(AppError::Invalid(tr("PNG export needs rsvg-convert or ImageMagick; export to .svg instead").into()).into());
+    }
+    Ok(())
+}
+
+/// The year in the terminal, a column per week like the exported heatmap.
+fn print_heatmap(year: i32, trained: &[(NaiveDate, &str)]) {
+    let jan1 = NaiveDate::from_ymd_opt(year, 1, 1).unwrap();
+    let dec31 = NaiveDate::from_ymd_opt(year, 12, 31).unwrap();
+    let offset = jan1.weekday().num_days_from_sunday() as usize;
+    let days: std::collections::HashSet<NaiveDate> = trained.iter().map(|(d, _)| *d).collect();
+
+    println!("\n{} {}", year.to_string().bold().heading(), tf("{} days trained", &[&days.len()]).dimmed());
+    let names: Vec<&str> = weekday_header().split_whitespace().collect();
+    for (row, name) in names.iter().enumerate() {
+        print!("{} ", name.dimmed());
+        for _ in 0..(if row < offset { 1 } else { 0 }) {
+            print!("  ");
+        }
+        for day in jan1.iter_days().take_while(|d| *d <= dec31) {
+            if (offset + day.ordinal0() as usize) % 7 != row {
+                continue;
+            }
+            if days.contains(&day) {
+                print!("{} ", "■".good());
+            } else {
+                print!("{} ", "·".dimmed());
+            }
+        }
+        println!();
+    }
+    println!();
+}
+
 fn format_duration(duration: chrono::Duration) -> String {
     let hours = duration.num_hours();
     let minutes = duration.num_minutes() % 60;
@@ -0,0 +1,294 @@
+use std::{io::Write, time::Duration};
+
+use anyhow::Result;
+use colored::Colorize;
+use sqlx::SqlitePool;
+use uuid::Uuid;
+
+use crate::{
+    commands::points::current_bodyweight,
+    errors::AppError,
+    i18n::{kg, tf, tr},
+    ui::{self, Themed},
+};
+
+/// An EMOM or circuit of the session's block.
+struct Circuit {
+    id: String,
+    kind: String,
+    rounds: i32,
+    interval_secs: Option<i32>,
+    movements: Vec<Movement>,
+}
+
+struct Movement {
+    exercise_id: String,
+    name: String,
+    reps: i32,
+    weight: Option<f64>,
+}
+
+impl Movement {
+    fn label(&self) -> String {
+        match self.weight {
+            Some(w) => format!("{} {} @ {}kg", self.reps, self.name, kg(w)),
+            None => format!("{} {}", self.reps, self.name),
+        }
+    }
+}
+
+impl Circuit {
+    /// "EMOM 10 × 60s" or "Circuit 3 rounds, 90s rest".
+    fn title(&self) -> String {
+        if self.kind == "emom" {
+            tf("EMOM {} × {}s", &[&self.rounds, &self.interval_secs.unwrap_or(60)])
+        } else {
+            match self.interval_secs {
+                Some(rest) => tf("Circuit {} rounds, {}s rest", &[&self.rounds, &rest]),
+                None => tf("Circuit {} rounds", &[&self.rounds]),
+            }
+        }
+    }
+
+    fn movements_label(&self) -> String {
+        self.movements.iter().map(Movement::label).collect::<Vec<_>>().join(" · ")
+    }
+}
+
+async fn circuits_of(pool: &SqlitePool, session_id: &str) -> Result<Vec<Circuit>> {
+    let rows: Vec<(String, String, i32, Option<i32>)> = sqlx::query_as(
+        r#"
+        SELECT pc.id, pc.kind, pc.rounds, pc.interval_secs
+        FROM program_circuits pc
+        JOIN training_sessions ts ON ts.program_block_id = pc.program_block_id
+        WHERE ts.id = ?
+        ORDER BY pc.position
+        "#,
+    )
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?;
+
+    let mut circuits = Vec::with_capacity(rows.len());
+    for (id, kind, rounds, interval_secs) in rows {
+        let movements = sqlx::query_as::<_, (String, String, i32, Option<f64>)>(
+            r#"
+            SELECT m.exercise_id, e.name, m.reps, m.weight
+            FROM program_circuit_movements m
+            JOIN exercises e ON e.id = m.exercise_id
+            WHERE m.circuit_id = ?
+            ORDER BY m.position
+            "#,
+        )
+        .bind(&id)
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|(exercise_id, name, reps, weight)| Movement { exercise_id, name, reps, weight })
+        .collect();
+        circuits.push(Circuit { id, kind, rounds, interval_secs, movements });
+    }
+
+    Ok(circuits)
+}
+
+async fn rounds_done(pool: &SqlitePool, session_id: &str, circuit_id: &str) -> Result<i32> {
+    Ok(sqlx::query_scalar(
+        "SELECT CAST(COALESCE(MAX(round), 0) AS INTEGER) FROM circuit_rounds WHERE training_session_id = ? AND circuit_id = ?",
+    )
+    .bind(session_id)
+    .bind(circuit_id)
+    .fetch_one(pool)
+    .await?)
+}
+
+/// Record a finished round and a set per movement. Circuit sets don't count
+/// toward e1RMs, and each movement is logged under the session's entry for
+/// its exercise, added on first use.
+async fn log_round(
+    pool: &SqlitePool,
+    session_id: &str,
+    c: &Circuit,
+    round: i32,
+    started_at: &str,
+    body_mass: Option<f32>,
+) -> Result<()> {
+    let mut tx = pool.begin().await?;
+
+    let round_id = Uuid::new_v4().to_string();
+    sqlx::query(
+        "INSERT INTO circuit_rounds (id, training_session_id, circuit_id, round, started_at, finished_at) VALUES (?, ?, ?, ?, ?, datetime('now'))",
+    )
+    .bind(&round_id)
+    .bind(session_id)
+    .bind(&c.id)
+    .bind(round)
+    .bind(started_at)
+    .execute(&mut *tx)
+    .await?;
+
+    for m in &c.movements {
+        let existing: Option<String> = sqlx::query_scalar(
+            "SELECT id FROM training_session_exercises WHERE training_session_id = ? AND exercise_id = ? LIMIT 1",
+        )
+        .bind(session_id)
+        .bind(&m.exercise_id)
+        .fetch_optional(&mut *tx)
+        .await?;
+        let session_exercise_id = match existing {
+            Some(id) => id,
+            None => {
+                let id = Uuid::new_v4().to_string();
+                sqlx::query("INSERT INTO training_session_exercises (id, training_session_id, exercise_id) VALUES (?, ?, ?)")
+                    .bind(&id)
+                    .bind(session_id)
+                    .bind(&m.exercise_id)
+                    .execute(&mut *tx)
+                    .await?;
+                id
+            }
+        };
+
+        sqlx::query(
+            r#"
+            INSERT INTO exercise_sets (
+                id, session_exercise_id, weight, reps, bodyweight, body_mass,
+                ignore_for_one_rm, circuit_round_id, timestamp
+            ) VALUES (?, ?, ?, ?, ?, ?, 1, ?, datetime('now'))
+            "#,
+        )
+        .bind(Uuid::new_v4().to_string())
+        .bind(&session_exercise_id)
+        .bind(m.weight.unwrap_or(0.0))
+        .bind(m.reps)
+        .bind(m.weight.is_none() as i32)
+        .bind(m.weight.is_none().then_some(body_mass).flatten())
+        .bind(&round_id)
+        .execute(&mut *tx)
+        .await?;
+    }
+
+    tx.commit().await?;
+    Ok(())
+}
+
+async fn now(pool: &SqlitePool) -> Result<String> {
+    Ok(sqlx::query_scalar("SELECT datetime('now')").fetch_one(pool).await?)
+}
+
+/// Count down `secs` on one line.
+async fn countdown(label: &str, secs: i32) {
+    for left in (1..=secs).rev() {
+        print!("\r  {} {}:{:02} ", label.dimmed(), left / 60, left % 60);
+        let _ = std::io::stdout().flush();
+        tokio::time::sleep(Duration::from_secs(1)).await;
+    }
+    print!("\r{}\r", " ".repeat(label.chars().count() + 12));
+}
+
+/// Wait for Enter; false when the user typed `q` (or stdin closed).
+async fn wait_for_enter() -> Result<bool> {
+    let line = tokio::task::spawn_blocking(|| {
+        let mut line = String::new();
+        std::io::stdin().read_line(&mut line).map(|n| (n > 0).then_some(line))
+    })
+    .await??;
+
+    Ok(line.is_some_and(|l| !l.trim().eq_ignore_ascii_case("q")))
+}
+
+/// Run circuit `n` of the active session: an EMOM ticks a round every
+/// interval, a circuit moves on when Enter is pressed. With `done`, log that
+/// many rounds without the timer.
+pub async fn handle(
+    pool: &SqlitePool,
+    session_id: &str,
+    n: Option<usize>,
+    done: Option<u32>,
+    bodyweight: Option<f32>,
+) -> Result<()> {
+    let circuits = circuits_of(pool, session_id).await?;
+    if circuits.is_empty() {
+        return Err(AppError::NotFound(tr("this block has no EMOM or circuit").into()).into());
+    }
+
+    let Some(n) = n else {
+        print_circuits(pool, session_id).await?;
+        return Ok(());
+    };
+    let Some(c) = n.checked_sub(1).and_then(|i| circuits.get(i)) else {
+        return Err(AppError::NotFound(tf("no circuit {} in this block (1-{})", &[&n, &circuits.len()]).into()).into());
+    };
+
+    let body_mass = current_bodyweight(pool, bodyweight).await?;
+    let from = rounds_done(pool, session_id, &c.id).await? + 1;
+    if from > c.rounds {
+        ui::info(tf("all {} rounds of {} are already logged", &[&c.rounds, &c.title()]));
+        return Ok(());
+    }
+
+    if let Some(done) = done {
+        let to = (from + done as i32 - 1).min(c.rounds);
+        for round in from..=to {
+            let started = now(pool).await?;
+            log_round(pool, session_id, c, round, &started, body_mass).await?;
+        }
+        ui::ok(tf("logged rounds {}-{} of {}", &[&from, &to, &c.title()]));
+        return Ok(());
+    }
+
+    ui::outln!("{} {}", c.title().heading().bold(), c.movements_label().dimmed());
+    if c.kind == "emom" {
+        ui::outln!("{}", tr("  a round every interval — Ctrl-C stops, finished rounds are kept").dimmed());
+    } else {
+        ui::outln!("{}", tr("  Enter when a round is done, q to stop").dimmed());
+    }
+
+    for round in from..=c.rounds {
+        let started = now(pool).await?;
+        ui::outln!("{} {}", tf("Round {}/{}", &[&round, &c.rounds]).accent().bold(), c.movements_label());
+
+        if c.kind == "emom" {
+            countdown(&tr("next round in"), c.interval_secs.unwrap_or(60)).await;
+        } else if !wait_for_enter().await? {
+            break;
+        }
+
+        log_round(pool, session_id, c, round, &started, body_mass).await?;
+        if c.kind == "circuit" && round < c.rounds {
+            if let Some(rest) = c.interval_secs {
+                countdown(&tr("rest"), rest).await;
+            }
+        }
+    }
+
+    let done = rounds_done(pool, session_id, &c.id).await?;
+    ui::ok(tf("{}: {}/{} rounds logged", &[&c.title(), &done, &c.rounds]));
+
+    Ok(())
+}
+
+/// "Circuits:" section of `session show`: each EMOM/circuit and its rounds done.
+pub async fn print_circuits(pool: &SqlitePool, session_id: &str) -> Result<()> {
+    let circuits = circuits_of(pool, session_id).await?;
+    if circuits.is_empty() {
+        return Ok(());
+    }
+
+    ui::outln!("{}", tr("Circuits:").heading().bold());
+    for (i, c) in circuits.iter().enumerate() {
+        let done = rounds_done(pool, session_id, &c.id).await?;
+        let progress = tf("{}/{} rounds", &[&done, &c.rounds]);
+        let progress = if done >= c.rounds { progress.good().to_string() } else { progress.dimmed().to_string() };
+        ui::outln!(
+            "{} • {} — {} {}",
+            (i + 1).to_string().accent(),
+            c.title().bold(),
+            c.movements_label(),
+            progress
+        );
+    }
+    ui::outln!();
+
+    Ok(())
+}
@@ -0,0 +1,351 @@
+use std::collections::{BTreeMap, HashMap};
+
+use anyhow::{Context, Result};
+use chrono::{Datelike, Duration, Local, NaiveDateTime, Timelike};
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::{journal::markdown_log, program::program_toml, recovery::min_reps},
+    formula,
+    i18n::{tf, tr},
+    types::SetStatus,
+    ui,
+};
+
+/// A zip archive of `files`, stored without compression: every unzip tool
+/// reads it and the text files are small.
+fn zip(files: &[(String, Vec<u8>)], now: NaiveDateTime) -> Vec<u8> {
+    let time = ((now.hour() << 11) | (now.minute() << 5) | (now.second() / 2)) as u16;
+    let date = (((now.year() - 1980).max(0) as u32) << 9 | now.month() << 5 | now.day()) as u16;
+    // Bit 11: names are UTF-8.
+    let flags: u16 = 0x0800;
+
+    let mut out = Vec::new();
+    let mut central = Vec::new();
+    for (name, data) in files {
+        let offset = out.len() as u32;
+        let crc = crc32fast::hash(data);
+        let size = data.len() as u32;
+
+        out.extend_from_slice(&0x0403_4b50u32.to_le_bytes());
+        out.extend_from_slice(&20u16.to_le_bytes());
+        out.extend_from_slice(&flags.to_le_bytes());
+        out.extend_from_slice(&0u16.to_le_bytes());
+        out.extend_from_slice(&time.to_le_bytes());
+        out.extend_from_slice(&date.to_le_bytes());
+        out.extend_from_slice(&crc.to_le_bytes());
+        out.extend_from_slice(&size.to_le_bytes());
+        out.extend_from_slice(&size.to_le_bytes());
+        out.extend_from_slice(&(name.len() as u16).to_le_bytes());
+        out.extend_from_slice(&0u16.to_le_bytes());
+        out.extend_from_slice(name.as_bytes());
+        out.extend_from_slice(data);
+
+        central.extend_from_slice(&0x0201_4b50u32.to_le_bytes());
+        central.extend_from_slice(&20u16.to_le_bytes());
+        central.extend_from_slice(&20u16.to_le_bytes());
+        central.extend_from_slice(&flags.to_le_bytes());
+        central.extend_from_slice(&0u16.to_le_bytes());
+        central.extend_from_slice(&time.to_le_bytes());
+        central.extend_from_slice(&date.to_le_bytes());
+        central.extend_from_slice(&crc.to_le_bytes());
+        central.extend_from_slice(&size.to_le_bytes());
+        central.extend_from_slice(&size.to_le_bytes());
+        central.extend_from_slice(&(name.len() as u16).to_le_bytes());
+        // Extra field, comment, disk, internal and external attributes.
+        central.extend_from_slice(&[0; 12]);
+        central.extend_from_slice(&offset.to_le_bytes());
+        central.extend_from_slice(name.as_bytes());
+    }
+
+    let central_offset = out.len() as u32;
+    out.extend_from_slice(&central);
+    out.extend_from_slice(&0x0605_4b50u32.to_le_bytes());
+    out.extend_from_slice(&[0; 4]);
+    out.extend_from_slice(&(files.len() as u16).to_le_bytes());
+    out.extend_from_slice(&(files.len() as u16).to_le_bytes());
+    out.extend_from_slice(&(central.len() as u32).to_le_bytes());
+    out.extend_from_slice(&central_offset.to_le_bytes());
+    out.extend_from_slice(&0u16.to_le_bytes());
+    out
+}
+
+fn csv_field(s: &str) -> String {
+    if s.contains([',', '"', '\n', '\r']) {
+        format!("\"{}\"", s.replace('"', "\"\""))
+    } else {
+        s.to_string()
+    }
+}
+
+/// Every set of the finished sessions from `from` to `to`, one row each.
+async fn sets_csv(pool: &SqlitePool, from: &str, to: &str) -> Result<String> {
+    let rows: Vec<(String, String, String, String, String, f64, i64, Option<f64>, bool, String, Option<String>)> =
+        sqlx::query_as(
+            r#"
+            SELECT ts.start_time, p.name, pb.name, e.name, tse.id,
+                   es.weight, es.reps, es.rpe, es.bodyweight, es.status, es.notes
+            FROM training_sessions ts
+            JOIN program_blocks pb ON pb.id = ts.program_block_id
+            JOIN programs p ON p.id = pb.program_id
+            JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+            JOIN exercises e ON e.id = tse.exercise_id
+            JOIN exercise_sets es ON es.session_exercise_id = tse.id
+            WHERE ts.end_time IS NOT NULL
+            AND date(ts.start_time) BETWEEN ? AND ?
+            ORDER BY ts.start_time, tse.order_index, es.set_index
+            "#,
+        )
+        .bind(from)
+        .bind(to)
+        .fetch_all(pool)
+        .await?;
+
+    let mut out = String::from("date,time,program,block,exercise,set,weight_kg,reps,rpe,bodyweight,status,e1rm_kg,notes\n");
+    let mut set_no: HashMap<String, usize> = HashMap::new();
+    for (start, program, block, exercise, tse_id, weight, reps, rpe, bodyweight, status, notes) in rows {
+        let n = set_no.entry(tse_id).or_default();
+        *n += 1;
+        let e1rm = (SetStatus::parse(&status) != SetStatus::Skipped && !bodyweight && weight > 0.0)
+            .then(|| format!("{:.1}", formula::e1rm(weight, reps as f64)))
+            .unwrap_or_default();
+        let fields = [
+            start.get(..10).unwrap_or(&start).to_string(),
+            start.get(11..16).unwrap_or_default().to_string(),
+            program,
+            block,
+            exercise,
+            n.to_string(),
+            weight.to_string(),
+            reps.to_string(),
+            rpe.map(|r| r.to_string()).unwrap_or_default(),
+            bodyweight.to_string(),
+            status,
+            e1rm,
+            notes.unwrap_or_default(),
+        ];
+        out += &fields.iter().map(|f| csv_field(f)).collect::<Vec<_>>().join(",");
+        out.push('\n');
+    }
+    Ok(out)
+}
+
+#[derive(Default)]
+struct Compliance {
+    sessions: usize,
+    planned: usize,
+    done: usize,
+    on_target: usize,
+}
+
+/// Planned sets of the sessions from `from` to `to` against the ones logged
+/// and the ones that reached their rep target, overall and per exercise. Each
+/// session is held to the prescription it was trained against.
+async fn compliance_md(pool: &SqlitePool, from: &str, to: &str, period: &str, weeks: u32) -> Result<String> {
+    let planned: Vec<(String, String, String, i64, Option<String>)> = sqlx::query_as(
+        r#"
+        SELECT ts.id, pe.exercise_id, e.name, COALESCE(pe.sets, 0), pe.reps
+        FROM training_sessions ts
+        JOIN session_program_exercises pe ON pe.training_session_id = ts.id
+        JOIN exercises e ON e.id = pe.exercise_id
+        WHERE ts.end_time IS NOT NULL
+        AND COALESCE(pe.pool, 0) = 0
+        AND date(ts.start_time) BETWEEN ? AND ?
+        ORDER BY ts.start_time, pe.order_index
+        "#,
+    )
+    .bind(from)
+    .bind(to)
+    .fetch_all(pool)
+    .await?;
+
+    // A variation rotated or swapped in counts for the exercise it stands in for.
+    let logged: Vec<(String, String, i64, String)> = sqlx::query_as(
+        r#"
+        SELECT ts.id, COALESCE(tse.rotated_from, tse.exercise_id), es.reps, es.status
+        FROM training_sessions ts
+        JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+        JOIN exercise_sets es ON es.session_exercise_id = tse.id
+        WHERE ts.end_time IS NOT NULL
+        AND date(ts.start_time) BETWEEN ? AND ?
+        ORDER BY ts.start_time, tse.order_index, es.set_index
+        "#,
+    )
+    .bind(from)
+    .bind(to)
+    .fetch_all(pool)
+    .await?;
+
+    let mut sets: HashMap<(String, String), Vec<(i64, SetStatus)>> = HashMap::new();
+    for (session, exercise, reps, status) in logged {
+        let status = SetStatus::parse(&status);
+        if status != SetStatus::Skipped {
+            sets.entry((session, exercise)).or_default().push((reps, status));
+        }
+    }
+
+    let mut total = Compliance::default();
+    let mut by_exercise: BTreeMap<String, Compliance> = BTreeMap::new();
+    let mut sessions = std::collections::HashSet::new();
+    for (session, exercise_id, name, planned_sets, reps) in planned {
+        let planned_sets = planned_sets.max(0) as usize;
+        let targets: Vec<&str> = reps.as_deref().map(|r| r.split(',').map(str::trim).collect()).unwrap_or_default();
+        let done = sets.get(&(session.clone(), exercise_id)).map(Vec::as_slice).unwrap_or_default();
+        let on_target = done
+            .iter()
+            .take(planned_sets)
+            .enumerate()
+            .filter(|(i, (reps, status))| {
+                let target = targets.get(*i).or(targets.last()).and_then(|t| min_reps(t));
+                *status == SetStatus::Completed && target.is_none_or(|t| *reps as i32 >= t)
+            })
+            .count();
+
+        sessions.insert(session);
+        for c in [by_exercise.entry(name).or_default(), &mut total] {
+            c.sessions += 1;
+            c.planned += planned_sets;
+            c.done += done.len().min(planned_sets);
+            c.on_target += on_target;
+        }
+    }
+    total.sessions = sessions.len();
+
+    let pct = |n: usize, of: usize| if of > 0 { format!("{:.0}%", n as f64 / of as f64 * 100.0) } else { "–".to_string() };
+    let mut out = format!("# {}\n\n", tf("Compliance — {}", &[&period]));
+    out += &format!(
+        "- {}\n- {}\n- {}\n",
+        tf("{} sessions ({} per week)", &[&total.sessions, &format!("{:.1}", total.sessions as f64 / weeks as f64)]),
+        tf("{} of {} planned sets done ({})", &[&total.done, &total.planned, &pct(total.done, total.planned)]),
+        tf("{} on their rep target ({})", &[&total.on_target, &pct(total.on_target, total.planned)])
+    );
+    if !by_exercise.is_empty() {
+        out += &format!(
+            "\n| {} | {} | {} | {} | {} |\n|---|---:|---:|---:|---:|\n",
+            tr("Exercise"),
+            tr("Sessions"),
+            tr("Planned"),
+            tr("Done"),
+            tr("On target")
+        );
+        for (name, c) in &by_exercise {
+            out += &format!(
+                "| {} | {} | {} | {} ({}) | {} ({}) |\n",
+                name.replace('|', "\\|"),
+                c.sessions,
+                c.planned,
+                c.done,
+                pct(c.done, c.planned),
+                c.on_target,
+                pct(c.on_target, c.planned)
+            );
+        }
+    }
+    Ok(out)
+}
+
+/// `coach-export`: the last `weeks` weeks as one .zip for a remote coach: the
+/// training log, every set as CSV, the programs trained and a compliance summary.
+pub async fn handle(pool: &SqlitePool, weeks: u32, out: Option<String>) -> Result<()> {
+    let now = Local::now().naive_local();
+    let to = now.date();
+    let from = to - Duration::days(weeks as i64 * 7 - 1);
+    let (from_s, to_s) = (from.format("%Y-%m-%d").to_string(), to.format("%Y-%m-%d").to_string());
+    let period = format!("{} – {}", from_s, to_s);
+
+    let Some(report) = markdown_log(pool, &from_s, &to_s, &period).await? else {
+        ui::info(tf("no finished sessions in the last {} weeks", &[&weeks]));
+        return Ok(());
+    };
+
+    let mut files = vec![
+        ("report.md".to_string(), report.into_bytes()),
+        ("sets.csv".to_string(), sets_csv(pool, &from_s, &to_s).await?.into_bytes()),
+        ("compliance.md".to_string(), compliance_md(pool, &from_s, &to_s, &period, weeks).await?.into_bytes()),
+    ];
+
+    let programs: Vec<(String, String)> = sqlx::query_as(
+        r#"
+        SELECT DISTINCT p.id, p.name
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        JOIN programs p ON p.id = pb.program_id
+        WHERE ts.end_time IS NOT NULL
+        AND date(ts.start_time) BETWEEN ? AND ?
+        ORDER BY p.name
+        "#,
+    )
+    .bind(&from_s)
+    .bind(&to_s)
+    .fetch_all(pool)
+    .await?;
+    for (id, name) in &programs {
+        let file = if programs.len() == 1 {
+            "program.toml".to_string()
+        } else {
+            let slug: String = name
+                .to_lowercase()
+                .chars()
+                .map(|c| if c.is_alphanumeric() { c } else { '-' })
+                .collect();
+            format!("program-{}.toml", slug.trim_matches('-'))
+        };
+        files.push((file, program_toml(pool, id).await?.into_bytes()));
+    }
+
+    let path = out.unwrap_or_else(|| format!("coach-review-{}.zip", to_s));
+    std::fs::write(&path, zip(&files, now)).with_context(|| format!("could not write `{}`", path))?;
+    ui::ok(tf("{} weeks of training written to {} ({} files)", &[&weeks, &path, &files.len()]));
+
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use std::process::Command;
+
+    use super::*;
+    use crate::testutil::{self, memory_db};
+
+    #[test]
+    fn zip_reads_back_with_unzip() {
+        let files = vec![
+            ("report.md".to_string(), b"# Report\n".to_vec()),
+            ("sets.csv".to_string(), "date,exercise\n2024-01-01,Agachamento ção\n".as_bytes().to_vec()),
+            ("empty.txt".to_string(), Vec::new()),
+        ];
+        let now = NaiveDateTime::parse_from_str("2024-03-05 14:30:20", "%Y-%m-%d %H:%M:%S").unwrap();
+        let path = testutil::temp_path("coach.zip");
+        std::fs::write(&path, zip(&files, now)).unwrap();
+
+        let Ok(test) = Command::new("unzip").arg("-t").arg(&path).output() else {
+            eprintln!("unzip not installed, skipping");
+            return;
+        };
+        assert!(test.status.success(), "{}", String::from_utf8_lossy(&test.stdout));
+        for (name, data) in &files {
+            let out = Command::new("unzip").arg("-p").arg(&path).arg(name).output().unwrap();
+            assert_eq!(&out.stdout, data, "{}", name);
+        }
+        let listing = Command::new("unzip").arg("-l").arg(&path).output().unwrap();
+        assert!(String::from_utf8_lossy(&listing.stdout).contains("2024-03-05 14:30"));
+        let _ = std::fs::remove_file(path);
+    }
+
+    #[tokio::test]
+    async fn compliance_uses_the_prescription_trained_against() {
+        let pool = memory_db().await;
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        let block = testutil::program_block(&pool, "P", "A", &[&squat], "5").await;
+        let session = testutil::session(&pool, &block, "2024-01-10 18:00:00", Some("2024-01-10 19:00:00")).await;
+        let tse = testutil::session_exercise(&pool, &session, &squat).await;
+        for _ in 0..3 {
+            testutil::set(&pool, &tse, 100.0, 5, "completed", "2024-01-10 18:10:00").await;
+        }
+        // The block grows to 5 sets after the session.
+        sqlx::query("UPDATE program_exercises SET sets = 5").execute(&pool).await.unwrap();
+
+        let md = compliance_md(&pool, "2024-01-01", "2024-01-31", "January", 4).await.unwrap();
+        assert!(md.contains("3 of 3 planned sets done (100%)"), "{}", md);
+    }
+}
@@ -0,0 +1,202 @@
+use std::collections::HashMap;
+
+use anyhow::Result;
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{errors::AppError, i18n::{kg, tf, tr}, types::bodyweight_label, ui::{self, Themed}};
+
+/// One logged set as shown in a comparison.
+struct SetRow {
+    weight: f32,
+    reps: i32,
+    bodyweight: bool,
+}
+
+impl SetRow {
+    fn label(&self) -> String {
+        if self.bodyweight {
+            bodyweight_label(self.weight, self.reps)
+        } else {
+            format!("{}kg × {}", kg(self.weight), self.reps)
+        }
+    }
+}
+
+/// Sets of a session grouped by exercise, in session order.
+async fn session_sets(pool: &SqlitePool, session_id: &str) -> Result<Vec<(String, String, Vec<SetRow>)>> {
+    let rows = sqlx::query_as::<_, (String, String, f32, i32, bool)>(
+        r#"
+        SELECT e.id, e.name, es.weight, es.reps, es.bodyweight
+        FROM training_session_exercises tse
+        JOIN exercises e ON e.id = tse.exercise_id
+        JOIN exercise_sets es ON es.session_exercise_id = tse.id
+        WHERE tse.training_session_id = ?
+        AND es.status != 'skipped'
+        ORDER BY tse.order_index, es.set_index
+        "#,
+    )
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?;
+
+    let mut out: Vec<(String, String, Vec<SetRow>)> = Vec::new();
+    for (ex_id, name, weight, reps, bodyweight) in rows {
+        let row = SetRow { weight, reps, bodyweight };
+        match out.iter_mut().find(|(id, _, _)| *id == ex_id) {
+            Some((_, _, sets)) => sets.push(row),
+            None => out.push((ex_id, name, vec![row])),
+        }
+    }
+
+    Ok(out)
+}
+
+/// Latest finished session of the same block that started before `session_id`.
+pub async fn previous_of_block(pool: &SqlitePool, session_id: &str) -> Result<Option<String>> {
+    Ok(sqlx::query_scalar(
+        r#"
+        SELECT prev.id
+        FROM training_sessions cur
+        JOIN training_sessions prev
+          ON prev.program_block_id = cur.program_block_id
+         AND prev.start_time < cur.start_time
+         AND prev.end_time IS NOT NULL
+        WHERE cur.id = ?
+        ORDER BY prev.start_time DESC
+        LIMIT 1
+        "#,
+    )
+    .bind(session_id)
+    .fetch_optional(pool)
+    .await?)
+}
+
+/// Resolve a full session id or a unique prefix of one.
+/// Session by id or unique id prefix.
+pub async fn resolve_session(pool: &SqlitePool, id: &str) -> Result<String> {
+    let matches: Vec<String> = sqlx::query_scalar("SELECT id FROM training_sessions WHERE id LIKE ? || '%'")
+        .bind(id)
+        .fetch_all(pool)
+        .await?;
+
+    match matches.as_slice() {
+        [one] => Ok(one.clone()),
+        [] => Err(AppError::NotFound(tf("no session `{}`", &[&id])).into()),
+        _ => Err(AppError::Invalid(tf("`{}` matches {} sessions, use more characters", &[&id, &matches.len()])).into()),
+    }
+}
+
+fn delta(old: &SetRow, new: &SetRow) -> String {
+    let dw = new.weight - old.weight;
+    let dr = new.reps - old.reps;
+    let mut parts = Vec::new();
+    if dw != 0.0 && !new.bodyweight {
+        parts.push(format!("{:+}kg", dw));
+    }
+    if dr != 0 {
+        parts.push(format!("{:+} reps", dr));
+    }
+
+    if parts.is_empty() {
+        "=".dimmed().to_string()
+    } else if dw >= 0.0 && dr >= 0 {
+        parts.join(" ").good().to_string()
+    } else if dw <= 0.0 && dr <= 0 {
+        parts.join(" ").bad().to_string()
+    } else {
+        parts.join(" ").accent().to_string()
+    }
+}
+
+/// Print `new` next to `old`, set by set, with deltas.
+pub async fn print_comparison(pool: &SqlitePool, old_id: &str, new_id: &str) -> Result<()> {
+    let dates: HashMap<String, String> = sqlx::query_as::<_, (String, String)>(
+        "SELECT id, start_time FROM training_sessions WHERE id IN (?, ?)",
+    )
+    .bind(old_id)
+    .bind(new_id)
+    .fetch_all(pool)
+    .await?
+    .into_iter()
+    .collect();
+    let date = |id: &str| dates.get(id).map(|d| d[..10].to_string()).unwrap_or_default();
+
+    let old = session_sets(pool, old_id).await?;
+    let new = session_sets(pool, new_id).await?;
+
+    ui::outln!(
+        "{} {} → {}",
+        tr("Compared to:").heading().bold(),
+        date(old_id).dimmed(),
+        date(new_id)
+    );
+
+    // New session's order first, then whatever was only done last time.
+    let mut order: Vec<(&str, &str)> = new.iter().map(|(id, n, _)| (id.as_str(), n.as_str())).collect();
+    for (id, n, _) in &old {
+        if !order.iter().any(|(o, _)| *o == id.as_str()) {
+            order.push((id.as_str(), n.as_str()));
+        }
+    }
+
+    let empty = Vec::new();
+    for (ex_id, name) in order {
+        let before = old.iter().find(|(id, _, _)| id == ex_id).map(|(_, _, s)| s).unwrap_or(&empty);
+        let after = new.iter().find(|(id, _, _)| id == ex_id).map(|(_, _, s)| s).unwrap_or(&empty);
+
+        ui::outln!("• {}", name.bold());
+        for i in 0..before.len().max(after.len()) {
+            let (b, a) = (before.get(i), after.get(i));
+            let left = b.map(SetRow::label).unwrap_or_else(|| "—".to_string());
+            let right = a.map(SetRow::label).unwrap_or_else(|| "—".to_string());
+            let change = match (b, a) {
+                (Some(b), Some(a)) => delta(b, a),
+                (None, Some(_)) => tr("new set").good().to_string(),
+                (Some(_), None) => tr("skipped").bad().to_string(),
+                (None, None) => String::new(),
+            };
+            ui::outln!(
+                "  {} {} → {:<14} {}",
+                format!("{}", i + 1).accent(),
+                format!("{:<14}", left).dimmed(),
+                right,
+                change
+            );
+        }
+    }
+
+    Ok(())
+}
+
+pub async fn handle(pool: &SqlitePool, first: Option<String>, second: Option<String>) -> Result<()> {
+    let (old_id, new_id) = match (first, second) {
+        (Some(a), Some(b)) => (resolve_session(pool, &a).await?, resolve_session(pool, &b).await?),
+        (one, None) => {
+            let newest = match one {
+                Some(id) => resolve_session(pool, &id).await?,
+                None => match sqlx::query_scalar::<_, String>(
+                    "SELECT id FROM training_sessions WHERE end_time IS NOT NULL ORDER BY start_time DESC LIMIT 1",
+                )
+                .fetch_optional(pool)
+                .await?
+                {
+                    Some(id) => id,
+                    None => {
+                        return Err(AppError::NotFound(tr("no finished sessions").into()).into());
+                    }
+                },
+            };
+            match previous_of_block(pool, &newest).await? {
+                Some(prev) => (prev, newest),
+                None => {
+                    ui::info(tr("no earlier session of the same block"));
+                    return Ok(());
+                }
+            }
+        }
+        (None, Some(_)) => unreachable!("clap fills positionals in order"),
+    };
+
+    print_comparison(pool, &old_id, &new_id).await
+}
@@ -1,6 +1,13 @@
 use std::path::PathBuf;
 
-use crate::{cli::ConfigCmd, types::Config};
+use crate::{
+    cli::ConfigCmd,
+    errors::AppError,
+    formula,
+    i18n::{parse_places, parse_separator, tf, tr},
+    types::{Config, DEFAULT_ALIASES},
+    ui::{self, Themed},
+};
 use anyhow::Result;
 use colored::Colorize;
 
@@ -8,11 +15,11 @@ pub async fn handle(cmd: ConfigCmd, mut cfg: Config, config_path: PathBuf) -> Re
     match cmd {
         ConfigCmd::List => {
             if cfg.map.is_empty() {
-                println!("{} {}", "warning:".yellow().bold(), "no config set".dimmed());
+                println!("{} {}", tr("warning:").accent().bold(), "no config set".dimmed());
             } else {
-                println!("{}", "Config:".cyan().bold());
+                println!("{}", tr("Config:").heading().bold());
                 for (k, v) in &cfg.map {
-                    println!("  {} = {}", k.green(), v);
+                    println!("  {} = {}", k.good(), v);
                 }
             }
         } 
@@ -20,27 +27,54 @@ pub async fn handle(cmd: ConfigCmd, mut cfg: Config, config_path: PathBuf) -> Re
         ConfigCmd::Get { key } => {
             match cfg.map.get(&key) {
                 Some(val) => println!("{}", val),
-                None      => println!("{} key `{}` not found", "warning:".yellow().bold(), key),
+                None      => println!(
+                    "{} {}",
+                    tr("warning:").accent().bold(),
+                    tf("key `{}` not found", &[&key])
+                ),
             }
         }
 
         ConfigCmd::Set { key, val } => {
             if !cfg.validate_key(&key) {
-                println!("{} Invalid config key `{}`", "error:".red().bold(), key);
-                return Ok(());
+                return Err(AppError::Invalid(tf("Invalid config key `{}`", &[&key]).into()).into());
+            }
+            if key == "e1rm_formula" {
+                if let Err(e) = formula::parse(&val) {
+                    return Err(AppError::Invalid(tf("invalid formula `{}`: {}", &[&val, &e])).into());
+                }
+            }
+            if key == "decimal_separator" && parse_separator(&val).is_none() {
+                return Err(AppError::Invalid(tf("invalid decimal separator `{}` (expected . or ,)", &[&val])).into());
+            }
+            if (key == "weight_decimals" || key == "lb_decimals") && parse_places(&val).is_none() {
+                return Err(AppError::Invalid(tf("invalid number of decimals `{}` (expected 0 to 3)", &[&val])).into());
             }
             
             cfg.map.insert(key.clone(), val.clone());
             cfg.save(&config_path)?;
-            println!("{} set `{}` = `{}`", "info:".blue().bold(), key.green(), val);
+            ui::info(tf("set `{}` = `{}`", &[&key.good(), &val]));
+        }
+
+        ConfigCmd::Aliases => {
+            let mut aliases: Vec<(String, Vec<String>)> = cfg.aliases().into_iter().collect();
+            aliases.sort();
+            println!("{}", tr("Aliases:").heading().bold());
+            for (alias, path) in aliases {
+                let builtin = DEFAULT_ALIASES
+                    .iter()
+                    .any(|(a, p)| *a == alias && p.split('.').eq(path.iter().map(String::as_str)));
+                let origin = if builtin { tr("built-in") } else { tr("config") };
+                println!("  {:<6} {}  {}", alias.good(), path.join(" "), origin.dimmed());
+            }
         }
 
         ConfigCmd::Unset { key } => {
             if cfg.map.remove(&key).is_some() {
                 cfg.save(&config_path)?;
-                println!("{} removed `{}`", "info:".blue().bold(), key.green());
+                ui::info(tf("removed `{}`", &[&key.good()]));
             } else {
-                println!("{} key `{}` not found", "warning:".yellow().bold(), key);
+                println!("{} {}", tr("warning:").accent().bold(), tf("key `{}` not found", &[&key]));
             }
         }
     }
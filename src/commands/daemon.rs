@@ -0,0 +1,320 @@
+use std::path::PathBuf;
+
+use anyhow::{Context, Result};
+use clap::Parser;
+use serde_json::{Value, json};
+use sqlx::SqlitePool;
+use tokio::sync::Mutex;
+
+use crate::{
+    cli::{Cli, Commands, SessionCmd, ShowCmd},
+    commands::session,
+    errors::AppError,
+    i18n::{tf, tr},
+    storage::SessionStore,
+    types::Config,
+    ui,
+};
+
+/// `$XDG_RUNTIME_DIR/lazarus.sock`, else next to the media directory.
+pub fn default_socket() -> Result<PathBuf> {
+    match dirs::runtime_dir() {
+        Some(dir) => Ok(dir.join("lazarus.sock")),
+        None => Ok(dirs::data_dir().context("no data dir")?.join("lazarus").join("lazarus.sock")),
+    }
+}
+
+/// Methods that can't change anything.
+pub const READ_METHODS: [&str; 2] = ["ping", "sessions.active"];
+
+fn rpc_error(id: &Value, code: i64, message: &str) -> Value {
+    json!({ "jsonrpc": "2.0", "id": id, "error": { "code": code, "message": message } })
+}
+
+/// Open sessions with their block, program and sets logged.
+async fn active_sessions(store: &impl SessionStore) -> Result<Value> {
+    Ok(serde_json::to_value(store.open_sessions().await?)?)
+}
+
+/// Session commands read the open session and then write to it, so the
+/// daemon runs them one at a time.
+static SESSION_LOCK: Mutex<()> = Mutex::const_new(());
+
+/// The session command `args` spell, parsed by the CLI's own parser so global
+/// flags in front (`--json`, `-q`...) can't hide what runs. Only the session
+/// logging commands and their top-level shortcuts are allowed; anything that
+/// touches files, config, tokens or other servers is refused, and so are the
+/// ones that wait on a terminal.
+fn session_command(args: &[String]) -> std::result::Result<(SessionCmd, Option<String>), String> {
+    let argv = std::iter::once("lazarus").chain(args.iter().map(String::as_str));
+    let cli = Cli::try_parse_from(argv).map_err(|e| e.to_string().trim().to_string())?;
+
+    let (cmd, tag) = match cli.cmd {
+        Some(Commands::Session(args)) => (args.cmd, args.session),
+        Some(Commands::Pause { session }) => (SessionCmd::Pause, session),
+        Some(Commands::Resume { session }) => (SessionCmd::Resume, session),
+        Some(Commands::MoveEx { exercise, to, session }) => (SessionCmd::MoveEx { exercise, to }, session),
+        Some(Commands::RemoveEx { exercise, force, session }) => (SessionCmd::RemoveEx { exercise, force }, session),
+        Some(Commands::Show(ShowCmd::Session { date: Some(date), session, vs_target })) => {
+            (SessionCmd::Log { date, vs_target }, session)
+        }
+        Some(Commands::Show(ShowCmd::Session { date: None, session, .. })) => (SessionCmd::Show, session),
+        _ => return Err("only session commands can be run (`session start`, `session edit`, `session end`...)".into()),
+    };
+    match cmd {
+        SessionCmd::TopSet { .. } | SessionCmd::Circuit { circuit: Some(_), done: None } => {
+            Err("this command waits on a terminal; use `session edit` (or `circuit --done`) instead".into())
+        }
+        cmd => Ok((cmd, tag)),
+    }
+}
+
+/// Run a session command in this process, over the daemon's pool. The caller
+/// gets what it printed, as plain text, the exit code the CLI would have
+/// returned and the error, if any.
+async fn run_session(pool: &SqlitePool, cfg: &Config, cmd: SessionCmd, tag: Option<String>) -> Value {
+    let _one_at_a_time = SESSION_LOCK.lock().await;
+    let (result, output) = ui::capture(session::run(cmd, pool, tag, cfg)).await;
+    match result {
+        Ok(()) => json!({ "code": 0, "output": output }),
+        Err(e) => {
+            let code = e.downcast_ref::<AppError>().map_or(1, AppError::exit_code);
+            json!({ "code": code, "output": output, "error": e.to_string() })
+        }
+    }
+}
+
+/// Answer one JSON-RPC 2.0 request. Methods:
+/// - `ping` → `{ "version": ... }`
+/// - `sessions.active` → open sessions
+/// - `run` `{ "args": ["session", "edit", "1", "100", "5"] }` → `{ code, output, error? }`
+///
+/// Also served on `metrics`' `/rpc`, where only [`READ_METHODS`] are open to
+/// read-scoped tokens.
+pub async fn dispatch(pool: &SqlitePool, cfg: &Config, line: &str) -> Value {
+    let req: Value = match serde_json::from_str(line) {
+        Ok(v) => v,
+        Err(e) => return rpc_error(&Value::Null, -32700, &e.to_string()),
+    };
+    let id = req.get("id").cloned().unwrap_or(Value::Null);
+    let Some(method) = req.get("method").and_then(Value::as_str) else {
+        return rpc_error(&id, -32600, "missing method");
+    };
+
+    let result = match method {
+        "ping" => Ok(json!({ "version": env!("CARGO_PKG_VERSION") })),
+        "sessions.active" => active_sessions(pool).await,
+        "run" => {
+            let args: Option<Vec<String>> = req
+                .pointer("/params/args")
+                .and_then(|a| serde_json::from_value(a.clone()).ok());
+            let Some(args) = args else {
+                return rpc_error(&id, -32602, "params.args must be an array of strings");
+            };
+            match session_command(&args) {
+                Ok((cmd, tag)) => Ok(run_session(pool, cfg, cmd, tag).await),
+                Err(e) => return rpc_error(&id, -32602, &e),
+            }
+        }
+        _ => return rpc_error(&id, -32601, &format!("unknown method `{}`", method)),
+    };
+
+    match result {
+        Ok(value) => json!({ "jsonrpc": "2.0", "id": id, "result": value }),
+        Err(e) => rpc_error(&id, -32000, &e.to_string()),
+    }
+}
+
+/// Removes the socket file when the daemon stops, including on Ctrl-C.
+#[cfg(unix)]
+struct SocketFile(PathBuf);
+
+#[cfg(unix)]
+impl Drop for SocketFile {
+    fn drop(&mut self) {
+        let _ = std::fs::remove_file(&self.0);
+    }
+}
+
+/// Listen on `path` with a socket only the current user can connect to. It's
+/// bound in a directory no one else can enter and moved into place once it's
+/// 0600, so there's no moment where others could reach it.
+#[cfg(unix)]
+fn bind_private(path: &std::path::Path) -> Result<tokio::net::UnixListener> {
+    use std::os::unix::fs::{DirBuilderExt, PermissionsExt};
+
+    let dir = path.parent().unwrap_or(std::path::Path::new("."));
+    // Short names: socket paths can't be much longer than 100 bytes.
+    let staging = dir.join(format!(".lz{}", &uuid::Uuid::new_v4().simple().to_string()[..8]));
+    std::fs::DirBuilder::new().mode(0o700).create(&staging)?;
+    let bound = staging.join("s");
+    let listener = tokio::net::UnixListener::bind(&bound).map_err(anyhow::Error::from).and_then(|listener| {
+        std::fs::set_permissions(&bound, std::fs::Permissions::from_mode(0o600))?;
+        std::fs::rename(&bound, path)?;
+        Ok(listener)
+    });
+    let _ = std::fs::remove_file(&bound);
+    let _ = std::fs::remove_dir(&staging);
+    listener
+}
+
+/// Serve newline-delimited JSON-RPC on a unix socket until Ctrl-C, which lets
+/// a session command already running finish first. Only the current user can
+/// connect.
+#[cfg(unix)]
+pub async fn handle(pool: &SqlitePool, socket: Option<String>, cfg: &Config) -> Result<()> {
+    use tokio::{
+        io::{AsyncBufReadExt, AsyncWriteExt, BufReader},
+        net::UnixStream,
+    };
+
+    let path = match socket {
+        Some(s) => PathBuf::from(s),
+        None => default_socket()?,
+    };
+    if let Some(dir) = path.parent() {
+        std::fs::create_dir_all(dir).with_context(|| format!("could not create `{}`", dir.display()))?;
+    }
+    // A socket left behind by a daemon that didn't shut down cleanly.
+    if path.exists() && UnixStream::connect(&path).await.is_err() {
+        std::fs::remove_file(&path)?;
+    }
+    let listener = bind_private(&path).with_context(|| format!("cannot listen on {}", path.display()))?;
+    let _socket = SocketFile(path.clone());
+
+    ui::info(tf("daemon listening on {} (Ctrl-C to stop)", &[&path.display()]));
+
+    let stop = tokio::signal::ctrl_c();
+    tokio::pin!(stop);
+    loop {
+        let (stream, _) = tokio::select! {
+            conn = listener.accept() => conn?,
+            _ = &mut stop => break,
+        };
+        let pool = pool.clone();
+        let cfg = cfg.clone();
+        tokio::spawn(async move {
+            let (read, mut write) = stream.into_split();
+            let mut lines = BufReader::new(read).lines();
+            while let Ok(Some(line)) = lines.next_line().await {
+                if line.trim().is_empty() {
+                    continue;
+                }
+                let mut reply = dispatch(&pool, &cfg, &line).await.to_string();
+                reply.push('\n');
+                // A client hanging up mid-reply only ends its own connection.
+                if write.write_all(reply.as_bytes()).await.is_err() {
+                    break;
+                }
+            }
+        });
+    }
+
+    // Wait out a command that is writing to the database.
+    let _done = SESSION_LOCK.lock().await;
+    ui::info(tr("daemon stopped"));
+    Ok(())
+}
+
+#[cfg(not(unix))]
+pub async fn handle(_pool: &SqlitePool, _socket: Option<String>, _cfg: &Config) -> Result<()> {
+    Err(crate::errors::AppError::Invalid("`daemon` needs unix sockets, which this platform doesn't have".into()).into())
+}
+
+#[cfg(test)]
+mod tests {
+    use colored::Colorize;
+
+    use super::*;
+    use crate::testutil::{self, memory_db};
+
+    fn args(s: &str) -> Vec<String> {
+        s.split_whitespace().map(str::to_string).collect()
+    }
+
+    #[test]
+    fn only_session_commands_run() {
+        assert!(session_command(&args("session show")).is_ok());
+        assert!(session_command(&args("--json -q s edit 1 100 5")).is_ok());
+        assert!(session_command(&args("pause --session home")).is_ok());
+        assert!(session_command(&args("show session")).is_ok());
+
+        for refused in [
+            "db export /tmp/x.toml",
+            "--json db import /tmp/x.toml",
+            "token create me --scope write",
+            "config set database /tmp/x.db",
+            "--no-color daemon",
+            "metrics",
+            "setup",
+            "ex import /etc/passwd",
+            "",
+        ] {
+            assert!(session_command(&args(refused)).is_err(), "{} should be refused", refused);
+        }
+    }
+
+    #[test]
+    fn terminal_commands_are_refused() {
+        assert!(session_command(&args("session top-set 1")).is_err());
+        assert!(session_command(&args("session circuit 1")).is_err());
+        assert!(session_command(&args("session circuit 1 --done 5")).is_ok());
+        assert!(session_command(&args("session circuit")).is_ok());
+    }
+
+    #[tokio::test]
+    async fn run_reports_the_exit_code() {
+        let pool = memory_db().await;
+        let cfg = Config::default();
+        let reply = dispatch(&pool, &cfg, r#"{"jsonrpc":"2.0","id":1,"method":"run","params":{"args":["session","show"]}}"#).await;
+        assert_eq!(reply["result"]["code"], 4);
+
+        let reply = dispatch(&pool, &cfg, r#"{"jsonrpc":"2.0","id":2,"method":"run","params":{"args":["db","export","x"]}}"#).await;
+        assert_eq!(reply["error"]["code"], -32602);
+    }
+
+    #[tokio::test]
+    async fn capture_keeps_output_off_stdout() {
+        let (value, output) = ui::capture(async {
+            ui::outln!("{} {}", "Session:".bold().red(), 1);
+            ui::outln!();
+            7
+        })
+        .await;
+        assert_eq!((value, output.as_str()), (7, "Session: 1\n\n"));
+    }
+
+    #[tokio::test]
+    async fn run_returns_what_the_command_printed() {
+        let pool = memory_db().await;
+        let cfg = Config::default();
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        testutil::program_block(&pool, "Strength", "Day A", &[&squat], "5").await;
+
+        let start = r#"{"jsonrpc":"2.0","id":1,"method":"run","params":{"args":["session","start","Strength","Day A"]}}"#;
+        assert_eq!(dispatch(&pool, &cfg, start).await["result"]["code"], 0);
+        let show = r#"{"jsonrpc":"2.0","id":2,"method":"run","params":{"args":["session","show"]}}"#;
+        let reply = dispatch(&pool, &cfg, show).await;
+        assert_eq!(reply["result"]["code"], 0);
+        let output = reply["result"]["output"].as_str().unwrap();
+        assert!(output.contains("Squat") && !output.contains('\x1b'), "{}", output);
+    }
+
+    #[cfg(unix)]
+    #[tokio::test]
+    async fn socket_is_private_from_the_start() {
+        use std::os::unix::fs::PermissionsExt;
+
+        let dir = testutil::temp_path("sockets");
+        std::fs::create_dir(&dir).unwrap();
+        let path = dir.join("lazarus.sock");
+        let _listener = bind_private(&path).unwrap();
+
+        let mode = std::fs::metadata(&path).unwrap().permissions().mode();
+        assert_eq!(mode & 0o777, 0o600);
+        assert!(tokio::net::UnixStream::connect(&path).await.is_ok());
+        // Only the socket is left, not the directory it was bound in.
+        assert_eq!(std::fs::read_dir(&dir).unwrap().count(), 1);
+        let _ = std::fs::remove_dir_all(dir);
+    }
+}
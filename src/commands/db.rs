@@ -2,9 +2,21 @@ use anyhow::Result;
 use colored::Colorize;
 use serde::{Deserialize, Serialize};
 use sqlx::{query, Executor, Row, SqlitePool};
-use std::fs;
+use std::{
+    collections::HashMap,
+    fs,
+    io::{IsTerminal, Write},
+    time::{Duration, Instant},
+};
 
-use crate::cli::DbCmd;
+use crate::{
+    cli::{DbCmd, DumpTable},
+    commands::session::session_checklist,
+    formula,
+    i18n::{display_db_date, tf, tr},
+    types::{OutputFmt, emit},
+    ui::{self, Themed},
+};
 
 #[derive(Serialize, Deserialize)]
 struct DatabaseDump {
@@ -13,6 +25,30 @@ struct DatabaseDump {
     sessions: Vec<Session>,
     #[serde(default)]
     personal_records: Vec<PersonalRecord>,
+    #[serde(default)]
+    equipment_settings: Vec<EquipmentSetting>,
+    #[serde(default)]
+    gyms: Vec<GymProfile>,
+    #[serde(default)]
+    program_progress: Vec<ProgramProgress>,
+    #[serde(default)]
+    rest_days: Vec<RestDay>,
+    #[serde(default)]
+    recovery: Vec<RecoveryDay>,
+    #[serde(default)]
+    nutrition: Vec<NutritionDay>,
+    #[serde(default)]
+    goals: Vec<GoalRow>,
+    #[serde(default)]
+    measurements: Vec<Measurement>,
+    #[serde(default)]
+    progress_photos: Vec<ProgressPhoto>,
+    #[serde(default)]
+    points_history: Vec<PointsSnapshot>,
+    #[serde(default)]
+    exercise_aliases: Vec<ExerciseAlias>,
+    #[serde(default)]
+    session_set_targets: Vec<SetTarget>,
 }
 
 #[derive(Serialize, Deserialize)]
@@ -24,6 +60,17 @@ struct Exercise {
     created_at: String,
     estimated_one_rm: Option<f64>,
     current_pr_date: Option<String>,
+    #[serde(default)]
+    unilateral: bool,
+    #[serde(default)]
+    equipment: Option<String>,
+    #[serde(default)]
+    rounding: Option<String>,
+    /// Path of the demo image; the file itself isn't dumped.
+    #[serde(default)]
+    demo_path: Option<String>,
+    #[serde(default)]
+    cues: Option<String>,
 }
 
 #[derive(Serialize, Deserialize)]
@@ -32,15 +79,58 @@ struct Program {
     name: String,
     description: Option<String>,
     created_at: String,
+    #[serde(default)]
+    off_weeks: Option<String>,
+    #[serde(default)]
+    archived_at: Option<String>,
+    #[serde(default)]
+    substitutions: Vec<Substitution>,
     blocks: Vec<ProgramBlock>,
 }
 
+#[derive(Serialize, Deserialize)]
+struct Substitution {
+    group: String,
+    /// CSV of exercise names.
+    exercises: String,
+}
+
 #[derive(Serialize, Deserialize)]
 struct ProgramBlock {
     id: String,
     name: String,
     description: Option<String>,
+    #[serde(default)]
+    expected_minutes: Option<i32>,
+    #[serde(default)]
+    week: Option<i32>,
+    #[serde(default)]
+    pool_choose: Option<i32>,
     exercises: Vec<ProgramExercise>,
+    /// EMOMs and circuits, in `session circuit` order.
+    #[serde(default)]
+    circuits: Vec<ProgramCircuit>,
+    /// Prescriptions replaced after the block was trained.
+    #[serde(default)]
+    versions: Vec<ProgramExercise>,
+}
+
+#[derive(Serialize, Deserialize)]
+struct ProgramCircuit {
+    id: String,
+    position: i32,
+    kind: String,
+    rounds: i32,
+    interval_secs: Option<i32>,
+    movements: Vec<CircuitMovement>,
+}
+
+#[derive(Serialize, Deserialize)]
+struct CircuitMovement {
+    position: i32,
+    exercise_id: String,
+    reps: i32,
+    weight: Option<f64>,
 }
 
 #[derive(Serialize, Deserialize)]
@@ -56,6 +146,29 @@ struct ProgramExercise {
     technique: Option<String>,
     technique_group: Option<i32>,
     order_index: i32,
+    #[serde(default)]
+    tempo: Option<String>,
+    #[serde(default)]
+    pause: Option<String>,
+    #[serde(default)]
+    options: Option<String>,
+    #[serde(default)]
+    warmup: Option<String>,
+    #[serde(default)]
+    backoff: Option<String>,
+    #[serde(default)]
+    superset: Option<String>,
+    #[serde(default)]
+    rotate_weeks: Option<i32>,
+    #[serde(default)]
+    rest_secs: Option<i32>,
+    #[serde(default)]
+    pool: bool,
+    #[serde(default)]
+    valid_from: Option<String>,
+    /// When the prescription was changed or removed; only set on a block's `versions`.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    valid_to: Option<String>,
 }
 
 #[derive(Serialize, Deserialize)]
@@ -65,14 +178,47 @@ struct Session {
     start_time: String,
     end_time: Option<String>,
     notes: Option<String>,
+    #[serde(default)]
+    tag: Option<String>,
+    #[serde(default)]
+    pauses: Vec<SessionPause>,
+    /// Free-form labels from `session end --tag`.
+    #[serde(default)]
+    tags: Vec<String>,
+    /// Pre-session checklist as (item, checked).
+    #[serde(default)]
+    checklist: Vec<(String, bool)>,
+    #[serde(default)]
+    circuit_rounds: Vec<CircuitRound>,
     exercises: Vec<SessionExercise>,
 }
 
+#[derive(Serialize, Deserialize)]
+struct CircuitRound {
+    id: String,
+    circuit_id: String,
+    round: i32,
+    started_at: String,
+    finished_at: String,
+}
+
+#[derive(Serialize, Deserialize)]
+struct SessionPause {
+    paused_at: String,
+    resumed_at: Option<String>,
+}
+
 #[derive(Serialize, Deserialize)]
 struct SessionExercise {
     id: String,
     exercise_id: String,
     notes: Option<String>,
+    /// Program exercise this one stood in for as a scheduled rotation.
+    #[serde(default)]
+    rotated_from: Option<String>,
+    /// Swapped in by hand rather than by rotation.
+    #[serde(default)]
+    swapped: bool,
     sets: Vec<ExerciseSet>,
 }
 
@@ -87,6 +233,144 @@ struct ExerciseSet {
     timestamp: String,
     ignore_for_one_rm: bool,
     bodyweight: bool,
+    #[serde(default)]
+    tempo: Option<String>,
+    #[serde(default)]
+    pause: Option<String>,
+    #[serde(default)]
+    amrap: bool,
+    #[serde(default)]
+    band: Option<String>,
+    #[serde(default)]
+    band_tension: Option<f64>,
+    #[serde(default)]
+    chain_weight: Option<f64>,
+    /// Bodyweight a bodyweight set was done at; `weight` is then the added load.
+    #[serde(default)]
+    body_mass: Option<f64>,
+    #[serde(default)]
+    side: Option<String>,
+    /// completed, failed or skipped; older dumps have none (completed).
+    #[serde(default)]
+    status: Option<String>,
+    /// Circuit round the set was done in.
+    #[serde(default)]
+    circuit_round_id: Option<String>,
+    /// Gear worn, e.g. "belt,straps".
+    #[serde(default)]
+    flags: Option<String>,
+    /// Paths of attached photos/videos; the files themselves aren't dumped.
+    #[serde(default)]
+    attachments: Vec<String>,
+}
+
+#[derive(Serialize, Deserialize)]
+struct EquipmentSetting {
+    exercise_id: String,
+    key: String,
+    value: String,
+    updated_at: String,
+}
+
+#[derive(Serialize, Deserialize)]
+struct GymProfile {
+    id: String,
+    name: String,
+    barbell: bool,
+    dumbbells: bool,
+    dumbbell_min: Option<f64>,
+    dumbbell_max: Option<f64>,
+    machines: bool,
+    cables: bool,
+    kettlebells: bool,
+    created_at: String,
+}
+
+#[derive(Serialize, Deserialize)]
+struct RestDay {
+    date: String,
+    reason: Option<String>,
+    created_at: String,
+}
+
+#[derive(Serialize, Deserialize)]
+struct RecoveryDay {
+    date: String,
+    sleep_minutes: Option<i64>,
+    hrv: Option<f64>,
+    source: Option<String>,
+    imported_at: String,
+}
+
+#[derive(Serialize, Deserialize)]
+struct NutritionDay {
+    date: String,
+    calories: i64,
+    protein: Option<f64>,
+    updated_at: String,
+}
+
+#[derive(Serialize, Deserialize)]
+struct Measurement {
+    date: String,
+    site: String,
+    value: f64,
+    updated_at: String,
+}
+
+/// Index entry only; the image stays in the media dir.
+#[derive(Serialize, Deserialize)]
+struct ProgressPhoto {
+    id: String,
+    date: String,
+    tag: String,
+    path: String,
+    created_at: String,
+}
+
+#[derive(Serialize, Deserialize)]
+struct PointsSnapshot {
+    date: String,
+    bodyweight: f64,
+    total: f64,
+    wilks: f64,
+    dots: f64,
+    ipf_gl: f64,
+}
+
+#[derive(Serialize, Deserialize)]
+struct GoalRow {
+    id: String,
+    exercise_id: String,
+    weight: f64,
+    reps: i64,
+    target_date: String,
+    created_at: String,
+    done_at: Option<String>,
+}
+
+#[derive(Serialize, Deserialize)]
+struct ProgramProgress {
+    program_id: String,
+    current_week: i32,
+    week_started_at: String,
+}
+
+#[derive(Serialize, Deserialize)]
+struct ExerciseAlias {
+    exercise_id: String,
+    alias: String,
+}
+
+/// A warm-up or back-off set, expanded from the program at session start.
+#[derive(Serialize, Deserialize)]
+struct SetTarget {
+    id: i64,
+    session_exercise_id: String,
+    kind: String,
+    position: i32,
+    percent: f64,
+    reps: Option<String>,
 }
 
 #[derive(Serialize, Deserialize)]
@@ -96,22 +380,40 @@ struct PersonalRecord {
     weight: f64,
     reps: i32,
     estimated_1rm: f64,
+    /// Lifted in a max test rather than estimated from a set.
+    #[serde(default)]
+    verified: bool,
 }
 
 /* ────────────────────────── public entry point ───────────────────────── */
 
-pub async fn handle(cmd: DbCmd, pool: &SqlitePool) -> Result<()> {
+pub async fn handle(cmd: DbCmd, pool: &SqlitePool, fmt: OutputFmt) -> Result<()> {
     match cmd {
-        DbCmd::Export { file } => {
+        DbCmd::Export { file, anonymize, canonical } => {
             let file_path = file.unwrap_or_else(|| "dump.toml".to_string());
-            export_db(pool, &file_path).await?;
-            println!("{} database exported to {}", "ok:".green().bold(), file_path);
+            export_db(pool, &file_path, anonymize, canonical).await?;
+            if anonymize {
+                ui::ok(tf("anonymized database exported to {}", &[&file_path]));
+            } else {
+                ui::ok(tf("database exported to {}", &[&file_path]));
+            }
         }
-        DbCmd::Import { file } => {
-            import_db(pool, &file).await?;
-            println!("{} database imported from {}", "ok:".green().bold(), file);
+        DbCmd::Import { file, tables } => {
+            let started = Instant::now();
+            let rows = import_db(pool, &file, &tables).await?;
+            ui::ok(tf(
+                "database imported from {} ({} rows in {}s)",
+                &[&file, &rows, &format!("{:.1}", started.elapsed().as_secs_f64())],
+            ));
+        }
+        DbCmd::Merge { file } => {
+            let report = merge_db(pool, &file).await?;
+            report.print();
+            ui::ok(tf("merged {} into the database", &[&file]));
         }
         DbCmd::Migrate { old_db } => migrate(pool, &old_db).await?,
+        DbCmd::Stats => print_stats(pool, fmt).await?,
+        DbCmd::Maintain => maintain(pool).await?,
     }
     Ok(())
 }
@@ -214,7 +516,7 @@ pub async fn migrate(pool: &SqlitePool, old_path: &str) -> Result<()> {
 
     /* 7. PERSONAL RECORDS (one best-set per day) ---------------------- */
     conn.execute(
-        r#"
+        &format!(r#"
 INSERT OR REPLACE INTO personal_records
       (exercise_id, date, weight, reps, estimated_1rm)
 WITH ranked AS (
@@ -223,10 +525,10 @@ WITH ranked AS (
         date(ts.start_time)              AS day,
         es.weight                        AS weight,
         es.reps                          AS reps,
-        es.weight * (1.0 + es.reps/30.0) AS estimated_1rm,
+        {e1rm} AS estimated_1rm,
         ROW_NUMBER() OVER (
             PARTITION BY e.id, date(ts.start_time)
-            ORDER BY es.weight * (1.0 + es.reps/30.0) DESC
+            ORDER BY {e1rm} DESC
         ) AS rn
     FROM   exercise_sets es
     JOIN   training_session_exercises tse ON tse.id = es.session_exercise_id
@@ -237,7 +539,7 @@ WITH ranked AS (
 SELECT exercise_id, day, weight, reps, estimated_1rm
 FROM   ranked
 WHERE  rn = 1;
-"#,
+"#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
     )
     .await?;
 
@@ -265,21 +567,50 @@ WHERE pr.exercise_id = exercises.id
 
     /* 9. detach & done ------------------------------------------------ */
     conn.execute("DETACH DATABASE old;").await?;
-    println!(
-        "{} migration complete – legacy exercises, sessions & PRs imported",
-        "ok:".green().bold()
-    );
+    ui::ok(tr("migration complete – legacy exercises, sessions & PRs imported"));
 
     Ok(())
 }
 
-async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
+/// A `program_exercises` (or `program_exercise_versions`) row.
+fn program_exercise(ex: &sqlx::sqlite::SqliteRow) -> ProgramExercise {
+    ProgramExercise {
+        id: ex.get("id"),
+        exercise_id: ex.get("exercise_id"),
+        sets: ex.get("sets"),
+        reps: ex.get("reps"),
+        target_rpe: ex.get("target_rpe"),
+        target_rm_percent: ex.get("target_rm_percent"),
+        notes: ex.get("notes"),
+        program_1rm: ex.get("program_1rm"),
+        technique: ex.get("technique"),
+        technique_group: ex.get("technique_group"),
+        order_index: ex.get("order_index"),
+        tempo: ex.get("tempo"),
+        pause: ex.get("pause"),
+        options: ex.get("options"),
+        warmup: ex.get("warmup"),
+        backoff: ex.get("backoff"),
+        superset: ex.get("superset"),
+        rotate_weeks: ex.get("rotate_weeks"),
+        rest_secs: ex.get("rest_secs"),
+        pool: ex.get("pool"),
+        valid_from: ex.get("valid_from"),
+        valid_to: ex.get("valid_to"),
+    }
+}
+
+/// Every table and row comes out ordered by primary key (or by its stored
+/// position, for a session's exercises and sets), so exporting an unchanged
+/// database twice gives the same file.
+async fn export_db(pool: &SqlitePool, file_path: &str, anonymize: bool, canonical: bool) -> Result<()> {
     // Fetch exercises
     let exercises = query(
         r#"
         SELECT id, name, primary_muscle, description, created_at, 
-               estimated_one_rm, current_pr_date
+               estimated_one_rm, current_pr_date, unilateral, equipment, rounding, demo_path, cues
         FROM exercises
+        ORDER BY id
         "#
     )
     .fetch_all(pool)
@@ -293,6 +624,11 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
         created_at: row.get("created_at"),
         estimated_one_rm: row.get("estimated_one_rm"),
         current_pr_date: row.get("current_pr_date"),
+        unilateral: row.get::<i32, _>("unilateral") != 0,
+        equipment: row.get("equipment"),
+        rounding: row.get("rounding"),
+        demo_path: row.get("demo_path"),
+        cues: row.get("cues"),
     })
     .collect::<Vec<_>>();
 
@@ -300,20 +636,30 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
     let mut programs = Vec::new();
     let program_rows = query(
         r#"
-        SELECT id, name, description, created_at
+        SELECT id, name, description, created_at, off_weeks, archived_at
         FROM programs
+        ORDER BY id
         "#
     )
     .fetch_all(pool)
     .await?;
 
     for prog in program_rows {
+        let substitutions = query("SELECT group_name, exercises FROM program_substitutions WHERE program_id = ? ORDER BY group_name")
+            .bind(prog.get::<String, _>("id"))
+            .fetch_all(pool)
+            .await?
+            .into_iter()
+            .map(|row| Substitution { group: row.get("group_name"), exercises: row.get("exercises") })
+            .collect();
+
         let mut blocks = Vec::new();
         let block_rows = query(
             r#"
-            SELECT id, name, description
+            SELECT id, name, description, expected_minutes, week, pool_choose
             FROM program_blocks
             WHERE program_id = ?
+            ORDER BY id
             "#
         )
         .bind(prog.get::<String, _>("id"))
@@ -324,35 +670,81 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
             let exercises = query(
                 r#"
                 SELECT id, exercise_id, sets, reps, target_rpe, target_rm_percent,
-                       notes, program_1rm, technique, technique_group, order_index
+                       notes, program_1rm, technique, technique_group, order_index,
+                       tempo, pause, options, warmup, backoff, superset, rotate_weeks, rest_secs, pool,
+                       valid_from, NULL AS valid_to
                 FROM program_exercises
                 WHERE program_block_id = ?
+                ORDER BY id
                 "#
             )
             .bind(block.get::<String, _>("id"))
             .fetch_all(pool)
             .await?
-            .into_iter()
-            .map(|ex| ProgramExercise {
-                id: ex.get("id"),
-                exercise_id: ex.get("exercise_id"),
-                sets: ex.get("sets"),
-                reps: ex.get("reps"),
-                target_rpe: ex.get("target_rpe"),
-                target_rm_percent: ex.get("target_rm_percent"),
-                notes: ex.get("notes"),
-                program_1rm: ex.get("program_1rm"),
-                technique: ex.get("technique"),
-                technique_group: ex.get("technique_group"),
-                order_index: ex.get("order_index"),
-            })
+            .iter()
+            .map(program_exercise)
+            .collect();
+
+            let versions = query(
+                r#"
+                SELECT program_exercise_id AS id, exercise_id, sets, reps, target_rpe, target_rm_percent,
+                       notes, program_1rm, technique, technique_group, order_index,
+                       tempo, pause, options, warmup, backoff, superset, rotate_weeks, rest_secs, pool,
+                       valid_from, valid_to
+                FROM program_exercise_versions
+                WHERE program_block_id = ?
+                ORDER BY program_exercise_id, valid_to
+                "#
+            )
+            .bind(block.get::<String, _>("id"))
+            .fetch_all(pool)
+            .await?
+            .iter()
+            .map(program_exercise)
             .collect();
 
+            let mut circuits = Vec::new();
+            let circuit_rows = query(
+                "SELECT id, position, kind, rounds, interval_secs FROM program_circuits WHERE program_block_id = ? ORDER BY position",
+            )
+            .bind(block.get::<String, _>("id"))
+            .fetch_all(pool)
+            .await?;
+            for c in circuit_rows {
+                let movements = query(
+                    "SELECT position, exercise_id, reps, weight FROM program_circuit_movements WHERE circuit_id = ? ORDER BY position",
+                )
+                .bind(c.get::<String, _>("id"))
+                .fetch_all(pool)
+                .await?
+                .into_iter()
+                .map(|m| CircuitMovement {
+                    position: m.get("position"),
+                    exercise_id: m.get("exercise_id"),
+                    reps: m.get("reps"),
+                    weight: m.get("weight"),
+                })
+                .collect();
+                circuits.push(ProgramCircuit {
+                    id: c.get("id"),
+                    position: c.get("position"),
+                    kind: c.get("kind"),
+                    rounds: c.get("rounds"),
+                    interval_secs: c.get("interval_secs"),
+                    movements,
+                });
+            }
+
             blocks.push(ProgramBlock {
                 id: block.get("id"),
                 name: block.get("name"),
                 description: block.get("description"),
+                expected_minutes: block.get("expected_minutes"),
+                week: block.get("week"),
+                pool_choose: block.get("pool_choose"),
                 exercises,
+                circuits,
+                versions,
             });
         }
 
@@ -361,6 +753,9 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
             name: prog.get("name"),
             description: prog.get("description"),
             created_at: prog.get("created_at"),
+            off_weeks: prog.get("off_weeks"),
+            archived_at: prog.get("archived_at"),
+            substitutions,
             blocks,
         });
     }
@@ -369,20 +764,61 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
     let mut sessions = Vec::new();
     let session_rows = query(
         r#"
-        SELECT id, program_block_id, start_time, end_time, notes
+        SELECT id, program_block_id, start_time, end_time, notes, tag
         FROM training_sessions
+        ORDER BY id
         "#
     )
     .fetch_all(pool)
     .await?;
 
     for sess in session_rows {
+        let pauses = query(
+            "SELECT paused_at, resumed_at FROM session_pauses WHERE training_session_id = ? ORDER BY paused_at, id",
+        )
+        .bind(sess.get::<String, _>("id"))
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|p| SessionPause {
+            paused_at: p.get("paused_at"),
+            resumed_at: p.get("resumed_at"),
+        })
+        .collect();
+
+        let checklist = session_checklist(pool, &sess.get::<String, _>("id")).await?;
+
+        let tags = query("SELECT tag FROM session_tags WHERE training_session_id = ? ORDER BY tag")
+            .bind(sess.get::<String, _>("id"))
+            .fetch_all(pool)
+            .await?
+            .into_iter()
+            .map(|t| t.get("tag"))
+            .collect();
+
+        let circuit_rounds = query(
+            "SELECT id, circuit_id, round, started_at, finished_at FROM circuit_rounds WHERE training_session_id = ? ORDER BY started_at, id",
+        )
+        .bind(sess.get::<String, _>("id"))
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|r| CircuitRound {
+            id: r.get("id"),
+            circuit_id: r.get("circuit_id"),
+            round: r.get("round"),
+            started_at: r.get("started_at"),
+            finished_at: r.get("finished_at"),
+        })
+        .collect();
+
         let mut exercises = Vec::new();
         let exercise_rows = query(
             r#"
-            SELECT id, exercise_id, notes
+            SELECT id, exercise_id, notes, rotated_from, swapped
             FROM training_session_exercises
             WHERE training_session_id = ?
+            ORDER BY order_index
             "#
         )
         .bind(sess.get::<String, _>("id"))
@@ -393,9 +829,16 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
             let sets = query(
                 r#"
                 SELECT id, weight, reps, rpe, rm_percent, notes,
-                       timestamp, ignore_for_one_rm, bodyweight
+                       timestamp, ignore_for_one_rm, bodyweight, tempo, pause, amrap,
+                       band, band_tension, chain_weight, body_mass, side, status, circuit_round_id, flags,
+                       (SELECT group_concat(path, char(10)) FROM (
+                            SELECT path FROM set_attachments sa
+                            WHERE sa.exercise_set_id = exercise_sets.id
+                            ORDER BY path
+                        )) AS attachments
                 FROM exercise_sets
                 WHERE session_exercise_id = ?
+                ORDER BY set_index, id
                 "#
             )
             .bind(ex.get::<String, _>("id"))
@@ -412,6 +855,21 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
                 timestamp: set.get("timestamp"),
                 ignore_for_one_rm: set.get::<i32, _>("ignore_for_one_rm") != 0,
                 bodyweight: set.get::<i32, _>("bodyweight") != 0,
+                tempo: set.get("tempo"),
+                pause: set.get("pause"),
+                amrap: set.get::<i32, _>("amrap") != 0,
+                band: set.get("band"),
+                band_tension: set.get("band_tension"),
+                chain_weight: set.get("chain_weight"),
+                body_mass: set.get("body_mass"),
+                side: set.get("side"),
+                status: set.get("status"),
+                circuit_round_id: set.get("circuit_round_id"),
+                flags: set.get("flags"),
+                attachments: set
+                    .get::<Option<String>, _>("attachments")
+                    .map(|a| a.lines().map(str::to_string).collect())
+                    .unwrap_or_default(),
             })
             .collect();
 
@@ -419,6 +877,8 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
                 id: ex.get("id"),
                 exercise_id: ex.get("exercise_id"),
                 notes: ex.get("notes"),
+                rotated_from: ex.get("rotated_from"),
+                swapped: ex.get("swapped"),
                 sets,
             });
         }
@@ -429,6 +889,11 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
             start_time: sess.get("start_time"),
             end_time: sess.get("end_time"),
             notes: sess.get("notes"),
+            tag: sess.get("tag"),
+            pauses,
+            tags,
+            checklist,
+            circuit_rounds,
             exercises,
         });
     }
@@ -436,8 +901,9 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
     // Fetch personal records
     let personal_records = query(
         r#"
-        SELECT exercise_id, date, weight, reps, estimated_1rm
+        SELECT exercise_id, date, weight, reps, estimated_1rm, verified
         FROM personal_records
+        ORDER BY exercise_id, date
         "#
     )
     .fetch_all(pool)
@@ -449,16 +915,213 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
         weight: row.get("weight"),
         reps: row.get("reps"),
         estimated_1rm: row.get("estimated_1rm"),
+        verified: row.get::<i32, _>("verified") != 0,
+    })
+    .collect::<Vec<_>>();
+
+    // Fetch equipment settings
+    let equipment_settings = query(
+        r#"
+        SELECT exercise_id, key, value, updated_at
+        FROM equipment_settings
+        ORDER BY exercise_id, key
+        "#
+    )
+    .fetch_all(pool)
+    .await?
+    .into_iter()
+    .map(|row| EquipmentSetting {
+        exercise_id: row.get("exercise_id"),
+        key: row.get("key"),
+        value: row.get("value"),
+        updated_at: row.get("updated_at"),
     })
     .collect::<Vec<_>>();
 
+    // Fetch gym profiles
+    let gyms = query("SELECT * FROM gyms ORDER BY id")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| GymProfile {
+            id: row.get("id"),
+            name: row.get("name"),
+            barbell: row.get::<i32, _>("barbell") != 0,
+            dumbbells: row.get::<i32, _>("dumbbells") != 0,
+            dumbbell_min: row.get("dumbbell_min"),
+            dumbbell_max: row.get("dumbbell_max"),
+            machines: row.get::<i32, _>("machines") != 0,
+            cables: row.get::<i32, _>("cables") != 0,
+            kettlebells: row.get::<i32, _>("kettlebells") != 0,
+            created_at: row.get("created_at"),
+        })
+        .collect::<Vec<_>>();
+
+    // Fetch program week progress
+    let program_progress = query("SELECT * FROM program_progress ORDER BY program_id")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| ProgramProgress {
+            program_id: row.get("program_id"),
+            current_week: row.get("current_week"),
+            week_started_at: row.get("week_started_at"),
+        })
+        .collect::<Vec<_>>();
+
+    // Fetch rest days
+    let rest_days = query("SELECT * FROM rest_days ORDER BY date")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| RestDay {
+            date: row.get("date"),
+            reason: row.get("reason"),
+            created_at: row.get("created_at"),
+        })
+        .collect::<Vec<_>>();
+
+    // Fetch recovery metrics
+    let recovery = query("SELECT * FROM recovery ORDER BY date")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| RecoveryDay {
+            date: row.get("date"),
+            sleep_minutes: row.get("sleep_minutes"),
+            hrv: row.get("hrv"),
+            source: row.get("source"),
+            imported_at: row.get("imported_at"),
+        })
+        .collect::<Vec<_>>();
+
+    // Fetch nutrition log
+    let nutrition = query("SELECT * FROM nutrition ORDER BY date")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| NutritionDay {
+            date: row.get("date"),
+            calories: row.get("calories"),
+            protein: row.get("protein"),
+            updated_at: row.get("updated_at"),
+        })
+        .collect::<Vec<_>>();
+
+    // Fetch goals
+    let goals = query("SELECT * FROM goals ORDER BY id")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| GoalRow {
+            id: row.get("id"),
+            exercise_id: row.get("exercise_id"),
+            weight: row.get("weight"),
+            reps: row.get("reps"),
+            target_date: row.get("target_date"),
+            created_at: row.get("created_at"),
+            done_at: row.get("done_at"),
+        })
+        .collect::<Vec<_>>();
+
+    // Fetch body measurements
+    let measurements = query("SELECT * FROM measurements ORDER BY date, site")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| Measurement {
+            date: row.get("date"),
+            site: row.get("site"),
+            value: row.get("value"),
+            updated_at: row.get("updated_at"),
+        })
+        .collect::<Vec<_>>();
+
+    // Fetch the progress photo index
+    let progress_photos = query("SELECT * FROM progress_photos ORDER BY id")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| ProgressPhoto {
+            id: row.get("id"),
+            date: row.get("date"),
+            tag: row.get("tag"),
+            path: row.get("path"),
+            created_at: row.get("created_at"),
+        })
+        .collect::<Vec<_>>();
+
+    // Fetch points snapshots
+    let points_history = query("SELECT * FROM points_history ORDER BY date")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| PointsSnapshot {
+            date: row.get("date"),
+            bodyweight: row.get("bodyweight"),
+            total: row.get("total"),
+            wilks: row.get("wilks"),
+            dots: row.get("dots"),
+            ipf_gl: row.get("ipf_gl"),
+        })
+        .collect::<Vec<_>>();
+
+    // Fetch exercise aliases
+    let exercise_aliases = query("SELECT exercise_id, alias FROM exercise_aliases ORDER BY exercise_id, alias")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| ExerciseAlias {
+            exercise_id: row.get("exercise_id"),
+            alias: row.get("alias"),
+        })
+        .collect::<Vec<_>>();
+
+    // Fetch warm-up/back-off targets of session exercises
+    let session_set_targets = query("SELECT * FROM session_set_targets ORDER BY id")
+        .fetch_all(pool)
+        .await?
+        .into_iter()
+        .map(|row| SetTarget {
+            id: row.get("id"),
+            session_exercise_id: row.get("session_exercise_id"),
+            kind: row.get("kind"),
+            position: row.get("position"),
+            percent: row.get("percent"),
+            reps: row.get("reps"),
+        })
+        .collect::<Vec<_>>();
+
     // Create the final dump structure
-    let dump = DatabaseDump {
+    let mut dump = DatabaseDump {
         exercises,
         programs,
         sessions,
         personal_records,
+        equipment_settings,
+        gyms,
+        program_progress,
+        rest_days,
+        recovery,
+        nutrition,
+        goals,
+        measurements,
+        progress_photos,
+        points_history,
+        exercise_aliases,
+        session_set_targets,
     };
+    if canonical {
+        for ex in &mut dump.exercises {
+            ex.estimated_one_rm = None;
+            ex.current_pr_date = None;
+        }
+        // Tested maxes can't be rebuilt, so they stay.
+        dump.personal_records.retain(|pr| pr.verified);
+    }
+    if anonymize {
+        anonymize_dump(&mut dump);
+    }
 
     // Write to file
     let toml_string = toml::to_string_pretty(&dump)?;
@@ -467,73 +1130,521 @@ async fn export_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
     Ok(())
 }
 
-async fn import_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
-    // Read and parse the TOML file
-    let toml_str = fs::read_to_string(file_path)?;
-    let dump: DatabaseDump = toml::from_str(&toml_str)?;
-
-    // Start a transaction
-    let mut tx = pool.begin().await?;
+/// Stable stand-ins for ids: each original id gets `<kind>-<n>` the first time
+/// it's seen, so references between tables still line up.
+#[derive(Default)]
+struct IdMap {
+    ids: HashMap<String, String>,
+    counts: HashMap<&'static str, usize>,
+}
 
-    // Import exercises
-    for ex in dump.exercises {
-        query(
-            r#"
-            INSERT OR REPLACE INTO exercises 
-            (id, name, primary_muscle, description, created_at, estimated_one_rm, current_pr_date)
-            VALUES (?, ?, ?, ?, ?, ?, ?)
-            "#
-        )
-        .bind(&ex.id)
-        .bind(&ex.name)
-        .bind(&ex.primary_muscle)
-        .bind(&ex.description)
-        .bind(&ex.created_at)
-        .bind(ex.estimated_one_rm)
-        .bind(&ex.current_pr_date)
-        .execute(&mut *tx)
-        .await?;
+impl IdMap {
+    fn get(&mut self, kind: &'static str, id: &str) -> String {
+        if let Some(new) = self.ids.get(id) {
+            return new.clone();
+        }
+        let n = self.counts.entry(kind).or_default();
+        *n += 1;
+        let new = format!("{}-{}", kind, n);
+        self.ids.insert(id.to_string(), new.clone());
+        new
     }
+}
 
-    // Import programs with their blocks and exercises
-    for prog in dump.programs {
-        // Insert program
-        query(
-            r#"
-            INSERT OR REPLACE INTO programs (id, name, description, created_at)
-            VALUES (?, ?, ?, ?)
-            "#
-        )
-        .bind(&prog.id)
-        .bind(&prog.name)
-        .bind(&prog.description)
-        .bind(&prog.created_at)
-        .execute(&mut *tx)
-        .await?;
+/// `path` renamed after `id`, keeping only its extension: media paths are
+/// absolute, so they hold the home directory and user name.
+fn anonymous_path(id: &str, path: &str) -> String {
+    let ext = std::path::Path::new(path)
+        .extension()
+        .map(|e| format!(".{}", e.to_string_lossy()))
+        .unwrap_or_default();
+    format!("{}{}", id, ext)
+}
 
-        // Insert blocks
-        for block in prog.blocks {
-            query(
-                r#"
-                INSERT OR REPLACE INTO program_blocks (id, program_id, name, description)
-                VALUES (?, ?, ?, ?)
-                "#
-            )
+/// Strip free text (notes, descriptions, cues, reasons, gym names, media paths),
+/// replace session tags and checklist items with stand-ins, and replace
+/// every id, keeping names of exercises/programs, dates and numbers.
+fn anonymize_dump(dump: &mut DatabaseDump) {
+    let mut ids = IdMap::default();
+    // Tags and checklist items compare case-insensitively, so "Cut" and
+    // "cut" stay one label.
+    let mut labels = IdMap::default();
+
+    for ex in &mut dump.exercises {
+        ex.id = ids.get("exercise", &ex.id);
+        ex.description = None;
+        ex.cues = None;
+        ex.demo_path = ex.demo_path.as_deref().map(|path| anonymous_path(&ex.id, path));
+    }
+    for prog in &mut dump.programs {
+        prog.id = ids.get("program", &prog.id);
+        prog.description = None;
+        for block in &mut prog.blocks {
+            block.id = ids.get("block", &block.id);
+            block.description = None;
+            for ex in block.exercises.iter_mut().chain(&mut block.versions) {
+                ex.id = ids.get("program-exercise", &ex.id);
+                ex.exercise_id = ids.get("exercise", &ex.exercise_id);
+                ex.notes = None;
+            }
+            for circuit in &mut block.circuits {
+                circuit.id = ids.get("circuit", &circuit.id);
+                for m in &mut circuit.movements {
+                    m.exercise_id = ids.get("exercise", &m.exercise_id);
+                }
+            }
+        }
+    }
+    for sess in &mut dump.sessions {
+        sess.id = ids.get("session", &sess.id);
+        sess.program_block_id = ids.get("block", &sess.program_block_id);
+        sess.notes = None;
+        sess.tag = sess.tag.as_deref().map(|t| labels.get("tag", &t.to_lowercase()));
+        for t in &mut sess.tags {
+            *t = labels.get("tag", &t.to_lowercase());
+        }
+        for (item, _) in &mut sess.checklist {
+            *item = labels.get("item", &format!("item:{}", item.to_lowercase()));
+        }
+        for round in &mut sess.circuit_rounds {
+            round.id = ids.get("circuit-round", &round.id);
+            round.circuit_id = ids.get("circuit", &round.circuit_id);
+        }
+        for ex in &mut sess.exercises {
+            ex.id = ids.get("session-exercise", &ex.id);
+            ex.exercise_id = ids.get("exercise", &ex.exercise_id);
+            ex.rotated_from = ex.rotated_from.as_deref().map(|id| ids.get("exercise", id));
+            ex.notes = None;
+            for set in &mut ex.sets {
+                set.id = ids.get("set", &set.id);
+                set.circuit_round_id = set.circuit_round_id.as_deref().map(|id| ids.get("circuit-round", id));
+                set.notes = None;
+                set.attachments.clear();
+            }
+        }
+    }
+    for pr in &mut dump.personal_records {
+        pr.exercise_id = ids.get("exercise", &pr.exercise_id);
+    }
+    for alias in &mut dump.exercise_aliases {
+        alias.exercise_id = ids.get("exercise", &alias.exercise_id);
+    }
+    for target in &mut dump.session_set_targets {
+        target.session_exercise_id = ids.get("session-exercise", &target.session_exercise_id);
+    }
+    for setting in &mut dump.equipment_settings {
+        setting.exercise_id = ids.get("exercise", &setting.exercise_id);
+    }
+    for (i, gym) in dump.gyms.iter_mut().enumerate() {
+        gym.id = ids.get("gym", &gym.id);
+        gym.name = format!("Gym {}", i + 1);
+    }
+    for progress in &mut dump.program_progress {
+        progress.program_id = ids.get("program", &progress.program_id);
+    }
+    for day in &mut dump.rest_days {
+        day.reason = None;
+    }
+    for goal in &mut dump.goals {
+        goal.id = ids.get("goal", &goal.id);
+        goal.exercise_id = ids.get("exercise", &goal.exercise_id);
+    }
+    for photo in &mut dump.progress_photos {
+        photo.id = ids.get("photo", &photo.id);
+        photo.path = anonymous_path(&photo.id, &photo.path);
+    }
+}
+
+impl DatabaseDump {
+    /// Drop every table not in `tables`.
+    fn keep_only(&mut self, tables: &[DumpTable]) {
+        let keep = |t: DumpTable| tables.contains(&t);
+        if !keep(DumpTable::Exercises) {
+            self.exercises.clear();
+        }
+        if !keep(DumpTable::Programs) {
+            self.programs.clear();
+        }
+        if !keep(DumpTable::Sessions) {
+            self.sessions.clear();
+        }
+        if !keep(DumpTable::PersonalRecords) {
+            self.personal_records.clear();
+        }
+        if !keep(DumpTable::EquipmentSettings) {
+            self.equipment_settings.clear();
+        }
+        if !keep(DumpTable::Gyms) {
+            self.gyms.clear();
+        }
+        if !keep(DumpTable::ProgramProgress) {
+            self.program_progress.clear();
+        }
+        if !keep(DumpTable::RestDays) {
+            self.rest_days.clear();
+        }
+        if !keep(DumpTable::Recovery) {
+            self.recovery.clear();
+        }
+        if !keep(DumpTable::Nutrition) {
+            self.nutrition.clear();
+        }
+        if !keep(DumpTable::Goals) {
+            self.goals.clear();
+        }
+        if !keep(DumpTable::Measurements) {
+            self.measurements.clear();
+        }
+        if !keep(DumpTable::ProgressPhotos) {
+            self.progress_photos.clear();
+        }
+        if !keep(DumpTable::PointsHistory) {
+            self.points_history.clear();
+        }
+        if !keep(DumpTable::ExerciseAliases) {
+            self.exercise_aliases.clear();
+        }
+        if !keep(DumpTable::SessionSetTargets) {
+            self.session_set_targets.clear();
+        }
+    }
+
+    /// Rows an import goes through: a program counts once with its blocks, a
+    /// session once plus one per set.
+    fn rows(&self) -> usize {
+        let sets: usize = self.sessions.iter().flat_map(|s| &s.exercises).map(|ex| ex.sets.len()).sum();
+        self.exercises.len()
+            + self.programs.len()
+            + self.sessions.len()
+            + sets
+            + self.personal_records.len()
+            + self.equipment_settings.len()
+            + self.gyms.len()
+            + self.program_progress.len()
+            + self.rest_days.len()
+            + self.recovery.len()
+            + self.nutrition.len()
+            + self.goals.len()
+            + self.measurements.len()
+            + self.progress_photos.len()
+            + self.points_history.len()
+            + self.exercise_aliases.len()
+            + self.session_set_targets.len()
+    }
+}
+
+/// Import progress on stderr, when that's a terminal: a bar, the row count
+/// and rows per second.
+struct Progress {
+    total: usize,
+    done: usize,
+    started: Instant,
+    drawn: Option<Instant>,
+    visible: bool,
+}
+
+impl Progress {
+    const WIDTH: usize = 30;
+    const REDRAW: Duration = Duration::from_millis(100);
+
+    fn new(total: usize) -> Self {
+        Self {
+            total,
+            done: 0,
+            started: Instant::now(),
+            drawn: None,
+            visible: total > 0 && !ui::quiet() && std::io::stderr().is_terminal(),
+        }
+    }
+
+    fn add(&mut self, rows: usize) {
+        self.done += rows;
+        if self.visible && self.drawn.is_none_or(|at| at.elapsed() >= Self::REDRAW) {
+            self.draw();
+        }
+    }
+
+    fn rate(&self) -> f64 {
+        self.done as f64 / self.started.elapsed().as_secs_f64().max(0.001)
+    }
+
+    fn draw(&mut self) {
+        let filled = Self::WIDTH * self.done.min(self.total) / self.total;
+        let bar = format!("[{}{}]", "#".repeat(filled), "-".repeat(Self::WIDTH - filled));
+        eprint!(
+            "\r{} {} {}",
+            bar.as_str().accent(),
+            tf("{}/{} rows", &[&self.done, &self.total]),
+            tf("({} rows/s)", &[&format!("{:.0}", self.rate())]).dimmed()
+        );
+        let _ = std::io::stderr().flush();
+        self.drawn = Some(Instant::now());
+    }
+
+    fn finish(&mut self) {
+        if self.visible {
+            self.draw();
+            eprintln!();
+        }
+    }
+}
+
+/// Rows per multi-row `INSERT` of sets; at 21 columns that's well under
+/// SQLite's limit on bound parameters.
+const SET_BATCH: usize = 200;
+
+/// Insert `(session exercise id, set)` pairs with one statement, then their
+/// attachments.
+async fn insert_sets(conn: &mut sqlx::SqliteConnection, sets: &[(String, ExerciseSet)]) -> Result<()> {
+    if sets.is_empty() {
+        return Ok(());
+    }
+    let row = format!("({})", ["?"; 21].join(", "));
+    let sql = format!(
+        r#"
+        INSERT INTO exercise_sets
+        (id, session_exercise_id, weight, reps, rpe, rm_percent, notes,
+         timestamp, ignore_for_one_rm, bodyweight, tempo, pause, amrap,
+         band, band_tension, chain_weight, body_mass, side, status, circuit_round_id, flags)
+        VALUES {}
+        ON CONFLICT (id) DO UPDATE SET
+            session_exercise_id = excluded.session_exercise_id, weight = excluded.weight, reps = excluded.reps,
+            rpe = excluded.rpe, rm_percent = excluded.rm_percent, notes = excluded.notes,
+            timestamp = excluded.timestamp, ignore_for_one_rm = excluded.ignore_for_one_rm,
+            bodyweight = excluded.bodyweight, tempo = excluded.tempo, pause = excluded.pause,
+            amrap = excluded.amrap, band = excluded.band, band_tension = excluded.band_tension,
+            chain_weight = excluded.chain_weight, body_mass = excluded.body_mass, side = excluded.side,
+            status = excluded.status, circuit_round_id = excluded.circuit_round_id, flags = excluded.flags
+        "#,
+        vec![row; sets.len()].join(", ")
+    );
+
+    let mut q = query(&sql);
+    for (session_exercise_id, set) in sets {
+        q = q
+            .bind(&set.id)
+            .bind(session_exercise_id)
+            .bind(set.weight)
+            .bind(set.reps)
+            .bind(set.rpe)
+            .bind(set.rm_percent)
+            .bind(&set.notes)
+            .bind(&set.timestamp)
+            .bind(set.ignore_for_one_rm as i32)
+            .bind(set.bodyweight as i32)
+            .bind(&set.tempo)
+            .bind(&set.pause)
+            .bind(set.amrap as i32)
+            .bind(&set.band)
+            .bind(set.band_tension)
+            .bind(set.chain_weight)
+            .bind(set.body_mass)
+            .bind(&set.side)
+            .bind(set.status.as_deref().unwrap_or("completed"))
+            .bind(&set.circuit_round_id)
+            .bind(&set.flags);
+    }
+    q.execute(&mut *conn).await?;
+
+    for (_, set) in sets {
+        insert_attachments(conn, &set.id, &set.attachments).await?;
+    }
+    Ok(())
+}
+
+async fn insert_prs(conn: &mut sqlx::SqliteConnection, prs: &[PersonalRecord]) -> Result<()> {
+    for pr in prs {
+        query(
+            r#"
+            INSERT OR REPLACE INTO personal_records
+            (exercise_id, date, weight, reps, estimated_1rm, verified)
+            VALUES (?, ?, ?, ?, ?, ?)
+            "#
+        )
+        .bind(&pr.exercise_id)
+        .bind(&pr.date)
+        .bind(pr.weight)
+        .bind(pr.reps)
+        .bind(pr.estimated_1rm)
+        .bind(pr.verified as i32)
+        .execute(&mut *conn)
+        .await?;
+    }
+    Ok(())
+}
+
+/// Attachments of a set that already exists in the database.
+async fn insert_attachments(conn: &mut sqlx::SqliteConnection, set_id: &str, paths: &[String]) -> Result<()> {
+    for path in paths {
+        query("INSERT OR IGNORE INTO set_attachments (exercise_set_id, path) VALUES (?, ?)")
+            .bind(set_id)
+            .bind(path)
+            .execute(&mut *conn)
+            .await?;
+    }
+    Ok(())
+}
+
+/// Import a dump in one transaction, so the database keeps serving the old
+/// data until it commits. Returns how many rows were imported.
+/// Rows written while this is set keep the `valid_from` they were exported
+/// with instead of counting as program edits (see migration 0041).
+async fn mark_import(conn: &mut sqlx::SqliteConnection, on: bool) -> Result<()> {
+    let sql = if on {
+        "INSERT OR IGNORE INTO import_in_progress (id) VALUES (1)"
+    } else {
+        "DELETE FROM import_in_progress"
+    };
+    query(sql).execute(conn).await?;
+    Ok(())
+}
+
+async fn import_db(pool: &SqlitePool, file_path: &str, tables: &[DumpTable]) -> Result<usize> {
+    // Read and parse the TOML file
+    let toml_str = fs::read_to_string(file_path)?;
+    let mut dump: DatabaseDump = toml::from_str(&toml_str)?;
+    if !tables.is_empty() {
+        dump.keep_only(tables);
+    }
+    let mut progress = Progress::new(dump.rows());
+
+    // Start a transaction
+    let mut tx = pool.begin().await?;
+    mark_import(&mut *tx, true).await?;
+
+    // Import exercises. Parent rows here and below are upserted: a REPLACE
+    // deletes the old row first, which cascades to aliases, PRs, sets and the
+    // rest, selected in `--tables` or not.
+    for ex in dump.exercises {
+        query(
+            r#"
+            INSERT INTO exercises
+            (id, name, primary_muscle, description, created_at, estimated_one_rm, current_pr_date,
+             unilateral, equipment, rounding, demo_path, cues)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+            ON CONFLICT (id) DO UPDATE SET
+                name = excluded.name, primary_muscle = excluded.primary_muscle, description = excluded.description,
+                created_at = excluded.created_at, estimated_one_rm = excluded.estimated_one_rm,
+                current_pr_date = excluded.current_pr_date, unilateral = excluded.unilateral,
+                equipment = excluded.equipment, rounding = excluded.rounding, demo_path = excluded.demo_path,
+                cues = excluded.cues
+            "#
+        )
+        .bind(&ex.id)
+        .bind(&ex.name)
+        .bind(&ex.primary_muscle)
+        .bind(&ex.description)
+        .bind(&ex.created_at)
+        .bind(ex.estimated_one_rm)
+        .bind(&ex.current_pr_date)
+        .bind(ex.unilateral as i32)
+        .bind(&ex.equipment)
+        .bind(&ex.rounding)
+        .bind(&ex.demo_path)
+        .bind(&ex.cues)
+        .execute(&mut *tx)
+        .await?;
+        progress.add(1);
+    }
+
+    // Import programs with their blocks and exercises
+    for prog in dump.programs {
+        progress.add(1);
+        // Insert program. An upsert, not a REPLACE: deleting the old row
+        // would cascade to its blocks, which sessions may still point at.
+        query(
+            r#"
+            INSERT INTO programs (id, name, description, created_at, off_weeks, archived_at)
+            VALUES (?, ?, ?, ?, ?, ?)
+            ON CONFLICT (id) DO UPDATE SET
+                name = excluded.name, description = excluded.description, created_at = excluded.created_at,
+                off_weeks = excluded.off_weeks, archived_at = excluded.archived_at
+            "#
+        )
+        .bind(&prog.id)
+        .bind(&prog.name)
+        .bind(&prog.description)
+        .bind(&prog.created_at)
+        .bind(&prog.off_weeks)
+        .bind(&prog.archived_at)
+        .execute(&mut *tx)
+        .await?;
+
+        for sub in &prog.substitutions {
+            query("INSERT OR REPLACE INTO program_substitutions (program_id, group_name, exercises) VALUES (?, ?, ?)")
+                .bind(&prog.id)
+                .bind(&sub.group)
+                .bind(&sub.exercises)
+                .execute(&mut *tx)
+                .await?;
+        }
+
+        // Insert blocks
+        for block in prog.blocks {
+            query(
+                r#"
+                INSERT INTO program_blocks (id, program_id, name, description, expected_minutes, week, pool_choose)
+                VALUES (?, ?, ?, ?, ?, ?, ?)
+                ON CONFLICT (id) DO UPDATE SET
+                    program_id = excluded.program_id, name = excluded.name, description = excluded.description,
+                    expected_minutes = excluded.expected_minutes, week = excluded.week,
+                    pool_choose = excluded.pool_choose
+                "#
+            )
             .bind(&block.id)
             .bind(&prog.id)
             .bind(&block.name)
             .bind(&block.description)
+            .bind(block.expected_minutes)
+            .bind(block.week)
+            .bind(block.pool_choose)
             .execute(&mut *tx)
             .await?;
 
+            for c in &block.circuits {
+                query(
+                    r#"
+                    INSERT INTO program_circuits (id, program_block_id, position, kind, rounds, interval_secs)
+                    VALUES (?, ?, ?, ?, ?, ?)
+                    ON CONFLICT (id) DO UPDATE SET
+                        program_block_id = excluded.program_block_id, position = excluded.position, kind = excluded.kind,
+                        rounds = excluded.rounds, interval_secs = excluded.interval_secs
+                    "#
+                )
+                .bind(&c.id)
+                .bind(&block.id)
+                .bind(c.position)
+                .bind(&c.kind)
+                .bind(c.rounds)
+                .bind(c.interval_secs)
+                .execute(&mut *tx)
+                .await?;
+                for m in &c.movements {
+                    query(
+                        r#"
+                        INSERT OR REPLACE INTO program_circuit_movements (circuit_id, position, exercise_id, reps, weight)
+                        VALUES (?, ?, ?, ?, ?)
+                        "#
+                    )
+                    .bind(&c.id)
+                    .bind(m.position)
+                    .bind(&m.exercise_id)
+                    .bind(m.reps)
+                    .bind(m.weight)
+                    .execute(&mut *tx)
+                    .await?;
+                }
+            }
+
             // Insert program exercises
             for ex in block.exercises {
                 query(
                     r#"
                     INSERT OR REPLACE INTO program_exercises 
                     (id, program_block_id, exercise_id, sets, reps, target_rpe, 
-                     target_rm_percent, notes, program_1rm, technique, technique_group, order_index)
-                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                     target_rm_percent, notes, program_1rm, technique, technique_group, order_index,
+                     tempo, pause, options, warmup, backoff, superset, rotate_weeks, rest_secs, pool, valid_from)
+                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
                     "#
                 )
                 .bind(&ex.id)
@@ -548,6 +1659,54 @@ async fn import_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
                 .bind(&ex.technique)
                 .bind(ex.technique_group)
                 .bind(ex.order_index)
+                .bind(&ex.tempo)
+                .bind(&ex.pause)
+                .bind(&ex.options)
+                .bind(&ex.warmup)
+                .bind(&ex.backoff)
+                .bind(&ex.superset)
+                .bind(ex.rotate_weeks)
+                .bind(ex.rest_secs)
+                .bind(ex.pool)
+                .bind(&ex.valid_from)
+                .execute(&mut *tx)
+                .await?;
+            }
+
+            for v in block.versions {
+                query(
+                    r#"
+                    INSERT OR REPLACE INTO program_exercise_versions
+                    (program_exercise_id, program_block_id, exercise_id, sets, reps, target_rpe,
+                     target_rm_percent, notes, program_1rm, technique, technique_group, order_index,
+                     tempo, pause, options, warmup, backoff, superset, rotate_weeks, rest_secs, pool,
+                     valid_from, valid_to)
+                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                    "#
+                )
+                .bind(&v.id)
+                .bind(&block.id)
+                .bind(&v.exercise_id)
+                .bind(v.sets)
+                .bind(&v.reps)
+                .bind(&v.target_rpe)
+                .bind(&v.target_rm_percent)
+                .bind(&v.notes)
+                .bind(v.program_1rm)
+                .bind(&v.technique)
+                .bind(v.technique_group)
+                .bind(v.order_index)
+                .bind(&v.tempo)
+                .bind(&v.pause)
+                .bind(&v.options)
+                .bind(&v.warmup)
+                .bind(&v.backoff)
+                .bind(&v.superset)
+                .bind(v.rotate_weeks)
+                .bind(v.rest_secs)
+                .bind(v.pool)
+                .bind(&v.valid_from)
+                .bind(&v.valid_to)
                 .execute(&mut *tx)
                 .await?;
             }
@@ -555,13 +1714,19 @@ async fn import_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
     }
 
     // Import sessions with their exercises and sets
+    let mut pending_sets = Vec::with_capacity(SET_BATCH);
     for sess in dump.sessions {
+        let rows = 1 + sess.exercises.iter().map(|ex| ex.sets.len()).sum::<usize>();
+
         // Insert session
         query(
             r#"
-            INSERT OR REPLACE INTO training_sessions 
-            (id, program_block_id, start_time, end_time, notes)
-            VALUES (?, ?, ?, ?, ?)
+            INSERT INTO training_sessions
+            (id, program_block_id, start_time, end_time, notes, tag)
+            VALUES (?, ?, ?, ?, ?, ?)
+            ON CONFLICT (id) DO UPDATE SET
+                program_block_id = excluded.program_block_id, start_time = excluded.start_time,
+                end_time = excluded.end_time, notes = excluded.notes, tag = excluded.tag
             "#
         )
         .bind(&sess.id)
@@ -569,121 +1734,326 @@ async fn import_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
         .bind(&sess.start_time)
         .bind(&sess.end_time)
         .bind(&sess.notes)
+        .bind(&sess.tag)
         .execute(&mut *tx)
         .await?;
 
+        // The dump holds all of the session's pauses.
+        query("DELETE FROM session_pauses WHERE training_session_id = ?")
+            .bind(&sess.id)
+            .execute(&mut *tx)
+            .await?;
+        for pause in &sess.pauses {
+            query("INSERT INTO session_pauses (training_session_id, paused_at, resumed_at) VALUES (?, ?, ?)")
+                .bind(&sess.id)
+                .bind(&pause.paused_at)
+                .bind(&pause.resumed_at)
+                .execute(&mut *tx)
+                .await?;
+        }
+
+        for tag in &sess.tags {
+            query("INSERT OR REPLACE INTO session_tags (training_session_id, tag) VALUES (?, ?)")
+                .bind(&sess.id)
+                .bind(tag)
+                .execute(&mut *tx)
+                .await?;
+        }
+
+        for (item, checked) in &sess.checklist {
+            query("INSERT OR REPLACE INTO session_checklist (training_session_id, item, checked) VALUES (?, ?, ?)")
+                .bind(&sess.id)
+                .bind(item)
+                .bind(checked)
+                .execute(&mut *tx)
+                .await?;
+        }
+
+        for r in &sess.circuit_rounds {
+            query(
+                r#"
+                INSERT OR REPLACE INTO circuit_rounds (id, training_session_id, circuit_id, round, started_at, finished_at)
+                VALUES (?, ?, ?, ?, ?, ?)
+                "#
+            )
+            .bind(&r.id)
+            .bind(&sess.id)
+            .bind(&r.circuit_id)
+            .bind(r.round)
+            .bind(&r.started_at)
+            .bind(&r.finished_at)
+            .execute(&mut *tx)
+            .await?;
+        }
+
         // Insert session exercises and their sets
         for ex in sess.exercises {
             query(
                 r#"
-                INSERT OR REPLACE INTO training_session_exercises
-                (id, training_session_id, exercise_id, notes)
-                VALUES (?, ?, ?, ?)
+                INSERT INTO training_session_exercises
+                (id, training_session_id, exercise_id, notes, rotated_from, swapped)
+                VALUES (?, ?, ?, ?, ?, ?)
+                ON CONFLICT (id) DO UPDATE SET
+                    training_session_id = excluded.training_session_id, exercise_id = excluded.exercise_id,
+                    notes = excluded.notes, rotated_from = excluded.rotated_from, swapped = excluded.swapped
                 "#
             )
             .bind(&ex.id)
             .bind(&sess.id)
             .bind(&ex.exercise_id)
             .bind(&ex.notes)
+            .bind(&ex.rotated_from)
+            .bind(ex.swapped)
             .execute(&mut *tx)
             .await?;
 
-            // Insert sets
+            // Sets go in batches, the exercise they belong to is already in.
             for set in ex.sets {
-                query(
-                    r#"
-                    INSERT OR REPLACE INTO exercise_sets
-                    (id, session_exercise_id, weight, reps, rpe, rm_percent, notes,
-                     timestamp, ignore_for_one_rm, bodyweight)
-                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-                    "#
-                )
-                .bind(&set.id)
-                .bind(&ex.id)
-                .bind(set.weight)
-                .bind(set.reps)
-                .bind(set.rpe)
-                .bind(set.rm_percent)
-                .bind(&set.notes)
-                .bind(&set.timestamp)
-                .bind(set.ignore_for_one_rm as i32)
-                .bind(set.bodyweight as i32)
-                .execute(&mut *tx)
-                .await?;
+                pending_sets.push((ex.id.clone(), set));
+                if pending_sets.len() == SET_BATCH {
+                    insert_sets(&mut *tx, &pending_sets).await?;
+                    pending_sets.clear();
+                }
             }
         }
+        progress.add(rows);
     }
+    insert_sets(&mut *tx, &pending_sets).await?;
 
-    // Import personal records if there are any in the dump
-    if !dump.personal_records.is_empty() {
-        for pr in dump.personal_records {
-            query(
-                r#"
-                INSERT OR REPLACE INTO personal_records
-                (exercise_id, date, weight, reps, estimated_1rm)
-                VALUES (?, ?, ?, ?, ?)
-                "#
-            )
-            .bind(&pr.exercise_id)
-            .bind(&pr.date)
-            .bind(pr.weight)
-            .bind(pr.reps)
-            .bind(pr.estimated_1rm)
-            .execute(&mut *tx)
-            .await?;
-        }
-    } else {
-        // If no PRs in the dump, calculate them from session sets
-        // First, clear any existing PRs
-        query("DELETE FROM personal_records")
-            .execute(&mut *tx)
-            .await?;
+    progress.add(dump.equipment_settings.len());
+    for setting in dump.equipment_settings {
+        query(
+            r#"
+            INSERT OR REPLACE INTO equipment_settings
+            (exercise_id, key, value, updated_at)
+            VALUES (?, ?, ?, ?)
+            "#
+        )
+        .bind(&setting.exercise_id)
+        .bind(&setting.key)
+        .bind(&setting.value)
+        .bind(&setting.updated_at)
+        .execute(&mut *tx)
+        .await?;
+    }
 
-        // Insert daily PRs - one best set per exercise per day
+    progress.add(dump.gyms.len());
+    for gym in dump.gyms {
         query(
             r#"
-            INSERT INTO personal_records
-                  (exercise_id, date, weight, reps, estimated_1rm)
-            WITH ranked AS (
-                SELECT
-                    e.id                             AS exercise_id,
-                    date(ts.start_time)              AS day,
-                    es.weight                        AS weight,
-                    es.reps                          AS reps,
-                    es.weight * (1.0 + es.reps/30.0) AS estimated_1rm,
-                    ROW_NUMBER() OVER (
-                        PARTITION BY e.id, date(ts.start_time)
-                        ORDER BY es.weight * (1.0 + es.reps/30.0) DESC
-                    ) AS rn
-                FROM   exercise_sets es
-                JOIN   training_session_exercises tse ON tse.id = es.session_exercise_id
-                JOIN   training_sessions         ts  ON ts.id  = tse.training_session_id
-                JOIN   exercises                 e   ON e.id   = tse.exercise_id
-                WHERE  es.weight > 0
-                  AND  es.ignore_for_one_rm = 0
-            )
-            SELECT exercise_id, day, weight, reps, estimated_1rm
-            FROM   ranked
-            WHERE  rn = 1
+            INSERT OR REPLACE INTO gyms
+            (id, name, barbell, dumbbells, dumbbell_min, dumbbell_max,
+             machines, cables, kettlebells, created_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
             "#
         )
+        .bind(&gym.id)
+        .bind(&gym.name)
+        .bind(gym.barbell as i32)
+        .bind(gym.dumbbells as i32)
+        .bind(gym.dumbbell_min)
+        .bind(gym.dumbbell_max)
+        .bind(gym.machines as i32)
+        .bind(gym.cables as i32)
+        .bind(gym.kettlebells as i32)
+        .bind(&gym.created_at)
         .execute(&mut *tx)
         .await?;
+    }
 
-        // Find all-time PR for each exercise
-        let exercise_prs = query(
+    progress.add(dump.program_progress.len());
+    for progress in dump.program_progress {
+        query(
             r#"
-            WITH all_sets AS (
-                SELECT
-                    e.id AS exercise_id,
-                    e.name AS exercise_name,
-                    es.weight,
-                    es.reps,
-                    es.weight * (1.0 + es.reps/30.0) AS estimated_1rm,
-                    date(ts.start_time) AS date
-                FROM exercise_sets es
-                JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
-                JOIN training_sessions ts ON ts.id = tse.training_session_id
+            INSERT OR REPLACE INTO program_progress
+            (program_id, current_week, week_started_at)
+            VALUES (?, ?, ?)
+            "#
+        )
+        .bind(&progress.program_id)
+        .bind(progress.current_week)
+        .bind(&progress.week_started_at)
+        .execute(&mut *tx)
+        .await?;
+    }
+
+    progress.add(dump.rest_days.len());
+    for rest in dump.rest_days {
+        query("INSERT OR REPLACE INTO rest_days (date, reason, created_at) VALUES (?, ?, ?)")
+            .bind(&rest.date)
+            .bind(&rest.reason)
+            .bind(&rest.created_at)
+            .execute(&mut *tx)
+            .await?;
+    }
+
+    progress.add(dump.recovery.len());
+    for day in dump.recovery {
+        query("INSERT OR REPLACE INTO recovery (date, sleep_minutes, hrv, source, imported_at) VALUES (?, ?, ?, ?, ?)")
+            .bind(&day.date)
+            .bind(day.sleep_minutes)
+            .bind(day.hrv)
+            .bind(&day.source)
+            .bind(&day.imported_at)
+            .execute(&mut *tx)
+            .await?;
+    }
+
+    progress.add(dump.nutrition.len());
+    for day in dump.nutrition {
+        query("INSERT OR REPLACE INTO nutrition (date, calories, protein, updated_at) VALUES (?, ?, ?, ?)")
+            .bind(&day.date)
+            .bind(day.calories)
+            .bind(day.protein)
+            .bind(&day.updated_at)
+            .execute(&mut *tx)
+            .await?;
+    }
+
+    progress.add(dump.measurements.len());
+    for m in dump.measurements {
+        query("INSERT OR REPLACE INTO measurements (date, site, value, updated_at) VALUES (?, ?, ?, ?)")
+            .bind(&m.date)
+            .bind(&m.site)
+            .bind(m.value)
+            .bind(&m.updated_at)
+            .execute(&mut *tx)
+            .await?;
+    }
+
+    progress.add(dump.progress_photos.len());
+    for photo in dump.progress_photos {
+        query("INSERT OR REPLACE INTO progress_photos (id, date, tag, path, created_at) VALUES (?, ?, ?, ?, ?)")
+            .bind(&photo.id)
+            .bind(&photo.date)
+            .bind(&photo.tag)
+            .bind(&photo.path)
+            .bind(&photo.created_at)
+            .execute(&mut *tx)
+            .await?;
+    }
+
+    progress.add(dump.points_history.len());
+    for s in dump.points_history {
+        query(
+            "INSERT OR REPLACE INTO points_history (date, bodyweight, total, wilks, dots, ipf_gl) VALUES (?, ?, ?, ?, ?, ?)",
+        )
+        .bind(&s.date)
+        .bind(s.bodyweight)
+        .bind(s.total)
+        .bind(s.wilks)
+        .bind(s.dots)
+        .bind(s.ipf_gl)
+        .execute(&mut *tx)
+        .await?;
+    }
+
+    progress.add(dump.goals.len());
+    for goal in dump.goals {
+        query(
+            r#"
+            INSERT OR REPLACE INTO goals (id, exercise_id, weight, reps, target_date, created_at, done_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?)
+            "#
+        )
+        .bind(&goal.id)
+        .bind(&goal.exercise_id)
+        .bind(goal.weight)
+        .bind(goal.reps)
+        .bind(&goal.target_date)
+        .bind(&goal.created_at)
+        .bind(&goal.done_at)
+        .execute(&mut *tx)
+        .await?;
+    }
+
+    progress.add(dump.exercise_aliases.len());
+    for alias in dump.exercise_aliases {
+        query("INSERT OR REPLACE INTO exercise_aliases (exercise_id, alias) VALUES (?, ?)")
+            .bind(&alias.exercise_id)
+            .bind(&alias.alias)
+            .execute(&mut *tx)
+            .await?;
+    }
+
+    progress.add(dump.session_set_targets.len());
+    for t in dump.session_set_targets {
+        query(
+            r#"
+            INSERT OR REPLACE INTO session_set_targets (id, session_exercise_id, kind, position, percent, reps)
+            VALUES (?, ?, ?, ?, ?, ?)
+            "#
+        )
+        .bind(t.id)
+        .bind(&t.session_exercise_id)
+        .bind(&t.kind)
+        .bind(t.position)
+        .bind(t.percent)
+        .bind(&t.reps)
+        .execute(&mut *tx)
+        .await?;
+    }
+
+    // Import personal records if there are any in the dump
+    if !tables.is_empty() && !tables.contains(&DumpTable::PersonalRecords) {
+        // Left as they are: every parent row above is upserted, so nothing
+        // cascades to them.
+    } else if dump.personal_records.iter().any(|pr| !pr.verified) {
+        progress.add(dump.personal_records.len());
+        insert_prs(&mut *tx, &dump.personal_records).await?;
+    } else {
+        // If no estimated PRs in the dump (none at all, or only the tested
+        // ones of a `--canonical` dump), calculate them from session sets
+        // First, clear any existing PRs
+        query("DELETE FROM personal_records")
+            .execute(&mut *tx)
+            .await?;
+
+        // Insert daily PRs - one best set per exercise per day
+        query(
+            &format!(r#"
+            INSERT INTO personal_records
+                  (exercise_id, date, weight, reps, estimated_1rm)
+            WITH ranked AS (
+                SELECT
+                    e.id                             AS exercise_id,
+                    date(ts.start_time)              AS day,
+                    es.weight                        AS weight,
+                    es.reps                          AS reps,
+                    {e1rm} AS estimated_1rm,
+                    ROW_NUMBER() OVER (
+                        PARTITION BY e.id, date(ts.start_time)
+                        ORDER BY {e1rm} DESC
+                    ) AS rn
+                FROM   exercise_sets es
+                JOIN   training_session_exercises tse ON tse.id = es.session_exercise_id
+                JOIN   training_sessions         ts  ON ts.id  = tse.training_session_id
+                JOIN   exercises                 e   ON e.id   = tse.exercise_id
+                WHERE  es.weight > 0
+                  AND  es.ignore_for_one_rm = 0
+            )
+            SELECT exercise_id, day, weight, reps, estimated_1rm
+            FROM   ranked
+            WHERE  rn = 1
+            "#, e1rm = formula::e1rm_sql("es.weight", "es.reps"))
+        )
+        .execute(&mut *tx)
+        .await?;
+
+        // Find all-time PR for each exercise
+        let exercise_prs = query(
+            &format!(r#"
+            WITH all_sets AS (
+                SELECT
+                    e.id AS exercise_id,
+                    e.name AS exercise_name,
+                    es.weight,
+                    es.reps,
+                    {e1rm} AS estimated_1rm,
+                    date(ts.start_time) AS date
+                FROM exercise_sets es
+                JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+                JOIN training_sessions ts ON ts.id = tse.training_session_id
                 JOIN exercises e ON e.id = tse.exercise_id
                 WHERE es.weight > 0
                   AND es.ignore_for_one_rm = 0
@@ -701,7 +2071,7 @@ async fn import_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
             SELECT exercise_id, date, weight, reps, estimated_1rm
             FROM ranked_by_1rm
             WHERE rn = 1
-            "#
+            "#, e1rm = formula::e1rm_sql("es.weight", "es.reps"))
         )
         .fetch_all(&mut *tx)
         .await?;
@@ -726,11 +2096,1238 @@ async fn import_db(pool: &SqlitePool, file_path: &str) -> Result<()> {
             .execute(&mut *tx)
             .await?;
         }
+
+        // Tested maxes win over an estimate of the same day.
+        progress.add(dump.personal_records.len());
+        insert_prs(&mut *tx, &dump.personal_records).await?;
     }
 
     // Commit all changes
+    mark_import(&mut *tx, false).await?;
+    tx.commit().await?;
+    progress.finish();
+
+    Ok(progress.done)
+}
+
+
+/* ───────────────────────────── merge dump ───────────────────────────── */
+
+/// What happened to the rows of one table during a merge.
+#[derive(Default)]
+struct Tally {
+    added: u32,
+    updated: u32,
+    kept: u32,
+}
+
+#[derive(Default)]
+struct MergeReport {
+    exercises: Tally,
+    programs: Tally,
+    blocks: Tally,
+    program_exercises: Tally,
+    sessions: Tally,
+    session_exercises: Tally,
+    sets: Tally,
+    equipment_settings: Tally,
+    gyms: Tally,
+    program_progress: Tally,
+    rest_days: Tally,
+    recovery: Tally,
+    nutrition: Tally,
+    measurements: Tally,
+    progress_photos: Tally,
+    points_history: Tally,
+    goals: Tally,
+    exercise_aliases: Tally,
+    set_targets: Tally,
+    personal_records: Tally,
+}
+
+impl MergeReport {
+    fn print(&self) {
+        println!("{}", tr("Merge report:").heading().bold());
+        println!(
+            "  {:<20} {:>7} {:>7} {:>7}",
+            "table".dimmed(),
+            "added".dimmed(),
+            "updated".dimmed(),
+            "kept".dimmed()
+        );
+        for (name, t) in [
+            ("exercises", &self.exercises),
+            ("programs", &self.programs),
+            ("blocks", &self.blocks),
+            ("program exercises", &self.program_exercises),
+            ("sessions", &self.sessions),
+            ("session exercises", &self.session_exercises),
+            ("sets", &self.sets),
+            ("equipment settings", &self.equipment_settings),
+            ("gyms", &self.gyms),
+            ("program weeks", &self.program_progress),
+            ("rest days", &self.rest_days),
+            ("recovery", &self.recovery),
+            ("nutrition", &self.nutrition),
+            ("measurements", &self.measurements),
+            ("progress photos", &self.progress_photos),
+            ("points history", &self.points_history),
+            ("goals", &self.goals),
+            ("exercise aliases", &self.exercise_aliases),
+            ("set targets", &self.set_targets),
+            ("personal records", &self.personal_records),
+        ] {
+            if t.added + t.updated + t.kept == 0 {
+                continue;
+            }
+            println!(
+                "  {:<20} {:>7} {:>7} {:>7}",
+                name,
+                t.added.to_string().good(),
+                t.updated.to_string().accent(),
+                t.kept
+            );
+        }
+    }
+}
+
+/// Merge a dump into the live database without deleting anything.
+///
+/// Rows are matched by primary key (exercises, programs and gyms also by
+/// name, blocks by name within their program). Missing rows are added;
+/// when both sides have a row, the local one is kept unless the dump's is
+/// provably newer (later set timestamp, later/complete session end, later
+/// settings update).
+async fn merge_db(pool: &SqlitePool, file_path: &str) -> Result<MergeReport> {
+    let toml_str = fs::read_to_string(file_path)?;
+    let dump: DatabaseDump = toml::from_str(&toml_str)?;
+
+    let mut report = MergeReport::default();
+    let mut tx = pool.begin().await?;
+    mark_import(&mut *tx, true).await?;
+
+    // dump id -> local id, for rows that already exist under another id
+    let mut exercise_ids: HashMap<String, String> = HashMap::new();
+    let mut program_ids: HashMap<String, String> = HashMap::new();
+    let mut block_ids: HashMap<String, String> = HashMap::new();
+
+    for ex in dump.exercises {
+        let local: Option<String> =
+            sqlx::query_scalar("SELECT id FROM exercises WHERE id = ? OR name = ? ORDER BY id = ? DESC LIMIT 1")
+                .bind(&ex.id)
+                .bind(&ex.name)
+                .bind(&ex.id)
+                .fetch_optional(&mut *tx)
+                .await?;
+
+        match local {
+            Some(id) => {
+                report.exercises.kept += 1;
+                exercise_ids.insert(ex.id, id);
+            }
+            None => {
+                query(
+                    r#"
+                    INSERT INTO exercises
+                    (id, name, primary_muscle, description, created_at, estimated_one_rm, current_pr_date,
+                     unilateral, equipment, rounding, demo_path, cues)
+                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                    "#
+                )
+                .bind(&ex.id)
+                .bind(&ex.name)
+                .bind(&ex.primary_muscle)
+                .bind(&ex.description)
+                .bind(&ex.created_at)
+                .bind(ex.estimated_one_rm)
+                .bind(&ex.current_pr_date)
+                .bind(ex.unilateral as i32)
+                .bind(&ex.equipment)
+                .bind(&ex.rounding)
+                .bind(&ex.demo_path)
+                .bind(&ex.cues)
+                .execute(&mut *tx)
+                .await?;
+                report.exercises.added += 1;
+                exercise_ids.insert(ex.id.clone(), ex.id);
+            }
+        }
+    }
+    let exercise_id = |id: &str| exercise_ids.get(id).cloned().unwrap_or_else(|| id.to_string());
+
+    for prog in dump.programs {
+        let local: Option<String> =
+            sqlx::query_scalar("SELECT id FROM programs WHERE id = ? OR name = ? ORDER BY id = ? DESC LIMIT 1")
+                .bind(&prog.id)
+                .bind(&prog.name)
+                .bind(&prog.id)
+                .fetch_optional(&mut *tx)
+                .await?;
+
+        let prog_id = match local {
+            Some(id) => {
+                report.programs.kept += 1;
+                id
+            }
+            None => {
+                query("INSERT INTO programs (id, name, description, created_at, off_weeks, archived_at) VALUES (?, ?, ?, ?, ?, ?)")
+                    .bind(&prog.id)
+                    .bind(&prog.name)
+                    .bind(&prog.description)
+                    .bind(&prog.created_at)
+                    .bind(&prog.off_weeks)
+                    .bind(&prog.archived_at)
+                    .execute(&mut *tx)
+                    .await?;
+                for sub in &prog.substitutions {
+                    query("INSERT INTO program_substitutions (program_id, group_name, exercises) VALUES (?, ?, ?)")
+                        .bind(&prog.id)
+                        .bind(&sub.group)
+                        .bind(&sub.exercises)
+                        .execute(&mut *tx)
+                        .await?;
+                }
+                report.programs.added += 1;
+                prog.id.clone()
+            }
+        };
+        program_ids.insert(prog.id, prog_id.clone());
+
+        for block in prog.blocks {
+            let local: Option<String> = sqlx::query_scalar(
+                "SELECT id FROM program_blocks WHERE id = ? OR (program_id = ? AND name = ?) ORDER BY id = ? DESC LIMIT 1",
+            )
+            .bind(&block.id)
+            .bind(&prog_id)
+            .bind(&block.name)
+            .bind(&block.id)
+            .fetch_optional(&mut *tx)
+            .await?;
+
+            let block_added = local.is_none();
+            let block_id = match local {
+                Some(id) => {
+                    report.blocks.kept += 1;
+                    id
+                }
+                None => {
+                    query(
+                        r#"
+                        INSERT INTO program_blocks (id, program_id, name, description, expected_minutes, week, pool_choose)
+                        VALUES (?, ?, ?, ?, ?, ?, ?)
+                        "#
+                    )
+                    .bind(&block.id)
+                    .bind(&prog_id)
+                    .bind(&block.name)
+                    .bind(&block.description)
+                    .bind(block.expected_minutes)
+                    .bind(block.week)
+                    .bind(block.pool_choose)
+                    .execute(&mut *tx)
+                    .await?;
+                    report.blocks.added += 1;
+                    block.id.clone()
+                }
+            };
+            block_ids.insert(block.id, block_id.clone());
+
+            // A kept block keeps its own circuits.
+            if block_added {
+                for c in &block.circuits {
+                    query(
+                        r#"
+                        INSERT OR IGNORE INTO program_circuits (id, program_block_id, position, kind, rounds, interval_secs)
+                        VALUES (?, ?, ?, ?, ?, ?)
+                        "#
+                    )
+                    .bind(&c.id)
+                    .bind(&block_id)
+                    .bind(c.position)
+                    .bind(&c.kind)
+                    .bind(c.rounds)
+                    .bind(c.interval_secs)
+                    .execute(&mut *tx)
+                    .await?;
+                    for m in &c.movements {
+                        query(
+                            r#"
+                            INSERT OR IGNORE INTO program_circuit_movements (circuit_id, position, exercise_id, reps, weight)
+                            VALUES (?, ?, ?, ?, ?)
+                            "#
+                        )
+                        .bind(&c.id)
+                        .bind(m.position)
+                        .bind(exercise_id(&m.exercise_id))
+                        .bind(m.reps)
+                        .bind(m.weight)
+                        .execute(&mut *tx)
+                        .await?;
+                    }
+                }
+            }
+
+            for ex in block.exercises {
+                let res = query(
+                    r#"
+                    INSERT INTO program_exercises
+                    (id, program_block_id, exercise_id, sets, reps, target_rpe,
+                     target_rm_percent, notes, program_1rm, technique, technique_group, order_index,
+                     tempo, pause, options, warmup, backoff, superset, rotate_weeks, rest_secs, pool, valid_from)
+                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                    ON CONFLICT DO NOTHING
+                    "#
+                )
+                .bind(&ex.id)
+                .bind(&block_id)
+                .bind(exercise_id(&ex.exercise_id))
+                .bind(ex.sets)
+                .bind(&ex.reps)
+                .bind(&ex.target_rpe)
+                .bind(&ex.target_rm_percent)
+                .bind(&ex.notes)
+                .bind(ex.program_1rm)
+                .bind(&ex.technique)
+                .bind(ex.technique_group)
+                .bind(ex.order_index)
+                .bind(&ex.tempo)
+                .bind(&ex.pause)
+                .bind(&ex.options)
+                .bind(&ex.warmup)
+                .bind(&ex.backoff)
+                .bind(&ex.superset)
+                .bind(ex.rotate_weeks)
+                .bind(ex.rest_secs)
+                .bind(ex.pool)
+                .bind(&ex.valid_from)
+                .execute(&mut *tx)
+                .await?;
+
+                if res.rows_affected() == 0 {
+                    report.program_exercises.kept += 1;
+                } else {
+                    report.program_exercises.added += 1;
+                }
+            }
+
+            for v in block.versions {
+                query(
+                    r#"
+                    INSERT OR IGNORE INTO program_exercise_versions
+                    (program_exercise_id, program_block_id, exercise_id, sets, reps, target_rpe,
+                     target_rm_percent, notes, program_1rm, technique, technique_group, order_index,
+                     tempo, pause, options, warmup, backoff, superset, rotate_weeks, rest_secs, pool,
+                     valid_from, valid_to)
+                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                    "#
+                )
+                .bind(&v.id)
+                .bind(&block_id)
+                .bind(exercise_id(&v.exercise_id))
+                .bind(v.sets)
+                .bind(&v.reps)
+                .bind(&v.target_rpe)
+                .bind(&v.target_rm_percent)
+                .bind(&v.notes)
+                .bind(v.program_1rm)
+                .bind(&v.technique)
+                .bind(v.technique_group)
+                .bind(v.order_index)
+                .bind(&v.tempo)
+                .bind(&v.pause)
+                .bind(&v.options)
+                .bind(&v.warmup)
+                .bind(&v.backoff)
+                .bind(&v.superset)
+                .bind(v.rotate_weeks)
+                .bind(v.rest_secs)
+                .bind(v.pool)
+                .bind(&v.valid_from)
+                .bind(&v.valid_to)
+                .execute(&mut *tx)
+                .await?;
+            }
+        }
+    }
+
+    for sess in dump.sessions {
+        let local: Option<Option<String>> =
+            sqlx::query_scalar("SELECT end_time FROM training_sessions WHERE id = ?")
+                .bind(&sess.id)
+                .fetch_optional(&mut *tx)
+                .await?;
+
+        match local {
+            None => {
+                let block_id = block_ids
+                    .get(&sess.program_block_id)
+                    .cloned()
+                    .unwrap_or_else(|| sess.program_block_id.clone());
+                query(
+                    r#"
+                    INSERT INTO training_sessions
+                    (id, program_block_id, start_time, end_time, notes, tag)
+                    VALUES (?, ?, ?, ?, ?, ?)
+                    "#
+                )
+                .bind(&sess.id)
+                .bind(&block_id)
+                .bind(&sess.start_time)
+                .bind(&sess.end_time)
+                .bind(&sess.notes)
+                .bind(&sess.tag)
+                .execute(&mut *tx)
+                .await?;
+                report.sessions.added += 1;
+            }
+            // A finished session beats an open one, a later end beats an earlier one.
+            Some(local_end)
+                if sess.end_time.is_some()
+                    && local_end.as_ref().is_none_or(|l| sess.end_time.as_ref() > Some(l)) =>
+            {
+                query("UPDATE training_sessions SET end_time = ?, notes = COALESCE(?, notes) WHERE id = ?")
+                    .bind(&sess.end_time)
+                    .bind(&sess.notes)
+                    .bind(&sess.id)
+                    .execute(&mut *tx)
+                    .await?;
+                report.sessions.updated += 1;
+            }
+            Some(_) => report.sessions.kept += 1,
+        }
+
+        // Pauses match on their start: a resumed pause closes an open one
+        // there, and one already here (or open, over one here) is skipped.
+        for pause in &sess.pauses {
+            let closed = query(
+                r#"
+                UPDATE session_pauses SET resumed_at = ?3
+                WHERE ?3 IS NOT NULL
+                  AND id = (SELECT id FROM session_pauses
+                            WHERE training_session_id = ?1 AND paused_at = ?2 AND resumed_at IS NULL
+                            LIMIT 1)
+                "#
+            )
+            .bind(&sess.id)
+            .bind(&pause.paused_at)
+            .bind(&pause.resumed_at)
+            .execute(&mut *tx)
+            .await?
+            .rows_affected();
+            if closed == 0 {
+                query(
+                    r#"
+                    INSERT INTO session_pauses (training_session_id, paused_at, resumed_at)
+                    SELECT ?1, ?2, ?3
+                    WHERE NOT EXISTS (SELECT 1 FROM session_pauses
+                                      WHERE training_session_id = ?1 AND paused_at = ?2
+                                        AND (resumed_at IS ?3 OR ?3 IS NULL))
+                    "#
+                )
+                .bind(&sess.id)
+                .bind(&pause.paused_at)
+                .bind(&pause.resumed_at)
+                .execute(&mut *tx)
+                .await?;
+            }
+        }
+
+        for tag in &sess.tags {
+            query("INSERT OR IGNORE INTO session_tags (training_session_id, tag) VALUES (?, ?)")
+                .bind(&sess.id)
+                .bind(tag)
+                .execute(&mut *tx)
+                .await?;
+        }
+
+        for (item, checked) in &sess.checklist {
+            query("INSERT OR IGNORE INTO session_checklist (training_session_id, item, checked) VALUES (?, ?, ?)")
+                .bind(&sess.id)
+                .bind(item)
+                .bind(checked)
+                .execute(&mut *tx)
+                .await?;
+        }
+
+        for r in &sess.circuit_rounds {
+            query(
+                r#"
+                INSERT OR IGNORE INTO circuit_rounds (id, training_session_id, circuit_id, round, started_at, finished_at)
+                VALUES (?, ?, ?, ?, ?, ?)
+                "#
+            )
+            .bind(&r.id)
+            .bind(&sess.id)
+            .bind(&r.circuit_id)
+            .bind(r.round)
+            .bind(&r.started_at)
+            .bind(&r.finished_at)
+            .execute(&mut *tx)
+            .await?;
+        }
+
+        for ex in sess.exercises {
+            let res = query(
+                r#"
+                INSERT INTO training_session_exercises
+                (id, training_session_id, exercise_id, notes, rotated_from, swapped)
+                VALUES (?, ?, ?, ?, ?, ?)
+                ON CONFLICT (id) DO NOTHING
+                "#
+            )
+            .bind(&ex.id)
+            .bind(&sess.id)
+            .bind(exercise_id(&ex.exercise_id))
+            .bind(&ex.notes)
+            .bind(ex.rotated_from.as_deref().map(exercise_id))
+            .bind(ex.swapped)
+            .execute(&mut *tx)
+            .await?;
+
+            if res.rows_affected() == 0 {
+                report.session_exercises.kept += 1;
+            } else {
+                report.session_exercises.added += 1;
+            }
+
+            for set in ex.sets {
+                let local: Option<String> = sqlx::query_scalar("SELECT timestamp FROM exercise_sets WHERE id = ?")
+                    .bind(&set.id)
+                    .fetch_optional(&mut *tx)
+                    .await?;
+
+                match local {
+                    Some(ts) if ts >= set.timestamp => {
+                        insert_attachments(&mut *tx, &set.id, &set.attachments).await?;
+                        report.sets.kept += 1;
+                        continue;
+                    }
+                    Some(_) => report.sets.updated += 1,
+                    None => report.sets.added += 1,
+                }
+
+                query(
+                    r#"
+                    INSERT INTO exercise_sets
+                    (id, session_exercise_id, weight, reps, rpe, rm_percent, notes,
+                     timestamp, ignore_for_one_rm, bodyweight, tempo, pause, amrap,
+                     band, band_tension, chain_weight, body_mass, side, status, circuit_round_id, flags)
+                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                    ON CONFLICT (id) DO UPDATE SET
+                      weight = excluded.weight,
+                      reps = excluded.reps,
+                      rpe = excluded.rpe,
+                      rm_percent = excluded.rm_percent,
+                      notes = excluded.notes,
+                      timestamp = excluded.timestamp,
+                      ignore_for_one_rm = excluded.ignore_for_one_rm,
+                      bodyweight = excluded.bodyweight,
+                      tempo = excluded.tempo,
+                      pause = excluded.pause,
+                      amrap = excluded.amrap,
+                      band = excluded.band,
+                      band_tension = excluded.band_tension,
+                      chain_weight = excluded.chain_weight,
+                      body_mass = excluded.body_mass,
+                      side = excluded.side,
+                      status = excluded.status,
+                      circuit_round_id = excluded.circuit_round_id,
+                      flags = excluded.flags
+                    "#
+                )
+                .bind(&set.id)
+                .bind(&ex.id)
+                .bind(set.weight)
+                .bind(set.reps)
+                .bind(set.rpe)
+                .bind(set.rm_percent)
+                .bind(&set.notes)
+                .bind(&set.timestamp)
+                .bind(set.ignore_for_one_rm as i32)
+                .bind(set.bodyweight as i32)
+                .bind(&set.tempo)
+                .bind(&set.pause)
+                .bind(set.amrap as i32)
+                .bind(&set.band)
+                .bind(set.band_tension)
+                .bind(set.chain_weight)
+                .bind(set.body_mass)
+                .bind(&set.side)
+                .bind(set.status.as_deref().unwrap_or("completed"))
+                .bind(&set.circuit_round_id)
+                .bind(&set.flags)
+                .execute(&mut *tx)
+                .await?;
+
+                insert_attachments(&mut *tx, &set.id, &set.attachments).await?;
+            }
+        }
+    }
+
+    for setting in dump.equipment_settings {
+        let ex_id = exercise_id(&setting.exercise_id);
+        let local: Option<String> =
+            sqlx::query_scalar("SELECT updated_at FROM equipment_settings WHERE exercise_id = ? AND key = ?")
+                .bind(&ex_id)
+                .bind(&setting.key)
+                .fetch_optional(&mut *tx)
+                .await?;
+
+        match local {
+            Some(at) if at >= setting.updated_at => {
+                report.equipment_settings.kept += 1;
+                continue;
+            }
+            Some(_) => report.equipment_settings.updated += 1,
+            None => report.equipment_settings.added += 1,
+        }
+
+        query(
+            r#"
+            INSERT INTO equipment_settings (exercise_id, key, value, updated_at)
+            VALUES (?, ?, ?, ?)
+            ON CONFLICT (exercise_id, key)
+            DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+            "#
+        )
+        .bind(&ex_id)
+        .bind(&setting.key)
+        .bind(&setting.value)
+        .bind(&setting.updated_at)
+        .execute(&mut *tx)
+        .await?;
+    }
+
+    for gym in dump.gyms {
+        let res = query(
+            r#"
+            INSERT INTO gyms
+            (id, name, barbell, dumbbells, dumbbell_min, dumbbell_max,
+             machines, cables, kettlebells, created_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+            ON CONFLICT DO NOTHING
+            "#
+        )
+        .bind(&gym.id)
+        .bind(&gym.name)
+        .bind(gym.barbell as i32)
+        .bind(gym.dumbbells as i32)
+        .bind(gym.dumbbell_min)
+        .bind(gym.dumbbell_max)
+        .bind(gym.machines as i32)
+        .bind(gym.cables as i32)
+        .bind(gym.kettlebells as i32)
+        .bind(&gym.created_at)
+        .execute(&mut *tx)
+        .await?;
+
+        if res.rows_affected() == 0 {
+            report.gyms.kept += 1;
+        } else {
+            report.gyms.added += 1;
+        }
+    }
+
+    for progress in dump.program_progress {
+        let prog_id = program_ids
+            .get(&progress.program_id)
+            .cloned()
+            .unwrap_or_else(|| progress.program_id.clone());
+        let local: Option<String> =
+            sqlx::query_scalar("SELECT week_started_at FROM program_progress WHERE program_id = ?")
+                .bind(&prog_id)
+                .fetch_optional(&mut *tx)
+                .await?;
+
+        match local {
+            Some(at) if at >= progress.week_started_at => {
+                report.program_progress.kept += 1;
+                continue;
+            }
+            Some(_) => report.program_progress.updated += 1,
+            None => report.program_progress.added += 1,
+        }
+
+        query(
+            r#"
+            INSERT INTO program_progress (program_id, current_week, week_started_at)
+            VALUES (?, ?, ?)
+            ON CONFLICT (program_id)
+            DO UPDATE SET current_week = excluded.current_week, week_started_at = excluded.week_started_at
+            "#
+        )
+        .bind(&prog_id)
+        .bind(progress.current_week)
+        .bind(&progress.week_started_at)
+        .execute(&mut *tx)
+        .await?;
+    }
+
+    for rest in dump.rest_days {
+        let res = query("INSERT INTO rest_days (date, reason, created_at) VALUES (?, ?, ?) ON CONFLICT (date) DO NOTHING")
+            .bind(&rest.date)
+            .bind(&rest.reason)
+            .bind(&rest.created_at)
+            .execute(&mut *tx)
+            .await?;
+
+        if res.rows_affected() == 0 {
+            report.rest_days.kept += 1;
+        } else {
+            report.rest_days.added += 1;
+        }
+    }
+
+    for day in dump.recovery {
+        let res = query(
+            r#"
+            INSERT INTO recovery (date, sleep_minutes, hrv, source, imported_at)
+            VALUES (?, ?, ?, ?, ?)
+            ON CONFLICT (date) DO NOTHING
+            "#
+        )
+        .bind(&day.date)
+        .bind(day.sleep_minutes)
+        .bind(day.hrv)
+        .bind(&day.source)
+        .bind(&day.imported_at)
+        .execute(&mut *tx)
+        .await?;
+
+        if res.rows_affected() == 0 {
+            report.recovery.kept += 1;
+        } else {
+            report.recovery.added += 1;
+        }
+    }
+
+    // A day logged on both sides keeps whichever was logged last.
+    for day in dump.nutrition {
+        let local: Option<String> = sqlx::query_scalar("SELECT updated_at FROM nutrition WHERE date = ?")
+            .bind(&day.date)
+            .fetch_optional(&mut *tx)
+            .await?;
+        match local {
+            Some(at) if at >= day.updated_at => {
+                report.nutrition.kept += 1;
+                continue;
+            }
+            Some(_) => report.nutrition.updated += 1,
+            None => report.nutrition.added += 1,
+        }
+
+        query("INSERT OR REPLACE INTO nutrition (date, calories, protein, updated_at) VALUES (?, ?, ?, ?)")
+            .bind(&day.date)
+            .bind(day.calories)
+            .bind(day.protein)
+            .bind(&day.updated_at)
+            .execute(&mut *tx)
+            .await?;
+    }
+
+    // Same for a site measured on both sides on the same day.
+    for m in dump.measurements {
+        let local: Option<String> =
+            sqlx::query_scalar("SELECT updated_at FROM measurements WHERE date = ? AND site = ?")
+                .bind(&m.date)
+                .bind(&m.site)
+                .fetch_optional(&mut *tx)
+                .await?;
+        match local {
+            Some(at) if at >= m.updated_at => {
+                report.measurements.kept += 1;
+                continue;
+            }
+            Some(_) => report.measurements.updated += 1,
+            None => report.measurements.added += 1,
+        }
+
+        query("INSERT OR REPLACE INTO measurements (date, site, value, updated_at) VALUES (?, ?, ?, ?)")
+            .bind(&m.date)
+            .bind(&m.site)
+            .bind(m.value)
+            .bind(&m.updated_at)
+            .execute(&mut *tx)
+            .await?;
+    }
+
+    for photo in dump.progress_photos {
+        let res = query(
+            r#"
+            INSERT INTO progress_photos (id, date, tag, path, created_at) VALUES (?, ?, ?, ?, ?)
+            ON CONFLICT (id) DO NOTHING
+            "#
+        )
+        .bind(&photo.id)
+        .bind(&photo.date)
+        .bind(&photo.tag)
+        .bind(&photo.path)
+        .bind(&photo.created_at)
+        .execute(&mut *tx)
+        .await?;
+
+        if res.rows_affected() == 0 {
+            report.progress_photos.kept += 1;
+        } else {
+            report.progress_photos.added += 1;
+        }
+    }
+
+    // Snapshots are per day; the local one wins.
+    for s in dump.points_history {
+        let res = query(
+            r#"
+            INSERT INTO points_history (date, bodyweight, total, wilks, dots, ipf_gl) VALUES (?, ?, ?, ?, ?, ?)
+            ON CONFLICT (date) DO NOTHING
+            "#
+        )
+        .bind(&s.date)
+        .bind(s.bodyweight)
+        .bind(s.total)
+        .bind(s.wilks)
+        .bind(s.dots)
+        .bind(s.ipf_gl)
+        .execute(&mut *tx)
+        .await?;
+
+        if res.rows_affected() == 0 {
+            report.points_history.kept += 1;
+        } else {
+            report.points_history.added += 1;
+        }
+    }
+
+    // Goals match by id; a goal finished on the other side is finished here too.
+    for goal in dump.goals {
+        let local: Option<Option<String>> = sqlx::query_scalar("SELECT done_at FROM goals WHERE id = ?")
+            .bind(&goal.id)
+            .fetch_optional(&mut *tx)
+            .await?;
+
+        match local {
+            Some(None) if goal.done_at.is_some() => {
+                query("UPDATE goals SET done_at = ? WHERE id = ?")
+                    .bind(&goal.done_at)
+                    .bind(&goal.id)
+                    .execute(&mut *tx)
+                    .await?;
+                report.goals.updated += 1;
+            }
+            Some(_) => report.goals.kept += 1,
+            None => {
+                query(
+                    r#"
+                    INSERT INTO goals (id, exercise_id, weight, reps, target_date, created_at, done_at)
+                    VALUES (?, ?, ?, ?, ?, ?, ?)
+                    "#
+                )
+                .bind(&goal.id)
+                .bind(exercise_id(&goal.exercise_id))
+                .bind(goal.weight)
+                .bind(goal.reps)
+                .bind(&goal.target_date)
+                .bind(&goal.created_at)
+                .bind(&goal.done_at)
+                .execute(&mut *tx)
+                .await?;
+                report.goals.added += 1;
+            }
+        }
+    }
+
+    // An alias another exercise already answers to stays with that one.
+    for alias in dump.exercise_aliases {
+        let res = query(
+            r#"
+            INSERT OR IGNORE INTO exercise_aliases (exercise_id, alias)
+            SELECT ?1, ?2
+            WHERE NOT EXISTS (SELECT 1 FROM exercise_aliases WHERE alias = ?2)
+            "#
+        )
+        .bind(exercise_id(&alias.exercise_id))
+        .bind(&alias.alias)
+        .execute(&mut *tx)
+        .await?;
+
+        if res.rows_affected() == 0 {
+            report.exercise_aliases.kept += 1;
+        } else {
+            report.exercise_aliases.added += 1;
+        }
+    }
+
+    // Targets match by session exercise, kind and position; ids are only
+    // unique per database.
+    for t in dump.session_set_targets {
+        let res = query(
+            r#"
+            INSERT INTO session_set_targets (session_exercise_id, kind, position, percent, reps)
+            SELECT ?1, ?2, ?3, ?4, ?5
+            WHERE EXISTS (SELECT 1 FROM training_session_exercises WHERE id = ?1)
+            AND NOT EXISTS (
+                SELECT 1 FROM session_set_targets WHERE session_exercise_id = ?1 AND kind = ?2 AND position = ?3
+            )
+            "#
+        )
+        .bind(&t.session_exercise_id)
+        .bind(&t.kind)
+        .bind(t.position)
+        .bind(t.percent)
+        .bind(&t.reps)
+        .execute(&mut *tx)
+        .await?;
+
+        if res.rows_affected() == 0 {
+            report.set_targets.kept += 1;
+        } else {
+            report.set_targets.added += 1;
+        }
+    }
+
+    for pr in dump.personal_records {
+        let res = query(
+            r#"
+            INSERT INTO personal_records (exercise_id, date, weight, reps, estimated_1rm, verified)
+            VALUES (?, ?, ?, ?, ?, ?)
+            ON CONFLICT (exercise_id, date) DO NOTHING
+            "#
+        )
+        .bind(exercise_id(&pr.exercise_id))
+        .bind(&pr.date)
+        .bind(pr.weight)
+        .bind(pr.reps)
+        .bind(pr.estimated_1rm)
+        .bind(pr.verified as i32)
+        .execute(&mut *tx)
+        .await?;
+
+        if res.rows_affected() == 0 {
+            report.personal_records.kept += 1;
+        } else {
+            report.personal_records.added += 1;
+        }
+    }
+
+    mark_import(&mut *tx, false).await?;
     tx.commit().await?;
 
+    Ok(report)
+}
+
+/* ─────────────────────────── stats / maintain ────────────────────────── */
+
+#[derive(Serialize)]
+struct DbStats {
+    size_bytes: i64,
+    free_bytes: i64,
+    tables: Vec<(String, i64)>,
+    /// Bytes per index; empty when SQLite was built without `dbstat`.
+    indexes: Vec<(String, i64)>,
+    oldest_session: Option<String>,
+    newest_session: Option<String>,
+}
+
+/// Size of the database file from its pages, which also works in memory.
+async fn size_bytes(pool: &SqlitePool) -> Result<(i64, i64)> {
+    let page_size: i64 = sqlx::query_scalar("PRAGMA page_size").fetch_one(pool).await?;
+    let pages: i64 = sqlx::query_scalar("PRAGMA page_count").fetch_one(pool).await?;
+    let free: i64 = sqlx::query_scalar("PRAGMA freelist_count").fetch_one(pool).await?;
+    Ok((pages * page_size, free * page_size))
+}
+
+fn human_bytes(bytes: i64) -> String {
+    match bytes {
+        b if b >= 1 << 20 => format!("{:.1} MiB", b as f64 / (1 << 20) as f64),
+        b if b >= 1 << 10 => format!("{:.1} KiB", b as f64 / (1 << 10) as f64),
+        b => format!("{} B", b),
+    }
+}
+
+async fn db_stats(pool: &SqlitePool) -> Result<DbStats> {
+    let (size_bytes, free_bytes) = size_bytes(pool).await?;
+
+    let names: Vec<String> = sqlx::query_scalar(
+        "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> '_sqlx_migrations' ORDER BY name",
+    )
+    .fetch_all(pool)
+    .await?;
+    let mut tables = Vec::new();
+    for name in names {
+        let count: i64 = sqlx::query_scalar(&format!("SELECT COUNT(*) FROM \"{}\"", name.replace('"', "\"\"")))
+            .fetch_one(pool)
+            .await?;
+        tables.push((name, count));
+    }
+
+    let indexes = sqlx::query_as(
+        r#"
+        SELECT m.name, SUM(s.pgsize)
+        FROM sqlite_master m
+        JOIN dbstat s ON s.name = m.name
+        WHERE m.type = 'index'
+        GROUP BY m.name
+        ORDER BY 2 DESC
+        "#,
+    )
+    .fetch_all(pool)
+    .await
+    .unwrap_or_default();
+
+    let (oldest_session, newest_session) =
+        sqlx::query_as("SELECT MIN(start_time), MAX(start_time) FROM training_sessions")
+            .fetch_one(pool)
+            .await?;
+
+    Ok(DbStats { size_bytes, free_bytes, tables, indexes, oldest_session, newest_session })
+}
+
+async fn print_stats(pool: &SqlitePool, fmt: OutputFmt) -> Result<()> {
+    let stats = db_stats(pool).await?;
+
+    emit(fmt, &stats, || {
+        println!(
+            "{} {} {}",
+            tr("Database:").heading().bold(),
+            human_bytes(stats.size_bytes),
+            tf("({} free, reclaimed by `db maintain`)", &[&human_bytes(stats.free_bytes)]).dimmed()
+        );
+        match (&stats.oldest_session, &stats.newest_session) {
+            (Some(oldest), Some(newest)) => println!(
+                "{} {}",
+                tr("Sessions:").heading().bold(),
+                tf("{} → {}", &[&display_db_date(oldest), &display_db_date(newest)])
+            ),
+            _ => println!("{} {}", tr("Sessions:").heading().bold(), tr("none yet").dimmed()),
+        }
+
+        println!("\n{}", tr("Rows:").heading().bold());
+        for (name, count) in stats.tables.iter().filter(|(_, c)| *c > 0) {
+            println!("  {:<28} {:>8}", name, count);
+        }
+        let empty = stats.tables.iter().filter(|(_, c)| *c == 0).count();
+        if empty > 0 {
+            println!("  {}", tf("{} empty tables", &[&empty]).dimmed());
+        }
+
+        if !stats.indexes.is_empty() {
+            println!("\n{}", tr("Indexes:").heading().bold());
+            for (name, bytes) in &stats.indexes {
+                println!("  {:<28} {:>10}", name, human_bytes(*bytes));
+            }
+        }
+    });
+
+    Ok(())
+}
+
+/// Integrity check first (no point compacting a broken file), then VACUUM and
+/// ANALYZE.
+async fn maintain(pool: &SqlitePool) -> Result<()> {
+    let problems: Vec<String> = sqlx::query_scalar("PRAGMA integrity_check").fetch_all(pool).await?;
+    if problems.iter().any(|p| p != "ok") {
+        for p in &problems {
+            eprintln!("  {}", p);
+        }
+        anyhow::bail!("integrity check failed, restore a backup or `db export` what is still readable");
+    }
+    ui::ok(tr("integrity check passed"));
+
+    let (before, _) = size_bytes(pool).await?;
+    query("VACUUM").execute(pool).await?;
+    query("ANALYZE").execute(pool).await?;
+    let (after, _) = size_bytes(pool).await?;
+
+    ui::ok(tf(
+        "vacuumed and analyzed: {} → {}",
+        &[&human_bytes(before), &human_bytes(after)]
+    ));
     Ok(())
 }
 
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::testutil::{self, memory_db};
+
+    /// A block with two exercises and one finished session, with an alias
+    /// and warm-up targets.
+    async fn seed(pool: &SqlitePool) {
+        let squat = testutil::exercise(pool, "Squat", "quads").await;
+        let bench = testutil::exercise(pool, "Bench Press", "chest").await;
+        sqlx::query("INSERT INTO exercise_aliases (exercise_id, alias) VALUES (?, 'back squat')")
+            .bind(&squat)
+            .execute(pool)
+            .await
+            .unwrap();
+        let block = testutil::program_block(pool, "Strength", "Week A", &[&squat, &bench], "5").await;
+        let session = testutil::session(pool, &block, "2024-01-01 10:00:00", Some("2024-01-01 11:00:00")).await;
+        for (exercise, weight) in [(&squat, 100.0), (&bench, 80.0)] {
+            let tse = testutil::session_exercise(pool, &session, exercise).await;
+            for (position, percent) in [(1, 0.5), (2, 0.7)] {
+                sqlx::query(
+                    "INSERT INTO session_set_targets (session_exercise_id, kind, position, percent, reps) VALUES (?, 'warmup', ?, ?, '5')",
+                )
+                .bind(&tse)
+                .bind(position)
+                .bind(percent)
+                .execute(pool)
+                .await
+                .unwrap();
+            }
+            for minute in 0..3 {
+                let ts = format!("2024-01-01 10:{:02}:00", 10 + minute);
+                testutil::set(pool, &tse, weight, 5, "completed", &ts).await;
+            }
+        }
+    }
+
+    #[tokio::test]
+    async fn export_import_round_trip() {
+        let pool = memory_db().await;
+        seed(&pool).await;
+        let first = testutil::temp_path("first.toml");
+        export_db(&pool, first.to_str().unwrap(), false, false).await.unwrap();
+
+        let text = fs::read_to_string(&first).unwrap();
+        assert!(text.contains("back squat") && text.contains("[[session_set_targets]]"));
+
+        let copy = memory_db().await;
+        let rows = import_db(&copy, first.to_str().unwrap(), &[]).await.unwrap();
+        assert!(rows > 0);
+        let second = testutil::temp_path("second.toml");
+        export_db(&copy, second.to_str().unwrap(), false, false).await.unwrap();
+
+        assert_eq!(fs::read_to_string(&first).unwrap(), fs::read_to_string(&second).unwrap());
+        let _ = fs::remove_file(first);
+        let _ = fs::remove_file(second);
+    }
+
+    #[tokio::test]
+    async fn import_only_selected_tables() {
+        let pool = memory_db().await;
+        seed(&pool).await;
+        let path = testutil::temp_path("dump.toml");
+        export_db(&pool, path.to_str().unwrap(), false, false).await.unwrap();
+
+        let copy = memory_db().await;
+        import_db(&copy, path.to_str().unwrap(), &[DumpTable::Exercises]).await.unwrap();
+        let exercises: i64 = sqlx::query_scalar("SELECT COUNT(*) FROM exercises").fetch_one(&copy).await.unwrap();
+        let sessions: i64 = sqlx::query_scalar("SELECT COUNT(*) FROM training_sessions").fetch_one(&copy).await.unwrap();
+        assert_eq!((exercises, sessions), (2, 0));
+
+        // Back into the database it came from: rows hanging off the
+        // exercises, which weren't selected, stay.
+        sqlx::query(
+            "INSERT INTO personal_records (exercise_id, date, weight, reps, estimated_1rm, verified) \
+             SELECT id, '2024-01-01', 100, 5, 116.7, 1 FROM exercises WHERE name = 'Squat'",
+        )
+        .execute(&pool)
+        .await
+        .unwrap();
+        let count = |table: &'static str| {
+            let pool = pool.clone();
+            async move {
+                sqlx::query_scalar::<_, i64>(&format!("SELECT COUNT(*) FROM {}", table))
+                    .fetch_one(&pool)
+                    .await
+                    .unwrap()
+            }
+        };
+        let tables = ["exercise_aliases", "personal_records", "training_session_exercises", "session_set_targets", "exercise_sets"];
+        let mut before = Vec::new();
+        for table in tables {
+            before.push(count(table).await);
+        }
+        import_db(&pool, path.to_str().unwrap(), &[DumpTable::Exercises]).await.unwrap();
+        let mut after = Vec::new();
+        for table in tables {
+            after.push(count(table).await);
+        }
+        assert_eq!(before, after);
+        assert_eq!(before[..2], [1, 1]);
+        let _ = fs::remove_file(path);
+    }
+
+    #[tokio::test]
+    async fn anonymized_dump_imports() {
+        let pool = memory_db().await;
+        seed(&pool).await;
+        let (block, session): (String, String) = sqlx::query_as("SELECT program_block_id, id FROM training_sessions")
+            .fetch_one(&pool)
+            .await
+            .unwrap();
+        let squat: String = sqlx::query_scalar("SELECT id FROM exercises WHERE name = 'Squat'").fetch_one(&pool).await.unwrap();
+        sqlx::query("INSERT INTO program_circuits (id, program_block_id, position, kind, rounds) VALUES ('c', ?, 1, 'emom', 5)")
+            .bind(&block)
+            .execute(&pool)
+            .await
+            .unwrap();
+        sqlx::query("INSERT INTO program_circuit_movements (circuit_id, position, exercise_id, reps) VALUES ('c', 1, ?, 5)")
+            .bind(&squat)
+            .execute(&pool)
+            .await
+            .unwrap();
+        for sql in [
+            "UPDATE training_sessions SET tag = 'Garage', notes = 'knee hurt' WHERE id = ?",
+            "INSERT INTO session_tags (training_session_id, tag) VALUES (?, 'summer-cut')",
+            "INSERT INTO session_checklist (training_session_id, item, checked) VALUES (?, 'knee sleeves', 1)",
+        ] {
+            sqlx::query(sql).bind(&session).execute(&pool).await.unwrap();
+        }
+        sqlx::query("UPDATE exercises SET cues = ?, demo_path = ? WHERE id = ?")
+            .bind("spread the floor; elbows under")
+            .bind("/home/alice/.local/share/lazarus/media/demos/squat.gif")
+            .bind(&squat)
+            .execute(&pool)
+            .await
+            .unwrap();
+
+        let path = testutil::temp_path("anon.toml");
+        export_db(&pool, path.to_str().unwrap(), true, false).await.unwrap();
+        let text = fs::read_to_string(&path).unwrap();
+        for private in [&session, &squat, "Garage", "knee", "summer-cut", "alice", "elbows"] {
+            assert!(!text.contains(private), "`{}` left in the anonymized dump", private);
+        }
+        assert!(text.contains(".gif\""), "the demo keeps its format");
+
+        let copy = memory_db().await;
+        import_db(&copy, path.to_str().unwrap(), &[]).await.unwrap();
+        let movements: i64 = sqlx::query_scalar(
+            "SELECT COUNT(*) FROM program_circuit_movements m JOIN exercises e ON e.id = m.exercise_id WHERE e.name = 'Squat'",
+        )
+        .fetch_one(&copy)
+        .await
+        .unwrap();
+        assert_eq!(movements, 1);
+        let _ = fs::remove_file(path);
+    }
+
+    #[tokio::test]
+    async fn reimport_keeps_past_prescriptions() {
+        let pool = memory_db().await;
+        seed(&pool).await;
+        let path = testutil::temp_path("dump.toml");
+        export_db(&pool, path.to_str().unwrap(), false, false).await.unwrap();
+
+        // The block already has a session, so an edit would be stamped now.
+        import_db(&pool, path.to_str().unwrap(), &[DumpTable::Programs]).await.unwrap();
+        let stamped: i64 = sqlx::query_scalar("SELECT COUNT(*) FROM program_exercises WHERE valid_from IS NOT NULL")
+            .fetch_one(&pool)
+            .await
+            .unwrap();
+        assert_eq!(stamped, 0);
+        let prescribed: i64 = sqlx::query_scalar("SELECT COUNT(*) FROM session_program_exercises")
+            .fetch_one(&pool)
+            .await
+            .unwrap();
+        assert_eq!(prescribed, 2);
+        let guard: i64 = sqlx::query_scalar("SELECT COUNT(*) FROM import_in_progress").fetch_one(&pool).await.unwrap();
+        assert_eq!(guard, 0);
+
+        let second = testutil::temp_path("second.toml");
+        export_db(&pool, second.to_str().unwrap(), false, false).await.unwrap();
+        assert_eq!(fs::read_to_string(&path).unwrap(), fs::read_to_string(&second).unwrap());
+        let _ = fs::remove_file(path);
+        let _ = fs::remove_file(second);
+    }
+}
@@ -0,0 +1,228 @@
+use std::{
+    fs,
+    io::{IsTerminal, Write},
+    path::Path,
+    process::{Command, Stdio},
+};
+
+use anyhow::{Context, Result};
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::attach::media_dir,
+    errors::AppError,
+    i18n::{tf, tr},
+    ui::{self, Themed},
+};
+
+/// Inline image protocols we can speak. Formats the terminal can't take as
+/// they are go through an external tool first.
+enum Graphics {
+    /// Kitty graphics protocol (kitty, Ghostty); PNG only, anything else is
+    /// played by `kitten icat` or converted to PNG.
+    Kitty,
+    /// iTerm2 inline images (iTerm2, WezTerm); any format, GIFs animate.
+    Iterm,
+    /// Sixel (foot, mlterm, xterm -ti vt340...); encoded by img2sixel or ImageMagick.
+    Sixel,
+}
+
+fn graphics() -> Option<Graphics> {
+    let env = |k: &str| std::env::var(k).unwrap_or_default();
+    let (term, program) = (env("TERM"), env("TERM_PROGRAM"));
+    if std::env::var_os("KITTY_WINDOW_ID").is_some() || term.contains("kitty") || program == "ghostty" {
+        Some(Graphics::Kitty)
+    } else if program == "iTerm.app" || program == "WezTerm" || env("LC_TERMINAL") == "iTerm2" {
+        Some(Graphics::Iterm)
+    } else if term.starts_with("foot") || term.starts_with("mlterm") || term.contains("sixel") {
+        Some(Graphics::Sixel)
+    } else {
+        None
+    }
+}
+
+/// What `render` managed.
+enum Rendered {
+    Shown,
+    /// Not a terminal, or one without inline images.
+    NoGraphics,
+    /// The terminal shows images, but not this format without a tool that
+    /// isn't installed.
+    NeedsTool,
+}
+
+/// The stdout of the first of `tools` that runs and prints something.
+fn convert(tools: &[(&str, Vec<&str>)]) -> Option<Vec<u8>> {
+    tools.iter().find_map(|(cmd, args)| {
+        let out = Command::new(cmd).args(args).stderr(Stdio::null()).output().ok()?;
+        (out.status.success() && !out.stdout.is_empty()).then_some(out.stdout)
+    })
+}
+
+fn base64(data: &[u8]) -> String {
+    const ABC: &[u8; 64] = b"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+    let mut out = String::with_capacity(data.len().div_ceil(3) * 4);
+    for chunk in data.chunks(3) {
+        let b = [chunk[0], *chunk.get(1).unwrap_or(&0), *chunk.get(2).unwrap_or(&0)];
+        let n = (b[0] as u32) << 16 | (b[1] as u32) << 8 | b[2] as u32;
+        for i in 0..4 {
+            if i <= chunk.len() {
+                out.push(ABC[(n >> (18 - 6 * i) & 63) as usize] as char);
+            } else {
+                out.push('=');
+            }
+        }
+    }
+    out
+}
+
+/// A PNG through the kitty graphics protocol.
+fn write_kitty(out: &mut impl Write, png: &[u8]) -> Result<()> {
+    // Sent in chunks of at most 4096 base64 bytes; m=1 means more follow.
+    let encoded = base64(png);
+    let chunks: Vec<&[u8]> = encoded.as_bytes().chunks(4096).collect();
+    for (i, chunk) in chunks.iter().enumerate() {
+        let more = (i + 1 < chunks.len()) as u8;
+        let head = if i == 0 { format!("a=T,f=100,m={}", more) } else { format!("m={}", more) };
+        write!(out, "\x1b_G{};{}\x1b\\", head, std::str::from_utf8(chunk)?)?;
+    }
+    Ok(())
+}
+
+/// Write `path` inline. A pre-rendered sixel file (`.six`/`.sixel`) is passed
+/// through as-is; a GIF plays in iTerm2 and with `kitten icat`, and is
+/// otherwise shown by its first frame (kitty, via ImageMagick) or encoded
+/// with img2sixel or ImageMagick (sixel).
+fn render(path: &Path) -> Result<Rendered> {
+    if !std::io::stdout().is_terminal() {
+        return Ok(Rendered::NoGraphics);
+    }
+    let ext = path.extension().map(|e| e.to_string_lossy().to_lowercase()).unwrap_or_default();
+    let data = fs::read(path).with_context(|| format!("could not read `{}`", path.display()))?;
+    let file = path.to_string_lossy();
+    let first_frame = format!("{}[0]", file);
+
+    if ext == "six" || ext == "sixel" {
+        let mut out = std::io::stdout().lock();
+        out.write_all(&data)?;
+        writeln!(out)?;
+        return Ok(Rendered::Shown);
+    }
+    match graphics() {
+        Some(Graphics::Kitty) if ext == "png" => write_kitty(&mut std::io::stdout().lock(), &data)?,
+        Some(Graphics::Kitty) => {
+            let played = std::env::var_os("KITTY_WINDOW_ID").is_some()
+                && Command::new("kitten").args(["icat", &file]).status().is_ok_and(|s| s.success());
+            if !played {
+                let tools = [("magick", vec![first_frame.as_str(), "png:-"]), ("convert", vec![first_frame.as_str(), "png:-"])];
+                let Some(png) = convert(&tools) else { return Ok(Rendered::NeedsTool) };
+                write_kitty(&mut std::io::stdout().lock(), &png)?;
+            }
+        }
+        Some(Graphics::Iterm) => {
+            write!(std::io::stdout().lock(), "\x1b]1337;File=inline=1;size={}:{}\x07", data.len(), base64(&data))?;
+        }
+        Some(Graphics::Sixel) => {
+            let tools = [
+                ("img2sixel", vec![file.as_ref()]),
+                ("magick", vec![file.as_ref(), "sixel:-"]),
+                ("convert", vec![file.as_ref(), "sixel:-"]),
+            ];
+            let Some(sixel) = convert(&tools) else { return Ok(Rendered::NeedsTool) };
+            std::io::stdout().lock().write_all(&sixel)?;
+        }
+        None => return Ok(Rendered::NoGraphics),
+    }
+    println!();
+    Ok(Rendered::Shown)
+}
+
+/// The exercise's demo image inline, or its cues (else its description) as text.
+pub async fn print_demo(pool: &SqlitePool, exercise_id: &str) -> Result<()> {
+    let (name, demo_path, cues, description): (String, Option<String>, Option<String>, Option<String>) =
+        sqlx::query_as("SELECT name, demo_path, cues, description FROM exercises WHERE id = ?")
+            .bind(exercise_id)
+            .fetch_one(pool)
+            .await?;
+
+    let mut needs_tool = false;
+    if let Some(path) = demo_path.as_deref().filter(|p| Path::new(p).is_file()) {
+        match render(Path::new(path))? {
+            Rendered::Shown => return Ok(()),
+            Rendered::NeedsTool => needs_tool = true,
+            Rendered::NoGraphics => {}
+        }
+    }
+
+    let text = cues.or(description).filter(|t| !t.trim().is_empty());
+    match text {
+        Some(text) => {
+            println!("{}", tr("Cues:").heading().bold());
+            for cue in text.split(';').map(str::trim).filter(|c| !c.is_empty()) {
+                println!("  • {}", cue);
+            }
+        }
+        None => ui::info(tf("no cues for `{}` yet, add them with `exercise demo {} --cues \"...\"`", &[&name, &name])),
+    }
+    if let Some(path) = demo_path {
+        println!("{} {}", tr("Demo:").heading().bold(), path.dimmed());
+    }
+    if needs_tool {
+        ui::info(tr("install ImageMagick (or img2sixel on sixel terminals) to show this demo inline, or open the file above in an image viewer"));
+    }
+    println!();
+
+    Ok(())
+}
+
+/// `exercise demo`: copy a demo image into the media folder and/or set cues.
+pub async fn handle_set(pool: &SqlitePool, exercise: String, image: Option<String>, cues: Option<String>) -> Result<()> {
+    let found: Option<(String, String)> = sqlx::query_as(
+        "SELECT id, name FROM exercises WHERE name = ? COLLATE NOCASE OR CAST(idx AS TEXT) = ?",
+    )
+    .bind(&exercise)
+    .bind(&exercise)
+    .fetch_optional(pool)
+    .await?;
+    let Some((id, name)) = found else {
+        return Err(AppError::ExerciseNotFound(exercise).into());
+    };
+    if image.is_none() && cues.is_none() {
+        return print_demo(pool, &id).await;
+    }
+
+    if let Some(file) = image {
+        let file = Path::new(&file);
+        if !file.is_file() {
+            return Err(AppError::NotFound(tf("no file at `{}`", &[&file.display()]).into()).into());
+        }
+        let ext = file
+            .extension()
+            .map(|e| format!(".{}", e.to_string_lossy()))
+            .unwrap_or_default();
+        let dir = media_dir()?.join("demos");
+        fs::create_dir_all(&dir).with_context(|| format!("could not create `{}`", dir.display()))?;
+        let slug: String = name.chars().map(|c| if c.is_alphanumeric() { c.to_ascii_lowercase() } else { '-' }).collect();
+        let dest = dir.join(format!("{}{}", slug, ext));
+        fs::copy(file, &dest).with_context(|| format!("could not copy `{}`", file.display()))?;
+
+        sqlx::query("UPDATE exercises SET demo_path = ? WHERE id = ?")
+            .bind(dest.to_string_lossy().into_owned())
+            .bind(&id)
+            .execute(pool)
+            .await?;
+        ui::ok(tf("demo for `{}` saved to {}", &[&name, &dest.display()]));
+    }
+
+    if let Some(cues) = cues {
+        sqlx::query("UPDATE exercises SET cues = ? WHERE id = ?")
+            .bind(Some(cues.trim()).filter(|c| !c.is_empty()))
+            .bind(&id)
+            .execute(pool)
+            .await?;
+        ui::ok(tf("cues for `{}` updated", &[&name]));
+    }
+
+    Ok(())
+}
@@ -0,0 +1,335 @@
+use anyhow::Result;
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{errors::AppError, i18n::{tf, tr}, ui::{self, Themed}};
+
+/// One integrity check: how to count the offending rows and how to fix them.
+struct Check {
+    name: &'static str,
+    count: &'static str,
+    repair: &'static str,
+}
+
+/// In dependency order: rows that would make a later repair fail go first,
+/// and rows a repair leaves orphaned are caught by a later one.
+const CHECKS: &[Check] = &[
+    // Sessions keep their block from being deleted, so they go first.
+    Check {
+        name: "sessions of a block without a program",
+        count: "SELECT COUNT(*) FROM training_sessions
+                WHERE program_block_id IN (SELECT id FROM program_blocks
+                                           WHERE program_id NOT IN (SELECT id FROM programs))",
+        repair: "DELETE FROM training_sessions
+                 WHERE program_block_id IN (SELECT id FROM program_blocks
+                                            WHERE program_id NOT IN (SELECT id FROM programs))",
+    },
+    Check {
+        name: "blocks without a program",
+        count: "SELECT COUNT(*) FROM program_blocks
+                WHERE program_id NOT IN (SELECT id FROM programs)",
+        repair: "DELETE FROM program_blocks
+                 WHERE program_id NOT IN (SELECT id FROM programs)",
+    },
+    Check {
+        name: "sessions pointing at a missing block",
+        count: "SELECT COUNT(*) FROM training_sessions
+                WHERE program_block_id NOT IN (SELECT id FROM program_blocks)",
+        repair: "DELETE FROM training_sessions
+                 WHERE program_block_id NOT IN (SELECT id FROM program_blocks)",
+    },
+    Check {
+        name: "session exercises without a session",
+        count: "SELECT COUNT(*) FROM training_session_exercises
+                WHERE training_session_id NOT IN (SELECT id FROM training_sessions)",
+        repair: "DELETE FROM training_session_exercises
+                 WHERE training_session_id NOT IN (SELECT id FROM training_sessions)",
+    },
+    Check {
+        name: "session exercises pointing at a missing exercise",
+        count: "SELECT COUNT(*) FROM training_session_exercises
+                WHERE exercise_id NOT IN (SELECT id FROM exercises)",
+        repair: "DELETE FROM training_session_exercises
+                 WHERE exercise_id NOT IN (SELECT id FROM exercises)",
+    },
+    Check {
+        name: "sets without a session exercise",
+        count: "SELECT COUNT(*) FROM exercise_sets
+                WHERE session_exercise_id NOT IN (SELECT id FROM training_session_exercises)",
+        repair: "DELETE FROM exercise_sets
+                 WHERE session_exercise_id NOT IN (SELECT id FROM training_session_exercises)",
+    },
+    Check {
+        name: "program exercises without a block",
+        count: "SELECT COUNT(*) FROM program_exercises
+                WHERE program_block_id NOT IN (SELECT id FROM program_blocks)",
+        repair: "DELETE FROM program_exercises
+                 WHERE program_block_id NOT IN (SELECT id FROM program_blocks)",
+    },
+    Check {
+        name: "program exercises pointing at a missing exercise",
+        count: "SELECT COUNT(*) FROM program_exercises
+                WHERE exercise_id NOT IN (SELECT id FROM exercises)",
+        repair: "DELETE FROM program_exercises
+                 WHERE exercise_id NOT IN (SELECT id FROM exercises)",
+    },
+    Check {
+        name: "personal records for a missing exercise",
+        count: "SELECT COUNT(*) FROM personal_records
+                WHERE exercise_id NOT IN (SELECT id FROM exercises)",
+        repair: "DELETE FROM personal_records
+                 WHERE exercise_id NOT IN (SELECT id FROM exercises)",
+    },
+    Check {
+        name: "equipment settings for a missing exercise",
+        count: "SELECT COUNT(*) FROM equipment_settings
+                WHERE exercise_id NOT IN (SELECT id FROM exercises)",
+        repair: "DELETE FROM equipment_settings
+                 WHERE exercise_id NOT IN (SELECT id FROM exercises)",
+    },
+    // Only the newest open session of each tag is live; anything older
+    // that never got an end_time is closed at its last logged set.
+    Check {
+        name: "stale sessions lacking end_time",
+        count: "SELECT COUNT(*) FROM training_sessions
+                WHERE end_time IS NULL
+                  AND id <> (SELECT id FROM training_sessions t
+                             WHERE t.end_time IS NULL AND t.tag IS training_sessions.tag
+                             ORDER BY t.start_time DESC LIMIT 1)",
+        repair: "UPDATE training_sessions
+                 SET end_time = COALESCE(
+                     (SELECT MAX(es.timestamp)
+                      FROM exercise_sets es
+                      JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+                      WHERE tse.training_session_id = training_sessions.id),
+                     start_time)
+                 WHERE end_time IS NULL
+                   AND id <> (SELECT id FROM training_sessions t
+                              WHERE t.end_time IS NULL AND t.tag IS training_sessions.tag
+                              ORDER BY t.start_time DESC LIMIT 1)",
+    },
+];
+
+/// Repair one check in its own transaction, so a failure neither undoes the
+/// repairs before it nor stops the ones after. Returns the rows still there.
+async fn repair_one(pool: &SqlitePool, check: &Check) -> Result<i64> {
+    let mut tx = pool.begin().await?;
+    sqlx::query(check.repair).execute(&mut *tx).await?;
+    let left = sqlx::query_scalar(check.count).fetch_one(&mut *tx).await?;
+    tx.commit().await?;
+    Ok(left)
+}
+
+/// Exits with `AppError::Invalid` (code 3) while problems are left, so
+/// scripts can tell a healthy database from one that needs `--repair`.
+pub async fn handle(pool: &SqlitePool, repair: bool) -> Result<()> {
+    // Problems found, and those still there at the end.
+    let mut problems = 0;
+    let mut left = 0;
+
+    // Every pooled connection is opened with foreign keys on; make sure.
+    let fk_on: i64 = sqlx::query_scalar("PRAGMA foreign_keys")
+        .fetch_one(pool)
+        .await?;
+    if fk_on != 1 {
+        println!("{} {}", tr("error:").bad().bold(), tr("foreign keys are disabled on this connection"));
+        problems += 1;
+        left += 1;
+    }
+
+    for check in CHECKS {
+        let n: i64 = sqlx::query_scalar(check.count).fetch_one(pool).await?;
+        if n == 0 {
+            ui::ok(check.name.dimmed());
+            continue;
+        }
+
+        problems += n as usize;
+        if !repair {
+            println!("{} {}: {}", tr("warning:").accent().bold(), check.name, n);
+            left += n as usize;
+            continue;
+        }
+        match repair_one(pool, check).await {
+            Ok(still) => {
+                println!(
+                    "{} {}",
+                    tr("fixed:").accent().bold(),
+                    tf("{}: {} found, {} repaired", &[&check.name, &n, &(n - still).max(0)])
+                );
+                left += still as usize;
+            }
+            Err(e) => {
+                println!("{} {}", tr("error:").bad().bold(), tf("{}: repair failed: {}", &[&check.name, &e]));
+                left += n as usize;
+            }
+        }
+    }
+
+    // After the repairs, so only what they left behind shows up.
+    let fk_violations: Vec<(String, Option<i64>, String, i64)> =
+        sqlx::query_as("PRAGMA foreign_key_check").fetch_all(pool).await?;
+    if !fk_violations.is_empty() {
+        problems += fk_violations.len();
+        left += fk_violations.len();
+        println!(
+            "{} {}",
+            tr("error:").bad().bold(),
+            tf("{} foreign key violation(s)", &[&fk_violations.len()])
+        );
+        for (table, rowid, parent, _) in &fk_violations {
+            println!(
+                "    {} row {} → missing {}",
+                table,
+                rowid.map(|r| r.to_string()).unwrap_or_else(|| "?".into()),
+                parent
+            );
+        }
+    }
+
+    let integrity: String = sqlx::query_scalar("PRAGMA integrity_check")
+        .fetch_one(pool)
+        .await?;
+    if integrity != "ok" {
+        problems += 1;
+        left += 1;
+        println!("{} {}", tr("error:").bad().bold(), tf("sqlite integrity check: {}", &[&integrity]));
+    }
+
+    println!();
+    if problems == 0 {
+        ui::ok(tr("database is healthy"));
+    } else if left == 0 {
+        println!("{} {}", tr("Summary:").heading().bold(), tf("{} problem(s) handled", &[&problems]));
+    } else if repair {
+        return Err(AppError::Invalid(tf("{} problem(s) left after repair", &[&left])).into());
+    } else {
+        return Err(
+            AppError::Invalid(tf("{} problem(s) found — run `lazarus doctor --repair` to fix them", &[&problems])).into()
+        );
+    }
+
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::testutil::{self, memory_db};
+
+    async fn count(pool: &SqlitePool, table: &str) -> i64 {
+        sqlx::query_scalar(&format!("SELECT COUNT(*) FROM {}", table))
+            .fetch_one(pool)
+            .await
+            .unwrap()
+    }
+
+    fn check(name: &str) -> &'static Check {
+        CHECKS.iter().find(|c| c.name == name).unwrap()
+    }
+
+    #[tokio::test]
+    async fn foreign_keys_are_on() {
+        let pool = memory_db().await;
+        let on: i64 = sqlx::query_scalar("PRAGMA foreign_keys").fetch_one(&pool).await.unwrap();
+        assert_eq!(on, 1);
+    }
+
+    #[tokio::test]
+    async fn deleting_a_session_cascades_to_its_sets() {
+        let pool = memory_db().await;
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        let block = testutil::program_block(&pool, "P", "A", &[&squat], "5").await;
+        let session = testutil::session(&pool, &block, "2024-01-01 10:00:00", None).await;
+        let tse = testutil::session_exercise(&pool, &session, &squat).await;
+        testutil::set(&pool, &tse, 100.0, 5, "completed", "2024-01-01 10:05:00").await;
+
+        sqlx::query("DELETE FROM training_sessions WHERE id = ?").bind(&session).execute(&pool).await.unwrap();
+        assert_eq!(count(&pool, "training_session_exercises").await, 0);
+        assert_eq!(count(&pool, "exercise_sets").await, 0);
+    }
+
+    #[tokio::test]
+    async fn deleting_a_program_cascades_to_blocks_and_exercises() {
+        let pool = memory_db().await;
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        testutil::program_block(&pool, "P", "A", &[&squat], "5").await;
+        testutil::program_block(&pool, "P", "B", &[&squat], "8").await;
+
+        sqlx::query("DELETE FROM programs").execute(&pool).await.unwrap();
+        assert_eq!(count(&pool, "program_blocks").await, 0);
+        assert_eq!(count(&pool, "program_exercises").await, 0);
+        assert_eq!(count(&pool, "exercises").await, 1);
+    }
+
+    #[tokio::test]
+    async fn a_block_with_sessions_cant_be_deleted() {
+        let pool = memory_db().await;
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        let block = testutil::program_block(&pool, "P", "A", &[&squat], "5").await;
+        testutil::session(&pool, &block, "2024-01-01 10:00:00", Some("2024-01-01 11:00:00")).await;
+
+        assert!(sqlx::query("DELETE FROM programs").execute(&pool).await.is_err());
+        assert_eq!(count(&pool, "program_blocks").await, 1);
+    }
+
+    #[tokio::test]
+    async fn orphaned_sets_are_found_and_repaired() {
+        let pool = memory_db().await;
+        // The in-memory pool has a single connection, so this sticks.
+        sqlx::query("PRAGMA foreign_keys = OFF").execute(&pool).await.unwrap();
+        testutil::set(&pool, "gone", 100.0, 5, "completed", "2024-01-01 10:05:00").await;
+        sqlx::query("PRAGMA foreign_keys = ON").execute(&pool).await.unwrap();
+
+        let orphans = check("sets without a session exercise");
+        let n: i64 = sqlx::query_scalar(orphans.count).fetch_one(&pool).await.unwrap();
+        assert_eq!(n, 1);
+        sqlx::query(orphans.repair).execute(&pool).await.unwrap();
+        let n: i64 = sqlx::query_scalar(orphans.count).fetch_one(&pool).await.unwrap();
+        assert_eq!(n, 0);
+    }
+
+    #[tokio::test]
+    async fn only_the_newest_open_session_stays_open() {
+        let pool = memory_db().await;
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        let block = testutil::program_block(&pool, "P", "A", &[&squat], "5").await;
+        let old = testutil::session(&pool, &block, "2024-01-01 10:00:00", None).await;
+        let tse = testutil::session_exercise(&pool, &old, &squat).await;
+        testutil::set(&pool, &tse, 100.0, 5, "completed", "2024-01-01 10:20:00").await;
+        let live = testutil::session(&pool, &block, "2024-01-03 10:00:00", None).await;
+
+        let stale = check("stale sessions lacking end_time");
+        sqlx::query(stale.repair).execute(&pool).await.unwrap();
+        let ends: Vec<(String, Option<String>)> = sqlx::query_as("SELECT id, end_time FROM training_sessions ORDER BY start_time")
+            .fetch_all(&pool)
+            .await
+            .unwrap();
+        assert_eq!(ends, vec![(old, Some("2024-01-01 10:20:00".to_string())), (live, None)]);
+    }
+
+    #[tokio::test]
+    async fn blocks_without_a_program_are_repaired_after_their_sessions() {
+        let pool = memory_db().await;
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        let block = testutil::program_block(&pool, "P", "A", &[&squat], "5").await;
+        let session = testutil::session(&pool, &block, "2024-01-01 10:00:00", Some("2024-01-01 11:00:00")).await;
+        let tse = testutil::session_exercise(&pool, &session, &squat).await;
+        testutil::set(&pool, &tse, 100.0, 5, "completed", "2024-01-01 10:05:00").await;
+        // The in-memory pool has a single connection, so this sticks.
+        sqlx::query("PRAGMA foreign_keys = OFF").execute(&pool).await.unwrap();
+        sqlx::query("DELETE FROM programs").execute(&pool).await.unwrap();
+        sqlx::query("PRAGMA foreign_keys = ON").execute(&pool).await.unwrap();
+
+        let exit_code = |e: anyhow::Error| e.downcast_ref::<AppError>().map(AppError::exit_code);
+        assert_eq!(exit_code(handle(&pool, false).await.unwrap_err()), Some(3));
+        assert_eq!(count(&pool, "program_blocks").await, 1);
+
+        handle(&pool, true).await.unwrap();
+        for table in ["training_sessions", "training_session_exercises", "exercise_sets", "program_blocks", "program_exercises"] {
+            assert_eq!(count(&pool, table).await, 0, "{}", table);
+        }
+        assert_eq!(count(&pool, "exercises").await, 1);
+        handle(&pool, false).await.unwrap();
+    }
+}
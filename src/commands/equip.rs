@@ -0,0 +1,120 @@
+use anyhow::Result;
+use sqlx::SqlitePool;
+
+use crate::{errors::AppError, i18n::{tf, tr}, storage::ExerciseStore, ui};
+
+/// Split a free-form note like "seat 4, handles B" into key/value pairs.
+/// Items may also use `key=value` or `key: value`; the value is the last word otherwise.
+fn parse_settings(note: &str) -> Vec<(String, String)> {
+    note.split(',')
+        .map(str::trim)
+        .filter(|item| !item.is_empty())
+        .map(|item| {
+            let (k, v) = item
+                .split_once(['=', ':'])
+                .or_else(|| item.rsplit_once(char::is_whitespace))
+                .unwrap_or((item, ""));
+            (k.trim().to_string(), v.trim().to_string())
+        })
+        .collect()
+}
+
+/// Settings of an exercise rendered as one line, e.g. "seat 4, handles B".
+pub async fn settings_line(pool: &SqlitePool, exercise_id: &str) -> Result<Option<String>> {
+    let rows: Vec<(String, String)> = sqlx::query_as(
+        "SELECT key, value FROM equipment_settings WHERE exercise_id = ? ORDER BY key",
+    )
+    .bind(exercise_id)
+    .fetch_all(pool)
+    .await?;
+
+    if rows.is_empty() {
+        return Ok(None);
+    }
+
+    Ok(Some(
+        rows.iter()
+            .map(|(k, v)| if v.is_empty() { k.clone() } else { format!("{} {}", k, v) })
+            .collect::<Vec<_>>()
+            .join(", "),
+    ))
+}
+
+/// A number is the exercise's index in the active session (like `session edit`),
+/// or its global index when no session is running; anything else is a name.
+async fn resolve_exercise(pool: &SqlitePool, exercise: &str) -> Result<Option<(String, String)>> {
+    if let Ok(n) = exercise.parse::<i64>() {
+        let in_session: Option<(String, String)> = sqlx::query_as(
+            r#"
+            SELECT e.id, e.name
+            FROM training_session_exercises tse
+            JOIN exercises e ON e.id = tse.exercise_id
+            WHERE tse.training_session_id = (SELECT id FROM current_session LIMIT 1)
+            ORDER BY tse.order_index
+            LIMIT 1 OFFSET ?
+            "#,
+        )
+        .bind(n - 1)
+        .fetch_optional(pool)
+        .await?;
+
+        if in_session.is_some() {
+            return Ok(in_session);
+        }
+    }
+    pool.exercise(exercise).await
+}
+
+pub async fn handle(
+    pool: &SqlitePool,
+    exercise: String,
+    note: Option<String>,
+    clear: bool,
+) -> Result<()> {
+    let Some((exercise_id, name)) = resolve_exercise(pool, &exercise).await? else {
+        return Err(AppError::NotFound(tf("no such exercise `{}`", &[&exercise]).into()).into());
+    };
+
+    let mut tx = pool.begin().await?;
+
+    if clear {
+        sqlx::query("DELETE FROM equipment_settings WHERE exercise_id = ?")
+            .bind(&exercise_id)
+            .execute(&mut *tx)
+            .await?;
+    }
+
+    if let Some(note) = &note {
+        let settings = parse_settings(note);
+        if settings.is_empty() {
+            return Err(AppError::Invalid(tr("empty note").into()).into());
+        }
+
+        // Known keys are overwritten, so "seat 5" later only moves the seat.
+        for (key, value) in settings {
+            sqlx::query(
+                r#"
+                INSERT INTO equipment_settings (exercise_id, key, value, updated_at)
+                VALUES (?, ?, ?, datetime('now'))
+                ON CONFLICT (exercise_id, key)
+                DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+                "#,
+            )
+            .bind(&exercise_id)
+            .bind(&key)
+            .bind(&value)
+            .execute(&mut *tx)
+            .await?;
+        }
+    }
+
+    tx.commit().await?;
+
+    match settings_line(pool, &exercise_id).await? {
+        Some(line) => ui::ok(format!("`{}`: {}", name, line)),
+        None if clear => ui::ok(tf("cleared settings for `{}`", &[&name])),
+        None => ui::info(tf("no settings saved for `{}`", &[&name])),
+    }
+
+    Ok(())
+}
@@ -0,0 +1,257 @@
+use std::collections::BTreeMap;
+
+use anyhow::{Context, Result};
+use colored::Colorize;
+use serde::Deserialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    cli::ExdbSource,
+    errors::AppError,
+    i18n::{tf, tr},
+    types::{ALLOWED_MUSCLES, cannonical_muscle},
+    ui::Themed,
+};
+
+const WGER_URL: &str = "https://wger.de/api/v2/exerciseinfo/?limit=200";
+const WGER_ENGLISH: i64 = 2;
+
+/// One page of the `exerciseinfo` endpoint. A raw dump saved with
+/// `curl` has the same shape, so both paths go through this struct.
+#[derive(Deserialize)]
+struct WgerPage {
+    next: Option<String>,
+    results: Vec<WgerExercise>,
+}
+
+#[derive(Deserialize)]
+struct WgerExercise {
+    category: Option<WgerNamed>,
+    #[serde(default)]
+    muscles: Vec<WgerMuscle>,
+    #[serde(default)]
+    translations: Vec<WgerTranslation>,
+    // Older dumps carry the english text at the top level.
+    name: Option<String>,
+    description: Option<String>,
+}
+
+#[derive(Deserialize)]
+struct WgerNamed {
+    name: String,
+}
+
+#[derive(Deserialize)]
+struct WgerMuscle {
+    name: String,
+    #[serde(default)]
+    name_en: String,
+}
+
+#[derive(Deserialize)]
+struct WgerTranslation {
+    name: String,
+    #[serde(default)]
+    description: String,
+    language: i64,
+}
+
+/// Map a wger muscle (latin or english name) to one of our muscles.
+fn map_wger_muscle(m: &WgerMuscle) -> Option<&'static str> {
+    let name = format!("{} {}", m.name, m.name_en).to_ascii_lowercase();
+    let table: &[(&str, &str)] = &[
+        ("biceps femoris", "hamstrings"),
+        ("biceps", "biceps"),
+        ("brachialis", "biceps"),
+        ("triceps", "triceps"),
+        ("deltoid", "shoulders"),
+        ("shoulders", "shoulders"),
+        ("pectoralis", "chest"),
+        ("chest", "chest"),
+        ("serratus", "chest"),
+        ("latissimus", "back"),
+        ("lats", "back"),
+        ("trapezius", "back"),
+        ("rectus abdominis", "abs"),
+        ("obliquus", "abs"),
+        ("abs", "abs"),
+        ("gastrocnemius", "calves"),
+        ("soleus", "calves"),
+        ("calves", "calves"),
+        ("gluteus", "glutes"),
+        ("glutes", "glutes"),
+        ("quadriceps", "quads"),
+        ("quads", "quads"),
+        ("hamstrings", "hamstrings"),
+    ];
+
+    table
+        .iter()
+        .find(|(needle, _)| name.contains(needle))
+        .map(|(_, muscle)| *muscle)
+}
+
+/// Fallback when an exercise lists no muscles: use its category, when that
+/// category is specific enough to mean a single muscle.
+fn map_wger_category(c: &WgerNamed) -> Option<&'static str> {
+    match c.name.to_ascii_lowercase().as_str() {
+        "chest" => Some("chest"),
+        "back" => Some("back"),
+        "shoulders" => Some("shoulders"),
+        "calves" => Some("calves"),
+        "abs" => Some("abs"),
+        _ => None,
+    }
+}
+
+/// wger descriptions are HTML snippets; keep only the text.
+fn strip_html(s: &str) -> String {
+    let mut out = String::with_capacity(s.len());
+    let mut in_tag = false;
+    for c in s.chars() {
+        match c {
+            '<' => in_tag = true,
+            '>' => {
+                in_tag = false;
+                out.push(' ');
+            }
+            _ if !in_tag => out.push(c),
+            _ => {}
+        }
+    }
+
+    out.replace("&nbsp;", " ")
+        .replace("&amp;", "&")
+        .split_whitespace()
+        .collect::<Vec<_>>()
+        .join(" ")
+}
+
+async fn fetch_wger() -> Result<Vec<WgerExercise>> {
+    let client = reqwest::Client::new();
+    let mut url = Some(WGER_URL.to_string());
+    let mut all = Vec::new();
+
+    while let Some(u) = url {
+        let page: WgerPage = client
+            .get(&u)
+            .send()
+            .await
+            .with_context(|| format!("Could not reach `{}`", u))?
+            .error_for_status()?
+            .json()
+            .await
+            .context("Failed to parse wger response")?;
+        all.extend(page.results);
+        url = page.next;
+    }
+
+    Ok(all)
+}
+
+fn read_wger_dump(file: &str) -> Result<Vec<WgerExercise>> {
+    let raw = std::fs::read_to_string(file)
+        .with_context(|| format!("Could not read file: `{}`", file))?;
+
+    // Accept both a saved API page and a bare array of exercises.
+    if let Ok(page) = serde_json::from_str::<WgerPage>(&raw) {
+        return Ok(page.results);
+    }
+    serde_json::from_str::<Vec<WgerExercise>>(&raw).context("Failed to parse wger JSON dump")
+}
+
+pub async fn handle(
+    pool: &SqlitePool,
+    source: ExdbSource,
+    muscle: String,
+    file: Option<String>,
+) -> Result<()> {
+    let filter = if muscle.eq_ignore_ascii_case("all") {
+        None
+    } else {
+        match cannonical_muscle(&muscle) {
+            Some(m) => Some(m),
+            None => {
+                let allowed = ALLOWED_MUSCLES.iter().cloned().collect::<Vec<_>>().join(", ");
+                let allowed = tf("all, {}", &[&allowed]);
+                return Err(AppError::Invalid(tf("unknown muscle `{}` (one of: {})", &[&muscle, &allowed])).into());
+            }
+        }
+    };
+
+    let raw = match (source, file) {
+        (ExdbSource::Wger, Some(f)) => read_wger_dump(&f)?,
+        (ExdbSource::Wger, None) => fetch_wger().await?,
+    };
+
+    // wger lists the same exercise once per translation set; keep the
+    // first english entry for each name.
+    let mut picked: BTreeMap<String, (String, &'static str)> = BTreeMap::new();
+    let mut unmapped = 0;
+    for ex in &raw {
+        let (name, desc) = match ex.translations.iter().find(|t| t.language == WGER_ENGLISH) {
+            Some(t) => (t.name.trim().to_string(), strip_html(&t.description)),
+            None => match &ex.name {
+                Some(n) => (
+                    n.trim().to_string(),
+                    strip_html(ex.description.as_deref().unwrap_or_default()),
+                ),
+                None => continue,
+            },
+        };
+        if name.is_empty() {
+            continue;
+        }
+
+        let musc = ex
+            .muscles
+            .iter()
+            .find_map(map_wger_muscle)
+            .or_else(|| ex.category.as_ref().and_then(map_wger_category));
+        let Some(musc) = musc else {
+            unmapped += 1;
+            continue;
+        };
+
+        if filter.as_deref().is_some_and(|f| f != musc) {
+            continue;
+        }
+
+        picked.entry(name).or_insert((desc, musc));
+    }
+
+    let mut inserted = 0;
+    let mut skipped = 0;
+    let mut tx = pool.begin().await?;
+    for (name, (desc, musc)) in &picked {
+        let res = sqlx::query(
+            r#"
+            INSERT OR IGNORE INTO exercises
+              (id, name, primary_muscle, description, created_at)
+            VALUES (?1, ?2, ?3, ?4, datetime('now'))
+            "#,
+        )
+        .bind(uuid::Uuid::new_v4().to_string())
+        .bind(name)
+        .bind(musc)
+        .bind(desc)
+        .execute(&mut *tx)
+        .await
+        .with_context(|| format!("DB error inserting `{}`", name))?;
+
+        if res.rows_affected() == 1 {
+            inserted += 1;
+        } else {
+            skipped += 1;
+        }
+    }
+    tx.commit().await?;
+
+    println!(
+        "{} {}",
+        tr("Summary:").heading().bold(),
+        tf("{} inserted, {} skipped (already exist), {} without a known muscle", &[&inserted, &skipped, &unmapped])
+    );
+
+    Ok(())
+}
@@ -4,12 +4,26 @@ use chrono::{DateTime, Utc};
 use crate::{
     OutputFmt,
     cli::ExerciseCmd,
-    types::{ALLOWED_MUSCLES, ExerciseImport, best_muscle_suggestions, cannonical_muscle, emit},
+    commands::{
+        demo::{handle_set, print_demo},
+        goal::print_goals,
+        session::tempo_suffix,
+        trend::e1rm_trend,
+    },
+    errors::AppError,
+    formula,
+    i18n::{display_db_date, kg, kg_places, number, tf, tr},
+    types::{
+        ALLOWED_MUSCLES, EQUIPMENT, ExerciseImport, Rounding, SET_LOAD, best_muscle_suggestions,
+        bodyweight_label, cannonical_muscle, emit,
+    },
+    ui::{self, Themed},
 };
 use anyhow::{Context, Result};
 use colored::Colorize;
 use serde::Serialize;
 use sqlx::{Row, SqlitePool};
+use strsim::jaro_winkler;
 
 #[derive(Serialize)]
 struct ExJson {
@@ -18,6 +32,32 @@ struct ExJson {
     primary_muscle: String,
     description: String,
     created_at: String,
+    unilateral: bool,
+}
+
+/// Names offered when an exercise isn't found.
+const SUGGESTED_NAMES: usize = 3;
+/// Jaro-Winkler similarity a name needs to be offered.
+const MIN_SIMILARITY: f64 = 0.75;
+
+/// Up to [`SUGGESTED_NAMES`] exercise names close to `input`, closest first.
+/// Names containing it, or contained in it, come before the rest.
+pub async fn similar_names(pool: &SqlitePool, input: &str) -> Result<Vec<String>> {
+    let names: Vec<String> = sqlx::query_scalar("SELECT name FROM exercises").fetch_all(pool).await?;
+    let input = input.trim().to_lowercase();
+    let mut scored: Vec<(f64, String)> = names
+        .into_iter()
+        .filter_map(|name| {
+            let n = name.to_lowercase();
+            let score = jaro_winkler(&input, &n);
+            match n.contains(&input) || input.contains(&n) {
+                true => Some((1.0 + score, name)),
+                false => (score >= MIN_SIMILARITY).then_some((score, name)),
+            }
+        })
+        .collect();
+    scored.sort_by(|a, b| b.0.total_cmp(&a.0));
+    Ok(scored.into_iter().take(SUGGESTED_NAMES).map(|(_, name)| name).collect())
 }
 
 fn plain_len(s: &str) -> usize {
@@ -114,6 +154,21 @@ fn create_ascii_graph(data: &[(DateTime<Utc>, f32)], width: usize, height: usize
     result
 }
 
+/// "2y 3m", "5m" or "3w" since a database timestamp.
+fn training_age(since: &str) -> String {
+    let Ok(first) = chrono::NaiveDate::parse_from_str(since.get(..10).unwrap_or_default(), "%Y-%m-%d") else {
+        return String::new();
+    };
+    let days = (chrono::Local::now().date_naive() - first).num_days().max(0);
+    let (years, months) = (days / 365, days % 365 / 30);
+    match (years, months) {
+        (0, 0) => tf("{}w", &[&(days / 7)]),
+        (0, m) => tf("{}m", &[&m]),
+        (y, 0) => tf("{}y", &[&y]),
+        (y, m) => tf("{}y {}m", &[&y, &m]),
+    }
+}
+
 async fn generate_progression_graph(
     exercise_id: &str,
     name: &str,
@@ -138,7 +193,7 @@ async fn generate_progression_graph(
     .await?;
 
     if sets.is_empty() {
-        println!("{} No data available for graph", "warning:".yellow().bold());
+        println!("{} {}", tr("warning:").accent().bold(), tr("No data available for graph"));
         return Ok(());
     }
 
@@ -161,13 +216,13 @@ async fn generate_progression_graph(
                 return None;
             };
             
-            let estimated_1rm = weight * (1.0 + reps as f32 / 30.0);
+            let estimated_1rm = formula::e1rm(weight as f64, reps as f64) as f32;
             Some((dt, estimated_1rm))
         })
         .collect();
 
     if data.is_empty() {
-        println!("{} No valid data available for graph", "warning:".yellow().bold());
+        println!("{} {}", tr("warning:").accent().bold(), tr("No valid data available for graph"));
         return Ok(());
     }
 
@@ -189,42 +244,51 @@ async fn generate_progression_graph(
     Ok(())
 }
 
-pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Result<()> {
+pub async fn handle(
+    cmd: ExerciseCmd,
+    pool: &SqlitePool,
+    fmt: OutputFmt,
+    imbalance_threshold: f32,
+) -> Result<()> {
     match cmd {
-        ExerciseCmd::Add { name, muscle, desc } => {
+        ExerciseCmd::Add { name, muscle, desc, unilateral, equipment } => {
+            let equipment = equipment.map(|e| e.to_ascii_lowercase());
+            if let Some(e) = equipment.as_deref().filter(|e| !EQUIPMENT.contains(e)) {
+                let allowed = EQUIPMENT.join(", ");
+                return Err(AppError::Invalid(tf("unknown equipment `{}` (one of: {})", &[&e, &allowed])).into());
+            }
+
             let res = sqlx::query(
                 r#"
                 INSERT INTO exercises
-                (id, name, primary_muscle, description, created_at)
-                VALUES (?1, ?2, ?3, ?4, datetime('now'))
+                (id, name, primary_muscle, description, created_at, unilateral, equipment)
+                VALUES (?1, ?2, ?3, ?4, datetime('now'), ?5, ?6)
                 "#,
             )
             .bind(uuid::Uuid::new_v4().to_string())
             .bind(&name)
             .bind(muscle.to_string())
             .bind(desc.unwrap_or_default())
+            .bind(unilateral as i32)
+            .bind(&equipment)
             .execute(pool)
             .await;
 
             match res {
                 Ok(info) if info.rows_affected() == 1 => {
-                    println!("{} Exercise \"{}\" added", "info:".blue().bold(), &name)
+                    ui::info(tf("Exercise \"{}\" added", &[&&name]))
                 }
-                Ok(_) => println!(
-                    "{} Exercise \"{}\" was not inserted",
-                    "info:".blue().bold(),
-                    &name
-                ),
+                Ok(_) => ui::info(tf("Exercise \"{}\" was not inserted", &[&&name])),
                 Err(sqlx::Error::Database(db_err)) if db_err.code() == Some("2067".into()) => {
                     // 2067 = SQLITE_CONSTRAINT_UNIQUE
                     println!(
-                        "{} Exercise \"{}\" already exists — use `ex list` to view all exercises",
-                        "warning:".yellow().bold(),
-                        name
+                        "{} {}",
+                        tr("warning:").accent().bold(),
+                        tf("Exercise \"{}\" already exists — use `ex list` to view all exercises", &[&name])
                     );
                 }
                 Err(e) => {
-                    println!("{} {}", "error:".red().bold(), e.to_string().red());
+                    println!("{} {}", tr("error:").bad().bold(), e.to_string().bad());
                     return Err(e.into());
                 }
             }
@@ -247,7 +311,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
             if import.exercise.is_empty() {
                 println!(
                     "{}",
-                    "warning: no [[exercise]] entries found".yellow().bold()
+                    tr("warning: no [[exercise]] entries found").accent().bold()
                 );
                 return Ok(());
             }
@@ -272,18 +336,15 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                             best_muscle_suggestions(&ex.primary_muscle.to_ascii_lowercase())
                         {
                             println!(
-                                "{} `{}` skipped – unknown muscle `{}` -- did you mean: `{}`?",
-                                "warning:".yellow().bold(),
-                                ex.name,
-                                ex.primary_muscle,
-                                sug.green()
+                                "{} {}",
+                                tr("warning:").accent().bold(),
+                                tf("`{}` skipped – unknown muscle `{}` -- did you mean: `{}`?", &[&ex.name, &ex.primary_muscle, &sug.good()])
                             );
                         } else {
                             println!(
-                                "{} `{}` skipped – unknown muscle `{}`",
-                                "warning:".yellow().bold(),
-                                ex.name,
-                                ex.primary_muscle
+                                "{} {}",
+                                tr("warning:").accent().bold(),
+                                tf("`{}` skipped – unknown muscle `{}`", &[&ex.name, &ex.primary_muscle])
                             );
                         }
 
@@ -298,14 +359,17 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                 let res = sqlx::query(
                     r#"
                     INSERT OR IGNORE INTO exercises
-                      (id, name, primary_muscle, description, created_at)
-                    VALUES (?1, ?2, ?3, ?4, datetime('now'))
+                      (id, name, primary_muscle, description, created_at, unilateral, equipment, rounding)
+                    VALUES (?1, ?2, ?3, ?4, datetime('now'), ?5, ?6, ?7)
                     "#,
                 )
                 .bind(uuid::Uuid::new_v4().to_string())
                 .bind(&ex.name)
                 .bind(&musc)
                 .bind(desc)
+                .bind(ex.unilateral as i32)
+                .bind(ex.equipment.as_deref().map(str::to_ascii_lowercase))
+                .bind(ex.rounding.as_deref().and_then(Rounding::parse).map(|r| r.to_string()))
                 .execute(pool)
                 .await
                 .with_context(|| format!("DB error inserting `{}`", ex.name))?;
@@ -319,17 +383,17 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
 
                 if res.rows_affected() == 1 {
                     inserted += 1;
-                    println!("{} `{}`", "ok:".green().bold(), ex.name);
+                    ui::ok(format!("`{}`", ex.name));
                 } else {
                     skipped += 1;
-                    println!("{} `{}` (already exists)", "info:".blue().bold(), ex.name);
+                    ui::info(tf("`{}` (already exists)", &[&ex.name]));
                 }
             }
 
             // Summary.
             println!(
                 "\n{} {} inserted, {} skipped",
-                "Summary:".cyan().bold(),
+                tr("Summary:").heading().bold(),
                 inserted,
                 skipped
             );
@@ -345,11 +409,12 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                 let bad = unknowns.into_iter().collect::<Vec<_>>().join(", ");
 
                 println!();
-                println!("{} {}", "Unknown muscles:".yellow().bold(), bad);
-                println!("{} {}", "Allowed muscles:".cyan().bold(), allowed);
+                println!("{} {}", tr("Unknown muscles:").accent().bold(), bad);
+                println!("{} {}", tr("Allowed muscles:").heading().bold(), allowed);
                 println!(
-                    "{} You can write in any case sensitive manner (e.g. `chest` == `CHEST` == `Chest`)",
-                    "Note:".blue().bold()
+                    "{} {}",
+                    tr("Note:").info().bold(),
+                    tr("You can write in any case sensitive manner (e.g. `chest` == `CHEST` == `Chest`)")
                 )
             }
         }
@@ -358,7 +423,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
             let base = "
                 SELECT idx, name, primary_muscle, 
                 COALESCE(description, '') AS description, 
-                created_at
+                created_at, unilateral
                 FROM exercises
             ";
 
@@ -379,11 +444,12 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                     primary_muscle: r.get("primary_muscle"),
                     description: r.get("description"),
                     created_at: r.get("created_at"),
+                    unilateral: r.get::<i32, _>("unilateral") != 0,
                 })
                 .collect();
 
             emit(fmt, &json_rows, || {
-                println!("{}", "Exercises:".cyan().bold());
+                println!("{}", tr("Exercises:").heading().bold());
 
                 // ---------- widths
                 let idx_w = json_rows
@@ -398,17 +464,23 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
 
                 for ex in &json_rows {
                     // exercise row
-                    let idx_col = format!("{:>width$}", ex.idx, width = idx_w).yellow();
+                    let idx_col = format!("{:>width$}", ex.idx, width = idx_w).accent();
                     let desc = if ex.description.is_empty() {
                         String::new()
                     } else {
                         format!("– {}", ex.description).dimmed().to_string()
                     };
+                    let uni = if ex.unilateral {
+                        " [L/R]".highlight().to_string()
+                    } else {
+                        String::new()
+                    };
                     left.push(format!(
-                        " {} • {} ({}) {}",
+                        " {} • {} ({}){} {}",
                         idx_col,
                         ex.name.bold(),
-                        ex.primary_muscle.yellow(),
+                        ex.primary_muscle.accent(),
+                        uni,
                         desc
                     ));
                     right.push(
@@ -428,7 +500,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                     println!(
                         "{:<total_pad$} {} {}",
                         l,
-                        "|".blue(),
+                        "|".info(),
                         r,
                         total_pad = total_pad
                     );
@@ -440,6 +512,66 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
             });
         }
 
+        ExerciseCmd::Unilateral { exercise, off } => {
+            let res = match exercise.parse::<i64>() {
+                Ok(idx) => sqlx::query("UPDATE exercises SET unilateral = ? WHERE idx = ?")
+                    .bind(!off as i32)
+                    .bind(idx)
+                    .execute(pool)
+                    .await?,
+                Err(_) => sqlx::query("UPDATE exercises SET unilateral = ? WHERE name = ?")
+                    .bind(!off as i32)
+                    .bind(&exercise)
+                    .execute(pool)
+                    .await?,
+            };
+
+            if res.rows_affected() == 0 {
+                return Err(AppError::NotFound(tf("no such exercise `{}`", &[&exercise]).into()).into());
+            }
+
+            let kind = if off { "bilateral" } else { "unilateral" };
+            ui::ok(tf("`{}` is now {}", &[&exercise, &kind]));
+        }
+
+        ExerciseCmd::Rounding { exercise, profile } => {
+            let profile = match profile.as_deref().map(|p| (p, Rounding::parse(p))) {
+                Some((p, None)) => {
+                    return Err(AppError::Invalid(tf(
+                        "invalid rounding `{}` (e.g. barbell, dumbbell, lb, 2.5, 1kg, 10lb)",
+                        &[&p],
+                    ))
+                    .into());
+                }
+                Some((_, Some(r))) => Some(r),
+                None => None,
+            };
+
+            let res = match exercise.parse::<i64>() {
+                Ok(idx) => sqlx::query("UPDATE exercises SET rounding = ? WHERE idx = ?")
+                    .bind(profile.map(|r| r.to_string()))
+                    .bind(idx)
+                    .execute(pool)
+                    .await?,
+                Err(_) => sqlx::query("UPDATE exercises SET rounding = ? WHERE name = ?")
+                    .bind(profile.map(|r| r.to_string()))
+                    .bind(&exercise)
+                    .execute(pool)
+                    .await?,
+            };
+
+            if res.rows_affected() == 0 {
+                return Err(AppError::NotFound(tf("no such exercise `{}`", &[&exercise]).into()).into());
+            }
+
+            match profile {
+                Some(r) => ui::ok(tf("`{}` rounds to {}", &[&exercise, &r])),
+                None => ui::ok(tf("`{}` uses the default rounding", &[&exercise])),
+            }
+        }
+
+        ExerciseCmd::Demo { exercise, image, cues } => handle_set(pool, exercise, image, cues).await?,
+
         ExerciseCmd::Delete { exercise } => {
             // Resolve exercise to its idx.
             let idx: i64 = if let Ok(n) = exercise.parse::<i64>() {
@@ -454,8 +586,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                 {
                     Ok(n) => n,
                     Err(_) => {
-                        println!("{} no such exercise `{}`", "error:".red().bold(), exercise);
-                        return Ok(());
+                        return Err(AppError::NotFound(tf("no such exercise `{}`", &[&exercise]).into()).into());
                     }
                 }
             };
@@ -472,10 +603,10 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                 .execute(pool)
                 .await?;
 
-            println!("{} deleted exercise `{}`", "ok:".green().bold(), name);
+            ui::ok(tf("deleted exercise `{}`", &[&name]));
         }
 
-        ExerciseCmd::Show { exercise, graph } => {
+        ExerciseCmd::Show { exercise, graph, image } => {
             let exercise = exercise.join(" ");
             
             // Resolve exercise to its ID
@@ -487,10 +618,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                     .await?
                 {
                     Some(id) => id,
-                    None => {
-                        println!("{} no exercise at index {}", "error:".red().bold(), idx);
-                        return Ok(());
-                    }
+                    None => return Err(AppError::ExerciseNotFound(idx.to_string()).into()),
                 }
             } else {
                 // User passed a name - look up by exact name
@@ -500,10 +628,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                     .await?
                 {
                     Some(id) => id,
-                    None => {
-                        println!("{} no exercise named `{}`", "error:".red().bold(), exercise);
-                        return Ok(());
-                    }
+                    None => return Err(AppError::ExerciseNotFound(exercise.to_string()).into()),
                 }
             };
 
@@ -519,6 +644,9 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                 generate_progression_graph(&exercise_id, &name, pool).await?;
                 return Ok(());
             }
+            if image {
+                print_demo(pool, &exercise_id).await?;
+            }
 
             // Get last performed date and total sessions
             let (last_performed, total_sessions): (Option<String>, i64) = sqlx::query_as(
@@ -542,7 +670,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
 
             // Get current PR info
             let (pr_weight, pr_reps, pr_date, pr_1rm): (Option<f32>, Option<i32>, Option<String>, Option<f32>) = sqlx::query_as(
-                r#"
+                &format!(r#"
                 WITH all_sets AS (
                     SELECT 
                         es.weight,
@@ -550,7 +678,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                         es.timestamp,
                         CASE 
                             WHEN es.bodyweight = 1 THEN 0
-                            ELSE CAST(es.weight AS REAL) * (1 + CAST(es.reps AS REAL) / 30)
+                            ELSE {e1rm}
                         END as estimated_1rm
                     FROM exercise_sets es
                     JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
@@ -565,7 +693,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                 FROM all_sets
                 ORDER BY estimated_1rm DESC, weight DESC, reps DESC
                 LIMIT 1
-                "#,
+                "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
             )
             .bind(&exercise_id)
             .fetch_optional(pool)
@@ -574,7 +702,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
 
             // Get 30-day PR change
             let (prev_pr_1rm, _prev_pr_date): (Option<f32>, Option<String>) = sqlx::query_as(
-                r#"
+                &format!(r#"
                 WITH all_sets AS (
                     SELECT 
                         es.weight,
@@ -582,7 +710,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                         es.timestamp,
                         CASE 
                             WHEN es.bodyweight = 1 THEN 0
-                            ELSE CAST(es.weight AS REAL) * (1 + CAST(es.reps AS REAL) / 30)
+                            ELSE {e1rm}
                         END as estimated_1rm
                     FROM exercise_sets es
                     JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
@@ -596,7 +724,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                 FROM all_sets
                 ORDER BY estimated_1rm DESC, weight DESC, reps DESC
                 LIMIT 1
-                "#,
+                "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
             )
             .bind(&exercise_id)
             .fetch_optional(pool)
@@ -631,17 +759,28 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
             .fetch_one(pool)
             .await?;
 
-            // Get lifetime volume stats
-            let (total_sets, total_reps, total_tonnage): (i64, i64, f64) = sqlx::query_as(
-                r#"
+            // Get lifetime stats: volume, first-ever set and the
+            // weekly frequency since then (a first week counts as a whole week)
+            let (total_sets, total_reps, total_tonnage, first_set, lifetime_freq): (
+                i64,
+                i64,
+                f64,
+                Option<String>,
+                Option<f64>,
+            ) = sqlx::query_as(
+                &format!(r#"
                 SELECT 
                     CAST(COUNT(*) AS INTEGER) as sets,
                     CAST(COALESCE(SUM(CAST(reps AS INTEGER)), 0) AS INTEGER) as reps,
-                    CAST(COALESCE(SUM(CAST(weight AS REAL) * CAST(reps AS INTEGER)), 0) AS REAL) as tonnage
+                    CAST(COALESCE(SUM({load} * CAST(reps AS INTEGER)), 0) AS REAL) as tonnage,
+                    MIN(es.timestamp) as first_set,
+                    COUNT(DISTINCT tse.training_session_id) * 7.0
+                        / MAX(7.0, julianday('now') - julianday(MIN(es.timestamp))) as per_week
                 FROM exercise_sets es
                 JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
                 WHERE tse.exercise_id = ?
-                "#
+                AND es.status != 'skipped'
+                "#, load = SET_LOAD)
             )
             .bind(&exercise_id)
             .fetch_one(pool)
@@ -673,16 +812,17 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
             .await?;
 
             // Get top 5 heaviest sets
-            let top_sets: Vec<(f32, i32, String)> = sqlx::query_as(
-                r#"
+            let top_sets: Vec<(f32, i32, String, bool)> = sqlx::query_as(
+                &format!(r#"
                 WITH set_volumes AS (
                     SELECT 
                         CAST(weight AS REAL) as weight,
                         CAST(reps AS INTEGER) as reps,
                         timestamp,
+                        bodyweight,
                         CASE 
                             WHEN bodyweight = 1 THEN 0
-                            ELSE CAST(weight AS REAL) * (1 + CAST(reps AS REAL) / 30)
+                            ELSE {e1rm_bare}
                         END as estimated_1rm
                     FROM exercise_sets es
                     JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
@@ -692,32 +832,36 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                 SELECT 
                     weight,
                     reps,
-                    timestamp
+                    timestamp,
+                    bodyweight
                 FROM set_volumes
                 ORDER BY estimated_1rm DESC, weight DESC, reps DESC
                 LIMIT 5
-                "#,
+                "#, e1rm_bare = formula::e1rm_sql("weight", "reps")),
             )
             .bind(&exercise_id)
             .fetch_all(pool)
             .await?;
 
             // Get last 10 sets with PR information
-            let last_sets: Vec<(String, f32, i32, Option<f32>, bool)> = sqlx::query_as(
-                r#"
+            let last_sets: Vec<(String, f32, i32, Option<f32>, bool, Option<String>, Option<String>, bool)> = sqlx::query_as(
+                &format!(r#"
                 WITH set_info AS (
                     SELECT 
                         es.timestamp,
                         CAST(es.weight AS REAL) as weight,
                         CAST(es.reps AS INTEGER) as reps,
                         CAST(es.rpe AS REAL) as rpe,
+                        es.tempo,
+                        es.pause,
+                        es.bodyweight,
                         CASE 
                             WHEN es.bodyweight = 1 THEN 0
-                            ELSE CAST(es.weight AS REAL) * (1 + CAST(es.reps AS REAL) / 30)
+                            ELSE {e1rm}
                         END as estimated_1rm,
                         ROW_NUMBER() OVER (
                             ORDER BY 
-                                CAST(es.weight AS REAL) * (1 + CAST(es.reps AS REAL) / 30) DESC,
+                                {e1rm} DESC,
                                 es.timestamp DESC
                         ) as set_rank
                     FROM exercise_sets es
@@ -732,10 +876,13 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                     weight,
                     reps,
                     rpe,
-                    set_rank = 1 as is_pr
+                    set_rank = 1 as is_pr,
+                    tempo,
+                    pause,
+                    bodyweight
                 FROM set_info
                 ORDER BY timestamp DESC
-                "#,
+                "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
             )
             .bind(&exercise_id)
             .fetch_all(pool)
@@ -744,16 +891,16 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
             // Print exercise header
             println!(
                 "{}: {} ({})",
-                "Exercise".cyan().bold(),
+                tr("Exercise").heading().bold(),
                 name.bold(),
-                muscle.yellow()
+                muscle.accent()
             );
             println!(
                 "{}: {} | {}: {} | {}: {}",
                 "Added".dimmed(),
-                &created_at[..10],
+                display_db_date(&created_at),
                 "Last performed".dimmed(),
-                last_performed.map_or("never".to_string(), |d| d[..10].to_string()),
+                last_performed.map_or("never".to_string(), |d| display_db_date(&d)),
                 "Total sessions".dimmed(),
                 total_sessions
             );
@@ -763,17 +910,70 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
             if let (Some(w), Some(r), Some(d), Some(rm)) = (pr_weight, pr_reps, pr_date, pr_1rm) {
                 println!(
                     "{}: {}kg × {}  (1 RM est: {}kg)  on {}",
-                    "Current PR".cyan().bold(),
-                    w,
+                    tr("Current PR").heading().bold(),
+                    kg(w),
                     r,
                     rm.round(),
-                    &d[..10]
+                    display_db_date(&d)
                 );
             }
+            let tested: Option<(f32, String)> = sqlx::query_as(
+                "SELECT weight, date FROM personal_records WHERE exercise_id = ? AND verified = 1 ORDER BY date DESC LIMIT 1",
+            )
+            .bind(&exercise_id)
+            .fetch_optional(pool)
+            .await?;
+            if let Some((w, d)) = tested {
+                println!("{}: {}kg  on {}", tr("Tested 1RM").heading().bold(), kg(w), display_db_date(&d));
+            }
+            if let Some(t) = e1rm_trend(pool, &exercise_id).await? {
+                println!(
+                    "{}: {}kg  {}",
+                    tr("e1RM trend").heading().bold(),
+                    t.estimate_kg.round(),
+                    tf(
+                        "(likely {}–{}kg over {} sessions)",
+                        &[&t.low_kg.round(), &t.high_kg.round(), &t.sessions]
+                    )
+                    .dimmed()
+                );
+            }
+            // Bodyweight sets done at a known bodyweight: total load and e1RM
+            // relative to it, e.g. a pull-up with 40kg at 80kg is 1.5×BW.
+            let bw_sets: Vec<(f64, i64, f64, String)> = sqlx::query_as(
+                r#"
+                SELECT CAST(es.weight AS REAL), es.reps, CAST(es.body_mass AS REAL), es.timestamp
+                FROM exercise_sets es
+                JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+                WHERE tse.exercise_id = ?
+                AND es.bodyweight = 1 AND es.body_mass > 0 AND es.reps > 0
+                AND es.status != 'skipped'
+                "#,
+            )
+            .bind(&exercise_id)
+            .fetch_all(pool)
+            .await?;
+            let best_relative = bw_sets
+                .iter()
+                .map(|(added, reps, bm, at)| (formula::e1rm(bm + added, *reps as f64) / bm, added, reps, bm, at))
+                .max_by(|a, b| a.0.total_cmp(&b.0));
+            if let Some((ratio, added, reps, bm, at)) = best_relative {
+                println!(
+                    "{}: {}  {}",
+                    tr("Relative strength").heading().bold(),
+                    tf("{}×BW e1RM", &[&number(ratio, 2)]),
+                    tf(
+                        "({} at {}kg bodyweight on {})",
+                        &[&bodyweight_label(*added as f32, *reps as i32), &kg(*bm), &display_db_date(at)]
+                    )
+                    .dimmed()
+                );
+            }
+            print_goals(pool, Some(&exercise_id)).await?;
 
             // Get PR progression history
             let pr_history: Vec<(String, f32, i32, f32)> = sqlx::query_as(
-                r#"
+                &format!(r#"
                 WITH pr_progression AS (
                     SELECT 
                         es.timestamp,
@@ -781,11 +981,11 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                         CAST(es.reps AS INTEGER) as reps,
                         CASE 
                             WHEN es.bodyweight = 1 THEN 0
-                            ELSE CAST(es.weight AS REAL) * (1 + CAST(es.reps AS REAL) / 30)
+                            ELSE {e1rm}
                         END as estimated_1rm,
                         MAX(CASE 
                             WHEN es.bodyweight = 1 THEN 0
-                            ELSE CAST(es.weight AS REAL) * (1 + CAST(es.reps AS REAL) / 30)
+                            ELSE {e1rm}
                         END) OVER (ORDER BY es.timestamp ROWS UNBOUNDED PRECEDING) as running_max_1rm
                     FROM exercise_sets es
                     JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
@@ -811,7 +1011,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                 WHERE estimated_1rm = running_max_1rm
                 AND (prev_max_1rm < running_max_1rm OR prev_max_1rm = 0)
                 ORDER BY timestamp ASC
-                "#,
+                "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
             )
             .bind(&exercise_id)
             .fetch_all(pool)
@@ -820,7 +1020,7 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
             // Print PR progression timeline
             if !pr_history.is_empty() {
                 println!();
-                println!("{}", "PR Progression".cyan().bold());
+                println!("{}", tr("PR Progression").heading().bold());
                 let mut pr_line = String::new();
                 for (i, (timestamp, weight, reps, _)) in pr_history.iter().enumerate() {
                     if i > 0 {
@@ -828,32 +1028,127 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
                     }
                     pr_line.push_str(&format!(
                         "{}kg×{} ({})",
-                        weight,
+                        kg(*weight),
                         reps,
-                        &timestamp[..10]
+                        display_db_date(timestamp)
                     ));
                 }
                 println!("  {}\n", pr_line);
             }
 
+            // AMRAP sets are the progression signal for 5/3/1-style programs:
+            // one line per week with its best AMRAP and the rep change.
+            let amrap_history: Vec<(String, f32, i32)> = sqlx::query_as(
+                &format!(r#"
+                WITH amraps AS (
+                    SELECT 
+                        strftime('%Y-W%W', es.timestamp) as week,
+                        CAST(es.weight AS REAL) as weight,
+                        CAST(es.reps AS INTEGER) as reps,
+                        ROW_NUMBER() OVER (
+                            PARTITION BY strftime('%Y-W%W', es.timestamp)
+                            ORDER BY {e1rm} DESC
+                        ) as rn
+                    FROM exercise_sets es
+                    JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+                    WHERE tse.exercise_id = ?
+                    AND es.amrap = 1
+                )
+                SELECT week, weight, reps
+                FROM amraps
+                WHERE rn = 1
+                ORDER BY week
+                "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
+            )
+            .bind(&exercise_id)
+            .fetch_all(pool)
+            .await?;
+
+            if !amrap_history.is_empty() {
+                println!("{}", tr("AMRAP progression").heading().bold());
+                let mut prev: Option<(f32, i32)> = None;
+                for (week, weight, reps) in &amrap_history {
+                    let delta = match prev {
+                        Some((pw, pr)) if pw == *weight => {
+                            let d = reps - pr;
+                            let s = format!("{:+} reps", d);
+                            if d > 0 { s.good().to_string() } else if d < 0 { s.bad().to_string() } else { s.dimmed().to_string() }
+                        }
+                        Some(_) => "new weight".dimmed().to_string(),
+                        None => String::new(),
+                    };
+                    println!("  {}  {}kg × {}+   {}", week, kg(*weight), reps, delta);
+                    prev = Some((*weight, *reps));
+                }
+                println!();
+            }
+
+            // Left/right balance over the last 8 weeks: best e1RM per side.
+            let sides: Vec<(String, f32, i64)> = sqlx::query_as(
+                &format!(r#"
+                SELECT
+                    es.side,
+                    CAST(MAX({e1rm}) AS REAL),
+                    CAST(SUM(es.reps) AS INTEGER)
+                FROM exercise_sets es
+                JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+                WHERE tse.exercise_id = ?
+                AND es.side IS NOT NULL
+                AND es.timestamp >= datetime('now', '-56 days')
+                GROUP BY es.side
+                ORDER BY es.side
+                "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
+            )
+            .bind(&exercise_id)
+            .fetch_all(pool)
+            .await?;
+
+            if let [(_, left_rm, left_reps), (_, right_rm, right_reps)] = sides.as_slice() {
+                println!("{}", tr("Left/right balance (8 w)").heading().bold());
+                println!(
+                    "  L: {}kg e1RM, {} reps   R: {}kg e1RM, {} reps",
+                    kg_places(*left_rm, 1),
+                    left_reps,
+                    kg_places(*right_rm, 1),
+                    right_reps
+                );
+                let (strong, weak) = (left_rm.max(*right_rm), left_rm.min(*right_rm));
+                if strong > 0.0 {
+                    let gap = (strong - weak) / strong * 100.0;
+                    let weaker = if left_rm < right_rm { "left" } else { "right" };
+                    if gap > imbalance_threshold {
+                        println!(
+                            "  {} {} side is {:.1}% weaker (threshold {}%)",
+                            tr("warning:").accent().bold(),
+                            weaker,
+                            gap,
+                            imbalance_threshold
+                        );
+                    } else {
+                        println!("  {}", format!("within {:.1}%", gap).dimmed());
+                    }
+                }
+                println!();
+            }
+
             // Print 30-day changes
             if let (Some(prev_rm), _) = (prev_pr_1rm, _prev_pr_date) {
                 let diff = pr_1rm.unwrap_or(0.0) - prev_rm;
                 let pct = (diff / prev_rm) * 100.0;
                 let arrow = if diff > 0.0 { "▲" } else { "▼" };
                 println!(
-                    "{} {} {:.1} kg  ({:+.1} %)",
-                    "30-day 1 RM change:".cyan().bold(),
+                    "{} {} {} kg  ({} %)",
+                    tr("30-day 1 RM change:").heading().bold(),
                     arrow,
-                    diff.abs(),
-                    pct
+                    kg_places(diff.abs(), 1),
+                    number(pct as f64, 1)
                 );
             }
 
             if let (Some(curr), Some(prev)) = (current_tonnage, prev_tonnage) {
                 println!(
                     "{}: {:.0} kg   (prev 30 d: {:.0} kg)",
-                    "30-day tonnage".cyan().bold(),
+                    tr("30-day tonnage").heading().bold(),
                     curr,
                     prev
                 );
@@ -863,55 +1158,67 @@ pub async fn handle(cmd: ExerciseCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resu
             // Print lifetime stats
             println!(
                 "{}: {} sets  – {} reps  – {:.0} t",
-                "Lifetime volume".cyan().bold(),
+                tr("Lifetime volume").heading().bold(),
                 total_sets,
                 total_reps,
                 total_tonnage
             );
+            if let (Some(first), Some(freq)) = (&first_set, lifetime_freq) {
+                println!(
+                    "{}: {}",
+                    tr("Training age").heading().bold(),
+                    tf(
+                        "since {} ({}) – {} sessions – {} / week",
+                        &[&display_db_date(first), &training_age(first), &total_sessions, &format!("{:.1}", freq)]
+                    )
+                );
+            }
 
             if let (Some(freq), Some(gap)) = (avg_freq, longest_gap) {
                 println!(
                     "{}: {:.1} sessions / week | {}: {} days",
-                    "Avg frequency (8 w)".cyan().bold(),
+                    tr("Avg frequency (8 w)").heading().bold(),
                     freq,
-                    "Longest gap".cyan().bold(),
+                    tr("Longest gap").heading().bold(),
                     gap
                 );
             }
             println!();
 
             // Print top 5 heaviest sets
-            println!("{}", "Top 5 heaviest sets".cyan().bold());
-            for (weight, reps, timestamp) in top_sets {
-                println!("  {}kg × {}   {}", weight, reps, &timestamp[..10]);
+            println!("{}", tr("Top 5 heaviest sets").heading().bold());
+            for (weight, reps, timestamp, bw) in top_sets {
+                let set_info = if bw { bodyweight_label(weight, reps) } else { format!("{}kg × {}", kg(weight), reps) };
+                println!("  {}   {}", set_info, display_db_date(&timestamp));
             }
             println!();
 
             // Print last 10 sets
-            println!("{}", "Last 10 sets".cyan().bold());
-            for (timestamp, weight, reps, rpe, is_pr) in last_sets {
-                let set_info = if weight == 0.0 {
-                    format!("bw × {}", reps)
+            println!("{}", tr("Last 10 sets").heading().bold());
+            for (timestamp, weight, reps, rpe, is_pr, tempo, pause, bw) in last_sets {
+                let set_info = if bw || weight == 0.0 {
+                    bodyweight_label(weight, reps)
                 } else {
-                    format!("{}kg × {}", weight, reps)
+                    format!("{}kg × {}", kg(weight), reps)
                 };
 
                 let rpe_info = rpe.map_or(String::new(), |r| format!("   @RPE {}", r));
+                let rpe_info = format!("{}{}", rpe_info, tempo_suffix(tempo.as_deref(), pause.as_deref()));
                 let pr_mark = if is_pr {
-                    "   ← PR".green().to_string()
+                    tr("   ← PR").good().to_string()
                 } else {
                     String::new()
                 };
 
                 let set_display = if is_pr {
-                    set_info.green().to_string()
+                    set_info.good().to_string()
                 } else {
                     set_info
                 };
 
                 println!(
-                    "  {}  {}{}{}",
-                    &timestamp[..10],
+                    "  {:<10}  {}{}{}",
+                    display_db_date(&timestamp),
                     set_display,
                     rpe_info.dimmed(),
                     pr_mark
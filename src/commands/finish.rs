@@ -0,0 +1,281 @@
+use anyhow::Result;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::program::{handle_archive, resolve_program},
+    formula,
+    i18n::{kg, tf, tr},
+    types::{OutputFmt, SET_LOAD, emit},
+    ui::Themed,
+};
+
+#[derive(Serialize)]
+struct ProgramSummary {
+    program: String,
+    sessions: i64,
+    first: Option<String>,
+    last: Option<String>,
+    tonnage_kg: f64,
+    planned: i64,
+    done: i64,
+    adherence_pct: Option<f64>,
+    lifts: Vec<LiftChange>,
+    prs: Vec<ProgramPr>,
+}
+
+/// Best set of a main lift in its first and last session of the program.
+#[derive(Serialize)]
+struct LiftChange {
+    exercise: String,
+    before_e1rm: Option<f64>,
+    after_e1rm: Option<f64>,
+    before_top: Option<(f64, i64)>,
+    after_top: Option<(f64, i64)>,
+}
+
+#[derive(Serialize)]
+struct ProgramPr {
+    exercise: String,
+    date: String,
+    weight: f64,
+    reps: i64,
+    e1rm: f64,
+}
+
+async fn collect(pool: &SqlitePool, prog_id: &str, program: String) -> Result<ProgramSummary> {
+    let (sessions, first, last): (i64, Option<String>, Option<String>) = sqlx::query_as(
+        r#"
+        SELECT CAST(COUNT(*) AS INTEGER), MIN(date(ts.start_time)), MAX(date(ts.start_time))
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        WHERE pb.program_id = ? AND ts.end_time IS NOT NULL
+        "#,
+    )
+    .bind(prog_id)
+    .fetch_one(pool)
+    .await?;
+
+    let tonnage_kg: f64 = sqlx::query_scalar(
+        &format!(r#"
+        SELECT COALESCE(SUM({load} * es.reps), 0)
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+        JOIN exercise_sets es ON es.session_exercise_id = tse.id
+        WHERE pb.program_id = ? AND ts.end_time IS NOT NULL
+        "#, load = SET_LOAD),
+    )
+    .bind(prog_id)
+    .fetch_one(pool)
+    .await?;
+
+    // A program with weeks plans one session per block; one without repeats
+    // every block each week between the first and the last session.
+    let (blocks, weekly, blocks_done, weeks): (i64, bool, i64, i64) = sqlx::query_as(
+        r#"
+        SELECT
+            CAST(COUNT(*) AS INTEGER),
+            COUNT(pb.week) > 0,
+            CAST(COALESCE(SUM(EXISTS (
+                SELECT 1 FROM training_sessions ts
+                WHERE ts.program_block_id = pb.id AND ts.end_time IS NOT NULL
+            )), 0) AS INTEGER),
+            CAST(COALESCE((julianday(?) - julianday(?)) / 7, 0) AS INTEGER) + 1
+        FROM program_blocks pb
+        WHERE pb.program_id = ?
+        "#,
+    )
+    .bind(&last)
+    .bind(&first)
+    .bind(prog_id)
+    .fetch_one(pool)
+    .await?;
+    let (planned, done) = if weekly {
+        (blocks, blocks_done)
+    } else {
+        (blocks * weeks, sessions.min(blocks * weeks))
+    };
+    let adherence_pct = (planned > 0 && sessions > 0).then(|| done as f64 / planned as f64 * 100.0);
+
+    // Main lifts are the first exercise of each block, as in `p show -m`.
+    let main_lifts: Vec<(String, String)> = sqlx::query_as(
+        r#"
+        SELECT DISTINCT e.id, e.name
+        FROM program_blocks pb
+        JOIN program_exercises pe ON pe.program_block_id = pb.id
+        JOIN exercises e ON e.id = pe.exercise_id
+        WHERE pb.program_id = ?
+        AND pe.order_index = (SELECT MIN(order_index) FROM program_exercises WHERE program_block_id = pb.id)
+        ORDER BY e.name
+        "#,
+    )
+    .bind(prog_id)
+    .fetch_all(pool)
+    .await?;
+
+    let mut lifts = Vec::new();
+    for (ex_id, name) in main_lifts {
+        let sets: Vec<(String, f64, i64, bool)> = sqlx::query_as(
+            r#"
+            SELECT ts.id, es.weight, es.reps, es.bodyweight
+            FROM training_sessions ts
+            JOIN program_blocks pb ON pb.id = ts.program_block_id
+            JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+            JOIN exercise_sets es ON es.session_exercise_id = tse.id
+            WHERE pb.program_id = ? AND ts.end_time IS NOT NULL
+            AND tse.exercise_id = ?
+            ORDER BY ts.start_time, es.timestamp
+            "#,
+        )
+        .bind(prog_id)
+        .bind(&ex_id)
+        .fetch_all(pool)
+        .await?;
+
+        let mut by_session: Vec<(String, Vec<(f64, i64, bool)>)> = Vec::new();
+        for (session, w, r, bw) in sets {
+            match by_session.last_mut() {
+                Some((s, v)) if *s == session => v.push((w, r, bw)),
+                _ => by_session.push((session, vec![(w, r, bw)])),
+            }
+        }
+
+        let top = |sets: &[(f64, i64, bool)]| {
+            sets.iter()
+                .filter(|(w, _, bw)| *w > 0.0 && !bw)
+                .map(|(w, r, _)| (formula::e1rm(*w, *r as f64), (*w, *r)))
+                .max_by(|a, b| a.0.total_cmp(&b.0))
+        };
+        let before = by_session.first().and_then(|(_, s)| top(s.as_slice()));
+        let after = by_session.last().and_then(|(_, s)| top(s.as_slice()));
+        lifts.push(LiftChange {
+            exercise: name,
+            before_e1rm: before.map(|t| t.0),
+            after_e1rm: after.map(|t| t.0),
+            before_top: before.map(|t| t.1),
+            after_top: after.map(|t| t.1),
+        });
+    }
+
+    // Same rule as the journal: a day's record counts when it beats every earlier day.
+    let prs: Vec<(String, String, f64, i64, f64)> = sqlx::query_as(
+        r#"
+        SELECT e.name, pr.date, pr.weight, pr.reps, pr.estimated_1rm
+        FROM personal_records pr
+        JOIN exercises e ON e.id = pr.exercise_id
+        WHERE EXISTS (
+            SELECT 1
+            FROM training_sessions ts
+            JOIN program_blocks pb ON pb.id = ts.program_block_id
+            JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+            WHERE pb.program_id = ? AND ts.end_time IS NOT NULL
+            AND tse.exercise_id = pr.exercise_id
+            AND date(ts.start_time) = pr.date
+        )
+        AND pr.estimated_1rm > COALESCE(
+            (SELECT MAX(prev.estimated_1rm) FROM personal_records prev
+             WHERE prev.exercise_id = pr.exercise_id AND prev.date < pr.date), 0)
+        ORDER BY pr.date, e.name
+        "#,
+    )
+    .bind(prog_id)
+    .fetch_all(pool)
+    .await?;
+
+    Ok(ProgramSummary {
+        program,
+        sessions,
+        first,
+        last,
+        tonnage_kg,
+        planned,
+        done,
+        adherence_pct,
+        lifts,
+        prs: prs
+            .into_iter()
+            .map(|(exercise, date, weight, reps, e1rm)| ProgramPr { exercise, date, weight, reps, e1rm })
+            .collect(),
+    })
+}
+
+fn print_pretty(s: &ProgramSummary) {
+    println!("{} {}", tr("Program complete:").heading().bold(), s.program.bold());
+    if s.sessions == 0 {
+        println!("{}", tr("  (no finished sessions of this program yet)").dimmed());
+        return;
+    }
+
+    println!(
+        "{} {}",
+        tr("Sessions:").heading().bold(),
+        tf("{} ({} → {})", &[&s.sessions, &s.first.clone().unwrap_or_default(), &s.last.clone().unwrap_or_default()])
+    );
+    println!("{} {:.0} kg", tr("Tonnage:").heading().bold(), s.tonnage_kg);
+    if let Some(pct) = s.adherence_pct {
+        let line = tf("{}% ({}/{} planned sessions)", &[&format!("{:.0}", pct), &s.done, &s.planned]);
+        let line = if pct >= 90.0 { line.good().to_string() } else if pct < 70.0 { line.bad().to_string() } else { line };
+        println!("{} {}", tr("Adherence:").heading().bold(), line);
+    }
+
+    if !s.lifts.is_empty() {
+        println!("\n{}", tr("Main lifts (e1RM, first → last session):").heading().bold());
+    }
+    for l in &s.lifts {
+        let set = |t: Option<(f64, i64)>| t.map(|(w, r)| format!("{}kg × {}", kg(w), r)).unwrap_or_else(|| "—".to_string());
+        let e1rm = |v: Option<f64>| v.map(|v| format!("{}kg", kg(v))).unwrap_or_else(|| "—".to_string());
+        let change = match (l.before_e1rm, l.after_e1rm) {
+            (Some(a), Some(b)) if a > 0.0 => {
+                let pct = (b - a) / a * 100.0;
+                let s = format!("{:+.1}%", pct);
+                if pct > 0.0 {
+                    s.good().to_string()
+                } else if pct < 0.0 {
+                    s.bad().to_string()
+                } else {
+                    s.dimmed().to_string()
+                }
+            }
+            _ => String::new(),
+        };
+        println!(
+            "  • {} {} → {} {} {}",
+            l.exercise.bold(),
+            e1rm(l.before_e1rm),
+            e1rm(l.after_e1rm),
+            change,
+            format!("({} → {})", set(l.before_top), set(l.after_top)).dimmed()
+        );
+    }
+
+    println!("\n{} {}", tr("PRs during the program:").heading().bold(), s.prs.len());
+    for pr in &s.prs {
+        println!(
+            "  🏆 {} {}kg × {} {} {}",
+            pr.exercise.bold(),
+            kg(pr.weight),
+            pr.reps,
+            format!("(e1RM {}kg)", kg(pr.e1rm)).dimmed(),
+            pr.date.dimmed()
+        );
+    }
+}
+
+pub async fn handle(pool: &SqlitePool, program: String, archive: bool, fmt: OutputFmt) -> Result<()> {
+    let prog_id = resolve_program(pool, &program).await?;
+    let name: String = sqlx::query_scalar("SELECT name FROM programs WHERE id = ?")
+        .bind(&prog_id)
+        .fetch_one(pool)
+        .await?;
+
+    let summary = collect(pool, &prog_id, name).await?;
+    emit(fmt, &summary, || print_pretty(&summary));
+
+    if archive {
+        handle_archive(pool, program, false).await?;
+    }
+
+    Ok(())
+}
@@ -0,0 +1,170 @@
+use std::collections::{BTreeMap, BTreeSet};
+
+use anyhow::Result;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    errors::AppError,
+    i18n::{tf, tr},
+    types::{OutputFmt, emit},
+    ui::{self, Themed},
+};
+
+#[derive(Serialize)]
+struct ExerciseFreq {
+    name: String,
+    muscle: String,
+    sessions: usize,
+    per_week: f64,
+    programs: Vec<String>,
+}
+
+#[derive(Serialize)]
+struct MuscleFreq {
+    muscle: String,
+    sessions: usize,
+    per_week: f64,
+    programs: Vec<String>,
+    /// Weeks in which more than one program trained the muscle.
+    overlap_weeks: usize,
+}
+
+#[derive(Serialize)]
+struct Report {
+    weeks: u32,
+    exercises: Vec<ExerciseFreq>,
+    muscles: Vec<MuscleFreq>,
+}
+
+/// "12w", "12" (weeks) or "30d" → days.
+fn parse_last(s: &str) -> Option<u32> {
+    let s = s.trim().to_lowercase();
+    let (n, per) = match s.strip_suffix('d') {
+        Some(n) => (n, 1),
+        None => (s.strip_suffix('w').unwrap_or(&s), 7),
+    };
+    n.trim().parse::<u32>().ok().filter(|&n| n > 0).map(|n| n * per)
+}
+
+pub async fn handle(pool: &SqlitePool, last: String, fmt: OutputFmt) -> Result<()> {
+    let days = parse_last(&last)
+        .ok_or_else(|| AppError::Invalid(tf("invalid span `{}` (expected e.g. 12w or 30d)", &[&last])))?;
+    let weeks = days.div_ceil(7);
+
+    // One row per exercise trained in a finished session.
+    let rows: Vec<(String, String, String, String, String)> = sqlx::query_as(
+        r#"
+        SELECT DISTINCT ts.id, e.name, e.primary_muscle, p.name,
+               strftime('%Y-%W', ts.start_time, 'localtime')
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        JOIN programs p ON p.id = pb.program_id
+        JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+        JOIN exercises e ON e.id = tse.exercise_id
+        JOIN exercise_sets es ON es.session_exercise_id = tse.id
+        WHERE ts.end_time IS NOT NULL
+        AND es.status != 'skipped'
+        AND ts.start_time >= datetime('now', '-' || ? || ' days')
+        "#,
+    )
+    .bind(days)
+    .fetch_all(pool)
+    .await?;
+
+    if rows.is_empty() {
+        ui::info(tf("no finished sessions in the last {} weeks", &[&weeks]));
+        return Ok(());
+    }
+
+    let mut by_exercise = BTreeMap::<&str, (&str, usize, BTreeSet<&str>)>::new();
+    for (_, name, muscle, program, _) in &rows {
+        let e = by_exercise.entry(name).or_insert((muscle, 0, BTreeSet::new()));
+        e.1 += 1;
+        e.2.insert(program);
+    }
+
+    // A muscle counts once per session, however many of its exercises were done.
+    let mut by_muscle = BTreeMap::<&str, (BTreeSet<&str>, BTreeSet<&str>, BTreeMap<&str, BTreeSet<&str>>)>::new();
+    for (session, _, muscle, program, week) in &rows {
+        let m = by_muscle.entry(muscle).or_default();
+        m.0.insert(session);
+        m.1.insert(program);
+        m.2.entry(week).or_default().insert(program);
+    }
+
+    let mut exercises: Vec<ExerciseFreq> = by_exercise
+        .into_iter()
+        .map(|(name, (muscle, sessions, programs))| ExerciseFreq {
+            name: name.to_string(),
+            muscle: muscle.to_string(),
+            sessions,
+            per_week: sessions as f64 / weeks as f64,
+            programs: programs.into_iter().map(String::from).collect(),
+        })
+        .collect();
+    exercises.sort_by(|a, b| b.sessions.cmp(&a.sessions).then_with(|| a.name.cmp(&b.name)));
+
+    let mut muscles: Vec<MuscleFreq> = by_muscle
+        .into_iter()
+        .map(|(muscle, (sessions, programs, per_week))| MuscleFreq {
+            muscle: muscle.to_string(),
+            sessions: sessions.len(),
+            per_week: sessions.len() as f64 / weeks as f64,
+            programs: programs.into_iter().map(String::from).collect(),
+            overlap_weeks: per_week.values().filter(|p| p.len() > 1).count(),
+        })
+        .collect();
+    muscles.sort_by(|a, b| b.sessions.cmp(&a.sessions).then_with(|| a.muscle.cmp(&b.muscle)));
+
+    let report = Report { weeks, exercises, muscles };
+    emit(fmt, &report, || {
+        let name_w = report.exercises.iter().map(|e| e.name.chars().count()).max().unwrap_or(0).max(10);
+
+        println!(
+            "{} {}",
+            tr("Exercise frequency:").heading().bold(),
+            tf("(last {} weeks)", &[&report.weeks]).dimmed()
+        );
+        for e in &report.exercises {
+            let shared = if e.programs.len() > 1 { " ⚠".accent().to_string() } else { String::new() };
+            println!(
+                "  {:<name_w$} {:<11} {:>5}/wk {:>5} {}{}",
+                e.name.bold(),
+                e.muscle.dimmed(),
+                format!("{:.1}", e.per_week),
+                format!("({})", e.sessions),
+                e.programs.join(", ").dimmed(),
+                shared,
+                name_w = name_w
+            );
+        }
+
+        println!();
+        println!("{}", tr("Muscle frequency:").heading().bold());
+        for m in &report.muscles {
+            let overlap = if m.overlap_weeks > 0 {
+                format!(" {}", tf("several programs in {} of {} weeks", &[&m.overlap_weeks, &report.weeks]))
+                    .accent()
+                    .to_string()
+            } else {
+                String::new()
+            };
+            println!(
+                "  {:<11} {:>5}/wk {:>5}{}",
+                m.muscle.bold(),
+                format!("{:.1}", m.per_week),
+                format!("({})", m.sessions),
+                overlap
+            );
+        }
+
+        if report.exercises.iter().any(|e| e.programs.len() > 1) {
+            println!();
+            println!("{}", tr("⚠ trained by more than one program").dimmed());
+        }
+    });
+
+    Ok(())
+}
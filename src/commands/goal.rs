@@ -0,0 +1,250 @@
+use anyhow::Result;
+use chrono::NaiveDate;
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    cli::GoalCmd,
+    dates::parse_date,
+    errors::AppError,
+    formula,
+    i18n::{kg, kg_places, number, short_date, tf, tr},
+    ui::{self, Themed},
+};
+
+/// Same estimate the rest of the app uses for sets.
+fn e1rm(weight: f64, reps: i64) -> f64 {
+    formula::e1rm(weight, reps as f64)
+}
+
+/// "Squat 180x1 by 2025-12-01" → ("Squat", 180.0, 1, date). Reps default to 1,
+/// and "180kg", "180 x 3" and "180×3" are accepted too.
+fn parse_goal(spec: &str) -> Option<(String, f64, i64, NaiveDate)> {
+    let (lift, date) = spec.trim().rsplit_once(" by ")?;
+    let date = parse_date(Some(date.trim()))?;
+
+    let lift = lift.replace('×', "x").replace(" x ", "x");
+    let (name, load) = lift.trim().rsplit_once(' ')?;
+    let (weight, reps) = match load.split_once('x') {
+        Some((w, r)) => (w, r.trim().parse().ok()?),
+        None => (load, 1),
+    };
+    let weight: f64 = weight.trim().trim_end_matches("kg").parse().ok()?;
+
+    (weight > 0.0 && reps > 0).then(|| (name.trim().to_string(), weight, reps, date))
+}
+
+struct Goal {
+    id: String,
+    exercise: String,
+    exercise_id: String,
+    weight: f64,
+    reps: i64,
+    target_date: String,
+    created_at: String,
+}
+
+/// Open goals (or every goal), nearest deadline first.
+async fn load_goals(pool: &SqlitePool, exercise_id: Option<&str>) -> Result<Vec<Goal>> {
+    let rows: Vec<(String, String, String, f64, i64, String, String)> = sqlx::query_as(
+        r#"
+        SELECT g.id, e.name, e.id, g.weight, g.reps, g.target_date, g.created_at
+        FROM goals g
+        JOIN exercises e ON e.id = g.exercise_id
+        WHERE g.done_at IS NULL
+        AND (?1 IS NULL OR g.exercise_id = ?1)
+        ORDER BY g.target_date, g.created_at
+        "#,
+    )
+    .bind(exercise_id)
+    .fetch_all(pool)
+    .await?;
+
+    Ok(rows
+        .into_iter()
+        .map(|(id, exercise, exercise_id, weight, reps, target_date, created_at)| Goal {
+            id,
+            exercise,
+            exercise_id,
+            weight,
+            reps,
+            target_date,
+            created_at,
+        })
+        .collect())
+}
+
+/// Best e1RM of an exercise, optionally only from sets before `before`.
+async fn best_e1rm(pool: &SqlitePool, exercise_id: &str, before: Option<&str>) -> Result<Option<f64>> {
+    Ok(sqlx::query_scalar(
+        &format!(r#"
+        SELECT MAX({e1rm})
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        WHERE tse.exercise_id = ?1
+        AND es.weight > 0 AND es.bodyweight = 0
+        AND (?2 IS NULL OR es.timestamp < ?2)
+        "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
+    )
+    .bind(exercise_id)
+    .bind(before)
+    .fetch_one(pool)
+    .await?)
+}
+
+/// "Squat 180kg × 1 by 2025-12-01: 172.5/186 kg e1RM (93%), needs +1.2 kg/week — on track"
+async fn progress_line(pool: &SqlitePool, g: &Goal) -> Result<String> {
+    let today = chrono::Local::now().date_naive();
+    let target = e1rm(g.weight, g.reps);
+    let current = best_e1rm(pool, &g.exercise_id, None).await?.unwrap_or(0.0);
+    let deadline = NaiveDate::parse_from_str(&g.target_date, "%Y-%m-%d").unwrap_or(today);
+    let weeks_left = (deadline - today).num_days() as f64 / 7.0;
+
+    // Recent pace: gain over the last 8 weeks, or since the goal was set if that's longer.
+    let since = (today - chrono::Duration::weeks(8)).format("%Y-%m-%d").to_string();
+    let since = since.min(g.created_at[..10].to_string());
+    let weeks_since = NaiveDate::parse_from_str(&since, "%Y-%m-%d")
+        .map(|d| ((today - d).num_days() as f64 / 7.0).max(1.0))
+        .unwrap_or(8.0);
+    let pace = best_e1rm(pool, &g.exercise_id, Some(&since))
+        .await?
+        .map(|before| (current - before) / weeks_since);
+
+    let head = format!(
+        "{} {}kg × {} {}",
+        g.exercise.bold(),
+        kg(g.weight),
+        g.reps,
+        tf("by {}", &[&short_date(deadline)]).dimmed()
+    );
+    let pct = if target > 0.0 { current / target * 100.0 } else { 0.0 };
+    let status = format!("{}/{} kg e1RM ({:.0}%)", kg_places(current, 1), kg_places(target, 1), pct);
+
+    let verdict = if current >= target {
+        tr("reached").good().bold().to_string()
+    } else if weeks_left <= 0.0 {
+        tr("overdue").bad().bold().to_string()
+    } else {
+        let needed = (target - current) / weeks_left;
+        let rate = tf("needs +{} kg/week", &[&number(needed as f64, 1)]);
+        let flag = match pace {
+            Some(p) if p >= needed => tr("on track").good().to_string(),
+            _ => tr("behind").bad().to_string(),
+        };
+        format!("{}, {}", rate, flag)
+    };
+
+    Ok(format!("{}: {} — {}", head, status, verdict))
+}
+
+/// Open goals with their progress; nothing when there are none.
+pub async fn print_goals(pool: &SqlitePool, exercise_id: Option<&str>) -> Result<()> {
+    let goals = load_goals(pool, exercise_id).await?;
+    if goals.is_empty() {
+        return Ok(());
+    }
+
+    println!("{}", tr("Goals:").heading().bold());
+    for g in &goals {
+        println!("  • {}", progress_line(pool, g).await?);
+    }
+    println!();
+
+    Ok(())
+}
+
+pub async fn handle(cmd: GoalCmd, pool: &SqlitePool) -> Result<()> {
+    match cmd {
+        GoalCmd::Add { spec } => {
+            let spec = spec.join(" ");
+            let Some((name, weight, reps, date)) = parse_goal(&spec) else {
+                return Err(AppError::Invalid(tf("can't read goal `{}` (expected e.g. \"Squat 180x1 by 2025-12-01\")", &[&spec]).into()).into());
+            };
+
+            let exercise_id: Option<String> =
+                sqlx::query_scalar("SELECT id FROM exercises WHERE name = ? COLLATE NOCASE")
+                    .bind(&name)
+                    .fetch_optional(pool)
+                    .await?;
+            let Some(exercise_id) = exercise_id else {
+                return Err(AppError::ExerciseNotFound(name).into());
+            };
+
+            sqlx::query(
+                "INSERT INTO goals (id, exercise_id, weight, reps, target_date, created_at) VALUES (?, ?, ?, ?, ?, datetime('now'))",
+            )
+            .bind(uuid::Uuid::new_v4().to_string())
+            .bind(&exercise_id)
+            .bind(weight)
+            .bind(reps)
+            .bind(date.format("%Y-%m-%d").to_string())
+            .execute(pool)
+            .await?;
+
+            ui::ok(tf("goal set: {} {}kg × {} by {}", &[&name, &kg(weight), &reps, &short_date(date)]));
+        }
+
+        GoalCmd::List { all } => {
+            println!("{}", tr("Goals:").heading().bold());
+            let goals = load_goals(pool, None).await?;
+            if goals.is_empty() {
+                println!("{}", tr("  (no open goals)").dimmed());
+            }
+            for (i, g) in goals.iter().enumerate() {
+                println!(" {} • {}", (i + 1).to_string().accent(), progress_line(pool, g).await?);
+            }
+
+            if all {
+                let done: Vec<(String, f64, i64, String)> = sqlx::query_as(
+                    r#"
+                    SELECT e.name, g.weight, g.reps, g.done_at
+                    FROM goals g
+                    JOIN exercises e ON e.id = g.exercise_id
+                    WHERE g.done_at IS NOT NULL
+                    ORDER BY g.done_at DESC
+                    "#,
+                )
+                .fetch_all(pool)
+                .await?;
+                for (name, weight, reps, done_at) in done {
+                    println!(
+                        "   {} {} {}kg × {} {}",
+                        "✓".good(),
+                        name,
+                        kg(weight),
+                        reps,
+                        tf("(done {})", &[&&done_at[..10]]).dimmed()
+                    );
+                }
+            }
+        }
+
+        GoalCmd::Done { goal } => {
+            let goals = load_goals(pool, None).await?;
+            let picked = match goal.parse::<usize>() {
+                Ok(n) => n.checked_sub(1).and_then(|i| goals.get(i)),
+                Err(_) => {
+                    let mut matching = goals.iter().filter(|g| g.exercise.eq_ignore_ascii_case(&goal));
+                    match (matching.next(), matching.next()) {
+                        (Some(g), None) => Some(g),
+                        (Some(_), Some(_)) => {
+                            return Err(AppError::Invalid(tf("several goals for `{}`, use the index from `goal list`", &[&goal]).into()).into());
+                        }
+                        _ => None,
+                    }
+                }
+            };
+            let Some(g) = picked else {
+                return Err(AppError::NotFound(tf("no open goal `{}`", &[&goal]).into()).into());
+            };
+
+            sqlx::query("UPDATE goals SET done_at = datetime('now') WHERE id = ?")
+                .bind(&g.id)
+                .execute(pool)
+                .await?;
+            ui::ok(tf("goal done: {} {}kg × {}", &[&g.exercise, &kg(g.weight), &g.reps]));
+        }
+    }
+
+    Ok(())
+}
@@ -0,0 +1,287 @@
+use anyhow::Result;
+use colored::Colorize;
+use sqlx::{Row, SqlitePool};
+
+use crate::{cli::GymCmd, errors::AppError, i18n::{kg, tf, tr}, types::guess_equipment, ui::{self, Themed}};
+
+/// What a gym has available.
+pub struct Gym {
+    pub name: String,
+    barbell: bool,
+    dumbbells: bool,
+    dumbbell_min: Option<f32>,
+    dumbbell_max: Option<f32>,
+    machines: bool,
+    cables: bool,
+    kettlebells: bool,
+}
+
+impl Gym {
+    /// Unknown equipment is assumed to be available.
+    pub fn allows(&self, equipment: Option<&str>) -> bool {
+        match equipment {
+            Some("barbell") => self.barbell,
+            Some("dumbbell") => self.dumbbells,
+            Some("machine") => self.machines,
+            Some("cable") => self.cables,
+            Some("kettlebell") => self.kettlebells,
+            _ => true,
+        }
+    }
+
+    fn summary(&self) -> String {
+        let mut has = Vec::new();
+        if self.barbell {
+            has.push("barbell".to_string());
+        }
+        if self.dumbbells {
+            match (self.dumbbell_min, self.dumbbell_max) {
+                (Some(lo), Some(hi)) => has.push(format!("dumbbells {}-{}kg", kg(lo), kg(hi))),
+                (None, Some(hi)) => has.push(format!("dumbbells up to {}kg", kg(hi))),
+                _ => has.push("dumbbells".to_string()),
+            }
+        }
+        if self.machines {
+            has.push("machines".to_string());
+        }
+        if self.cables {
+            has.push("cables".to_string());
+        }
+        if self.kettlebells {
+            has.push("kettlebells".to_string());
+        }
+        if has.is_empty() {
+            "bodyweight only".to_string()
+        } else {
+            has.join(", ")
+        }
+    }
+}
+
+/// Parse "2-30kg", "2-30" or "30kg" into a (min, max) range in kg.
+fn parse_range(s: &str) -> Option<(Option<f32>, f32)> {
+    let s = s.trim().trim_end_matches("kg").trim();
+    match s.split_once('-') {
+        Some((lo, hi)) => {
+            let lo: f32 = lo.trim().parse().ok()?;
+            let hi: f32 = hi.trim().trim_end_matches("kg").parse().ok()?;
+            (lo <= hi).then_some((Some(lo), hi))
+        }
+        None => Some((None, s.parse().ok()?)),
+    }
+}
+
+pub async fn load_gym(pool: &SqlitePool, name: &str) -> Result<Option<Gym>> {
+    let row = sqlx::query("SELECT * FROM gyms WHERE name = ?")
+        .bind(name)
+        .fetch_optional(pool)
+        .await?;
+
+    Ok(row.map(|r| Gym {
+        name: r.get("name"),
+        barbell: r.get::<i32, _>("barbell") != 0,
+        dumbbells: r.get::<i32, _>("dumbbells") != 0,
+        dumbbell_min: r.get("dumbbell_min"),
+        dumbbell_max: r.get("dumbbell_max"),
+        machines: r.get::<i32, _>("machines") != 0,
+        cables: r.get::<i32, _>("cables") != 0,
+        kettlebells: r.get::<i32, _>("kettlebells") != 0,
+    }))
+}
+
+/// Equipment of an exercise: the stored value, or a guess from its name.
+pub fn equipment_of(name: &str, stored: Option<&str>) -> Option<String> {
+    stored
+        .map(str::to_string)
+        .or_else(|| guess_equipment(name).map(str::to_string))
+}
+
+/// Hint for an exercise the gym can't host: first a program option the gym
+/// can do, then the most trained exercise for the same muscle.
+pub async fn swap_hint(
+    pool: &SqlitePool,
+    gym: &Gym,
+    session_idx: usize,
+    exercise_id: &str,
+    exercise_name: &str,
+    equipment: Option<&str>,
+    options: Option<&str>,
+) -> Result<Option<String>> {
+    if !gym.allows(equipment) {
+        let mut pick = None;
+
+        for opt in options.unwrap_or_default().split(',').map(str::trim).filter(|o| !o.is_empty()) {
+            let eq: Option<Option<String>> =
+                sqlx::query_scalar("SELECT equipment FROM exercises WHERE name = ?")
+                    .bind(opt)
+                    .fetch_optional(pool)
+                    .await?;
+            let Some(eq) = eq else { continue };
+            if gym.allows(equipment_of(opt, eq.as_deref()).as_deref()) {
+                pick = Some(opt.to_string());
+                break;
+            }
+        }
+
+        if pick.is_none() {
+            let candidates: Vec<(String, Option<String>)> = sqlx::query_as(
+                r#"
+                SELECT e.name, e.equipment
+                FROM exercises e
+                LEFT JOIN training_session_exercises tse ON tse.exercise_id = e.id
+                WHERE e.primary_muscle = (SELECT primary_muscle FROM exercises WHERE id = ?)
+                AND e.id <> ?
+                GROUP BY e.id
+                ORDER BY COUNT(tse.id) DESC, e.name
+                "#,
+            )
+            .bind(exercise_id)
+            .bind(exercise_id)
+            .fetch_all(pool)
+            .await?;
+
+            pick = candidates
+                .into_iter()
+                .find(|(n, eq)| gym.allows(equipment_of(n, eq.as_deref()).as_deref()))
+                .map(|(n, _)| n);
+        }
+
+        let need = equipment.unwrap_or("equipment");
+        return Ok(Some(match pick {
+            Some(alt) => format!(
+                "{} no {} — try `session swap {} \"{}\"`",
+                tr("swap:").highlight().bold(),
+                need,
+                session_idx,
+                alt
+            ),
+            None => format!(
+                "{} no {} and no alternative found for `{}`",
+                tr("warning:").accent().bold(),
+                need,
+                exercise_name
+            ),
+        }));
+    }
+
+    // Dumbbells exist, but maybe not heavy (or light) enough.
+    if equipment == Some("dumbbell") {
+        let last: Option<f32> = sqlx::query_scalar(
+            r#"
+            SELECT es.weight
+            FROM exercise_sets es
+            JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+            WHERE tse.exercise_id = ? AND es.weight > 0
+            ORDER BY es.timestamp DESC
+            LIMIT 1
+            "#,
+        )
+        .bind(exercise_id)
+        .fetch_optional(pool)
+        .await?;
+
+        if let Some(w) = last {
+            if gym.dumbbell_max.is_some_and(|hi| w > hi) || gym.dumbbell_min.is_some_and(|lo| w < lo) {
+                return Ok(Some(format!(
+                    "{} last used {}kg, gym has {}",
+                    tr("note:").accent().bold(),
+                    kg(w),
+                    gym.summary()
+                )));
+            }
+        }
+    }
+
+    Ok(None)
+}
+
+pub async fn handle(cmd: GymCmd, pool: &SqlitePool) -> Result<()> {
+    match cmd {
+        GymCmd::Add {
+            name,
+            no_barbell,
+            no_dumbbells,
+            dumbbells,
+            no_machines,
+            no_cables,
+            no_kettlebells,
+        } => {
+            let (dumbbell_min, dumbbell_max) = match dumbbells.as_deref() {
+                Some(range) => match parse_range(range) {
+                    Some((lo, hi)) => (lo, Some(hi)),
+                    None => {
+                        return Err(AppError::Invalid(tf("invalid dumbbell range `{}` (expected e.g. 2-30kg)", &[&range]).into()).into());
+                    }
+                },
+                None => (None, None),
+            };
+
+            sqlx::query(
+                r#"
+                INSERT INTO gyms
+                  (id, name, barbell, dumbbells, dumbbell_min, dumbbell_max,
+                   machines, cables, kettlebells, created_at)
+                VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+                ON CONFLICT (name) DO UPDATE SET
+                  barbell = excluded.barbell,
+                  dumbbells = excluded.dumbbells,
+                  dumbbell_min = excluded.dumbbell_min,
+                  dumbbell_max = excluded.dumbbell_max,
+                  machines = excluded.machines,
+                  cables = excluded.cables,
+                  kettlebells = excluded.kettlebells
+                "#,
+            )
+            .bind(uuid::Uuid::new_v4().to_string())
+            .bind(&name)
+            .bind(!no_barbell as i32)
+            .bind(!no_dumbbells as i32)
+            .bind(dumbbell_min)
+            .bind(dumbbell_max)
+            .bind(!no_machines as i32)
+            .bind(!no_cables as i32)
+            .bind(!no_kettlebells as i32)
+            .execute(pool)
+            .await?;
+
+            if let Some(gym) = load_gym(pool, &name).await? {
+                ui::ok(format!("`{}`: {}", gym.name, gym.summary()));
+            }
+        }
+
+        GymCmd::List => {
+            let names: Vec<String> = sqlx::query_scalar("SELECT name FROM gyms ORDER BY name")
+                .fetch_all(pool)
+                .await?;
+
+            println!("{}", tr("Gyms:").heading().bold());
+            if names.is_empty() {
+                println!("{}", "  (no gyms found)".dimmed());
+            }
+            for (i, name) in names.iter().enumerate() {
+                if let Some(gym) = load_gym(pool, name).await? {
+                    println!(
+                        " {} • {} {}",
+                        (i + 1).to_string().accent(),
+                        gym.name.bold(),
+                        format!("– {}", gym.summary()).dimmed()
+                    );
+                }
+            }
+        }
+
+        GymCmd::Delete { name } => {
+            let res = sqlx::query("DELETE FROM gyms WHERE name = ?")
+                .bind(&name)
+                .execute(pool)
+                .await?;
+
+            if res.rows_affected() == 0 {
+                return Err(AppError::NotFound(tf("no gym named `{}`", &[&name])).into());
+            }
+            ui::ok(tf("deleted gym `{}`", &[&name]));
+        }
+    }
+
+    Ok(())
+}
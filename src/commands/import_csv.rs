@@ -0,0 +1,454 @@
+use std::{
+    collections::{BTreeMap, HashMap},
+    io::{IsTerminal, Write},
+};
+
+use anyhow::Result;
+use chrono::{Duration, NaiveDate};
+use colored::Colorize;
+use sqlx::SqlitePool;
+use uuid::Uuid;
+
+use crate::{
+    commands::recovery::split_delimited,
+    errors::AppError,
+    formula,
+    i18n::{short_date, tf, tr},
+    types::cannonical_muscle,
+    ui::{self, Themed},
+};
+
+/// Fields a spreadsheet column can map to, and whether they're required.
+const FIELDS: [(&str, bool); 8] = [
+    ("date", true),
+    ("exercise", true),
+    ("weight", true),
+    ("reps", true),
+    ("sets", false),
+    ("rpe", false),
+    ("notes", false),
+    ("muscle", false),
+];
+
+const PROGRAM: &str = "Spreadsheet import";
+const BLOCK: &str = "Imported sessions";
+
+/// Seconds between imported sets, so they keep their order.
+const SET_SPACING_SECS: i64 = 120;
+
+/// "A" → 0, "AB" → 27.
+fn column_letter(label: &str) -> Option<usize> {
+    if label.is_empty() || !label.chars().all(|c| c.is_ascii_alphabetic()) {
+        return None;
+    }
+    let n = label
+        .to_ascii_uppercase()
+        .bytes()
+        .fold(0usize, |acc, b| acc * 26 + (b - b'A' + 1) as usize);
+    Some(n - 1)
+}
+
+/// A column by spreadsheet letter, 1-based number or header name.
+fn column(spec: &str, header: &[String]) -> Option<usize> {
+    let spec = spec.trim();
+    header
+        .iter()
+        .position(|h| h.eq_ignore_ascii_case(spec))
+        .or_else(|| spec.parse::<usize>().ok().filter(|&n| n > 0).map(|n| n - 1))
+        .or_else(|| column_letter(spec))
+        .filter(|&i| i < header.len())
+}
+
+/// "A", "B", ... for column `i`.
+fn letter(i: usize) -> String {
+    let mut n = i + 1;
+    let mut out = Vec::new();
+    while n > 0 {
+        out.push((b'A' + ((n - 1) % 26) as u8) as char);
+        n = (n - 1) / 26;
+    }
+    out.iter().rev().collect()
+}
+
+/// `date=A,exercise=B,weight=D,reps=E` → field → column index.
+fn parse_map(spec: &str, header: &[String]) -> Result<HashMap<&'static str, usize>> {
+    let mut map = HashMap::new();
+    for pair in spec.split(',').filter(|p| !p.trim().is_empty()) {
+        let Some((field, col)) = pair.split_once('=') else {
+            return Err(AppError::Invalid(tf("expected field=column in `{}`", &[&pair])).into());
+        };
+        let Some(&(field, _)) = FIELDS.iter().find(|(f, _)| f.eq_ignore_ascii_case(field.trim())) else {
+            let known = FIELDS.iter().map(|(f, _)| *f).collect::<Vec<_>>().join(", ");
+            return Err(AppError::Invalid(tf("unknown field `{}` (one of: {})", &[&field.trim(), &known])).into());
+        };
+        let Some(i) = column(col, header) else {
+            return Err(AppError::Invalid(tf("no column `{}` in the file", &[&col.trim()])).into());
+        };
+        map.insert(field, i);
+    }
+
+    let missing: Vec<&str> = FIELDS
+        .iter()
+        .filter(|(f, required)| *required && !map.contains_key(f))
+        .map(|(f, _)| *f)
+        .collect();
+    if !missing.is_empty() {
+        return Err(AppError::Invalid(tf("`--map` is missing {}", &[&missing.join(", ")])).into());
+    }
+    Ok(map)
+}
+
+/// Ask for each field's column, showing the header with a sample row.
+fn wizard(header: &[String], sample: &[String]) -> Result<HashMap<&'static str, usize>> {
+    if !std::io::stdin().is_terminal() {
+        return Err(AppError::Invalid(
+            tr("pass `--map`, e.g. `--map \"date=A,exercise=B,weight=D,reps=E\"`").into(),
+        )
+        .into());
+    }
+
+    println!("{}", tr("Columns:").heading().bold());
+    for (i, name) in header.iter().enumerate() {
+        let example = sample.get(i).map(String::as_str).unwrap_or_default();
+        println!("  {:>3}  {:<24} {}", letter(i).accent(), name, example.dimmed());
+    }
+    println!("{}", tr("Answer with a column letter, number or name; Enter skips optional fields.").dimmed());
+
+    let mut map = HashMap::new();
+    for (field, required) in FIELDS {
+        loop {
+            let optional = if required { String::new() } else { format!(" {}", tr("(optional)")) };
+            print!("{}{}: ", field.bold(), optional.dimmed());
+            std::io::stdout().flush()?;
+            let mut answer = String::new();
+            if std::io::stdin().read_line(&mut answer)? == 0 {
+                return Err(AppError::Invalid(tr("mapping cancelled").into()).into());
+            }
+            let answer = answer.trim();
+            if answer.is_empty() && !required {
+                break;
+            }
+            match column(answer, header) {
+                Some(i) => {
+                    map.insert(field, i);
+                    break;
+                }
+                None => println!("  {}", tf("no column `{}`", &[&answer]).bad()),
+            }
+        }
+    }
+
+    let mut fields: Vec<_> = map.iter().collect();
+    fields.sort_by_key(|(f, _)| FIELDS.iter().position(|(n, _)| n == *f));
+    let spec = fields
+        .iter()
+        .map(|(f, i)| format!("{}={}", f, letter(**i)))
+        .collect::<Vec<_>>()
+        .join(",");
+    ui::info(tf("next time pass `--map \"{}\"`", &[&spec]));
+    Ok(map)
+}
+
+/// ISO dates, then day-first ones (31/01/2024, 31-01-2024, 31.01.2024), unless
+/// a chrono format is given.
+fn parse_date(s: &str, format: Option<&str>) -> Option<NaiveDate> {
+    let s = s.trim();
+    if let Some(format) = format {
+        return NaiveDate::parse_from_str(s, format).ok();
+    }
+    let day = s.split([' ', 'T']).next()?;
+    ["%Y-%m-%d", "%d/%m/%Y", "%d-%m-%Y", "%d.%m.%Y", "%Y/%m/%d"]
+        .iter()
+        .find_map(|f| NaiveDate::parse_from_str(day, f).ok())
+}
+
+/// "102,5", "102.5 kg" → 102.5; "bw" or empty → bodyweight.
+fn parse_weight(s: &str) -> Option<(f64, bool)> {
+    let s = s.trim().to_lowercase();
+    if s.is_empty() || s == "bw" {
+        return Some((0.0, true));
+    }
+    let number = s.trim_end_matches(|c: char| c.is_alphabetic() || c.is_whitespace()).replace(',', ".");
+    number.parse().ok().map(|w: f64| (w, w == 0.0))
+}
+
+struct Row {
+    date: NaiveDate,
+    exercise: String,
+    weight: f64,
+    bodyweight: bool,
+    reps: i64,
+    sets: i64,
+    rpe: Option<f64>,
+    notes: Option<String>,
+    muscle: Option<String>,
+}
+
+fn parse_row(cells: &[String], map: &HashMap<&'static str, usize>, date_format: Option<&str>) -> Option<Row> {
+    let cell = |field: &str| map.get(field).and_then(|&i| cells.get(i)).map(|c| c.trim()).filter(|c| !c.is_empty());
+    let (weight, bodyweight) = parse_weight(cell("weight").unwrap_or_default())?;
+
+    Some(Row {
+        date: parse_date(cell("date")?, date_format)?,
+        exercise: cell("exercise")?.to_string(),
+        weight,
+        bodyweight,
+        reps: cell("reps")?.parse().ok().filter(|&r| r > 0)?,
+        sets: match cell("sets") {
+            Some(s) => s.parse().ok().filter(|&n| n > 0)?,
+            None => 1,
+        },
+        rpe: cell("rpe").and_then(|r| r.replace(',', ".").parse().ok()),
+        notes: cell("notes").map(str::to_string),
+        muscle: cell("muscle").map(str::to_string),
+    })
+}
+
+/// Id of the program/block imported sessions hang off, created on first use.
+async fn import_block(tx: &mut sqlx::SqliteConnection) -> Result<String> {
+    let existing: Option<String> = sqlx::query_scalar(
+        r#"
+        SELECT pb.id FROM program_blocks pb
+        JOIN programs p ON p.id = pb.program_id
+        WHERE p.name = ? AND pb.name = ?
+        "#,
+    )
+    .bind(PROGRAM)
+    .bind(BLOCK)
+    .fetch_optional(&mut *tx)
+    .await?;
+    if let Some(id) = existing {
+        return Ok(id);
+    }
+
+    let program_id = Uuid::new_v4().to_string();
+    sqlx::query("INSERT INTO programs (id, name, description, created_at) VALUES (?, ?, 'sessions from `import-csv`', datetime('now'))")
+        .bind(&program_id)
+        .bind(PROGRAM)
+        .execute(&mut *tx)
+        .await?;
+    let block_id = Uuid::new_v4().to_string();
+    sqlx::query("INSERT INTO program_blocks (id, program_id, name) VALUES (?, ?, ?)")
+        .bind(&block_id)
+        .bind(&program_id)
+        .bind(BLOCK)
+        .execute(&mut *tx)
+        .await?;
+    Ok(block_id)
+}
+
+pub async fn handle(pool: &SqlitePool, file: String, map: Option<String>, date_format: Option<String>) -> Result<()> {
+    let Ok(text) = std::fs::read_to_string(&file) else {
+        return Err(AppError::NotFound(tf("cannot open `{}`", &[&file])).into());
+    };
+    let mut lines = text.lines().filter(|l| !l.trim().is_empty());
+    let Some(first) = lines.next() else {
+        return Err(AppError::NotFound(tf("`{}` is empty", &[&file])).into());
+    };
+    // Spreadsheets saved with decimal commas separate cells with `;`.
+    let delim = if first.matches(';').count() > first.matches(',').count() { ';' } else { ',' };
+    let header = split_delimited(first, delim);
+    let body: Vec<Vec<String>> = lines.map(|l| split_delimited(l, delim)).collect();
+
+    let map = match map {
+        Some(spec) => parse_map(&spec, &header)?,
+        None => wizard(&header, body.first().map(Vec::as_slice).unwrap_or_default())?,
+    };
+
+    // Without a header the first line is data too.
+    let mut rows: Vec<Row> = parse_row(&header, &map, date_format.as_deref()).into_iter().collect();
+    let mut skipped = Vec::new();
+    for (i, cells) in body.iter().enumerate() {
+        match parse_row(cells, &map, date_format.as_deref()) {
+            Some(row) => rows.push(row),
+            None => skipped.push(i + 2),
+        }
+    }
+    if rows.is_empty() {
+        return Err(AppError::NotFound(tf("no rows of `{}` could be read with this mapping", &[&file])).into());
+    }
+
+    let mut tx = pool.begin().await?;
+
+    // Resolve every exercise before writing anything.
+    let mut exercise_ids: HashMap<String, String> = HashMap::new();
+    let mut missing = Vec::new();
+    let mut created = 0;
+    for row in &rows {
+        let key = row.exercise.to_lowercase();
+        if exercise_ids.contains_key(&key) || missing.contains(&row.exercise) {
+            continue;
+        }
+        let id: Option<String> = sqlx::query_scalar("SELECT id FROM exercises WHERE name = ? COLLATE NOCASE")
+            .bind(&row.exercise)
+            .fetch_optional(&mut *tx)
+            .await?;
+        match (id, row.muscle.as_deref().and_then(cannonical_muscle)) {
+            (Some(id), _) => {
+                exercise_ids.insert(key, id);
+            }
+            (None, Some(muscle)) => {
+                let id = Uuid::new_v4().to_string();
+                sqlx::query("INSERT INTO exercises (id, name, primary_muscle, created_at) VALUES (?, ?, ?, datetime('now'))")
+                    .bind(&id)
+                    .bind(&row.exercise)
+                    .bind(muscle)
+                    .execute(&mut *tx)
+                    .await?;
+                exercise_ids.insert(key, id);
+                created += 1;
+            }
+            (None, None) => missing.push(row.exercise.clone()),
+        }
+    }
+    if !missing.is_empty() {
+        return Err(AppError::ExerciseNotFound(missing.join("`, `")).into());
+    }
+
+    let block_id = import_block(&mut *tx).await?;
+
+    let mut days: BTreeMap<NaiveDate, Vec<&Row>> = BTreeMap::new();
+    for row in &rows {
+        days.entry(row.date).or_default().push(row);
+    }
+
+    let mut sessions = 0;
+    let mut sets = 0;
+    let mut already = 0;
+    for (date, day_rows) in &days {
+        // Importing the same file twice adds nothing.
+        let start = date.and_hms_opt(12, 0, 0).unwrap();
+        let exists: bool = sqlx::query_scalar(
+            "SELECT EXISTS(SELECT 1 FROM training_sessions WHERE program_block_id = ? AND date(start_time) = ?)",
+        )
+        .bind(&block_id)
+        .bind(date.format("%Y-%m-%d").to_string())
+        .fetch_one(&mut *tx)
+        .await?;
+        if exists {
+            already += 1;
+            continue;
+        }
+
+        let session_id = Uuid::new_v4().to_string();
+        sqlx::query("INSERT INTO training_sessions (id, program_block_id, start_time, end_time) VALUES (?, ?, ?, ?)")
+            .bind(&session_id)
+            .bind(&block_id)
+            .bind(start.format("%Y-%m-%d %H:%M:%S").to_string())
+            .bind(start.format("%Y-%m-%d %H:%M:%S").to_string())
+            .execute(&mut *tx)
+            .await?;
+
+        let mut session_exercises: HashMap<&str, String> = HashMap::new();
+        let mut at = start;
+        for row in day_rows {
+            let exercise_id = &exercise_ids[&row.exercise.to_lowercase()];
+            let tse_id = match session_exercises.get(exercise_id.as_str()) {
+                Some(id) => id.clone(),
+                None => {
+                    let id = Uuid::new_v4().to_string();
+                    sqlx::query("INSERT INTO training_session_exercises (id, training_session_id, exercise_id) VALUES (?, ?, ?)")
+                        .bind(&id)
+                        .bind(&session_id)
+                        .bind(exercise_id)
+                        .execute(&mut *tx)
+                        .await?;
+                    session_exercises.insert(exercise_id.as_str(), id.clone());
+                    id
+                }
+            };
+
+            for _ in 0..row.sets {
+                at += Duration::seconds(SET_SPACING_SECS);
+                sqlx::query(
+                    r#"
+                    INSERT INTO exercise_sets (id, session_exercise_id, weight, reps, rpe, notes, timestamp, bodyweight)
+                    VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+                    "#,
+                )
+                .bind(Uuid::new_v4().to_string())
+                .bind(&tse_id)
+                .bind(row.weight)
+                .bind(row.reps)
+                .bind(row.rpe)
+                .bind(&row.notes)
+                .bind(at.format("%Y-%m-%d %H:%M:%S").to_string())
+                .bind(row.bodyweight)
+                .execute(&mut *tx)
+                .await?;
+                sets += 1;
+            }
+        }
+
+        sqlx::query("UPDATE training_sessions SET end_time = ? WHERE id = ?")
+            .bind(at.format("%Y-%m-%d %H:%M:%S").to_string())
+            .bind(&session_id)
+            .execute(&mut *tx)
+            .await?;
+        sessions += 1;
+    }
+
+    // Best set per exercise and day, without touching PRs already recorded.
+    sqlx::query(
+        &format!(r#"
+        INSERT OR IGNORE INTO personal_records (exercise_id, date, weight, reps, estimated_1rm)
+        WITH ranked AS (
+            SELECT tse.exercise_id, date(ts.start_time) AS day, es.weight, es.reps,
+                   {e1rm} AS e1rm,
+                   ROW_NUMBER() OVER (
+                       PARTITION BY tse.exercise_id, date(ts.start_time)
+                       ORDER BY {e1rm} DESC
+                   ) AS rn
+            FROM exercise_sets es
+            JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+            JOIN training_sessions ts ON ts.id = tse.training_session_id
+            WHERE ts.program_block_id = ? AND es.weight > 0 AND es.bodyweight = 0
+        )
+        SELECT exercise_id, day, weight, reps, e1rm FROM ranked WHERE rn = 1
+        "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
+    )
+    .bind(&block_id)
+    .execute(&mut *tx)
+    .await?;
+
+    sqlx::query(
+        r#"
+        UPDATE exercises
+        SET current_pr_date = pr.date, estimated_one_rm = pr.estimated_1rm
+        FROM (
+            SELECT exercise_id, date, estimated_1rm,
+                   ROW_NUMBER() OVER (PARTITION BY exercise_id ORDER BY estimated_1rm DESC) AS rn
+            FROM personal_records
+        ) AS pr
+        WHERE pr.exercise_id = exercises.id AND pr.rn = 1
+        AND (exercises.estimated_one_rm IS NULL OR pr.estimated_1rm > exercises.estimated_one_rm)
+        "#,
+    )
+    .execute(&mut *tx)
+    .await?;
+
+    tx.commit().await?;
+
+    if !skipped.is_empty() {
+        let shown = skipped.iter().take(10).map(|n| n.to_string()).collect::<Vec<_>>().join(", ");
+        let more = if skipped.len() > 10 { ", …" } else { "" };
+        println!(
+            "{} {}",
+            tr("warning:").accent().bold(),
+            tf("skipped {} unreadable rows ({}{})", &[&skipped.len(), &shown, &more])
+        );
+    }
+    if already > 0 {
+        ui::info(tf("{} days were already imported", &[&already]));
+    }
+    if created > 0 {
+        ui::info(tf("added {} new exercises", &[&created]));
+    }
+    let (first, last) = (days.keys().next().unwrap(), days.keys().last().unwrap());
+    ui::ok(tf(
+        "imported {} sets in {} sessions ({} – {}) into `{}`",
+        &[&sets, &sessions, &short_date(*first), &short_date(*last), &PROGRAM]
+    ));
+
+    Ok(())
+}
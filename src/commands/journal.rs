@@ -0,0 +1,416 @@
+use anyhow::Result;
+use chrono::NaiveDate;
+use sqlx::SqlitePool;
+use std::fs;
+
+use crate::{
+    cli::LogFormat,
+    commands::{
+        measure::{Change, changes},
+        session::session_tags,
+    },
+    dates::parse_date,
+    errors::AppError,
+    i18n::{kg, kg_places, language_tag, long_date, tf, tr},
+    types::SetStatus,
+    ui,
+};
+
+struct SetLine {
+    weight: f32,
+    reps: i32,
+    rpe: Option<f32>,
+    bodyweight: bool,
+    status: SetStatus,
+    notes: Option<String>,
+    media: Vec<String>,
+}
+
+struct ExerciseLog {
+    name: String,
+    notes: Option<String>,
+    sets: Vec<SetLine>,
+}
+
+struct SessionLog {
+    id: String,
+    program: String,
+    block: String,
+    start_time: String,
+    end_time: Option<String>,
+    notes: Option<String>,
+    tags: Vec<String>,
+    exercises: Vec<ExerciseLog>,
+}
+
+struct Pr {
+    exercise: String,
+    weight: f64,
+    reps: i32,
+    estimated_1rm: f64,
+}
+
+struct Day {
+    date: String,
+    sessions: Vec<SessionLog>,
+    prs: Vec<Pr>,
+}
+
+/// Finished sessions from `from` to `to` (YYYY-MM-DD, inclusive), only those
+/// tagged `tag` when given, grouped by day.
+async fn collect(pool: &SqlitePool, from: &str, to: &str, tag: Option<&str>) -> Result<Vec<Day>> {
+    let rows = sqlx::query_as::<
+        _,
+        (String, String, String, String, Option<String>, Option<String>, String, Option<String>, f32, i32, Option<f32>, bool, String, Option<String>, Option<String>),
+    >(
+        r#"
+        SELECT ts.id, p.name, pb.name, ts.start_time, ts.end_time, ts.notes,
+               e.name, tse.notes,
+               es.weight, es.reps, es.rpe, es.bodyweight, es.status, es.notes,
+               (SELECT group_concat(sa.path, char(10)) FROM set_attachments sa WHERE sa.exercise_set_id = es.id)
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        JOIN programs p ON p.id = pb.program_id
+        JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+        JOIN exercises e ON e.id = tse.exercise_id
+        JOIN exercise_sets es ON es.session_exercise_id = tse.id
+        WHERE date(ts.start_time) BETWEEN ?1 AND ?2
+        AND ts.end_time IS NOT NULL
+        AND (?3 IS NULL OR EXISTS (SELECT 1 FROM session_tags st WHERE st.training_session_id = ts.id AND st.tag = ?3))
+        ORDER BY ts.start_time, tse.order_index, es.set_index
+        "#,
+    )
+    .bind(from)
+    .bind(to)
+    .bind(tag)
+    .fetch_all(pool)
+    .await?;
+
+    let mut days: Vec<Day> = Vec::new();
+    for (sid, program, block, start, end, snotes, exercise, enotes, weight, reps, rpe, bodyweight, status, notes, media) in rows {
+        let date = start[..10].to_string();
+        if days.last().is_none_or(|d| d.date != date) {
+            days.push(Day { date, sessions: Vec::new(), prs: Vec::new() });
+        }
+        let day = days.last_mut().unwrap();
+
+        if day.sessions.last().is_none_or(|s| s.id != sid) {
+            let tags = session_tags(pool, &sid).await?;
+            day.sessions.push(SessionLog {
+                id: sid,
+                program,
+                block,
+                start_time: start,
+                end_time: end,
+                notes: snotes,
+                tags,
+                exercises: Vec::new(),
+            });
+        }
+        let session = day.sessions.last_mut().unwrap();
+
+        if session.exercises.last().is_none_or(|e| e.name != exercise) {
+            session.exercises.push(ExerciseLog { name: exercise, notes: enotes, sets: Vec::new() });
+        }
+        session.exercises.last_mut().unwrap().sets.push(SetLine {
+            weight,
+            reps,
+            rpe,
+            bodyweight,
+            status: SetStatus::parse(&status),
+            notes,
+            media: media.map(|m| m.lines().map(str::to_string).collect()).unwrap_or_default(),
+        });
+    }
+
+    // A day's best is a PR when it beats every earlier day.
+    let prs = sqlx::query_as::<_, (String, String, f64, i32, f64)>(
+        r#"
+        SELECT pr.date, e.name, pr.weight, pr.reps, pr.estimated_1rm
+        FROM personal_records pr
+        JOIN exercises e ON e.id = pr.exercise_id
+        WHERE pr.date BETWEEN ? AND ?
+        AND pr.estimated_1rm > COALESCE(
+            (SELECT MAX(prev.estimated_1rm) FROM personal_records prev
+             WHERE prev.exercise_id = pr.exercise_id AND prev.date < pr.date), 0)
+        ORDER BY pr.date, e.name
+        "#,
+    )
+    .bind(from)
+    .bind(to)
+    .fetch_all(pool)
+    .await?;
+
+    for (date, exercise, weight, reps, estimated_1rm) in prs {
+        if let Some(day) = days.iter_mut().find(|d| d.date == date) {
+            day.prs.push(Pr { exercise, weight, reps, estimated_1rm });
+        }
+    }
+
+    Ok(days)
+}
+
+fn duration(s: &SessionLog) -> String {
+    let parse = |t: &str| chrono::NaiveDateTime::parse_from_str(t, "%Y-%m-%d %H:%M:%S").ok();
+    match (parse(&s.start_time), s.end_time.as_deref().and_then(parse)) {
+        (Some(a), Some(b)) => format!("{} min", (b - a).num_minutes()),
+        _ => "?".to_string(),
+    }
+}
+
+fn load(set: &SetLine) -> String {
+    let load = if set.bodyweight { "bw".to_string() } else { format!("{}kg", kg(set.weight)) };
+    match set.status {
+        SetStatus::Completed => load,
+        SetStatus::Failed => format!("{} ({})", load, tr("failed")),
+        SetStatus::Skipped => tr("skipped").to_string(),
+    }
+}
+
+fn day_title(date: &str) -> String {
+    NaiveDate::parse_from_str(date, "%Y-%m-%d")
+        .map(long_date)
+        .unwrap_or_else(|_| date.to_string())
+}
+
+fn render_markdown(period: &str, days: &[Day], measurements: &[Change]) -> String {
+    let mut out = format!("# {}\n", tf("Training log — {}", &[&period]));
+
+    for day in days {
+        out += &format!("\n## {}\n", day_title(&day.date));
+
+        for s in &day.sessions {
+            out += &format!(
+                "\n### {} — {} ({}, {})\n",
+                s.program,
+                s.block,
+                &s.start_time[11..16],
+                duration(s)
+            );
+            if !s.tags.is_empty() {
+                out += &format!("\n{}\n", s.tags.iter().map(|t| format!("`#{}`", t)).collect::<Vec<_>>().join(" "));
+            }
+            if let Some(n) = &s.notes {
+                out += &format!("\n> {}\n", n);
+            }
+
+            for ex in &s.exercises {
+                out += &format!("\n**{}**\n\n", ex.name);
+                if let Some(n) = &ex.notes {
+                    out += &format!("_{}_\n\n", n);
+                }
+                out += &format!(
+                    "| {} | {} | {} | RPE | {} |\n|---:|---:|---:|---:|---|\n",
+                    tr("Set"),
+                    tr("Load"),
+                    tr("Reps"),
+                    tr("Notes")
+                );
+                for (i, set) in ex.sets.iter().enumerate() {
+                    out += &format!(
+                        "| {} | {} | {} | {} | {} |\n",
+                        i + 1,
+                        load(set),
+                        set.reps,
+                        set.rpe.map(|r| r.to_string()).unwrap_or_default(),
+                        set.notes.as_deref().unwrap_or_default().replace('|', "\\|")
+                    );
+                }
+
+                let media: Vec<(usize, &String)> = ex
+                    .sets
+                    .iter()
+                    .enumerate()
+                    .flat_map(|(i, set)| set.media.iter().map(move |m| (i + 1, m)))
+                    .collect();
+                if !media.is_empty() {
+                    out += "\n";
+                    for (set, path) in media {
+                        let name = path.rsplit(['/', '\\']).next().unwrap_or(path);
+                        out += &format!("- {} {}: [{}](<{}>)\n", tr("Set"), set, name, path);
+                    }
+                }
+            }
+        }
+
+        if !day.prs.is_empty() {
+            out += "\n**PRs**\n\n";
+            for pr in &day.prs {
+                out += &format!(
+                    "- 🏆 {}: {}kg × {} (e1RM {}kg)\n",
+                    pr.exercise,
+                    kg(pr.weight),
+                    pr.reps,
+                    kg_places(pr.estimated_1rm, 1)
+                );
+            }
+        }
+    }
+
+    if !measurements.is_empty() {
+        out += &format!("\n## {}\n\n", tr("Measurements"));
+        for m in measurements {
+            out += &format!("- {}\n", m.line());
+        }
+    }
+
+    out
+}
+
+/// The Markdown log of the finished sessions from `from` to `to`, titled
+/// `period`, with the measurements that changed over it; `None` without sessions.
+pub async fn markdown_log(pool: &SqlitePool, from: &str, to: &str, period: &str) -> Result<Option<String>> {
+    let days = collect(pool, from, to, None).await?;
+    if days.is_empty() {
+        return Ok(None);
+    }
+    let measurements = changes(pool, from, to).await?;
+    Ok(Some(render_markdown(period, &days, &measurements)))
+}
+
+pub fn escape_html(s: &str) -> String {
+    s.replace('&', "&amp;")
+        .replace('<', "&lt;")
+        .replace('>', "&gt;")
+        .replace('"', "&quot;")
+}
+
+fn render_html(period: &str, days: &[Day], measurements: &[Change]) -> String {
+    let mut out = format!(
+        r#"<!DOCTYPE html>
+<html lang="{lang}">
+<head>
+<meta charset="utf-8">
+<title>{title}</title>
+<style>
+  body {{ font-family: system-ui, sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #222; }}
+  h2 {{ border-bottom: 1px solid #ddd; padding-bottom: .25rem; margin-top: 2.5rem; }}
+  table {{ border-collapse: collapse; margin: .5rem 0 1rem; }}
+  th, td {{ padding: .2rem .75rem; border-bottom: 1px solid #eee; text-align: right; }}
+  td:last-child, th:last-child {{ text-align: left; }}
+  .muted {{ color: #777; }}
+  .pr {{ color: #b8860b; }}
+</style>
+</head>
+<body>
+<h1>{title}</h1>
+"#,
+        lang = language_tag(),
+        title = escape_html(&tf("Training log — {}", &[&period]))
+    );
+
+    for day in days {
+        out += &format!("<h2>{}</h2>\n", escape_html(&day_title(&day.date)));
+
+        for s in &day.sessions {
+            out += &format!(
+                "<h3>{} — {} <span class=\"muted\">({}, {})</span></h3>\n",
+                escape_html(&s.program),
+                escape_html(&s.block),
+                &s.start_time[11..16],
+                duration(s)
+            );
+            if !s.tags.is_empty() {
+                let tags: Vec<String> = s.tags.iter().map(|t| format!("#{}", escape_html(t))).collect();
+                out += &format!("<p class=\"muted\">{}</p>\n", tags.join(" "));
+            }
+            if let Some(n) = &s.notes {
+                out += &format!("<blockquote>{}</blockquote>\n", escape_html(n));
+            }
+
+            for ex in &s.exercises {
+                out += &format!("<h4>{}</h4>\n", escape_html(&ex.name));
+                if let Some(n) = &ex.notes {
+                    out += &format!("<p class=\"muted\"><em>{}</em></p>\n", escape_html(n));
+                }
+                out += &format!(
+                    "<table>\n<tr><th>{}</th><th>{}</th><th>{}</th><th>RPE</th><th>{}</th></tr>\n",
+                    tr("Set"),
+                    tr("Load"),
+                    tr("Reps"),
+                    tr("Notes")
+                );
+                for (i, set) in ex.sets.iter().enumerate() {
+                    out += &format!(
+                        "<tr><td>{}</td><td>{}</td><td>{}</td><td>{}</td><td>{}</td></tr>\n",
+                        i + 1,
+                        load(set),
+                        set.reps,
+                        set.rpe.map(|r| r.to_string()).unwrap_or_default(),
+                        escape_html(set.notes.as_deref().unwrap_or_default())
+                    );
+                }
+                out += "</table>\n";
+            }
+        }
+
+        if !day.prs.is_empty() {
+            out += "<ul class=\"pr\">\n";
+            for pr in &day.prs {
+                out += &format!(
+                    "<li>🏆 {}: {}kg × {} (e1RM {}kg)</li>\n",
+                    escape_html(&pr.exercise),
+                    kg(pr.weight),
+                    pr.reps,
+                    kg_places(pr.estimated_1rm, 1)
+                );
+            }
+            out += "</ul>\n";
+        }
+    }
+
+    if !measurements.is_empty() {
+        out += &format!("<h2>{}</h2>\n<ul>\n", escape_html(tr("Measurements")));
+        for m in measurements {
+            out += &format!("<li>{}</li>\n", escape_html(&m.line()));
+        }
+        out += "</ul>\n";
+    }
+
+    out += "</body>\n</html>\n";
+    out
+}
+
+pub async fn handle(
+    pool: &SqlitePool,
+    month: Option<String>,
+    format: LogFormat,
+    out: Option<String>,
+    tag: Option<String>,
+) -> Result<()> {
+    // A month as YYYY-MM, or any date (e.g. "last month") for the month it falls in
+    let month = month.unwrap_or_else(|| chrono::Local::now().format("%Y-%m").to_string());
+    let month = if NaiveDate::parse_from_str(&format!("{}-01", month), "%Y-%m-%d").is_ok() {
+        month
+    } else if let Some(day) = parse_date(Some(&month)) {
+        day.format("%Y-%m").to_string()
+    } else {
+        return Err(AppError::Invalid(tf("invalid month `{}` (expected YYYY-MM or a date)", &[&month]).into()).into());
+    };
+
+    let (from, to) = (format!("{}-01", month), format!("{}-31", month));
+    let days = collect(pool, &from, &to, tag.as_deref()).await?;
+    if days.is_empty() {
+        match &tag {
+            Some(tag) => ui::info(tf("no finished sessions tagged `{}` in {}", &[&tag, &month])),
+            None => ui::info(tf("no finished sessions in {}", &[&month])),
+        }
+        return Ok(());
+    }
+
+    let measurements = changes(pool, &from, &to).await?;
+    let doc = match format {
+        LogFormat::Md => render_markdown(&month, &days, &measurements),
+        LogFormat::Html => render_html(&month, &days, &measurements),
+    };
+
+    match out {
+        Some(path) => {
+            fs::write(&path, doc)?;
+            ui::ok(tf("training log for {} written to {}", &[&month, &path]));
+        }
+        None => print!("{}", doc),
+    }
+
+    Ok(())
+}
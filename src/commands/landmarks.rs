@@ -0,0 +1,230 @@
+use std::collections::{BTreeMap, HashMap};
+
+use anyhow::Result;
+use chrono::{Duration, NaiveDate};
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    errors::AppError,
+    formula,
+    i18n::{short_date, tf, tr},
+    types::{OutputFmt, cannonical_muscle, emit},
+    ui::{self, Themed},
+};
+
+/// Width of the weekly set ranges: 1–4, 5–8, 9–12...
+const RANGE_SETS: i64 = 4;
+/// Weeks a range needs before it can be called the best one.
+const MIN_WEEKS: usize = 3;
+/// Weeks over the best range listed per muscle, latest first.
+const SHOWN_OVER: usize = 5;
+
+#[derive(Serialize, Clone)]
+struct Range {
+    min_sets: i64,
+    max_sets: i64,
+    weeks: usize,
+    /// Mean e1RM change into the following week, in %.
+    progress_pct: f64,
+}
+
+#[derive(Serialize)]
+struct OverWeek {
+    week: String,
+    sets: i64,
+    progress_pct: Option<f64>,
+}
+
+#[derive(Serialize)]
+struct Landmark {
+    muscle: String,
+    weeks: usize,
+    best: Option<Range>,
+    ranges: Vec<Range>,
+    over: Vec<OverWeek>,
+}
+
+/// Per muscle, week (its Monday) and exercise of the last `weeks` weeks: the
+/// sets done and the top e1RM, as (muscle, week, exercise id, sets, e1RM).
+async fn weekly(pool: &SqlitePool, weeks: u32, muscle: Option<&str>) -> Result<Vec<(String, String, String, i64, Option<f64>)>> {
+    Ok(sqlx::query_as(&format!(
+        r#"
+        SELECT e.primary_muscle,
+               date(ts.start_time, 'localtime', 'weekday 0', '-6 days'),
+               e.id,
+               SUM(es.status != 'skipped'),
+               MAX(CASE WHEN es.status != 'skipped' AND es.weight > 0 AND es.bodyweight = 0
+                         AND COALESCE(es.ignore_for_one_rm, 0) = 0
+                        THEN {e1rm} END)
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        JOIN training_sessions ts ON ts.id = tse.training_session_id
+        JOIN exercises e ON e.id = tse.exercise_id
+        WHERE ts.end_time IS NOT NULL
+        AND ts.start_time >= datetime('now', '-' || ?1 || ' days')
+        AND (?2 IS NULL OR e.primary_muscle = ?2)
+        GROUP BY 1, 2, 3
+        "#,
+        e1rm = formula::e1rm_sql("es.weight", "es.reps")
+    ))
+    .bind(weeks * 7)
+    .bind(muscle)
+    .fetch_all(pool)
+    .await?)
+}
+
+/// The weekly set range whose weeks were followed by the fastest e1RM gains,
+/// and the weeks trained above it.
+fn landmark(muscle: String, weeks: BTreeMap<NaiveDate, (i64, HashMap<String, f64>)>) -> Landmark {
+    // Each week is judged by how the muscle's lifts moved into the next one;
+    // a week followed by a week off has nothing to judge it by.
+    let progress: BTreeMap<NaiveDate, Option<f64>> = weeks
+        .iter()
+        .map(|(week, (_, e1rms))| {
+            let next = weeks.get(&(*week + Duration::weeks(1)));
+            let changes: Vec<f64> = next
+                .into_iter()
+                .flat_map(|(_, next)| {
+                    e1rms
+                        .iter()
+                        .filter_map(|(ex, e1rm)| next.get(ex).map(|n| (n / e1rm - 1.0) * 100.0))
+                })
+                .collect();
+            let mean = (!changes.is_empty()).then(|| changes.iter().sum::<f64>() / changes.len() as f64);
+            (*week, mean)
+        })
+        .collect();
+
+    let mut by_range: BTreeMap<i64, Vec<f64>> = BTreeMap::new();
+    for (week, (sets, _)) in &weeks {
+        if let (true, Some(p)) = (*sets > 0, progress[week]) {
+            by_range.entry((sets - 1) / RANGE_SETS).or_default().push(p);
+        }
+    }
+    let ranges: Vec<Range> = by_range
+        .into_iter()
+        .map(|(i, changes)| Range {
+            min_sets: i * RANGE_SETS + 1,
+            max_sets: (i + 1) * RANGE_SETS,
+            weeks: changes.len(),
+            progress_pct: changes.iter().sum::<f64>() / changes.len() as f64,
+        })
+        .collect();
+
+    let best = ranges
+        .iter()
+        .filter(|r| r.weeks >= MIN_WEEKS && r.progress_pct > 0.0)
+        .max_by(|a, b| a.progress_pct.total_cmp(&b.progress_pct))
+        .cloned();
+
+    let over = match &best {
+        Some(best) => weeks
+            .iter()
+            .rev()
+            .filter(|(_, (sets, _))| *sets > best.max_sets)
+            .map(|(week, (sets, _))| OverWeek {
+                week: week.format("%Y-%m-%d").to_string(),
+                sets: *sets,
+                progress_pct: progress[week],
+            })
+            .collect(),
+        None => Vec::new(),
+    };
+
+    Landmark { muscle, weeks: weeks.len(), best, ranges, over }
+}
+
+fn print_landmark(l: &Landmark) {
+    let summary = match &l.best {
+        Some(best) => tf(
+            "best at {}–{} sets/week ({}%/week over {} weeks)",
+            &[&best.min_sets, &best.max_sets, &format!("{:+.1}", best.progress_pct), &best.weeks],
+        )
+        .good()
+        .to_string(),
+        None => tf("no range with {}+ weeks of progress yet", &[&MIN_WEEKS]).dimmed().to_string(),
+    };
+    println!("\n  {} {}", l.muscle.bold(), summary);
+
+    for r in &l.ranges {
+        let is_best = l.best.as_ref().is_some_and(|b| b.min_sets == r.min_sets);
+        let pct = format!("{:>6}", format!("{:+.1}%", r.progress_pct));
+        let pct = match r.progress_pct {
+            p if p > 0.0 => pct.good(),
+            p if p < 0.0 => pct.bad(),
+            _ => pct.dimmed(),
+        };
+        println!(
+            "    {} {:>7} {} {}",
+            if is_best { "◆".good().to_string() } else { " ".to_string() },
+            format!("{}–{}", r.min_sets, r.max_sets),
+            pct,
+            tf("({} weeks)", &[&r.weeks]).dimmed()
+        );
+    }
+
+    if !l.over.is_empty() {
+        let weeks: Vec<String> = l
+            .over
+            .iter()
+            .take(SHOWN_OVER)
+            .map(|w| {
+                let pct = w.progress_pct.map(|p| format!(", {:+.1}%", p)).unwrap_or_default();
+                let week = NaiveDate::parse_from_str(&w.week, "%Y-%m-%d").map(short_date).unwrap_or_else(|_| w.week.clone());
+                format!("{} ({} sets{})", week, w.sets, pct)
+            })
+            .collect();
+        let more = match l.over.len().saturating_sub(SHOWN_OVER) {
+            0 => String::new(),
+            n => format!(" {}", tf("and {} more", &[&n])),
+        };
+        println!("    {} {}{}", tr("▲ over it:").accent(), weeks.join(", "), more);
+    }
+}
+
+/// `volume-landmarks`: for each muscle, the weekly set range after which its
+/// lifts' e1RMs climbed fastest, and the weeks trained beyond it.
+pub async fn handle(pool: &SqlitePool, weeks: u32, muscle: Option<String>, fmt: OutputFmt) -> Result<()> {
+    let muscle = match muscle {
+        Some(m) => Some(cannonical_muscle(&m).ok_or_else(|| AppError::Invalid(tf("unknown muscle `{}`", &[&m])))?),
+        None => None,
+    };
+
+    let rows = weekly(pool, weeks, muscle.as_deref()).await?;
+    if rows.is_empty() {
+        ui::info(tf("no finished sessions in the last {} weeks", &[&weeks]));
+        return Ok(());
+    }
+
+    let mut muscles: BTreeMap<String, BTreeMap<NaiveDate, (i64, HashMap<String, f64>)>> = BTreeMap::new();
+    for (muscle, week, exercise, sets, e1rm) in rows {
+        let Ok(week) = NaiveDate::parse_from_str(&week, "%Y-%m-%d") else { continue };
+        let entry = muscles.entry(muscle).or_default().entry(week).or_default();
+        entry.0 += sets;
+        if let Some(e1rm) = e1rm.filter(|m| *m > 0.0) {
+            entry.1.insert(exercise, e1rm);
+        }
+    }
+
+    let landmarks: Vec<Landmark> = muscles.into_iter().map(|(m, w)| landmark(m, w)).collect();
+    emit(fmt, &landmarks, || {
+        println!(
+            "{} {}",
+            tr("Volume landmarks:").heading().bold(),
+            tf("(last {} weeks)", &[&weeks]).dimmed()
+        );
+        for l in &landmarks {
+            print_landmark(l);
+        }
+        println!();
+        println!(
+            "{}",
+            tr("Each week's working sets against how the muscle's e1RMs moved into the next week; ◆ marks the range that gained the most.")
+                .dimmed()
+        );
+    });
+
+    Ok(())
+}
@@ -0,0 +1,235 @@
+use anyhow::{Context, Result};
+use colored::Colorize;
+use serde::{Deserialize, Serialize};
+use sqlx::SqlitePool;
+use uuid::Uuid;
+
+use crate::{
+    errors::AppError,
+    i18n::{tf, tr},
+    types::cannonical_muscle,
+    ui::{self, Themed},
+};
+
+/// An `exercises.toml`: the exercise definitions without any training history.
+#[derive(Serialize, Deserialize)]
+struct Library {
+    #[serde(default)]
+    exercises: Vec<LibraryExercise>,
+}
+
+#[derive(Serialize, Deserialize)]
+struct LibraryExercise {
+    name: String,
+    muscle: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    description: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    equipment: Option<String>,
+    #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+    unilateral: bool,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    rounding: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    cues: Option<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    aliases: Vec<String>,
+}
+
+/// `export-exercises`: every exercise with its aliases, muscle and equipment,
+/// to a file or stdout.
+pub async fn handle_export(pool: &SqlitePool, out: Option<String>) -> Result<()> {
+    let rows: Vec<(String, String, String, Option<String>, Option<String>, bool, Option<String>, Option<String>)> =
+        sqlx::query_as(
+            r#"
+            SELECT id, name, primary_muscle, description, equipment, COALESCE(unilateral, 0), rounding, cues
+            FROM exercises
+            ORDER BY name
+            "#,
+        )
+        .fetch_all(pool)
+        .await?;
+
+    let mut exercises = Vec::with_capacity(rows.len());
+    for (id, name, muscle, description, equipment, unilateral, rounding, cues) in rows {
+        let aliases: Vec<String> =
+            sqlx::query_scalar("SELECT alias FROM exercise_aliases WHERE exercise_id = ? ORDER BY alias")
+                .bind(&id)
+                .fetch_all(pool)
+                .await?;
+        let text = |s: Option<String>| s.filter(|s| !s.trim().is_empty());
+        exercises.push(LibraryExercise {
+            name,
+            muscle,
+            description: text(description),
+            equipment: text(equipment),
+            unilateral,
+            rounding: text(rounding),
+            cues: text(cues),
+            aliases,
+        });
+    }
+
+    let count = exercises.len();
+    let toml = toml::to_string_pretty(&Library { exercises })?;
+    match out {
+        Some(path) => {
+            std::fs::write(&path, toml).with_context(|| format!("could not write `{}`", path))?;
+            ui::ok(tf("{} exercises exported to {}", &[&count, &path]));
+        }
+        None => print!("{}", toml),
+    }
+
+    Ok(())
+}
+
+/// The exercise an imported one stands for: same name, or one of its names
+/// is the other's alias.
+async fn existing(pool: &SqlitePool, ex: &LibraryExercise) -> Result<Option<String>> {
+    for name in std::iter::once(&ex.name).chain(&ex.aliases) {
+        let id: Option<String> = sqlx::query_scalar(
+            r#"
+            SELECT id FROM exercises WHERE name = ?1 COLLATE NOCASE
+            UNION ALL
+            SELECT exercise_id FROM exercise_aliases WHERE alias = ?1
+            LIMIT 1
+            "#,
+        )
+        .bind(name)
+        .fetch_optional(pool)
+        .await?;
+        if id.is_some() {
+            return Ok(id);
+        }
+    }
+    Ok(None)
+}
+
+/// `import-exercises`: add a shared library's exercises.
+pub async fn handle_import(pool: &SqlitePool, file: String, merge: bool) -> Result<()> {
+    let raw = std::fs::read_to_string(&file).with_context(|| format!("could not read `{}`", file))?;
+    let library: Library = toml::from_str(&raw).with_context(|| format!("could not parse `{}`", file))?;
+    import(pool, library, merge).await
+}
+
+/// Add a library given as TOML text, such as the starter one `setup` offers.
+pub async fn import_str(pool: &SqlitePool, raw: &str, merge: bool) -> Result<()> {
+    import(pool, toml::from_str(raw)?, merge).await
+}
+
+/// Without `merge` any exercise that already exists stops the import; with
+/// it, those are matched by name or alias and only gain the fields and
+/// aliases they lack.
+async fn import(pool: &SqlitePool, library: Library, merge: bool) -> Result<()> {
+    let mut matched = Vec::with_capacity(library.exercises.len());
+    let mut seen = std::collections::HashSet::new();
+    for ex in &library.exercises {
+        if !seen.insert(ex.name.to_lowercase()) {
+            continue;
+        }
+        let Some(muscle) = cannonical_muscle(&ex.muscle) else {
+            return Err(AppError::Invalid(tf("unknown muscle `{}` for `{}`", &[&ex.muscle, &ex.name])).into());
+        };
+        matched.push((ex, muscle, existing(pool, ex).await?));
+    }
+
+    let clashes: Vec<&str> = matched
+        .iter()
+        .filter(|(_, _, id)| id.is_some())
+        .map(|(ex, _, _)| ex.name.as_str())
+        .collect();
+    if !merge && !clashes.is_empty() {
+        return Err(AppError::Invalid(tf(
+            "{} exercises already exist ({}); pass --merge to combine the libraries",
+            &[&clashes.len(), &clashes.join(", ")],
+        ))
+        .into());
+    }
+
+    let (mut added, mut merged, mut aliases_added) = (0, 0, 0);
+    let mut tx = pool.begin().await?;
+    for (ex, muscle, found) in matched {
+        let id = match found {
+            Some(id) => {
+                // Only fill what the local exercise leaves empty.
+                let res = sqlx::query(
+                    r#"
+                    UPDATE exercises
+                    SET description = COALESCE(NULLIF(description, ''), ?1),
+                        equipment   = COALESCE(equipment, ?2),
+                        unilateral  = CASE WHEN ?3 THEN 1 ELSE unilateral END,
+                        rounding    = COALESCE(rounding, ?4),
+                        cues        = COALESCE(NULLIF(cues, ''), ?5)
+                    WHERE id = ?6
+                    AND (((description IS NULL OR description = '') AND ?1 IS NOT NULL)
+                         OR (equipment IS NULL AND ?2 IS NOT NULL)
+                         OR (?3 AND COALESCE(unilateral, 0) = 0)
+                         OR (rounding IS NULL AND ?4 IS NOT NULL)
+                         OR ((cues IS NULL OR cues = '') AND ?5 IS NOT NULL))
+                    "#,
+                )
+                .bind(&ex.description)
+                .bind(&ex.equipment)
+                .bind(ex.unilateral)
+                .bind(&ex.rounding)
+                .bind(&ex.cues)
+                .bind(&id)
+                .execute(&mut *tx)
+                .await?;
+                if res.rows_affected() > 0 {
+                    merged += 1;
+                    println!("  {} {}", "~".accent(), ex.name);
+                }
+                id
+            }
+            None => {
+                let id = Uuid::new_v4().to_string();
+                sqlx::query(
+                    r#"
+                    INSERT INTO exercises (id, name, primary_muscle, description, equipment, unilateral, rounding, cues, created_at)
+                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+                    "#,
+                )
+                .bind(&id)
+                .bind(&ex.name)
+                .bind(&muscle)
+                .bind(&ex.description)
+                .bind(&ex.equipment)
+                .bind(ex.unilateral)
+                .bind(&ex.rounding)
+                .bind(&ex.cues)
+                .execute(&mut *tx)
+                .await?;
+                added += 1;
+                println!("  {} {}", "+".good(), ex.name);
+                id
+            }
+        };
+
+        // An alias naming some other exercise would make lookups ambiguous.
+        for alias in ex.aliases.iter().map(|a| a.trim()).filter(|a| !a.is_empty()) {
+            let res = sqlx::query(
+                r#"
+                INSERT OR IGNORE INTO exercise_aliases (exercise_id, alias)
+                SELECT ?1, ?2
+                WHERE NOT EXISTS (SELECT 1 FROM exercises WHERE name = ?2 COLLATE NOCASE)
+                AND NOT EXISTS (SELECT 1 FROM exercise_aliases WHERE alias = ?2 AND exercise_id <> ?1)
+                "#,
+            )
+            .bind(&id)
+            .bind(alias)
+            .execute(&mut *tx)
+            .await?;
+            aliases_added += res.rows_affected();
+        }
+    }
+    tx.commit().await?;
+
+    println!(
+        "{} {}",
+        tr("Summary:").heading().bold(),
+        tf("{} added, {} merged, {} aliases added", &[&added, &merged, &aliases_added])
+    );
+
+    Ok(())
+}
@@ -0,0 +1,176 @@
+use anyhow::Result;
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    dates::{DATE_FORMATS, parse_date},
+    errors::AppError,
+    i18n::{short_date, tf, tr},
+    ui::{self, Themed},
+};
+
+/// Body sites we track, in display order. Values are circumferences in cm.
+pub const SITES: &[&str] = &["waist", "hips", "chest", "arm", "thigh", "calf", "neck"];
+
+/// How a site changed over a period: (site, first, last).
+pub struct Change {
+    pub site: String,
+    pub first: f64,
+    pub last: f64,
+}
+
+impl Change {
+    /// "waist 86.5 → 84 cm (-2.5)"
+    pub fn line(&self) -> String {
+        format!("{} {} → {} cm ({:+.1})", self.site, self.first, self.last, self.last - self.first)
+    }
+}
+
+/// First and last reading of every site measured between `from` and `to` (inclusive).
+pub async fn changes(pool: &SqlitePool, from: &str, to: &str) -> Result<Vec<Change>> {
+    let rows: Vec<(String, f64, f64)> = sqlx::query_as(
+        r#"
+        SELECT site,
+               (SELECT value FROM measurements f WHERE f.site = m.site AND f.date BETWEEN ?1 AND ?2 ORDER BY f.date LIMIT 1),
+               (SELECT value FROM measurements l WHERE l.site = m.site AND l.date BETWEEN ?1 AND ?2 ORDER BY l.date DESC LIMIT 1)
+        FROM measurements m
+        WHERE m.date BETWEEN ?1 AND ?2
+        GROUP BY site
+        "#,
+    )
+    .bind(from)
+    .bind(to)
+    .fetch_all(pool)
+    .await?;
+
+    let mut out: Vec<Change> = rows
+        .into_iter()
+        .map(|(site, first, last)| Change { site, first, last })
+        .collect();
+    out.sort_by_key(|c| SITES.iter().position(|s| *s == c.site).unwrap_or(SITES.len()));
+    Ok(out)
+}
+
+fn colored_delta(delta: f64) -> String {
+    let s = format!("{:+.1} cm", delta);
+    match delta {
+        d if d.abs() < 0.05 => s.dimmed().to_string(),
+        d if d < 0.0 => s.info().to_string(),
+        _ => s.accent().to_string(),
+    }
+}
+
+/// Change per site over the last `weeks` weeks, for `status`. Prints nothing
+/// without readings in that window.
+pub async fn print_trends(pool: &SqlitePool, weeks: u32) -> Result<()> {
+    let from = (chrono::Local::now().date_naive() - chrono::Duration::weeks(weeks as i64))
+        .format("%Y-%m-%d")
+        .to_string();
+    let rows = changes(pool, &from, "9999-12-31").await?;
+    if rows.is_empty() {
+        return Ok(());
+    }
+
+    println!();
+    println!("{}", tr("Measurements:").heading().bold());
+    for c in rows {
+        println!(
+            "  {:<6} {:>6} cm  {} {}",
+            c.site,
+            c.last,
+            colored_delta(c.last - c.first),
+            tf("since {} cm", &[&c.first]).dimmed()
+        );
+    }
+
+    Ok(())
+}
+
+/// Every reading of the last `weeks` weeks, site by site, with the change
+/// from the reading before.
+async fn print_history(pool: &SqlitePool, weeks: u32) -> Result<()> {
+    let rows: Vec<(String, String, f64)> = sqlx::query_as(
+        r#"
+        SELECT site, date, value
+        FROM measurements
+        WHERE date >= date('now', '-' || ? || ' days')
+        ORDER BY date
+        "#,
+    )
+    .bind(weeks * 7)
+    .fetch_all(pool)
+    .await?;
+
+    if rows.is_empty() {
+        ui::info(tr("no measurements yet, log some with e.g. `measure --waist 84 --arm 39.5`"));
+        return Ok(());
+    }
+
+    println!("{}", tf("Measurements (last {} weeks):", &[&weeks]).heading().bold());
+    for site in SITES {
+        let readings: Vec<&(String, String, f64)> = rows.iter().filter(|(s, _, _)| s == site).collect();
+        if readings.is_empty() {
+            continue;
+        }
+
+        println!("• {}", site.bold());
+        let mut prev: Option<f64> = None;
+        for (_, date, value) in readings {
+            let delta = prev.map(|p| colored_delta(value - p)).unwrap_or_default();
+            let date = chrono::NaiveDate::parse_from_str(date, "%Y-%m-%d")
+                .map(short_date)
+                .unwrap_or_else(|_| date.clone());
+            println!("  {:<12} {:>6} cm  {}", date.dimmed(), value, delta);
+            prev = Some(*value);
+        }
+    }
+
+    Ok(())
+}
+
+pub async fn handle(
+    pool: &SqlitePool,
+    values: Vec<(&'static str, Option<f32>)>,
+    date: Option<String>,
+    weeks: u32,
+) -> Result<()> {
+    let values: Vec<(&str, f32)> = values.into_iter().filter_map(|(s, v)| Some((s, v?))).collect();
+    if values.is_empty() {
+        return print_history(pool, weeks).await;
+    }
+
+    let Some(day) = parse_date(date.as_deref()) else {
+        return Err(AppError::Invalid(tf("invalid date `{}` (expected {})", &[&date.unwrap_or_default(), &DATE_FORMATS]).into()).into());
+    };
+
+    if let Some((site, _)) = values.iter().find(|(_, v)| *v <= 0.0) {
+        return Err(AppError::Invalid(tf("{} must be above 0", &[&site]).into()).into());
+    }
+
+    let mut tx = pool.begin().await?;
+    for (site, value) in &values {
+        sqlx::query(
+            r#"
+            INSERT INTO measurements (date, site, value, updated_at) VALUES (?, ?, ?, datetime('now'))
+            ON CONFLICT (date, site) DO UPDATE SET
+              value = excluded.value,
+              updated_at = excluded.updated_at
+            "#,
+        )
+        .bind(day.format("%Y-%m-%d").to_string())
+        .bind(site)
+        .bind(value)
+        .execute(&mut *tx)
+        .await?;
+    }
+    tx.commit().await?;
+
+    let logged = values
+        .iter()
+        .map(|(site, value)| format!("{} {} cm", site, value))
+        .collect::<Vec<_>>()
+        .join(", ");
+    ui::ok(tf("{}: {}", &[&short_date(day), &logged]));
+
+    Ok(())
+}
@@ -0,0 +1,396 @@
+use std::{fmt::Write as _, net::IpAddr, time::Duration};
+
+use anyhow::{Context, Result};
+use colored::Colorize;
+use serde_json::Value;
+use sqlx::SqlitePool;
+use tokio::{
+    io::{AsyncReadExt, AsyncWriteExt},
+    net::{TcpListener, TcpStream},
+};
+
+use crate::{
+    cli::TokenScope,
+    commands::{
+        daemon::{READ_METHODS, dispatch},
+        rest::{current_streak, load_weeks},
+        token,
+    },
+    i18n::{tf, tr},
+    types::{Config, SET_LOAD},
+    ui::{self, Themed},
+};
+
+const CONTENT_TYPE: &str = "application/openmetrics-text; version=1.0.0; charset=utf-8";
+
+/// Label values may not contain raw quotes, backslashes or newlines.
+fn escape_label(v: &str) -> String {
+    v.replace('\\', "\\\\").replace('"', "\\\"").replace('\n', "\\n")
+}
+
+/// Current metrics in the OpenMetrics text format.
+async fn render(pool: &SqlitePool) -> Result<String> {
+    let sessions: i64 = sqlx::query_scalar("SELECT COUNT(*) FROM training_sessions WHERE end_time IS NOT NULL")
+        .fetch_one(pool)
+        .await?;
+
+    let tonnage: Vec<(String, f64)> = sqlx::query_as(
+        &format!(r#"
+        SELECT e.primary_muscle, CAST(SUM({load} * es.reps) AS REAL)
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        JOIN exercises e ON e.id = tse.exercise_id
+        WHERE es.timestamp >= datetime('now', '-7 days')
+        AND {load} > 0
+        GROUP BY e.primary_muscle
+        ORDER BY e.primary_muscle
+        "#, load = SET_LOAD),
+    )
+    .fetch_all(pool)
+    .await?;
+
+    let (trained, rested) = load_weeks(pool).await?;
+    let streak = current_streak(&trained, &rested, chrono::Local::now().date_naive());
+
+    let last_session: Option<i64> = sqlx::query_scalar(
+        "SELECT CAST(strftime('%s', MAX(end_time)) AS INTEGER) FROM training_sessions WHERE end_time IS NOT NULL",
+    )
+    .fetch_one(pool)
+    .await?;
+
+    let mut out = String::new();
+    writeln!(out, "# TYPE lazarus_sessions counter")?;
+    writeln!(out, "# HELP lazarus_sessions Finished training sessions.")?;
+    writeln!(out, "lazarus_sessions_total {}", sessions)?;
+
+    writeln!(out, "# TYPE lazarus_weekly_tonnage_kilograms gauge")?;
+    writeln!(out, "# UNIT lazarus_weekly_tonnage_kilograms kilograms")?;
+    writeln!(out, "# HELP lazarus_weekly_tonnage_kilograms Weight × reps over the last 7 days, by primary muscle.")?;
+    for (muscle, kg) in &tonnage {
+        writeln!(out, "lazarus_weekly_tonnage_kilograms{{muscle=\"{}\"}} {}", escape_label(muscle), kg)?;
+    }
+
+    writeln!(out, "# TYPE lazarus_streak_weeks gauge")?;
+    writeln!(out, "# HELP lazarus_streak_weeks Consecutive weeks with at least one session (planned rest weeks don't break it).")?;
+    writeln!(out, "lazarus_streak_weeks {}", streak)?;
+
+    if let Some(ts) = last_session {
+        writeln!(out, "# TYPE lazarus_last_session_timestamp_seconds gauge")?;
+        writeln!(out, "# UNIT lazarus_last_session_timestamp_seconds seconds")?;
+        writeln!(out, "# HELP lazarus_last_session_timestamp_seconds When the last session ended.")?;
+        writeln!(out, "lazarus_last_session_timestamp_seconds {}", ts)?;
+    }
+
+    writeln!(out, "# EOF")?;
+    Ok(out)
+}
+
+/// A bare port (":9104") listens on loopback only; name an address, e.g.
+/// "0.0.0.0:9104", to be reachable from other machines.
+fn listen_addr(listen: &str) -> String {
+    if listen.starts_with(':') {
+        format!("127.0.0.1{}", listen)
+    } else {
+        listen.to_string()
+    }
+}
+
+/// Largest request accepted, body included.
+const MAX_REQUEST: usize = 64 * 1024;
+/// A client that hasn't sent its request by then is dropped, so a stalled
+/// connection can't hold up the next scrape.
+const READ_TIMEOUT: Duration = Duration::from_secs(5);
+
+/// The parts of an HTTP/1.1 request the server looks at.
+struct Request {
+    method: String,
+    path: String,
+    bearer: Option<String>,
+    body: String,
+}
+
+async fn read_request(stream: &mut TcpStream) -> Option<Request> {
+    let mut buf = Vec::new();
+    let mut chunk = [0u8; 4096];
+    let head_len = loop {
+        let n = stream.read(&mut chunk).await.ok()?;
+        if n == 0 {
+            return None;
+        }
+        buf.extend_from_slice(&chunk[..n]);
+        if let Some(i) = buf.windows(4).position(|w| w == b"\r\n\r\n") {
+            break i + 4;
+        }
+        if buf.len() > MAX_REQUEST {
+            return None;
+        }
+    };
+
+    let head = String::from_utf8_lossy(&buf[..head_len]).to_string();
+    let mut lines = head.lines();
+    let mut request_line = lines.next()?.split_whitespace();
+    let method = request_line.next()?.to_string();
+    let path = request_line.next()?.split('?').next()?.to_string();
+
+    let (mut bearer, mut length) = (None, 0);
+    for line in lines {
+        let Some((name, value)) = line.split_once(':') else { continue };
+        let value = value.trim();
+        if name.eq_ignore_ascii_case("authorization") {
+            bearer = value
+                .split_once(' ')
+                .filter(|(kind, _)| kind.eq_ignore_ascii_case("bearer"))
+                .map(|(_, token)| token.trim().to_string());
+        } else if name.eq_ignore_ascii_case("content-length") {
+            length = value.parse().ok()?;
+        }
+    }
+    if head_len + length > MAX_REQUEST {
+        return None;
+    }
+    while buf.len() < head_len + length {
+        let n = stream.read(&mut chunk).await.ok()?;
+        if n == 0 {
+            return None;
+        }
+        buf.extend_from_slice(&chunk[..n]);
+    }
+    let body = String::from_utf8_lossy(&buf[head_len..head_len + length]).to_string();
+
+    Some(Request { method, path, bearer, body })
+}
+
+struct Response {
+    status: u16,
+    content_type: &'static str,
+    body: String,
+}
+
+impl Response {
+    fn text(status: u16, body: &str) -> Self {
+        Self { status, content_type: "text/plain; charset=utf-8", body: format!("{}\n", body) }
+    }
+
+    fn to_http(&self) -> String {
+        let reason = match self.status {
+            200 => "OK",
+            400 => "Bad Request",
+            401 => "Unauthorized",
+            403 => "Forbidden",
+            404 => "Not Found",
+            405 => "Method Not Allowed",
+            _ => "Internal Server Error",
+        };
+        let challenge = if self.status == 401 { "WWW-Authenticate: Bearer\r\n" } else { "" };
+        format!(
+            "HTTP/1.1 {} {}\r\n{}Content-Type: {}\r\nContent-Length: {}\r\nConnection: close\r\n\r\n{}",
+            self.status,
+            reason,
+            challenge,
+            self.content_type,
+            self.body.len(),
+            self.body
+        )
+    }
+}
+
+/// Route one request. Once any token exists every request needs one; before
+/// that `/metrics` is open and `/rpc` is off. Read-scoped tokens only get the
+/// JSON-RPC methods that can't change anything.
+async fn respond(pool: &SqlitePool, cfg: &Config, req: &Request) -> Result<(Response, Option<String>)> {
+    let token = match &req.bearer {
+        Some(secret) => token::authenticate(pool, secret).await?,
+        None => None,
+    };
+    let name = token.as_ref().map(|t| t.name.clone());
+    if token.is_none() && token::any(pool).await? {
+        let why = if req.bearer.is_some() { "unknown or revoked token" } else { "missing bearer token" };
+        return Ok((Response::text(401, why), name));
+    }
+
+    let response = match (req.method.as_str(), req.path.as_str()) {
+        ("GET", "/metrics") => Response { status: 200, content_type: CONTENT_TYPE, body: render(pool).await? },
+        ("POST", "/rpc") => match &token {
+            None => Response::text(403, "/rpc needs a token: `lazarus token create <name>`"),
+            Some(t) => {
+                let method = serde_json::from_str::<Value>(&req.body)
+                    .ok()
+                    .and_then(|v| v.get("method").and_then(Value::as_str).map(str::to_string));
+                match method {
+                    Some(m) if t.scope == TokenScope::Read && !READ_METHODS.contains(&m.as_str()) => {
+                        Response::text(403, &format!("token `{}` is read-only and `{}` needs write", t.name, m))
+                    }
+                    _ => Response {
+                        status: 200,
+                        content_type: "application/json",
+                        body: dispatch(pool, cfg, &req.body).await.to_string(),
+                    },
+                }
+            }
+        },
+        (_, "/metrics") | (_, "/rpc") => Response::text(405, "method not allowed"),
+        _ => Response::text(404, "not found"),
+    };
+    Ok((response, name))
+}
+
+/// One access log line: time, client, request, status and the token used.
+fn log_request(peer: IpAddr, req: Option<&Request>, status: u16, token: Option<&str>) {
+    let (method, path) = req.map(|r| (r.method.as_str(), r.path.as_str())).unwrap_or(("-", "-"));
+    let status = match status {
+        s if s < 300 => s.to_string().good(),
+        s if s < 500 => s.to_string().accent(),
+        s => s.to_string().bad(),
+    };
+    println!(
+        "{} {} {} {} {} {}",
+        chrono::Local::now().format("%Y-%m-%d %H:%M:%S").to_string().dimmed(),
+        peer,
+        method,
+        path,
+        status,
+        token.unwrap_or("-").dimmed()
+    );
+}
+
+/// Serve `/metrics` and, for token holders, the daemon's JSON-RPC on `/rpc`
+/// until Ctrl-C, which lets the request being answered finish first. Requests
+/// are handled one at a time; a scrape every few seconds doesn't need more.
+pub async fn handle(pool: &SqlitePool, listen: String, cfg: &Config) -> Result<()> {
+    let addr = listen_addr(&listen);
+    let listener = TcpListener::bind(&addr)
+        .await
+        .with_context(|| format!("cannot listen on {}", addr))?;
+
+    ui::info(tf("serving metrics on http://{}/metrics (Ctrl-C to stop)", &[&addr]));
+    if !token::any(pool).await? && !listener.local_addr()?.ip().is_loopback() {
+        println!(
+            "{} {}",
+            tr("warning:").accent().bold(),
+            tr("no API tokens, so anyone on the network can read these metrics; add one with `lazarus token create <name>`")
+        );
+    }
+
+    let stop = tokio::signal::ctrl_c();
+    tokio::pin!(stop);
+    loop {
+        let (mut stream, peer) = tokio::select! {
+            conn = listener.accept() => conn?,
+            _ = &mut stop => break,
+        };
+
+        let req = tokio::time::timeout(READ_TIMEOUT, read_request(&mut stream)).await.ok().flatten();
+        let (response, token) = match &req {
+            Some(req) => match respond(pool, cfg, req).await {
+                Ok(r) => r,
+                Err(e) => (Response::text(500, &e.to_string()), None),
+            },
+            None => (Response::text(400, "bad request"), None),
+        };
+        log_request(peer.ip(), req.as_ref(), response.status, token.as_deref());
+
+        // A scraper hanging up early is not our problem.
+        let _ = stream.write_all(response.to_http().as_bytes()).await;
+        let _ = stream.shutdown().await;
+    }
+
+    ui::info(tr("metrics server stopped"));
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::testutil::{self, assert_golden, memory_db};
+
+    #[tokio::test]
+    async fn empty_database() {
+        let pool = memory_db().await;
+        assert_golden("metrics_empty.txt", &render(&pool).await.unwrap());
+    }
+
+    #[tokio::test]
+    async fn one_old_session_and_recent_sets() {
+        let pool = memory_db().await;
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        let bench = testutil::exercise(&pool, "Bench Press", "chest").await;
+        let block = testutil::program_block(&pool, "P", "A", &[&squat, &bench], "5").await;
+        let session = testutil::session(&pool, &block, "2024-01-01 10:00:00", Some("2024-01-01 11:00:00")).await;
+        let now = chrono::Utc::now().format("%Y-%m-%d %H:%M:%S").to_string();
+        for (exercise, weight) in [(&squat, 100.0), (&bench, 60.0)] {
+            let tse = testutil::session_exercise(&pool, &session, exercise).await;
+            testutil::set(&pool, &tse, weight, 5, "completed", &now).await;
+            testutil::set(&pool, &tse, weight, 5, "completed", "2024-01-01 10:30:00").await;
+        }
+        assert_golden("metrics_one_session.txt", &render(&pool).await.unwrap());
+    }
+
+    async fn add_token(pool: &SqlitePool, name: &str, scope: &str) -> String {
+        use sha2::{Digest, Sha256};
+        let secret = format!("lzr_test_{}", name);
+        let hash: String = Sha256::digest(secret.as_bytes()).iter().map(|b| format!("{:02x}", b)).collect();
+        sqlx::query("INSERT INTO api_tokens (id, name, token_hash, scope, created_at) VALUES (?, ?, ?, ?, datetime('now'))")
+            .bind(name)
+            .bind(name)
+            .bind(hash)
+            .bind(scope)
+            .execute(pool)
+            .await
+            .unwrap();
+        secret
+    }
+
+    fn rpc(bearer: Option<&str>, body: &str) -> Request {
+        Request { method: "POST".into(), path: "/rpc".into(), bearer: bearer.map(str::to_string), body: body.into() }
+    }
+
+    #[tokio::test]
+    async fn rpc_needs_the_right_token() {
+        let pool = memory_db().await;
+        let cfg = Config::default();
+        let reader = add_token(&pool, "reader", "read").await;
+        let writer = add_token(&pool, "writer", "write").await;
+        let run = r#"{"jsonrpc":"2.0","id":1,"method":"run","params":{"args":["session","show"]}}"#;
+
+        let (res, _) = respond(&pool, &cfg, &rpc(None, run)).await.unwrap();
+        assert_eq!(res.status, 401);
+        let (res, _) = respond(&pool, &cfg, &rpc(Some("lzr_nope"), run)).await.unwrap();
+        assert_eq!(res.status, 401);
+        let (res, name) = respond(&pool, &cfg, &rpc(Some(&reader), run)).await.unwrap();
+        assert_eq!((res.status, name.as_deref()), (403, Some("reader")));
+        let (res, _) = respond(&pool, &cfg, &rpc(Some(&writer), run)).await.unwrap();
+        assert_eq!(res.status, 200);
+        assert!(res.body.contains(r#""code":4"#), "{}", res.body);
+    }
+
+    #[tokio::test]
+    async fn write_tokens_only_run_session_commands() {
+        let pool = memory_db().await;
+        let cfg = Config::default();
+        let writer = add_token(&pool, "writer", "write").await;
+        for args in [
+            r#"["db","export","/tmp/all.toml"]"#,
+            r#"["--json","token","create","more","--scope","write"]"#,
+            r#"["-q","metrics"]"#,
+        ] {
+            let body = format!(r#"{{"jsonrpc":"2.0","id":1,"method":"run","params":{{"args":{}}}}}"#, args);
+            let (res, _) = respond(&pool, &cfg, &rpc(Some(&writer), &body)).await.unwrap();
+            let reply: Value = serde_json::from_str(&res.body).unwrap();
+            assert_eq!(reply["error"]["code"], -32602, "{}", args);
+        }
+        let tokens: i64 = sqlx::query_scalar("SELECT COUNT(*) FROM api_tokens").fetch_one(&pool).await.unwrap();
+        assert_eq!(tokens, 1);
+    }
+
+    #[test]
+    fn listen_address() {
+        assert_eq!(listen_addr(":9104"), "127.0.0.1:9104");
+        assert_eq!(listen_addr("0.0.0.0:9104"), "0.0.0.0:9104");
+        assert_eq!(listen_addr("127.0.0.1:9104"), "127.0.0.1:9104");
+    }
+
+    #[test]
+    fn label_escaping() {
+        assert_eq!(escape_label("a\"b\\c\nd"), "a\\\"b\\\\c\\nd");
+    }
+}
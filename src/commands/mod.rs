@@ -5,3 +5,51 @@ pub mod program;
 pub mod calendar;
 pub mod db;
 pub mod status;
+pub mod exdb;
+pub mod search;
+pub mod doctor;
+pub mod equip;
+pub mod gym;
+pub mod wrapped;
+pub mod compare;
+pub mod week;
+pub mod metrics;
+pub mod journal;
+pub mod rest;
+pub mod recovery;
+pub mod nutrition;
+pub mod goal;
+pub mod block_stats;
+pub mod today;
+pub mod attach;
+pub mod test_1rm;
+pub mod measure;
+pub mod photo;
+pub mod standards;
+pub mod points;
+pub mod remind;
+pub mod daemon;
+pub mod analyze;
+pub mod stall;
+pub mod rest_analysis;
+pub mod trend;
+pub mod import_csv;
+pub mod share;
+pub mod sheet;
+pub mod rpe_chart;
+pub mod freq;
+pub mod recover;
+pub mod pr_timeline;
+pub mod finish;
+pub mod circuit;
+pub mod demo;
+pub mod wal;
+pub mod top_set;
+pub mod library;
+pub mod volume_diff;
+pub mod landmarks;
+pub mod coach_export;
+pub mod setup;
+pub mod vs_target;
+pub mod token;
+pub mod remote;
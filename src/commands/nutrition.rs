@@ -0,0 +1,99 @@
+use anyhow::Result;
+use chrono::Duration;
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    dates::{DATE_FORMATS, parse_date},
+    errors::AppError,
+    i18n::{short_date, tf, tr},
+    ui::{self, Themed},
+};
+
+pub async fn handle(pool: &SqlitePool, calories: u32, protein: Option<f32>, date: Option<String>) -> Result<()> {
+    let Some(day) = parse_date(date.as_deref()) else {
+        return Err(AppError::Invalid(tf("invalid date `{}` (expected {})", &[&date.unwrap_or_default(), &DATE_FORMATS]).into()).into());
+    };
+
+    sqlx::query(
+        r#"
+        INSERT INTO nutrition (date, calories, protein, updated_at) VALUES (?, ?, ?, datetime('now'))
+        ON CONFLICT (date) DO UPDATE SET
+          calories = excluded.calories,
+          protein = COALESCE(excluded.protein, protein),
+          updated_at = excluded.updated_at
+        "#,
+    )
+    .bind(day.format("%Y-%m-%d").to_string())
+    .bind(calories as i64)
+    .bind(protein)
+    .execute(pool)
+    .await?;
+
+    let protein = protein.map(|p| format!(", {}g protein", p)).unwrap_or_default();
+    ui::ok(tf("{}: {} kcal{}", &[&short_date(day), &calories, &protein]));
+
+    Ok(())
+}
+
+fn intake(calories: i64, protein: Option<f64>) -> String {
+    match protein {
+        Some(p) => format!("{} kcal, {:.0}g protein", calories, p),
+        None => format!("{} kcal", calories),
+    }
+}
+
+/// Yesterday's intake, for the top of `status`.
+pub async fn print_yesterday(pool: &SqlitePool) -> Result<()> {
+    let yesterday = chrono::Local::now().date_naive() - Duration::days(1);
+    let row: Option<(i64, Option<f64>)> = sqlx::query_as("SELECT calories, protein FROM nutrition WHERE date = ?")
+        .bind(yesterday.format("%Y-%m-%d").to_string())
+        .fetch_optional(pool)
+        .await?;
+
+    if let Some((calories, protein)) = row {
+        println!("{} {}", tr("Yesterday:").heading().bold(), intake(calories, protein));
+    }
+
+    Ok(())
+}
+
+/// Average intake on days with a finished session vs. days without one,
+/// over the last `weeks` weeks. Prints nothing without logged days.
+pub async fn print_averages(pool: &SqlitePool, weeks: u32) -> Result<()> {
+    let rows: Vec<(bool, i64, f64, Option<f64>)> = sqlx::query_as(
+        r#"
+        SELECT EXISTS (
+                   SELECT 1 FROM training_sessions ts
+                   WHERE date(ts.start_time) = n.date AND ts.end_time IS NOT NULL
+               ) AS trained,
+               CAST(COUNT(*) AS INTEGER),
+               CAST(AVG(n.calories) AS REAL),
+               CAST(AVG(n.protein) AS REAL)
+        FROM nutrition n
+        WHERE n.date >= date('now', '-' || ? || ' days')
+        GROUP BY trained
+        ORDER BY trained DESC
+        "#,
+    )
+    .bind(weeks * 7)
+    .fetch_all(pool)
+    .await?;
+    if rows.is_empty() {
+        return Ok(());
+    }
+
+    println!();
+    println!("{}", tr("Nutrition:").heading().bold());
+    for (trained, days, calories, protein) in rows {
+        let label = if trained { tr("training days") } else { tr("rest days") };
+        println!(
+            "  {:<14} {} {}",
+            label,
+            intake(calories.round() as i64, protein),
+            tf("({} days)", &[&days]).dimmed()
+        );
+    }
+
+    Ok(())
+}
@@ -0,0 +1,185 @@
+use std::{fs, path::Path};
+
+use anyhow::{Context, Result};
+use chrono::NaiveDate;
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    cli::PhotoCmd,
+    commands::{attach::media_dir, journal::escape_html},
+    dates::{DATE_FORMATS, parse_date},
+    errors::AppError,
+    i18n::{language_tag, long_date, short_date, tf, tr},
+    ui::{self, Themed},
+};
+
+struct Photo {
+    id: String,
+    date: String,
+    tag: String,
+    path: String,
+}
+
+async fn load_photos(pool: &SqlitePool, tag: Option<&str>) -> Result<Vec<Photo>> {
+    let rows: Vec<(String, String, String, String)> = sqlx::query_as(
+        r#"
+        SELECT id, date, tag, path
+        FROM progress_photos
+        WHERE ?1 IS NULL OR tag = ?1
+        ORDER BY date, tag, created_at
+        "#,
+    )
+    .bind(tag)
+    .fetch_all(pool)
+    .await?;
+
+    Ok(rows
+        .into_iter()
+        .map(|(id, date, tag, path)| Photo { id, date, tag, path })
+        .collect())
+}
+
+fn pretty_date(date: &str, f: fn(NaiveDate) -> String) -> String {
+    NaiveDate::parse_from_str(date, "%Y-%m-%d")
+        .map(f)
+        .unwrap_or_else(|_| date.to_string())
+}
+
+/// One page with a column per tag and a row per date, oldest first.
+fn contact_sheet(photos: &[Photo]) -> String {
+    let mut tags: Vec<&str> = Vec::new();
+    let mut dates: Vec<&str> = Vec::new();
+    for p in photos {
+        if !tags.contains(&p.tag.as_str()) {
+            tags.push(&p.tag);
+        }
+        if !dates.contains(&p.date.as_str()) {
+            dates.push(&p.date);
+        }
+    }
+
+    let title = escape_html(tr("Progress photos"));
+    let mut out = format!(
+        r#"<!DOCTYPE html>
+<html lang="{lang}">
+<head>
+<meta charset="utf-8">
+<title>{title}</title>
+<style>
+  body {{ font-family: system-ui, sans-serif; margin: 2rem; color: #222; }}
+  table {{ border-collapse: collapse; }}
+  th, td {{ padding: .5rem; vertical-align: top; }}
+  th {{ text-align: left; }}
+  td.date {{ white-space: nowrap; color: #777; }}
+  img {{ width: 14rem; border-radius: 4px; }}
+</style>
+</head>
+<body>
+<h1>{title}</h1>
+<table>
+<tr><th></th>"#,
+        lang = language_tag(),
+        title = title
+    );
+    for tag in &tags {
+        out += &format!("<th>{}</th>", escape_html(tag));
+    }
+    out += "</tr>\n";
+
+    for date in &dates {
+        out += &format!("<tr><td class=\"date\">{}</td>", escape_html(&pretty_date(date, long_date)));
+        for tag in &tags {
+            out += "<td>";
+            for p in photos.iter().filter(|p| p.date == *date && p.tag == *tag) {
+                out += &format!("<img src=\"file://{0}\" alt=\"{1}\">", escape_html(&p.path), escape_html(&p.tag));
+            }
+            out += "</td>";
+        }
+        out += "</tr>\n";
+    }
+
+    out += "</table>\n</body>\n</html>\n";
+    out
+}
+
+pub async fn handle(cmd: PhotoCmd, pool: &SqlitePool) -> Result<()> {
+    match cmd {
+        PhotoCmd::Add { file, tag, date } => {
+            let file = Path::new(&file);
+            if !file.is_file() {
+                return Err(AppError::NotFound(tf("no file at `{}`", &[&file.display()]).into()).into());
+            }
+            let Some(day) = parse_date(date.as_deref()) else {
+                return Err(AppError::Invalid(tf("invalid date `{}` (expected {})", &[&date.unwrap_or_default(), &DATE_FORMATS]).into()).into());
+            };
+
+            let id = uuid::Uuid::new_v4().to_string();
+            let ext = file
+                .extension()
+                .map(|e| format!(".{}", e.to_string_lossy()))
+                .unwrap_or_default();
+            let dir = media_dir()?.join("photos");
+            fs::create_dir_all(&dir).with_context(|| format!("could not create `{}`", dir.display()))?;
+            let slug: String = tag.chars().map(|c| if c.is_alphanumeric() { c } else { '-' }).collect();
+            let dest = dir.join(format!("{}-{}-{}{}", day.format("%Y-%m-%d"), slug, &id[..8], ext));
+            fs::copy(file, &dest).with_context(|| format!("could not copy `{}`", file.display()))?;
+
+            sqlx::query(
+                "INSERT INTO progress_photos (id, date, tag, path, created_at) VALUES (?, ?, ?, ?, datetime('now'))",
+            )
+            .bind(&id)
+            .bind(day.format("%Y-%m-%d").to_string())
+            .bind(&tag)
+            .bind(dest.to_string_lossy().into_owned())
+            .execute(pool)
+            .await?;
+
+            ui::ok(tf("{} photo for {} saved to {}", &[&tag, &short_date(day), &dest.display()]));
+        }
+
+        PhotoCmd::Timeline { tag, html } => {
+            let photos = load_photos(pool, tag.as_deref()).await?;
+            if photos.is_empty() {
+                ui::info(tr("no progress photos yet, add one with `photo add <file> --tag front`"));
+                return Ok(());
+            }
+
+            if let Some(out) = html {
+                fs::write(&out, contact_sheet(&photos))?;
+                ui::ok(tf("contact sheet with {} photos written to {}", &[&photos.len(), &out]));
+                return Ok(());
+            }
+
+            println!("{}", tr("Progress photos:").heading().bold());
+            for (i, p) in photos.iter().enumerate() {
+                println!(
+                    " {} • {:<12} {:<8} {}",
+                    (i + 1).to_string().accent(),
+                    pretty_date(&p.date, short_date),
+                    p.tag.bold(),
+                    p.path.dimmed()
+                );
+            }
+        }
+
+        PhotoCmd::Remove { photo } => {
+            // Same numbering as an untagged `photo timeline`.
+            let photos = load_photos(pool, None).await?;
+            let Some(p) = photo.checked_sub(1).and_then(|i| photos.get(i)) else {
+                return Err(AppError::NotFound(tf("no photo at index {}", &[&photo]).into()).into());
+            };
+
+            sqlx::query("DELETE FROM progress_photos WHERE id = ?")
+                .bind(&p.id)
+                .execute(pool)
+                .await?;
+            // The copy is ours; a file that's already gone is fine.
+            let _ = fs::remove_file(&p.path);
+
+            ui::ok(tf("removed {} photo from {}", &[&p.tag, &pretty_date(&p.date, short_date)]));
+        }
+    }
+
+    Ok(())
+}
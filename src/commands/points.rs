@@ -0,0 +1,188 @@
+use anyhow::Result;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::standards::{Lift, lift_best},
+    errors::AppError,
+    i18n::{kg, kg_places, number, short_date, tf, tr},
+    types::{OutputFmt, Sex, emit},
+    ui::Themed,
+};
+
+fn poly(coef: &[f64], x: f64) -> f64 {
+    coef.iter().rev().fold(0.0, |acc, c| acc * x + c)
+}
+
+/// Original Wilks formula.
+pub fn wilks(total: f64, bw: f64, sex: Sex) -> f64 {
+    let (coef, bw): (&[f64], f64) = match sex {
+        Sex::Male => (
+            &[-216.0475144, 16.2606339, -0.002388645, -0.00113732, 7.01863e-06, -1.291e-08],
+            bw.clamp(40.0, 201.9),
+        ),
+        Sex::Female => (
+            &[594.31747775582, -27.23842536447, 0.82112226871, -0.00930733913, 4.731582e-05, -9.054e-08],
+            bw.clamp(26.51, 154.53),
+        ),
+    };
+    total * 500.0 / poly(coef, bw)
+}
+
+pub fn dots(total: f64, bw: f64, sex: Sex) -> f64 {
+    let (coef, bw): (&[f64], f64) = match sex {
+        Sex::Male => (
+            &[-307.75076, 24.0900756, -0.1918759221, 0.0007391293, -0.000001093],
+            bw.clamp(40.0, 210.0),
+        ),
+        Sex::Female => (
+            &[-57.96288, 13.6175032, -0.1126655495, 0.0005158568, -0.0000010706],
+            bw.clamp(40.0, 150.0),
+        ),
+    };
+    total * 500.0 / poly(coef, bw)
+}
+
+/// IPF GL points for raw (classic) full powerlifting.
+pub fn ipf_gl(total: f64, bw: f64, sex: Sex) -> f64 {
+    let (a, b, c) = match sex {
+        Sex::Male => (1199.72839, 1025.18162, 0.00921),
+        Sex::Female => (610.32796, 1045.59282, 0.03048),
+    };
+    total * 100.0 / (a - b * (-c * bw).exp())
+}
+
+#[derive(Serialize)]
+struct Points {
+    bodyweight_kg: f64,
+    squat_kg: f64,
+    bench_kg: f64,
+    deadlift_kg: f64,
+    total_kg: f64,
+    wilks: f64,
+    dots: f64,
+    ipf_gl: f64,
+}
+
+/// Snapshots saved by `points` within the last `weeks` weeks, for `status`.
+/// Prints nothing without any.
+pub async fn print_history(pool: &SqlitePool, weeks: u32) -> Result<()> {
+    let rows: Vec<(String, f64, f64, f64, f64)> = sqlx::query_as(
+        r#"
+        SELECT date, bodyweight, total, dots, ipf_gl
+        FROM points_history
+        WHERE date >= date('now', '-' || ? || ' days')
+        ORDER BY date
+        "#,
+    )
+    .bind(weeks * 7)
+    .fetch_all(pool)
+    .await?;
+    if rows.is_empty() {
+        return Ok(());
+    }
+
+    println!();
+    println!("{}", tr("Powerlifting total:").heading().bold());
+    let mut prev: Option<f64> = None;
+    for (date, bw, total, dots, gl) in rows {
+        let delta = match prev {
+            Some(p) if total > p => format!("+{}", kg_places(total - p, 1)).good().to_string(),
+            Some(p) if total < p => kg_places(total - p, 1).bad().to_string(),
+            _ => String::new(),
+        };
+        let date = chrono::NaiveDate::parse_from_str(&date, "%Y-%m-%d")
+            .map(short_date)
+            .unwrap_or(date);
+        println!(
+            "  {:<12} {:>6} kg {:<7} {} {}",
+            date.dimmed(),
+            kg_places(total, 1),
+            delta,
+            tf("DOTS {} · GL {}", &[&number(dots, 1), &number(gl, 1)]),
+            tf("@ {} kg", &[&kg(bw)]).dimmed()
+        );
+        prev = Some(total);
+    }
+
+    Ok(())
+}
+
+/// Bodyweight to count against bodyweight sets: `bodyweight` from config,
+/// else the one of the latest points snapshot.
+pub async fn current_bodyweight(pool: &SqlitePool, configured: Option<f32>) -> Result<Option<f32>> {
+    if configured.is_some() {
+        return Ok(configured);
+    }
+    Ok(sqlx::query_scalar("SELECT CAST(bodyweight AS REAL) FROM points_history ORDER BY date DESC LIMIT 1")
+        .fetch_optional(pool)
+        .await?)
+}
+
+pub async fn handle(pool: &SqlitePool, bw: Option<f32>, sex: Option<Sex>, fmt: OutputFmt) -> Result<()> {
+    let (Some(bw), Some(sex)) = (bw, sex) else {
+        return Err(AppError::Invalid(tr("pass `--bw <kg>` (or set `bodyweight`) and set `sex` in config first").into()).into());
+    };
+
+    let mut bests = Vec::new();
+    for lift in [Lift::Squat, Lift::Bench, Lift::Deadlift] {
+        match lift_best(pool, lift).await? {
+            Some((_, kg)) => bests.push(kg as f64),
+            None => {
+                return Err(AppError::NotFound(tf("no {} logged yet", &[&tr(lift.label()).to_lowercase()]).into()).into());
+            }
+        }
+    }
+
+    let bw = bw as f64;
+    let total: f64 = bests.iter().sum();
+    let p = Points {
+        bodyweight_kg: bw,
+        squat_kg: bests[0],
+        bench_kg: bests[1],
+        deadlift_kg: bests[2],
+        total_kg: total,
+        wilks: wilks(total, bw, sex),
+        dots: dots(total, bw, sex),
+        ipf_gl: ipf_gl(total, bw, sex),
+    };
+
+    // One snapshot per day; re-running replaces it.
+    sqlx::query(
+        r#"
+        INSERT OR REPLACE INTO points_history (date, bodyweight, total, wilks, dots, ipf_gl)
+        VALUES (date('now', 'localtime'), ?, ?, ?, ?, ?)
+        "#,
+    )
+    .bind(p.bodyweight_kg)
+    .bind(p.total_kg)
+    .bind(p.wilks)
+    .bind(p.dots)
+    .bind(p.ipf_gl)
+    .execute(pool)
+    .await?;
+
+    emit(fmt, &p, || {
+        println!(
+            "{} {}",
+            tr("Total:").heading().bold(),
+            tf(
+                "{} kg ({} / {} / {}) at {} kg bodyweight",
+                &[
+                    &kg_places(p.total_kg, 1).bold(),
+                    &kg_places(p.squat_kg, 1),
+                    &kg_places(p.bench_kg, 1),
+                    &kg_places(p.deadlift_kg, 1),
+                    &kg(p.bodyweight_kg),
+                ]
+            )
+        );
+        println!("  {:<8} {:>7.2}", "Wilks", p.wilks);
+        println!("  {:<8} {:>7.2}", "DOTS", p.dots);
+        println!("  {:<8} {:>7.2}", "IPF GL", p.ipf_gl);
+        println!("{}", tr("Totals use current e1RMs, not meet lifts.").dimmed());
+    });
+
+    Ok(())
+}
@@ -0,0 +1,189 @@
+use anyhow::Result;
+use chrono::NaiveDate;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    errors::AppError,
+    formula,
+    i18n::{display_date, kg, kg_places, number, tf, tr},
+    commands::session::flag_markers,
+    types::{OutputFmt, SET_FLAGS, emit, set_flag_sql},
+    ui::{self, Themed},
+};
+
+/// Width of the longest bar.
+const BAR: usize = 30;
+
+#[derive(Serialize)]
+struct Pr {
+    /// YYYY-MM-DD.
+    date: String,
+    #[serde(skip)]
+    day: NaiveDate,
+    weight: f64,
+    reps: i64,
+    e1rm: f64,
+    /// Gear worn, e.g. "belt,straps".
+    flags: Option<String>,
+    /// Gain over the previous record, `None` for the first.
+    delta_kg: Option<f64>,
+    days_since_previous: Option<i64>,
+}
+
+#[derive(Serialize)]
+struct Timeline {
+    exercise: String,
+    /// `with belt` / `without belt` when filtered by gear.
+    gear: Option<String>,
+    prs: Vec<Pr>,
+    /// Average e1RM gained per 30 days from the first record to the last.
+    kg_per_month: Option<f64>,
+}
+
+/// Every set that beat the exercise's best e1RM so far, oldest first.
+fn records(sets: Vec<(String, f64, i64, Option<String>)>) -> Vec<Pr> {
+    let mut prs: Vec<Pr> = Vec::new();
+    for (timestamp, weight, reps, flags) in sets {
+        let e1rm = formula::e1rm(weight, reps as f64);
+        let best = prs.last().map(|p| p.e1rm);
+        if best.is_some_and(|b| e1rm <= b) {
+            continue;
+        }
+        let Ok(date) = NaiveDate::parse_from_str(timestamp.get(..10).unwrap_or_default(), "%Y-%m-%d") else {
+            continue;
+        };
+        let days_since_previous = prs.last().map(|p| (date - p.day).num_days());
+        prs.push(Pr {
+            date: date.to_string(),
+            day: date,
+            weight,
+            reps,
+            e1rm,
+            flags: flags.filter(|f| !f.is_empty()),
+            delta_kg: best.map(|b| e1rm - b),
+            days_since_previous,
+        });
+    }
+    prs
+}
+
+/// `pr-timeline`: every e1RM record of an exercise, optionally only among the
+/// sets done `with` or `without` a piece of gear.
+pub async fn handle(
+    pool: &SqlitePool,
+    exercise: String,
+    with: Option<String>,
+    without: Option<String>,
+    fmt: OutputFmt,
+) -> Result<()> {
+    let gear = match (with, without) {
+        (Some(flag), _) => Some((flag.to_lowercase(), true)),
+        (None, Some(flag)) => Some((flag.to_lowercase(), false)),
+        (None, None) => None,
+    };
+    if let Some((flag, _)) = gear.as_ref().filter(|(f, _)| !SET_FLAGS.contains(&f.as_str())) {
+        return Err(AppError::Invalid(tf("unknown gear `{}` (expected one of {})", &[&flag, &SET_FLAGS.join(", ")])).into());
+    }
+
+    let found: Option<(String, String)> =
+        sqlx::query_as("SELECT id, name FROM exercises WHERE name = ? COLLATE NOCASE OR CAST(idx AS TEXT) = ?")
+            .bind(&exercise)
+            .bind(&exercise)
+            .fetch_optional(pool)
+            .await?;
+    let Some((exercise_id, name)) = found else {
+        return Err(AppError::ExerciseNotFound(exercise).into());
+    };
+
+    let sets: Vec<(String, f64, i64, Option<String>)> = sqlx::query_as(&format!(
+        r#"
+        SELECT es.timestamp, CAST(es.weight AS REAL), es.reps, es.flags
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        WHERE tse.exercise_id = ?
+        AND es.weight > 0 AND es.reps > 0 AND es.bodyweight = 0
+        AND COALESCE(es.ignore_for_one_rm, 0) = 0
+        AND {gear}
+        ORDER BY es.timestamp
+        "#,
+        gear = match &gear {
+            Some((flag, worn)) => set_flag_sql("es.flags", flag, *worn),
+            None => "1".to_string(),
+        }
+    ))
+    .bind(&exercise_id)
+    .fetch_all(pool)
+    .await?;
+
+    let prs = records(sets);
+    if prs.is_empty() {
+        ui::info(tf("no weighted sets logged for `{}` yet", &[&name]));
+        return Ok(());
+    }
+
+    let (first, last) = (&prs[0], &prs[prs.len() - 1]);
+    let span = (last.day - first.day).num_days();
+    let kg_per_month = (span > 0).then(|| (last.e1rm - first.e1rm) / span as f64 * 30.0);
+    let gear = gear.map(|(flag, worn)| if worn { tf("with {}", &[&flag]) } else { tf("without {}", &[&flag]) });
+    let timeline = Timeline { exercise: name, gear, prs, kg_per_month };
+
+    emit(fmt, &timeline, || {
+        println!(
+            "{} {}",
+            tr("PR timeline:").heading().bold(),
+            tf("{} ({} records)", &[&timeline.exercise.bold(), &timeline.prs.len()])
+        );
+        if let Some(gear) = &timeline.gear {
+            println!("{}", tf("  only sets {}", &[gear]).dimmed());
+        }
+
+        // Bars start at the first record so the climb stays visible.
+        let low = timeline.prs[0].e1rm * 0.9;
+        let high = timeline.prs.iter().map(|p| p.e1rm).fold(low, f64::max);
+        for pr in &timeline.prs {
+            let len = if high > low { ((pr.e1rm - low) / (high - low) * BAR as f64).round() as usize } else { BAR };
+            let delta = match pr.delta_kg {
+                Some(d) => format!("{:>8}", format!("+{}", kg_places(d, 1))).good().to_string(),
+                None => " ".repeat(8),
+            };
+            let gap = pr
+                .days_since_previous
+                .map(|d| tf("{}d later", &[&d]))
+                .unwrap_or_default();
+            println!(
+                "  {:<12} {:>9} {:>7} {} {:<width$} {}{}",
+                display_date(pr.day),
+                format!("{}×{}", kg(pr.weight), pr.reps),
+                kg_places(pr.e1rm, 1),
+                delta,
+                "█".repeat(len.max(1)).accent(),
+                gap.dimmed(),
+                pr.flags.as_deref().map(|f| format!(" {}", flag_markers(f))).unwrap_or_default(),
+                width = BAR
+            );
+        }
+
+        let first = &timeline.prs[0];
+        let last = &timeline.prs[timeline.prs.len() - 1];
+        println!();
+        print!(
+            "{}",
+            tf("{} → {} kg e1RM", &[&kg_places(first.e1rm, 1), &kg_places(last.e1rm, 1)])
+        );
+        if let Some(rate) = timeline.kg_per_month {
+            let gaps: Vec<i64> = timeline.prs.iter().filter_map(|p| p.days_since_previous).collect();
+            let avg_gap = gaps.iter().sum::<i64>() as f64 / gaps.len() as f64;
+            print!(
+                ", {}",
+                tf("{} kg/month, a PR every {} days on average", &[&format!("{}{}", if rate >= 0.0 { "+" } else { "" }, kg_places(rate, 1)), &number(avg_gap, 0)])
+            );
+        }
+        println!();
+        let since_last = (chrono::Local::now().date_naive() - last.day).num_days();
+        println!("{}", tf("last PR {} days ago", &[&since_last]).dimmed());
+    });
+
+    Ok(())
+}
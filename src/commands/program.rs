@@ -1,44 +1,286 @@
 use std::{
-    collections::{HashMap, HashSet},
+    collections::{BTreeMap, BTreeSet, HashMap, HashSet},
     fs::read_to_string,
+    ops::RangeInclusive,
 };
 
 use anyhow::Result;
 use colored::Colorize;
-use serde::Deserialize;
+use serde::{Deserialize, Serialize};
 use sqlx::{Row, SqlitePool};
 
 use crate::{
     cli::ProgramCmd,
-    types::{OutputFmt, emit},
+    errors::AppError,
+    i18n::{kg, tf, tr},
+    storage::ProgramStore,
+    types::{OutputFmt, RepTarget, emit},
+    ui::{self, Themed},
 };
 
-#[derive(Debug, Deserialize)]
+#[derive(Debug, Deserialize, Serialize)]
 #[serde(deny_unknown_fields)]
 struct ProgramToml {
     name: String,
     description: Option<String>,
+    /// Planned off/vacation weeks of the macrocycle; they don't break the week streak.
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    off_weeks: Vec<u32>,
+    /// Interchangeable exercises across the whole program, offered by `session swap`.
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    substitutions: BTreeMap<String, Vec<String>>,
     blocks: Vec<BlockToml>,
 }
 
-#[derive(Debug, Deserialize)]
+#[derive(Debug, Deserialize, Serialize)]
 struct BlockToml {
     name: String,
     description: Option<String>,
+    /// Expected session length in minutes.
+    duration: Option<u32>,
+    /// Week of the macrocycle; blocks without one repeat every week.
+    week: Option<u32>,
     exercises: Vec<BlockExerciseToml>,
+    /// Accessories to pick from at `session start`, after the fixed exercises.
+    accessories: Option<AccessoryPoolToml>,
+    /// EMOMs and circuits, run with `session circuit`.
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    circuits: Vec<CircuitToml>,
 }
 
-#[derive(Debug, Deserialize)]
+/// `[[blocks.circuits]]`: `kind = "emom"` ticks a round every `interval`
+/// seconds (60 by default); `kind = "circuit"` rests `interval` between rounds.
+#[derive(Debug, Deserialize, Serialize)]
+struct CircuitToml {
+    kind: String,
+    rounds: u32,
+    interval: Option<u32>,
+    exercises: Vec<MovementToml>,
+}
+
+/// `{ name = "Kettlebell Swing", reps = 15, weight = 24 }`; no weight means bodyweight.
+#[derive(Debug, Deserialize, Serialize)]
+struct MovementToml {
+    name: String,
+    reps: u32,
+    weight: Option<f64>,
+}
+
+impl CircuitToml {
+    fn validate(&self) -> std::result::Result<(), String> {
+        if self.kind != "emom" && self.kind != "circuit" {
+            return Err(tf("unknown circuit kind `{}` (expected emom or circuit)", &[&self.kind]));
+        }
+        if self.rounds == 0 || self.exercises.is_empty() {
+            return Err(tr("a circuit needs at least one round and one exercise").into());
+        }
+        if self.kind == "emom" && self.interval == Some(0) {
+            return Err(tr("an EMOM interval must be at least 1 second").into());
+        }
+        Ok(())
+    }
+}
+
+/// `accessories = { choose = 2, exercises = [...] }`
+#[derive(Debug, Deserialize, Serialize)]
+struct AccessoryPoolToml {
+    choose: u32,
+    exercises: Vec<BlockExerciseToml>,
+}
+
+/// `percent_of_top` is one fraction for every set or one per set.
+#[derive(Debug, Deserialize, Serialize)]
+#[serde(untagged)]
+enum Percents {
+    One(f64),
+    Many(Vec<f64>),
+}
+
+/// `backoff = { percent_of_top = 0.9, sets = 3, reps = "5" }`
+#[derive(Debug, Deserialize, Serialize)]
+struct SetGroupToml {
+    percent_of_top: Percents,
+    sets: Option<u32>,
+    reps: Option<String>,
+}
+
+/// `group = "A"`, or the older `group = 1` (read as "A").
+#[derive(Debug, Deserialize, Serialize)]
+#[serde(untagged)]
+enum GroupToml {
+    Num(u32),
+    Name(String),
+}
+
+impl GroupToml {
+    fn name(&self) -> Option<String> {
+        match self {
+            GroupToml::Num(n @ 1..=26) => Some(char::from(b'A' + (*n as u8 - 1)).to_string()),
+            GroupToml::Num(_) => None,
+            GroupToml::Name(s) => {
+                let s = s.trim().to_uppercase();
+                let mut chars = s.chars();
+                let ok = chars.next().is_some_and(|c| c.is_ascii_alphabetic())
+                    && chars.all(|c| c.is_ascii_alphanumeric())
+                    && s.len() <= 3;
+                ok.then_some(s)
+            }
+        }
+    }
+}
+
+/// Check a block's superset groups: valid names, at least two members each,
+/// and members listed one after another. Returns each exercise's group.
+fn validate_groups(exercises: &[BlockExerciseToml]) -> std::result::Result<Vec<Option<String>>, String> {
+    let mut groups = Vec::with_capacity(exercises.len());
+    for ex in exercises {
+        match &ex.group {
+            None => groups.push(None),
+            Some(g) => match g.name() {
+                Some(name) => groups.push(Some(name)),
+                None => return Err(tf("invalid group for `{}` (expected a name like \"A\")", &[&ex.name])),
+            },
+        }
+    }
+
+    let mut closed = HashSet::new();
+    for (i, g) in groups.iter().enumerate() {
+        let Some(g) = g else { continue };
+        let prev = i.checked_sub(1).and_then(|p| groups[p].as_ref());
+        if prev != Some(g) && !closed.insert(g.clone()) {
+            return Err(tf("members of group `{}` must be consecutive", &[&g]));
+        }
+    }
+    for g in &closed {
+        if groups.iter().filter(|x| x.as_ref() == Some(g)).count() < 2 {
+            return Err(tf("group `{}` needs at least two exercises", &[&g]));
+        }
+    }
+
+    Ok(groups)
+}
+
+/// "5-8" or "3".
+fn parse_weeks(s: &str) -> Option<RangeInclusive<u32>> {
+    let (lo, hi) = s.split_once('-').unwrap_or((s, s));
+    let (lo, hi): (u32, u32) = (lo.trim().parse().ok()?, hi.trim().parse().ok()?);
+    (lo >= 1 && lo <= hi).then_some(lo..=hi)
+}
+
+fn format_weeks(weeks: &RangeInclusive<u32>) -> String {
+    if weeks.start() == weeks.end() {
+        weeks.start().to_string()
+    } else {
+        format!("{}-{}", weeks.start(), weeks.end())
+    }
+}
+
+fn parse_rep_target(reps: &str, exercise: &str) -> std::result::Result<RepTarget, String> {
+    RepTarget::parse(reps).ok_or_else(|| {
+        tf(
+            "invalid rep target `{}` for `{}` (expected e.g. 5, 8-12, 5+, 3x3 cluster or 30s)",
+            &[&reps.trim(), &exercise],
+        )
+    })
+}
+
+/// An exercise's rep targets in canonical form ("8 - 12" → "8-12"), with
+/// `amrap` applied to the last set.
+fn rep_targets(ex: &BlockExerciseToml) -> std::result::Result<Option<String>, String> {
+    let Some(reps) = &ex.reps else { return Ok(None) };
+    let mut targets = reps
+        .iter()
+        .map(|r| parse_rep_target(r, &ex.name))
+        .collect::<std::result::Result<Vec<_>, _>>()?;
+    if ex.amrap {
+        if let Some(last) = targets.last_mut() {
+            *last = last.amrap();
+        }
+    }
+    Ok(Some(targets.iter().map(RepTarget::to_string).collect::<Vec<_>>().join(",")))
+}
+
+/// "A1", "A2", ... for grouped exercises, in the order given.
+pub fn superset_labels(groups: &[Option<String>]) -> Vec<Option<String>> {
+    let mut seen: HashMap<&str, usize> = HashMap::new();
+    groups
+        .iter()
+        .map(|g| {
+            let g = g.as_deref()?;
+            let n = seen.entry(g).or_default();
+            *n += 1;
+            Some(format!("{}{}", g, n))
+        })
+        .collect()
+}
+
+impl SetGroupToml {
+    /// Stored as "0.9:5,0.9:5,0.9:5".
+    fn to_csv(&self) -> String {
+        let percents = match &self.percent_of_top {
+            Percents::One(p) => vec![*p; self.sets.unwrap_or(1) as usize],
+            Percents::Many(ps) => ps.clone(),
+        };
+        let reps = self.reps.as_deref().unwrap_or_default();
+        percents
+            .iter()
+            .map(|p| format!("{}:{}", p, reps))
+            .collect::<Vec<_>>()
+            .join(",")
+    }
+
+    /// Back from the stored "0.9:5,0.9:5,0.9:5".
+    fn from_csv(csv: &str) -> Option<Self> {
+        let sets: Vec<(f64, &str)> = csv
+            .split(',')
+            .map(|s| {
+                let (p, r) = s.split_once(':')?;
+                Some((p.trim().parse().ok()?, r.trim()))
+            })
+            .collect::<Option<_>>()?;
+        let &(first, reps) = sets.first()?;
+        let percent_of_top = if sets.iter().all(|(p, _)| *p == first) {
+            Percents::One(first)
+        } else {
+            Percents::Many(sets.iter().map(|(p, _)| *p).collect())
+        };
+        Some(SetGroupToml {
+            percent_of_top,
+            sets: Some(sets.len() as u32),
+            reps: (!reps.is_empty()).then(|| reps.to_string()),
+        })
+    }
+}
+
+#[derive(Debug, Deserialize, Serialize)]
 struct BlockExerciseToml {
     name: String,
     sets: u32,
     reps: Option<Vec<String>>,
-    target_rpe: Option<Vec<f32>>,
-    target_rm_percent: Option<Vec<f32>>,
+    target_rpe: Option<Vec<f64>>,
+    target_rm_percent: Option<Vec<f64>>,
     notes: Option<String>,
-    program_1rm: Option<f32>,
+    program_1rm: Option<f64>,
     technique: Option<String>,
-    group: Option<u32>,
+    /// Superset this exercise belongs to, e.g. "A"; members must be consecutive.
+    group: Option<GroupToml>,
+    /// Eccentric-pause-concentric-pause, e.g. "3-1-1-0".
+    tempo: Option<String>,
+    /// Pause per set, e.g. ["", "2s", "2s"]; bare numbers are seconds.
+    pause: Option<Vec<String>>,
+    /// Rest between sets in seconds, checked by `analyze-rest`.
+    rest: Option<u32>,
+    /// Alternatives to swap in, e.g. when the gym lacks the equipment.
+    options: Option<Vec<String>>,
+    /// Cycle through the exercise and its `options`, one every this many weeks.
+    rotate: Option<u32>,
+    /// Warm-up sets as fractions of the top set.
+    warmup: Option<SetGroupToml>,
+    /// Back-off sets as fractions of the top set.
+    backoff: Option<SetGroupToml>,
+    /// Make the last set AMRAP (same as writing "5+" as its reps).
+    #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+    amrap: bool,
 }
 
 #[derive(Debug)]
@@ -52,6 +294,8 @@ struct ProgJson {
     name: String,
     description: String,
     created_at: String,
+    active: bool,
+    archived_at: Option<String>,
     blocks: i64,
 }
 
@@ -84,6 +328,577 @@ async fn blocks_by_program(pool: &SqlitePool) -> Result<HashMap<String, Vec<Bloc
     Ok(map)
 }
 
+/// Resolve a program index (from `p list`) or exact name to its id.
+/// Fails with [`AppError::ProgramNotFound`] when nothing matches.
+pub async fn resolve_program(store: &impl ProgramStore, program: &str) -> Result<String> {
+    store
+        .program_id(program)
+        .await?
+        .ok_or_else(|| AppError::ProgramNotFound(program.to_string()).into())
+}
+
+/// Resolve a block index (ordered by name, as in `p show`) or name inside a program.
+pub async fn resolve_block(pool: &SqlitePool, prog_id: &str, block: &str) -> Result<String> {
+    let id = if let Ok(idx) = block.parse::<i64>() {
+        sqlx::query_scalar(
+            r#"
+            SELECT id
+            FROM (
+              SELECT id, ROW_NUMBER() OVER (ORDER BY name) AS rn
+              FROM program_blocks
+              WHERE program_id = ?
+            ) t
+            WHERE t.rn = ?
+            "#,
+        )
+        .bind(prog_id)
+        .bind(idx)
+        .fetch_optional(pool)
+        .await?
+    } else {
+        sqlx::query_scalar("SELECT id FROM program_blocks WHERE program_id = ? AND name = ?")
+            .bind(prog_id)
+            .bind(block)
+            .fetch_optional(pool)
+            .await?
+    };
+
+    id.ok_or_else(|| AppError::NotFound(tf("no block `{}` in this program", &[&block])).into())
+}
+
+/// Program exercises of a block, in display order: (program_exercise id, exercise name).
+async fn block_exercises(pool: &SqlitePool, block_id: &str) -> Result<Vec<(String, String)>> {
+    let rows = sqlx::query_as::<_, (String, String)>(
+        r#"
+        SELECT pe.id, e.name
+        FROM program_exercises pe
+        JOIN exercises e ON e.id = pe.exercise_id
+        WHERE pe.program_block_id = ?
+        ORDER BY pe.order_index
+        "#,
+    )
+    .bind(block_id)
+    .fetch_all(pool)
+    .await?;
+
+    Ok(rows)
+}
+
+/// Find an exercise inside a block by 1-based position or name.
+fn find_in_block(exs: &[(String, String)], exercise: &str) -> Option<usize> {
+    if let Ok(pos) = exercise.parse::<usize>() {
+        (pos >= 1 && pos <= exs.len()).then(|| pos - 1)
+    } else {
+        exs.iter().position(|(_, name)| name.eq_ignore_ascii_case(exercise))
+    }
+}
+
+/// Rewrite `order_index` so it matches the order of `ids`.
+async fn write_block_order(pool: &SqlitePool, ids: &[String]) -> Result<()> {
+    let mut tx = pool.begin().await?;
+    for (i, id) in ids.iter().enumerate() {
+        sqlx::query("UPDATE program_exercises SET order_index = ? WHERE id = ?")
+            .bind(i as i32)
+            .bind(id)
+            .execute(&mut *tx)
+            .await?;
+    }
+    tx.commit().await?;
+
+    Ok(())
+}
+
+/// Short label for a lift in the matrix: long names become initials ("High Bar Squat" -> "HBS").
+fn lift_label(name: &str) -> String {
+    if name.chars().count() <= 10 {
+        name.to_string()
+    } else {
+        name.split_whitespace()
+            .filter_map(|w| w.chars().next())
+            .collect::<String>()
+            .to_uppercase()
+    }
+}
+
+/// One matrix cell: the block's first (main) lift with its prescription,
+/// plus the block's total working sets, e.g. "Bench 5×5 @80% · 17s".
+fn matrix_cell(
+    name: &str,
+    sets: i32,
+    reps: Option<&str>,
+    rpe: Option<&str>,
+    rm: Option<&str>,
+    total_sets: i64,
+) -> String {
+    let reps = reps
+        .and_then(|r| {
+            let mut parts = r.split(',').map(str::trim);
+            let first = parts.next()?;
+            Some(if parts.all(|p| p == first) { first.to_string() } else { "var".to_string() })
+        })
+        .unwrap_or_else(|| "?".to_string());
+
+    let nums = |csv: Option<&str>| -> Vec<f32> {
+        csv.map(|c| c.split(',').filter_map(|v| v.trim().parse().ok()).collect())
+            .unwrap_or_default()
+    };
+    let rms = nums(rm);
+    let rpes = nums(rpe);
+    let intensity = if !rms.is_empty() {
+        format!(" @{:.0}%", rms.iter().cloned().fold(f32::MIN, f32::max))
+    } else if !rpes.is_empty() {
+        format!(" @{}", rpes.iter().cloned().fold(f32::MIN, f32::max))
+    } else {
+        String::new()
+    };
+
+    format!("{} {}×{}{} · {}s", lift_label(name), sets, reps, intensity, total_sets)
+}
+
+/// Week × day grid of a program. Days are the blocks of a week in name
+/// order; blocks without a week are treated as week 1.
+async fn print_matrix(pool: &SqlitePool, prog_id: &str) -> Result<()> {
+    let rows = sqlx::query_as::<_, (i32, String, Option<String>, i32, Option<String>, Option<String>, Option<String>, i64)>(
+        r#"
+        SELECT
+            COALESCE(pb.week, 1) AS week,
+            pb.name,
+            e.name,
+            COALESCE(pe.sets, 0),
+            pe.reps,
+            pe.target_rpe,
+            pe.target_rm_percent,
+            (SELECT COALESCE(SUM(sets), 0) FROM program_exercises WHERE program_block_id = pb.id)
+        FROM program_blocks pb
+        LEFT JOIN program_exercises pe
+          ON pe.program_block_id = pb.id
+         AND pe.order_index = (SELECT MIN(order_index) FROM program_exercises WHERE program_block_id = pb.id)
+        LEFT JOIN exercises e ON e.id = pe.exercise_id
+        WHERE pb.program_id = ?
+        ORDER BY week, pb.name
+        "#,
+    )
+    .bind(prog_id)
+    .fetch_all(pool)
+    .await?;
+
+    if rows.is_empty() {
+        println!("{} {}", tr("warning:").accent().bold(), tr("no blocks defined"));
+        return Ok(());
+    }
+
+    // week -> cells in day order
+    let mut weeks: Vec<(i32, Vec<String>)> = Vec::new();
+    for (week, _block, ex, sets, reps, rpe, rm, total) in &rows {
+        let cell = match ex {
+            Some(name) => matrix_cell(name, *sets, reps.as_deref(), rpe.as_deref(), rm.as_deref(), *total),
+            None => "rest".to_string(),
+        };
+        match weeks.last_mut() {
+            Some((w, cells)) if *w == *week => cells.push(cell),
+            _ => weeks.push((*week, vec![cell])),
+        }
+    }
+
+    let days = weeks.iter().map(|(_, c)| c.len()).max().unwrap_or(0);
+    let widths: Vec<usize> = (0..days)
+        .map(|d| {
+            weeks
+                .iter()
+                .filter_map(|(_, c)| c.get(d))
+                .map(|c| c.chars().count())
+                .max()
+                .unwrap_or(0)
+                .max(format!("Day {}", d + 1).len())
+        })
+        .collect();
+
+    let header = (0..days)
+        .map(|d| format!("{:<w$}", format!("Day {}", d + 1), w = widths[d]))
+        .collect::<Vec<_>>()
+        .join(" │ ");
+    println!("{}", tr("Periodization:").heading().bold());
+    println!("     {}", header.bold());
+
+    for (week, cells) in &weeks {
+        let line = (0..days)
+            .map(|d| format!("{:<w$}", cells.get(d).map(String::as_str).unwrap_or("—"), w = widths[d]))
+            .collect::<Vec<_>>()
+            .join(" │ ");
+        println!(" {} {}", format!("W{:<2}", week).accent(), line);
+    }
+
+    Ok(())
+}
+
+/// An exercise's prescription in one week of a block.
+struct WeekTarget {
+    exercise: String,
+    sets: i32,
+    reps: Option<String>,
+    rpe: Option<f32>,
+    rm: Option<f32>,
+}
+
+impl WeekTarget {
+    /// Planned reps over all sets, counting the low end of ranges; `None`
+    /// when any target is timed or unreadable.
+    fn total_reps(&self) -> Option<i64> {
+        let csv = self.reps.as_deref()?;
+        let targets: Vec<u32> = csv
+            .split(',')
+            .map(|r| RepTarget::parse(r).and_then(RepTarget::min_reps))
+            .collect::<Option<_>>()?;
+        Some(match targets.as_slice() {
+            [one] => *one as i64 * self.sets as i64,
+            many => many.iter().map(|&r| r as i64).sum(),
+        })
+    }
+
+    /// "5×5 @80%", "3×8-12 @8", "4×var".
+    fn label(&self) -> String {
+        let reps = self
+            .reps
+            .as_deref()
+            .map(|r| {
+                let mut parts = r.split(',').map(str::trim);
+                let first = parts.next().unwrap_or("?");
+                if parts.all(|p| p == first) { first.to_string() } else { "var".to_string() }
+            })
+            .unwrap_or_else(|| "?".to_string());
+        let intensity = match (self.rm, self.rpe) {
+            (Some(rm), _) => format!(" @{:.0}%", rm),
+            (None, Some(rpe)) => format!(" @{}", rpe),
+            _ => String::new(),
+        };
+        format!("{}×{}{}", self.sets, reps, intensity)
+    }
+}
+
+/// Blocks of `week` by name, each with its exercises in order. A block
+/// without a week stands in when the week has no block of that name.
+async fn week_targets(pool: &SqlitePool, prog_id: &str, week: u32) -> Result<BTreeMap<String, Vec<WeekTarget>>> {
+    let rows = sqlx::query_as::<_, (String, String, i32, Option<String>, Option<String>, Option<String>)>(
+        r#"
+        SELECT pb.name, e.name, COALESCE(pe.sets, 0), pe.reps, pe.target_rpe, pe.target_rm_percent
+        FROM program_blocks pb
+        JOIN program_exercises pe ON pe.program_block_id = pb.id
+        JOIN exercises e ON e.id = pe.exercise_id
+        WHERE pb.program_id = ?1
+        AND (pb.week = ?2 OR (pb.week IS NULL AND NOT EXISTS (
+            SELECT 1 FROM program_blocks o
+            WHERE o.program_id = pb.program_id AND o.name = pb.name AND o.week = ?2
+        )))
+        ORDER BY pb.name, pe.order_index
+        "#,
+    )
+    .bind(prog_id)
+    .bind(week as i32)
+    .fetch_all(pool)
+    .await?;
+
+    let top = |csv: Option<String>| -> Option<f32> {
+        csv.and_then(|c| c.split(',').filter_map(|v| v.trim().parse::<f32>().ok()).reduce(f32::max))
+    };
+    let mut blocks: BTreeMap<String, Vec<WeekTarget>> = BTreeMap::new();
+    for (block, exercise, sets, reps, rpe, rm) in rows {
+        blocks.entry(block).or_default().push(WeekTarget { exercise, sets, reps, rpe: top(rpe), rm: top(rm) });
+    }
+    Ok(blocks)
+}
+
+/// "+1 set", "-5%": green when the target went up, red when it went down.
+fn delta(diff: f64, unit: &str) -> Option<String> {
+    let text = format!("{:+}{}", (diff * 10.0).round() / 10.0, unit);
+    if diff > 0.0 {
+        Some(text.good().to_string())
+    } else if diff < 0.0 {
+        Some(text.bad().to_string())
+    } else {
+        None
+    }
+}
+
+/// Every block of week `a` next to the same block in week `b`, with the
+/// changes in sets, planned reps and intensity. Blocks where nothing goes
+/// up are flagged, since the point is to check the program progresses.
+async fn print_week_compare(pool: &SqlitePool, prog_id: &str, a: u32, b: u32) -> Result<()> {
+    for week in [a, b] {
+        let has_week: bool = sqlx::query_scalar("SELECT EXISTS (SELECT 1 FROM program_blocks WHERE program_id = ? AND week = ?)")
+            .bind(prog_id)
+            .bind(week as i32)
+            .fetch_one(pool)
+            .await?;
+        if !has_week {
+            return Err(AppError::Invalid(tf("the program has no blocks for week {}", &[&week])).into());
+        }
+    }
+
+    let (mut from, mut to) = (week_targets(pool, prog_id, a).await?, week_targets(pool, prog_id, b).await?);
+    let names: Vec<String> = from.keys().chain(to.keys()).cloned().collect::<BTreeSet<_>>().into_iter().collect();
+    let name_w = from
+        .values()
+        .chain(to.values())
+        .flatten()
+        .map(|t| t.exercise.chars().count())
+        .max()
+        .unwrap_or(0)
+        .max(10);
+
+    println!("{} {}", tr("Week comparison:").heading().bold(), tf("W{} → W{}", &[&a, &b]));
+    let mut flat = 0;
+    for name in &names {
+        let before = from.remove(name).unwrap_or_default();
+        let after = to.remove(name).unwrap_or_default();
+        println!();
+        println!("{}", name.bold());
+
+        let mut progressed = false;
+        // Exercises in week `b` order, then the ones dropped since week `a`.
+        for t in &after {
+            let Some(old) = before.iter().find(|o| o.exercise == t.exercise) else {
+                println!("  {:<name_w$} {:>12} → {:<12} {}", t.exercise, "—", t.label(), tr("new").good(), name_w = name_w);
+                progressed = true;
+                continue;
+            };
+
+            let mut changes = Vec::new();
+            changes.extend(delta((t.sets - old.sets) as f64, &format!(" {}", tr("sets"))));
+            if let (Some(x), Some(y)) = (old.total_reps(), t.total_reps()) {
+                changes.extend(delta((y - x) as f64, &format!(" {}", tr("reps"))));
+            }
+            if let (Some(x), Some(y)) = (old.rm, t.rm) {
+                changes.extend(delta((y - x) as f64, "%"));
+            }
+            if let (Some(x), Some(y)) = (old.rpe, t.rpe) {
+                changes.extend(delta((y - x) as f64, " RPE"));
+            }
+            let up = t.sets > old.sets
+                || old.total_reps().zip(t.total_reps()).is_some_and(|(x, y)| y > x)
+                || old.rm.zip(t.rm).is_some_and(|(x, y)| y > x)
+                || old.rpe.zip(t.rpe).is_some_and(|(x, y)| y > x);
+            progressed |= up;
+
+            let (old_label, new_label) = (old.label(), format!("{:<12}", t.label()));
+            let new_label = if old_label == new_label.trim_end() {
+                new_label.dimmed().to_string()
+            } else {
+                new_label.highlight().to_string()
+            };
+            println!(
+                "  {:<name_w$} {:>12} → {} {}",
+                t.exercise,
+                old_label,
+                new_label,
+                changes.join(", "),
+                name_w = name_w
+            );
+        }
+        for old in before.iter().filter(|o| !after.iter().any(|t| t.exercise == o.exercise)) {
+            println!("  {:<name_w$} {:>12} → {:<12} {}", old.exercise, old.label(), "—", tr("dropped").dimmed(), name_w = name_w);
+        }
+
+        if after.is_empty() {
+            println!("  {}", tf("not in week {}", &[&b]).dimmed());
+        } else if before.is_empty() {
+            println!("  {}", tf("not in week {}", &[&a]).dimmed());
+        } else if !progressed {
+            flat += 1;
+            println!("  {}", tr("no load or volume increase").accent());
+        }
+    }
+
+    if flat > 0 {
+        println!();
+        println!("{}", tf("{} of {} blocks don't progress from week {} to {}", &[&flat, &names.len(), &a, &b]).accent());
+    }
+
+    Ok(())
+}
+
+/// Substitution groups of a program, with how often each member was swapped in.
+async fn print_substitutions(pool: &SqlitePool, prog_id: &str) -> Result<()> {
+    let groups = substitution_groups(pool, prog_id).await?;
+    if groups.is_empty() {
+        return Ok(());
+    }
+
+    println!("{}", tr("Substitutions:").heading().bold());
+    for (group, exs) in groups {
+        let mut members = Vec::with_capacity(exs.len());
+        for ex in exs {
+            let swaps = swap_count(pool, prog_id, &ex).await?;
+            members.push(if swaps > 0 {
+                format!("{} {}", ex, format!("({}×)", swaps).dimmed())
+            } else {
+                ex
+            });
+        }
+        println!("  {} {}", format!("{}:", group).bold(), members.join(", "));
+    }
+
+    Ok(())
+}
+
+/// Program-wide substitution groups as (group, exercise names).
+pub async fn substitution_groups(pool: &SqlitePool, prog_id: &str) -> Result<Vec<(String, Vec<String>)>> {
+    let rows: Vec<(String, String)> = sqlx::query_as(
+        "SELECT group_name, exercises FROM program_substitutions WHERE program_id = ? ORDER BY group_name",
+    )
+    .bind(prog_id)
+    .fetch_all(pool)
+    .await?;
+
+    Ok(rows
+        .into_iter()
+        .map(|(g, csv)| (g, csv.split(',').map(|e| e.trim().to_string()).collect()))
+        .collect())
+}
+
+/// Times `exercise` was swapped in by hand during the program's sessions.
+pub async fn swap_count(pool: &SqlitePool, prog_id: &str, exercise: &str) -> Result<i64> {
+    Ok(sqlx::query_scalar(
+        r#"
+        SELECT COUNT(*)
+        FROM training_session_exercises tse
+        JOIN training_sessions ts ON ts.id = tse.training_session_id
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        JOIN exercises e ON e.id = tse.exercise_id
+        WHERE pb.program_id = ? AND tse.swapped = 1 AND e.name = ?
+        "#,
+    )
+    .bind(prog_id)
+    .bind(exercise)
+    .fetch_one(pool)
+    .await?)
+}
+
+/// A program written back in the format `p import` reads.
+pub async fn program_toml(pool: &SqlitePool, prog_id: &str) -> Result<String> {
+    let list = |csv: Option<String>| {
+        csv.filter(|c| !c.is_empty())
+            .map(|c| c.split(',').map(|s| s.trim().to_string()).collect::<Vec<_>>())
+    };
+    let nums = |csv: Option<String>| {
+        csv.filter(|c| !c.is_empty())
+            .and_then(|c| c.split(',').map(|s| s.trim().parse::<f64>().ok()).collect::<Option<Vec<_>>>())
+    };
+
+    let (name, description, off_weeks): (String, Option<String>, Option<String>) =
+        sqlx::query_as("SELECT name, description, off_weeks FROM programs WHERE id = ?")
+            .bind(prog_id)
+            .fetch_one(pool)
+            .await?;
+
+    let mut blocks = Vec::new();
+    let block_rows = sqlx::query(
+        "SELECT id, name, description, expected_minutes, week, pool_choose FROM program_blocks WHERE program_id = ? ORDER BY COALESCE(week, 0), rowid",
+    )
+    .bind(prog_id)
+    .fetch_all(pool)
+    .await?;
+    for b in block_rows {
+        let block_id: String = b.get("id");
+        let (mut exercises, mut pooled) = (Vec::new(), Vec::new());
+        let rows = sqlx::query(
+            r#"
+            SELECT e.name, pe.sets, pe.reps, pe.target_rpe, pe.target_rm_percent, pe.notes, pe.program_1rm,
+                   pe.technique, pe.superset, pe.tempo, pe.pause, pe.rest_secs, pe.options, pe.rotate_weeks,
+                   pe.warmup, pe.backoff, COALESCE(pe.pool, 0) AS pool
+            FROM program_exercises pe
+            JOIN exercises e ON e.id = pe.exercise_id
+            WHERE pe.program_block_id = ?
+            ORDER BY pe.order_index
+            "#,
+        )
+        .bind(&block_id)
+        .fetch_all(pool)
+        .await?;
+        for r in rows {
+            let ex = BlockExerciseToml {
+                name: r.get("name"),
+                sets: r.get::<i32, _>("sets").max(0) as u32,
+                reps: list(r.get("reps")),
+                target_rpe: nums(r.get("target_rpe")),
+                target_rm_percent: nums(r.get("target_rm_percent")),
+                notes: r.get("notes"),
+                program_1rm: r.get("program_1rm"),
+                technique: r.get("technique"),
+                group: r.get::<Option<String>, _>("superset").map(GroupToml::Name),
+                tempo: r.get("tempo"),
+                pause: list(r.get("pause")),
+                rest: r.get::<Option<i32>, _>("rest_secs").map(|s| s.max(0) as u32),
+                options: list(r.get("options")),
+                rotate: r.get::<Option<i32>, _>("rotate_weeks").map(|w| w.max(0) as u32),
+                warmup: r.get::<Option<String>, _>("warmup").as_deref().and_then(SetGroupToml::from_csv),
+                backoff: r.get::<Option<String>, _>("backoff").as_deref().and_then(SetGroupToml::from_csv),
+                amrap: false,
+            };
+            if r.get::<bool, _>("pool") {
+                pooled.push(ex);
+            } else {
+                exercises.push(ex);
+            }
+        }
+
+        let mut circuits = Vec::new();
+        let circuit_rows: Vec<(String, String, i32, Option<i32>)> = sqlx::query_as(
+            "SELECT id, kind, rounds, interval_secs FROM program_circuits WHERE program_block_id = ? ORDER BY position",
+        )
+        .bind(&block_id)
+        .fetch_all(pool)
+        .await?;
+        for (cid, kind, rounds, interval) in circuit_rows {
+            let movements: Vec<(String, i32, Option<f64>)> = sqlx::query_as(
+                r#"
+                SELECT e.name, m.reps, m.weight
+                FROM program_circuit_movements m
+                JOIN exercises e ON e.id = m.exercise_id
+                WHERE m.circuit_id = ?
+                ORDER BY m.position
+                "#,
+            )
+            .bind(&cid)
+            .fetch_all(pool)
+            .await?;
+            circuits.push(CircuitToml {
+                kind,
+                rounds: rounds.max(0) as u32,
+                interval: interval.map(|s| s.max(0) as u32),
+                exercises: movements
+                    .into_iter()
+                    .map(|(name, reps, weight)| MovementToml { name, reps: reps.max(0) as u32, weight })
+                    .collect(),
+            });
+        }
+
+        let choose: Option<i32> = b.get("pool_choose");
+        blocks.push(BlockToml {
+            name: b.get("name"),
+            description: b.get("description"),
+            duration: b.get::<Option<i32>, _>("expected_minutes").map(|m| m.max(0) as u32),
+            week: b.get::<Option<i32>, _>("week").map(|w| w.max(0) as u32),
+            exercises,
+            accessories: (!pooled.is_empty()).then(|| AccessoryPoolToml {
+                choose: choose.unwrap_or(1).max(0) as u32,
+                exercises: pooled,
+            }),
+            circuits,
+        });
+    }
+
+    let program = ProgramToml {
+        name,
+        description: description.filter(|d| !d.is_empty()),
+        off_weeks: off_weeks
+            .map(|w| w.split(',').filter_map(|n| n.trim().parse().ok()).collect())
+            .unwrap_or_default(),
+        substitutions: substitution_groups(pool, prog_id).await?.into_iter().collect(),
+        blocks,
+    };
+    Ok(toml::to_string(&program)?)
+}
+
 fn pretty_print(
     progs: &[ProgJson],
     blk_map: &HashMap<String, Vec<BlockRow>>,
@@ -94,13 +909,33 @@ fn pretty_print(
         return;
     }
 
-    println!("{}", "Programs:".cyan().bold());
-
     let idx_w = progs
         .iter()
         .map(|p| p.idx.to_string().len())
         .max()
         .unwrap_or(1);
+    let (active, past): (Vec<&ProgJson>, Vec<&ProgJson>) = progs.iter().partition(|p| p.active);
+
+    println!("{}", tr("Programs:").heading().bold());
+    if active.is_empty() {
+        println!("{}", tr("  (no active programs)").dimmed());
+    } else {
+        print_rows(&active, blk_map, idx2id, idx_w);
+    }
+
+    if !past.is_empty() {
+        println!();
+        println!("{}", tr("Past programs:").heading().bold());
+        print_rows(&past, blk_map, idx2id, idx_w);
+    }
+}
+
+fn print_rows(
+    progs: &[&ProgJson],
+    blk_map: &HashMap<String, Vec<BlockRow>>,
+    idx2id: &HashMap<i64, String>,
+    idx_w: usize,
+) {
     let mut left = Vec::<String>::new();
     let mut right = Vec::<String>::new();
 
@@ -109,18 +944,18 @@ fn pretty_print(
         // Program row.
         //
 
-        let idx = format!("{:>width$}", p.idx, width = idx_w).yellow();
+        let idx = format!("{:>width$}", p.idx, width = idx_w).accent();
         let desc = if p.description.is_empty() {
             String::new()
         } else {
             format!("– {}", p.description).dimmed().to_string()
         };
         left.push(format!(" {} • {} {}", idx, p.name.bold(), desc));
-        right.push(
-            format!("added {}", &p.created_at[..10])
-                .dimmed()
-                .to_string(),
-        );
+        let when = match &p.archived_at {
+            Some(at) => format!("archived {}", &at[..10]),
+            None => format!("added {}", &p.created_at[..10]),
+        };
+        right.push(when.dimmed().to_string());
 
         //
         // Block rows
@@ -133,10 +968,10 @@ fn pretty_print(
                     } else {
                         "├─"
                     };
-                    let b_idx_col = format!("{:>width$}", i + 1, width = idx_w).yellow();
+                    let b_idx_col = format!("{:>width$}", i + 1, width = idx_w).accent();
                     left.push(format!(
                         " {}   {} {} • {}",
-                        " ".repeat(idx_w).yellow(),
+                        " ".repeat(idx_w).accent(),
                         connector,
                         b_idx_col,
                         b.name.bold()
@@ -153,40 +988,108 @@ fn pretty_print(
         if r.is_empty() {
             println!("{}", l);
         } else {
-            println!("{:<pad$} {} {}", l, "|".blue(), r, pad = pad);
+            println!("{:<pad$} {} {}", l, "|".info(), r, pad = pad);
         }
     }
 }
 
+/// Fill in the missing `program_1rm` of %1RM exercises in a program's blocks
+/// (or just `block_id`): the exercise's training max from any block that sets
+/// one, or else its current e1RM. Returns how many were filled.
+pub async fn autofill_1rm(pool: &SqlitePool, prog_id: &str, block_id: Option<&str>) -> Result<usize> {
+    let missing: Vec<(String, String, Option<f32>, Option<f32>)> = sqlx::query_as(
+        r#"
+        SELECT pe.id, e.name,
+               (SELECT MAX(o.program_1rm) FROM program_exercises o WHERE o.exercise_id = pe.exercise_id),
+               e.estimated_one_rm
+        FROM program_exercises pe
+        JOIN program_blocks pb ON pb.id = pe.program_block_id
+        JOIN exercises e ON e.id = pe.exercise_id
+        WHERE pb.program_id = ?1 AND (?2 IS NULL OR pb.id = ?2)
+        AND pe.program_1rm IS NULL
+        AND COALESCE(pe.target_rm_percent, '') != ''
+        ORDER BY pb.name, pe.order_index
+        "#,
+    )
+    .bind(prog_id)
+    .bind(block_id)
+    .fetch_all(pool)
+    .await?;
+
+    let mut filled = 0;
+    let mut reported = HashSet::new();
+    for (pe_id, name, training_max, e1rm) in missing {
+        let (max, source) = match (training_max, e1rm.filter(|e| *e > 0.0)) {
+            (Some(tm), _) => (tm, tr("training max")),
+            (None, Some(e1rm)) => (e1rm, tr("e1RM")),
+            (None, None) => {
+                if reported.insert(name.clone()) {
+                    println!(
+                        "{} {}",
+                        tr("warning:").accent().bold(),
+                        tf("no training max or e1RM for `{}` yet; its %1RM sets have no weight", &[&name])
+                    );
+                }
+                continue;
+            }
+        };
+        sqlx::query("UPDATE program_exercises SET program_1rm = ? WHERE id = ?")
+            .bind(max)
+            .bind(&pe_id)
+            .execute(pool)
+            .await?;
+        if reported.insert(name.clone()) {
+            ui::info(tf("1RM of {} filled in as {}kg from its {}", &[&name.bold(), &kg(max), &source]));
+        }
+        filled += 1;
+    }
+    Ok(filled)
+}
+
 pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Result<()> {
     match cmd {
-        ProgramCmd::Import { files } => {
+        ProgramCmd::Import { files, weeks, autofill_1rm: autofill } => {
             if files.is_empty() {
-                println!("{} no program file provided", "warning:".yellow().bold());
+                println!("{} {}", tr("warning:").accent().bold(), tr("no program file provided"));
             }
+            let weeks = match weeks {
+                Some(w) => Some(parse_weeks(&w).ok_or_else(|| {
+                    AppError::Invalid(tf("invalid weeks `{}` (expected e.g. 5-8 or 3)", &[&w]))
+                })?),
+                None => None,
+            };
             for f in files {
                 // Read TOML.
-                let toml = match read_to_string(&f) {
-                    Ok(s) => s,
-                    Err(_) => {
-                        println!("{} cannot open `{}`", "error:".red().bold(), f);
-                        continue;
-                    }
-                };
-                let prog: ProgramToml = match toml::from_str(&toml) {
-                    Ok(p) => p,
-                    Err(e) => {
-                        println!("{} parsing `{}`: {}", "error:".red().bold(), f, e);
+                // A bad file stops the import; the files before it are already in.
+                let toml = read_to_string(&f).map_err(|_| AppError::NotFound(tf("cannot open `{}`", &[&f])))?;
+                let mut prog: ProgramToml =
+                    toml::from_str(&toml).map_err(|e| AppError::Invalid(tf("parsing `{}`: {}", &[&f, &e])))?;
+
+                // Only the requested weeks; blocks without a week belong to none.
+                if let Some(weeks) = &weeks {
+                    prog.blocks.retain(|b| b.week.is_some_and(|w| weeks.contains(&w)));
+                    if prog.blocks.is_empty() {
+                        println!(
+                            "{} {}",
+                            tr("warning:").accent().bold(),
+                            tf("`{}` has no blocks in weeks {}", &[&f, &format_weeks(weeks)])
+                        );
                         continue;
                     }
-                };
+                }
 
                 // Validate exercises exist.
                 let mut all_ex = HashSet::new();
                 for b in &prog.blocks {
-                    for e in &b.exercises {
+                    for e in b.exercises.iter().chain(b.accessories.iter().flat_map(|a| &a.exercises)) {
                         all_ex.insert(e.name.as_str());
                     }
+                    for m in b.circuits.iter().flat_map(|c| &c.exercises) {
+                        all_ex.insert(m.name.as_str());
+                    }
+                }
+                for e in prog.substitutions.values().flatten() {
+                    all_ex.insert(e.as_str());
                 }
                 if !all_ex.is_empty() {
                     let marks = std::iter::repeat("?")
@@ -207,15 +1110,40 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
                         .filter(|n| !present.contains(*n))
                         .collect();
                     if !missing.is_empty() {
-                        println!(
-                            "{} missing exercises: {}",
-                            "warning:".yellow().bold(),
-                            missing.join(", ")
-                        );
-                        continue;
+                        return Err(AppError::NotFound(tf("missing exercises: {}", &[&missing.join(", ")])).into());
                     }
                 }
 
+                if let Some((group, _)) = prog.substitutions.iter().find(|(_, exs)| exs.len() < 2) {
+                    return Err(
+                        AppError::Invalid(tf("substitution group `{}` needs at least two exercises", &[&group])).into()
+                    );
+                }
+
+                // Validate superset groups, rep targets and accessory pools.
+                let mut block_groups = Vec::with_capacity(prog.blocks.len());
+                for b in &prog.blocks {
+                    let reps_ok = b
+                        .exercises
+                        .iter()
+                        .chain(b.accessories.iter().flat_map(|a| &a.exercises))
+                        .try_for_each(|ex| rep_targets(ex).map(drop));
+                    let pool_ok = match &b.accessories {
+                        Some(a) if a.choose == 0 || a.choose as usize > a.exercises.len() => Err(tf(
+                            "accessories: can't choose {} of {} exercises",
+                            &[&a.choose, &a.exercises.len()],
+                        )),
+                        _ => Ok(()),
+                    };
+                    let circuits_ok = b.circuits.iter().try_for_each(CircuitToml::validate);
+                    let groups = reps_ok
+                        .and(pool_ok)
+                        .and(circuits_ok)
+                        .and_then(|_| validate_groups(&b.exercises))
+                        .map_err(|e| AppError::Invalid(tf("block `{}`: {}", &[&b.name, &e])))?;
+                    block_groups.push(groups);
+                }
+
                 // Insert program.
                 let mut tx = pool.begin().await?;
                 let pid = uuid::Uuid::new_v4().to_string();
@@ -226,46 +1154,93 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
                     .fetch_optional(&mut *tx)
                     .await?;
 
+                let off_weeks = (!prog.off_weeks.is_empty())
+                    .then(|| prog.off_weeks.iter().map(u32::to_string).collect::<Vec<_>>().join(","));
+
                 let pid = if let Some(ref existing_id) = existing_id {
                     // Update existing program.
-                    sqlx::query("UPDATE programs SET description = ? WHERE id = ?")
+                    sqlx::query("UPDATE programs SET description = ?, off_weeks = ? WHERE id = ?")
                         .bind(prog.description.as_deref())
+                        .bind(&off_weeks)
                         .bind(&existing_id)
                         .execute(&mut *tx)
                         .await?;
 
-                    // Delete existing blocks and exercises.
-                    sqlx::query("DELETE FROM program_blocks WHERE program_id = ?")
-                        .bind(&existing_id)
-                        .execute(&mut *tx)
-                        .await?;
+                    // Delete existing blocks and exercises (of the requested weeks only).
+                    match &weeks {
+                        Some(weeks) => {
+                            sqlx::query("DELETE FROM program_blocks WHERE program_id = ? AND week BETWEEN ? AND ?")
+                                .bind(&existing_id)
+                                .bind(*weeks.start() as i32)
+                                .bind(*weeks.end() as i32)
+                                .execute(&mut *tx)
+                                .await?
+                        }
+                        None => {
+                            sqlx::query("DELETE FROM program_blocks WHERE program_id = ?")
+                                .bind(&existing_id)
+                                .execute(&mut *tx)
+                                .await?
+                        }
+                    };
 
                     existing_id
                 } else {
                     // Insert new program.
-                    sqlx::query("INSERT INTO programs (id,name,description,created_at) VALUES (?1,?2,?3,datetime('now'))")
+                    sqlx::query("INSERT INTO programs (id,name,description,created_at,off_weeks) VALUES (?1,?2,?3,datetime('now'),?4)")
                         .bind(&pid)
                         .bind(&prog.name)
                         .bind(prog.description.as_deref())
+                        .bind(&off_weeks)
                         .execute(&mut *tx)
                         .await?;
                     &pid
                 };
 
+                // Substitution groups are program-wide: replace them as a whole.
+                sqlx::query("DELETE FROM program_substitutions WHERE program_id = ?")
+                    .bind(pid)
+                    .execute(&mut *tx)
+                    .await?;
+                for (group, exs) in &prog.substitutions {
+                    sqlx::query("INSERT INTO program_substitutions (program_id, group_name, exercises) VALUES (?, ?, ?)")
+                        .bind(pid)
+                        .bind(group)
+                        .bind(exs.join(","))
+                        .execute(&mut *tx)
+                        .await?;
+                }
+
                 // Insert blocks & exercises.
-                for b in prog.blocks {
+                for (b, groups) in prog.blocks.into_iter().zip(block_groups) {
                     let bid = uuid::Uuid::new_v4().to_string();
-                    sqlx::query("INSERT INTO program_blocks (id,program_id,name,description) VALUES (?1,?2,?3,?4)")
-                        .bind(&bid).bind(&pid).bind(&b.name).bind(b.description.as_deref())
+                    sqlx::query("INSERT INTO program_blocks (id,program_id,name,description,expected_minutes,week,pool_choose) VALUES (?1,?2,?3,?4,?5,?6,?7)")
+                        .bind(&bid).bind(&pid).bind(&b.name).bind(b.description.as_deref()).bind(b.duration.map(|d| d as i32)).bind(b.week.map(|w| w as i32))
+                        .bind(b.accessories.as_ref().map(|a| a.choose as i32))
                         .execute(&mut *tx).await?;
+                    for (pos, c) in b.circuits.iter().enumerate() {
+                        let cid = uuid::Uuid::new_v4().to_string();
+                        sqlx::query("INSERT INTO program_circuits (id,program_block_id,position,kind,rounds,interval_secs) VALUES (?1,?2,?3,?4,?5,?6)")
+                            .bind(&cid).bind(&bid).bind(pos as i32 + 1).bind(&c.kind).bind(c.rounds as i32)
+                            .bind(c.interval.map(|s| s as i32).or((c.kind == "emom").then_some(60)))
+                            .execute(&mut *tx).await?;
+                        for (mpos, m) in c.exercises.iter().enumerate() {
+                            sqlx::query("INSERT INTO program_circuit_movements (circuit_id,position,exercise_id,reps,weight) VALUES (?1,?2,(SELECT id FROM exercises WHERE name=?3),?4,?5)")
+                                .bind(&cid).bind(mpos as i32).bind(&m.name).bind(m.reps as i32).bind(m.weight)
+                                .execute(&mut *tx).await?;
+                        }
+                    }
+
+                    // Pool exercises go after the fixed ones, flagged and ungrouped.
+                    let pooled = b.accessories.map(|a| a.exercises).unwrap_or_default();
+                    let fixed = b.exercises.into_iter().zip(groups).map(|(ex, group)| (ex, group, false));
                     let mut seen = HashSet::new();
-                    for (idx, ex) in b.exercises.into_iter().enumerate() {
+                    for (idx, (ex, group, in_pool)) in fixed.chain(pooled.into_iter().map(|ex| (ex, None, true))).enumerate() {
                         if !seen.insert(ex.name.clone()) {
                             println!(
-                                "{} duplicate `{}` in block `{}`—skipped",
-                                "warning:".yellow().bold(),
-                                ex.name,
-                                b.name
+                                "{} {}",
+                                tr("warning:").accent().bold(),
+                                tf("duplicate `{}` in block `{}`—skipped", &[&ex.name, &b.name])
                             );
                             continue;
                         }
@@ -274,27 +1249,41 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
                                 .bind(&ex.name)
                                 .fetch_one(&mut *tx)
                                 .await?;
-                        sqlx::query("INSERT INTO program_exercises (id,program_block_id,exercise_id,sets,reps,target_rpe,target_rm_percent,notes,program_1rm,technique,technique_group,order_index) VALUES (?1,?2,?3,?4,?5,?6,?7,?8,?9,?10,?11,?12)")
+                        sqlx::query("INSERT INTO program_exercises (id,program_block_id,exercise_id,sets,reps,target_rpe,target_rm_percent,notes,program_1rm,technique,technique_group,order_index,tempo,pause,options,warmup,backoff,superset,rotate_weeks,rest_secs,pool) VALUES (?1,?2,?3,?4,?5,?6,?7,?8,?9,?10,?11,?12,?13,?14,?15,?16,?17,?18,?19,?20,?21)")
                             .bind(uuid::Uuid::new_v4().to_string())
                             .bind(&bid)
                             .bind(&ex_id)
                             .bind(ex.sets as i32)
-                            .bind(ex.reps.map(|v|v.join(",")))
+                            .bind(rep_targets(&ex).ok().flatten())
                             .bind(ex.target_rpe.map(|v| v.into_iter().map(|x| x.to_string()).collect::<Vec<_>>().join(",")))
                             .bind(ex.target_rm_percent.map(|v| v.into_iter().map(|x| x.to_string()).collect::<Vec<_>>().join(",")))
                             .bind(ex.notes.as_deref())
                             .bind(ex.program_1rm)
                             .bind(ex.technique.as_deref())
-                            .bind(ex.group.map(|g|g as i32))
+                            .bind(None::<i32>)
                             .bind(idx as i32)
+                            .bind(ex.tempo.as_deref())
+                            .bind(ex.pause.map(|v| v.join(",")))
+                            .bind(ex.options.map(|v| v.join(",")))
+                            .bind(ex.warmup.as_ref().map(SetGroupToml::to_csv))
+                            .bind(ex.backoff.as_ref().map(SetGroupToml::to_csv))
+                            .bind(&group)
+                            .bind(ex.rotate.filter(|&w| w > 0).map(|w| w as i32))
+                            .bind(ex.rest.map(|s| s as i32))
+                            .bind(in_pool)
                             .execute(&mut *tx).await?;
                     }
                 }
                 tx.commit().await?;
-                if existing_id.is_some() {
-                    println!("{} `{}` updated", "ok:".green().bold(), prog.name);
+                if autofill {
+                    autofill_1rm(pool, &pid, None).await?;
+                }
+                if let (Some(_), Some(weeks)) = (&existing_id, &weeks) {
+                    ui::ok(tf("`{}` updated (weeks {})", &[&prog.name, &format_weeks(weeks)]));
+                } else if existing_id.is_some() {
+                    ui::ok(tf("`{}` updated", &[&prog.name]));
                 } else {
-                println!("{} `{}`", "ok:".green().bold(), prog.name);
+                ui::ok(format!("`{}`", prog.name));
                 }
             }
         }
@@ -305,7 +1294,7 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
                 SELECT ROW_NUMBER() OVER (ORDER BY name) AS idx,
                        id, name,
                        COALESCE(description,'') AS description,
-                       created_at
+                       created_at, archived_at
                 FROM   programs
                 ORDER  BY idx
                 "#,
@@ -322,6 +1311,8 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
                     name: r.get("name"),
                     description: r.get("description"),
                     created_at: r.get("created_at"),
+                    active: r.get::<Option<String>, _>("archived_at").is_none(),
+                    archived_at: r.get("archived_at"),
                     blocks: 0,
                 });
                 idx2id.insert(idx, r.get("id"));
@@ -337,42 +1328,8 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
             emit(fmt, &progs, || pretty_print(&progs, &blk_map, &idx2id));
         }
 
-        ProgramCmd::Show { program } => {
-            // Figure out the real UUID for this program.
-            let prog_id: String = if let Ok(idx) = program.parse::<i64>() {
-                // User passed a number - look up by row number.
-                match sqlx::query_scalar(
-                    r#"
-                SELECT id 
-                FROM (
-                  SELECT id, ROW_NUMBER() OVER (ORDER BY name) AS rn
-                  FROM programs
-                ) t
-                WHERE t.rn = ?
-                "#,
-                )
-                .bind(idx)
-                .fetch_one(pool)
-                .await {
-                    Ok(id) => id,
-                    Err(_) => {
-                        println!("{} no program at index {}", "error:".red().bold(), idx);
-                        return Ok(());
-                    }
-                }
-            } else {
-                // User passed a name - look up by exact name.
-                match sqlx::query_scalar("SELECT id FROM programs WHERE name = ?")
-                    .bind(&program)
-                    .fetch_one(pool)
-                    .await {
-                    Ok(id) => id,
-                    Err(_) => {
-                        println!("{} no program named `{}`", "error:".red().bold(), program);
-                        return Ok(());
-                    }
-                }
-            };
+        ProgramCmd::Show { program, matrix, compare_weeks } => {
+            let prog_id = resolve_program(pool, &program).await?;
 
             // Fetch the program's metadata.
             let (name, desc, created) = sqlx::query_as::<_, (String, String, String)>(
@@ -386,51 +1343,67 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
             .fetch_one(pool)
             .await?;
 
-            if !desc.is_empty() {
-                println!(
-                    "{} {} — {} (added {})",
-                    "Program:".cyan().bold(),
-                    name.bold(),
-                    desc.dimmed(),
-                    &created[..10]
-                );
-            } else {
-                println!(
-                    "{} {} (added {})",
-                    "Program:".cyan().bold(),
-                    name.bold(),
-                    &created[..10]
-                );
+            println!(
+                "{} {}",
+                tr("Program:").heading().bold(),
+                tf("{} (added {})", &[&name.bold(), &&created[..10]])
+            );
+            // Descriptions and notes are Markdown, printed under what they describe.
+            if !desc.trim().is_empty() {
+                println!("{}", ui::markdown(&desc, 2));
+            }
+
+            if matrix {
+                print_matrix(pool, &prog_id).await?;
+                return Ok(());
+            }
+
+            if let Some(weeks) = compare_weeks {
+                let pair = weeks
+                    .split_once(',')
+                    .and_then(|(a, b)| Some((a.trim().parse::<u32>().ok()?, b.trim().parse::<u32>().ok()?)));
+                let Some((a, b)) = pair.filter(|(a, b)| a != b) else {
+                    return Err(AppError::Invalid(tf("invalid weeks `{}` (expected two weeks, e.g. 3,4)", &[&weeks])).into());
+                };
+                print_week_compare(pool, &prog_id, a, b).await?;
+                return Ok(());
             }
 
             // Fetch its blocks in order.
-            let blocks = sqlx::query_as::<_, (String,String)>(
-                "SELECT name, COALESCE(description,'') FROM program_blocks WHERE program_id = ? ORDER BY name",
+            let blocks = sqlx::query_as::<_, (String, String, Option<i32>, Option<i32>)>(
+                "SELECT name, COALESCE(description,''), expected_minutes, pool_choose FROM program_blocks WHERE program_id = ? ORDER BY name",
             )
             .bind(&prog_id)
             .fetch_all(pool)
             .await?;
 
             if blocks.is_empty() {
-                println!("{} no blocks defined)", "warning".yellow().bold());
+                println!("{} {}", tr("warning").accent().bold(), tr("no blocks defined)"));
             } else {
-                println!("{}", "Blocks:".cyan().bold());
+                println!("{}", tr("Blocks:").heading().bold());
                 
-                for (i, (block_name, block_desc)) in blocks.into_iter().enumerate() {
-                    let idx = format!("{}", i + 1).yellow();
-                    let desc = if !block_desc.is_empty() {
-                        format!(" — {}", block_desc).dimmed().to_string()
-                    } else {
-                        String::new()
-                    };
-                    println!("{} • {}{}", idx, block_name.bold(), desc);
+                for (i, (block_name, block_desc, minutes, choose)) in blocks.into_iter().enumerate() {
+                    let idx = format!("{}", i + 1).accent();
+                    let duration = minutes
+                        .map(|m| format!(" (~{} min)", m).dimmed().to_string())
+                        .unwrap_or_default();
+                    let pool_display = choose
+                        .map(|n| format!(" ({})", tf("{} accessories from the pool", &[&n])).dimmed().to_string())
+                        .unwrap_or_default();
+                    println!("{} • {}{}{}", idx, block_name.bold(), duration, pool_display);
+                    if !block_desc.trim().is_empty() {
+                        println!("{}", ui::markdown(&block_desc, 4));
+                    }
                     
                     // Fetch the exercises in that block.
-                    let exs = sqlx::query_as::<_, (i32, String, i32)>(
+                    let exs = sqlx::query_as::<_, (i32, String, i32, Option<String>, bool, Option<String>)>(
                         r#"
                         SELECT pe.order_index,
                                e.name,
-                               pe.sets
+                               pe.sets,
+                               pe.superset,
+                               pe.pool,
+                               pe.notes
                       FROM program_exercises pe
                       JOIN exercises e
                         ON e.id = pe.exercise_id
@@ -448,7 +1421,10 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
                     .fetch_all(pool)
                     .await?;
 
-                    for (order, ex_name, sets) in exs.clone() {
+                    let groups: Vec<Option<String>> = exs.iter().map(|(_, _, _, g, _, _)| g.clone()).collect();
+                    let labels = superset_labels(&groups);
+
+                    for ((order, ex_name, sets, _, in_pool, notes), label) in exs.clone().into_iter().zip(labels) {
                         let reps_csv: Option<String> = sqlx::query_scalar(
                             r#"
                             SELECT reps
@@ -470,15 +1446,25 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
                         .fetch_one(pool)
                         .await?;
 
-                        // format the reps into a nicer "(5, 6–10, 15 reps)" if present
+                        // format the reps into a nicer "(5, 6-10, 15 reps)" if present;
+                        // clusters and timed sets spell out each target instead
                         let reps_display = reps_csv
                             .map(|csv| {
+                                let targets: Vec<Option<RepTarget>> = csv.split(',').map(RepTarget::parse).collect();
+                                let counts = targets.iter().all(|t| {
+                                    matches!(t, Some(RepTarget::Exact(_) | RepTarget::Range(..) | RepTarget::AtLeast(_)))
+                                });
                                 let pretty = csv
                                     .split(',')
-                                    .map(|s| s.trim())
+                                    .zip(&targets)
+                                    .map(|(raw, t)| match t {
+                                        Some(t) if !counts => t.label(),
+                                        Some(t) => t.to_string(),
+                                        None => raw.trim().to_string(),
+                                    })
                                     .collect::<Vec<_>>()
                                     .join(", ");
-                                format!(" ({pretty} reps)")
+                                if counts { format!(" ({pretty} reps)") } else { format!(" ({pretty})") }
                             })
                             .unwrap_or_default();
 
@@ -487,58 +1473,36 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
                         } else {
                             "├─"
                         };
-                        let idx = format!("{}", order + 1).yellow();
+                        let idx = format!("{}", order + 1).accent();
+                        let label = label
+                            .map(|l| format!("{} ", l).highlight().bold().to_string())
+                            .unwrap_or_default();
+
+                        let pool_tag = if in_pool { format!(" {}", tr("[pool]")).dimmed().to_string() } else { String::new() };
 
                         println!(
-                            " {} {} {} • {} -> {} sets{}",
+                            " {} {} {} • {}{} -> {} sets{}{}",
                             " ".repeat(2),
                             connector,
                             idx,
+                            label,
                             ex_name.bold(),
                             sets,
-                            reps_display
+                            reps_display,
+                            pool_tag
                         );
+                        if let Some(notes) = notes.filter(|n| !n.trim().is_empty()) {
+                            println!("{}", ui::markdown(&notes, 11));
+                        }
                     }
                 }
             }
+
+            print_substitutions(pool, &prog_id).await?;
         }
 
         ProgramCmd::Delete { program } => {
-            // Figure out the real UUID for this program.
-            let prog_id: String = if let Ok(idx) = program.parse::<i64>() {
-                // User passed a number - look up by row number.
-                match sqlx::query_scalar(
-                    r#"
-                SELECT id 
-                FROM (
-                  SELECT id, ROW_NUMBER() OVER (ORDER BY name) AS rn
-                  FROM programs
-                ) t
-                WHERE t.rn = ?
-                "#,
-                )
-                .bind(idx)
-                .fetch_one(pool)
-                .await {
-                    Ok(id) => id,
-                    Err(_) => {
-                        println!("{} no program at index {}", "error:".red().bold(), idx);
-                        return Ok(());
-                    }
-                }
-            } else {
-                // User passed a name - look up by exact name.
-                match sqlx::query_scalar("SELECT id FROM programs WHERE name = ?")
-                    .bind(&program)
-                    .fetch_one(pool)
-                    .await {
-                    Ok(id) => id,
-                    Err(_) => {
-                        println!("{} no program named `{}`", "error:".red().bold(), program);
-                        return Ok(());
-                    }
-                }
-            };
+            let prog_id = resolve_program(pool, &program).await?;
 
             // Get program name for confirmation message.
             let name: String = sqlx::query_scalar("SELECT name FROM programs WHERE id = ?")
@@ -552,8 +1516,305 @@ pub async fn handle(cmd: ProgramCmd, pool: &SqlitePool, fmt: OutputFmt) -> Resul
                 .execute(pool)
                 .await?;
 
-            println!("{} deleted program `{}`", "ok:".green().bold(), name);
+            ui::ok(tf("deleted program `{}`", &[&name]));
+        }
+
+        ProgramCmd::AddEx {
+            program,
+            block,
+            exercise,
+            sets,
+            reps,
+            at,
+        } => {
+            let prog_id = resolve_program(pool, &program).await?;
+            let block_id = resolve_block(pool, &prog_id, &block).await?;
+
+            // Exercise index (from `ex list`) or exact name.
+            let ex: Option<(String, String)> = if let Ok(idx) = exercise.parse::<i64>() {
+                sqlx::query_as("SELECT id, name FROM exercises WHERE idx = ?")
+                    .bind(idx)
+                    .fetch_optional(pool)
+                    .await?
+            } else {
+                sqlx::query_as("SELECT id, name FROM exercises WHERE name = ?")
+                    .bind(&exercise)
+                    .fetch_optional(pool)
+                    .await?
+            };
+            let Some((ex_id, ex_name)) = ex else {
+                return Err(AppError::NotFound(tf("no such exercise `{}`", &[&exercise]).into()).into());
+            };
+
+            if sets == 0 {
+                return Err(AppError::Invalid(tr("sets must be at least 1").into()).into());
+            }
+
+            // A single value applies to every set, a list is taken as-is.
+            let reps_csv = match reps {
+                Some(r) => {
+                    let parts = r
+                        .split(',')
+                        .map(|p| parse_rep_target(p, &ex_name).map(|t| t.to_string()))
+                        .collect::<std::result::Result<Vec<_>, _>>()
+                        .map_err(AppError::Invalid)?;
+                    Some(if parts.len() == 1 {
+                        vec![parts[0].as_str(); sets as usize].join(",")
+                    } else {
+                        parts.join(",")
+                    })
+                }
+                None => None,
+            };
+
+            let mut order = block_exercises(pool, &block_id).await?;
+            let pe_id = uuid::Uuid::new_v4().to_string();
+            let res = sqlx::query(
+                "INSERT INTO program_exercises (id,program_block_id,exercise_id,sets,reps,order_index) VALUES (?1,?2,?3,?4,?5,?6)",
+            )
+            .bind(&pe_id)
+            .bind(&block_id)
+            .bind(&ex_id)
+            .bind(sets as i32)
+            .bind(reps_csv.as_deref())
+            .bind(order.len() as i32)
+            .execute(pool)
+            .await;
+
+            match res {
+                Ok(_) => {}
+                Err(sqlx::Error::Database(db_err)) if db_err.code() == Some("2067".into()) => {
+                    println!(
+                        "{} {}",
+                        tr("warning:").accent().bold(),
+                        tf("`{}` is already in block `{}`", &[&ex_name, &block])
+                    );
+                    return Ok(());
+                }
+                Err(e) => return Err(e.into()),
+            }
+
+            if let Some(pos) = at {
+                let pos = pos.clamp(1, order.len() + 1) - 1;
+                order.insert(pos, (pe_id, ex_name.clone()));
+                let ids: Vec<String> = order.into_iter().map(|(id, _)| id).collect();
+                write_block_order(pool, &ids).await?;
+            }
+
+            ui::ok(tf("added `{}` to block `{}` ({} sets{})", &[&ex_name, &block, &sets, &reps_csv.map(|r| format!(" of {}", r)).unwrap_or_default()]));
+        }
+
+        ProgramCmd::RmEx {
+            program,
+            block,
+            exercise,
+        } => {
+            let prog_id = resolve_program(pool, &program).await?;
+            let block_id = resolve_block(pool, &prog_id, &block).await?;
+
+            let mut order = block_exercises(pool, &block_id).await?;
+            let Some(pos) = find_in_block(&order, &exercise) else {
+                return Err(AppError::NotFound(tf("no exercise `{}` in block `{}`", &[&exercise, &block]).into()).into());
+            };
+
+            let (pe_id, ex_name) = order.remove(pos);
+            sqlx::query("DELETE FROM program_exercises WHERE id = ?")
+                .bind(&pe_id)
+                .execute(pool)
+                .await?;
+
+            // Close the gap so `p show` numbering stays contiguous.
+            let ids: Vec<String> = order.into_iter().map(|(id, _)| id).collect();
+            write_block_order(pool, &ids).await?;
+
+            ui::ok(tf("removed `{}` from block `{}`", &[&ex_name, &block]));
+        }
+
+        ProgramCmd::MoveEx {
+            program,
+            block,
+            exercise,
+            to,
+        } => {
+            let prog_id = resolve_program(pool, &program).await?;
+            let block_id = resolve_block(pool, &prog_id, &block).await?;
+
+            let mut order = block_exercises(pool, &block_id).await?;
+            let Some(pos) = find_in_block(&order, &exercise) else {
+                return Err(AppError::NotFound(tf("no exercise `{}` in block `{}`", &[&exercise, &block]).into()).into());
+            };
+            if to == 0 || to > order.len() {
+                return Err(AppError::Invalid(tf("position must be between 1 and {}", &[&order.len()]).into()).into());
+            }
+
+            let item = order.remove(pos);
+            let ex_name = item.1.clone();
+            order.insert(to - 1, item);
+            let ids: Vec<String> = order.into_iter().map(|(id, _)| id).collect();
+            write_block_order(pool, &ids).await?;
+
+            ui::ok(tf("moved `{}` to position {} in block `{}`", &[&ex_name, &to, &block]));
         }
     }
     Ok(())
 }
+
+/// Archive a finished program, or make it active again with `restore`.
+pub async fn handle_archive(pool: &SqlitePool, program: String, restore: bool) -> Result<()> {
+    let prog_id = resolve_program(pool, &program).await?;
+    let (name, archived_at): (String, Option<String>) =
+        sqlx::query_as("SELECT name, archived_at FROM programs WHERE id = ?")
+            .bind(&prog_id)
+            .fetch_one(pool)
+            .await?;
+
+    if restore {
+        if archived_at.is_none() {
+            ui::info(tf("`{}` is already active", &[&name]));
+            return Ok(());
+        }
+        sqlx::query("UPDATE programs SET archived_at = NULL WHERE id = ?")
+            .bind(&prog_id)
+            .execute(pool)
+            .await?;
+        ui::ok(tf("`{}` is active again", &[&name]));
+        return Ok(());
+    }
+
+    if let Some(at) = archived_at {
+        ui::info(tf("`{}` was already archived on {}", &[&name, &&at[..10]]));
+        return Ok(());
+    }
+    sqlx::query("UPDATE programs SET archived_at = datetime('now') WHERE id = ?")
+        .bind(&prog_id)
+        .execute(pool)
+        .await?;
+    ui::ok(tf("archived `{}`", &[&name]));
+
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::testutil::{self, memory_db};
+
+    /// `program import` of `toml`, written to a file first.
+    async fn import(pool: &SqlitePool, toml: &str) -> Result<()> {
+        let path = testutil::temp_path("program.toml");
+        std::fs::write(&path, toml).unwrap();
+        let files = vec![path.display().to_string()];
+        let res = handle(ProgramCmd::Import { files, weeks: None, autofill_1rm: false }, pool, OutputFmt { json: false }).await;
+        let _ = std::fs::remove_file(path);
+        res
+    }
+
+    /// `(block, exercise, sets, reps)` of every prescribed exercise, in order.
+    async fn prescribed(pool: &SqlitePool) -> Vec<(String, String, i64, Option<String>)> {
+        sqlx::query_as(
+            r#"
+            SELECT pb.name, e.name, pe.sets, pe.reps
+            FROM program_exercises pe
+            JOIN program_blocks pb ON pb.id = pe.program_block_id
+            JOIN exercises e ON e.id = pe.exercise_id
+            ORDER BY pb.name, pe.order_index
+            "#,
+        )
+        .fetch_all(pool)
+        .await
+        .unwrap()
+    }
+
+    #[tokio::test]
+    async fn importing_then_updating_a_program() {
+        let pool = memory_db().await;
+        testutil::exercise(&pool, "Squat", "quads").await;
+        testutil::exercise(&pool, "Bench Press", "chest").await;
+
+        import(
+            &pool,
+            r#"
+            name = "Strength"
+            description = "v1"
+
+            [[blocks]]
+            name = "Day A"
+
+            [[blocks.exercises]]
+            name = "Squat"
+            sets = 3
+            reps = ["5"]
+
+            [[blocks.exercises]]
+            name = "Bench Press"
+            sets = 3
+            reps = ["6-10"]
+            "#,
+        )
+        .await
+        .unwrap();
+        assert_eq!(
+            prescribed(&pool).await,
+            [
+                ("Day A".into(), "Squat".into(), 3, Some("5".into())),
+                ("Day A".into(), "Bench Press".into(), 3, Some("6-10".into())),
+            ]
+        );
+
+        // Same name: the program is updated in place, its blocks rebuilt.
+        import(
+            &pool,
+            r#"
+            name = "Strength"
+            description = "v2"
+
+            [[blocks]]
+            name = "Day A"
+
+            [[blocks.exercises]]
+            name = "Squat"
+            sets = 5
+            reps = ["3"]
+
+            [[blocks]]
+            name = "Day B"
+
+            [[blocks.exercises]]
+            name = "Bench Press"
+            sets = 4
+            reps = ["8"]
+            "#,
+        )
+        .await
+        .unwrap();
+        let programs: Vec<(String, Option<String>)> =
+            sqlx::query_as("SELECT name, description FROM programs").fetch_all(&pool).await.unwrap();
+        assert_eq!(programs, [("Strength".into(), Some("v2".into()))]);
+        assert_eq!(
+            prescribed(&pool).await,
+            [
+                ("Day A".into(), "Squat".into(), 5, Some("3".into())),
+                ("Day B".into(), "Bench Press".into(), 4, Some("8".into())),
+            ]
+        );
+
+        // An unknown exercise fails the import and leaves the program alone.
+        let err = import(
+            &pool,
+            r#"
+            name = "Strength"
+
+            [[blocks]]
+            name = "Day A"
+
+            [[blocks.exercises]]
+            name = "Deadlift"
+            sets = 1
+            "#,
+        )
+        .await
+        .unwrap_err();
+        assert_eq!(err.downcast_ref::<AppError>().map(AppError::exit_code), Some(2));
+        assert_eq!(prescribed(&pool).await.len(), 2);
+    }
+}
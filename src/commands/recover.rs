@@ -0,0 +1,145 @@
+use anyhow::Result;
+use colored::Colorize;
+use sqlx::SqlitePool;
+use uuid::Uuid;
+
+use crate::{
+    commands::{compare::resolve_session, wal},
+    errors::AppError,
+    i18n::{display_db_date, tf, tr},
+    ui::{self, Themed},
+};
+
+/// Hours without a logged set after which an open session counts as forgotten.
+const STALE_HOURS: i64 = 12;
+
+/// Unfinished sessions as (id, tag, block, start, sets logged, last activity).
+async fn unfinished(pool: &SqlitePool) -> Result<Vec<(String, Option<String>, String, String, i64, String)>> {
+    Ok(sqlx::query_as(
+        r#"
+        SELECT ts.id, ts.tag, pb.name, ts.start_time,
+               COUNT(es.id),
+               COALESCE(MAX(es.timestamp), ts.start_time)
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        LEFT JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+        LEFT JOIN exercise_sets es ON es.session_exercise_id = tse.id
+        WHERE ts.end_time IS NULL
+        GROUP BY ts.id
+        ORDER BY ts.start_time
+        "#,
+    )
+    .fetch_all(pool)
+    .await?)
+}
+
+/// List unfinished sessions, or rebuild one from what the database kept of it.
+pub async fn handle(pool: &SqlitePool, session: Option<String>) -> Result<()> {
+    let Some(session) = session else {
+        let open = unfinished(pool).await?;
+        if open.is_empty() {
+            ui::info(tr("no unfinished sessions"));
+            return Ok(());
+        }
+
+        println!("{}", tr("Unfinished sessions:").heading().bold());
+        for (id, tag, block, start, sets, last) in open {
+            let idle_hours: i64 = sqlx::query_scalar("SELECT CAST((julianday('now') - julianday(?)) * 24 AS INTEGER)")
+                .bind(&last)
+                .fetch_one(pool)
+                .await?;
+            let stale = if idle_hours >= STALE_HOURS {
+                format!(" {}", tf("stale, idle for {}h", &[&idle_hours]).accent())
+            } else {
+                String::new()
+            };
+            println!(
+                "  {} • {}{} — {} {}{}",
+                id[..8].accent(),
+                block.bold(),
+                tag.map(|t| format!(" [{}]", t)).unwrap_or_default(),
+                tf("{} sets", &[&sets]),
+                tf("(started {})", &[&display_db_date(&start)]).dimmed(),
+                stale
+            );
+        }
+        println!("{}", tr("rebuild one with `recover-session <id>`").dimmed());
+        return Ok(());
+    };
+
+    let session_id = resolve_session(pool, &session).await?;
+    let (block_id, tag, end_time): (String, Option<String>, Option<String>) =
+        sqlx::query_as("SELECT program_block_id, tag, end_time FROM training_sessions WHERE id = ?")
+            .bind(&session_id)
+            .fetch_one(pool)
+            .await?;
+    if end_time.is_some() {
+        return Err(AppError::Invalid(tf("session {} is already finished", &[&&session_id[..8]])).into());
+    }
+
+    // Program exercises with no row in the session (a start cut short, a
+    // partial import) are added back after the ones already there.
+    let missing: Vec<(String, String)> = sqlx::query_as(
+        r#"
+        SELECT e.id, e.name
+        FROM program_exercises pe
+        JOIN exercises e ON e.id = pe.exercise_id
+        WHERE pe.program_block_id = ?1
+        AND NOT EXISTS (
+            SELECT 1 FROM training_session_exercises tse
+            WHERE tse.training_session_id = ?2
+            AND COALESCE(tse.rotated_from, tse.exercise_id) = pe.exercise_id
+        )
+        ORDER BY pe.order_index
+        "#,
+    )
+    .bind(&block_id)
+    .bind(&session_id)
+    .fetch_all(pool)
+    .await?;
+
+    let mut tx = pool.begin().await?;
+    for (ex_id, name) in &missing {
+        sqlx::query("INSERT INTO training_session_exercises (id, training_session_id, exercise_id) VALUES (?, ?, ?)")
+            .bind(Uuid::new_v4().to_string())
+            .bind(&session_id)
+            .bind(ex_id)
+            .execute(&mut *tx)
+            .await?;
+        println!("  {} {}", "+".good(), name);
+    }
+
+    // Recovering resumes the session, so a pause left open is closed.
+    sqlx::query("UPDATE session_pauses SET resumed_at = datetime('now') WHERE training_session_id = ? AND resumed_at IS NULL")
+        .bind(&session_id)
+        .execute(&mut *tx)
+        .await?;
+    tx.commit().await?;
+
+    // Sets the journal has and the database lost.
+    let replayed = wal::replay(pool, &session_id).await?;
+
+    let sets: i64 = sqlx::query_scalar(
+        r#"
+        SELECT COUNT(*)
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        WHERE tse.training_session_id = ?
+        "#,
+    )
+    .bind(&session_id)
+    .fetch_one(pool)
+    .await?;
+
+    ui::ok(tf(
+        "recovered session {}: {} sets kept, {} replayed from the journal, {} exercises restored",
+        &[&&session_id[..8], &sets, &replayed, &missing.len()]
+    ));
+    let flag = tag.map(|t| format!(" --session {}", t)).unwrap_or_default();
+    println!(
+        "{}",
+        tf("carry on with `session show{}`, or close it with `session finish{}`", &[&flag, &flag]).dimmed()
+    );
+
+    Ok(())
+}
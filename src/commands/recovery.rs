@@ -0,0 +1,347 @@
+use std::collections::{BTreeMap, HashMap};
+
+use anyhow::Result;
+use chrono::{Duration, NaiveDate};
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::rest::monday,
+    errors::AppError,
+    i18n::{short_date, tf, tr},
+    types::RepTarget,
+    ui::{self, Themed},
+};
+
+/// One night as read from an export; either metric may be missing.
+#[derive(Default)]
+struct Night {
+    sleep_minutes: Option<i64>,
+    hrv_sum: f64,
+    hrv_n: u32,
+}
+
+impl Night {
+    fn add_sleep(&mut self, minutes: i64) {
+        *self.sleep_minutes.get_or_insert(0) += minutes;
+    }
+
+    fn add_hrv(&mut self, hrv: f64) {
+        self.hrv_sum += hrv;
+        self.hrv_n += 1;
+    }
+
+    fn hrv(&self) -> Option<f64> {
+        (self.hrv_n > 0).then(|| self.hrv_sum / self.hrv_n as f64)
+    }
+}
+
+/// "2024-01-05", "2024-01-05 7:12AM", "2024-01-05T00:00:00" or "01/05/2024".
+fn parse_day(s: &str) -> Option<NaiveDate> {
+    let s = s.trim().trim_matches('"');
+    s.get(..10)
+        .and_then(|d| NaiveDate::parse_from_str(d, "%Y-%m-%d").ok())
+        .or_else(|| NaiveDate::parse_from_str(s.split_whitespace().next()?, "%m/%d/%Y").ok())
+}
+
+/// Split a CSV line, honoring double quotes.
+fn split_csv(line: &str) -> Vec<String> {
+    split_delimited(line, ',')
+}
+
+/// Split a line on `delim` (`;` in locales with decimal commas), honoring
+/// double quotes.
+pub fn split_delimited(line: &str, delim: char) -> Vec<String> {
+    let mut out = vec![String::new()];
+    let mut quoted = false;
+    for c in line.chars() {
+        match c {
+            '"' => quoted = !quoted,
+            c if c == delim && !quoted => out.push(String::new()),
+            _ => out.last_mut().unwrap().push(c),
+        }
+    }
+    out.into_iter().map(|f| f.trim().to_string()).collect()
+}
+
+/// Fitbit sleep ("End Time", "Minutes Asleep") and HRV ("timestamp", "rmssd")
+/// exports. Naps on the same day add up.
+fn parse_fitbit(text: &str) -> Option<BTreeMap<NaiveDate, Night>> {
+    let mut lines = text.lines().filter(|l| !l.trim().is_empty());
+    // The sleep export starts with a "Sleep" title line before the header.
+    let mut header = split_csv(lines.next()?);
+    if header.len() == 1 {
+        header = split_csv(lines.next()?);
+    }
+    let col = |names: &[&str]| {
+        names
+            .iter()
+            .find_map(|n| header.iter().position(|h| h.eq_ignore_ascii_case(n)))
+    };
+
+    let date = col(&["end time", "date", "timestamp", "day", "start time"])?;
+    let minutes = col(&["minutes asleep", "minutes_asleep", "sleep minutes"]);
+    let hours = col(&["sleep hours", "hours asleep"]);
+    let hrv = col(&["rmssd", "daily rmssd", "hrv"]);
+    if minutes.is_none() && hours.is_none() && hrv.is_none() {
+        return None;
+    }
+
+    let mut nights: BTreeMap<NaiveDate, Night> = BTreeMap::new();
+    for line in lines {
+        let row = split_csv(line);
+        let Some(day) = row.get(date).and_then(|d| parse_day(d)) else { continue };
+        let num = |i: Option<usize>| i.and_then(|i| row.get(i)).and_then(|v| v.parse::<f64>().ok());
+        let night = nights.entry(day).or_default();
+        if let Some(m) = num(minutes).or_else(|| num(hours).map(|h| h * 60.0)) {
+            night.add_sleep(m.round() as i64);
+        }
+        if let Some(h) = num(hrv).filter(|h| *h > 0.0) {
+            night.add_hrv(h);
+        }
+    }
+
+    Some(nights)
+}
+
+/// Oura exports: API v2 (`data[].day`, `total_sleep_duration`, `average_hrv`)
+/// or the older `sleep[].summary_date`, `total`, `rmssd`. Durations are seconds.
+fn parse_oura(text: &str) -> Option<BTreeMap<NaiveDate, Night>> {
+    let json: serde_json::Value = serde_json::from_str(text).ok()?;
+    let records = match &json {
+        serde_json::Value::Array(a) => a,
+        v => v.get("data").or_else(|| v.get("sleep"))?.as_array()?,
+    };
+
+    let mut nights: BTreeMap<NaiveDate, Night> = BTreeMap::new();
+    for r in records {
+        let field = |names: &[&str]| names.iter().find_map(|n| r.get(*n));
+        let Some(day) = field(&["day", "summary_date", "date"])
+            .and_then(|d| d.as_str())
+            .and_then(parse_day)
+        else {
+            continue;
+        };
+        let night = nights.entry(day).or_default();
+        if let Some(secs) = field(&["total_sleep_duration", "total"]).and_then(|v| v.as_f64()) {
+            night.add_sleep((secs / 60.0).round() as i64);
+        }
+        if let Some(h) = field(&["average_hrv", "rmssd", "hrv"]).and_then(|v| v.as_f64()).filter(|h| *h > 0.0) {
+            night.add_hrv(h);
+        }
+    }
+
+    Some(nights)
+}
+
+pub async fn handle_import(pool: &SqlitePool, file: String) -> Result<()> {
+    let text = match std::fs::read_to_string(&file) {
+        Ok(t) => t,
+        Err(_) => {
+            return Err(AppError::NotFound(tf("cannot open `{}`", &[&file]).into()).into());
+        }
+    };
+
+    let lower = file.to_ascii_lowercase();
+    let (source, nights) = if lower.ends_with(".json") {
+        ("oura", parse_oura(&text))
+    } else if lower.ends_with(".csv") {
+        ("fitbit", parse_fitbit(&text))
+    } else {
+        return Err(AppError::Invalid(tf("unknown format for `{}` (expected a Fitbit .csv or Oura .json export)", &[&file]).into()).into());
+    };
+
+    let Some(nights) = nights.filter(|n| !n.is_empty()) else {
+        return Err(AppError::NotFound(tf("no sleep or HRV data found in `{}`", &[&file]).into()).into());
+    };
+
+    let mut tx = pool.begin().await?;
+    for (day, night) in &nights {
+        sqlx::query(
+            r#"
+            INSERT INTO recovery (date, sleep_minutes, hrv, source, imported_at)
+            VALUES (?, ?, ?, ?, datetime('now'))
+            ON CONFLICT (date) DO UPDATE SET
+              sleep_minutes = COALESCE(excluded.sleep_minutes, sleep_minutes),
+              hrv = COALESCE(excluded.hrv, hrv),
+              source = excluded.source,
+              imported_at = excluded.imported_at
+            "#,
+        )
+        .bind(day.format("%Y-%m-%d").to_string())
+        .bind(night.sleep_minutes)
+        .bind(night.hrv())
+        .bind(source)
+        .execute(&mut *tx)
+        .await?;
+    }
+    tx.commit().await?;
+
+    let (first, last) = (nights.keys().next().unwrap(), nights.keys().last().unwrap());
+    ui::ok(tf("imported {} nights ({} – {})", &[&nights.len(), &short_date(*first), &short_date(*last)]));
+
+    Ok(())
+}
+
+/// Lower bound of a rep target: "5" → 5, "6-10" → 6, "8+" → 8, "3x3 cluster" → 9.
+pub fn min_reps(target: &str) -> Option<i32> {
+    RepTarget::parse(target)?.min_reps().map(|n| n as i32)
+}
+
+#[derive(Default)]
+struct Week {
+    sleep_sum: i64,
+    sleep_n: i64,
+    hrv_sum: f64,
+    hrv_n: i64,
+    sessions: i64,
+    sets: i64,
+    missed: i64,
+}
+
+impl Week {
+    fn sleep_hours(&self) -> Option<f64> {
+        (self.sleep_n > 0).then(|| self.sleep_sum as f64 / self.sleep_n as f64 / 60.0)
+    }
+
+    fn hrv(&self) -> Option<f64> {
+        (self.hrv_n > 0).then(|| self.hrv_sum / self.hrv_n as f64)
+    }
+}
+
+/// Weekly sleep/HRV next to sessions and missed sets (reps below the program
+/// target), plus how often sets were missed in short-sleep weeks. Prints
+/// nothing when no recovery data falls in the period.
+pub async fn print_overlay(pool: &SqlitePool, weeks: u32) -> Result<()> {
+    let from = monday(chrono::Local::now().date_naive()) - Duration::weeks(weeks.saturating_sub(1) as i64);
+    let from_str = from.format("%Y-%m-%d").to_string();
+
+    let nights: Vec<(String, Option<i64>, Option<f64>)> =
+        sqlx::query_as("SELECT date, sleep_minutes, hrv FROM recovery WHERE date >= ? ORDER BY date")
+            .bind(&from_str)
+            .fetch_all(pool)
+            .await?;
+    if nights.is_empty() {
+        return Ok(());
+    }
+
+    let mut by_week: BTreeMap<NaiveDate, Week> = BTreeMap::new();
+    for (d, sleep, hrv) in nights {
+        let Ok(d) = NaiveDate::parse_from_str(&d, "%Y-%m-%d") else { continue };
+        let w = by_week.entry(monday(d)).or_default();
+        if let Some(m) = sleep {
+            w.sleep_sum += m;
+            w.sleep_n += 1;
+        }
+        if let Some(h) = hrv {
+            w.hrv_sum += h;
+            w.hrv_n += 1;
+        }
+    }
+
+    let sessions: Vec<String> =
+        sqlx::query_scalar("SELECT date(start_time) FROM training_sessions WHERE end_time IS NOT NULL AND date(start_time) >= ?")
+            .bind(&from_str)
+            .fetch_all(pool)
+            .await?;
+    for d in sessions {
+        if let Ok(d) = NaiveDate::parse_from_str(&d, "%Y-%m-%d") {
+            by_week.entry(monday(d)).or_default().sessions += 1;
+        }
+    }
+
+    // Sets in logging order, with the program's rep targets for their exercise.
+    let sets: Vec<(String, String, Option<String>, i32)> = sqlx::query_as(
+        r#"
+        SELECT date(ts.start_time), tse.id, pe.reps, es.reps
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        JOIN training_sessions ts ON ts.id = tse.training_session_id
+        LEFT JOIN program_exercises pe
+          ON pe.program_block_id = ts.program_block_id
+         AND pe.exercise_id = COALESCE(tse.rotated_from, tse.exercise_id)
+        WHERE ts.end_time IS NOT NULL
+        AND date(ts.start_time) >= ?
+        ORDER BY tse.id, es.set_index
+        "#,
+    )
+    .bind(&from_str)
+    .fetch_all(pool)
+    .await?;
+
+    let mut set_no: HashMap<String, usize> = HashMap::new();
+    for (d, tse_id, targets, reps) in sets {
+        let Ok(d) = NaiveDate::parse_from_str(&d, "%Y-%m-%d") else { continue };
+        let n = set_no.entry(tse_id).or_default();
+        let i = *n;
+        *n += 1;
+
+        let w = by_week.entry(monday(d)).or_default();
+        w.sets += 1;
+        let targets: Vec<&str> = targets.as_deref().map(|t| t.split(',').collect()).unwrap_or_default();
+        let target = targets.get(i).or(targets.last()).and_then(|t| min_reps(t));
+        if target.is_some_and(|t| reps < t) {
+            w.missed += 1;
+        }
+    }
+
+    println!();
+    println!("{}", tr("Recovery:").heading().bold());
+    println!(
+        "  {:<10} {:>6} {:>5} {:>9} {:>7}",
+        tr("week").dimmed(),
+        tr("sleep").dimmed(),
+        "HRV".dimmed(),
+        tr("sessions").dimmed(),
+        tr("missed").dimmed()
+    );
+
+    let avg_sleep = {
+        let hours: Vec<f64> = by_week.values().filter_map(Week::sleep_hours).collect();
+        (!hours.is_empty()).then(|| hours.iter().sum::<f64>() / hours.len() as f64)
+    };
+
+    for (week, w) in &by_week {
+        let sleep = match w.sleep_hours() {
+            Some(h) => {
+                let s = format!("{:>5.1}h", h);
+                if avg_sleep.is_some_and(|a| h < a - 0.5) { s.bad().to_string() } else { s }
+            }
+            None => format!("{:>6}", "—"),
+        };
+        let hrv = w.hrv().map(|h| format!("{:>5.0}", h)).unwrap_or_else(|| format!("{:>5}", "—"));
+        let missed = format!("{}/{}", w.missed, w.sets);
+        let missed = if w.missed > 0 { missed.accent().to_string() } else { missed };
+        println!(
+            "  {:<10} {} {} {:>9} {:>7}",
+            short_date(*week),
+            sleep,
+            hrv,
+            w.sessions,
+            missed
+        );
+    }
+
+    // Missed-set rate in weeks with below-average sleep vs. the rest.
+    if let Some(avg) = avg_sleep {
+        let rate = |short: bool| {
+            let (missed, sets) = by_week
+                .values()
+                .filter(|w| w.sleep_hours().is_some_and(|h| (h < avg) == short))
+                .fold((0, 0), |(m, s), w| (m + w.missed, s + w.sets));
+            (sets > 0).then(|| missed as f64 / sets as f64 * 100.0)
+        };
+        if let (Some(short), Some(rested)) = (rate(true), rate(false)) {
+            println!(
+                "  {}",
+                tf(
+                    "missed sets: {}% in short-sleep weeks vs {}% otherwise (avg sleep {}h)",
+                    &[&format!("{:.0}", short), &format!("{:.0}", rested), &format!("{:.1}", avg)]
+                )
+                .dimmed()
+            );
+        }
+    }
+
+    Ok(())
+}
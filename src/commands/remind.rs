@@ -0,0 +1,267 @@
+use std::{fs, path::PathBuf, process::Command};
+
+use anyhow::{Context, Result};
+use chrono::{NaiveTime, Timelike};
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::{journal::escape_html, week::pending_blocks},
+    errors::AppError,
+    i18n::{tf, tr},
+    ui::{self, Themed},
+};
+
+/// `--days` names with their systemd spelling and launchd weekday (Sunday = 0).
+const DAYS: [(&str, &str, u32); 7] = [
+    ("mon", "Mon", 1),
+    ("tue", "Tue", 2),
+    ("wed", "Wed", 3),
+    ("thu", "Thu", 4),
+    ("fri", "Fri", 5),
+    ("sat", "Sat", 6),
+    ("sun", "Sun", 0),
+];
+
+const UNIT: &str = "lazarus-remind";
+const LAUNCHD_LABEL: &str = "com.lazarus.remind";
+
+/// "mon,wed,fri" → indices into [`DAYS`]; every day when omitted.
+fn parse_days(days: Option<&str>) -> Option<Vec<usize>> {
+    let Some(days) = days else {
+        return Some((0..DAYS.len()).collect());
+    };
+    let mut out = Vec::new();
+    for d in days.split(',').map(|d| d.trim().to_lowercase()) {
+        let i = DAYS.iter().position(|(name, _, _)| d.starts_with(name))?;
+        if !out.contains(&i) {
+            out.push(i);
+        }
+    }
+    out.sort();
+    (!out.is_empty()).then_some(out)
+}
+
+fn systemd_dir() -> Result<PathBuf> {
+    Ok(dirs::config_dir().context("no config dir")?.join("systemd").join("user"))
+}
+
+fn launchd_plist() -> Result<PathBuf> {
+    Ok(dirs::home_dir()
+        .context("no home dir")?
+        .join("Library")
+        .join("LaunchAgents")
+        .join(format!("{}.plist", LAUNCHD_LABEL)))
+}
+
+/// Run a service manager command, warning with the command line when it fails
+/// so it can be run by hand.
+fn run(cmd: &str, args: &[&str]) {
+    let ok = Command::new(cmd).args(args).status().is_ok_and(|s| s.success());
+    if !ok {
+        println!(
+            "{} {}",
+            tr("warning:").accent().bold(),
+            tf("`{} {}` failed, run it yourself", &[&cmd, &args.join(" ")])
+        );
+    }
+}
+
+fn install_systemd(exe: &str, cwd: &str, at: NaiveTime, days: &[usize]) -> Result<PathBuf> {
+    let dir = systemd_dir()?;
+    fs::create_dir_all(&dir).with_context(|| format!("could not create `{}`", dir.display()))?;
+
+    // The database path may be relative, so run from where the reminder was set up.
+    let service = format!(
+        "[Unit]\nDescription=lazarus training reminder\n\n[Service]\nType=oneshot\nWorkingDirectory={}\nExecStart=\"{}\" remind --check\n",
+        cwd, exe
+    );
+    let on_calendar = days.iter().map(|&i| DAYS[i].1).collect::<Vec<_>>().join(",");
+    let timer = format!(
+        "[Unit]\nDescription=lazarus training reminder\n\n[Timer]\nOnCalendar={} *-*-* {}\n\n[Install]\nWantedBy=timers.target\n",
+        on_calendar,
+        at.format("%H:%M:00")
+    );
+    fs::write(dir.join(format!("{}.service", UNIT)), service)?;
+    let timer_path = dir.join(format!("{}.timer", UNIT));
+    fs::write(&timer_path, timer)?;
+
+    let timer_unit = format!("{}.timer", UNIT);
+    run("systemctl", &["--user", "daemon-reload"]);
+    run("systemctl", &["--user", "enable", "--now", timer_unit.as_str()]);
+    Ok(timer_path)
+}
+
+fn install_launchd(exe: &str, cwd: &str, at: NaiveTime, days: &[usize]) -> Result<PathBuf> {
+    let path = launchd_plist()?;
+    if let Some(dir) = path.parent() {
+        fs::create_dir_all(dir).with_context(|| format!("could not create `{}`", dir.display()))?;
+    }
+
+    let intervals: String = days
+        .iter()
+        .map(|&i| {
+            format!(
+                "    <dict><key>Weekday</key><integer>{}</integer><key>Hour</key><integer>{}</integer><key>Minute</key><integer>{}</integer></dict>\n",
+                DAYS[i].2,
+                at.hour(),
+                at.minute()
+            )
+        })
+        .collect();
+    let plist = format!(
+        r#"<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Label</key><string>{label}</string>
+  <key>ProgramArguments</key>
+  <array><string>{exe}</string><string>remind</string><string>--check</string></array>
+  <key>WorkingDirectory</key><string>{cwd}</string>
+  <key>StartCalendarInterval</key>
+  <array>
+{intervals}  </array>
+</dict>
+</plist>
+"#,
+        label = LAUNCHD_LABEL,
+        exe = escape_html(exe),
+        cwd = escape_html(cwd),
+        intervals = intervals
+    );
+    fs::write(&path, plist)?;
+
+    let path_str = path.to_string_lossy().into_owned();
+    // Loading twice fails, so drop a previous version first.
+    let _ = Command::new("launchctl").args(["unload", path_str.as_str()]).output();
+    run("launchctl", &["load", "-w", path_str.as_str()]);
+    Ok(path)
+}
+
+fn uninstall() -> Result<bool> {
+    if cfg!(target_os = "macos") {
+        let path = launchd_plist()?;
+        if !path.exists() {
+            return Ok(false);
+        }
+        let path_str = path.to_string_lossy().into_owned();
+        run("launchctl", &["unload", "-w", path_str.as_str()]);
+        fs::remove_file(&path)?;
+    } else {
+        let dir = systemd_dir()?;
+        let timer = dir.join(format!("{}.timer", UNIT));
+        if !timer.exists() {
+            return Ok(false);
+        }
+        let timer_unit = format!("{}.timer", UNIT);
+        run("systemctl", &["--user", "disable", "--now", timer_unit.as_str()]);
+        fs::remove_file(&timer)?;
+        let _ = fs::remove_file(dir.join(format!("{}.service", UNIT)));
+        run("systemctl", &["--user", "daemon-reload"]);
+    }
+    Ok(true)
+}
+
+/// Desktop notification, falling back to stdout (the service log) without a notifier.
+fn notify(body: &str) {
+    let sent = if cfg!(target_os = "macos") {
+        let script = format!(
+            "display notification \"{}\" with title \"lazarus\"",
+            body.replace('\\', "\\\\").replace('"', "\\\"")
+        );
+        Command::new("osascript").args(["-e", script.as_str()]).status()
+    } else {
+        Command::new("notify-send").args(["lazarus", body]).status()
+    };
+    if !sent.is_ok_and(|s| s.success()) {
+        println!("{}", body);
+    }
+}
+
+/// What the timer runs: notify unless a session was started today, today is a
+/// rest day, or every program is done for the week.
+async fn check(pool: &SqlitePool) -> Result<()> {
+    let today = chrono::Local::now().date_naive().format("%Y-%m-%d").to_string();
+    let (started, resting): (bool, bool) = sqlx::query_as(
+        r#"
+        SELECT EXISTS(SELECT 1 FROM training_sessions WHERE date(start_time, 'localtime') = ?1),
+               EXISTS(SELECT 1 FROM rest_days WHERE date = ?1)
+        "#,
+    )
+    .bind(&today)
+    .fetch_one(pool)
+    .await?;
+    if started || resting {
+        return Ok(());
+    }
+
+    let programs: Vec<(String, String)> = sqlx::query_as(
+        r#"
+        SELECT DISTINCT p.id, p.name
+        FROM programs p
+        JOIN program_blocks pb ON pb.program_id = p.id
+        JOIN training_sessions ts ON ts.program_block_id = pb.id
+        ORDER BY p.name
+        "#,
+    )
+    .fetch_all(pool)
+    .await?;
+    if programs.is_empty() {
+        notify(tr("No session started yet today."));
+        return Ok(());
+    }
+
+    for (id, name) in programs {
+        let (_, pending) = pending_blocks(pool, &id).await?;
+        if let Some(block) = pending.first() {
+            notify(&tf("No session started yet today. Next up: {} ({}).", &[&block, &name]));
+            return Ok(());
+        }
+    }
+
+    Ok(())
+}
+
+pub async fn handle(
+    pool: &SqlitePool,
+    at: Option<String>,
+    days: Option<String>,
+    remove: bool,
+    check_now: bool,
+) -> Result<()> {
+    if check_now {
+        return check(pool).await;
+    }
+
+    if remove {
+        if uninstall()? {
+            ui::ok(tr("reminder removed"));
+        } else {
+            ui::info(tr("no reminder installed"));
+        }
+        return Ok(());
+    }
+
+    let Some(at) = at else {
+        return Err(AppError::Invalid(tr("pass `--at <HH:MM>`, e.g. `remind --at 18:00 --days mon,wed,fri`").into()).into());
+    };
+    let Ok(time) = NaiveTime::parse_from_str(&at, "%H:%M") else {
+        return Err(AppError::Invalid(tf("invalid time `{}` (expected HH:MM)", &[&at])).into());
+    };
+    let Some(days) = parse_days(days.as_deref()) else {
+        return Err(AppError::Invalid(tf("invalid days `{}` (expected e.g. mon,wed,fri)", &[&days.unwrap_or_default()])).into());
+    };
+
+    let exe = std::env::current_exe()?.to_string_lossy().into_owned();
+    let cwd = std::env::current_dir()?.to_string_lossy().into_owned();
+    let path = if cfg!(target_os = "macos") {
+        install_launchd(&exe, &cwd, time, &days)?
+    } else {
+        install_systemd(&exe, &cwd, time, &days)?
+    };
+
+    let day_list = days.iter().map(|&i| DAYS[i].0).collect::<Vec<_>>().join(", ");
+    ui::ok(tf("reminder set for {} on {} ({})", &[&time.format("%H:%M"), &day_list, &path.display()]));
+
+    Ok(())
+}
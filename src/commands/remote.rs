@@ -0,0 +1,104 @@
+//! Session commands against another machine's database: with `remote` set,
+//! they go to that machine's `metrics` server as a `run` on `/rpc`, so a
+//! session started on one machine is carried on from another.
+
+use anyhow::{Context, Result, anyhow};
+use serde_json::{Value, json};
+
+use crate::{
+    cli::{Commands, ShowCmd},
+    errors::AppError,
+    i18n::tf,
+};
+
+/// Whether `cmd` acts on a session, and so runs where the session lives.
+pub fn forwards(cmd: &Option<Commands>) -> bool {
+    matches!(
+        cmd,
+        Some(
+            Commands::Session(_)
+                | Commands::Pause { .. }
+                | Commands::Resume { .. }
+                | Commands::MoveEx { .. }
+                | Commands::RemoveEx { .. }
+                | Commands::Show(ShowCmd::Session { .. })
+        )
+    )
+}
+
+/// The error the remote's exit `code` stands for, with its message.
+fn remote_error(code: i64, message: String) -> anyhow::Error {
+    match code {
+        2 => AppError::NotFound(message).into(),
+        3 => AppError::Invalid(message).into(),
+        4 => AppError::NoActiveSession.into(),
+        _ => anyhow!(message),
+    }
+}
+
+/// What a `run` reply means here: the output to print and the error to fail
+/// with, if any.
+fn reply(body: &str) -> Result<(String, Option<anyhow::Error>)> {
+    let reply: Value = serde_json::from_str(body).context("the remote didn't answer with JSON-RPC")?;
+    if let Some(message) = reply.pointer("/error/message").and_then(Value::as_str) {
+        return Ok((String::new(), Some(AppError::Invalid(message.to_string()).into())));
+    }
+    let result = &reply["result"];
+    let output = result["output"].as_str().unwrap_or_default().to_string();
+    let error = match result["code"].as_i64() {
+        Some(0) => None,
+        code => {
+            let message = result["error"].as_str().unwrap_or("the remote command failed").to_string();
+            Some(remote_error(code.unwrap_or(1), message))
+        }
+    };
+    Ok((output, error))
+}
+
+/// Run the CLI `args` (without the program name) on the `remote` server,
+/// printing what the command printed there and failing as it failed.
+pub async fn run(url: &str, token: Option<&str>, args: &[String]) -> Result<()> {
+    let endpoint = format!("{}/rpc", url.trim_end_matches('/'));
+    let body = json!({ "jsonrpc": "2.0", "id": 1, "method": "run", "params": { "args": args } });
+
+    let mut req = reqwest::Client::new().post(&endpoint).json(&body);
+    if let Some(token) = token {
+        req = req.bearer_auth(token);
+    }
+    let res = req
+        .send()
+        .await
+        .with_context(|| tf("could not reach the remote `{}` (`config unset remote` to log locally)", &[&url]))?;
+    let status = res.status();
+    let text = res.text().await?;
+    if !status.is_success() {
+        return Err(anyhow!("{} {}: {}", endpoint, status.as_u16(), text.trim()));
+    }
+
+    let (output, error) = reply(&text)?;
+    print!("{}", output);
+    match error {
+        Some(e) => Err(e),
+        None => Ok(()),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn replies_keep_the_remote_exit_code() {
+        let (output, error) = reply(r#"{"jsonrpc":"2.0","id":1,"result":{"code":0,"output":"Session: A\n"}}"#).unwrap();
+        assert_eq!((output.as_str(), error.is_none()), ("Session: A\n", true));
+
+        let (_, error) = reply(r#"{"jsonrpc":"2.0","id":1,"result":{"code":4,"output":"","error":"no active session"}}"#).unwrap();
+        let error = error.unwrap();
+        assert_eq!(error.downcast_ref::<AppError>().map(AppError::exit_code), Some(4));
+
+        let (_, error) = reply(r#"{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"waits on a terminal"}}"#).unwrap();
+        assert_eq!(error.unwrap().to_string(), "waits on a terminal");
+
+        assert!(reply("<html>").is_err());
+    }
+}
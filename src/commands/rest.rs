@@ -0,0 +1,185 @@
+use std::collections::BTreeSet;
+
+use anyhow::Result;
+use chrono::{Datelike, Duration, NaiveDate};
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    dates::{DATE_FORMATS, parse_date},
+    errors::AppError,
+    i18n::{short_date, tf, tr},
+    ui::{self, Themed},
+};
+
+/// Monday of the week `d` falls in.
+pub fn monday(d: NaiveDate) -> NaiveDate {
+    d - Duration::days(d.weekday().num_days_from_monday() as i64)
+}
+
+/// Weeks (by their Monday) with a finished session, and weeks with a rest day.
+pub async fn load_weeks(pool: &SqlitePool) -> Result<(BTreeSet<NaiveDate>, BTreeSet<NaiveDate>)> {
+    let trained: Vec<String> =
+        sqlx::query_scalar("SELECT DISTINCT date(start_time) FROM training_sessions WHERE end_time IS NOT NULL")
+            .fetch_all(pool)
+            .await?;
+    let rested: Vec<String> = sqlx::query_scalar("SELECT date FROM rest_days")
+        .fetch_all(pool)
+        .await?;
+
+    let weeks = |days: Vec<String>| {
+        days.iter()
+            .filter_map(|d| NaiveDate::parse_from_str(d, "%Y-%m-%d").ok())
+            .map(monday)
+            .collect::<BTreeSet<_>>()
+    };
+
+    Ok((weeks(trained), weeks(rested)))
+}
+
+/// Longest week streak between `from` and `to`. A trained week extends the
+/// streak, a week with a planned rest day keeps it alive without counting,
+/// any other week breaks it.
+pub fn longest_streak(trained: &BTreeSet<NaiveDate>, rested: &BTreeSet<NaiveDate>, from: NaiveDate, to: NaiveDate) -> i64 {
+    let (mut best, mut run) = (0, 0);
+    let mut week = monday(from);
+
+    while week <= to {
+        if trained.contains(&week) {
+            run += 1;
+            best = best.max(run);
+        } else if !rested.contains(&week) {
+            run = 0;
+        }
+        week += Duration::weeks(1);
+    }
+
+    best
+}
+
+/// Streak ending this week, or last week so it survives until this one is over.
+pub fn current_streak(trained: &BTreeSet<NaiveDate>, rested: &BTreeSet<NaiveDate>, today: NaiveDate) -> i64 {
+    let Some(first) = trained.first() else {
+        return 0;
+    };
+
+    let mut week = monday(today);
+    if !trained.contains(&week) {
+        week -= Duration::weeks(1);
+    }
+
+    let mut streak = 0;
+    while week >= *first {
+        if trained.contains(&week) {
+            streak += 1;
+        } else if !rested.contains(&week) {
+            break;
+        }
+        week -= Duration::weeks(1);
+    }
+
+    streak
+}
+
+/// Record `days` rest days starting at `from`, keeping any already there.
+pub async fn mark_rest_days(pool: &SqlitePool, from: NaiveDate, days: i64, reason: &str) -> Result<()> {
+    for i in 0..days {
+        sqlx::query("INSERT OR IGNORE INTO rest_days (date, reason, created_at) VALUES (?, ?, datetime('now'))")
+            .bind((from + Duration::days(i)).format("%Y-%m-%d").to_string())
+            .bind(reason)
+            .execute(pool)
+            .await?;
+    }
+
+    Ok(())
+}
+
+pub async fn handle(
+    pool: &SqlitePool,
+    date: Option<String>,
+    reason: Option<String>,
+    week: bool,
+    remove: bool,
+    list: bool,
+) -> Result<()> {
+    if list {
+        let rows: Vec<(String, Option<String>)> = sqlx::query_as(
+            "SELECT date, reason FROM rest_days WHERE date >= date('now', '-30 days') ORDER BY date",
+        )
+        .fetch_all(pool)
+        .await?;
+
+        println!("{}", tr("Rest days:").heading().bold());
+        if rows.is_empty() {
+            println!("{}", tr("  (none in the last 30 days)").dimmed());
+        }
+        for (d, reason) in rows {
+            let shown = NaiveDate::parse_from_str(&d, "%Y-%m-%d").map(short_date).unwrap_or(d);
+            println!("  • {} {}", shown.accent(), reason.unwrap_or_default().dimmed());
+        }
+        return Ok(());
+    }
+
+    let Some(day) = parse_date(date.as_deref()) else {
+        return Err(AppError::Invalid(tf("invalid date `{}` (expected {})", &[&date.unwrap_or_default(), &DATE_FORMATS]).into()).into());
+    };
+    let (from, days) = if week { (monday(day), 7) } else { (day, 1) };
+
+    if remove {
+        let res = sqlx::query("DELETE FROM rest_days WHERE date BETWEEN ? AND ?")
+            .bind(from.format("%Y-%m-%d").to_string())
+            .bind((from + Duration::days(days - 1)).format("%Y-%m-%d").to_string())
+            .execute(pool)
+            .await?;
+        ui::ok(tf("removed {} rest day(s)", &[&res.rows_affected()]));
+        return Ok(());
+    }
+
+    for i in 0..days {
+        sqlx::query(
+            r#"
+            INSERT INTO rest_days (date, reason, created_at) VALUES (?, ?, datetime('now'))
+            ON CONFLICT (date) DO UPDATE SET reason = excluded.reason
+            "#,
+        )
+        .bind((from + Duration::days(i)).format("%Y-%m-%d").to_string())
+        .bind(&reason)
+        .execute(pool)
+        .await?;
+    }
+
+    if week {
+        ui::ok(tf("week of {} marked as rest", &[&short_date(from)]));
+    } else {
+        ui::ok(tf("{} marked as a rest day", &[&short_date(from)]));
+    }
+
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn weeks(days: &[&str]) -> BTreeSet<NaiveDate> {
+        days.iter().map(|d| monday(NaiveDate::parse_from_str(d, "%Y-%m-%d").unwrap())).collect()
+    }
+
+    #[test]
+    fn streak_counts_back_from_last_week_until_a_gap() {
+        let today = NaiveDate::from_ymd_opt(2024, 1, 24).unwrap();
+        let trained = weeks(&["2024-01-01", "2024-01-08", "2024-01-16"]);
+        assert_eq!(current_streak(&trained, &BTreeSet::new(), today), 3);
+        let trained = weeks(&["2024-01-01", "2024-01-16"]);
+        assert_eq!(current_streak(&trained, &BTreeSet::new(), today), 1);
+    }
+
+    #[test]
+    fn rest_weeks_keep_the_streak_alive() {
+        let today = NaiveDate::from_ymd_opt(2024, 1, 24).unwrap();
+        let trained = weeks(&["2024-01-01", "2024-01-16", "2024-01-23"]);
+        let rested = weeks(&["2024-01-10"]);
+        assert_eq!(current_streak(&trained, &rested, today), 3);
+        assert_eq!(current_streak(&BTreeSet::new(), &rested, today), 0);
+    }
+}
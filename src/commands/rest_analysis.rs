@@ -0,0 +1,253 @@
+use anyhow::Result;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::compare::resolve_session,
+    errors::AppError,
+    i18n::{display_db_date, tf, tr},
+    types::{OutputFmt, SET_LOAD, emit},
+    ui::Themed,
+};
+
+/// Longer gaps are a break (or a forgotten set), not rest.
+const MAX_REST_SECS: i64 = 900;
+
+#[derive(Serialize)]
+struct ExerciseRest {
+    exercise: String,
+    superset: Option<String>,
+    sets: usize,
+    /// Seconds between consecutive sets of this exercise.
+    rests: Vec<i64>,
+    avg_rest_secs: Option<f64>,
+    target_rest_secs: Option<i64>,
+    tonnage_kg: f64,
+    /// Tonnage per minute from its first to its last set.
+    density_kg_min: Option<f64>,
+}
+
+#[derive(Serialize)]
+struct SessionRest {
+    session_id: String,
+    block: String,
+    start_time: String,
+    exercises: Vec<ExerciseRest>,
+    working_secs: i64,
+    tonnage_kg: f64,
+    density_kg_min: Option<f64>,
+    /// Share of rests within range of their target, when any exercise has one.
+    on_target_pct: Option<f64>,
+}
+
+fn mmss(secs: f64) -> String {
+    let secs = secs.round().max(0.0) as i64;
+    format!("{}:{:02}", secs / 60, secs % 60)
+}
+
+/// A rest counts as on target within 15 s or 15% of the prescription.
+fn on_target(rest: i64, target: i64) -> bool {
+    (rest - target).abs() as f64 <= 15f64.max(target as f64 * 0.15)
+}
+
+fn per_minute(tonnage: f64, secs: i64) -> Option<f64> {
+    (secs > 0).then(|| tonnage / (secs as f64 / 60.0))
+}
+
+async fn load(pool: &SqlitePool, session_id: &str) -> Result<SessionRest> {
+    let (block, start_time): (String, String) = sqlx::query_as(
+        r#"
+        SELECT pb.name, ts.start_time
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        WHERE ts.id = ?
+        "#,
+    )
+    .bind(session_id)
+    .fetch_one(pool)
+    .await?;
+
+    let rows: Vec<(String, String, Option<i64>, Option<String>, i64, f64, i64)> = sqlx::query_as(&format!(
+        r#"
+        SELECT tse.id, e.name, pe.rest_secs, pe.superset,
+               CAST(strftime('%s', es.timestamp) AS INTEGER),
+               CAST({load} AS REAL), es.reps
+        FROM training_session_exercises tse
+        JOIN training_sessions ts ON ts.id = tse.training_session_id
+        JOIN exercises e ON e.id = tse.exercise_id
+        JOIN exercise_sets es ON es.session_exercise_id = tse.id
+        LEFT JOIN program_exercises pe
+          ON pe.program_block_id = ts.program_block_id
+         AND pe.exercise_id = COALESCE(tse.rotated_from, tse.exercise_id)
+        WHERE tse.training_session_id = ?
+        AND es.status != 'skipped'
+        ORDER BY tse.order_index, es.set_index
+        "#,
+        load = SET_LOAD
+    ))
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?;
+
+    // (session exercise id, first/last set time, exercise)
+    let mut groups: Vec<(String, i64, i64, ExerciseRest)> = Vec::new();
+    for (tse_id, name, target, superset, at, weight, reps) in rows {
+        let tonnage = weight * reps as f64;
+        match groups.iter_mut().find(|(id, ..)| *id == tse_id) {
+            Some((_, _, last, ex)) => {
+                let gap = at - *last;
+                if gap > 0 && gap < MAX_REST_SECS {
+                    ex.rests.push(gap);
+                }
+                *last = at;
+                ex.sets += 1;
+                ex.tonnage_kg += tonnage;
+            }
+            None => groups.push((
+                tse_id,
+                at,
+                at,
+                ExerciseRest {
+                    exercise: name,
+                    superset,
+                    sets: 1,
+                    rests: Vec::new(),
+                    avg_rest_secs: None,
+                    target_rest_secs: target,
+                    tonnage_kg: tonnage,
+                    density_kg_min: None,
+                },
+            )),
+        }
+    }
+
+    let first = groups.iter().map(|(_, first, ..)| *first).min().unwrap_or_default();
+    let last = groups.iter().map(|(_, _, last, _)| *last).max().unwrap_or_default();
+
+    let mut checked = 0;
+    let mut hits = 0;
+    let exercises: Vec<ExerciseRest> = groups
+        .into_iter()
+        .map(|(_, first, last, mut ex)| {
+            if !ex.rests.is_empty() {
+                ex.avg_rest_secs = Some(ex.rests.iter().sum::<i64>() as f64 / ex.rests.len() as f64);
+            }
+            if let Some(target) = ex.target_rest_secs {
+                checked += ex.rests.len();
+                hits += ex.rests.iter().filter(|&&r| on_target(r, target)).count();
+            }
+            ex.density_kg_min = per_minute(ex.tonnage_kg, last - first);
+            ex
+        })
+        .collect();
+
+    let tonnage_kg = exercises.iter().map(|e| e.tonnage_kg).sum();
+    Ok(SessionRest {
+        session_id: session_id.to_string(),
+        block,
+        start_time,
+        exercises,
+        working_secs: last - first,
+        tonnage_kg,
+        density_kg_min: per_minute(tonnage_kg, last - first),
+        on_target_pct: (checked > 0).then(|| hits as f64 * 100.0 / checked as f64),
+    })
+}
+
+pub async fn handle(pool: &SqlitePool, session: Option<String>, fmt: OutputFmt) -> Result<()> {
+    let session_id = match session {
+        Some(id) => resolve_session(pool, &id).await?,
+        None => sqlx::query_scalar("SELECT id FROM training_sessions ORDER BY start_time DESC LIMIT 1")
+            .fetch_optional(pool)
+            .await?
+            .ok_or_else(|| AppError::NotFound(tr("no sessions yet").into()))?,
+    };
+
+    let report = load(pool, &session_id).await?;
+    if report.exercises.is_empty() {
+        return Err(AppError::NotFound(tr("no sets logged in this session").into()).into());
+    }
+
+    emit(fmt, &report, || {
+        println!(
+            "{} {} {}",
+            tr("Rest:").heading().bold(),
+            report.block.bold(),
+            format!("({})", display_db_date(&report.start_time)).dimmed()
+        );
+        println!(
+            "  {:<22} {:>4} {:>8} {:>11} {:>14} {:>11}",
+            "",
+            tr("sets"),
+            tr("avg rest"),
+            tr("range"),
+            tr("target"),
+            tr("density")
+        );
+        for ex in &report.exercises {
+            let name = match &ex.superset {
+                Some(group) => format!("{} [{}]", ex.exercise, group),
+                None => ex.exercise.clone(),
+            };
+            let avg = ex.avg_rest_secs.map(mmss).unwrap_or_else(|| "–".into());
+            let range = match (ex.rests.iter().min(), ex.rests.iter().max()) {
+                (Some(&lo), Some(&hi)) => format!("{}–{}", mmss(lo as f64), mmss(hi as f64)),
+                _ => "–".into(),
+            };
+            // Padded before coloring so the escape codes don't break the columns.
+            let target = match (ex.target_rest_secs, ex.avg_rest_secs) {
+                (Some(t), Some(avg)) => {
+                    let text = format!("{:>14}", format!("{} ({:+.0}s)", mmss(t as f64), avg - t as f64));
+                    if on_target(avg.round() as i64, t) {
+                        text.good().to_string()
+                    } else if avg > t as f64 {
+                        text.bad().to_string()
+                    } else {
+                        text.accent().to_string()
+                    }
+                }
+                (Some(t), None) => format!("{:>14}", mmss(t as f64)),
+                (None, _) => format!("{:>14}", "–").dimmed().to_string(),
+            };
+            let density = ex
+                .density_kg_min
+                .map(|d| format!("{:.0} kg/min", d))
+                .unwrap_or_else(|| "–".into());
+            println!(
+                "  {:<22} {:>4} {:>8} {:>11} {} {:>11}",
+                name.bold(),
+                ex.sets,
+                avg,
+                range,
+                target,
+                density
+            );
+        }
+
+        println!(
+            "{} {}",
+            tr("Density:").heading().bold(),
+            tf(
+                "{} kg in {} of working time ({})",
+                &[
+                    &format!("{:.0}", report.tonnage_kg),
+                    &mmss(report.working_secs as f64),
+                    &report
+                        .density_kg_min
+                        .map(|d| format!("{:.0} kg/min", d))
+                        .unwrap_or_else(|| "–".into()),
+                ]
+            )
+        );
+        if let Some(pct) = report.on_target_pct {
+            println!(
+                "{} {}",
+                tr("On target:").heading().bold(),
+                tf("{}% of rests within 15s (or 15%) of the program", &[&format!("{:.0}", pct)])
+            );
+        }
+    });
+
+    Ok(())
+}
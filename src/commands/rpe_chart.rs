@@ -0,0 +1,117 @@
+use anyhow::Result;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::{
+        session::{rounding_for, rpe_percent},
+        test_1rm::current_max,
+    },
+    errors::AppError,
+    i18n::{tf, tr},
+    types::{OutputFmt, RoundingRules, emit},
+    ui::Themed,
+};
+
+const RPES: [f32; 8] = [10.0, 9.5, 9.0, 8.5, 8.0, 7.5, 7.0, 6.5];
+const MAX_REPS: u32 = 10;
+
+#[derive(Serialize)]
+struct Row {
+    reps: u32,
+    /// Load per RPE in `RPES` order, `None` past the end of the chart.
+    kg: Vec<Option<f32>>,
+}
+
+#[derive(Serialize)]
+struct Chart {
+    exercise: String,
+    /// The max every load is a percentage of.
+    max_kg: f32,
+    source: &'static str,
+    rpes: Vec<f32>,
+    rows: Vec<Row>,
+}
+
+/// Highest training max any program block uses for the exercise.
+async fn training_max(pool: &SqlitePool, exercise_id: &str) -> Result<Option<f32>> {
+    Ok(sqlx::query_scalar("SELECT MAX(program_1rm) FROM program_exercises WHERE exercise_id = ?")
+        .bind(exercise_id)
+        .fetch_one(pool)
+        .await?)
+}
+
+pub async fn handle(
+    pool: &SqlitePool,
+    exercise: String,
+    tm: bool,
+    from: Option<f32>,
+    rules: RoundingRules,
+    fmt: OutputFmt,
+) -> Result<()> {
+    let found: Option<(String, String)> =
+        sqlx::query_as("SELECT id, name FROM exercises WHERE name = ? COLLATE NOCASE OR CAST(idx AS TEXT) = ?")
+            .bind(&exercise)
+            .bind(&exercise)
+            .fetch_optional(pool)
+            .await?;
+    let Some((exercise_id, name)) = found else {
+        return Err(AppError::ExerciseNotFound(exercise).into());
+    };
+    let rounding = rounding_for(pool, &rules, &exercise_id).await?;
+
+    let (max, source) = match from {
+        Some(kg) => (Some(kg), "given"),
+        None if tm => (training_max(pool, &exercise_id).await?, "training max"),
+        None => (current_max(pool, &exercise_id).await?, "e1RM"),
+    };
+    let Some(max) = max.filter(|m| *m > 0.0) else {
+        let msg = if tm {
+            tf("no program sets a training max for `{}`", &[&name])
+        } else {
+            tf("no e1RM for `{}` yet, pass `--from <kg>` to use a guess", &[&name])
+        };
+        return Err(AppError::NotFound(msg).into());
+    };
+
+    let rows: Vec<Row> = (1..=MAX_REPS)
+        .map(|reps| Row {
+            reps,
+            kg: RPES
+                .iter()
+                .map(|&rpe| rpe_percent(reps, rpe).map(|pct| rounding.round(max * pct / 100.0)))
+                .collect(),
+        })
+        .collect();
+    let chart = Chart {
+        exercise: name,
+        max_kg: max,
+        source,
+        rpes: RPES.to_vec(),
+        rows,
+    };
+
+    emit(fmt, &chart, || {
+        println!(
+            "{} {}",
+            tr("RPE chart:").heading().bold(),
+            tf("{} ({} {})", &[&chart.exercise.bold(), &tr(chart.source), &rounding.format(max)])
+        );
+        let header: String = RPES.iter().map(|rpe| format!("{:>8}", format!("@{}", rpe))).collect();
+        println!("  {:>4}{}", tr("reps").dimmed(), header.dimmed());
+        for row in &chart.rows {
+            let cells: String = row
+                .kg
+                .iter()
+                .map(|kg| match kg {
+                    Some(kg) => format!("{:>8}", (kg * 100.0).round() / 100.0),
+                    None => format!("{:>8}", "–"),
+                })
+                .collect();
+            println!("  {:>4}{}", row.reps.to_string().accent(), cells);
+        }
+    });
+
+    Ok(())
+}
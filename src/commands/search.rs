@@ -0,0 +1,186 @@
+use anyhow::Result;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{errors::AppError, i18n::tr, types::{OutputFmt, emit}, ui::Themed};
+
+#[derive(Serialize)]
+struct SearchHit {
+    kind: String,
+    title: String,
+    context: String,
+    jump: String,
+}
+
+// Match markers handed to `snippet()`, swapped for colors when printing.
+const HL_START: &str = "\u{1}";
+const HL_END: &str = "\u{2}";
+
+/// Refill the FTS table from every searchable column. Jumps are commands to
+/// paste into a shell: exercises go by index, and program names are single
+/// quoted (with `'` written as `'\''`) so nothing in them is expanded.
+async fn rebuild_index(pool: &SqlitePool) -> Result<()> {
+    let mut tx = pool.begin().await?;
+
+    sqlx::query("DELETE FROM search_index").execute(&mut *tx).await?;
+
+    let inserts = [
+        r#"
+        INSERT INTO search_index (kind, title, body, jump)
+        SELECT 'exercise', name, COALESCE(description, ''),
+               'lazarus ex show ' || idx
+        FROM exercises
+        "#,
+        r#"
+        INSERT INTO search_index (kind, title, body, jump)
+        SELECT 'program', name, COALESCE(description, ''),
+               'lazarus p show ''' || replace(name, '''', '''\''''') || ''''
+        FROM programs
+        "#,
+        r#"
+        INSERT INTO search_index (kind, title, body, jump)
+        SELECT 'block', p.name || ' / ' || pb.name, COALESCE(pb.description, ''),
+               'lazarus p show ''' || replace(p.name, '''', '''\''''') || ''''
+        FROM program_blocks pb
+        JOIN programs p ON p.id = pb.program_id
+        "#,
+        r#"
+        INSERT INTO search_index (kind, title, body, jump)
+        SELECT 'program-note', p.name || ' / ' || pb.name || ' / ' || e.name, pe.notes,
+               'lazarus p show ''' || replace(p.name, '''', '''\''''') || ''''
+        FROM program_exercises pe
+        JOIN program_blocks pb ON pb.id = pe.program_block_id
+        JOIN programs p ON p.id = pb.program_id
+        JOIN exercises e ON e.id = pe.exercise_id
+        WHERE COALESCE(pe.notes, '') <> ''
+        "#,
+        r#"
+        INSERT INTO search_index (kind, title, body, jump)
+        SELECT 'session-note', date(ts.start_time) || ' · ' || pb.name, ts.notes,
+               CASE WHEN ts.end_time IS NULL THEN 'lazarus s show'
+                    ELSE 'lazarus s log -d ' || strftime('%d-%m-%Y', ts.start_time) END
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        WHERE COALESCE(ts.notes, '') <> ''
+        "#,
+        r#"
+        INSERT INTO search_index (kind, title, body, jump)
+        SELECT 'session-note', date(ts.start_time) || ' · ' || e.name, tse.notes,
+               CASE WHEN ts.end_time IS NULL THEN 'lazarus s show'
+                    ELSE 'lazarus s log -d ' || strftime('%d-%m-%Y', ts.start_time) END
+        FROM training_session_exercises tse
+        JOIN training_sessions ts ON ts.id = tse.training_session_id
+        JOIN exercises e ON e.id = tse.exercise_id
+        WHERE COALESCE(tse.notes, '') <> ''
+        "#,
+    ];
+    for q in inserts {
+        sqlx::query(q).execute(&mut *tx).await?;
+    }
+
+    tx.commit().await?;
+    Ok(())
+}
+
+/// Turn free text into an FTS5 query: every word must appear, as a prefix.
+/// Quoting each word keeps things like `3-1-1-0` from being read as syntax.
+fn fts_query(words: &[String]) -> String {
+    words
+        .iter()
+        .flat_map(|w| w.split_whitespace())
+        .map(|w| format!("\"{}\"*", w.replace('"', "\"\"")))
+        .collect::<Vec<_>>()
+        .join(" ")
+}
+
+pub async fn handle(pool: &SqlitePool, query: Vec<String>, limit: u32, fmt: OutputFmt) -> Result<()> {
+    let q = fts_query(&query);
+    if q.is_empty() {
+        return Err(AppError::Invalid(tr("nothing to search for").into()).into());
+    }
+
+    rebuild_index(pool).await?;
+
+    let rows = sqlx::query_as::<_, (String, String, String, String)>(
+        r#"
+        SELECT kind, title, snippet(search_index, -1, ?, ?, '…', 10), jump
+        FROM search_index
+        WHERE search_index MATCH ?
+        ORDER BY rank
+        LIMIT ?
+        "#,
+    )
+    .bind(HL_START)
+    .bind(HL_END)
+    .bind(&q)
+    .bind(limit as i64)
+    .fetch_all(pool)
+    .await?;
+
+    let hits: Vec<SearchHit> = rows
+        .into_iter()
+        .map(|(kind, title, context, jump)| SearchHit {
+            kind,
+            title,
+            context,
+            jump,
+        })
+        .collect();
+
+    emit(fmt, &hits, || {
+        if hits.is_empty() {
+            println!("{}", "  (no matches)".dimmed());
+            return;
+        }
+
+        println!("{} {}", tr("Matches:").heading().bold(), hits.len());
+        for h in &hits {
+            let context = h
+                .context
+                .split(HL_START)
+                .enumerate()
+                .map(|(i, part)| match (i, part.split_once(HL_END)) {
+                    (0, _) | (_, None) => part.to_string(),
+                    (_, Some((hit, rest))) => format!("{}{}", hit.accent().bold(), rest),
+                })
+                .collect::<String>();
+
+            println!(" {} • {}", format!("[{}]", h.kind).accent(), h.title.bold());
+            if !context.trim().is_empty() {
+                println!("     {}", context);
+            }
+            println!("     {} {}", "→".info(), h.jump.dimmed());
+        }
+    });
+
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::testutil::{self, memory_db};
+
+    #[tokio::test]
+    async fn jumps_are_safe_to_paste() {
+        let pool = memory_db().await;
+        testutil::exercise(&pool, "Farmer's \"Walk\" $(reboot)", "grip").await;
+        testutil::program_block(&pool, "Bob's `whoami`", "Day A", &[], "5").await;
+        rebuild_index(&pool).await.unwrap();
+
+        let jumps: Vec<(String, String)> = sqlx::query_as("SELECT kind, jump FROM search_index ORDER BY kind")
+            .fetch_all(&pool)
+            .await
+            .unwrap();
+        let idx: i64 = sqlx::query_scalar("SELECT idx FROM exercises").fetch_one(&pool).await.unwrap();
+        assert_eq!(
+            jumps,
+            [
+                ("block".into(), r"lazarus p show 'Bob'\''s `whoami`'".into()),
+                ("exercise".into(), format!("lazarus ex show {}", idx)),
+                ("program".into(), r"lazarus p show 'Bob'\''s `whoami`'".into()),
+            ]
+        );
+    }
+}
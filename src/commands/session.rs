@@ -3,54 +3,160 @@ use colored::Colorize;
 use sqlx::SqlitePool;
 use std::collections::HashMap;
 use uuid::Uuid;
-use chrono::NaiveDate;
 
-use crate::cli::SessionCmd;
+use crate::{
+    cli::{SessionCmd, Side},
+    commands::{
+        attach::print_attachments,
+        circuit::{self, print_circuits},
+        compare::{previous_of_block, print_comparison},
+        equip::settings_line,
+        exercise::similar_names,
+        gym::{equipment_of, load_gym, swap_hint},
+        points::current_bodyweight,
+        program::{autofill_1rm, resolve_program, substitution_groups, superset_labels, swap_count},
+        top_set, vs_target, wal,
+        week::advance_after_session,
+    },
+    dates::{DATE_FORMATS, parse_date},
+    errors::AppError,
+    formula,
+    i18n::{display_db_date, kg, kg_places, number, short_date, tf, tr},
+    storage::SessionStore,
+    types::{
+        Accommodating, Config, RepTarget, Rounding, RoundingRules, SetStatus, band_tension_kg, best_muscle_suggestions, SET_FLAGS, bodyweight_label,
+        cannonical_muscle,
+    },
+    ui::{self, Themed},
+};
+
+/// The open session other commands act on. Fails with
+/// [`AppError::NoActiveSession`] when there is none, like
+/// [`select_session`] when the tag doesn't pick one.
+pub async fn active_session(store: &impl SessionStore, tag: Option<&str>) -> Result<String> {
+    select_session(store, tag).await?.ok_or_else(|| AppError::NoActiveSession.into())
+}
+
+/// "<date> HH:MM" of a session start, with the date as `dates` asks for.
+fn started_at(start_time: &str) -> String {
+    format!("{} {}", display_db_date(start_time), start_time.get(11..16).unwrap_or_default())
+}
+
+/// A session's checklist as (item, checked), configured items first.
+pub async fn session_checklist(pool: &SqlitePool, session_id: &str) -> Result<Vec<(String, bool)>> {
+    Ok(sqlx::query_as("SELECT item, checked FROM session_checklist WHERE training_session_id = ? ORDER BY rowid")
+        .bind(session_id)
+        .fetch_all(pool)
+        .await?)
+}
+
+/// `✓ belt  ☐ straps`.
+fn checklist_line(items: &[(String, bool)]) -> String {
+    items
+        .iter()
+        .map(|(item, checked)| match checked {
+            true => format!("{} {}", "✓".good(), item),
+            false => format!("{} {}", "☐".dimmed(), item.dimmed()),
+        })
+        .collect::<Vec<_>>()
+        .join("  ")
+}
+
+/// Free-form tags of a session, from `session end --tag`.
+pub async fn session_tags(pool: &SqlitePool, session_id: &str) -> Result<Vec<String>> {
+    Ok(sqlx::query_scalar("SELECT tag FROM session_tags WHERE training_session_id = ? ORDER BY tag")
+        .bind(session_id)
+        .fetch_all(pool)
+        .await?)
+}
+
+/// Seconds a session spent paused, counting an open pause up to now.
+pub async fn paused_secs(pool: &SqlitePool, session_id: &str) -> Result<i64> {
+    Ok(sqlx::query_scalar(
+        r#"
+        SELECT CAST(COALESCE(SUM(strftime('%s', COALESCE(resumed_at, 'now')) - strftime('%s', paused_at)), 0) AS INTEGER)
+        FROM session_pauses
+        WHERE training_session_id = ?
+        "#,
+    )
+    .bind(session_id)
+    .fetch_one(pool)
+    .await?)
+}
+
+async fn is_paused(pool: &SqlitePool, session_id: &str) -> Result<bool> {
+    let open: Option<String> = sqlx::query_scalar(
+        "SELECT paused_at FROM session_pauses WHERE training_session_id = ? AND resumed_at IS NULL",
+    )
+    .bind(session_id)
+    .fetch_optional(pool)
+    .await?;
+    Ok(open.is_some())
+}
+
+fn hms(secs: i64) -> String {
+    let secs = secs.max(0);
+    format!("{:02}:{:02}:{:02}", secs / 3600, secs / 60 % 60, secs % 60)
+}
+
+/// The open session tagged `tag`, or the only open session when no tag is
+/// given; `None` when nothing is open. Fails with [`AppError::NotFound`] when
+/// no session has the tag and with [`AppError::Invalid`], naming the
+/// candidates, when several are open and no tag picks one.
+async fn select_session(store: &impl SessionStore, tag: Option<&str>) -> Result<Option<String>> {
+    let open = store.open_sessions().await?;
+
+    if let Some(tag) = tag {
+        return match open.into_iter().find(|s| s.tag.as_deref() == Some(tag)) {
+            Some(s) => Ok(Some(s.id)),
+            None => Err(AppError::NotFound(tf("no active session tagged `{}`", &[&tag])).into()),
+        };
+    }
+
+    match open.len() {
+        0 => Ok(None),
+        1 => Ok(Some(open.into_iter().next().unwrap().id)),
+        n => {
+            let candidates: Vec<String> = open
+                .iter()
+                .map(|s| format!("{} ({})", s.tag.as_deref().unwrap_or(tr("untagged")), s.block))
+                .collect();
+            Err(AppError::Invalid(tf(
+                "{} sessions are active, pick one with `--session <tag>`: {}",
+                &[&n, &candidates.join(", ")],
+            ))
+            .into())
+        }
+    }
+}
+
+/// A session command with the config's defaults applied, the same from the
+/// terminal and from the daemon.
+pub async fn run(mut cmd: SessionCmd, pool: &SqlitePool, session: Option<String>, cfg: &Config) -> Result<()> {
+    if let SessionCmd::Start(start) = &mut cmd {
+        start.autofill_1rm |= cfg.autofill_1rm();
+        start.checklist = cfg.checklist();
+    }
+    handle(cmd, pool, session, cfg.accommodating(), cfg.rounding(), cfg.bodyweight()).await
+}
+
+pub async fn handle(
+    cmd: SessionCmd,
+    pool: &SqlitePool,
+    session: Option<String>,
+    accommodating: Accommodating,
+    rules: RoundingRules,
+    bodyweight: Option<f32>,
+) -> Result<()> {
+    // Everything but start/list-active/log works on a single open session.
+    let active = match cmd {
+        SessionCmd::Start(_) | SessionCmd::ListActive | SessionCmd::Log { .. } => None,
+        _ => select_session(pool, session.as_deref()).await?,
+    };
 
-pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
     match cmd {
         SessionCmd::Start(args) => {
-            // First, resolve the program name/index to its ID
-            let prog_id: String = if let Ok(idx) = args.program.parse::<i64>() {
-                // User passed a number - look up by row number.
-                match sqlx::query_scalar(
-                    r#"
-                    SELECT id 
-                    FROM (
-                      SELECT id, ROW_NUMBER() OVER (ORDER BY name) AS rn
-                      FROM programs
-                    ) t
-                    WHERE t.rn = ?
-                    "#,
-                )
-                .bind(idx)
-                .fetch_one(pool)
-                .await
-                {
-                    Ok(id) => id,
-                    Err(_) => {
-                        println!("{} no program at index {}", "error:".red().bold(), idx);
-                        return Ok(());
-                    }
-                }
-            } else {
-                // User passed a name - look up by exact name.
-                match sqlx::query_scalar("SELECT id FROM programs WHERE name = ?")
-                    .bind(&args.program)
-                    .fetch_one(pool)
-                    .await
-                {
-                    Ok(id) => id,
-                    Err(_) => {
-                        println!(
-                            "{} no program named `{}`",
-                            "error:".red().bold(),
-                            args.program
-                        );
-                        return Ok(());
-                    }
-                }
-            };
+            let prog_id = resolve_program(pool, &args.program).await?;
 
             // Then, resolve the block name to its ID.
             let block_id: String = if let Ok(idx) = args.block.parse::<i64>() {
@@ -73,13 +179,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 {
                     Ok(id) => id,
                     Err(_) => {
-                        println!(
-                            "{} no block at index {} in program `{}`",
-                            "error:".red().bold(),
-                            idx,
-                            args.program
-                        );
-                        return Ok(());
+                        return Err(AppError::NotFound(tf("no block at index {} in program `{}`", &[&idx, &args.program]).into()).into());
                     }
                 }
             } else {
@@ -94,29 +194,37 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 {
                     Ok(id) => id,
                     Err(_) => {
-                        println!(
-                            "{} no block named `{}` in program `{}`",
-                            "error:".red().bold(),
-                            args.block,
-                            args.program
-                        );
-                        return Ok(());
+                        return Err(AppError::NotFound(tf("no block named `{}` in program `{}`", &[&args.block, &args.program]).into()).into());
                     }
                 }
             };
 
-            // Check if there's already an active session.
-            let active: Option<String> = sqlx::query_scalar("SELECT id FROM current_session")
-                .fetch_optional(pool)
-                .await?;
+            if args.autofill_1rm {
+                autofill_1rm(pool, &prog_id, Some(&block_id)).await?;
+            }
 
-            if let Some(id) = active {
-                println!(
-                    "{} there is already an active session (id: {})",
-                    "error:".red().bold(),
-                    id
-                );
-                return Ok(());
+            let gym = match &args.gym {
+                Some(name) => match load_gym(pool, name).await? {
+                    Some(g) => Some(g),
+                    None => {
+                        return Err(AppError::NotFound(tf("no gym named `{}` (see `gym list`)", &[&name]).into()).into());
+                    }
+                },
+                None => None,
+            };
+
+            // Only one open session per tag (untagged counts as a tag of its own).
+            let clash: Option<String> =
+                sqlx::query_scalar("SELECT id FROM training_sessions WHERE end_time IS NULL AND tag IS ?")
+                    .bind(&args.tag)
+                    .fetch_optional(pool)
+                    .await?;
+
+            if let Some(id) = clash {
+                return Err(AppError::Invalid(tf(
+                    "there is already an active session{} (id: {}) — use `--tag` to start another",
+                    &[&args.tag.as_deref().map(|t| tf(" tagged `{}`", &[&t])).unwrap_or_default(), &id]
+                ).into()).into());
             }
 
             // Start a transaction.
@@ -125,17 +233,18 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
             // Create the session
             let session_id = Uuid::new_v4().to_string();
             sqlx::query(
-                "INSERT INTO training_sessions (id, program_block_id, start_time) VALUES (?, ?, datetime('now'))",
+                "INSERT INTO training_sessions (id, program_block_id, start_time, tag) VALUES (?, ?, datetime('now'), ?)",
             )
             .bind(&session_id)
             .bind(&block_id)
+            .bind(&args.tag)
             .execute(&mut *tx)
             .await?;
 
             // Get all exercises for this block.
-            let exercises = sqlx::query_as::<_, (String, String, i32, Option<String>)>(
+            let mut exercises = sqlx::query_as::<_, (String, String, i32, Option<String>, Option<String>, Option<String>, Option<i32>)>(
                 r#"
-                SELECT e.id, e.name, pe.sets, pe.reps
+                SELECT e.id, e.name, pe.sets, pe.reps, e.equipment, pe.options, pe.rotate_weeks
                 FROM program_exercises pe
                 JOIN exercises e ON e.id = pe.exercise_id
                 WHERE pe.program_block_id = ?
@@ -146,50 +255,231 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
             .fetch_all(&mut *tx)
             .await?;
 
+            // From the accessory pool, keep the ones asked for or the least recently trained.
+            let choose: Option<i32> = sqlx::query_scalar("SELECT pool_choose FROM program_blocks WHERE id = ?")
+                .bind(&block_id)
+                .fetch_one(&mut *tx)
+                .await?;
+            let mut picked: Vec<String> = Vec::new();
+            if let Some(choose) = choose {
+                let pooled: Vec<(String, String)> = sqlx::query_as(
+                    r#"
+                    SELECT e.id, e.name,
+                           (SELECT MAX(ts.start_time)
+                            FROM training_session_exercises tse
+                            JOIN training_sessions ts ON ts.id = tse.training_session_id
+                            WHERE tse.exercise_id = e.id AND ts.end_time IS NOT NULL) AS last_done
+                    FROM program_exercises pe
+                    JOIN exercises e ON e.id = pe.exercise_id
+                    WHERE pe.program_block_id = ? AND pe.pool = 1
+                    ORDER BY last_done, pe.order_index
+                    "#,
+                )
+                .bind(&block_id)
+                .fetch_all(&mut *tx)
+                .await?;
+
+                picked = match &args.accessories {
+                    Some(list) => {
+                        let mut ids = Vec::new();
+                        for name in list.split(',').map(str::trim).filter(|n| !n.is_empty()) {
+                            match pooled.iter().find(|(_, n)| n.eq_ignore_ascii_case(name)) {
+                                Some((id, _)) => ids.push(id.clone()),
+                                None => {
+                                    return Err(AppError::Invalid(tf(
+                                        "`{}` is not in this block's accessory pool ({})",
+                                        &[&name, &pooled.iter().map(|(_, n)| n.as_str()).collect::<Vec<_>>().join(", ")],
+                                    )).into());
+                                }
+                            }
+                        }
+                        ids
+                    }
+                    None => pooled.iter().take(choose.max(0) as usize).map(|(id, _)| id.clone()).collect(),
+                };
+
+                let pool_ids: Vec<&String> = pooled.iter().map(|(id, _)| id).collect();
+                exercises.retain(|(id, ..)| !pool_ids.contains(&id) || picked.contains(id));
+            }
+
+            // Whole weeks since the program's first session, which drive rotations.
+            let weeks_in: i64 = sqlx::query_scalar(
+                r#"
+                SELECT CAST((julianday('now') - julianday(MIN(ts.start_time))) / 7 AS INTEGER)
+                FROM training_sessions ts
+                JOIN program_blocks pb ON pb.id = ts.program_block_id
+                WHERE pb.program_id = (SELECT program_id FROM program_blocks WHERE id = ?)
+                "#,
+            )
+            .bind(&block_id)
+            .fetch_one(&mut *tx)
+            .await?;
+
             // Create session exercise records.
-            println!("{}", "Exercises:".cyan().bold());
-            for (i, (ex_id, ex_name, sets, reps)) in exercises.iter().enumerate() {
+            ui::outln!("{}", tr("Exercises:").heading().bold());
+            for (i, (ex_id, ex_name, sets, reps, _, options, rotate_weeks)) in exercises.iter().enumerate() {
+                // The exercise itself first, then each option for `rotate_weeks` weeks.
+                let mut rotated: Option<(String, String)> = None;
+                if let (Some(weeks), Some(options)) = (rotate_weeks.filter(|&w| w > 0), options.as_deref()) {
+                    let names: Vec<&str> = options.split(',').map(str::trim).filter(|o| !o.is_empty()).collect();
+                    let turn = (weeks_in / weeks as i64) as usize % (names.len() + 1);
+                    if turn > 0 {
+                        let name = names[turn - 1];
+                        match sqlx::query_scalar::<_, String>("SELECT id FROM exercises WHERE name = ?")
+                            .bind(name)
+                            .fetch_optional(&mut *tx)
+                            .await?
+                        {
+                            Some(id) => rotated = Some((id, name.to_string())),
+                            None => ui::outln!(
+                                "{} {}",
+                                tr("warning:").accent().bold(),
+                                tf("rotation option `{}` is not an exercise—doing `{}` instead", &[&name, ex_name])
+                            ),
+                        }
+                    }
+                }
+
                 let session_ex_id = Uuid::new_v4().to_string();
                 sqlx::query(
-                    "INSERT INTO training_session_exercises (id, training_session_id, exercise_id) VALUES (?, ?, ?)",
+                    "INSERT INTO training_session_exercises (id, training_session_id, exercise_id, rotated_from) VALUES (?, ?, ?, ?)",
                 )
                 .bind(&session_ex_id)
                 .bind(&session_id)
-                .bind(ex_id)
+                .bind(rotated.as_ref().map_or(ex_id, |(id, _)| id))
+                .bind(rotated.as_ref().map(|_| ex_id))
                 .execute(&mut *tx)
                 .await?;
 
+                // Expand warm-up/back-off groups into concrete per-session targets.
+                let (warmup, backoff): (Option<String>, Option<String>) = sqlx::query_as(
+                    "SELECT warmup, backoff FROM program_exercises WHERE program_block_id = ? AND exercise_id = ?",
+                )
+                .bind(&block_id)
+                .bind(ex_id)
+                .fetch_one(&mut *tx)
+                .await?;
+                let mut extra = Vec::new();
+                for (kind, csv) in [("warmup", &warmup), ("backoff", &backoff)] {
+                    let Some(csv) = csv else { continue };
+                    let mut n = 0;
+                    for (pos, entry) in csv.split(',').enumerate() {
+                        let (pct, reps) = entry.split_once(':').unwrap_or((entry, ""));
+                        let Ok(pct) = pct.trim().parse::<f32>() else { continue };
+                        sqlx::query(
+                            "INSERT INTO session_set_targets (session_exercise_id, kind, position, percent, reps) VALUES (?, ?, ?, ?, ?)",
+                        )
+                        .bind(&session_ex_id)
+                        .bind(kind)
+                        .bind(pos as i32)
+                        .bind(pct)
+                        .bind(Some(reps.trim()).filter(|r| !r.is_empty()))
+                        .execute(&mut *tx)
+                        .await?;
+                        n += 1;
+                    }
+                    if n > 0 {
+                        extra.push(format!("{} {}", n, if kind == "warmup" { "warm-up" } else { "back-off" }));
+                    }
+                }
+
                 // Print exercise info.
-                let idx = format!("{}", i + 1).yellow();
+                let idx = format!("{}", i + 1).accent();
                 let reps_display = reps
                     .as_deref()
                     .map(|r| format!(" ({})", r))
                     .unwrap_or_default();
-                println!(
-                    "{} • {} — {} sets{}",
+                let extra_display = if extra.is_empty() {
+                    String::new()
+                } else {
+                    format!(" + {}", extra.join(", ")).dimmed().to_string()
+                };
+                let (shown, rotation_display) = match &rotated {
+                    Some((_, name)) => (
+                        name.as_str(),
+                        format!(" ({})", tf("rotation of {}", &[ex_name])).dimmed().to_string(),
+                    ),
+                    None => (ex_name.as_str(), String::new()),
+                };
+                ui::outln!(
+                    "{} • {}{} — {} sets{}{}",
                     idx,
-                    ex_name.bold(),
+                    shown.bold(),
+                    rotation_display,
                     sets,
-                    reps_display
+                    reps_display,
+                    extra_display
                 );
             }
 
+            // The configured checklist, then anything else ticked off.
+            let mut checklist: Vec<(String, bool)> = Vec::new();
+            for item in args.checklist.iter().chain(&args.checks).map(|i| i.trim()).filter(|i| !i.is_empty()) {
+                if checklist.iter().any(|(i, _)| i.eq_ignore_ascii_case(item)) {
+                    continue;
+                }
+                let checked = args.checks.iter().any(|c| c.trim().eq_ignore_ascii_case(item));
+                sqlx::query("INSERT INTO session_checklist (training_session_id, item, checked) VALUES (?, ?, ?)")
+                    .bind(&session_id)
+                    .bind(item)
+                    .bind(checked)
+                    .execute(&mut *tx)
+                    .await?;
+                checklist.push((item.to_string(), checked));
+            }
+
             // Commit the transaction.
             tx.commit().await?;
 
-            println!(
-                "\n{} session started (id: {})",
-                "ok:".green().bold(),
-                session_id
+            if !checklist.is_empty() {
+                ui::outln!("\n{} {}", tr("Checklist:").heading().bold(), checklist_line(&checklist));
+                if checklist.iter().any(|(_, checked)| !checked) {
+                    ui::outln!("{}", tr("  tick items off with `--check <item>` when starting").dimmed());
+                }
+            }
+
+            if !picked.is_empty() && args.accessories.is_none() {
+                ui::outln!("{}", tr("  accessories picked from the pool: least recently trained first").dimmed());
+            }
+
+            // Exercises this gym can't host, with a swap to use instead
+            if let Some(gym) = &gym {
+                let mut hints = Vec::new();
+                for (i, (ex_id, ex_name, _, _, equipment, options, _)) in exercises.iter().enumerate() {
+                    let equipment = equipment_of(ex_name, equipment.as_deref());
+                    if let Some(hint) = swap_hint(
+                        pool,
+                        gym,
+                        i + 1,
+                        ex_id,
+                        ex_name,
+                        equipment.as_deref(),
+                        options.as_deref(),
+                    )
+                    .await?
+                    {
+                        hints.push(format!("{} • {}: {}", (i + 1).to_string().accent(), ex_name.bold(), hint));
+                    }
+                }
+
+                ui::outln!("\n{} {}", tr("Gym:").heading().bold(), gym.name);
+                if hints.is_empty() {
+                    ui::outln!("{}", "  everything in this block can be done here".dimmed());
+                }
+                for hint in hints {
+                    ui::outln!("{}", hint);
+                }
+            }
+
+            ui::outln!(
+                "\n{} session started (id: {}{})",
+                tr("ok:").good().bold(),
+                session_id,
+                args.tag.as_deref().map(|t| format!(", tag: {}", t)).unwrap_or_default()
             );
         }
 
         SessionCmd::Cancel => {
-            // Check if there's an active session.
-            let active: Option<String> = sqlx::query_scalar("SELECT id FROM current_session")
-                .fetch_optional(pool)
-                .await?;
-
             if let Some(id) = active {
                 // Start a transaction.
                 let mut tx = pool.begin().await?;
@@ -202,50 +492,99 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
 
                 // Commit the transaction.
                 tx.commit().await?;
+                wal::discard(&id)?;
 
-                println!("{} session cancelled (id: {})", "ok:".green().bold(), id);
+                ui::ok(tf("session cancelled (id: {})", &[&id]));
             } else {
-                println!("{} no active session to cancel", "error:".red().bold());
+                return Err(AppError::NoActiveSession.into());
+            }
+        }
+
+        SessionCmd::ListActive => {
+            let open = pool.open_sessions().await?;
+
+            ui::outln!("{}", tr("Active sessions:").heading().bold());
+            if open.is_empty() {
+                ui::outln!("{}", "  (none)".dimmed());
+            }
+            for s in open {
+                ui::outln!(
+                    "  • {} {} {}",
+                    s.tag.as_deref().unwrap_or(tr("untagged")).accent(),
+                    s.block.bold(),
+                    format!("– started {}, {} sets logged ({})", started_at(&s.start_time), s.sets, &s.id[..8]).dimmed()
+                );
             }
         }
 
         SessionCmd::Show => {
             // Get current session info
-            let session: Option<(String, String, String, String)> = sqlx::query_as(
+            let session: Option<(String, String, String, String, Option<String>)> = sqlx::query_as(
                 r#"
-                SELECT ts.id, ts.start_time, pb.name, COALESCE(pb.description, '')
+                SELECT ts.id, ts.start_time, pb.name, COALESCE(pb.description, ''), ts.tag
                 FROM training_sessions ts
                 JOIN program_blocks pb ON pb.id = ts.program_block_id
-                WHERE ts.end_time IS NULL
-                LIMIT 1
+                WHERE ts.id = ?
                 "#,
             )
+            .bind(&active)
             .fetch_optional(pool)
             .await?;
 
-            if let Some((session_id, start_time, block_name, block_desc)) = session {
-                // Calculate session duration
-                let duration = sqlx::query_scalar::<_, String>(
+            if let Some((session_id, start_time, block_name, block_desc, tag)) = session {
+                // Calculate session duration, leaving out paused time
+                let paused = paused_secs(pool, &session_id).await?;
+                let elapsed: i64 = sqlx::query_scalar("SELECT CAST(strftime('%s', 'now') - strftime('%s', ?) AS INTEGER)")
+                    .bind(&start_time)
+                    .fetch_one(pool)
+                    .await?;
+                let duration = hms(elapsed - paused);
+
+                // Print session header
+                ui::outln!(
+                    "{} {}",
+                    tr("Session:").heading().bold(),
+                    tf("{}{} — {} (started {}, duration: {})", &[&block_name.bold(), &tag.map(|t| format!(" [{}]", t).accent().to_string()).unwrap_or_default(), &block_desc.dimmed(), &started_at(&start_time), &duration])
+                );
+
+                // Elapsed vs expected time, when the block declares a duration.
+                let (elapsed_secs, expected_minutes): (i64, Option<i32>) = sqlx::query_as(
                     r#"
-                    SELECT strftime('%H:%M:%S', 
-                        strftime('%s', 'now') - strftime('%s', ?) || ' seconds', 
-                        'unixepoch'
-                    )
+                    SELECT CAST(strftime('%s', 'now') - strftime('%s', ts.start_time) AS INTEGER),
+                           pb.expected_minutes
+                    FROM training_sessions ts
+                    JOIN program_blocks pb ON pb.id = ts.program_block_id
+                    WHERE ts.id = ?
                     "#,
                 )
-                .bind(&start_time)
+                .bind(&session_id)
                 .fetch_one(pool)
                 .await?;
 
-                // Print session header
-                println!(
-                    "{} {} — {} (started {}, duration: {})",
-                    "Session:".cyan().bold(),
-                    block_name.bold(),
-                    block_desc.dimmed(),
-                    &start_time[..16],
-                    duration
-                );
+                if paused > 0 {
+                    let state = if is_paused(pool, &session_id).await? {
+                        format!(" ({})", tr("paused now")).highlight().to_string()
+                    } else {
+                        String::new()
+                    };
+                    ui::outln!("{} {}{}", tr("Paused:").heading().bold(), hms(paused).dimmed(), state);
+                }
+
+                let checklist = session_checklist(pool, &session_id).await?;
+                if !checklist.is_empty() {
+                    ui::outln!("{} {}", tr("Checklist:").heading().bold(), checklist_line(&checklist));
+                }
+
+                if let Some(expected) = expected_minutes {
+                    let elapsed_min = (elapsed_secs - paused) / 60;
+                    let line = format!("{}m elapsed / {}m expected", elapsed_min, expected);
+                    let line = if elapsed_min > expected as i64 {
+                        line.bad().to_string()
+                    } else {
+                        line.dimmed().to_string()
+                    };
+                    ui::outln!("{} {}", tr("Time:").heading().bold(), line);
+                }
 
                 // Get exercises with their PRs
                 let exercises = sqlx::query_as::<
@@ -264,6 +603,8 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         Option<String>,
                         Option<f32>,
                         String,
+                        Option<String>,
+                        Option<String>,
                     ),
                 >(
                     r#"
@@ -278,11 +619,11 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         GROUP BY exercise_id
                     ),
                     session_exercise_order AS (
-                        -- Use SQLite rowid to maintain original insertion order
+                        -- Workout order, as logged or rearranged with `move-ex`
                         SELECT 
                             tse.id as tse_id,
                             tse.exercise_id,
-                            ROW_NUMBER() OVER (ORDER BY tse.rowid) as display_order
+                            ROW_NUMBER() OVER (ORDER BY tse.order_index) as display_order
                         FROM training_session_exercises tse
                         WHERE tse.training_session_id = ?
                     )
@@ -299,27 +640,29 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         pe.target_rpe,
                         pe.target_rm_percent,
                         pe.program_1rm,
-                        seo.tse_id
+                        seo.tse_id,
+                        pe.tempo,
+                        pe.pause
                     FROM training_session_exercises tse
                     JOIN session_exercise_order seo ON seo.tse_id = tse.id
                     JOIN exercises e ON e.id = tse.exercise_id
-                    LEFT JOIN program_exercises pe ON pe.exercise_id = e.id 
-                        AND pe.program_block_id = (
-                            SELECT program_block_id 
-                            FROM training_sessions 
-                            WHERE id = ?
-                        )
+                    -- The targets as they were when the session was trained.
+                    LEFT JOIN session_program_exercises pe ON pe.exercise_id = COALESCE(tse.rotated_from, e.id)
+                        AND pe.training_session_id = tse.training_session_id
                     WHERE tse.training_session_id = ?
                     ORDER BY seo.display_order
                     "#,
                 )
                 .bind(&session_id)
                 .bind(&session_id)
-                .bind(&session_id)
                 .fetch_all(pool)
                 .await?;
 
-                println!("\n{}", "Exercises:".cyan().bold());
+                ui::outln!("\n{}", tr("Exercises:").heading().bold());
+                let labels = superset_labels_of(pool, &session_id).await?;
+
+                // Seconds of rest still ahead of us, summed over every exercise.
+                let mut remaining_secs = 0.0;
 
                 // Pre-calculate all previous set information to find the maximum width
                 let mut prev_sets_info = Vec::new();
@@ -339,6 +682,8 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         _target_rm_percent,
                         _program_1rm,
                         _tse_id,
+                        _tempo,
+                        _pause,
                     ),
                 ) in exercises.iter().enumerate()
                 {
@@ -357,7 +702,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                                     tse.exercise_id,
                                     ROW_NUMBER() OVER (
                                         PARTITION BY tse.exercise_id, tse.id
-                                        ORDER BY es.timestamp
+                                        ORDER BY es.set_index
                                     ) - 1 as set_num
                                 FROM exercise_sets es
                                 JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
@@ -388,7 +733,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         .await?;
 
                         let prev_info = prev_set
-                            .map(|(w, r)| format!(" - {}kg × {}", w, r))
+                            .map(|(w, r)| format!(" - {}kg × {}", kg(w), r))
                             .unwrap_or_default();
 
                         exercise_prev_sets.push(prev_info);
@@ -421,10 +766,12 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         _target_rm_percent,
                         _program_1rm,
                         tse_id,
+                        tempo,
+                        pause,
                     ),
                 ) in exercises.iter().enumerate()
                 {
-                    let idx = format!("{}", i + 1).yellow();
+                    let idx = format!("{}", i + 1).accent();
 
                     // Get the best (highest 1RM) PR for this exercise
                     let (pr_weight, pr_reps, pr_1rm): (Option<f32>, Option<i32>, Option<f32>) =
@@ -444,14 +791,18 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
 
                     // Print exercise header with PR info
                     let pr_info = if let (Some(w), Some(r)) = (pr_weight, pr_reps) {
-                        let one_rm = pr_1rm.unwrap_or_else(|| epley_1rm(w, r).round());
-                        let actual_pr = format!("{}kg × {}", w, r).red().bold().to_string();
-                        format!(" - PR: {} (1RM: {:.1}kg)", actual_pr, one_rm)
+                        let one_rm = pr_1rm.unwrap_or_else(|| estimate_1rm(w, r).round());
+                        let actual_pr = format!("{}kg × {}", kg(w), r).bad().bold().to_string();
+                        format!(" - PR: {} (1RM: {}kg)", actual_pr, kg_places(one_rm, 1))
                     } else {
                         String::new()
                     };
 
-                    println!("{} • {}{}", idx, ex_name.bold(), pr_info.dimmed());
+                    let label = labels
+                        .get(tse_id.as_str())
+                        .map(|l| format!("{} ", l).highlight().bold().to_string())
+                        .unwrap_or_default();
+                    ui::outln!("{} • {}{}{}", idx, label, ex_name.bold(), pr_info.dimmed());
 
                     // Print exercise note if it exists
                     let note: Option<String> = sqlx::query_scalar(
@@ -463,10 +814,18 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
 
                     if let Some(note) = note {
                         if note != "" {
-                            println!("    {} {}", "NOTE:".blue().bold(), note);
+                            print_note(&note);
                         }
                     }
 
+                    // Machine settings remembered from last time
+                    if let Some(settings) = settings_line(pool, ex_id).await? {
+                        ui::outln!("    {} {}", tr("EQUIP:").info().bold(), settings);
+                    }
+                    print_attachments(pool, tse_id).await?;
+
+                    let rounding = rounding_for(pool, &rules, ex_id).await?;
+
                     // Parse target values
                     let target_rpes: Vec<f32> = _target_rpe
                         .as_deref()
@@ -478,23 +837,35 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         .map(|s| s.split(',').filter_map(|v| v.trim().parse().ok()).collect())
                         .unwrap_or_default();
 
+
+                    let pauses: Vec<&str> = pause
+
+                        .as_deref()
+
+                        .map(|s| s.split(',').map(|v| v.trim()).collect())
+
+                        .unwrap_or_default();
+
                     // Print sets
                     let reps_display = reps
                         .as_deref()
                         .map(|r| r.split(',').collect::<Vec<_>>())
                         .unwrap_or_default();
 
+                    let unilateral = is_unilateral(pool, ex_id).await?;
+
                     // Get all logged sets for this exercise
                     let logged_sets_1_based_num = sqlx::query_as::<_, (i64, f32, i32, bool)>(
                         r#"
                         WITH set_numbers AS (
                             SELECT 
                                 es.*,
-                                ROW_NUMBER() OVER (PARTITION BY tse.id ORDER BY es.timestamp) as set_num -- 1-based
+                                ROW_NUMBER() OVER (PARTITION BY tse.id ORDER BY es.set_index) as set_num -- 1-based
                             FROM exercise_sets es
                             JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
                             WHERE tse.exercise_id = ?
                             AND tse.training_session_id = ?
+                            AND es.side IS ? -- unilateral: L rows, R shown alongside
                         )
                         SELECT set_num, weight, reps, bodyweight
                         FROM set_numbers
@@ -503,6 +874,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     )
                     .bind(ex_id)
                     .bind(&session_id)
+                    .bind(unilateral.then_some("L"))
                     .fetch_all(pool)
                     .await?;
 
@@ -543,31 +915,55 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         all_sets
                     };
 
+                    let accommodating_info = accommodating_by_set(pool, &session_id, ex_id).await?;
+                    let statuses = set_statuses(pool, &session_id, ex_id).await?;
+                    let flags = set_flags(pool, &session_id, ex_id).await?;
+                    let right_side = if unilateral {
+                        right_side_sets(pool, &session_id, ex_id).await?
+                    } else {
+                        HashMap::new()
+                    };
+
+                    let sets_left = sets_to_show
+                        .iter()
+                        .filter(|(n, w, r, bw)| *w == 0.0 && *r == 0 && !*bw && !statuses.contains_key(n))
+                        .count();
+
+                    print_set_targets(pool, tse_id, "warmup", *_program_1rm, &target_rms, rounding).await?;
+
                     // Display all sets
                     for (set_num_0_based_in_loop, weight, reps, bw) in sets_to_show {
                         let set_num_usize = set_num_0_based_in_loop as usize; // 0-based for array indexing
+                        // No %1RM for this set: suggest a load for the RPE from the e1RM.
+                        let e1rm = _est_1rm.filter(|_| set_num_usize >= target_rms.len());
                         let target_info = if let Some(program_1rm) = _program_1rm {
                             if set_num_usize < target_rpes.len() {
-                                format!(" @RPE {}", target_rpes[set_num_usize])
+                                rpe_target(target_rpes[set_num_usize], reps_display.get(set_num_usize).copied(), e1rm, rounding)
                             } else if set_num_usize < target_rms.len() {
                                 let target_weight =
                                     program_1rm * (target_rms[set_num_usize] / 100.0);
                                 format!(
-                                    " @{}% ({}kg)",
+                                    " @{}% ({})",
                                     target_rms[set_num_usize],
-                                    target_weight.round()
+                                    rounding.format(target_weight)
                                 )
                             } else {
                                 String::new()
                             }
                         } else {
                             if set_num_usize < target_rpes.len() {
-                                format!(" @RPE {}", target_rpes[set_num_usize])
+                                rpe_target(target_rpes[set_num_usize], reps_display.get(set_num_usize).copied(), e1rm, rounding)
                             } else {
                                 String::new()
                             }
                         };
 
+                        let target_info = format!(
+                            "{}{}",
+                            target_info,
+                            tempo_suffix(tempo.as_deref(), pauses.get(set_num_usize).copied())
+                        );
+
                         // Get previous set info from our pre-calculated list
                         let prev_info = if set_num_usize < prev_sets_info[i].len() {
                             &prev_sets_info[i][set_num_usize]
@@ -577,11 +973,13 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         let prev_column =
                             format!("{:<width$}", prev_info, width = max_prev_width).dimmed();
 
-                        let target_reps = if set_num_usize < reps_display.len() {
-                            format!("{} reps", reps_display[set_num_usize])
-                        } else {
-                            String::from("do your thing")
-                        };
+                        let is_amrap = reps_display
+                            .get(set_num_usize)
+                            .is_some_and(|r| is_amrap_target(r));
+                        let target_reps = reps_display
+                            .get(set_num_usize)
+                            .map(|r| target_label(r))
+                            .unwrap_or_else(|| String::from("do your thing"));
 
                         let target_padding = if (target_reps.len() + target_info.len()) < 25 {
                             25 - (target_reps.len() + target_info.len())
@@ -590,12 +988,17 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         };
 
                         // Create all parts of the display separately
-                        let set_num_str = format!("{}", set_num_0_based_in_loop + 1).yellow(); // Display as 1-based
+                        let set_num_str = format!("{}", set_num_0_based_in_loop + 1).accent(); // Display as 1-based
                         let indent = " ".repeat(2);
+                        let target_reps_colored = if is_amrap {
+                            target_reps.highlight().bold().to_string()
+                        } else {
+                            target_reps.clone()
+                        };
                         let target_part = if target_reps.is_empty() {
                             String::new()
                         } else {
-                            format!("{}{}", target_reps, target_info.dimmed())
+                            format!("{}{}", target_reps_colored, target_info.dimmed())
                         };
                         let padding = " ".repeat(target_padding);
 
@@ -606,21 +1009,39 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                             false
                         };
 
+                        let extra = accommodating_info
+                            .get(&set_num_0_based_in_loop)
+                            .map(String::as_str)
+                            .unwrap_or_default();
                         let current_info = if bw {
-                            format!("bw × {}", reps)
+                            bodyweight_label(weight, reps)
                         } else if weight > 0.0 {
-                            let set_info = format!("{}kg × {}", weight, reps);
+                            let set_info = format!("{}kg × {}{}", kg(weight), reps, extra);
                             if is_pr_set {
-                                set_info.green().bold().to_string()
+                                set_info.good().bold().to_string()
                             } else {
                                 set_info
                             }
                         } else {
                             String::new()
                         };
+                        let current_info = match statuses.get(&set_num_0_based_in_loop) {
+                            Some(SetStatus::Skipped) => tr("skipped").dimmed().to_string(),
+                            Some(SetStatus::Failed) => format!("{} {}", current_info, tr("✗ failed").bad()),
+                            _ => current_info,
+                        };
+                        let current_info = match flags.get(&set_num_0_based_in_loop) {
+                            Some(f) if !current_info.is_empty() => format!("{} {}", current_info, flag_markers(f)),
+                            _ => current_info,
+                        };
+                        let current_info = if unilateral && !current_info.is_empty() {
+                            format!("L {}", current_info)
+                        } else {
+                            current_info
+                        };
 
                         // Print with explicit parts
-                        println!(
+                        ui::outln!(
                             " {} {} • {} {}{} | {}",
                             indent,
                             set_num_str,
@@ -629,34 +1050,96 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                             prev_column,
                             current_info
                         );
+                        if let Some(right) = right_side.get(&set_num_0_based_in_loop) {
+                            ui::outln!(" {}   {} {}", indent, "↳".dimmed(), right);
+                        }
+                    }
+                    print_set_targets(pool, tse_id, "backoff", *_program_1rm, &target_rms, rounding).await?;
+                    // Pace: unlogged sets × this exercise's usual rest.
+                    if sets_left > 0 {
+                        let rest = avg_rest_secs(pool, ex_id).await?;
+                        let secs = sets_left as f64 * rest;
+                        remaining_secs += secs;
+                        ui::outln!(
+                            "    {}",
+                            format!(
+                                "pace: {} set{} left ≈ {}m",
+                                sets_left,
+                                if sets_left == 1 { "" } else { "s" },
+                                (secs / 60.0).round()
+                            )
+                            .dimmed()
+                        );
+                    }
+                    ui::outln!();
+                }
+
+                print_circuits(pool, &session_id).await?;
+
+                if remaining_secs > 0.0 {
+                    let finish_min = elapsed_secs / 60 + (remaining_secs / 60.0).round() as i64;
+                    let summary = format!("~{}m left, finishing around {}m", (remaining_secs / 60.0).round(), finish_min);
+                    match expected_minutes {
+                        Some(expected) if finish_min > expected as i64 => ui::outln!(
+                            "{} {}",
+                            tr("Pace:").heading().bold(),
+                            tf("{} ({}m over the expected {}m)", &[&summary.bad(), &(finish_min - expected as i64), &expected])
+                        ),
+                        Some(expected) => ui::outln!(
+                            "{} {}",
+                            tr("Pace:").heading().bold(),
+                            tf("{} (expected {}m)", &[&summary.good(), &expected])
+                        ),
+                        None => ui::outln!("{} {}", tr("Pace:").heading().bold(), summary),
                     }
-                    println!();
                 }
             } else {
-                println!("{} no active session", "error:".red().bold());
+                return Err(AppError::NoActiveSession.into());
             }
         }
 
         SessionCmd::Edit {
             exercise,
             weight,
+            weight_flag,
+            added_weight,
             reps,
+            reps_flag,
+            skip,
+            failed,
             set,
             new,
+            band,
+            band_tension,
+            chains,
+            side,
+            belt,
+            straps,
+            sleeves,
+            wraps,
         } => {
-            // Check if there's an active session
-            let session: Option<(String,)> =
-                sqlx::query_as("SELECT id FROM current_session LIMIT 1")
-                    .fetch_optional(pool)
-                    .await?;
+            let session_id = match active {
+                Some(id) => id,
+                None => return Err(AppError::NoActiveSession.into()),
+            };
 
-            let session_id = match session {
-                Some((id,)) => id,
-                None => {
-                    println!("{} no active session", "error:".red().bold());
-                    return Ok(());
-                }
+            let status = if skip {
+                SetStatus::Skipped
+            } else if failed {
+                SetStatus::Failed
+            } else {
+                SetStatus::Completed
             };
+            let flags: Vec<&str> = SET_FLAGS
+                .iter()
+                .zip([belt, straps, sleeves, wraps])
+                .filter(|(_, worn)| *worn)
+                .map(|(flag, _)| *flag)
+                .collect();
+            let flags = (!flags.is_empty()).then(|| flags.join(","));
+            // A skipped set is stored as 0 × 0 so the next set moves on past it.
+            let weight = weight.or(weight_flag).unwrap_or_else(|| "0".to_string());
+            let reps = reps.or(reps_flag).unwrap_or(0);
 
             // Parse weight - handle bodyweight exercises
             let (is_bodyweight, parsed_weight) = if weight.to_lowercase() == "bw" {
@@ -665,21 +1148,42 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 match weight.parse::<f32>() {
                     Ok(w) => (false, Some(w)),
                     Err(_) => {
-                        println!("{} invalid weight: {}", "error:".red().bold(), weight);
-                        return Ok(());
+                        return Err(AppError::Invalid(tf("invalid weight: {}", &[&weight]).into()).into());
                     }
                 }
             };
+            // On a bodyweight set `weight` holds the added load, and the
+            // bodyweight of the day is kept next to it for the total.
+            if added_weight.is_some() && !is_bodyweight {
+                return Err(AppError::Invalid(tr("--added-weight goes with a `bw` set, e.g. `session edit 2 bw 5 --added-weight 20`").into()).into());
+            }
+            let parsed_weight = if is_bodyweight { added_weight } else { parsed_weight };
+            let body_mass = if is_bodyweight { current_bodyweight(pool, bodyweight).await? } else { None };
+
+            // Accommodating resistance: fall back to the color's usual tension
+            let band_tension = band_tension.or_else(|| band.as_deref().and_then(band_tension_kg));
+            if band.is_some() && band_tension.is_none() {
+                ui::outln!(
+                    "{} {}",
+                    tr("warning:").accent().bold(),
+                    tf("unknown band `{}`, pass --band-tension to count it", &[&band.as_deref().unwrap_or_default()])
+                );
+            }
+            let extra_load = band_tension.unwrap_or(0.0) + chains.unwrap_or(0.0);
+            let one_rm_weight = accommodating.one_rm_weight(parsed_weight.unwrap_or(0.0), extra_load);
+            // A failed set still counts with the reps it got (zero estimates
+            // nothing); a skipped one never lifted anything.
+            let ignore_for_one_rm = one_rm_weight.is_none() || skip;
 
             // Get the exercise ID for the given index
             let exercise_info: Option<(String, String)> = sqlx::query_as(
                 r#"
                 WITH session_exercise_order AS (
-                    -- Use SQLite rowid to maintain original insertion order
+                    -- Workout order, as logged or rearranged with `move-ex`
                     SELECT 
                         tse.id as tse_id,
                         tse.exercise_id,
-                        ROW_NUMBER() OVER (ORDER BY tse.rowid) as display_order
+                        ROW_NUMBER() OVER (ORDER BY tse.order_index) as display_order
                     FROM training_session_exercises tse
                     WHERE tse.training_session_id = ?
                 )
@@ -700,13 +1204,24 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
             let (exercise_id, session_exercise_id) = match exercise_info {
                 Some(info) => info,
                 None => {
-                    println!(
-                        "{} no exercise at index {}",
-                        "error:".red().bold(),
-                        exercise
-                    );
-                    return Ok(());
+                    return Err(AppError::NotFound(tf("no exercise at index {}", &[&exercise]).into()).into());
+                }
+            };
+
+            // Unilateral exercises number their sets per side; `both` logs L and R at once
+            let unilateral: bool =
+                sqlx::query_scalar("SELECT COALESCE(unilateral, 0) FROM exercises WHERE id = ?")
+                    .bind(&exercise_id)
+                    .fetch_one(pool)
+                    .await?;
+            let sides: Vec<Option<&str>> = match (unilateral, side) {
+                (false, None) => vec![None],
+                (false, Some(_)) => {
+                    return Err(AppError::Invalid(tf("exercise {} is not unilateral (mark it with `ex unilateral`)", &[&exercise]).into()).into());
                 }
+                (true, Some(Side::L)) => vec![Some("L")],
+                (true, Some(Side::R)) => vec![Some("R")],
+                (true, None | Some(Side::Both)) => vec![Some("L"), Some("R")],
             };
 
             // Determine which set to edit
@@ -718,10 +1233,11 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     r#"
                     SELECT COUNT(*)
                     FROM exercise_sets
-                    WHERE session_exercise_id = ?
+                    WHERE session_exercise_id = ? AND side IS ?
                     "#,
                 )
                 .bind(&session_exercise_id)
+                .bind(sides[0])
                 .fetch_one(pool)
                 .await? as usize
             } else {
@@ -730,10 +1246,11 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     r#"
                     SELECT COUNT(*)
                     FROM exercise_sets
-                    WHERE session_exercise_id = ?
+                    WHERE session_exercise_id = ? AND side IS ?
                     "#,
                 )
                 .bind(&session_exercise_id)
+                .bind(sides[0])
                 .fetch_one(pool)
                 .await? as usize
             };
@@ -752,9 +1269,9 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 ),
                 set_numbers AS (
                     SELECT 
-                        ROW_NUMBER() OVER (ORDER BY timestamp) - 1 as set_num
+                        ROW_NUMBER() OVER (ORDER BY set_index) - 1 as set_num
                     FROM exercise_sets
-                    WHERE session_exercise_id = ?
+                    WHERE session_exercise_id = ? AND side IS ?
                 ),
                 additional_sets AS (
                     SELECT COUNT(*) as extra_sets
@@ -768,94 +1285,178 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
             .bind(&exercise_id)
             .bind(&session_id)
             .bind(&session_exercise_id)
+            .bind(sides[0])
             .fetch_one(pool)
             .await?;
 
             // Only check set limit if --new flag is not used
             if !new && set_index >= total_sets as usize {
-                println!(
-                    "{} no set at index {} (max: {})",
-                    "error:".red().bold(),
-                    set_index + 1,
-                    total_sets
-                );
-                return Ok(());
+                return Err(AppError::NotFound(tf("no set at index {} (max: {})", &[&(set_index + 1), &total_sets]).into()).into());
             }
 
-            // Start a transaction
-            let mut tx = pool.begin().await?;
-
-            // Check if this set already exists and fetch its creation date
-            let existing_set: Option<(String, String)> = sqlx::query_as(
+            // Snapshot the prescribed tempo/pause so history keeps it even if the program changes
+            let (tempo, pause_csv, reps_csv): (Option<String>, Option<String>, Option<String>) = sqlx::query_as(
                 r#"
-                WITH set_numbers AS (
-                    SELECT 
-                        es.id,
-                        es.timestamp,
-                        ROW_NUMBER() OVER (PARTITION BY es.session_exercise_id ORDER BY es.timestamp) as set_num
-                    FROM exercise_sets es
-                    WHERE es.session_exercise_id = ?
+                SELECT tempo, pause, reps
+                FROM program_exercises
+                WHERE exercise_id = ? AND program_block_id = (
+                    SELECT program_block_id
+                    FROM training_sessions
+                    WHERE id = ?
                 )
-                SELECT id, timestamp
-                FROM set_numbers
-                WHERE set_num = ?
                 "#,
             )
-            .bind(&session_exercise_id)
-            .bind((set_index + 1) as i64) // Query with 1-based set number
-            .fetch_optional(&mut *tx)
-            .await?;
+            .bind(&exercise_id)
+            .bind(&session_id)
+            .fetch_optional(pool)
+            .await?
+            .unwrap_or((None, None, None));
+            let amrap = reps_csv
+                .as_deref()
+                .and_then(|r| r.split(',').nth(set_index))
+                .is_some_and(is_amrap_target);
+            let pause = pause_csv
+                .and_then(|p| p.split(',').nth(set_index).map(|v| v.trim().to_string()))
+                .filter(|p| !p.is_empty());
 
-            // If set exists, update it; otherwise create new
-            if let Some((set_id, _)) = existing_set {
-                // Update existing set
-                sqlx::query(
+            // Start a transaction
+            let mut tx = pool.begin().await?;
+
+            for side in &sides {
+                // Check if this set already exists and fetch its creation date
+                let existing_set: Option<(String, String, Option<String>)> = sqlx::query_as(
                     r#"
-                    UPDATE exercise_sets
-                    SET weight = ?, reps = ?, bodyweight = ?
-                    WHERE id = ?
-                    "#,
-                )
-                .bind(if is_bodyweight {
-                    0.0
-                } else {
-                    parsed_weight.unwrap_or(0.0)
-                })
-                .bind(reps)
-                .bind(is_bodyweight as i32)
-                .bind(&set_id)
-                .execute(&mut *tx)
-                .await?;
-            } else {
-                // Insert new set
-                sqlx::query(
-                    r#"
-                    INSERT INTO exercise_sets (
-                        id,
-                        session_exercise_id,
-                        weight,
-                        reps,
-                        bodyweight,
-                        timestamp
-                    ) VALUES (?, ?, ?, ?, ?, datetime('now'))
+                    WITH set_numbers AS (
+                        SELECT 
+                            es.id,
+                            es.timestamp,
+                            es.flags,
+                            ROW_NUMBER() OVER (PARTITION BY es.session_exercise_id ORDER BY es.set_index) as set_num
+                        FROM exercise_sets es
+                        WHERE es.session_exercise_id = ? AND es.side IS ?
+                    )
+                    SELECT id, timestamp, flags
+                    FROM set_numbers
+                    WHERE set_num = ?
                     "#,
                 )
-                .bind(Uuid::new_v4().to_string())
                 .bind(&session_exercise_id)
-                .bind(if is_bodyweight {
-                    0.0
-                } else {
-                    parsed_weight.unwrap_or(0.0)
-                })
-                .bind(reps)
-                .bind(is_bodyweight as i32)
-                .execute(&mut *tx)
+                .bind(*side)
+                .bind((set_index + 1) as i64) // Query with 1-based set number
+                .fetch_optional(&mut *tx)
                 .await?;
+
+                // Journal the set before the transaction touches it, so
+                // `recover-session` can replay it if the write is lost.
+                let (set_id, timestamp, logged_flags) = existing_set.clone().unwrap_or_else(|| {
+                    (Uuid::new_v4().to_string(), chrono::Utc::now().format("%Y-%m-%d %H:%M:%S").to_string(), None)
+                });
+                // Re-logging a set without gear flags keeps the ones it had.
+                let flags = flags.clone().or(logged_flags);
+                wal::append(
+                    &session_id,
+                    &wal::SetEntry {
+                        set_id: set_id.clone(),
+                        session_exercise_id: session_exercise_id.clone(),
+                        exercise_id: exercise_id.clone(),
+                        weight: parsed_weight.unwrap_or(0.0),
+                        reps,
+                        bodyweight: is_bodyweight,
+                        body_mass,
+                        tempo: tempo.clone(),
+                        pause: pause.clone(),
+                        amrap,
+                        band: band.clone(),
+                        band_tension,
+                        chain_weight: chains,
+                        ignore_for_one_rm,
+                        side: side.map(str::to_string),
+                        status: status.as_str().to_string(),
+                        timestamp: timestamp.clone(),
+                        rpe: None,
+                        flags: flags.clone(),
+                    },
+                )?;
+
+                // If set exists, update it; otherwise create new
+                if existing_set.is_some() {
+                    // Update existing set
+                    sqlx::query(
+                        r#"
+                        UPDATE exercise_sets
+                        SET weight = ?, reps = ?, bodyweight = ?, body_mass = ?, tempo = ?, pause = ?, amrap = ?,
+                            band = ?, band_tension = ?, chain_weight = ?, ignore_for_one_rm = ?, status = ?, flags = ?
+                        WHERE id = ?
+                        "#,
+                    )
+                    .bind(parsed_weight.unwrap_or(0.0))
+                    .bind(reps)
+                    .bind(is_bodyweight as i32)
+                    .bind(body_mass)
+                    .bind(&tempo)
+                    .bind(&pause)
+                    .bind(amrap as i32)
+                    .bind(&band)
+                    .bind(band_tension)
+                    .bind(chains)
+                    .bind(ignore_for_one_rm as i32)
+                    .bind(status.as_str())
+                    .bind(&flags)
+                    .bind(&set_id)
+                    .execute(&mut *tx)
+                    .await?;
+                } else {
+                    // Insert new set
+                    sqlx::query(
+                        r#"
+                        INSERT INTO exercise_sets (
+                            id,
+                            session_exercise_id,
+                            weight,
+                            reps,
+                            bodyweight,
+                            body_mass,
+                            tempo,
+                            pause,
+                            amrap,
+                            band,
+                            band_tension,
+                            chain_weight,
+                            ignore_for_one_rm,
+                            side,
+                            status,
+                            timestamp,
+                            flags
+                        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                        "#,
+                    )
+                    .bind(&set_id)
+                    .bind(&session_exercise_id)
+                    .bind(parsed_weight.unwrap_or(0.0))
+                    .bind(reps)
+                    .bind(is_bodyweight as i32)
+                    .bind(body_mass)
+                    .bind(&tempo)
+                    .bind(&pause)
+                    .bind(amrap as i32)
+                    .bind(&band)
+                    .bind(band_tension)
+                    .bind(chains)
+                    .bind(ignore_for_one_rm as i32)
+                    .bind(*side)
+                    .bind(status.as_str())
+                    .bind(&timestamp)
+                    .bind(&flags)
+                    .execute(&mut *tx)
+                    .await?;
+                }
             }
 
             // Check if this is a new PR
-            let is_pr = if !is_bodyweight {
-                let current_estimated_1rm = epley_1rm(parsed_weight.unwrap_or(0.0), reps);
+            let is_pr = if ignore_for_one_rm {
+                false
+            } else if !is_bodyweight {
+                let current_estimated_1rm = estimate_1rm(one_rm_weight.unwrap_or(0.0), reps);
                 
                 let best_pr_1rm: Option<f32> = sqlx::query_scalar(
                     r#"
@@ -900,7 +1501,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 let estimated_1rm = if is_bodyweight {
                     0.0 // For bodyweight exercises, we don't calculate 1RM
                 } else {
-                    epley_1rm(parsed_weight.unwrap_or(0.0), reps)
+                    estimate_1rm(one_rm_weight.unwrap_or(0.0), reps)
                 };
 
                 // Insert new PR
@@ -950,79 +1551,108 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
             } else {
                 "weighted"
             };
-            let weight_display = if is_bodyweight {
-                "bodyweight".to_string()
+            let added = parsed_weight.unwrap_or(0.0);
+            let weight_display = if !is_bodyweight {
+                format!("{}kg", kg(added))
+            } else if added > 0.0 {
+                tf("bodyweight + {}kg", &[&kg(added)])
             } else {
-                format!("{}kg", parsed_weight.unwrap_or(0.0))
+                "bodyweight".to_string()
             };
 
-            println!(
-                "{} logged {} set {} for exercise {} ({} × {})",
-                "ok:".green().bold(),
-                set_type,
-                set_index + 1,
-                exercise,
-                weight_display,
-                reps
-            );
+            let side_label = match sides.as_slice() {
+                [Some(s)] => format!(" ({})", s),
+                [Some(_), Some(_)] => " (L+R)".to_string(),
+                _ => String::new(),
+            };
+
+            match status {
+                SetStatus::Skipped => ui::ok(tf("skipped set {}{} of exercise {}", &[&(set_index + 1), &side_label, &exercise])),
+                SetStatus::Failed => ui::ok(tf(
+                    "logged failed {} set {}{} for exercise {} ({} × {})",
+                    &[&set_type, &(set_index + 1), &side_label, &exercise, &weight_display, &reps],
+                )),
+                SetStatus::Completed => ui::ok(tf(
+                    "logged {} set {}{} for exercise {} ({} × {})",
+                    &[&set_type, &(set_index + 1), &side_label, &exercise, &weight_display, &reps],
+                )),
+            }
+
+            if is_bodyweight && added > 0.0 && !skip {
+                match body_mass {
+                    Some(bm) => ui::outln!(
+                        "{}",
+                        tf("total load {}kg ({}× bodyweight)", &[&kg(bm + added), &number(((bm + added) / bm) as f64, 2)]).dimmed()
+                    ),
+                    None => ui::outln!(
+                        "{} {}",
+                        tr("note:").highlight().bold(),
+                        tr("set `bodyweight` in config to count the total load of weighted bodyweight sets")
+                    ),
+                }
+            }
+
+            if amrap && !skip {
+                ui::outln!("{} {}", tr("note:").highlight().bold(), tf("AMRAP set logged ({} reps)", &[&reps]));
+            }
 
             if is_pr {
-                println!("{} new personal record!", "note:".yellow().bold());
+                ui::outln!("{} {}", tr("note:").accent().bold(), tr("new personal record!"));
             }
         }
 
-        SessionCmd::End => {
+        SessionCmd::End { tags } => {
             // Check if there's an active session
             let session: Option<(String, String, String)> = sqlx::query_as(
                 r#"
                 SELECT ts.id, ts.start_time, pb.name
                 FROM training_sessions ts
                 JOIN program_blocks pb ON pb.id = ts.program_block_id
-                WHERE ts.end_time IS NULL
-                LIMIT 1
+                WHERE ts.id = ?
                 "#,
             )
+            .bind(&active)
             .fetch_optional(pool)
             .await?;
 
             let (session_id, start_time, block_name) = match session {
                 Some(s) => s,
-                None => {
-                    println!("{} no active session", "error:".red().bold());
-                    return Ok(());
-                }
+                None => return Err(AppError::NoActiveSession.into()),
             };
 
             // Start a transaction
             let mut tx = pool.begin().await?;
 
             // Get all exercises and their sets for this session
-            let exercises = sqlx::query_as::<_, (String, String, i32, Option<f32>, bool)>(
+            let exercises = sqlx::query_as::<_, (String, String, i32, Option<f32>, bool, f32)>(
                 r#"
                 SELECT 
                     e.id,
                     e.name,
                     es.reps,
                     es.weight,
-                    es.bodyweight
+                    es.bodyweight,
+                    CAST(COALESCE(es.band_tension, 0) + COALESCE(es.chain_weight, 0) AS REAL)
                 FROM training_session_exercises tse
                 JOIN exercises e ON e.id = tse.exercise_id
                 JOIN exercise_sets es ON es.session_exercise_id = tse.id
                 WHERE tse.training_session_id = ?
-                ORDER BY es.timestamp
+                AND es.status <> 'skipped'
+                ORDER BY tse.order_index, es.set_index
                 "#,
             )
             .bind(&session_id)
             .fetch_all(&mut *tx)
             .await?;
 
-            // Group sets by exercise
-            let mut exercise_sets: HashMap<String, Vec<(i32, Option<f32>, bool)>> = HashMap::new();
-            for (ex_id, _ex_name, reps, weight, bw) in exercises {
-                exercise_sets
-                    .entry(ex_id)
-                    .or_default()
-                    .push((reps, weight, bw));
+            // Group sets by exercise, in session order; an exercise added
+            // twice keeps its first place.
+            let mut exercise_sets: Vec<(String, Vec<(i32, Option<f32>, bool, f32)>)> = Vec::new();
+            for (ex_id, _ex_name, reps, weight, bw, extra) in exercises {
+                match exercise_sets.iter_mut().find(|(id, _)| *id == ex_id) {
+                    Some((_, sets)) => sets.push((reps, weight, bw, extra)),
+                    None => exercise_sets.push((ex_id, vec![(reps, weight, bw, extra)])),
+                }
             }
 
             // Process PRs and exercise stats
@@ -1033,7 +1663,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 let mut pr_weight = 0.0;
                 let mut pr_reps = 0;
 
-                for (reps, weight, bw) in sets {
+                for (reps, weight, bw, extra) in sets {
                     if *bw {
                         // For bodyweight exercises, we only track reps
                         if *reps > pr_reps {
@@ -1041,8 +1671,12 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                             pr_weight = 0.0;
                         }
                     } else if let Some(w) = weight {
+                        // Bands/chains either don't count or count partially
+                        let Some(load) = accommodating.one_rm_weight(*w, *extra) else {
+                            continue;
+                        };
                         // For weighted exercises, calculate estimated 1RM
-                        let est_1rm = epley_1rm(*w, *reps);
+                        let est_1rm = estimate_1rm(load, *reps);
                         if est_1rm > max_1rm {
                             max_1rm = est_1rm;
                             pr_weight = *w;
@@ -1118,44 +1752,56 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 .await?;
             }
 
+            // A session ended while paused resumes at its end.
+            sqlx::query(
+                "UPDATE session_pauses SET resumed_at = datetime('now') WHERE training_session_id = ? AND resumed_at IS NULL",
+            )
+            .bind(&session_id)
+            .execute(&mut *tx)
+            .await?;
+
             // Mark session as ended
             sqlx::query("UPDATE training_sessions SET end_time = datetime('now') WHERE id = ?")
                 .bind(&session_id)
                 .execute(&mut *tx)
                 .await?;
 
+            for tag in tags.iter().map(|t| t.trim()).filter(|t| !t.is_empty()) {
+                sqlx::query("INSERT OR IGNORE INTO session_tags (training_session_id, tag) VALUES (?, ?)")
+                    .bind(&session_id)
+                    .bind(tag)
+                    .execute(&mut *tx)
+                    .await?;
+            }
+
             // Commit the transaction
             tx.commit().await?;
+            wal::discard(&session_id)?;
 
-            // Calculate session duration
-            let duration = sqlx::query_scalar::<_, String>(
-                r#"
-                SELECT strftime('%H:%M:%S', 
-                    strftime('%s', 'now') - strftime('%s', ?) || ' seconds', 
-                    'unixepoch'
-                )
-                "#,
-            )
-            .bind(&start_time)
-            .fetch_one(pool)
-            .await?;
+            // Calculate session duration, leaving out paused time
+            let paused = paused_secs(pool, &session_id).await?;
+            let elapsed: i64 = sqlx::query_scalar("SELECT CAST(strftime('%s', 'now') - strftime('%s', ?) AS INTEGER)")
+                .bind(&start_time)
+                .fetch_one(pool)
+                .await?;
+            let duration = hms(elapsed - paused);
 
             // Print summary
-            println!(
-                "{} session ended (id: {})",
-                "ok:".green().bold(),
-                session_id
-            );
-            println!(
-                "{} {} — {} (duration: {})",
-                "Session:".cyan().bold(),
-                block_name.bold(),
-                start_time[..16].to_string(),
-                duration
+            ui::ok(tf("session ended (id: {})", &[&session_id]));
+            ui::outln!(
+                "{} {}",
+                tr("Session:").heading().bold(),
+                tf("{} — {} (duration: {})", &[&block_name.bold(), &started_at(&start_time), &duration])
             );
+            if !tags.is_empty() {
+                ui::outln!("{} {}", tr("Tags:").heading().bold(), tags.join(", "));
+            }
+            if paused > 0 {
+                ui::outln!("{} {}", tr("Paused:").heading().bold(), hms(paused).dimmed());
+            }
 
             // Print exercise summary
-            println!("\n{}", "Exercises:".cyan().bold());
+            ui::outln!("\n{}", tr("Exercises:").heading().bold());
             for (ex_id, sets) in &exercise_sets {
                 let exercise_name: String =
                     sqlx::query_scalar("SELECT name FROM exercises WHERE id = ?")
@@ -1163,33 +1809,89 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         .fetch_one(pool)
                         .await?;
 
-                println!("• {}", exercise_name.bold());
-                for (reps, weight, bw) in sets {
+                ui::outln!("• {}", exercise_name.bold());
+                for (reps, weight, bw, _) in sets {
                     if *bw {
-                        println!("  - {} reps (bodyweight)", reps);
+                        ui::outln!("  - {} reps (bodyweight)", reps);
                     } else if let Some(w) = weight {
-                        println!("  - {}kg × {}", w, reps);
+                        ui::outln!("  - {}kg × {}", kg(*w), reps);
                     }
                 }
             }
+
+            // Ghost comparison against the last time this block was trained
+            if let Some(prev) = previous_of_block(pool, &session_id).await? {
+                ui::outln!();
+                print_comparison(pool, &prev, &session_id).await?;
+            }
+
+            advance_after_session(pool, &session_id).await?;
+        }
+
+        SessionCmd::Circuit { circuit, done } => {
+            let session_id = match active {
+                Some(id) => id,
+                None => return Err(AppError::NoActiveSession.into()),
+            };
+            circuit::handle(pool, &session_id, circuit, done, bodyweight).await?;
+        }
+
+        SessionCmd::TopSet { exercise, rpe, reps, from, drop, sets } => {
+            let session_id = match active {
+                Some(id) => id,
+                None => return Err(AppError::NoActiveSession.into()),
+            };
+            top_set::handle(pool, &session_id, exercise, rpe, reps, from, drop, sets, rules).await?;
+        }
+
+        SessionCmd::Pause => {
+            let Some(id) = active else {
+                return Err(AppError::NoActiveSession.into());
+            };
+            if is_paused(pool, &id).await? {
+                return Err(AppError::Invalid(tr("session is already paused").into()).into());
+            }
+
+            sqlx::query("INSERT INTO session_pauses (training_session_id, paused_at) VALUES (?, datetime('now'))")
+                .bind(&id)
+                .execute(pool)
+                .await?;
+
+            ui::ok(tr("session paused, `lazarus resume` to pick it back up"));
+        }
+
+        SessionCmd::Resume => {
+            let Some(id) = active else {
+                return Err(AppError::NoActiveSession.into());
+            };
+
+            let paused_at: Option<String> = sqlx::query_scalar(
+                r#"
+                UPDATE session_pauses SET resumed_at = datetime('now')
+                WHERE training_session_id = ? AND resumed_at IS NULL
+                RETURNING paused_at
+                "#,
+            )
+            .bind(&id)
+            .fetch_optional(pool)
+            .await?;
+
+            match paused_at {
+                Some(_) => {
+                    let total = paused_secs(pool, &id).await?;
+                    ui::ok(tf("session resumed ({} paused in total)", &[&hms(total)]));
+                }
+                None => return Err(AppError::Invalid(tr("session is not paused").into()).into()),
+            }
         }
 
         SessionCmd::Swap {
             exercise,
             new_exercise,
         } => {
-            // Check if there's an active session
-            let session: Option<(String,)> =
-                sqlx::query_as("SELECT id FROM current_session LIMIT 1")
-                    .fetch_optional(pool)
-                    .await?;
-
-            let session_id = match session {
-                Some((id,)) => id,
-                None => {
-                    println!("{} no active session", "error:".red().bold());
-                    return Ok(());
-                }
+            let session_id = match active {
+                Some(id) => id,
+                None => return Err(AppError::NoActiveSession.into()),
             };
 
             // Get information about the current session's block
@@ -1199,22 +1901,24 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     .fetch_one(pool)
                     .await?;
 
-            // Get the exercise to replace info with its order_index
-            let old_exercise_info: Option<(String, String, String)> = sqlx::query_as(
+            // Get the exercise to replace info with its order_index, and the
+            // program exercise it stands for (itself unless already swapped or rotated)
+            let old_exercise_info: Option<(String, String, String, String)> = sqlx::query_as(
                 r#"
                 WITH session_exercise_order AS (
-                    -- Use SQLite rowid to maintain original insertion order
+                    -- Workout order, as logged or rearranged with `move-ex`
                     SELECT 
                         tse.id as tse_id,
                         tse.exercise_id,
-                        ROW_NUMBER() OVER (ORDER BY tse.rowid) as display_order
+                        ROW_NUMBER() OVER (ORDER BY tse.order_index) as display_order
                     FROM training_session_exercises tse
                     WHERE tse.training_session_id = ?
                 )
-                SELECT tse.id, tse.exercise_id, e.name
+                SELECT tse.id, tse.exercise_id, e.name, pe.name
                 FROM training_session_exercises tse
                 JOIN session_exercise_order seo ON seo.tse_id = tse.id
                 JOIN exercises e ON e.id = tse.exercise_id
+                JOIN exercises pe ON pe.id = COALESCE(tse.rotated_from, tse.exercise_id)
                 WHERE tse.training_session_id = ?
                 ORDER BY seo.display_order
                 LIMIT 1 OFFSET ?
@@ -1226,25 +1930,56 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
             .fetch_optional(pool)
             .await?;
 
-            let (old_session_exercise_id, old_exercise_id, old_exercise_name) =
+            let (old_session_exercise_id, old_exercise_id, old_exercise_name, program_exercise_name) =
                 match old_exercise_info {
                     Some(info) => info,
                     None => {
-                        println!(
-                            "{} no exercise at index {} in current session",
-                            "error:".red().bold(),
-                            exercise
-                        );
-                        return Ok(());
+                        return Err(AppError::NotFound(tf("no exercise at index {} in current session", &[&exercise]).into()).into());
                     }
                 };
 
+            // No exercise given: offer the program's substitutes for it
+            let Some(new_exercise) = new_exercise else {
+                let program_id: String =
+                    sqlx::query_scalar("SELECT program_id FROM program_blocks WHERE id = ?")
+                        .bind(&program_block_id)
+                        .fetch_one(pool)
+                        .await?;
+                let groups: Vec<(String, Vec<String>)> = substitution_groups(pool, &program_id)
+                    .await?
+                    .into_iter()
+                    .filter(|(_, exs)| exs.iter().any(|e| e.eq_ignore_ascii_case(&program_exercise_name)))
+                    .collect();
+                if groups.is_empty() {
+                    ui::info(tf("`{}` is in no substitution group of this program", &[&program_exercise_name]));
+                    return Ok(());
+                }
+
+                ui::outln!("{}", tf("Substitutes for {}:", &[&program_exercise_name.bold()]).heading());
+                for (group, exs) in groups {
+                    ui::outln!("  {}", format!("{}:", group).bold());
+                    for ex in exs.iter().filter(|e| !e.eq_ignore_ascii_case(&old_exercise_name)) {
+                        let swaps = swap_count(pool, &program_id, ex).await?;
+                        let used = if swaps > 0 {
+                            format!(" {}", tf("(swapped in {}×)", &[&swaps]).dimmed())
+                        } else {
+                            String::new()
+                        };
+                        ui::outln!("    {}{}", ex, used);
+                    }
+                }
+                ui::outln!("{}", tf("swap with `session swap {} \"<name>\"`", &[&exercise]).dimmed());
+                return Ok(());
+            };
+
             // Get the original exercise's set count from the program for display purposes
             let original_sets: i32 = sqlx::query_scalar(
                 "SELECT COALESCE(pe.sets, 2) FROM program_exercises pe 
-                 WHERE pe.program_block_id = ? AND pe.exercise_id = ?"
+                 WHERE pe.program_block_id = ? AND pe.exercise_id = COALESCE(
+                     (SELECT rotated_from FROM training_session_exercises WHERE id = ?), ?)"
             )
             .bind(&program_block_id)
+            .bind(&old_session_exercise_id)
             .bind(&old_exercise_id)
             .fetch_optional(pool)
             .await?
@@ -1266,10 +2001,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 .await?
                 {
                     Some(id) => id,
-                    None => {
-                        println!("{} no exercise at index {}", "error:".red().bold(), idx);
-                        return Ok(());
-                    }
+                    None => return Err(AppError::ExerciseNotFound(idx.to_string()).into()),
                 }
             } else {
                 // User provided an exercise name
@@ -1279,14 +2011,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     .await?
                 {
                     Some(id) => id,
-                    None => {
-                        println!(
-                            "{} no exercise named `{}`",
-                            "error:".red().bold(),
-                            new_exercise
-                        );
-                        return Ok(());
-                    }
+                    None => return Err(AppError::ExerciseNotFound(new_exercise.to_string()).into()),
                 }
             };
 
@@ -1309,42 +2034,36 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
             .fetch_optional(&mut *tx)
             .await?;
 
-            // ONLY update the training_session_exercise record - DO NOT modify program_exercises
-            sqlx::query("UPDATE training_session_exercises SET exercise_id = ? WHERE id = ?")
-                .bind(&new_exercise_id)
-                .bind(&old_session_exercise_id)
-                .execute(&mut *tx)
-                .await?;
+            // ONLY update the training_session_exercise record - DO NOT modify program_exercises.
+            // It remembers the program exercise it replaces, so the prescription follows
+            // and swapping back to it clears the swap.
+            sqlx::query(
+                r#"
+                UPDATE training_session_exercises
+                SET rotated_from = NULLIF(COALESCE(rotated_from, exercise_id), ?1),
+                    swapped = NULLIF(COALESCE(rotated_from, exercise_id), ?1) IS NOT NULL,
+                    exercise_id = ?1
+                WHERE id = ?2
+                "#,
+            )
+            .bind(&new_exercise_id)
+            .bind(&old_session_exercise_id)
+            .execute(&mut *tx)
+            .await?;
 
             // Commit the transaction
             tx.commit().await?;
 
             // Show success message
-            println!(
-                "{} swapped {} with {} ({} sets{})",
-                "ok:".green().bold(),
-                old_exercise_name.bold(),
-                new_exercise_name.bold(),
-                original_sets,
-                reps.as_deref()
-                    .map(|r| format!(" of {}", r))
-                    .unwrap_or_default()
-            );
+            ui::ok(tf("swapped {} with {} ({} sets{})", &[&old_exercise_name.bold(), &new_exercise_name.bold(), &original_sets, &reps.as_deref()
+                .map(|r| format!(" of {}", r))
+                .unwrap_or_default()]));
         }
 
-        SessionCmd::AddEx { exercise, sets } => {
-            // Check if there's an active session
-            let session: Option<(String,)> =
-                sqlx::query_as("SELECT id FROM current_session LIMIT 1")
-                    .fetch_optional(pool)
-                    .await?;
-
-            let session_id = match session {
-                Some((id,)) => id,
-                None => {
-                    println!("{} no active session", "error:".red().bold());
-                    return Ok(());
-                }
+        SessionCmd::AddEx { exercise, sets, create, muscle } => {
+            let session_id = match active {
+                Some(id) => id,
+                None => return Err(AppError::NoActiveSession.into()),
             };
 
             // Resolve the exercise (by index or name)
@@ -1363,22 +2082,30 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 .await?
                 {
                     Some(id) => id,
-                    None => {
-                        println!("{} no exercise at index {}", "error:".red().bold(), idx);
-                        return Ok(());
-                    }
+                    None => return Err(AppError::ExerciseNotFound(idx.to_string()).into()),
                 }
             } else {
-                // User provided an exercise name
-                match sqlx::query_scalar::<_, String>("SELECT id FROM exercises WHERE name = ?")
-                    .bind(&exercise)
-                    .fetch_optional(pool)
-                    .await?
-                {
-                    Some(id) => id,
-                    None => {
-                        println!("{} no exercise named `{}`", "error:".red().bold(), exercise);
-                        return Ok(());
+                // User provided an exercise name; the exact spelling wins over a different case
+                let found = sqlx::query_scalar::<_, String>(
+                    "SELECT id FROM exercises WHERE name = ?1 COLLATE NOCASE ORDER BY name = ?1 DESC LIMIT 1",
+                )
+                .bind(exercise.trim())
+                .fetch_optional(pool)
+                .await?;
+                match (found, muscle) {
+                    (Some(id), _) => id,
+                    (None, Some(muscle)) if create => create_exercise(pool, exercise.trim(), &muscle).await?,
+                    (None, _) => {
+                        let similar = similar_names(pool, &exercise).await?;
+                        if similar.is_empty() {
+                            return Err(AppError::ExerciseNotFound(exercise.to_string()).into());
+                        }
+                        let similar: Vec<String> = similar.iter().map(|n| format!("'{}'", n)).collect();
+                        return Err(AppError::NotFound(tf(
+                            "no exercise named `{}` -- did you mean {}? (or add it with `--create --muscle <muscle>`)",
+                            &[&exercise, &similar.join(", ")],
+                        ))
+                        .into());
                     }
                 }
             };
@@ -1408,25 +2135,17 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
             tx.commit().await?;
 
             // Show success message
-            println!(
-                "{} added {} ({} sets)",
-                "ok:".green().bold(),
-                exercise_name.bold(),
-                sets
-            );
+            ui::ok(tf("added {} ({} sets)", &[&exercise_name.bold(), &sets]));
         }
 
         SessionCmd::Note { exercise, note } => {
-            let session_id: String = sqlx::query_scalar("SELECT id FROM current_session")
-                .fetch_optional(pool)
-                .await?
-                .ok_or_else(|| anyhow::anyhow!("no active session"))?;
+            let session_id = active.ok_or_else(|| anyhow::anyhow!("no active session"))?;
 
             let tse_id: String = sqlx::query_scalar(
                 r#"
                 WITH ordered AS (
                     SELECT tse.id,
-                           ROW_NUMBER() OVER (ORDER BY tse.rowid) AS rn
+                           ROW_NUMBER() OVER (ORDER BY tse.order_index) AS rn
                     FROM training_session_exercises tse
                     WHERE tse.training_session_id = ?
                 )
@@ -1445,16 +2164,72 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 .execute(pool)
                 .await?;
 
-            println!(
-                "{} note saved for exercise {}",
-                "ok:".green().bold(),
-                exercise
-            );
+            ui::ok(tf("note saved for exercise {}", &[&exercise]));
+        }
+
+        SessionCmd::MoveEx { exercise, to } => {
+            let session_id = active.ok_or(AppError::NoActiveSession)?;
+            let mut order = session_exercises(pool, &session_id).await?;
+            for idx in [exercise, to] {
+                if idx == 0 || idx > order.len() {
+                    return Err(AppError::Invalid(tf("no exercise at index {}", &[&idx])).into());
+                }
+            }
+
+            let moved = order.remove(exercise - 1);
+            let name = moved.1.clone();
+            order.insert(to - 1, moved);
+
+            let mut tx = pool.begin().await?;
+            for (i, (tse_id, _)) in order.iter().enumerate() {
+                sqlx::query("UPDATE training_session_exercises SET order_index = ? WHERE id = ?")
+                    .bind(i as i64)
+                    .bind(tse_id)
+                    .execute(&mut *tx)
+                    .await?;
+            }
+            tx.commit().await?;
+
+            ui::ok(tf("moved {} to position {}", &[&name.bold(), &to]));
         }
 
-        SessionCmd::Log { date } => {
-            // Parse the date string (format: DD-MM-YYYY)
-            let date = NaiveDate::parse_from_str(&date, "%d-%m-%Y")?;
+        SessionCmd::RemoveEx { exercise, force } => {
+            let session_id = active.ok_or(AppError::NoActiveSession)?;
+            let order = session_exercises(pool, &session_id).await?;
+            let Some((tse_id, name)) = exercise.checked_sub(1).and_then(|i| order.get(i)) else {
+                return Err(AppError::Invalid(tf("no exercise at index {}", &[&exercise])).into());
+            };
+
+            let logged: i64 = sqlx::query_scalar("SELECT COUNT(*) FROM exercise_sets WHERE session_exercise_id = ?")
+                .bind(tse_id)
+                .fetch_one(pool)
+                .await?;
+            if logged > 0 && !force {
+                return Err(AppError::Invalid(tf(
+                    "{} already has {} logged set(s), pass --force to remove them too",
+                    &[name, &logged],
+                ))
+                .into());
+            }
+
+            let mut tx = pool.begin().await?;
+            sqlx::query("DELETE FROM exercise_sets WHERE session_exercise_id = ?")
+                .bind(tse_id)
+                .execute(&mut *tx)
+                .await?;
+            sqlx::query("DELETE FROM training_session_exercises WHERE id = ?")
+                .bind(tse_id)
+                .execute(&mut *tx)
+                .await?;
+            tx.commit().await?;
+
+            ui::ok(tf("removed {} from the session", &[&name.bold()]));
+        }
+
+        SessionCmd::Log { date, vs_target } => {
+            let Some(date) = parse_date(Some(&date)) else {
+                return Err(AppError::Invalid(tf("invalid date `{}` (expected {})", &[&date, &DATE_FORMATS])).into());
+            };
             
             // Get session info for the given date
             let session: Option<(String, String, String, String)> = sqlx::query_as(
@@ -1474,35 +2249,57 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
             let (session_id, start_time, block_name, block_desc) = match session {
                 Some(s) => s,
                 None => {
-                    println!("{} no completed session found for {}", "error:".red().bold(), date.format("%d-%m-%Y"));
-                    return Ok(());
+                    return Err(AppError::NotFound(tf("no completed session found for {}", &[&short_date(date)]).into()).into());
                 }
             };
 
-            // Calculate session duration
-            let duration = sqlx::query_scalar::<_, String>(
-                r#"
-                SELECT strftime('%H:%M:%S', 
-                    strftime('%s', end_time) - strftime('%s', start_time) || ' seconds', 
-                    'unixepoch'
-                )
-                FROM training_sessions
-                WHERE id = ?
-                "#,
+            // Calculate session duration, leaving out paused time
+            let paused = paused_secs(pool, &session_id).await?;
+            let elapsed: i64 = sqlx::query_scalar(
+                "SELECT CAST(strftime('%s', end_time) - strftime('%s', start_time) AS INTEGER) FROM training_sessions WHERE id = ?",
             )
             .bind(&session_id)
             .fetch_one(pool)
             .await?;
+            let duration = hms(elapsed - paused);
+
+            if vs_target {
+                let title = tf("{} — {} (started {}, duration: {})", &[&block_name.bold(), &block_desc.dimmed(), &started_at(&start_time), &duration]);
+                return vs_target::print(pool, &session_id, &title, rules).await;
+            }
 
             // Print session header
-            println!(
-                "{} {} — {} (started {}, duration: {})",
-                "Session:".cyan().bold(),
-                block_name.bold(),
-                block_desc.dimmed(),
-                &start_time[..16],
-                duration
+            ui::outln!(
+                "{} {}",
+                tr("Session:").heading().bold(),
+                tf("{} — {} (started {}, duration: {})", &[&block_name.bold(), &block_desc.dimmed(), &started_at(&start_time), &duration])
             );
+            let tags = session_tags(pool, &session_id).await?;
+            if !tags.is_empty() {
+                ui::outln!("{} {}", tr("Tags:").heading().bold(), tags.join(", "));
+            }
+            let checklist = session_checklist(pool, &session_id).await?;
+            if !checklist.is_empty() {
+                ui::outln!("{} {}", tr("Checklist:").heading().bold(), checklist_line(&checklist));
+            }
+            if paused > 0 {
+                ui::outln!("{} {}", tr("Paused:").heading().bold(), hms(paused).dimmed());
+            }
+            let program_changed: bool = sqlx::query_scalar(
+                r#"
+                SELECT EXISTS (
+                    SELECT 1 FROM program_exercise_versions v
+                    JOIN training_sessions ts ON ts.program_block_id = v.program_block_id
+                    WHERE ts.id = ? AND v.valid_to > ts.start_time
+                )
+                "#,
+            )
+            .bind(&session_id)
+            .fetch_one(pool)
+            .await?;
+            if program_changed {
+                ui::outln!("{}", tr("(targets as prescribed then; the block has changed since)").dimmed());
+            }
 
             // Get exercises with their PRs
             let exercises = sqlx::query_as::<
@@ -1521,6 +2318,8 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     Option<String>,
                     Option<f32>,
                     String,
+                    Option<String>,
+                    Option<String>,
                 ),
             >(
                 r#"
@@ -1538,7 +2337,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     SELECT 
                         tse.id as tse_id,
                         tse.exercise_id,
-                        ROW_NUMBER() OVER (ORDER BY tse.rowid) as display_order
+                        ROW_NUMBER() OVER (ORDER BY tse.order_index) as display_order
                     FROM training_session_exercises tse
                     WHERE tse.training_session_id = ?
                 )
@@ -1555,11 +2354,13 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     pe.target_rpe,
                     pe.target_rm_percent,
                     pe.program_1rm,
-                    seo.tse_id
+                    seo.tse_id,
+                    pe.tempo,
+                    pe.pause
                 FROM training_session_exercises tse
                 JOIN session_exercise_order seo ON seo.tse_id = tse.id
                 JOIN exercises e ON e.id = tse.exercise_id
-                LEFT JOIN program_exercises pe ON pe.exercise_id = e.id 
+                LEFT JOIN program_exercises pe ON pe.exercise_id = COALESCE(tse.rotated_from, e.id)
                     AND pe.program_block_id = (
                         SELECT program_block_id 
                         FROM training_sessions 
@@ -1575,7 +2376,8 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
             .fetch_all(pool)
             .await?;
 
-            println!("\n{}", "Exercises:".cyan().bold());
+            ui::outln!("\n{}", tr("Exercises:").heading().bold());
+            let labels = superset_labels_of(pool, &session_id).await?;
 
             // Pre-calculate all previous set information to find the maximum width
             let mut prev_sets_info = Vec::new();
@@ -1595,6 +2397,8 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     _target_rm_percent,
                     _program_1rm,
                     _tse_id,
+                    _tempo,
+                    _pause,
                 ),
             ) in exercises.iter().enumerate()
             {
@@ -1613,7 +2417,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                                 tse.exercise_id,
                                 ROW_NUMBER() OVER (
                                     PARTITION BY tse.exercise_id, tse.id
-                                    ORDER BY es.timestamp
+                                    ORDER BY es.set_index
                                 ) - 1 as set_num
                             FROM exercise_sets es
                             JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
@@ -1644,7 +2448,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     .await?;
 
                     let prev_info = prev_set
-                        .map(|(w, r)| format!(" - {}kg × {}", w, r))
+                        .map(|(w, r)| format!(" - {}kg × {}", kg(w), r))
                         .unwrap_or_default();
 
                     exercise_prev_sets.push(prev_info);
@@ -1677,10 +2481,12 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     _target_rm_percent,
                     _program_1rm,
                     tse_id,
+                    tempo,
+                    pause,
                 ),
             ) in exercises.iter().enumerate()
             {
-                let idx = format!("{}", i + 1).yellow();
+                let idx = format!("{}", i + 1).accent();
 
                 // Get the best (highest 1RM) PR for this exercise
                 let (pr_weight, pr_reps, pr_1rm): (Option<f32>, Option<i32>, Option<f32>) =
@@ -1700,14 +2506,18 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
 
                 // Print exercise header with PR info
                 let pr_info = if let (Some(w), Some(r)) = (pr_weight, pr_reps) {
-                    let one_rm = pr_1rm.unwrap_or_else(|| epley_1rm(w, r).round());
-                    let actual_pr = format!("{}kg × {}", w, r).red().bold().to_string();
-                    format!(" - PR: {} (1RM: {:.1}kg)", actual_pr, one_rm)
+                    let one_rm = pr_1rm.unwrap_or_else(|| estimate_1rm(w, r).round());
+                    let actual_pr = format!("{}kg × {}", kg(w), r).bad().bold().to_string();
+                    format!(" - PR: {} (1RM: {}kg)", actual_pr, kg_places(one_rm, 1))
                 } else {
                     String::new()
                 };
 
-                println!("{} • {}{}", idx, ex_name.bold(), pr_info.dimmed());
+                let label = labels
+                    .get(tse_id.as_str())
+                    .map(|l| format!("{} ", l).highlight().bold().to_string())
+                    .unwrap_or_default();
+                ui::outln!("{} • {}{}{}", idx, label, ex_name.bold(), pr_info.dimmed());
 
                 // Print exercise note if it exists
                 let note: Option<String> = sqlx::query_scalar(
@@ -1719,10 +2529,18 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
 
                 if let Some(note) = note {
                     if note != "" {
-                        println!("    {} {}", "NOTE:".blue().bold(), note);
+                        print_note(&note);
                     }
                 }
 
+                // Machine settings remembered from last time
+                if let Some(settings) = settings_line(pool, ex_id).await? {
+                    ui::outln!("    {} {}", tr("EQUIP:").info().bold(), settings);
+                }
+                print_attachments(pool, &tse_id).await?;
+
+                let rounding = rounding_for(pool, &rules, ex_id).await?;
+
                 // Parse target values
                 let target_rpes: Vec<f32> = _target_rpe
                     .as_deref()
@@ -1734,23 +2552,35 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     .map(|s| s.split(',').filter_map(|v| v.trim().parse().ok()).collect())
                     .unwrap_or_default();
 
+
+                let pauses: Vec<&str> = pause
+
+                    .as_deref()
+
+                    .map(|s| s.split(',').map(|v| v.trim()).collect())
+
+                    .unwrap_or_default();
+
                 // Print sets
                 let reps_display = reps
                     .as_deref()
                     .map(|r| r.split(',').collect::<Vec<_>>())
                     .unwrap_or_default();
 
+                let unilateral = is_unilateral(pool, ex_id).await?;
+
                 // Get all logged sets for this exercise
                 let logged_sets_1_based_num = sqlx::query_as::<_, (i64, f32, i32, bool)>(
                     r#"
                     WITH set_numbers AS (
                         SELECT 
                             es.*,
-                            ROW_NUMBER() OVER (PARTITION BY tse.id ORDER BY es.timestamp) as set_num -- 1-based
+                            ROW_NUMBER() OVER (PARTITION BY tse.id ORDER BY es.set_index) as set_num -- 1-based
                         FROM exercise_sets es
                         JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
                         WHERE tse.exercise_id = ?
                         AND tse.training_session_id = ?
+                        AND es.side IS ? -- unilateral: L rows, R shown alongside
                     )
                     SELECT set_num, weight, reps, bodyweight
                     FROM set_numbers
@@ -1759,6 +2589,7 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                 )
                 .bind(ex_id)
                 .bind(&session_id)
+                .bind(unilateral.then_some("L"))
                 .fetch_all(pool)
                 .await?;
 
@@ -1799,31 +2630,55 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     all_sets
                 };
 
+                let accommodating_info = accommodating_by_set(pool, &session_id, ex_id).await?;
+                let statuses = set_statuses(pool, &session_id, ex_id).await?;
+                let flags = set_flags(pool, &session_id, ex_id).await?;
+                let right_side = if unilateral {
+                    right_side_sets(pool, &session_id, ex_id).await?
+                } else {
+                    HashMap::new()
+                };
+
+                print_set_targets(pool, tse_id, "warmup", *_program_1rm, &target_rms, rounding).await?;
+
                 // Display all sets
                 for (set_num_0_based_in_loop, weight, reps, bw) in sets_to_show {
                     let set_num_usize = set_num_0_based_in_loop as usize; // 0-based for array indexing
+                    // No %1RM for this set: suggest a load for the RPE from the e1RM.
+                    let e1rm = _est_1rm.filter(|_| set_num_usize >= target_rms.len());
                     let target_info = if let Some(program_1rm) = _program_1rm {
                         if set_num_usize < target_rpes.len() {
-                            format!(" @RPE {}", target_rpes[set_num_usize])
+                            rpe_target(target_rpes[set_num_usize], reps_display.get(set_num_usize).copied(), e1rm, rounding)
                         } else if set_num_usize < target_rms.len() {
                             let target_weight =
                                 program_1rm * (target_rms[set_num_usize] / 100.0);
                             format!(
-                                " @{}% ({}kg)",
+                                " @{}% ({})",
                                 target_rms[set_num_usize],
-                                target_weight.round()
+                                rounding.format(target_weight)
                             )
                         } else {
                             String::new()
                         }
                     } else {
                         if set_num_usize < target_rpes.len() {
-                            format!(" @RPE {}", target_rpes[set_num_usize])
+                            rpe_target(target_rpes[set_num_usize], reps_display.get(set_num_usize).copied(), e1rm, rounding)
                         } else {
                             String::new()
                         }
                     };
 
+                    let target_info = format!(
+
+                        "{}{}",
+
+                        target_info,
+
+                        tempo_suffix(tempo.as_deref(), pauses.get(set_num_usize).copied())
+
+                    );
+
+
                     // Get previous set info from our pre-calculated list
                     let prev_info = if set_num_usize < prev_sets_info[i].len() {
                         &prev_sets_info[i][set_num_usize]
@@ -1833,11 +2688,13 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     let prev_column =
                         format!("{:<width$}", prev_info, width = max_prev_width).dimmed();
 
-                    let target_reps = if set_num_usize < reps_display.len() {
-                        format!("{} reps", reps_display[set_num_usize])
-                    } else {
-                        String::from("do your thing")
-                    };
+                    let is_amrap = reps_display
+                        .get(set_num_usize)
+                        .is_some_and(|r| is_amrap_target(r));
+                    let target_reps = reps_display
+                        .get(set_num_usize)
+                        .map(|r| target_label(r))
+                        .unwrap_or_else(|| String::from("do your thing"));
 
                     let target_padding = if (target_reps.len() + target_info.len()) < 25 {
                         25 - (target_reps.len() + target_info.len())
@@ -1846,12 +2703,17 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                     };
 
                     // Create all parts of the display separately
-                    let set_num_str = format!("{}", set_num_0_based_in_loop + 1).yellow(); // Display as 1-based
+                    let set_num_str = format!("{}", set_num_0_based_in_loop + 1).accent(); // Display as 1-based
                     let indent = " ".repeat(2);
+                    let target_reps_colored = if is_amrap {
+                        target_reps.highlight().bold().to_string()
+                    } else {
+                        target_reps.clone()
+                    };
                     let target_part = if target_reps.is_empty() {
                         String::new()
                     } else {
-                        format!("{}{}", target_reps, target_info.dimmed())
+                        format!("{}{}", target_reps_colored, target_info.dimmed())
                     };
                     let padding = " ".repeat(target_padding);
 
@@ -1862,21 +2724,39 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         false
                     };
 
+                    let extra = accommodating_info
+                        .get(&set_num_0_based_in_loop)
+                        .map(String::as_str)
+                        .unwrap_or_default();
                     let current_info = if bw {
-                        format!("bw × {}", reps)
+                        bodyweight_label(weight, reps)
                     } else if weight > 0.0 {
-                        let set_info = format!("{}kg × {}", weight, reps);
+                        let set_info = format!("{}kg × {}{}", kg(weight), reps, extra);
                         if is_pr_set {
-                            set_info.green().bold().to_string()
+                            set_info.good().bold().to_string()
                         } else {
                             set_info
                         }
                     } else {
                         String::new()
                     };
+                    let current_info = match statuses.get(&set_num_0_based_in_loop) {
+                        Some(SetStatus::Skipped) => tr("skipped").dimmed().to_string(),
+                        Some(SetStatus::Failed) => format!("{} {}", current_info, tr("✗ failed").bad()),
+                        _ => current_info,
+                    };
+                    let current_info = match flags.get(&set_num_0_based_in_loop) {
+                        Some(f) if !current_info.is_empty() => format!("{} {}", current_info, flag_markers(f)),
+                        _ => current_info,
+                    };
+                    let current_info = if unilateral && !current_info.is_empty() {
+                        format!("L {}", current_info)
+                    } else {
+                        current_info
+                    };
 
                     // Print with explicit parts
-                    println!(
+                    ui::outln!(
                         " {} {} • {} {}{} | {}",
                         indent,
                         set_num_str,
@@ -1885,8 +2765,12 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
                         prev_column,
                         current_info
                     );
+                    if let Some(right) = right_side.get(&set_num_0_based_in_loop) {
+                        ui::outln!(" {}   {} {}", indent, "↳".dimmed(), right);
+                    }
                 }
-                println!();
+                print_set_targets(pool, tse_id, "backoff", *_program_1rm, &target_rms, rounding).await?;
+                ui::outln!();
             }
         }
     }
@@ -1894,11 +2778,570 @@ pub async fn handle(cmd: SessionCmd, pool: &SqlitePool) -> Result<()> {
     Ok(())
 }
 
-fn epley_1rm(weight: f32, reps: i32) -> f32 {
-    if reps == 0 {
-        0.0
+/// Add an exercise from `session add-ex --create`, returning its id.
+async fn create_exercise(pool: &SqlitePool, name: &str, muscle: &str) -> Result<String> {
+    let Some(muscle) = cannonical_muscle(muscle) else {
+        let msg = match best_muscle_suggestions(muscle) {
+            Some(m) => tf("unknown muscle `{}` -- did you mean `{}`?", &[&muscle, &m]),
+            None => tf("unknown muscle `{}`", &[&muscle]),
+        };
+        return Err(AppError::Invalid(msg).into());
+    };
+    let id = Uuid::new_v4().to_string();
+    sqlx::query(
+        r#"
+        INSERT INTO exercises (id, name, primary_muscle, description, created_at, unilateral)
+        VALUES (?, ?, ?, '', datetime('now'), 0)
+        "#,
+    )
+    .bind(&id)
+    .bind(name)
+    .bind(&muscle)
+    .execute(pool)
+    .await?;
+    ui::info(tf("Exercise \"{}\" added", &[&name]));
+    Ok(id)
+}
+
+/// A session's exercises as (session exercise id, name), in the order
+/// `session show` numbers them.
+async fn session_exercises(pool: &SqlitePool, session_id: &str) -> Result<Vec<(String, String)>> {
+    Ok(sqlx::query_as(
+        r#"
+        SELECT tse.id, e.name
+        FROM training_session_exercises tse
+        JOIN exercises e ON e.id = tse.exercise_id
+        WHERE tse.training_session_id = ?
+        ORDER BY tse.order_index
+        "#,
+    )
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?)
+}
+
+/// Superset labels ("A1", "A2", ...) of a session's exercises, keyed by
+/// session exercise id.
+async fn superset_labels_of(pool: &SqlitePool, session_id: &str) -> Result<HashMap<String, String>> {
+    let rows: Vec<(String, Option<String>)> = sqlx::query_as(
+        r#"
+        SELECT tse.id, pe.superset
+        FROM training_session_exercises tse
+        JOIN training_sessions ts ON ts.id = tse.training_session_id
+        LEFT JOIN program_exercises pe
+          ON pe.program_block_id = ts.program_block_id
+         AND pe.exercise_id = COALESCE(tse.rotated_from, tse.exercise_id)
+        WHERE tse.training_session_id = ?
+        ORDER BY tse.order_index
+        "#,
+    )
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?;
+
+    let groups: Vec<Option<String>> = rows.iter().map(|(_, g)| g.clone()).collect();
+    Ok(rows
+        .into_iter()
+        .zip(superset_labels(&groups))
+        .filter_map(|((id, _), label)| Some((id, label?)))
+        .collect())
+}
+
+/// A rep target like "8+" asks for as many reps as possible.
+pub fn is_amrap_target(reps: &str) -> bool {
+    RepTarget::parse(reps).is_some_and(RepTarget::is_amrap)
+}
+
+/// How a set's target reads in the log, e.g. "8-12 reps" or "30s hold".
+/// Anything the parser doesn't know is shown as written.
+fn target_label(reps: &str) -> String {
+    match RepTarget::parse(reps) {
+        Some(target) => target.label(),
+        None => format!("{} reps", reps.trim()),
+    }
+}
+
+/// Tempo and pause notation appended to a set's target, e.g. ` [3-1-1-0] (pause 2s)`.
+pub fn tempo_suffix(tempo: Option<&str>, pause: Option<&str>) -> String {
+    let mut out = String::new();
+    if let Some(t) = tempo.filter(|t| !t.is_empty()) {
+        out.push_str(&format!(" [{}]", t));
+    }
+    if let Some(p) = pause.filter(|p| !p.is_empty() && *p != "0") {
+        // Bare numbers are seconds.
+        if p.chars().all(|c| c.is_ascii_digit()) {
+            out.push_str(&format!(" (pause {}s)", p));
+        } else {
+            out.push_str(&format!(" (pause {})", p));
+        }
+    }
+    out
+}
+
+/// Rest assumed between sets when an exercise has no logged history.
+const DEFAULT_REST_SECS: f64 = 120.0;
+
+/// Average time between consecutive sets of an exercise, from its history.
+/// Gaps over 15 minutes are treated as interruptions and ignored.
+/// Band/chain annotations for the logged sets of one exercise, keyed by
+/// 0-based set number, e.g. " +band red (15kg) +chains 20kg".
+/// Failed and skipped sets of an exercise in a session, keyed by 0-based set
+/// number (left side only for unilateral exercises, like the set list).
+async fn set_statuses(pool: &SqlitePool, session_id: &str, exercise_id: &str) -> Result<HashMap<i64, SetStatus>> {
+    let rows = sqlx::query_as::<_, (i64, String)>(
+        r#"
+        WITH set_numbers AS (
+            SELECT
+                es.status,
+                ROW_NUMBER() OVER (PARTITION BY tse.id, es.side ORDER BY es.set_index) - 1 AS set_num
+            FROM exercise_sets es
+            JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+            WHERE tse.exercise_id = ?
+            AND tse.training_session_id = ?
+            AND es.side IS NOT 'R'
+        )
+        SELECT set_num, status
+        FROM set_numbers
+        WHERE status != 'completed'
+        "#,
+    )
+    .bind(exercise_id)
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?;
+
+    Ok(rows.into_iter().map(|(set_num, status)| (set_num, SetStatus::parse(&status))).collect())
+}
+
+/// Gear flags of the logged sets of an exercise in a session, keyed by 0-based
+/// set number (left side only for unilateral exercises, like the set list).
+async fn set_flags(pool: &SqlitePool, session_id: &str, exercise_id: &str) -> Result<HashMap<i64, String>> {
+    Ok(sqlx::query_as::<_, (i64, String)>(
+        r#"
+        WITH set_numbers AS (
+            SELECT
+                es.flags,
+                ROW_NUMBER() OVER (PARTITION BY tse.id, es.side ORDER BY es.set_index) - 1 AS set_num
+            FROM exercise_sets es
+            JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+            WHERE tse.exercise_id = ?
+            AND tse.training_session_id = ?
+            AND es.side IS NOT 'R'
+        )
+        SELECT set_num, flags
+        FROM set_numbers
+        WHERE COALESCE(flags, '') != ''
+        "#,
+    )
+    .bind(exercise_id)
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?
+    .into_iter()
+    .collect())
+}
+
+/// `[belt·straps]`, dimmed, for a set's gear flags.
+pub fn flag_markers(flags: &str) -> String {
+    format!("[{}]", flags.split(',').collect::<Vec<_>>().join("·")).dimmed().to_string()
+}
+
+async fn accommodating_by_set(
+    pool: &SqlitePool,
+    session_id: &str,
+    exercise_id: &str,
+) -> Result<HashMap<i64, String>> {
+    let rows = sqlx::query_as::<_, (i64, Option<String>, Option<f32>, Option<f32>)>(
+        r#"
+        WITH set_numbers AS (
+            SELECT
+                es.band, es.band_tension, es.chain_weight,
+                ROW_NUMBER() OVER (PARTITION BY tse.id, es.side ORDER BY es.set_index) - 1 AS set_num
+            FROM exercise_sets es
+            JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+            WHERE tse.exercise_id = ?
+            AND tse.training_session_id = ?
+            AND es.side IS NOT 'R'
+        )
+        SELECT set_num, band, band_tension, chain_weight
+        FROM set_numbers
+        WHERE band IS NOT NULL OR chain_weight IS NOT NULL
+        "#,
+    )
+    .bind(exercise_id)
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?;
+
+    Ok(rows
+        .into_iter()
+        .map(|(set_num, band, tension, chains)| {
+            let mut info = String::new();
+            if let Some(band) = band {
+                info.push_str(&format!(" +band {}", band));
+                if let Some(t) = tension {
+                    info.push_str(&format!(" ({}kg)", kg(t)));
+                }
+            }
+            if let Some(c) = chains {
+                info.push_str(&format!(" +chains {}kg", kg(c)));
+            }
+            (set_num, info)
+        })
+        .collect())
+}
+
+/// Rounding profile of an exercise: its own override, else the configured
+/// profile for its equipment.
+pub async fn rounding_for(pool: &SqlitePool, rules: &RoundingRules, exercise_id: &str) -> Result<Rounding> {
+    let (name, equipment, own): (String, Option<String>, Option<String>) =
+        sqlx::query_as("SELECT name, equipment, rounding FROM exercises WHERE id = ?")
+            .bind(exercise_id)
+            .fetch_one(pool)
+            .await?;
+
+    Ok(rules.pick(own.as_deref(), equipment_of(&name, equipment.as_deref()).as_deref()))
+}
+
+/// Print warm-up or back-off targets of one session exercise. Weights are a
+/// fraction of the top set: the heaviest set logged today, or before that the
+/// planned top (program 1RM × highest %) or last session's heaviest set.
+async fn print_set_targets(
+    pool: &SqlitePool,
+    tse_id: &str,
+    kind: &str,
+    program_1rm: Option<f32>,
+    target_rms: &[f32],
+    rounding: Rounding,
+) -> Result<()> {
+    let targets: Vec<(f32, Option<String>)> = sqlx::query_as(
+        "SELECT percent, reps FROM session_set_targets WHERE session_exercise_id = ? AND kind = ? ORDER BY position",
+    )
+    .bind(tse_id)
+    .bind(kind)
+    .fetch_all(pool)
+    .await?;
+    if targets.is_empty() {
+        return Ok(());
+    }
+
+    let logged_top: Option<f32> = sqlx::query_scalar(
+        "SELECT MAX(weight) FROM exercise_sets WHERE session_exercise_id = ? AND bodyweight = 0 AND weight > 0",
+    )
+    .bind(tse_id)
+    .fetch_one(pool)
+    .await?;
+    let planned_top = match program_1rm {
+        Some(rm) if !target_rms.is_empty() => {
+            Some(rm * target_rms.iter().cloned().fold(f32::MIN, f32::max) / 100.0)
+        }
+        _ => sqlx::query_scalar(
+            r#"
+            SELECT MAX(es.weight)
+            FROM exercise_sets es
+            JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+            WHERE tse.exercise_id = (SELECT exercise_id FROM training_session_exercises WHERE id = ?1)
+            AND tse.training_session_id = (
+                SELECT ts.id
+                FROM training_sessions ts
+                JOIN training_session_exercises t2 ON t2.training_session_id = ts.id
+                WHERE t2.exercise_id = (SELECT exercise_id FROM training_session_exercises WHERE id = ?1)
+                AND t2.id <> ?1
+                AND ts.end_time IS NOT NULL
+                ORDER BY ts.start_time DESC
+                LIMIT 1
+            )
+            "#,
+        )
+        .bind(tse_id)
+        .fetch_one(pool)
+        .await?,
+    };
+    let (top, source) = match (logged_top, planned_top) {
+        (Some(t), _) => (Some(t), "top set"),
+        (None, Some(t)) => (Some(t), "planned top"),
+        (None, None) => (None, ""),
+    };
+
+    let (label, tag) = if kind == "warmup" { ("warm-up", "W") } else { ("back-off", "B") };
+    for (i, (pct, reps)) in targets.iter().enumerate() {
+        let weight = top
+            .map(|t| rounding.format(t * pct))
+            .unwrap_or_else(|| "?kg".to_string());
+        let reps = reps.as_deref().map(|r| format!(" × {}", r)).unwrap_or_default();
+        ui::outln!(
+            "   {} • {}{} {}",
+            format!("{}{}", tag, i + 1).highlight(),
+            weight,
+            reps,
+            format!("({} {:.0}% of {})", label, pct * 100.0, source).dimmed()
+        );
+    }
+
+    Ok(())
+}
+
+/// "NOTE: ..." under an exercise; a note of several lines starts below the label.
+fn print_note(note: &str) {
+    let rendered = ui::markdown(note, 6);
+    if note.trim().lines().count() > 1 {
+        ui::outln!("    {}\n{}", tr("NOTE:").info().bold(), rendered);
     } else {
-        weight * (1.0 + reps as f32 / 30.0)
+        ui::outln!("    {} {}", tr("NOTE:").info().bold(), rendered.trim_start());
     }
 }
 
+async fn is_unilateral(pool: &SqlitePool, exercise_id: &str) -> Result<bool> {
+    let flag: Option<bool> =
+        sqlx::query_scalar("SELECT COALESCE(unilateral, 0) FROM exercises WHERE id = ?")
+            .bind(exercise_id)
+            .fetch_optional(pool)
+            .await?;
+    Ok(flag.unwrap_or(false))
+}
+
+/// Right-side sets of a unilateral exercise, keyed by 0-based set number and
+/// rendered as "R 20kg × 8", shown under the matching left-side row.
+async fn right_side_sets(
+    pool: &SqlitePool,
+    session_id: &str,
+    exercise_id: &str,
+) -> Result<HashMap<i64, String>> {
+    let rows = sqlx::query_as::<_, (i64, f32, i32, bool)>(
+        r#"
+        SELECT
+            ROW_NUMBER() OVER (PARTITION BY tse.id ORDER BY es.set_index) - 1 AS set_num,
+            es.weight, es.reps, es.bodyweight
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        WHERE tse.exercise_id = ?
+        AND tse.training_session_id = ?
+        AND es.side = 'R'
+        "#,
+    )
+    .bind(exercise_id)
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?;
+
+    Ok(rows
+        .into_iter()
+        .map(|(set_num, weight, reps, bw)| {
+            let info = if bw {
+                format!("R {}", bodyweight_label(weight, reps))
+            } else {
+                format!("R {}kg × {}", kg(weight), reps)
+            };
+            (set_num, info)
+        })
+        .collect())
+}
+
+async fn avg_rest_secs(pool: &SqlitePool, exercise_id: &str) -> Result<f64> {
+    let avg: Option<f64> = sqlx::query_scalar(
+        r#"
+        WITH gaps AS (
+            SELECT strftime('%s', es.timestamp) - strftime('%s', LAG(es.timestamp) OVER (
+                       PARTITION BY es.session_exercise_id
+                       ORDER BY es.set_index
+                   )) AS gap
+            FROM exercise_sets es
+            JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+            WHERE tse.exercise_id = ?
+        )
+        SELECT CAST(AVG(gap) AS REAL) FROM gaps WHERE gap > 0 AND gap < 900
+        "#,
+    )
+    .bind(exercise_id)
+    .fetch_one(pool)
+    .await?;
+
+    Ok(avg.unwrap_or(DEFAULT_REST_SECS))
+}
+
+/// Share of 1RM for `reps` at `rpe`, from the RPE chart: the RPE 10 column
+/// indexed by reps plus reps in reserve, interpolated for half RPEs.
+pub fn rpe_percent(reps: u32, rpe: f32) -> Option<f32> {
+    const RPE_10: [f32; 12] = [100.0, 95.5, 92.2, 89.2, 86.3, 83.7, 81.1, 78.6, 76.2, 73.9, 70.7, 68.0];
+    if !(5.0..=10.0).contains(&rpe) || reps == 0 {
+        return None;
+    }
+    let at = reps as f32 + (10.0 - rpe) - 1.0;
+    let lo = at.floor() as usize;
+    let hi = at.ceil() as usize;
+    let (a, b) = (*RPE_10.get(lo)?, *RPE_10.get(hi)?);
+    Some(a + (b - a) * (at - lo as f32))
+}
+
+/// " @RPE 8", with a suggested load when the exercise has an e1RM and the set
+/// a rep count, e.g. " @RPE 8 (~82.5kg)".
+fn rpe_target(rpe: f32, reps: Option<&str>, e1rm: Option<f32>, rounding: Rounding) -> String {
+    let load = reps
+        .and_then(RepTarget::parse)
+        .filter(|t| !matches!(t, RepTarget::Cluster { .. }))
+        .and_then(RepTarget::min_reps)
+        .zip(e1rm.filter(|e| *e > 0.0))
+        .and_then(|(reps, e1rm)| Some(e1rm * rpe_percent(reps, rpe)? / 100.0));
+    match load {
+        Some(kg) => format!(" @RPE {} (~{})", rpe, rounding.format(kg)),
+        None => format!(" @RPE {}", rpe),
+    }
+}
+
+fn estimate_1rm(weight: f32, reps: i32) -> f32 {
+    formula::e1rm(weight as f64, reps as f64) as f32
+}
+
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::{
+        cli::{Cli, Commands},
+        storage::OpenSession,
+        testutil::{self, memory_db},
+    };
+
+    fn exit_code(e: anyhow::Error) -> i32 {
+        e.downcast_ref::<AppError>().map_or(1, AppError::exit_code)
+    }
+
+    #[tokio::test]
+    async fn picking_the_active_session() {
+        let pool = memory_db().await;
+        assert_eq!(exit_code(active_session(&pool, None).await.unwrap_err()), 4);
+
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        let block = testutil::program_block(&pool, "P", "A", &[&squat], "5").await;
+        let home = testutil::session(&pool, &block, "2024-01-01 10:00:00", None).await;
+        assert_eq!(active_session(&pool, None).await.unwrap(), home);
+
+        let gym = testutil::session(&pool, &block, "2024-01-01 11:00:00", None).await;
+        sqlx::query("UPDATE training_sessions SET tag = 'gym' WHERE id = ?").bind(&gym).execute(&pool).await.unwrap();
+        assert_eq!(exit_code(active_session(&pool, None).await.unwrap_err()), 3);
+        assert_eq!(active_session(&pool, Some("gym")).await.unwrap(), gym);
+        assert_eq!(exit_code(active_session(&pool, Some("garage")).await.unwrap_err()), 2);
+    }
+
+    /// Open sessions without a database behind them.
+    struct Open(Vec<OpenSession>);
+
+    impl SessionStore for Open {
+        async fn open_sessions(&self) -> Result<Vec<OpenSession>> {
+            Ok(self.0.clone())
+        }
+    }
+
+    fn open(id: &str, tag: Option<&str>) -> OpenSession {
+        OpenSession {
+            id: id.into(),
+            tag: tag.map(str::to_string),
+            block: "A".into(),
+            program: "P".into(),
+            start_time: "2024-01-01 10:00:00".into(),
+            sets: 0,
+        }
+    }
+
+    #[tokio::test]
+    async fn picking_from_any_session_store() {
+        let store = Open(vec![open("home", None), open("gym", Some("gym"))]);
+        assert_eq!(active_session(&store, Some("gym")).await.unwrap(), "gym");
+        assert_eq!(exit_code(active_session(&store, None).await.unwrap_err()), 3);
+        assert_eq!(exit_code(active_session(&Open(Vec::new()), None).await.unwrap_err()), 4);
+    }
+
+    /// `lazarus session <args>`, parsed as the CLI parses it.
+    async fn session(pool: &SqlitePool, args: &[&str]) -> Result<()> {
+        use clap::Parser;
+
+        let argv = ["lazarus", "session"].into_iter().chain(args.iter().copied());
+        let Some(Commands::Session(cli)) = Cli::try_parse_from(argv).unwrap().cmd else {
+            unreachable!("not a session command")
+        };
+        run(cli.cmd, pool, cli.session, &Config::default()).await
+    }
+
+    #[tokio::test]
+    async fn logging_and_ending_a_session() {
+        let pool = memory_db().await;
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        let bench = testutil::exercise(&pool, "Bench Press", "chest").await;
+        testutil::program_block(&pool, "Strength", "Day A", &[&squat, &bench], "5").await;
+
+        session(&pool, &["start", "Strength", "Day A"]).await.unwrap();
+        let id = active_session(&pool, None).await.unwrap();
+        let exercises: Vec<(String,)> =
+            sqlx::query_as("SELECT exercise_id FROM training_session_exercises WHERE training_session_id = ? ORDER BY order_index")
+                .bind(&id)
+                .fetch_all(&pool)
+                .await
+                .unwrap();
+        assert_eq!(exercises, [(squat.clone(),), (bench.clone(),)]);
+
+        session(&pool, &["edit", "1", "100", "5"]).await.unwrap();
+        session(&pool, &["edit", "1", "110", "3"]).await.unwrap();
+        session(&pool, &["edit", "2", "-w", "80", "-r", "6", "--failed"]).await.unwrap();
+        session(&pool, &["edit", "2", "--skip"]).await.unwrap();
+        let sets: Vec<(String, f64, i64, String)> = sqlx::query_as(
+            r#"
+            SELECT tse.exercise_id, es.weight, es.reps, es.status
+            FROM exercise_sets es
+            JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+            WHERE tse.training_session_id = ?
+            ORDER BY tse.order_index, es.set_index
+            "#,
+        )
+        .bind(&id)
+        .fetch_all(&pool)
+        .await
+        .unwrap();
+        let statuses: Vec<_> = sets.iter().map(|(ex, w, r, s)| (ex == &squat, *w, *r, s.as_str())).collect();
+        assert_eq!(
+            statuses,
+            [(true, 100.0, 5, "completed"), (true, 110.0, 3, "completed"), (false, 80.0, 6, "failed"), (false, 0.0, 0, "skipped")]
+        );
+
+        session(&pool, &["end"]).await.unwrap();
+        let end_time: Option<String> = sqlx::query_scalar("SELECT end_time FROM training_sessions WHERE id = ?")
+            .bind(&id)
+            .fetch_one(&pool)
+            .await
+            .unwrap();
+        assert!(end_time.is_some());
+        assert_eq!(exit_code(session(&pool, &["show"]).await.unwrap_err()), 4);
+
+        // The best e1RM of the session is the squat's PR.
+        let prs: Vec<(String, f64, i64)> = sqlx::query_as("SELECT exercise_id, weight, reps FROM personal_records")
+            .fetch_all(&pool)
+            .await
+            .unwrap();
+        assert!(prs.contains(&(squat, 110.0, 3)), "{:?}", prs);
+    }
+
+    #[tokio::test]
+    async fn pausing_twice_within_a_second() {
+        let pool = memory_db().await;
+        let squat = testutil::exercise(&pool, "Squat", "quads").await;
+        testutil::program_block(&pool, "Strength", "Day A", &[&squat], "5").await;
+        session(&pool, &["start", "Strength", "Day A"]).await.unwrap();
+        let id = active_session(&pool, None).await.unwrap();
+
+        // A pause resumed this very second, as a quick pause/resume leaves it.
+        sqlx::query("INSERT INTO session_pauses (training_session_id, paused_at, resumed_at) VALUES (?, datetime('now'), datetime('now'))")
+            .bind(&id)
+            .execute(&pool)
+            .await
+            .unwrap();
+        session(&pool, &["pause"]).await.unwrap();
+        assert!(is_paused(&pool, &id).await.unwrap());
+        session(&pool, &["resume"]).await.unwrap();
+
+        let pauses: i64 = sqlx::query_scalar("SELECT COUNT(*) FROM session_pauses WHERE training_session_id = ? AND resumed_at IS NOT NULL")
+            .bind(&id)
+            .fetch_one(&pool)
+            .await
+            .unwrap();
+        assert_eq!(pauses, 2);
+    }
+}
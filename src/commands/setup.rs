@@ -0,0 +1,478 @@
+use std::{
+    io::{IsTerminal, Write},
+    path::PathBuf,
+};
+
+use anyhow::{Context, Result};
+use colored::Colorize;
+
+use crate::{
+    cli::ProgramCmd,
+    commands::{library, program},
+    db::{self, Backend},
+    errors::AppError,
+    i18n::{tf, tr},
+    types::{Config, OutputFmt},
+    ui::{self, Themed},
+};
+
+/// Offered by `setup`, in the `export-exercises` format. Covers every
+/// exercise of the templates below.
+const STARTER_LIBRARY: &str = r#"
+[[exercises]]
+name = "Back Squat"
+muscle = "quads"
+description = "Full depth barbell back squat"
+equipment = "barbell"
+aliases = ["Squat"]
+
+[[exercises]]
+name = "Bench Press"
+muscle = "chest"
+description = "Flat bench barbell press"
+equipment = "barbell"
+aliases = ["Bench"]
+
+[[exercises]]
+name = "Deadlift"
+muscle = "back"
+description = "Conventional barbell deadlift"
+equipment = "barbell"
+
+[[exercises]]
+name = "Overhead Press"
+muscle = "shoulders"
+description = "Standing barbell press"
+equipment = "barbell"
+aliases = ["OHP"]
+
+[[exercises]]
+name = "Barbell Row"
+muscle = "back"
+equipment = "barbell"
+
+[[exercises]]
+name = "Romanian Deadlift"
+muscle = "hamstrings"
+equipment = "barbell"
+aliases = ["RDL"]
+
+[[exercises]]
+name = "Incline Dumbbell Press"
+muscle = "chest"
+equipment = "dumbbell"
+
+[[exercises]]
+name = "Lat Pulldown"
+muscle = "back"
+equipment = "cable"
+
+[[exercises]]
+name = "BW Pull-Up"
+muscle = "back"
+equipment = "bodyweight"
+
+[[exercises]]
+name = "Seated Cable Row"
+muscle = "back"
+equipment = "cable"
+
+[[exercises]]
+name = "Leg Press"
+muscle = "quads"
+equipment = "machine"
+
+[[exercises]]
+name = "Leg Curl"
+muscle = "hamstrings"
+equipment = "machine"
+
+[[exercises]]
+name = "Hip Thrust"
+muscle = "glutes"
+equipment = "barbell"
+
+[[exercises]]
+name = "Standing Calf Raise"
+muscle = "calves"
+equipment = "machine"
+
+[[exercises]]
+name = "Dumbbell Lateral Raise"
+muscle = "shoulders"
+equipment = "dumbbell"
+
+[[exercises]]
+name = "Dumbbell Curl"
+muscle = "biceps"
+equipment = "dumbbell"
+
+[[exercises]]
+name = "Cable Tricep Pushdown"
+muscle = "triceps"
+equipment = "cable"
+
+[[exercises]]
+name = "Hanging Leg Raise"
+muscle = "abs"
+equipment = "bodyweight"
+"#;
+
+const FULL_BODY: &str = r#"
+name = "Full Body"
+description = "Three full-body days a week, A/B/C; add weight when every set reaches the top of the range."
+
+[[blocks]]
+name = "Day A"
+duration = 60
+
+[[blocks.exercises]]
+name = "Back Squat"
+sets = 3
+reps = ["5-8"]
+target_rpe = [8.0]
+rest = 180
+
+[[blocks.exercises]]
+name = "Bench Press"
+sets = 3
+reps = ["5-8"]
+target_rpe = [8.0]
+rest = 180
+
+[[blocks.exercises]]
+name = "Barbell Row"
+sets = 3
+reps = ["8-10"]
+
+[[blocks.exercises]]
+name = "Dumbbell Lateral Raise"
+sets = 2
+reps = ["12-15"]
+
+[[blocks]]
+name = "Day B"
+duration = 60
+
+[[blocks.exercises]]
+name = "Deadlift"
+sets = 2
+reps = ["4-6"]
+target_rpe = [8.0]
+rest = 180
+
+[[blocks.exercises]]
+name = "Overhead Press"
+sets = 3
+reps = ["6-8"]
+target_rpe = [8.0]
+
+[[blocks.exercises]]
+name = "Lat Pulldown"
+sets = 3
+reps = ["8-12"]
+
+[[blocks.exercises]]
+name = "Dumbbell Curl"
+sets = 2
+reps = ["10-12"]
+
+[[blocks]]
+name = "Day C"
+duration = 60
+
+[[blocks.exercises]]
+name = "Leg Press"
+sets = 3
+reps = ["8-12"]
+
+[[blocks.exercises]]
+name = "Incline Dumbbell Press"
+sets = 3
+reps = ["8-12"]
+
+[[blocks.exercises]]
+name = "Romanian Deadlift"
+sets = 3
+reps = ["8-10"]
+
+[[blocks.exercises]]
+name = "Cable Tricep Pushdown"
+sets = 2
+reps = ["10-15"]
+"#;
+
+const UPPER_LOWER: &str = r#"
+name = "Upper/Lower"
+description = "Four days a week: a heavier and a lighter upper and lower day."
+
+[substitutions]
+"vertical pull" = ["Lat Pulldown", "BW Pull-Up"]
+
+[[blocks]]
+name = "Upper 1"
+duration = 75
+
+[[blocks.exercises]]
+name = "Bench Press"
+sets = 3
+reps = ["4-6"]
+target_rpe = [8.0]
+rest = 180
+
+[[blocks.exercises]]
+name = "Barbell Row"
+sets = 3
+reps = ["6-8"]
+
+[[blocks.exercises]]
+name = "Overhead Press"
+sets = 2
+reps = ["6-8"]
+
+[[blocks.exercises]]
+name = "Lat Pulldown"
+sets = 2
+reps = ["8-12"]
+
+[[blocks.exercises]]
+name = "Dumbbell Curl"
+sets = 2
+reps = ["10-12"]
+group = "A"
+
+[[blocks.exercises]]
+name = "Cable Tricep Pushdown"
+sets = 2
+reps = ["10-12"]
+group = "A"
+
+[[blocks]]
+name = "Lower 1"
+duration = 75
+
+[[blocks.exercises]]
+name = "Back Squat"
+sets = 3
+reps = ["4-6"]
+target_rpe = [8.0]
+rest = 180
+
+[[blocks.exercises]]
+name = "Romanian Deadlift"
+sets = 3
+reps = ["6-8"]
+
+[[blocks.exercises]]
+name = "Leg Curl"
+sets = 2
+reps = ["10-12"]
+
+[[blocks.exercises]]
+name = "Standing Calf Raise"
+sets = 3
+reps = ["10-15"]
+
+[[blocks]]
+name = "Upper 2"
+duration = 75
+
+[[blocks.exercises]]
+name = "Incline Dumbbell Press"
+sets = 3
+reps = ["8-12"]
+
+[[blocks.exercises]]
+name = "Seated Cable Row"
+sets = 3
+reps = ["8-12"]
+
+[[blocks.exercises]]
+name = "BW Pull-Up"
+sets = 2
+reps = ["AMRAP"]
+
+[[blocks.exercises]]
+name = "Dumbbell Lateral Raise"
+sets = 3
+reps = ["12-15"]
+
+[[blocks]]
+name = "Lower 2"
+duration = 75
+
+[[blocks.exercises]]
+name = "Deadlift"
+sets = 2
+reps = ["3-5"]
+target_rpe = [8.0]
+rest = 180
+
+[[blocks.exercises]]
+name = "Leg Press"
+sets = 3
+reps = ["10-12"]
+
+[[blocks.exercises]]
+name = "Hip Thrust"
+sets = 3
+reps = ["8-12"]
+
+[[blocks.exercises]]
+name = "Hanging Leg Raise"
+sets = 3
+reps = ["10-15"]
+"#;
+
+/// First programs to start from, as (file name, summary, program).
+const TEMPLATES: &[(&str, &str, &str)] = &[
+    ("full-body", "Full body, 3 days a week", FULL_BODY),
+    ("upper-lower", "Upper/lower, 4 days a week", UPPER_LOWER),
+];
+
+/// Ask `question`, `default` kept on Enter.
+fn ask(question: &str, default: &str) -> Result<String> {
+    let shown = if default.is_empty() { String::new() } else { format!(" [{}]", default) };
+    print!("{}{}: ", question.bold(), shown.dimmed());
+    std::io::stdout().flush()?;
+    let mut answer = String::new();
+    if std::io::stdin().read_line(&mut answer)? == 0 {
+        return Err(AppError::Invalid(tr("setup cancelled").into()).into());
+    }
+    let answer = answer.trim();
+    Ok(if answer.is_empty() { default.to_string() } else { answer.to_string() })
+}
+
+fn ask_yes(question: &str, default: bool) -> Result<bool> {
+    loop {
+        match ask(question, if default { "y" } else { "n" })?.to_lowercase().as_str() {
+            "y" | "yes" | "s" | "sim" => return Ok(true),
+            "n" | "no" | "não" | "nao" => return Ok(false),
+            _ => println!("  {}", tr("answer y or n").bad()),
+        }
+    }
+}
+
+/// Walk through the config, one question per key, into `cfg`; returns the
+/// database to open.
+fn configure(cfg: &mut Config) -> Result<Backend> {
+    let backend = loop {
+        let database = ask(&tr("Database file (or :memory:)"), &cfg.database())?;
+        match Backend::parse(&database) {
+            Ok(backend) => {
+                cfg.map.insert("database".into(), database);
+                break backend;
+            }
+            Err(e) => println!("  {}", e.to_string().bad()),
+        }
+    };
+
+    let units = if cfg.map.get("rounding").is_some_and(|r| r == "lb") { "lb" } else { "kg" };
+    loop {
+        match ask(&tr("Units (kg or lb)"), units)?.to_lowercase().as_str() {
+            "kg" => {
+                if cfg.map.get("rounding").is_some_and(|r| r == "lb") {
+                    cfg.map.remove("rounding");
+                }
+                break;
+            }
+            "lb" | "lbs" => {
+                cfg.map.insert("rounding".into(), "lb".into());
+                break;
+            }
+            other => println!("  {}", tf("unknown unit `{}`", &[&other]).bad()),
+        }
+    }
+
+    let locale = cfg.map.get("locale").cloned().unwrap_or_else(|| "en".into());
+    loop {
+        match ask(&tr("Language (en or pt-BR)"), &locale)?.to_lowercase().replace('_', "-").as_str() {
+            "en" => {
+                cfg.map.insert("locale".into(), "en".into());
+                break;
+            }
+            "pt-br" | "pt" => {
+                cfg.map.insert("locale".into(), "pt-BR".into());
+                break;
+            }
+            other => println!("  {}", tf("unknown language `{}`", &[&other]).bad()),
+        }
+    }
+
+    let bodyweight = cfg.bodyweight().map(|b| b.to_string()).unwrap_or_default();
+    loop {
+        let answer = ask(&tr("Bodyweight in kg (Enter to skip)"), &bodyweight)?;
+        if answer.is_empty() {
+            break;
+        }
+        match answer.replace(',', ".").parse::<f32>() {
+            Ok(kg) if kg > 0.0 => {
+                cfg.map.insert("bodyweight".into(), kg.to_string());
+                break;
+            }
+            _ => println!("  {}", tf("invalid bodyweight `{}`", &[&answer]).bad()),
+        }
+    }
+
+    Ok(backend)
+}
+
+/// `setup`: write the config, create the database, and optionally add the
+/// starter exercise library and a first program from a template.
+pub async fn handle(mut cfg: Config, config_path: PathBuf, fmt: OutputFmt) -> Result<()> {
+    if !std::io::stdin().is_terminal() {
+        return Err(AppError::Invalid(tr("`setup` asks questions; from a script use `config set` instead").into()).into());
+    }
+
+    println!("{} {}", tr("Setup:").heading().bold(), tr("Enter keeps the value in brackets.").dimmed());
+    let backend = configure(&mut cfg)?;
+    cfg.save(&config_path)?;
+    ui::ok(tf("config written to {}", &[&config_path.display()]));
+    ui::info(tr("times follow the system timezone (TZ) and weeks start on Monday"));
+
+    // Opening runs the migrations, so this creates and initializes the database.
+    let pool = db::open(&backend).await?;
+    ui::ok(tf("database ready: {}", &[&cfg.database()]));
+
+    let starter = ask_yes(&tr("Import the starter exercise library?"), true)?;
+
+    println!("{}", tr("Program templates:").heading().bold());
+    for (i, (name, summary, _)) in TEMPLATES.iter().enumerate() {
+        println!("  {} {:<12} {}", format!("{}.", i + 1).accent(), name, tr(summary).dimmed());
+    }
+    let template = loop {
+        let answer = ask(&tr("First program (number, Enter for none)"), "")?;
+        if answer.is_empty() {
+            break None;
+        }
+        match answer.parse::<usize>().ok().and_then(|i| TEMPLATES.get(i.wrapping_sub(1))) {
+            Some(t) => break Some(t),
+            None => println!("  {}", tf("no template `{}`", &[&answer]).bad()),
+        }
+    };
+
+    if starter || template.is_some() {
+        if !starter {
+            ui::info(tr("the template's exercises come from the starter library, adding the missing ones"));
+        }
+        library::import_str(&pool, STARTER_LIBRARY, true).await?;
+    }
+
+    if let Some((name, _, toml)) = template {
+        // Kept next to the config so it can be edited and imported again.
+        let dir = config_path.parent().context("no config dir")?.join("programs");
+        std::fs::create_dir_all(&dir).with_context(|| format!("could not create `{}`", dir.display()))?;
+        let path = dir.join(format!("{}.toml", name));
+        if !path.exists() {
+            std::fs::write(&path, toml.trim_start()).with_context(|| format!("could not write `{}`", path.display()))?;
+        }
+        let path = path.display().to_string();
+        program::handle(ProgramCmd::Import { files: vec![path.clone()], weeks: None, autofill_1rm: cfg.autofill_1rm() }, &pool, fmt).await?;
+        ui::info(tf("edit {} and run `p import` on it to change the program", &[&path]));
+    }
+
+    pool.close().await;
+    ui::ok(tr("all set, run `lazarus` for today's dashboard"));
+
+    Ok(())
+}
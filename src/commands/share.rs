@@ -0,0 +1,190 @@
+use std::{
+    io::Write,
+    process::{Command, Stdio},
+};
+
+use anyhow::Result;
+use chrono::NaiveDateTime;
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::compare::resolve_session,
+    errors::AppError,
+    formula,
+    i18n::{kg, kg_places, long_date, tf, tr},
+    types::{SET_LOAD, bodyweight_label},
+    ui::{self, Themed},
+};
+
+/// Best set of an exercise in the session plus how many sets it had.
+struct Line {
+    exercise_id: String,
+    name: String,
+    sets: usize,
+    weight: f64,
+    reps: i64,
+    bodyweight: bool,
+}
+
+impl Line {
+    /// Heaviest by e1RM; for bodyweight sets, the most reps.
+    fn score(weight: f64, reps: i64, bodyweight: bool) -> f64 {
+        if bodyweight { reps as f64 } else { formula::e1rm(weight, reps as f64) }
+    }
+
+    fn top_set(&self) -> String {
+        if self.bodyweight {
+            bodyweight_label(self.weight as f32, self.reps as i32)
+        } else {
+            format!("{}kg × {}", kg(self.weight), self.reps)
+        }
+    }
+}
+
+/// Plain-text summary, short enough for a chat message.
+async fn summary(pool: &SqlitePool, session_id: &str) -> Result<String> {
+    let (block, start, end): (String, String, Option<String>) = sqlx::query_as(
+        r#"
+        SELECT pb.name, ts.start_time, ts.end_time
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        WHERE ts.id = ?
+        "#,
+    )
+    .bind(session_id)
+    .fetch_one(pool)
+    .await?;
+
+    let sets: Vec<(String, String, f64, i64, bool, f64)> = sqlx::query_as(&format!(
+        r#"
+        SELECT e.id, e.name, CAST(es.weight AS REAL), es.reps, es.bodyweight, CAST({load} AS REAL)
+        FROM training_session_exercises tse
+        JOIN exercises e ON e.id = tse.exercise_id
+        JOIN exercise_sets es ON es.session_exercise_id = tse.id
+        WHERE tse.training_session_id = ?
+        AND es.status != 'skipped'
+        ORDER BY tse.order_index, es.set_index
+        "#,
+        load = SET_LOAD
+    ))
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?;
+    if sets.is_empty() {
+        return Err(AppError::NotFound(tr("no sets logged in this session").into()).into());
+    }
+
+    let mut lines: Vec<Line> = Vec::new();
+    let mut tonnage = 0.0;
+    for (exercise_id, name, weight, reps, bodyweight, load) in sets {
+        tonnage += load * reps as f64;
+        match lines.iter_mut().find(|l| l.exercise_id == exercise_id) {
+            Some(line) => {
+                line.sets += 1;
+                if Line::score(weight, reps, bodyweight) > Line::score(line.weight, line.reps, line.bodyweight) {
+                    (line.weight, line.reps, line.bodyweight) = (weight, reps, bodyweight);
+                }
+            }
+            None => lines.push(Line { exercise_id, name, sets: 1, weight, reps, bodyweight }),
+        }
+    }
+
+    // Same rule as the journal: the day's best beats every earlier day.
+    let prs: Vec<(String, f64, i64, f64)> = sqlx::query_as(
+        r#"
+        SELECT pr.exercise_id, pr.weight, pr.reps, pr.estimated_1rm
+        FROM personal_records pr
+        WHERE pr.date = date(?)
+        AND pr.exercise_id IN (SELECT exercise_id FROM training_session_exercises WHERE training_session_id = ?)
+        AND pr.estimated_1rm > COALESCE(
+            (SELECT MAX(prev.estimated_1rm) FROM personal_records prev
+             WHERE prev.exercise_id = pr.exercise_id AND prev.date < pr.date), 0)
+        "#,
+    )
+    .bind(&start)
+    .bind(session_id)
+    .fetch_all(pool)
+    .await?;
+
+    let parse = |t: &str| NaiveDateTime::parse_from_str(t, "%Y-%m-%d %H:%M:%S").ok();
+    let date = parse(&start).map(|t| long_date(t.date())).unwrap_or_else(|| start[..10].to_string());
+    let minutes = match (parse(&start), end.as_deref().and_then(parse)) {
+        (Some(a), Some(b)) => format!(" ({} min)", (b - a).num_minutes()),
+        _ => String::new(),
+    };
+
+    let mut out = format!("🏋️ {} — {}{}\n", block, date, minutes);
+    for line in &lines {
+        let pr = if prs.iter().any(|(id, ..)| *id == line.exercise_id) { " 🏆" } else { "" };
+        out += &format!(
+            "• {}: {} {}{}\n",
+            line.name,
+            line.top_set(),
+            tf("({} sets)", &[&line.sets]),
+            pr
+        );
+    }
+    let total_sets: usize = lines.iter().map(|l| l.sets).sum();
+    out += &format!("📊 {}\n", tf("{} sets · {} kg moved", &[&total_sets, &format!("{:.0}", tonnage)]));
+    for (exercise_id, weight, reps, e1rm) in &prs {
+        if let Some(line) = lines.iter().find(|l| l.exercise_id == *exercise_id) {
+            out += &format!("🏆 PR: {} {}kg × {} (e1RM {}kg)\n", line.name, kg(*weight), reps, kg_places(*e1rm, 1));
+        }
+    }
+
+    Ok(out)
+}
+
+/// Pipe `text` into the first clipboard tool that works.
+fn copy_to_clipboard(text: &str) -> bool {
+    let tools: &[(&str, &[&str])] = if cfg!(target_os = "macos") {
+        &[("pbcopy", &[])]
+    } else if cfg!(windows) {
+        &[("clip", &[])]
+    } else {
+        &[
+            ("wl-copy", &[]),
+            ("xclip", &["-selection", "clipboard"]),
+            ("xsel", &["--clipboard", "--input"]),
+            ("termux-clipboard-set", &[]),
+        ]
+    };
+
+    tools.iter().any(|(cmd, args)| {
+        let Ok(mut child) = Command::new(cmd).args(*args).stdin(Stdio::piped()).stdout(Stdio::null()).spawn() else {
+            return false;
+        };
+        let written = child.stdin.take().is_some_and(|mut stdin| stdin.write_all(text.as_bytes()).is_ok());
+        child.wait().is_ok_and(|s| s.success()) && written
+    })
+}
+
+pub async fn handle(pool: &SqlitePool, session: Option<String>, clipboard: bool) -> Result<()> {
+    let session_id = match session {
+        Some(id) => resolve_session(pool, &id).await?,
+        None => sqlx::query_scalar(
+            "SELECT id FROM training_sessions WHERE end_time IS NOT NULL ORDER BY start_time DESC LIMIT 1",
+        )
+        .fetch_optional(pool)
+        .await?
+        .ok_or_else(|| AppError::NotFound(tr("no finished sessions").into()))?,
+    };
+
+    let text = summary(pool, &session_id).await?;
+    print!("{}", text);
+
+    if clipboard {
+        if copy_to_clipboard(&text) {
+            ui::ok(tr("copied to the clipboard"));
+        } else {
+            println!(
+                "{} {}",
+                tr("warning:").accent().bold(),
+                tr("no clipboard tool found (wl-copy, xclip, xsel, pbcopy), copy the text above")
+            );
+        }
+    }
+
+    Ok(())
+}
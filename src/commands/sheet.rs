@@ -0,0 +1,307 @@
+use std::{fs, path::Path};
+
+use anyhow::Result;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::program::{resolve_block, resolve_program, superset_labels},
+    errors::AppError,
+    i18n::{tf, tr},
+    ui,
+};
+
+struct SheetExercise {
+    label: Option<String>,
+    name: String,
+    sets: i32,
+    reps: Vec<String>,
+    rpe: Vec<String>,
+    percent: Vec<String>,
+    tempo: Option<String>,
+    notes: Option<String>,
+}
+
+impl SheetExercise {
+    /// Prescription of set `i`, e.g. "5 @ RPE 8" or "3 @ 85%". Per-set lists
+    /// shorter than the set count repeat their last entry.
+    fn target(&self, i: usize) -> String {
+        let pick = |v: &[String]| v.get(i).or(v.last()).cloned().filter(|s| !s.is_empty());
+        let mut out = pick(&self.reps).unwrap_or_default();
+        if let Some(rpe) = pick(&self.rpe) {
+            out += &format!(" @ RPE {}", rpe);
+        }
+        if let Some(pct) = pick(&self.percent) {
+            out += &format!(" @ {}%", pct);
+        }
+        out.trim().to_string()
+    }
+
+    fn title(&self) -> String {
+        match &self.label {
+            Some(label) => format!("{} {}", label, self.name),
+            None => self.name.clone(),
+        }
+    }
+
+    /// Tempo and program notes, when there are any.
+    fn details(&self) -> Option<String> {
+        let parts: Vec<String> = self
+            .tempo
+            .iter()
+            .map(|t| tf("tempo {}", &[t]))
+            .chain(self.notes.iter().cloned())
+            .collect();
+        (!parts.is_empty()).then(|| parts.join(" · "))
+    }
+}
+
+fn csv(s: Option<String>) -> Vec<String> {
+    s.map(|s| s.split(',').map(|p| p.trim().to_string()).collect()).unwrap_or_default()
+}
+
+async fn load(pool: &SqlitePool, block_id: &str) -> Result<Vec<SheetExercise>> {
+    let rows: Vec<(String, i32, Option<String>, Option<String>, Option<String>, Option<String>, Option<String>, Option<String>)> =
+        sqlx::query_as(
+            r#"
+            SELECT e.name, pe.sets, pe.reps, pe.target_rpe, pe.target_rm_percent, pe.tempo, pe.notes, pe.superset
+            FROM program_exercises pe
+            JOIN exercises e ON e.id = pe.exercise_id
+            WHERE pe.program_block_id = ?
+            ORDER BY pe.order_index
+            "#,
+        )
+        .bind(block_id)
+        .fetch_all(pool)
+        .await?;
+
+    let groups: Vec<Option<String>> = rows.iter().map(|r| r.7.clone()).collect();
+    Ok(rows
+        .into_iter()
+        .zip(superset_labels(&groups))
+        .map(|((name, sets, reps, rpe, percent, tempo, notes, _), label)| SheetExercise {
+            label,
+            name,
+            sets,
+            reps: csv(reps),
+            rpe: csv(rpe),
+            percent: csv(percent),
+            tempo,
+            notes,
+        })
+        .collect())
+}
+
+fn columns() -> [&'static str; 6] {
+    [tr("Set"), tr("Target"), tr("Weight"), tr("Reps"), "RPE", tr("Notes")]
+}
+
+fn render_markdown(title: &str, exercises: &[SheetExercise]) -> String {
+    let mut out = format!("# {}\n\n", title);
+    out += &format!("{}: ____________ · {}: ________\n", tr("Date"), tr("Bodyweight"));
+    for ex in exercises {
+        out += &format!("\n### {}\n\n", ex.title());
+        if let Some(details) = ex.details() {
+            out += &format!("*{}*\n\n", details);
+        }
+        out += &format!("| {} |\n", columns().join(" | "));
+        out += "|---|---|---|---|---|---|\n";
+        for i in 0..ex.sets.max(1) as usize {
+            out += &format!("| {} | {} |  |  |  |  |\n", i + 1, ex.target(i));
+        }
+    }
+    out
+}
+
+/// Just enough of PDF to lay out text and table rules on A4 pages with the
+/// built-in Helvetica, so no font files or extra crates are needed.
+struct Pdf {
+    pages: Vec<String>,
+    y: f64,
+}
+
+const PAGE_W: f64 = 595.0;
+const PAGE_H: f64 = 842.0;
+const MARGIN: f64 = 40.0;
+const ROW_H: f64 = 20.0;
+/// Left edge of each table column, then the right edge of the table.
+const COLS: [f64; 7] = [MARGIN, 75.0, 215.0, 300.0, 355.0, 400.0, PAGE_W - MARGIN];
+
+/// Text as a PDF string in WinAnsiEncoding (what the standard fonts use).
+fn pdf_string(s: &str) -> Vec<u8> {
+    let mut out = vec![b'('];
+    for c in s.chars() {
+        let byte = match c {
+            '(' | ')' | '\\' => {
+                out.push(b'\\');
+                c as u8
+            }
+            ' '..='~' => c as u8,
+            '\u{a0}'..='\u{ff}' => c as u32 as u8,
+            '•' => 0x95,
+            '–' => 0x96,
+            '—' => 0x97,
+            '’' => 0x92,
+            _ => b'?',
+        };
+        out.push(byte);
+    }
+    out.push(b')');
+    out
+}
+
+impl Pdf {
+    fn new() -> Self {
+        Pdf { pages: vec![String::new()], y: PAGE_H - MARGIN }
+    }
+
+    fn page(&mut self) -> &mut String {
+        self.pages.last_mut().unwrap()
+    }
+
+    /// Start a new page unless `height` still fits on this one.
+    fn reserve(&mut self, height: f64) {
+        if self.y - height < MARGIN {
+            self.pages.push(String::new());
+            self.y = PAGE_H - MARGIN;
+        }
+    }
+
+    fn text(&mut self, x: f64, y: f64, size: f64, bold: bool, s: &str) {
+        let font = if bold { "F2" } else { "F1" };
+        // Kept as Latin-1 in the string; converted to bytes when written out.
+        let encoded: String = pdf_string(s).into_iter().map(|b| b as char).collect();
+        self.page().push_str(&format!("BT /{} {} Tf {:.1} {:.1} Td {} Tj ET\n", font, size, x, y, encoded));
+    }
+
+    fn rule(&mut self, x1: f64, y1: f64, x2: f64, y2: f64) {
+        self.page().push_str(&format!("{:.1} {:.1} m {:.1} {:.1} l S\n", x1, y1, x2, y2));
+    }
+
+    fn line(&mut self, size: f64, bold: bool, s: &str) {
+        self.reserve(size * 1.6);
+        self.y -= size * 1.4;
+        self.text(MARGIN, self.y, size, bold, s);
+    }
+
+    fn table(&mut self, header: &[&str], rows: &[Vec<String>]) {
+        let row = |pdf: &mut Pdf, cells: &[&str], bold: bool| {
+            pdf.reserve(ROW_H);
+            let top = pdf.y;
+            pdf.y -= ROW_H;
+            for (i, cell) in cells.iter().enumerate() {
+                pdf.text(COLS[i] + 4.0, pdf.y + 6.0, 9.0, bold, cell);
+            }
+            pdf.rule(COLS[0], top, COLS[6], top);
+            pdf.rule(COLS[0], pdf.y, COLS[6], pdf.y);
+            for x in COLS {
+                pdf.rule(x, top, x, pdf.y);
+            }
+        };
+        row(self, header, true);
+        for cells in rows {
+            let cells: Vec<&str> = cells.iter().map(String::as_str).collect();
+            row(self, &cells, false);
+        }
+    }
+
+    fn finish(self) -> Vec<u8> {
+        let mut objects: Vec<Vec<u8>> = vec![
+            b"<< /Type /Catalog /Pages 2 0 R >>".to_vec(),
+            Vec::new(), // page tree, once the page ids are known
+            b"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>".to_vec(),
+            b"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>".to_vec(),
+        ];
+        let mut kids = Vec::new();
+        for content in &self.pages {
+            let stream: Vec<u8> = format!("0.5 w\n{}", content).chars().map(|c| c as u32 as u8).collect();
+            let page_id = objects.len() + 1;
+            let content_id = page_id + 1;
+            kids.push(format!("{} 0 R", page_id));
+            objects.push(
+                format!(
+                    "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 {} {}] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents {} 0 R >>",
+                    PAGE_W, PAGE_H, content_id
+                )
+                .into_bytes(),
+            );
+            let mut obj = format!("<< /Length {} >>\nstream\n", stream.len()).into_bytes();
+            obj.extend(stream);
+            obj.extend(b"\nendstream");
+            objects.push(obj);
+        }
+        objects[1] = format!("<< /Type /Pages /Kids [{}] /Count {} >>", kids.join(" "), self.pages.len()).into_bytes();
+
+        let mut out = b"%PDF-1.4\n".to_vec();
+        let mut offsets = Vec::new();
+        for (i, obj) in objects.iter().enumerate() {
+            offsets.push(out.len());
+            out.extend(format!("{} 0 obj\n", i + 1).into_bytes());
+            out.extend(obj);
+            out.extend(b"\nendobj\n");
+        }
+        let xref = out.len();
+        out.extend(format!("xref\n0 {}\n0000000000 65535 f \n", objects.len() + 1).into_bytes());
+        for offset in offsets {
+            out.extend(format!("{:010} 00000 n \n", offset).into_bytes());
+        }
+        out.extend(format!("trailer\n<< /Size {} /Root 1 0 R >>\nstartxref\n{}\n%%EOF\n", objects.len() + 1, xref).into_bytes());
+        out
+    }
+}
+
+fn render_pdf(title: &str, exercises: &[SheetExercise]) -> Vec<u8> {
+    let mut pdf = Pdf::new();
+    pdf.line(16.0, true, title);
+    pdf.line(10.0, false, &format!("{}: ____________     {}: ________", tr("Date"), tr("Bodyweight")));
+    for ex in exercises {
+        pdf.reserve(ROW_H * 3.0 + 30.0);
+        pdf.y -= 8.0;
+        pdf.line(11.0, true, &ex.title());
+        if let Some(details) = ex.details() {
+            pdf.line(8.0, false, &details);
+        }
+        pdf.y -= 4.0;
+        let rows: Vec<Vec<String>> = (0..ex.sets.max(1) as usize)
+            .map(|i| vec![(i + 1).to_string(), ex.target(i)])
+            .collect();
+        pdf.table(&columns(), &rows);
+    }
+    pdf.finish()
+}
+
+pub async fn handle(pool: &SqlitePool, program: String, block: String, out: Option<String>) -> Result<()> {
+    let program_id = resolve_program(pool, &program).await?;
+    let block_id = resolve_block(pool, &program_id, &block).await?;
+    let (program_name, block_name): (String, String) = sqlx::query_as(
+        "SELECT p.name, pb.name FROM program_blocks pb JOIN programs p ON p.id = pb.program_id WHERE pb.id = ?",
+    )
+    .bind(&block_id)
+    .fetch_one(pool)
+    .await?;
+
+    let exercises = load(pool, &block_id).await?;
+    if exercises.is_empty() {
+        return Err(AppError::NotFound(tf("block `{}` has no exercises", &[&block_name])).into());
+    }
+    let title = format!("{} — {}", program_name, block_name);
+
+    let Some(path) = out else {
+        print!("{}", render_markdown(&title, &exercises));
+        return Ok(());
+    };
+    let ext = Path::new(&path)
+        .extension()
+        .map(|e| e.to_string_lossy().to_lowercase())
+        .unwrap_or_default();
+    let doc = match ext.as_str() {
+        "pdf" => render_pdf(&title, &exercises),
+        "md" | "markdown" => render_markdown(&title, &exercises).into_bytes(),
+        _ => {
+            return Err(AppError::Invalid(tf("can't tell the format of `{}` (use .pdf or .md)", &[&path])).into());
+        }
+    };
+    fs::write(&path, doc)?;
+    ui::ok(tf("sheet for {} written to {}", &[&title, &path]));
+
+    Ok(())
+}
@@ -0,0 +1,200 @@
+use anyhow::Result;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::trend::{Trend, e1rm_trend},
+    formula,
+    i18n::{display_db_date, kg_places, tf, tr},
+    types::{OutputFmt, emit},
+    ui::{self, Themed},
+};
+
+/// A lift trained in the last weeks whose best e1RM there didn't beat the one
+/// from before.
+#[derive(Serialize)]
+pub struct Stall {
+    #[serde(skip)]
+    exercise_id: String,
+    pub exercise: String,
+    /// Best e1RM before the window and when it was set.
+    pub best_kg: f64,
+    pub best_date: String,
+    /// Best e1RM inside the window.
+    pub recent_kg: f64,
+    pub sessions: i64,
+    pub weeks_since_best: i64,
+}
+
+/// Exercises with at least two finished sessions in the last `weeks` weeks and
+/// no e1RM above their earlier best, longest stall first.
+pub async fn stalled(pool: &SqlitePool, weeks: u32) -> Result<Vec<Stall>> {
+    let rows: Vec<(String, String, f64, String, f64, i64, i64)> = sqlx::query_as(
+        &format!(r#"
+        WITH sets AS (
+            SELECT tse.exercise_id, ts.id AS session_id, ts.start_time,
+                   {e1rm} AS e1rm,
+                   ts.start_time >= datetime('now', '-' || ?1 || ' days') AS recent
+            FROM exercise_sets es
+            JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+            JOIN training_sessions ts ON ts.id = tse.training_session_id
+            WHERE ts.end_time IS NOT NULL
+            AND es.weight > 0 AND es.bodyweight = 0 AND COALESCE(es.ignore_for_one_rm, 0) = 0
+        ),
+        summary AS (
+            SELECT exercise_id,
+                   MAX(CASE WHEN NOT recent THEN e1rm END) AS best,
+                   MAX(CASE WHEN recent THEN e1rm END) AS recent_best,
+                   COUNT(DISTINCT CASE WHEN recent THEN session_id END) AS sessions
+            FROM sets
+            GROUP BY exercise_id
+        )
+        SELECT s.exercise_id, e.name, s.best,
+               (SELECT MAX(start_time) FROM sets b WHERE b.exercise_id = s.exercise_id AND NOT b.recent AND b.e1rm = s.best),
+               s.recent_best, s.sessions,
+               CAST((julianday('now') - julianday(
+                   (SELECT MAX(start_time) FROM sets b WHERE b.exercise_id = s.exercise_id AND NOT b.recent AND b.e1rm = s.best)
+               )) / 7 AS INTEGER)
+        FROM summary s
+        JOIN exercises e ON e.id = s.exercise_id
+        WHERE s.sessions >= 2 AND s.recent_best <= s.best
+        ORDER BY 7 DESC, e.name
+        "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
+    )
+    .bind(weeks * 7)
+    .fetch_all(pool)
+    .await?;
+
+    Ok(rows
+        .into_iter()
+        .map(|(exercise_id, exercise, best_kg, best_date, recent_kg, sessions, weeks_since_best)| Stall {
+            exercise_id,
+            exercise,
+            best_kg,
+            best_date,
+            recent_kg,
+            sessions,
+            weeks_since_best,
+        })
+        .collect())
+}
+
+fn stall_line(s: &Stall) -> String {
+    tf(
+        "best {} kg e1RM on {}, top {} kg in {} sessions since",
+        &[
+            &kg_places(s.best_kg, 1),
+            &display_db_date(&s.best_date),
+            &kg_places(s.recent_kg, 1),
+            &s.sessions,
+        ],
+    )
+}
+
+/// Stalled lifts for `status`. Prints nothing when everything moves.
+pub async fn print_stalls(pool: &SqlitePool, weeks: u32) -> Result<()> {
+    let stalls = stalled(pool, weeks).await?;
+    if stalls.is_empty() {
+        return Ok(());
+    }
+
+    println!();
+    println!(
+        "{} {}",
+        tr("Stalled lifts:").heading().bold(),
+        tf("(no e1RM PR in {}+ weeks, `suggest` for what to do)", &[&weeks]).dimmed()
+    );
+    for s in &stalls {
+        println!("  {:<20} {}", s.exercise.bold(), stall_line(s).dimmed());
+    }
+
+    Ok(())
+}
+
+/// Most trained other exercise for the same primary muscle.
+async fn variation(pool: &SqlitePool, exercise_id: &str) -> Result<Option<String>> {
+    Ok(sqlx::query_scalar(
+        r#"
+        SELECT e.name
+        FROM exercises e
+        JOIN training_session_exercises tse ON tse.exercise_id = e.id
+        WHERE e.primary_muscle = (SELECT primary_muscle FROM exercises WHERE id = ?1)
+        AND e.id <> ?1
+        GROUP BY e.id
+        ORDER BY COUNT(tse.id) DESC, e.name
+        LIMIT 1
+        "#,
+    )
+    .bind(exercise_id)
+    .fetch_optional(pool)
+    .await?)
+}
+
+#[derive(Serialize)]
+struct Suggestion {
+    #[serde(flatten)]
+    stall: Stall,
+    action: &'static str,
+    trend: Option<Trend>,
+    deload_kg: Option<f64>,
+    variation: Option<String>,
+}
+
+/// A deload first, sized off the smoothed e1RM trend rather than a single set;
+/// a lift still stuck after twice the window gets a variation swap instead.
+pub async fn handle_suggest(pool: &SqlitePool, weeks: u32, fmt: OutputFmt) -> Result<()> {
+    let mut out = Vec::new();
+    for stall in stalled(pool, weeks).await? {
+        let trend = e1rm_trend(pool, &stall.exercise_id).await?;
+        let suggestion = if stall.weeks_since_best >= 2 * weeks as i64 {
+            let variation = variation(pool, &stall.exercise_id).await?;
+            Suggestion { stall, action: "variation", trend, deload_kg: None, variation }
+        } else {
+            let base = trend.map_or(stall.recent_kg, |t| t.estimate_kg);
+            let deload = (base * 0.9 * 10.0).round() / 10.0;
+            Suggestion { stall, action: "deload", trend, deload_kg: Some(deload), variation: None }
+        };
+        out.push(suggestion);
+    }
+
+    if out.is_empty() {
+        ui::info(tf("no lift has gone {} weeks without an e1RM PR", &[&weeks]));
+        return Ok(());
+    }
+
+    emit(fmt, &out, || {
+        println!("{}", tr("Suggestions:").heading().bold());
+        for s in &out {
+            println!("• {} {}", s.stall.exercise.bold(), stall_line(&s.stall).dimmed());
+            if let Some(t) = &s.trend {
+                println!(
+                    "  {}",
+                    tf(
+                        "trend {} kg e1RM (likely {}–{} kg)",
+                        &[
+                            &kg_places(t.estimate_kg, 1),
+                            &kg_places(t.low_kg, 1),
+                            &kg_places(t.high_kg, 1),
+                        ]
+                    )
+                    .dimmed()
+                );
+            }
+            let advice = match (s.deload_kg, s.variation.as_deref()) {
+                (Some(kg), _) => tf(
+                    "deload: a week around {} kg e1RM (90% of the current trend), then build back over 2-3 weeks",
+                    &[&kg_places(kg, 1)],
+                ),
+                (None, Some(alt)) => tf(
+                    "swap for a variation: `{}` for a block, then come back to it",
+                    &[&alt],
+                ),
+                (None, None) => tr("swap for a variation for a block (change grip, stance or tempo)").to_string(),
+            };
+            println!("  {} {}", "→".highlight(), advice);
+        }
+    });
+
+    Ok(())
+}
@@ -0,0 +1,184 @@
+use anyhow::Result;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::test_1rm::current_max,
+    errors::AppError,
+    i18n::{kg, kg_places, tf, tr},
+    types::{OutputFmt, Sex, emit},
+    ui::Themed,
+};
+
+/// The lifts standards (and powerlifting points) are published for.
+#[derive(Clone, Copy, PartialEq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum Lift {
+    Squat,
+    Bench,
+    Deadlift,
+    Press,
+}
+
+impl Lift {
+    pub const ALL: [Lift; 4] = [Lift::Squat, Lift::Bench, Lift::Deadlift, Lift::Press];
+
+    pub fn label(self) -> &'static str {
+        match self {
+            Lift::Squat => "Squat",
+            Lift::Bench => "Bench press",
+            Lift::Deadlift => "Deadlift",
+            Lift::Press => "Overhead press",
+        }
+    }
+
+    /// Exercise names (or aliases) counted as this lift, lowercase.
+    fn names(self) -> &'static [&'static str] {
+        match self {
+            Lift::Squat => &["squat", "back squat", "barbell squat", "barbell back squat", "high bar squat", "low bar squat"],
+            Lift::Bench => &["bench press", "bench", "barbell bench press", "flat bench press"],
+            Lift::Deadlift => &["deadlift", "conventional deadlift", "barbell deadlift", "sumo deadlift"],
+            Lift::Press => &["overhead press", "ohp", "military press", "barbell overhead press", "standing press"],
+        }
+    }
+
+    /// e1RM as a multiple of bodyweight needed for novice, intermediate,
+    /// advanced and elite. Rounded from the common published tables, which
+    /// scale close to linearly with bodyweight in the usual ranges.
+    fn ratios(self, sex: Sex) -> [f32; 4] {
+        match (sex, self) {
+            (Sex::Male, Lift::Squat) => [1.0, 1.5, 2.0, 2.5],
+            (Sex::Male, Lift::Bench) => [0.75, 1.0, 1.5, 2.0],
+            (Sex::Male, Lift::Deadlift) => [1.25, 1.75, 2.5, 3.0],
+            (Sex::Male, Lift::Press) => [0.5, 0.75, 1.0, 1.25],
+            (Sex::Female, Lift::Squat) => [0.75, 1.25, 1.5, 2.0],
+            (Sex::Female, Lift::Bench) => [0.5, 0.75, 1.0, 1.25],
+            (Sex::Female, Lift::Deadlift) => [1.0, 1.5, 2.0, 2.5],
+            (Sex::Female, Lift::Press) => [0.35, 0.5, 0.75, 0.9],
+        }
+    }
+}
+
+const LEVELS: [&str; 5] = ["untrained", "novice", "intermediate", "advanced", "elite"];
+
+/// Heaviest current max among the exercises that count as `lift`, with the
+/// name of the exercise it came from.
+pub async fn lift_best(pool: &SqlitePool, lift: Lift) -> Result<Option<(String, f32)>> {
+    let rows: Vec<(String, String, Option<String>)> = sqlx::query_as(
+        r#"
+        SELECT e.id, e.name, a.alias
+        FROM exercises e
+        LEFT JOIN exercise_aliases a ON a.exercise_id = e.id
+        "#,
+    )
+    .fetch_all(pool)
+    .await?;
+
+    let names = lift.names();
+    let mut best: Option<(String, f32)> = None;
+    let mut seen: Vec<&str> = Vec::new();
+    for (id, name, alias) in &rows {
+        let hit = names.contains(&name.to_lowercase().as_str())
+            || alias.as_ref().is_some_and(|a| names.contains(&a.to_lowercase().as_str()));
+        if !hit || seen.contains(&id.as_str()) {
+            continue;
+        }
+        seen.push(id);
+
+        if let Some(max) = current_max(pool, id).await? {
+            if best.as_ref().is_none_or(|(_, b)| max > *b) {
+                best = Some((name.clone(), max));
+            }
+        }
+    }
+
+    Ok(best)
+}
+
+#[derive(Serialize)]
+struct Standing {
+    lift: Lift,
+    exercise: Option<String>,
+    e1rm_kg: Option<f32>,
+    bodyweight_ratio: Option<f32>,
+    level: Option<&'static str>,
+    next_level: Option<&'static str>,
+    next_level_kg: Option<f32>,
+}
+
+fn standing(lift: Lift, best: Option<(String, f32)>, bodyweight: f32, sex: Sex) -> Standing {
+    let thresholds = lift.ratios(sex).map(|r| r * bodyweight);
+    let Some((exercise, e1rm)) = best else {
+        return Standing {
+            lift,
+            exercise: None,
+            e1rm_kg: None,
+            bodyweight_ratio: None,
+            level: None,
+            next_level: None,
+            next_level_kg: None,
+        };
+    };
+
+    let reached = thresholds.iter().filter(|t| e1rm >= **t).count();
+    Standing {
+        lift,
+        exercise: Some(exercise),
+        e1rm_kg: Some(e1rm),
+        bodyweight_ratio: Some(e1rm / bodyweight),
+        level: Some(LEVELS[reached]),
+        next_level: LEVELS.get(reached + 1).copied(),
+        next_level_kg: thresholds.get(reached).copied(),
+    }
+}
+
+pub async fn handle(pool: &SqlitePool, bodyweight: Option<f32>, sex: Option<Sex>, fmt: OutputFmt) -> Result<()> {
+    let (Some(bodyweight), Some(sex)) = (bodyweight, sex) else {
+        return Err(AppError::Invalid(tr("set your bodyweight and sex first, e.g. `config set bodyweight 82.5` and `config set sex male`").into()).into());
+    };
+
+    let mut standings = Vec::new();
+    for lift in Lift::ALL {
+        let best = lift_best(pool, lift).await?;
+        standings.push(standing(lift, best, bodyweight, sex));
+    }
+
+    emit(fmt, &standings, || {
+        println!(
+            "{} {}",
+            tr("Strength standards").heading().bold(),
+            tf("({} kg bodyweight)", &[&kg(bodyweight)]).dimmed()
+        );
+        for s in &standings {
+            let (Some(e1rm), Some(level)) = (s.e1rm_kg, s.level) else {
+                println!("  {:<16} {}", tr(s.lift.label()).bold(), tr("no sets logged").dimmed());
+                continue;
+            };
+
+            let level_str = match level {
+                "elite" | "advanced" => tr(level).good().bold().to_string(),
+                "untrained" => tr(level).dimmed().to_string(),
+                _ => tr(level).accent().to_string(),
+            };
+            let next = match (s.next_level, s.next_level_kg) {
+                (Some(next), Some(next_kg)) => tf("{} at {} kg (+{} kg)", &[&tr(next), &kg_places(next_kg, 1), &kg_places(next_kg - e1rm, 1)]),
+                _ => tr("top of the table").to_string(),
+            };
+            println!(
+                "  {:<16} {:>6.1} kg  {:>5.2}× {:<14} {}",
+                tr(s.lift.label()).bold(),
+                e1rm,
+                s.bodyweight_ratio.unwrap_or_default(),
+                level_str,
+                next.dimmed()
+            );
+            // Say which exercise counted when it isn't obvious from the label.
+            if let Some(ex) = s.exercise.as_ref().filter(|ex| !ex.eq_ignore_ascii_case(s.lift.label())) {
+                println!("  {:<16} {}", "", tf("from {}", &[&ex]).dimmed());
+            }
+        }
+    });
+
+    Ok(())
+}
@@ -3,6 +3,22 @@ use chrono::{DateTime, Utc};
 use colored::Colorize;
 use sqlx::SqlitePool;
 
+use crate::{
+    commands::{
+        goal::print_goals,
+        measure::print_trends,
+        points::print_history,
+        nutrition::{print_averages, print_yesterday},
+        recovery::print_overlay,
+        rest::monday,
+        stall::print_stalls,
+        week::print_week_progress,
+    },
+    formula,
+    i18n::{tf, tr},
+    ui::Themed,
+};
+
 fn create_ascii_graph(data: &[(DateTime<Utc>, f32)], width: usize, height: usize, title: &str) -> Vec<String> {
     if data.is_empty() {
         return vec!["No data available".to_string()];
@@ -137,12 +153,12 @@ async fn show_global_progression(pool: &SqlitePool, weeks: u32, show_graph: bool
 
     // Get PR progression data for the period
     let pr_progression_data: Vec<(String, f32)> = sqlx::query_as(
-        r#"
+        &format!(r#"
         WITH weekly_pr_data AS (
             SELECT 
                 date(es.timestamp, 'weekday 1', '-6 days') as week_start,
                 tse.exercise_id,
-                MAX(CAST(es.weight AS REAL) * (1 + CAST(es.reps AS REAL) / 30)) as week_best_1rm
+                MAX({e1rm}) as week_best_1rm
             FROM exercise_sets es
             JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
             JOIN training_sessions ts ON ts.id = tse.training_session_id
@@ -154,7 +170,7 @@ async fn show_global_progression(pool: &SqlitePool, weeks: u32, show_graph: bool
         baseline_prs AS (
             SELECT 
                 exercise_id,
-                MAX(CAST(weight AS REAL) * (1 + CAST(reps AS REAL) / 30)) as baseline_1rm
+                MAX({e1rm_bare}) as baseline_1rm
             FROM exercise_sets es
             JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
             JOIN training_sessions ts ON ts.id = tse.training_session_id
@@ -177,7 +193,7 @@ async fn show_global_progression(pool: &SqlitePool, weeks: u32, show_graph: bool
             ORDER BY wpd.week_start
         )
         SELECT week_start, avg_improvement_percent FROM weekly_improvements
-        "#,
+        "#, e1rm_bare = formula::e1rm_sql("weight", "reps"), e1rm = formula::e1rm_sql("es.weight", "es.reps")),
     )
     .bind(weeks * 7)
     .bind(weeks * 7)
@@ -266,20 +282,20 @@ async fn show_global_progression(pool: &SqlitePool, weeks: u32, show_graph: bool
         (0.0, 0)
     };
 
-    println!("{} ({} weeks)", "Global Training Status".cyan().bold(), weeks);
+    println!("{} {}", tr("Global Training Status").heading().bold(), tf("({} weeks)", &[&weeks]));
     println!();
 
     // Print summary stats
-    println!("{}: {:.0} kg", "Total tonnage".cyan().bold(), total_tonnage);
-    println!("{}: {} sets", "Total volume".cyan().bold(), total_sets);
-    println!("{}: {} sessions", "Training sessions".cyan().bold(), total_sessions);
-    println!("{}: {} exercises", "Active exercises".cyan().bold(), active_exercises);
+    println!("{}: {:.0} kg", tr("Total tonnage").heading().bold(), total_tonnage);
+    println!("{}: {} sets", tr("Total volume").heading().bold(), total_sets);
+    println!("{}: {} sessions", tr("Training sessions").heading().bold(), total_sessions);
+    println!("{}: {} exercises", tr("Active exercises").heading().bold(), active_exercises);
     
     if total_sessions > 0 {
         let avg_frequency = total_sessions as f64 / (weeks as f64);
         let avg_tonnage_per_session = total_tonnage / total_sessions as f64;
-        println!("{}: {:.1} sessions/week", "Avg frequency".cyan().bold(), avg_frequency);
-        println!("{}: {:.0} kg/session", "Avg tonnage/session".cyan().bold(), avg_tonnage_per_session);
+        println!("{}: {:.1} sessions/week", tr("Avg frequency").heading().bold(), avg_frequency);
+        println!("{}: {:.0} kg/session", tr("Avg tonnage/session").heading().bold(), avg_tonnage_per_session);
     }
 
     // Print percentage improvements
@@ -292,10 +308,10 @@ async fn show_global_progression(pool: &SqlitePool, weeks: u32, show_graph: bool
         };
 
         println!();
-        println!("{}", "Volume trends over period:".cyan().bold());
+        println!("{}", tr("Volume trends over period:").heading().bold());
         
-        let tonnage_color = if tonnage_improvement > 0.0 { "▲".green() } else { "▼".red() };
-        let sets_color = if sets_improvement > 0.0 { "▲".green() } else { "▼".red() };
+        let tonnage_color = if tonnage_improvement > 0.0 { "▲".good() } else { "▼".bad() };
+        let sets_color = if sets_improvement > 0.0 { "▲".good() } else { "▼".bad() };
         
         println!("  {} Weekly tonnage: {:+.1}% ({:.0} → {:.0} kg)", 
                 tonnage_color, tonnage_improvement, early_tonnage, late_tonnage);
@@ -306,9 +322,9 @@ async fn show_global_progression(pool: &SqlitePool, weeks: u32, show_graph: bool
     // Print PR improvement statistics
     if exercises_with_prs > 0 {
         println!();
-        println!("{}", "Strength progression:".cyan().bold());
+        println!("{}", tr("Strength progression:").heading().bold());
         
-        let pr_color = if pr_improvement_percent > 0.0 { "▲".green() } else { "▼".red() };
+        let pr_color = if pr_improvement_percent > 0.0 { "▲".good() } else { "▼".bad() };
         println!("  {} Average PR improvement: {:+.1}% across {} exercises", 
                 pr_color, pr_improvement_percent, exercises_with_prs);
     }
@@ -428,12 +444,12 @@ async fn show_muscle_progression(pool: &SqlitePool, muscle: &str, weeks: u32, sh
 
     // Get PR progression data for this muscle group
     let pr_progression_data: Vec<(String, f32)> = sqlx::query_as(
-        r#"
+        &format!(r#"
         WITH weekly_pr_data AS (
             SELECT 
                 date(es.timestamp, 'weekday 1', '-6 days') as week_start,
                 tse.exercise_id,
-                MAX(CAST(es.weight AS REAL) * (1 + CAST(es.reps AS REAL) / 30)) as week_best_1rm
+                MAX({e1rm}) as week_best_1rm
             FROM exercise_sets es
             JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
             JOIN training_sessions ts ON ts.id = tse.training_session_id
@@ -447,7 +463,7 @@ async fn show_muscle_progression(pool: &SqlitePool, muscle: &str, weeks: u32, sh
         baseline_prs AS (
             SELECT 
                 exercise_id,
-                MAX(CAST(weight AS REAL) * (1 + CAST(reps AS REAL) / 30)) as baseline_1rm
+                MAX({e1rm_bare}) as baseline_1rm
             FROM exercise_sets es
             JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
             JOIN training_sessions ts ON ts.id = tse.training_session_id
@@ -472,7 +488,7 @@ async fn show_muscle_progression(pool: &SqlitePool, muscle: &str, weeks: u32, sh
             ORDER BY wpd.week_start
         )
         SELECT week_start, avg_improvement_percent FROM weekly_improvements
-        "#,
+        "#, e1rm_bare = formula::e1rm_sql("weight", "reps"), e1rm = formula::e1rm_sql("es.weight", "es.reps")),
     )
     .bind(weeks * 7)
     .bind(muscle)
@@ -483,11 +499,11 @@ async fn show_muscle_progression(pool: &SqlitePool, muscle: &str, weeks: u32, sh
 
     // Get top exercises for this muscle
     let top_exercises: Vec<(String, f64, f32)> = sqlx::query_as(
-        r#"
+        &format!(r#"
         SELECT 
             e.name,
             COALESCE(SUM(CAST(es.weight AS REAL) * CAST(es.reps AS INTEGER)), 0) as tonnage,
-            MAX(CAST(es.weight AS REAL) * (1 + CAST(es.reps AS REAL) / 30)) as best_1rm
+            MAX({e1rm}) as best_1rm
         FROM exercise_sets es
         JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
         JOIN training_sessions ts ON ts.id = tse.training_session_id
@@ -499,7 +515,7 @@ async fn show_muscle_progression(pool: &SqlitePool, muscle: &str, weeks: u32, sh
         GROUP BY e.id, e.name
         ORDER BY tonnage DESC
         LIMIT 5
-        "#,
+        "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
     )
     .bind(weeks * 7)
     .bind(muscle)
@@ -507,7 +523,11 @@ async fn show_muscle_progression(pool: &SqlitePool, muscle: &str, weeks: u32, sh
     .await?;
 
     if muscle_tonnage == 0.0 {
-        println!("{} No training data found for muscle group: {}", "warning:".yellow().bold(), muscle);
+        println!(
+            "{} {}",
+            tr("warning:").accent().bold(),
+            tf("No training data found for muscle group: {}", &[&muscle])
+        );
         return Ok(());
     }
 
@@ -535,22 +555,26 @@ async fn show_muscle_progression(pool: &SqlitePool, muscle: &str, weeks: u32, sh
         (0.0, 0)
     };
 
-    println!("{} {} ({} weeks)", "Muscle Group Progress:".cyan().bold(), muscle.bold(), weeks);
+    println!(
+        "{} {}",
+        tr("Muscle Group Progress:").heading().bold(),
+        tf("{} ({} weeks)", &[&muscle.bold(), &weeks])
+    );
     println!();
 
     // Print muscle-specific stats
-    println!("{}: {:.0} kg", "Total tonnage".cyan().bold(), muscle_tonnage);
-    println!("{}: {} sets", "Total volume".cyan().bold(), muscle_sets);
-    println!("{}: {} exercises", "Active exercises".cyan().bold(), active_exercises);
+    println!("{}: {:.0} kg", tr("Total tonnage").heading().bold(), muscle_tonnage);
+    println!("{}: {} sets", tr("Total volume").heading().bold(), muscle_sets);
+    println!("{}: {} exercises", tr("Active exercises").heading().bold(), active_exercises);
 
     // Print percentage improvement for muscle volume
     if early_volume > 0.0 && late_volume > 0.0 {
         let volume_improvement = ((late_volume - early_volume) / early_volume) * 100.0;
         
         println!();
-        println!("{}", "Volume trends over period:".cyan().bold());
+        println!("{}", tr("Volume trends over period:").heading().bold());
         
-        let volume_color = if volume_improvement > 0.0 { "▲".green() } else { "▼".red() };
+        let volume_color = if volume_improvement > 0.0 { "▲".good() } else { "▼".bad() };
         println!("  {} Weekly volume: {:+.1}% ({:.0} → {:.0} sets/week)", 
                 volume_color, volume_improvement, early_volume, late_volume);
     }
@@ -558,15 +582,15 @@ async fn show_muscle_progression(pool: &SqlitePool, muscle: &str, weeks: u32, sh
     // Print PR improvement statistics
     if exercises_with_prs > 0 {
         println!();
-        println!("{}", "Strength progression:".cyan().bold());
+        println!("{}", tr("Strength progression:").heading().bold());
         
-        let pr_color = if pr_improvement_percent > 0.0 { "▲".green() } else { "▼".red() };
+        let pr_color = if pr_improvement_percent > 0.0 { "▲".good() } else { "▼".bad() };
         println!("  {} Average PR improvement: {:+.1}% across {} exercises", 
                 pr_color, pr_improvement_percent, exercises_with_prs);
     }
 
     println!();
-    println!("{}", "Top exercises by tonnage:".cyan().bold());
+    println!("{}", tr("Top exercises by tonnage:").heading().bold());
     for (name, tonnage, best_1rm) in top_exercises {
         println!("  {} — {:.0} kg tonnage, {:.0} kg best 1RM", name.bold(), tonnage, best_1rm);
     }
@@ -631,9 +655,71 @@ async fn show_muscle_progression(pool: &SqlitePool, muscle: &str, weeks: u32, sh
     Ok(())
 }
 
-pub async fn handle_status(muscle: Option<String>, weeks: u32, graph: bool, pool: &SqlitePool) -> Result<()> {
+/// Sets per muscle this week (or the last 7 days with `rolling`) next to the
+/// average of the 4 windows before it, so a Monday reading isn't just zeros.
+async fn print_muscle_sets(pool: &SqlitePool, rolling: bool) -> Result<()> {
+    let today = chrono::Local::now().date_naive();
+    let from = if rolling { today - chrono::Duration::days(6) } else { monday(today) };
+    let prior_from = from - chrono::Duration::weeks(4);
+
+    let rows: Vec<(String, i64, i64)> = sqlx::query_as(
+        r#"
+        SELECT e.primary_muscle,
+               CAST(SUM(date(ts.start_time) >= ?1) AS INTEGER),
+               CAST(SUM(date(ts.start_time) < ?1) AS INTEGER)
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        JOIN training_sessions ts ON ts.id = tse.training_session_id
+        JOIN exercises e ON e.id = tse.exercise_id
+        WHERE ts.end_time IS NOT NULL
+        AND es.status != 'skipped'
+        AND date(ts.start_time) >= ?2
+        GROUP BY e.primary_muscle
+        ORDER BY 2 DESC, 3 DESC, e.primary_muscle
+        "#,
+    )
+    .bind(from.format("%Y-%m-%d").to_string())
+    .bind(prior_from.format("%Y-%m-%d").to_string())
+    .fetch_all(pool)
+    .await?;
+    if rows.is_empty() {
+        return Ok(());
+    }
+
+    println!();
+    let title = if rolling { tr("Sets per muscle (last 7 days):") } else { tr("Sets per muscle (current week):") };
+    println!("{} {}", title.heading().bold(), tr("(4-week avg)").dimmed());
+    for (muscle, sets, prior) in rows {
+        let avg = prior as f64 / 4.0;
+        let sets_str = format!("{:>3}", sets);
+        let sets_str = if avg > 0.0 && sets as f64 >= avg { sets_str.good().to_string() } else { sets_str };
+        println!("  {:<11} {} {}", muscle, sets_str, format!("({:.1})", avg).dimmed());
+    }
+
+    Ok(())
+}
+
+pub async fn handle_status(
+    muscle: Option<String>,
+    weeks: u32,
+    graph: bool,
+    rolling: bool,
+    stall_weeks: u32,
+    pool: &SqlitePool,
+) -> Result<()> {
     match muscle {
         Some(muscle_name) => show_muscle_progression(pool, &muscle_name, weeks, graph).await,
-        None => show_global_progression(pool, weeks, graph).await,
+        None => {
+            print_week_progress(pool).await?;
+            print_goals(pool, None).await?;
+            print_yesterday(pool).await?;
+            show_global_progression(pool, weeks, graph).await?;
+            print_muscle_sets(pool, rolling).await?;
+            print_stalls(pool, stall_weeks).await?;
+            print_overlay(pool, weeks).await?;
+            print_averages(pool, weeks).await?;
+            print_trends(pool, weeks).await?;
+            print_history(pool, weeks).await
+        }
     }
 } 
\ No newline at end of file
@@ -0,0 +1,140 @@
+use anyhow::Result;
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::session::rounding_for,
+    errors::AppError,
+    formula,
+    i18n::{tf, tr},
+    types::RoundingRules,
+    ui::{self, Themed},
+};
+
+/// Warm-up ramp as (% of the expected max, reps).
+const WARMUPS: &[(f32, i32)] = &[(40.0, 5), (55.0, 3), (70.0, 2), (80.0, 1), (87.0, 1)];
+
+/// Opener, second and third attempt as % of the expected max.
+const ATTEMPTS: &[(f32, &str)] = &[
+    (92.0, "opener — should move fast"),
+    (98.0, "second — a heavy single you're sure of"),
+    (102.0, "third — only if the second moved well"),
+];
+
+/// Current max of an exercise: the stored e1RM, else the best set ever logged.
+pub async fn current_max(pool: &SqlitePool, exercise_id: &str) -> Result<Option<f32>> {
+    Ok(sqlx::query_scalar(
+        &format!(r#"
+        SELECT COALESCE(
+            (SELECT estimated_one_rm FROM exercises WHERE id = ?1),
+            (SELECT MAX({e1rm})
+             FROM exercise_sets es
+             JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+             WHERE tse.exercise_id = ?1 AND es.weight > 0 AND es.bodyweight = 0)
+        )
+        "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
+    )
+    .bind(exercise_id)
+    .fetch_one(pool)
+    .await?)
+}
+
+pub async fn handle(
+    pool: &SqlitePool,
+    exercise: String,
+    result: Option<f32>,
+    from: Option<f32>,
+    tm_percent: f32,
+    rules: RoundingRules,
+) -> Result<()> {
+    let found: Option<(String, String)> =
+        sqlx::query_as("SELECT id, name FROM exercises WHERE name = ? COLLATE NOCASE OR CAST(idx AS TEXT) = ?")
+            .bind(&exercise)
+            .bind(&exercise)
+            .fetch_optional(pool)
+            .await?;
+    let Some((exercise_id, name)) = found else {
+        return Err(AppError::ExerciseNotFound(exercise).into());
+    };
+    let rounding = rounding_for(pool, &rules, &exercise_id).await?;
+
+    let Some(tested) = result else {
+        let Some(max) = from.or(current_max(pool, &exercise_id).await?) else {
+            return Err(AppError::NotFound(tf("no e1RM for `{}` yet, pass `--from <kg>` to plan around a guess", &[&name]).into()).into());
+        };
+
+        println!(
+            "{} {}",
+            tr("1RM test:").heading().bold(),
+            tf("{} (planned around {})", &[&name.bold(), &rounding.format(max)])
+        );
+        println!("\n{}", tr("Warm-up:").heading().bold());
+        for (i, (pct, reps)) in WARMUPS.iter().enumerate() {
+            println!(
+                "  {} {} × {} {}",
+                (i + 1).to_string().accent(),
+                rounding.format(rounding.round(max * pct / 100.0)),
+                reps,
+                format!("({}%)", pct).dimmed()
+            );
+        }
+        println!("\n{}", tr("Attempts:").heading().bold());
+        for (i, (pct, hint)) in ATTEMPTS.iter().enumerate() {
+            println!(
+                "  {} {} × 1 {}",
+                (i + 1).to_string().accent(),
+                rounding.format(rounding.round(max * pct / 100.0)).bold(),
+                format!("({}%, {})", pct, tr(hint)).dimmed()
+            );
+        }
+        println!(
+            "\n{}",
+            tf("Rest 3-5 min between attempts, then record the heaviest good single with `test-1rm \"{}\" --result <kg>`.", &[&name])
+                .dimmed()
+        );
+        return Ok(());
+    };
+
+    if tested <= 0.0 {
+        return Err(AppError::Invalid(tr("the tested 1RM must be above 0").into()).into());
+    }
+
+    let mut tx = pool.begin().await?;
+
+    // A tested max is a 1-rep record whose "estimate" is exactly what was lifted.
+    sqlx::query(
+        r#"
+        INSERT OR REPLACE INTO personal_records (exercise_id, weight, reps, estimated_1rm, date, verified)
+        VALUES (?, ?, 1, ?, datetime('now'), 1)
+        "#,
+    )
+    .bind(&exercise_id)
+    .bind(tested)
+    .bind(tested)
+    .execute(&mut *tx)
+    .await?;
+
+    sqlx::query("UPDATE exercises SET estimated_one_rm = ?, current_pr_date = datetime('now') WHERE id = ?")
+        .bind(tested)
+        .bind(&exercise_id)
+        .execute(&mut *tx)
+        .await?;
+
+    // Only blocks that already program off a 1RM get the new training max.
+    let training_max = rounding.round(tested * tm_percent / 100.0);
+    let updated = sqlx::query("UPDATE program_exercises SET program_1rm = ? WHERE exercise_id = ? AND program_1rm IS NOT NULL")
+        .bind(training_max)
+        .bind(&exercise_id)
+        .execute(&mut *tx)
+        .await?
+        .rows_affected();
+
+    tx.commit().await?;
+
+    ui::ok(tf("recorded a tested 1RM of {} for {}", &[&rounding.format(tested), &name.bold()]));
+    if updated > 0 {
+        ui::info(tf("training max set to {} ({}%) in {} program block(s)", &[&rounding.format(training_max), &tm_percent, &updated]));
+    }
+
+    Ok(())
+}
@@ -0,0 +1,144 @@
+use anyhow::Result;
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::{
+        goal::print_goals,
+        rest::{current_streak, load_weeks},
+        week::pending_blocks,
+    },
+    i18n::{long_date, tf, tr},
+    types::SET_LOAD,
+    ui::Themed,
+};
+
+/// Open sessions with their block and how long they've been going.
+async fn print_active(pool: &SqlitePool) -> Result<()> {
+    let open: Vec<(String, Option<String>, i64)> = sqlx::query_as(
+        r#"
+        SELECT pb.name, ts.tag,
+               CAST((julianday('now') - julianday(ts.start_time)) * 24 * 60 AS INTEGER)
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        WHERE ts.end_time IS NULL
+        ORDER BY ts.start_time
+        "#,
+    )
+    .fetch_all(pool)
+    .await?;
+
+    for (block, tag, minutes) in &open {
+        let tag = tag.as_ref().map(|t| format!(" [{}]", t).accent().to_string()).unwrap_or_default();
+        println!(
+            "{} {}{} {}",
+            tr("In progress:").good().bold(),
+            block.bold(),
+            tag,
+            tf("({} min so far — `session show`)", &[&minutes]).dimmed()
+        );
+    }
+
+    Ok(())
+}
+
+/// Next blocks of every program that has been trained.
+async fn print_next_up(pool: &SqlitePool) -> Result<()> {
+    let programs: Vec<(String, String)> = sqlx::query_as(
+        r#"
+        SELECT DISTINCT p.id, p.name
+        FROM programs p
+        JOIN program_blocks pb ON pb.program_id = p.id
+        JOIN training_sessions ts ON ts.program_block_id = pb.id
+        ORDER BY p.name
+        "#,
+    )
+    .fetch_all(pool)
+    .await?;
+
+    for (id, name) in programs {
+        let (week, pending) = pending_blocks(pool, &id).await?;
+        let next = match pending.first() {
+            Some(block) => block.bold().to_string(),
+            None => tr("nothing left this week").dimmed().to_string(),
+        };
+        println!(
+            "{} {} {} {}",
+            tr("Next up:").heading().bold(),
+            next,
+            tf("— {}, week {}", &[&name, &week]).dimmed(),
+            if pending.len() > 1 {
+                tf("(+{} more)", &[&(pending.len() - 1)]).dimmed().to_string()
+            } else {
+                String::new()
+            }
+        );
+    }
+
+    Ok(())
+}
+
+/// Block, date, duration, sets and tonnage of the latest finished session.
+async fn print_last_session(pool: &SqlitePool) -> Result<()> {
+    let last: Option<(String, String, i64, i64, f64)> = sqlx::query_as(
+        &format!(r#"
+        SELECT pb.name, ts.start_time,
+               CAST((julianday(ts.end_time) - julianday(ts.start_time)
+                     - COALESCE((SELECT SUM(julianday(sp.resumed_at) - julianday(sp.paused_at))
+                                 FROM session_pauses sp
+                                 WHERE sp.training_session_id = ts.id), 0)) * 24 * 60 AS INTEGER),
+               CAST(COUNT(es.id) AS INTEGER),
+               CAST(COALESCE(SUM({load} * es.reps), 0) AS REAL)
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        LEFT JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+        LEFT JOIN exercise_sets es ON es.session_exercise_id = tse.id
+        WHERE ts.id = (
+            SELECT id FROM training_sessions WHERE end_time IS NOT NULL
+            ORDER BY start_time DESC LIMIT 1
+        )
+        GROUP BY ts.id
+        "#, load = SET_LOAD),
+    )
+    .fetch_optional(pool)
+    .await?;
+
+    match last {
+        Some((block, start, minutes, sets, tonnage)) => {
+            let when = chrono::NaiveDate::parse_from_str(&start[..10], "%Y-%m-%d")
+                .map(long_date)
+                .unwrap_or_else(|_| start[..10].to_string());
+            println!(
+                "{} {} {} {}",
+                tr("Last session:").heading().bold(),
+                block.bold(),
+                when.dimmed(),
+                tf("({} min, {} sets, {} kg)", &[&minutes, &sets, &format!("{:.0}", tonnage)])
+            );
+        }
+        None => println!("{} {}", tr("Last session:").heading().bold(), tr("none yet").dimmed()),
+    }
+
+    Ok(())
+}
+
+/// Dashboard shown by `today` and by a bare `lazarus`.
+pub async fn handle(pool: &SqlitePool) -> Result<()> {
+    let today = chrono::Local::now().date_naive();
+    println!("{}", long_date(today).heading().bold());
+    println!();
+
+    print_active(pool).await?;
+    print_next_up(pool).await?;
+    print_last_session(pool).await?;
+
+    let (trained, rested) = load_weeks(pool).await?;
+    println!(
+        "{} {}",
+        tr("Streak:").heading().bold(),
+        tf("{} weeks", &[&current_streak(&trained, &rested, today)])
+    );
+    println!();
+
+    print_goals(pool, None).await
+}
@@ -0,0 +1,152 @@
+use anyhow::Result;
+use colored::Colorize;
+use serde::Serialize;
+use sha2::{Digest, Sha256};
+use sqlx::SqlitePool;
+
+use crate::{
+    cli::{TokenCmd, TokenScope},
+    errors::AppError,
+    i18n::{tf, tr},
+    types::{OutputFmt, emit},
+    ui::{self, Themed},
+};
+
+/// Tokens start with this, so they're easy to spot in a config file or a leak.
+const PREFIX: &str = "lzr_";
+
+/// A token that authenticated a request.
+pub struct Token {
+    pub name: String,
+    pub scope: TokenScope,
+}
+
+#[derive(Serialize)]
+struct Listed {
+    name: String,
+    scope: String,
+    created_at: String,
+    last_used_at: Option<String>,
+}
+
+fn scope_name(scope: TokenScope) -> &'static str {
+    match scope {
+        TokenScope::Read => "read",
+        TokenScope::Write => "write",
+    }
+}
+
+fn hash(secret: &str) -> String {
+    Sha256::digest(secret.as_bytes()).iter().map(|b| format!("{:02x}", b)).collect()
+}
+
+/// 244 random bits; v4 uuids come from the OS random number generator.
+fn generate() -> String {
+    format!("{}{}{}", PREFIX, uuid::Uuid::new_v4().simple(), uuid::Uuid::new_v4().simple())
+}
+
+/// Whether any token exists, i.e. whether the server has to ask for one.
+pub async fn any(pool: &SqlitePool) -> Result<bool> {
+    Ok(sqlx::query_scalar("SELECT EXISTS (SELECT 1 FROM api_tokens)")
+        .fetch_one(pool)
+        .await?)
+}
+
+/// The token `secret` belongs to, marking it as used; `None` when it's unknown
+/// or was revoked.
+pub async fn authenticate(pool: &SqlitePool, secret: &str) -> Result<Option<Token>> {
+    let row: Option<(String, String)> = sqlx::query_as(
+        r#"
+        UPDATE api_tokens SET last_used_at = datetime('now')
+        WHERE token_hash = ?
+        RETURNING name, scope
+        "#,
+    )
+    .bind(hash(secret))
+    .fetch_optional(pool)
+    .await?;
+
+    Ok(row.map(|(name, scope)| Token {
+        name,
+        scope: if scope == "write" { TokenScope::Write } else { TokenScope::Read },
+    }))
+}
+
+pub async fn handle(cmd: TokenCmd, pool: &SqlitePool, fmt: OutputFmt) -> Result<()> {
+    match cmd {
+        TokenCmd::Create { name, scope } => {
+            let taken: bool = sqlx::query_scalar("SELECT EXISTS (SELECT 1 FROM api_tokens WHERE name = ?)")
+                .bind(&name)
+                .fetch_one(pool)
+                .await?;
+            if taken {
+                return Err(AppError::Invalid(tf("a token named `{}` already exists (revoke it first)", &[&name])).into());
+            }
+
+            let secret = generate();
+            sqlx::query(
+                r#"
+                INSERT INTO api_tokens (id, name, token_hash, scope, created_at)
+                VALUES (?, ?, ?, ?, datetime('now'))
+                "#,
+            )
+            .bind(uuid::Uuid::new_v4().to_string())
+            .bind(&name)
+            .bind(hash(&secret))
+            .bind(scope_name(scope))
+            .execute(pool)
+            .await?;
+
+            ui::ok(tf("created {} token `{}`", &[&scope_name(scope), &name]));
+            // The token itself goes to stdout on its own line, so it can be piped.
+            println!("{}", secret);
+            ui::info(tr("it won't be shown again; send it as `Authorization: Bearer <token>`"));
+        }
+
+        TokenCmd::List => {
+            let rows: Vec<(String, String, String, Option<String>)> = sqlx::query_as(
+                "SELECT name, scope, created_at, last_used_at FROM api_tokens ORDER BY created_at, name",
+            )
+            .fetch_all(pool)
+            .await?;
+            let tokens: Vec<Listed> = rows
+                .into_iter()
+                .map(|(name, scope, created_at, last_used_at)| Listed { name, scope, created_at, last_used_at })
+                .collect();
+
+            emit(fmt, &tokens, || {
+                println!("{}", tr("Tokens:").heading().bold());
+                if tokens.is_empty() {
+                    println!("{}", tr("  (no tokens — the HTTP server is open to anyone who can reach it)").dimmed());
+                }
+                for t in &tokens {
+                    let used = match &t.last_used_at {
+                        Some(at) => tf("last used {}", &[at]),
+                        None => tr("never used").to_string(),
+                    };
+                    let scope = if t.scope == "write" { t.scope.as_str().accent() } else { t.scope.as_str().good() };
+                    println!(
+                        " • {} {} {}",
+                        t.name.bold(),
+                        scope,
+                        format!("– {}, {}", tf("created {}", &[&t.created_at]), used).dimmed()
+                    );
+                }
+            });
+        }
+
+        TokenCmd::Revoke { name } => {
+            let res = sqlx::query("DELETE FROM api_tokens WHERE name = ?")
+                .bind(&name)
+                .execute(pool)
+                .await?;
+
+            if res.rows_affected() == 0 {
+                return Err(AppError::NotFound(tf("no token named `{}`", &[&name])).into());
+            }
+            ui::ok(tf("revoked token `{}`", &[&name]));
+        }
+    }
+
+    Ok(())
+}
@@ -0,0 +1,274 @@
+use anyhow::Result;
+use colored::Colorize;
+use sqlx::SqlitePool;
+use uuid::Uuid;
+
+use crate::{
+    commands::{
+        session::{rounding_for, rpe_percent},
+        test_1rm::current_max,
+        wal,
+    },
+    errors::AppError,
+    i18n::{kg, tf, tr},
+    types::{RepTarget, Rounding, RoundingRules, SetStatus},
+    ui::{self, Themed},
+};
+
+/// Back-off reps when the program doesn't give any.
+const DEFAULT_BACKOFF_REPS: u32 = 5;
+
+/// Load for `reps` at `rpe` off an e1RM, rounded to what can be loaded.
+fn load_at(e1rm: f32, reps: u32, rpe: f32, rounding: Rounding) -> Option<f32> {
+    rpe_percent(reps, rpe).map(|pct| rounding.round(e1rm * pct / 100.0))
+}
+
+/// e1RM implied by `weight` × `reps` at `rpe`.
+fn e1rm_at(weight: f32, reps: u32, rpe: f32) -> Option<f32> {
+    rpe_percent(reps, rpe).map(|pct| weight * 100.0 / pct)
+}
+
+/// The next attempt: the load for the target off the e1RM the last attempt
+/// implies, but always a step up from it.
+fn next_attempt(last: (f32, f32), reps: u32, target: f32, rounding: Rounding) -> f32 {
+    let (weight, rpe) = last;
+    let up = rounding.round(weight + 2.5).max(weight + 0.5);
+    e1rm_at(weight, reps, rpe)
+        .and_then(|e1rm| load_at(e1rm, reps, target, rounding))
+        .map_or(up, |w| w.max(up))
+}
+
+/// "140 8" (weight and RPE) or "8" (RPE of the suggested weight).
+fn parse_attempt(line: &str, suggested: Option<f32>) -> Option<(f32, f32)> {
+    let nums: Vec<f32> = line
+        .split_whitespace()
+        .map(|t| t.trim_start_matches('@').trim_end_matches("kg").parse().ok())
+        .collect::<Option<_>>()?;
+    let (weight, rpe) = match nums[..] {
+        [rpe] => (suggested?, rpe),
+        [weight, rpe] => (weight, rpe),
+        _ => return None,
+    };
+    (weight > 0.0 && (5.0..=10.0).contains(&rpe)).then_some((weight, rpe))
+}
+
+async fn read_line() -> Result<Option<String>> {
+    Ok(tokio::task::spawn_blocking(|| {
+        let mut line = String::new();
+        std::io::stdin().read_line(&mut line).map(|n| (n > 0).then_some(line))
+    })
+    .await??)
+}
+
+/// Log one attempt as a set of the session exercise, with its RPE.
+async fn log_attempt(
+    pool: &SqlitePool,
+    session_id: &str,
+    tse_id: &str,
+    exercise_id: &str,
+    weight: f32,
+    reps: u32,
+    rpe: f32,
+) -> Result<()> {
+    let set_id = Uuid::new_v4().to_string();
+    let timestamp = chrono::Utc::now().format("%Y-%m-%d %H:%M:%S").to_string();
+    wal::append(
+        session_id,
+        &wal::SetEntry {
+            set_id: set_id.clone(),
+            session_exercise_id: tse_id.to_string(),
+            exercise_id: exercise_id.to_string(),
+            weight,
+            reps: reps as i32,
+            bodyweight: false,
+            body_mass: None,
+            tempo: None,
+            pause: None,
+            amrap: false,
+            band: None,
+            band_tension: None,
+            chain_weight: None,
+            ignore_for_one_rm: false,
+            side: None,
+            status: SetStatus::Completed.as_str().to_string(),
+            timestamp: timestamp.clone(),
+            rpe: Some(rpe),
+            flags: None,
+        },
+    )?;
+
+    sqlx::query(
+        "INSERT INTO exercise_sets (id, session_exercise_id, weight, reps, rpe, timestamp) VALUES (?, ?, ?, ?, ?, ?)",
+    )
+    .bind(&set_id)
+    .bind(tse_id)
+    .bind(weight)
+    .bind(reps as i32)
+    .bind(rpe)
+    .bind(&timestamp)
+    .execute(pool)
+    .await?;
+
+    Ok(())
+}
+
+/// Back-off sets off the top set: the program's back-off group when the
+/// exercise has one, else `sets` × the program's reps `drop`% below the top.
+async fn print_backoffs(
+    pool: &SqlitePool,
+    tse_id: &str,
+    exercise_id: &str,
+    top: f32,
+    drop: f32,
+    sets: u32,
+    rounding: Rounding,
+) -> Result<()> {
+    let planned: Vec<(f32, Option<String>)> = sqlx::query_as(
+        "SELECT percent, reps FROM session_set_targets WHERE session_exercise_id = ? AND kind = 'backoff' ORDER BY position",
+    )
+    .bind(tse_id)
+    .fetch_all(pool)
+    .await?;
+
+    let backoffs: Vec<(f32, String)> = if planned.is_empty() {
+        let program_reps: Option<String> = sqlx::query_scalar(
+            r#"
+            SELECT pe.reps
+            FROM program_exercises pe
+            JOIN training_sessions ts ON ts.program_block_id = pe.program_block_id
+            JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+            WHERE tse.id = ? AND pe.exercise_id = ?
+            "#,
+        )
+        .bind(tse_id)
+        .bind(exercise_id)
+        .fetch_optional(pool)
+        .await?
+        .flatten();
+        let reps = program_reps
+            .as_deref()
+            .and_then(|csv| csv.split(',').next_back())
+            .and_then(RepTarget::parse)
+            .and_then(RepTarget::min_reps)
+            .unwrap_or(DEFAULT_BACKOFF_REPS);
+        (0..sets).map(|_| (1.0 - drop / 100.0, reps.to_string())).collect()
+    } else {
+        planned
+            .into_iter()
+            .map(|(pct, reps)| (pct, reps.unwrap_or_else(|| "?".to_string())))
+            .collect()
+    };
+
+    println!("{}", tr("Back-off sets:").heading().bold());
+    for (i, (pct, reps)) in backoffs.iter().enumerate() {
+        println!(
+            "   {} • {} × {} {}",
+            format!("B{}", i + 1).highlight(),
+            rounding.format(top * pct),
+            reps,
+            tf("({}% of the top set)", &[&format!("{:.0}", pct * 100.0)]).dimmed()
+        );
+    }
+
+    Ok(())
+}
+
+/// `session top-set`: work up to a top single/double at the target RPE, one
+/// logged attempt at a time, then print the back-off sets off it.
+pub async fn handle(
+    pool: &SqlitePool,
+    session_id: &str,
+    exercise: usize,
+    target: f32,
+    reps: u32,
+    from: Option<f32>,
+    drop: f32,
+    sets: u32,
+    rules: RoundingRules,
+) -> Result<()> {
+    if !(6.0..=10.0).contains(&target) {
+        return Err(AppError::Invalid(tf("target RPE must be between 6 and 10, got {}", &[&target])).into());
+    }
+    if !(1..=3).contains(&reps) {
+        return Err(AppError::Invalid(tr("a top set is a single, double or triple (--reps 1-3)").into()).into());
+    }
+    if !(0.0..100.0).contains(&drop) {
+        return Err(AppError::Invalid(tf("invalid back-off drop: {}%", &[&drop])).into());
+    }
+
+    let found: Option<(String, String, String)> = sqlx::query_as(
+        r#"
+        SELECT tse.id, e.id, e.name
+        FROM training_session_exercises tse
+        JOIN exercises e ON e.id = tse.exercise_id
+        WHERE tse.training_session_id = ?
+        ORDER BY tse.order_index
+        LIMIT 1 OFFSET ?
+        "#,
+    )
+    .bind(session_id)
+    .bind(exercise.saturating_sub(1) as i64)
+    .fetch_optional(pool)
+    .await?;
+    let Some((tse_id, exercise_id, name)) = found.filter(|_| exercise > 0) else {
+        return Err(AppError::NotFound(tf("no exercise at index {}", &[&exercise]).into()).into());
+    };
+    let rounding = rounding_for(pool, &rules, &exercise_id).await?;
+
+    // Open two RPE below the target so there is room to work up.
+    let e1rm = current_max(pool, &exercise_id).await?.filter(|m| *m > 0.0);
+    let mut suggested = from
+        .map(|w| rounding.round(w))
+        .or_else(|| e1rm.and_then(|m| load_at(m, reps, (target - 2.0).max(6.0), rounding)));
+
+    println!(
+        "{} {}",
+        tf("Top set @{}:", &[&target]).heading().bold(),
+        tf("{} × {}", &[&name.bold(), &reps])
+    );
+    if let Some(m) = e1rm {
+        println!("{}", tf("  e1RM {}kg, aiming for about {}", &[&kg(m), &load_at(m, reps, target, rounding).map(|w| rounding.format(w)).unwrap_or_default()]).dimmed());
+    }
+    println!("{}", tr("  after each attempt type its RPE (or `<kg> <rpe>` for another weight), q to stop").dimmed());
+
+    let mut attempts: Vec<(f32, f32)> = Vec::new();
+    let top = loop {
+        let prompt = match suggested {
+            Some(w) => tf("Attempt {}: {} × {} — RPE?", &[&(attempts.len() + 1), &rounding.format(w), &reps]),
+            None => tf("Attempt {}: <kg> <rpe>?", &[&(attempts.len() + 1)]),
+        };
+        println!("{}", prompt.accent().bold());
+
+        let Some(line) = read_line().await? else { break None };
+        let line = line.trim();
+        if line.eq_ignore_ascii_case("q") {
+            break None;
+        }
+        let Some((weight, rpe)) = parse_attempt(line, suggested) else {
+            println!("{}", tr("  type an RPE like `7.5`, or a weight and an RPE like `140 8`").dimmed());
+            continue;
+        };
+
+        log_attempt(pool, session_id, &tse_id, &exercise_id, weight, reps, rpe).await?;
+        attempts.push((weight, rpe));
+        if rpe >= target {
+            if rpe > target + 1.0 {
+                println!("{}", tf("  overshot the target by {} RPE, take the back-offs a little lighter", &[&(rpe - target)]).bad());
+            }
+            break Some((weight, rpe));
+        }
+        suggested = Some(next_attempt((weight, rpe), reps, target, rounding));
+    };
+
+    let Some((weight, rpe)) = top else {
+        ui::info(tf("stopped after {} attempts, no top set @{}", &[&attempts.len(), &target]));
+        return Ok(());
+    };
+
+    let implied = e1rm_at(weight, reps, rpe).map(|m| format!(" (e1RM ~{}kg)", kg(m))).unwrap_or_default();
+    ui::ok(tf("top set: {}kg × {} @{}{}", &[&kg(weight), &reps, &rpe, &implied]));
+    print_backoffs(pool, &tse_id, &exercise_id, weight, drop, sets, rounding).await?;
+    println!("{}", tr("log them with `session edit`").dimmed());
+
+    Ok(())
+}
@@ -0,0 +1,67 @@
+use anyhow::Result;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::formula;
+
+/// Weight of the newest session in the trend; 0.3 gives about a two-session
+/// half-life.
+const ALPHA: f64 = 0.3;
+
+/// Sessions needed before the band means anything.
+const MIN_SESSIONS: usize = 3;
+
+/// Smoothed e1RM of an exercise with a ~95% band around it.
+#[derive(Serialize, Clone, Copy)]
+pub struct Trend {
+    pub estimate_kg: f64,
+    pub low_kg: f64,
+    pub high_kg: f64,
+    pub sessions: usize,
+}
+
+/// Exponentially weighted average (and spread) of each session's top e1RM, so
+/// one lucky set only moves the estimate a little.
+fn smooth(tops: &[f64]) -> Option<Trend> {
+    let (&first, rest) = tops.split_first()?;
+    let mut mean = first;
+    let mut var = 0.0;
+    for &x in rest {
+        let diff = x - mean;
+        mean += ALPHA * diff;
+        var = (1.0 - ALPHA) * (var + ALPHA * diff * diff);
+    }
+    let half = 1.96 * var.sqrt();
+
+    Some(Trend {
+        estimate_kg: mean,
+        low_kg: mean - half,
+        high_kg: mean + half,
+        sessions: tops.len(),
+    })
+}
+
+/// e1RM trend over an exercise's finished sessions, `None` until it has a few.
+pub async fn e1rm_trend(pool: &SqlitePool, exercise_id: &str) -> Result<Option<Trend>> {
+    let tops: Vec<f64> = sqlx::query_scalar(
+        &format!(r#"
+        SELECT MAX({e1rm})
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        JOIN training_sessions ts ON ts.id = tse.training_session_id
+        WHERE tse.exercise_id = ?
+        AND ts.end_time IS NOT NULL
+        AND es.weight > 0 AND es.bodyweight = 0 AND COALESCE(es.ignore_for_one_rm, 0) = 0
+        GROUP BY ts.id
+        ORDER BY ts.start_time
+        "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
+    )
+    .bind(exercise_id)
+    .fetch_all(pool)
+    .await?;
+
+    if tops.len() < MIN_SESSIONS {
+        return Ok(None);
+    }
+    Ok(smooth(&tops))
+}
@@ -0,0 +1,195 @@
+use std::collections::BTreeMap;
+
+use anyhow::Result;
+use chrono::{Duration, NaiveDate};
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::rest::monday,
+    dates::{DATE_FORMATS, parse_date},
+    errors::AppError,
+    i18n::{short_date, tf, tr},
+    types::{OutputFmt, SET_LOAD, emit},
+    ui::Themed,
+};
+
+/// Rows flagged as the biggest drops, per section.
+const FLAGGED_DROPS: usize = 3;
+
+#[derive(Serialize)]
+struct VolumeDiff {
+    week_a: String,
+    week_b: String,
+    total: Change,
+    muscles: Vec<Change>,
+    exercises: Vec<Change>,
+}
+
+#[derive(Serialize, Default, Clone)]
+struct Change {
+    name: String,
+    sets_a: i64,
+    sets_b: i64,
+    tonnage_a: f64,
+    tonnage_b: f64,
+}
+
+impl Change {
+    fn set_delta(&self) -> i64 {
+        self.sets_b - self.sets_a
+    }
+
+    fn tonnage_delta(&self) -> f64 {
+        self.tonnage_b - self.tonnage_a
+    }
+}
+
+/// Sets (skipped ones left out) and tonnage per exercise of the week starting `from`,
+/// as (exercise, muscle, sets, tonnage).
+async fn week_volume(pool: &SqlitePool, from: NaiveDate) -> Result<Vec<(String, String, i64, f64)>> {
+    Ok(sqlx::query_as(&format!(
+        r#"
+        SELECT e.name, e.primary_muscle, COUNT(*), COALESCE(SUM({load} * es.reps), 0)
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        JOIN training_sessions ts ON ts.id = tse.training_session_id
+        JOIN exercises e ON e.id = tse.exercise_id
+        WHERE ts.end_time IS NOT NULL
+        AND es.status != 'skipped'
+        AND date(ts.start_time) >= ? AND date(ts.start_time) < ?
+        GROUP BY e.id
+        "#,
+        load = SET_LOAD
+    ))
+    .bind(from.format("%Y-%m-%d").to_string())
+    .bind((from + Duration::days(7)).format("%Y-%m-%d").to_string())
+    .fetch_all(pool)
+    .await?)
+}
+
+async fn collect(pool: &SqlitePool, a: NaiveDate, b: NaiveDate) -> Result<VolumeDiff> {
+    let mut muscles: BTreeMap<String, Change> = BTreeMap::new();
+    let mut exercises: BTreeMap<String, Change> = BTreeMap::new();
+    let mut total = Change { name: tr("Total").to_string(), ..Default::default() };
+
+    for (week, from) in [(0, a), (1, b)] {
+        for (exercise, muscle, sets, tonnage) in week_volume(pool, from).await? {
+            let rows = [
+                exercises.entry(exercise.clone()).or_insert_with(|| Change { name: exercise, ..Default::default() }),
+                muscles.entry(muscle.clone()).or_insert_with(|| Change { name: muscle, ..Default::default() }),
+                &mut total,
+            ];
+            for row in rows {
+                if week == 0 {
+                    row.sets_a += sets;
+                    row.tonnage_a += tonnage;
+                } else {
+                    row.sets_b += sets;
+                    row.tonnage_b += tonnage;
+                }
+            }
+        }
+    }
+
+    // Biggest drops first: by sets, then by tonnage.
+    let sorted = |rows: BTreeMap<String, Change>| {
+        let mut rows: Vec<Change> = rows.into_values().collect();
+        rows.sort_by(|x, y| {
+            x.set_delta()
+                .cmp(&y.set_delta())
+                .then(x.tonnage_delta().total_cmp(&y.tonnage_delta()))
+        });
+        rows
+    };
+
+    Ok(VolumeDiff {
+        week_a: a.format("%Y-%m-%d").to_string(),
+        week_b: b.format("%Y-%m-%d").to_string(),
+        total,
+        muscles: sorted(muscles),
+        exercises: sorted(exercises),
+    })
+}
+
+fn print_row(c: &Change, width: usize, flagged: bool) {
+    let delta = c.set_delta();
+    let sets = format!("{:>3} → {:<3}", c.sets_a, c.sets_b);
+    let sets_delta = format!("{:+}", delta);
+    let sets_delta = match delta {
+        d if d < 0 => sets_delta.bad().to_string(),
+        d if d > 0 => sets_delta.good().to_string(),
+        _ => sets_delta.dimmed().to_string(),
+    };
+    let tonnage = match c.tonnage_a {
+        a if a > 0.0 => format!("{:+.0}%", c.tonnage_delta() / a * 100.0),
+        _ if c.tonnage_b > 0.0 => tr("new").to_string(),
+        _ => "—".to_string(),
+    };
+    let marker = if flagged { "▼".bad().bold().to_string() } else { " ".to_string() };
+    println!(
+        "  {} {:<width$} {} {:>4}   {}",
+        marker,
+        c.name,
+        sets,
+        sets_delta,
+        format!("{:.0} → {:.0} kg ({})", c.tonnage_a, c.tonnage_b, tonnage).dimmed(),
+        width = width
+    );
+}
+
+fn print_section(title: &str, rows: &[Change]) {
+    if rows.is_empty() {
+        return;
+    }
+    println!("\n{}", title.heading().bold());
+    let width = rows.iter().map(|r| r.name.chars().count()).max().unwrap_or(0);
+    for (i, row) in rows.iter().enumerate() {
+        // Rows are sorted biggest drop first.
+        let flagged = i < FLAGGED_DROPS && (row.set_delta() < 0 || (row.set_delta() == 0 && row.tonnage_delta() < 0.0));
+        print_row(row, width, flagged);
+    }
+}
+
+fn print_pretty(d: &VolumeDiff, a: NaiveDate, b: NaiveDate) {
+    println!(
+        "{} {}",
+        tr("Volume:").heading().bold(),
+        tf("week of {} → week of {}", &[&short_date(a), &short_date(b)])
+    );
+    if d.total.sets_a == 0 && d.total.sets_b == 0 {
+        println!("{}", tr("  (no finished sessions in either week)").dimmed());
+        return;
+    }
+    print_row(&d.total, d.total.name.chars().count(), false);
+    print_section(&tr("Muscles (sets, tonnage):"), &d.muscles);
+    print_section(&tr("Exercises (sets, tonnage):"), &d.exercises);
+}
+
+/// `volume-diff`: sets and tonnage per muscle and per exercise between two
+/// weeks (Monday to Sunday), the biggest drops first. Without weeks it
+/// compares last week to this one; with one, the week before it to it.
+pub async fn handle(pool: &SqlitePool, weeks: Vec<String>, fmt: OutputFmt) -> Result<()> {
+    let mut mondays = Vec::with_capacity(2);
+    for week in &weeks {
+        let Some(day) = parse_date(Some(week)) else {
+            return Err(AppError::Invalid(tf("invalid date `{}` (expected {})", &[&week, &DATE_FORMATS])).into());
+        };
+        mondays.push(monday(day));
+    }
+    let (a, b) = match mondays[..] {
+        [] => {
+            let this = monday(chrono::Local::now().date_naive());
+            (this - Duration::weeks(1), this)
+        }
+        [b] => (b - Duration::weeks(1), b),
+        [a, b] => (a, b),
+        _ => return Err(AppError::Invalid(tr("give at most two weeks").into()).into()),
+    };
+
+    let diff = collect(pool, a, b).await?;
+    emit(fmt, &diff, || print_pretty(&diff, a, b));
+
+    Ok(())
+}
@@ -0,0 +1,258 @@
+use anyhow::Result;
+use colored::{ColoredString, Colorize};
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::session::rounding_for,
+    i18n::{kg, tf, tr},
+    types::{RepTarget, RoundingRules},
+    ui::{self, Themed},
+};
+
+/// How close a logged weight must be to the target to count as on it, in kg;
+/// pound plates don't land exactly on a kg target.
+const WEIGHT_SLACK: f32 = 0.5;
+/// How close a logged RPE must be to the target to count as on it.
+const RPE_SLACK: f32 = 0.5;
+
+/// How one part of a set compares with its target.
+#[derive(Clone, Copy, PartialEq)]
+enum Verdict {
+    Beat,
+    Met,
+    Missed,
+}
+
+impl Verdict {
+    fn paint(self, s: String) -> ColoredString {
+        match self {
+            Self::Met => s.as_str().good(),
+            Self::Beat => s.as_str().accent(),
+            Self::Missed => s.as_str().bad(),
+        }
+    }
+
+    /// Beat when `diff` is over `slack`, missed when under `-slack`.
+    fn of(diff: f32, slack: f32) -> Self {
+        if diff > slack {
+            Self::Beat
+        } else if diff < -slack {
+            Self::Missed
+        } else {
+            Self::Met
+        }
+    }
+}
+
+/// Reps against a target: over a range's top (or an exact/AMRAP count) beats it,
+/// under its bottom misses it. Timed targets aren't judged.
+fn reps_verdict(target: RepTarget, reps: i32) -> Option<Verdict> {
+    let reps = reps.max(0) as u32;
+    let (lo, hi) = match target {
+        RepTarget::Range(lo, hi) => (lo, hi),
+        RepTarget::Time(_) => return None,
+        t => {
+            let n = t.min_reps()?;
+            (n, n)
+        }
+    };
+    Some(if reps > hi {
+        Verdict::Beat
+    } else if reps < lo {
+        Verdict::Missed
+    } else {
+        Verdict::Met
+    })
+}
+
+/// Planned sets (as the program stood when the session started) and the ones
+/// logged of one exercise of the session.
+struct Exercise {
+    name: String,
+    sets: i32,
+    reps: Vec<String>,
+    rpes: Vec<f32>,
+    percents: Vec<f32>,
+    program_1rm: Option<f32>,
+    /// (weight, reps, rpe, bodyweight, status), in the order logged.
+    logged: Vec<(f32, i32, Option<f32>, bool, String)>,
+    exercise_id: String,
+}
+
+async fn exercises(pool: &SqlitePool, session_id: &str) -> Result<Vec<Exercise>> {
+    let rows: Vec<(String, String, String, i32, Option<String>, Option<String>, Option<String>, Option<f32>)> =
+        sqlx::query_as(
+            r#"
+            SELECT tse.id, e.id, e.name, COALESCE(pe.sets, 0), pe.reps, pe.target_rpe, pe.target_rm_percent, pe.program_1rm
+            FROM training_session_exercises tse
+            JOIN exercises e ON e.id = tse.exercise_id
+            LEFT JOIN session_program_exercises pe ON pe.exercise_id = COALESCE(tse.rotated_from, e.id)
+                AND pe.training_session_id = tse.training_session_id
+            WHERE tse.training_session_id = ?
+            ORDER BY tse.order_index
+            "#,
+        )
+        .bind(session_id)
+        .fetch_all(pool)
+        .await?;
+
+    let list = |s: Option<String>| -> Vec<String> {
+        s.map(|s| s.split(',').map(|v| v.trim().to_string()).collect()).unwrap_or_default()
+    };
+    let mut out = Vec::with_capacity(rows.len());
+    for (tse_id, exercise_id, name, sets, reps, rpes, percents, program_1rm) in rows {
+        // Unilateral exercises are judged by their left side.
+        let logged = sqlx::query_as(
+            r#"
+            SELECT weight, reps, rpe, bodyweight, status
+            FROM exercise_sets
+            WHERE session_exercise_id = ?
+            AND (side IS NULL OR side = 'L')
+            ORDER BY set_index
+            "#,
+        )
+        .bind(&tse_id)
+        .fetch_all(pool)
+        .await?;
+        out.push(Exercise {
+            name,
+            sets,
+            reps: list(reps),
+            rpes: list(rpes).iter().filter_map(|v| v.parse().ok()).collect(),
+            percents: list(percents).iter().filter_map(|v| v.parse().ok()).collect(),
+            program_1rm,
+            logged,
+            exercise_id,
+        });
+    }
+    Ok(out)
+}
+
+/// `session log --vs-target`: every set of a finished session next to its
+/// program target, reps, weight and RPE colored by whether they beat (yellow),
+/// met (green) or missed (red) it. The header's score is the share of planned
+/// sets with nothing missed.
+pub async fn print(pool: &SqlitePool, session_id: &str, title: &str, rules: RoundingRules) -> Result<()> {
+    let exercises = exercises(pool, session_id).await?;
+
+    let mut lines = Vec::new();
+    let (mut planned, mut on_target) = (0, 0);
+    for ex in &exercises {
+        let rounding = rounding_for(pool, &rules, &ex.exercise_id).await?;
+        lines.push(format!("\n{}", ex.name.bold()));
+
+        for i in 0..(ex.sets.max(0) as usize).max(ex.logged.len()) {
+            let is_planned = i < ex.sets as usize;
+            let reps_target = ex.reps.get(i).filter(|_| is_planned);
+            let target_rpe = ex.rpes.get(i).copied().filter(|_| is_planned);
+            // As in the plain log, an RPE target takes over from a %1RM one.
+            let target_weight = match (ex.program_1rm, ex.percents.get(i)) {
+                (Some(orm), Some(pct)) if is_planned && target_rpe.is_none() => Some(rounding.round(orm * pct / 100.0)),
+                _ => None,
+            };
+
+            let mut target = Vec::new();
+            if let Some(reps) = reps_target {
+                target.push(reps.clone());
+            }
+            if let Some(w) = target_weight {
+                target.push(format!("{}kg", kg(w)));
+            }
+            if let Some(rpe) = target_rpe {
+                target.push(format!("@{}", rpe));
+            }
+            let target = match (is_planned, target.is_empty()) {
+                (false, _) => tr("extra").to_string(),
+                (true, true) => tr("no target").to_string(),
+                (true, false) => target.join(" "),
+            };
+
+            let mut missed = false;
+            let done = match ex.logged.get(i) {
+                None => {
+                    missed = true;
+                    tr("not logged").bad().to_string()
+                }
+                Some((_, _, _, _, status)) if status == "skipped" => {
+                    missed = true;
+                    tr("skipped").bad().to_string()
+                }
+                Some((weight, reps, rpe, bodyweight, status)) => {
+                    let mut parts = Vec::new();
+                    if !bodyweight && *weight > 0.0 {
+                        let w = format!("{}kg", kg(*weight));
+                        parts.push(match target_weight {
+                            Some(t) => {
+                                let v = Verdict::of(weight - t, WEIGHT_SLACK);
+                                missed |= v == Verdict::Missed;
+                                v.paint(w).to_string()
+                            }
+                            None => w,
+                        });
+                    }
+                    let r = format!("× {}", reps);
+                    let v = match reps_target.and_then(|t| RepTarget::parse(t)) {
+                        Some(t) if status == "failed" => reps_verdict(t, *reps).map(|_| Verdict::Missed),
+                        Some(t) => reps_verdict(t, *reps),
+                        None => None,
+                    };
+                    missed |= v == Some(Verdict::Missed);
+                    parts.push(v.map(|v| v.paint(r.clone()).to_string()).unwrap_or(r));
+                    if let Some(rpe) = rpe {
+                        let r = format!("@{}", rpe);
+                        parts.push(match target_rpe {
+                            // A lower RPE than planned is reps left in the tank.
+                            Some(t) => {
+                                let v = Verdict::of(t - rpe, RPE_SLACK);
+                                missed |= v == Verdict::Missed;
+                                v.paint(r).to_string()
+                            }
+                            None => r,
+                        });
+                    }
+                    if status == "failed" {
+                        parts.push(tr("✗ failed").bad().to_string());
+                    }
+                    parts.join(" ")
+                }
+            };
+
+            if is_planned {
+                planned += 1;
+                if !missed {
+                    on_target += 1;
+                }
+            }
+            let mark = match (is_planned, missed) {
+                (false, _) => "·".dimmed(),
+                (true, false) => "✓".good(),
+                (true, true) => "✗".bad(),
+            };
+            lines.push(format!("  {} {} {:<20} {}", mark, format!("{}", i + 1).accent(), target.dimmed(), done));
+        }
+    }
+
+    let score = match planned {
+        0 => tr("no planned sets").dimmed().to_string(),
+        n => {
+            let pct = on_target as f64 / n as f64 * 100.0;
+            let s = tf("compliance {}% ({}/{} sets on target)", &[&format!("{:.0}", pct), &on_target, &n]);
+            match pct {
+                p if p >= 90.0 => s.good().bold().to_string(),
+                p if p >= 70.0 => s.accent().bold().to_string(),
+                _ => s.bad().bold().to_string(),
+            }
+        }
+    };
+    ui::outln!("{} {} — {}", tr("Session:").heading().bold(), title, score);
+    for line in lines {
+        ui::outln!("{}", line);
+    }
+    ui::outln!();
+    ui::outln!(
+        "{}",
+        tr("Yellow beat the target, green met it, red missed it; a set is on target when nothing was missed.")
+            .dimmed()
+    );
+    Ok(())
+}
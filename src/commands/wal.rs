@@ -0,0 +1,175 @@
+use std::{
+    collections::HashMap,
+    fs::{self, OpenOptions},
+    io::Write,
+    path::PathBuf,
+};
+
+use anyhow::{Context, Result};
+use serde::{Deserialize, Serialize};
+use sqlx::SqlitePool;
+
+/// A set as `session edit` (or `session top-set`) is about to write it. Each one is appended to the
+/// session's journal (and synced) before the database transaction runs, so a
+/// write lost to a crash can be replayed by `recover-session`.
+#[derive(Serialize, Deserialize)]
+pub struct SetEntry {
+    pub set_id: String,
+    pub session_exercise_id: String,
+    pub exercise_id: String,
+    pub weight: f32,
+    pub reps: i32,
+    pub bodyweight: bool,
+    pub body_mass: Option<f32>,
+    pub tempo: Option<String>,
+    pub pause: Option<String>,
+    pub amrap: bool,
+    pub band: Option<String>,
+    pub band_tension: Option<f32>,
+    pub chain_weight: Option<f32>,
+    pub ignore_for_one_rm: bool,
+    pub side: Option<String>,
+    pub status: String,
+    pub timestamp: String,
+    #[serde(default)]
+    pub rpe: Option<f32>,
+    #[serde(default)]
+    pub flags: Option<String>,
+}
+
+fn journal_path(session_id: &str) -> Result<PathBuf> {
+    Ok(dirs::data_dir()
+        .context("no data dir")?
+        .join("lazarus")
+        .join("journal")
+        .join(format!("{}.jsonl", session_id)))
+}
+
+/// Append `entry` to the session's journal, one JSON line per set.
+pub fn append(session_id: &str, entry: &SetEntry) -> Result<()> {
+    let path = journal_path(session_id)?;
+    if let Some(dir) = path.parent() {
+        fs::create_dir_all(dir).with_context(|| format!("could not create `{}`", dir.display()))?;
+    }
+    let mut file = OpenOptions::new()
+        .create(true)
+        .append(true)
+        .open(&path)
+        .with_context(|| format!("could not open `{}`", path.display()))?;
+    writeln!(file, "{}", serde_json::to_string(entry)?)?;
+    file.sync_data()?;
+    Ok(())
+}
+
+/// Drop the journal of a session that was finished or cancelled.
+pub fn discard(session_id: &str) -> Result<()> {
+    let path = journal_path(session_id)?;
+    if path.exists() {
+        fs::remove_file(&path).with_context(|| format!("could not remove `{}`", path.display()))?;
+    }
+    Ok(())
+}
+
+/// Write back what the journal has and the database lost: missing sets are
+/// inserted and sets whose values differ are updated, the last entry for a
+/// set winning. A torn last line (a crash mid-append) is skipped. Returns
+/// the number of sets replayed.
+pub async fn replay(pool: &SqlitePool, session_id: &str) -> Result<usize> {
+    let path = journal_path(session_id)?;
+    let Ok(text) = fs::read_to_string(&path) else {
+        return Ok(0);
+    };
+
+    let mut order = Vec::new();
+    let mut latest: HashMap<String, SetEntry> = HashMap::new();
+    for entry in text.lines().filter_map(|l| serde_json::from_str::<SetEntry>(l).ok()) {
+        if !latest.contains_key(&entry.set_id) {
+            order.push(entry.set_id.clone());
+        }
+        latest.insert(entry.set_id.clone(), entry);
+    }
+
+    let mut replayed = 0;
+    let mut tx = pool.begin().await?;
+    for id in &order {
+        let e = &latest[id];
+
+        // The set's exercise row may be gone too, e.g. a session rebuilt from an import.
+        sqlx::query("INSERT OR IGNORE INTO training_session_exercises (id, training_session_id, exercise_id) VALUES (?, ?, ?)")
+            .bind(&e.session_exercise_id)
+            .bind(session_id)
+            .bind(&e.exercise_id)
+            .execute(&mut *tx)
+            .await?;
+
+        let exists: bool = sqlx::query_scalar("SELECT EXISTS (SELECT 1 FROM exercise_sets WHERE id = ?)")
+            .bind(&e.set_id)
+            .fetch_one(&mut *tx)
+            .await?;
+        let affected = if exists {
+            sqlx::query(
+                r#"
+                UPDATE exercise_sets
+                SET weight = ?1, reps = ?2, bodyweight = ?3, body_mass = ?4, tempo = ?5, pause = ?6, amrap = ?7,
+                    band = ?8, band_tension = ?9, chain_weight = ?10, ignore_for_one_rm = ?11, status = ?12,
+                    flags = ?14
+                WHERE id = ?13
+                AND (weight IS NOT ?1 OR reps IS NOT ?2 OR bodyweight IS NOT ?3 OR body_mass IS NOT ?4
+                     OR band IS NOT ?8 OR band_tension IS NOT ?9 OR chain_weight IS NOT ?10
+                     OR ignore_for_one_rm IS NOT ?11 OR status IS NOT ?12 OR flags IS NOT ?14)
+                "#,
+            )
+            .bind(e.weight)
+            .bind(e.reps)
+            .bind(e.bodyweight as i32)
+            .bind(e.body_mass)
+            .bind(&e.tempo)
+            .bind(&e.pause)
+            .bind(e.amrap as i32)
+            .bind(&e.band)
+            .bind(e.band_tension)
+            .bind(e.chain_weight)
+            .bind(e.ignore_for_one_rm as i32)
+            .bind(&e.status)
+            .bind(&e.set_id)
+            .bind(&e.flags)
+            .execute(&mut *tx)
+            .await?
+            .rows_affected()
+        } else {
+            sqlx::query(
+                r#"
+                INSERT INTO exercise_sets (
+                    id, session_exercise_id, weight, reps, bodyweight, body_mass, tempo, pause, amrap,
+                    band, band_tension, chain_weight, ignore_for_one_rm, side, status, timestamp, rpe, flags
+                ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                "#,
+            )
+            .bind(&e.set_id)
+            .bind(&e.session_exercise_id)
+            .bind(e.weight)
+            .bind(e.reps)
+            .bind(e.bodyweight as i32)
+            .bind(e.body_mass)
+            .bind(&e.tempo)
+            .bind(&e.pause)
+            .bind(e.amrap as i32)
+            .bind(&e.band)
+            .bind(e.band_tension)
+            .bind(e.chain_weight)
+            .bind(e.ignore_for_one_rm as i32)
+            .bind(&e.side)
+            .bind(&e.status)
+            .bind(&e.timestamp)
+            .bind(e.rpe)
+            .bind(&e.flags)
+            .execute(&mut *tx)
+            .await?
+            .rows_affected()
+        };
+        replayed += affected as usize;
+    }
+    tx.commit().await?;
+
+    Ok(replayed)
+}
@@ -0,0 +1,285 @@
+use anyhow::Result;
+use colored::Colorize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::{program::resolve_program, rest::mark_rest_days},
+    errors::AppError,
+    i18n::{tf, tr},
+    ui::{self, Themed},
+};
+
+/// Current week of a program and when it started, creating the row on first use.
+async fn progress(pool: &SqlitePool, program_id: &str) -> Result<(i32, String)> {
+    sqlx::query(
+        "INSERT OR IGNORE INTO program_progress (program_id, current_week, week_started_at) VALUES (?, 1, datetime('now'))",
+    )
+    .bind(program_id)
+    .execute(pool)
+    .await?;
+
+    Ok(sqlx::query_as("SELECT current_week, week_started_at FROM program_progress WHERE program_id = ?")
+        .bind(program_id)
+        .fetch_one(pool)
+        .await?)
+}
+
+/// Highest explicit week of a program, `None` when its blocks have no weeks.
+async fn last_week(pool: &SqlitePool, program_id: &str) -> Result<Option<i32>> {
+    Ok(sqlx::query_scalar("SELECT MAX(week) FROM program_blocks WHERE program_id = ?")
+        .bind(program_id)
+        .fetch_one(pool)
+        .await?)
+}
+
+/// Planned off weeks of a program (`off_weeks` in its TOML).
+async fn off_weeks(pool: &SqlitePool, program_id: &str) -> Result<Vec<i32>> {
+    let csv: Option<String> = sqlx::query_scalar("SELECT off_weeks FROM programs WHERE id = ?")
+        .bind(program_id)
+        .fetch_one(pool)
+        .await?;
+
+    Ok(csv
+        .unwrap_or_default()
+        .split(',')
+        .filter_map(|w| w.trim().parse().ok())
+        .collect())
+}
+
+/// Week after `week`, back to 1 after the last block or off week.
+async fn following(pool: &SqlitePool, program_id: &str, week: i32) -> Result<i32> {
+    let last = last_week(pool, program_id)
+        .await?
+        .into_iter()
+        .chain(off_weeks(pool, program_id).await?)
+        .max();
+
+    Ok(match last {
+        Some(last) if week < last => week + 1,
+        Some(_) => 1,
+        None => week + 1,
+    })
+}
+
+/// Record the coming 7 days as rest when `week` is a planned off week.
+async fn rest_if_off(pool: &SqlitePool, program_id: &str, program_name: &str, week: i32) -> Result<()> {
+    if !off_weeks(pool, program_id).await?.contains(&week) {
+        return Ok(());
+    }
+
+    let tomorrow = chrono::Local::now().date_naive() + chrono::Duration::days(1);
+    let reason = format!("off week {} of {}", week, program_name);
+    mark_rest_days(pool, tomorrow, 7, &reason).await?;
+
+    ui::outln!(
+        "{} {}",
+        tr("note:").accent().bold(),
+        tf("week {} is a planned off week — the streak is frozen until you're back", &[&week])
+    );
+
+    Ok(())
+}
+
+/// Blocks scheduled for `week` and how many of them have a finished session
+/// since `since`. Blocks without a week belong to week 1, as in `p show -m`;
+/// a program with no weeks at all repeats every block each week.
+async fn week_status(pool: &SqlitePool, program_id: &str, week: i32, since: &str) -> Result<(i64, i64)> {
+    let weekly = last_week(pool, program_id).await?.is_some();
+
+    Ok(sqlx::query_as(
+        r#"
+        SELECT
+            CAST(COUNT(*) AS INTEGER),
+            CAST(COALESCE(SUM(EXISTS (
+                SELECT 1 FROM training_sessions ts
+                WHERE ts.program_block_id = pb.id
+                AND ts.end_time IS NOT NULL
+                AND ts.start_time >= ?
+            )), 0) AS INTEGER)
+        FROM program_blocks pb
+        WHERE pb.program_id = ?
+        AND (? = 0 OR COALESCE(pb.week, 1) = ?)
+        "#,
+    )
+    .bind(since)
+    .bind(program_id)
+    .bind(weekly)
+    .bind(week)
+    .fetch_one(pool)
+    .await?)
+}
+
+/// Current week of a program and its blocks still without a finished session
+/// this week, by name. No blocks during a planned off week.
+pub async fn pending_blocks(pool: &SqlitePool, program_id: &str) -> Result<(i32, Vec<String>)> {
+    let (week, since) = progress(pool, program_id).await?;
+    if off_weeks(pool, program_id).await?.contains(&week) {
+        return Ok((week, Vec::new()));
+    }
+    let weekly = last_week(pool, program_id).await?.is_some();
+
+    let blocks = sqlx::query_scalar(
+        r#"
+        SELECT pb.name
+        FROM program_blocks pb
+        WHERE pb.program_id = ?
+        AND (? = 0 OR COALESCE(pb.week, 1) = ?)
+        AND NOT EXISTS (
+            SELECT 1 FROM training_sessions ts
+            WHERE ts.program_block_id = pb.id
+            AND ts.end_time IS NOT NULL
+            AND ts.start_time >= ?
+        )
+        ORDER BY pb.name
+        "#,
+    )
+    .bind(program_id)
+    .bind(weekly)
+    .bind(week)
+    .bind(&since)
+    .fetch_all(pool)
+    .await?;
+
+    Ok((week, blocks))
+}
+
+/// Move `program_id` to `week`, starting `since` (or now).
+async fn set_week(pool: &SqlitePool, program_id: &str, week: i32, since: Option<&str>) -> Result<()> {
+    sqlx::query(
+        r#"
+        INSERT INTO program_progress (program_id, current_week, week_started_at)
+        VALUES (?, ?, COALESCE(?, datetime('now')))
+        ON CONFLICT (program_id)
+        DO UPDATE SET current_week = excluded.current_week, week_started_at = excluded.week_started_at
+        "#,
+    )
+    .bind(program_id)
+    .bind(week)
+    .bind(since)
+    .execute(pool)
+    .await?;
+
+    Ok(())
+}
+
+/// Called after a session ends: once every block of the current week has a
+/// finished session, roll the program to the next week (back to 1 after the last).
+pub async fn advance_after_session(pool: &SqlitePool, session_id: &str) -> Result<()> {
+    let Some((program_id, program_name)): Option<(String, String)> = sqlx::query_as(
+        r#"
+        SELECT p.id, p.name
+        FROM training_sessions ts
+        JOIN program_blocks pb ON pb.id = ts.program_block_id
+        JOIN programs p ON p.id = pb.program_id
+        WHERE ts.id = ?
+        "#,
+    )
+    .bind(session_id)
+    .fetch_optional(pool)
+    .await?
+    else {
+        return Ok(());
+    };
+
+    let (mut week, since) = progress(pool, &program_id).await?;
+
+    // Training again during a planned off week ends it early.
+    let off = off_weeks(pool, &program_id).await?;
+    for _ in 0..off.len() {
+        if !off.contains(&week) {
+            break;
+        }
+        week = following(pool, &program_id, week).await?;
+        set_week(pool, &program_id, week, Some(&since)).await?;
+    }
+
+    let (total, done) = week_status(pool, &program_id, week, &since).await?;
+    if total == 0 || done < total {
+        return Ok(());
+    }
+
+    let next = following(pool, &program_id, week).await?;
+    set_week(pool, &program_id, next, None).await?;
+
+    ui::outln!(
+        "{} {}",
+        tr("note:").accent().bold(),
+        tf("week {} of `{}` complete — now on week {}", &[&week, &program_name, &next])
+    );
+    rest_if_off(pool, &program_id, &program_name, next).await?;
+
+    Ok(())
+}
+
+/// "Program — week N of M: x/y blocks done" for every active program that has been trained.
+pub async fn print_week_progress(pool: &SqlitePool) -> Result<()> {
+    let programs: Vec<(String, String)> = sqlx::query_as(
+        r#"
+        SELECT DISTINCT p.id, p.name
+        FROM programs p
+        JOIN program_blocks pb ON pb.program_id = p.id
+        JOIN training_sessions ts ON ts.program_block_id = pb.id
+        WHERE p.archived_at IS NULL
+        ORDER BY p.name
+        "#,
+    )
+    .fetch_all(pool)
+    .await?;
+
+    if programs.is_empty() {
+        return Ok(());
+    }
+
+    ui::outln!("{}", tr("Program weeks:").heading().bold());
+    for (id, name) in programs {
+        let (week, since) = progress(pool, &id).await?;
+        let of = last_week(pool, &id)
+            .await?
+            .into_iter()
+            .chain(off_weeks(pool, &id).await?)
+            .max()
+            .map(|last| format!(" of {}", last))
+            .unwrap_or_default();
+        if off_weeks(pool, &id).await?.contains(&week) {
+            ui::outln!(
+                "  {} — week {}{}: {}",
+                name.bold(),
+                week.to_string().accent(),
+                of,
+                tr("planned off week").dimmed()
+            );
+            continue;
+        }
+        let (total, done) = week_status(pool, &id, week, &since).await?;
+        ui::outln!(
+            "  {} — week {}{}: {}/{} blocks done {}",
+            name.bold(),
+            week.to_string().accent(),
+            of,
+            done,
+            total,
+            format!("(since {})", &since[..10.min(since.len())]).dimmed()
+        );
+    }
+    ui::outln!();
+
+    Ok(())
+}
+
+pub async fn handle_set_week(pool: &SqlitePool, program: String, week: u32) -> Result<()> {
+    let program_id = resolve_program(pool, &program).await?;
+    if week == 0 {
+        return Err(AppError::Invalid(tr("weeks start at 1").into()).into());
+    }
+
+    set_week(pool, &program_id, week as i32, None).await?;
+    ui::ok(tf("`{}` is now on week {}", &[&program, &week]));
+
+    let name: String = sqlx::query_scalar("SELECT name FROM programs WHERE id = ?")
+        .bind(&program_id)
+        .fetch_one(pool)
+        .await?;
+    rest_if_off(pool, &program_id, &name, week as i32).await?;
+
+    Ok(())
+}
@@ -0,0 +1,298 @@
+use anyhow::Result;
+use chrono::Datelike;
+use colored::Colorize;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+use crate::{
+    commands::rest::{load_weeks, longest_streak},
+    formula,
+    i18n::{kg_places, month_name, tf, tr},
+    types::{OutputFmt, SET_LOAD, emit},
+    ui::{self, Themed},
+};
+
+#[derive(Serialize)]
+struct Wrapped {
+    year: i32,
+    sessions: i64,
+    sets: i64,
+    reps: i64,
+    tonnage_kg: f64,
+    tonnage_comparison: String,
+    most_trained: Option<(String, i64)>,
+    biggest_pr_jump: Option<PrJump>,
+    longest_streak_weeks: i64,
+    busiest_month: Option<(String, i64)>,
+    favorite_hour: Option<(u32, i64)>,
+}
+
+#[derive(Serialize)]
+struct PrJump {
+    exercise: String,
+    from_kg: f64,
+    to_kg: f64,
+}
+
+/// Something silly weighing about as much as the year's tonnage.
+fn tonnage_comparison(kg: f64) -> String {
+    let things: &[(f64, &str, &str)] = &[
+        (150_000.0, "blue whale", "blue whales"),
+        (6_000.0, "african elephant", "african elephants"),
+        (1_500.0, "car", "cars"),
+        (500.0, "grand piano", "grand pianos"),
+        (100.0, "fridge", "fridges"),
+        (4.5, "house cat", "house cats"),
+    ];
+
+    match things.iter().find(|(w, _, _)| kg >= *w) {
+        Some((w, one, many)) => {
+            let n = kg / w;
+            if n < 1.5 {
+                format!("about one {}", one)
+            } else {
+                format!("about {:.0} {}", n, many)
+            }
+        }
+        None => "not even a house cat (yet)".to_string(),
+    }
+}
+
+async fn collect(pool: &SqlitePool, year: i32) -> Result<Wrapped> {
+    let y = year.to_string();
+
+    let (sessions, sets, reps, tonnage): (i64, i64, i64, f64) = sqlx::query_as(
+        &format!(r#"
+        SELECT
+            CAST(COUNT(DISTINCT ts.id) AS INTEGER),
+            CAST(COUNT(es.id) AS INTEGER),
+            CAST(COALESCE(SUM(es.reps), 0) AS INTEGER),
+            CAST(COALESCE(SUM({load} * es.reps), 0) AS REAL)
+        FROM training_sessions ts
+        LEFT JOIN training_session_exercises tse ON tse.training_session_id = ts.id
+        LEFT JOIN exercise_sets es ON es.session_exercise_id = tse.id
+        WHERE strftime('%Y', ts.start_time) = ?
+        "#, load = SET_LOAD),
+    )
+    .bind(&y)
+    .fetch_one(pool)
+    .await?;
+
+    let most_trained: Option<(String, i64)> = sqlx::query_as(
+        r#"
+        SELECT e.name, CAST(COUNT(es.id) AS INTEGER) AS n
+        FROM exercise_sets es
+        JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+        JOIN exercises e ON e.id = tse.exercise_id
+        WHERE strftime('%Y', es.timestamp) = ?
+        GROUP BY e.id
+        ORDER BY n DESC
+        LIMIT 1
+        "#,
+    )
+    .bind(&y)
+    .fetch_optional(pool)
+    .await?;
+
+    // Best e1RM this year vs. the best before it (or the year's first set).
+    let biggest_pr_jump = sqlx::query_as::<_, (String, f64, f64)>(
+        &format!(r#"
+        WITH e1rm AS (
+            SELECT tse.exercise_id, es.timestamp,
+                   {e1rm} AS rm
+            FROM exercise_sets es
+            JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+            WHERE es.weight > 0 AND es.bodyweight = 0 AND es.ignore_for_one_rm = 0
+        ),
+        per_ex AS (
+            SELECT exercise_id,
+                   MAX(CASE WHEN strftime('%Y', timestamp) = ?1 THEN rm END) AS best,
+                   COALESCE(
+                       MAX(CASE WHEN strftime('%Y', timestamp) < ?1 THEN rm END),
+                       (SELECT rm FROM e1rm f
+                        WHERE f.exercise_id = e1rm.exercise_id
+                          AND strftime('%Y', f.timestamp) = ?1
+                        ORDER BY f.timestamp LIMIT 1)
+                   ) AS base
+            FROM e1rm
+            GROUP BY exercise_id
+        )
+        SELECT e.name, CAST(base AS REAL), CAST(best AS REAL)
+        FROM per_ex
+        JOIN exercises e ON e.id = per_ex.exercise_id
+        WHERE best > base
+        ORDER BY best - base DESC
+        LIMIT 1
+        "#, e1rm = formula::e1rm_sql("es.weight", "es.reps")),
+    )
+    .bind(&y)
+    .fetch_optional(pool)
+    .await?
+    .map(|(exercise, from_kg, to_kg)| PrJump {
+        exercise,
+        from_kg,
+        to_kg,
+    });
+
+    // Planned rest weeks keep the streak alive.
+    let (trained, rested) = load_weeks(pool).await?;
+    let longest_streak_weeks = match (
+        chrono::NaiveDate::from_ymd_opt(year, 1, 1),
+        chrono::NaiveDate::from_ymd_opt(year, 12, 31),
+    ) {
+        (Some(from), Some(to)) => longest_streak(&trained, &rested, from, to),
+        _ => 0,
+    };
+
+    let busiest_month: Option<(String, i64)> = sqlx::query_as(
+        r#"
+        SELECT strftime('%m', start_time) AS m, CAST(COUNT(*) AS INTEGER) AS n
+        FROM training_sessions
+        WHERE strftime('%Y', start_time) = ?
+        GROUP BY m
+        ORDER BY n DESC, m
+        LIMIT 1
+        "#,
+    )
+    .bind(&y)
+    .fetch_optional(pool)
+    .await?;
+    let busiest_month = busiest_month.map(|(m, n)| {
+        let name = m
+            .parse::<u32>()
+            .ok()
+            .filter(|m| (1..=12).contains(m))
+            .map(|m| month_name(m).to_string())
+            .unwrap_or(m);
+        (name, n)
+    });
+
+    let favorite_hour: Option<(String, i64)> = sqlx::query_as(
+        r#"
+        SELECT strftime('%H', start_time) AS h, CAST(COUNT(*) AS INTEGER) AS n
+        FROM training_sessions
+        WHERE strftime('%Y', start_time) = ?
+        GROUP BY h
+        ORDER BY n DESC, h
+        LIMIT 1
+        "#,
+    )
+    .bind(&y)
+    .fetch_optional(pool)
+    .await?;
+
+    Ok(Wrapped {
+        year,
+        sessions,
+        sets,
+        reps,
+        tonnage_kg: tonnage,
+        tonnage_comparison: tonnage_comparison(tonnage),
+        most_trained,
+        biggest_pr_jump,
+        longest_streak_weeks,
+        busiest_month,
+        favorite_hour: favorite_hour.and_then(|(h, n)| Some((h.parse().ok()?, n))),
+    })
+}
+
+fn print_pretty(w: &Wrapped) {
+    println!("\n{}", format!("Your {} in lifting", w.year).bold().heading());
+    println!("{}", "─".repeat(30).dimmed());
+
+    println!(
+        "{} {}",
+        tr("Showed up:").heading().bold(),
+        tf("{} sessions, {} sets, {} reps", &[&w.sessions, &w.sets, &w.reps])
+    );
+    println!(
+        "{} {:.1} t — {}",
+        tr("Moved:").heading().bold(),
+        w.tonnage_kg / 1000.0,
+        w.tonnage_comparison
+    );
+    if let Some((name, sets)) = &w.most_trained {
+        println!("{} {}", tr("Favorite lift:").heading().bold(), tf("{} ({} sets)", &[&name.bold(), &sets]));
+    }
+    if let Some(j) = &w.biggest_pr_jump {
+        println!(
+            "{} {} {} → {} kg e1RM ({})",
+            tr("Biggest PR jump:").heading().bold(),
+            j.exercise.bold(),
+            kg_places(j.from_kg, 1),
+            kg_places(j.to_kg, 1),
+            format!("+{} kg", kg_places(j.to_kg - j.from_kg, 1)).good()
+        );
+    }
+    println!(
+        "{} {}",
+        tr("Longest streak:").heading().bold(),
+        tf("{} weeks in a row", &[&w.longest_streak_weeks])
+    );
+    if let Some((month, n)) = &w.busiest_month {
+        println!("{} {}", tr("Busiest month:").heading().bold(), tf("{} ({} sessions)", &[&month, &n]));
+    }
+    if let Some((hour, n)) = w.favorite_hour {
+        println!(
+            "{} {:02}:00 ({} sessions started then)",
+            tr("Favorite hour:").heading().bold(),
+            hour,
+            n
+        );
+    }
+    println!();
+}
+
+fn print_markdown(w: &Wrapped) {
+    println!("# {} in lifting\n", w.year);
+    println!("- **Sessions:** {} ({} sets, {} reps)", w.sessions, w.sets, w.reps);
+    println!(
+        "- **Tonnage:** {:.1} t — {}",
+        w.tonnage_kg / 1000.0,
+        w.tonnage_comparison
+    );
+    if let Some((name, sets)) = &w.most_trained {
+        println!("- **Favorite lift:** {} ({} sets)", name, sets);
+    }
+    if let Some(j) = &w.biggest_pr_jump {
+        println!(
+            "- **Biggest PR jump:** {} {} → {} kg e1RM (+{} kg)",
+            j.exercise,
+            kg_places(j.from_kg, 1),
+            kg_places(j.to_kg, 1),
+            kg_places(j.to_kg - j.from_kg, 1)
+        );
+    }
+    println!("- **Longest streak:** {} weeks", w.longest_streak_weeks);
+    if let Some((month, n)) = &w.busiest_month {
+        println!("- **Busiest month:** {} ({} sessions)", month, n);
+    }
+    if let Some((hour, n)) = w.favorite_hour {
+        println!("- **Favorite hour:** {:02}:00 ({} sessions)", hour, n);
+    }
+}
+
+pub async fn handle(
+    pool: &SqlitePool,
+    year: Option<i32>,
+    markdown: bool,
+    fmt: OutputFmt,
+) -> Result<()> {
+    let year = year.unwrap_or_else(|| chrono::Local::now().year());
+    let w = collect(pool, year).await?;
+
+    if w.sessions == 0 && !fmt.json {
+        ui::info(tf("no sessions in {}", &[&year]));
+        return Ok(());
+    }
+
+    emit(fmt, &w, || {
+        if markdown {
+            print_markdown(&w);
+        } else {
+            print_pretty(&w);
+        }
+    });
+
+    Ok(())
+}
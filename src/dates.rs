@@ -0,0 +1,124 @@
+//! Dates and durations typed on the command line. Every date flag goes
+//! through [`parse_date`], so they all take the same formats and phrases.
+
+use chrono::{Datelike, Days, Months, NaiveDate, Weekday};
+
+/// What a date flag accepts, for error messages.
+pub const DATE_FORMATS: &str = "YYYY-MM-DD, DD-MM-YYYY, DD/MM/YYYY, DD/MM/YY, DD/MM, or e.g. \"yesterday\", \"last friday\", \"3 days ago\"";
+
+fn weekday(s: &str) -> Option<Weekday> {
+    let pt = [
+        ("segunda", Weekday::Mon),
+        ("terça", Weekday::Tue),
+        ("terca", Weekday::Tue),
+        ("quarta", Weekday::Wed),
+        ("quinta", Weekday::Thu),
+        ("sexta", Weekday::Fri),
+        ("sábado", Weekday::Sat),
+        ("sabado", Weekday::Sat),
+        ("domingo", Weekday::Sun),
+    ];
+    let s = s.trim_end_matches("-feira");
+    pt.iter().find(|(name, _)| *name == s).map(|(_, d)| *d).or_else(|| s.parse().ok())
+}
+
+/// `n` units (days, weeks, months, years) as a signed offset from `today`.
+fn shift(today: NaiveDate, n: u32, unit: &str, back: bool) -> Option<NaiveDate> {
+    match unit {
+        "day" | "days" | "dia" | "dias" | "d" => {
+            if back { today.checked_sub_days(Days::new(n as u64)) } else { today.checked_add_days(Days::new(n as u64)) }
+        }
+        "week" | "weeks" | "semana" | "semanas" | "w" => {
+            let days = Days::new(n as u64 * 7);
+            if back { today.checked_sub_days(days) } else { today.checked_add_days(days) }
+        }
+        "month" | "months" | "mês" | "mes" | "meses" | "m" => {
+            if back { today.checked_sub_months(Months::new(n)) } else { today.checked_add_months(Months::new(n)) }
+        }
+        "year" | "years" | "ano" | "anos" | "y" => {
+            let months = Months::new(n * 12);
+            if back { today.checked_sub_months(months) } else { today.checked_add_months(months) }
+        }
+        _ => None,
+    }
+}
+
+/// A phrase relative to `today`: "today", "yesterday", "3 days ago",
+/// "2 weeks ago", "in 4 weeks", "friday"/"last friday" (the latest one before
+/// today), "next friday", and the Portuguese "hoje", "ontem", "há 3 dias",
+/// "sexta passada".
+fn parse_phrase(s: &str, today: NaiveDate) -> Option<NaiveDate> {
+    let s = s.to_lowercase();
+    let words: Vec<&str> = s.split_whitespace().collect();
+    match words[..] {
+        ["today"] | ["hoje"] => Some(today),
+        ["yesterday"] | ["ontem"] => today.pred_opt(),
+        ["anteontem"] => today.pred_opt()?.pred_opt(),
+        ["tomorrow"] | ["amanhã"] | ["amanha"] => today.succ_opt(),
+        [n, unit, "ago"] | ["há" | "ha", n, unit] => shift(today, n.parse().ok()?, unit, true),
+        ["in" | "em", n, unit] => shift(today, n.parse().ok()?, unit, false),
+        ["last" | "past", "week"] | ["semana", "passada"] => shift(today, 1, "week", true),
+        ["last", "month"] | ["mês" | "mes", "passado"] => shift(today, 1, "month", true),
+        ["next", day] | [day, "que", "vem"] => {
+            let d = weekday(day)?;
+            let ahead = (d.num_days_from_monday() + 7 - today.weekday().num_days_from_monday()) % 7;
+            today.checked_add_days(Days::new(if ahead == 0 { 7 } else { ahead } as u64))
+        }
+        ["last" | "past", day] | [day, "passada" | "passado"] | [day] => {
+            let d = weekday(day)?;
+            let back = (today.weekday().num_days_from_monday() + 7 - d.num_days_from_monday()) % 7;
+            today.checked_sub_days(Days::new(if back == 0 { 7 } else { back } as u64))
+        }
+        _ => None,
+    }
+}
+
+/// A date in any of [`DATE_FORMATS`], relative phrases counted from `today`.
+/// Two-part dates are day-first (Brazilian order) and fall in `today`'s year.
+fn parse_day_from(s: &str, today: NaiveDate) -> Option<NaiveDate> {
+    let s = s.trim();
+    let parts: Vec<&str> = s.split(['/', '-', '.']).collect();
+    if parts.len() < 2 || !parts.iter().all(|p| !p.is_empty() && p.chars().all(|c| c.is_ascii_digit())) {
+        return parse_phrase(s, today);
+    }
+
+    // The year is whichever end has four digits; two-digit years are 20xx.
+    let num = |p: &str| p.parse::<u32>().ok();
+    let (y, m, d) = match parts[..] {
+        [y, m, d] if y.len() == 4 => (y.parse().ok()?, num(m)?, num(d)?),
+        [d, m, y] if y.len() == 4 => (y.parse().ok()?, num(m)?, num(d)?),
+        [d, m, y] if y.len() == 2 => (2000 + y.parse::<i32>().ok()?, num(m)?, num(d)?),
+        [d, m] => (today.year(), num(m)?, num(d)?),
+        _ => return None,
+    };
+    NaiveDate::from_ymd_opt(y, m, d)
+}
+
+/// A date flag; today when omitted.
+pub fn parse_date(date: Option<&str>) -> Option<NaiveDate> {
+    let today = chrono::Local::now().date_naive();
+    match date {
+        None => Some(today),
+        Some(d) => parse_day_from(d, today),
+    }
+}
+
+/// A `--weeks` flag: a number of weeks, or a span like `12w`, `30d`, `3m` or
+/// `1y`, rounded up to whole weeks.
+pub fn parse_weeks(s: &str) -> Result<u32, String> {
+    let s = s.trim().to_lowercase();
+    let split = s.find(|c: char| !c.is_ascii_digit()).unwrap_or(s.len());
+    let (n, unit) = s.split_at(split);
+    let n: u32 = n.parse().map_err(|_| format!("invalid duration `{}` (e.g. 12, 12w, 30d, 3m, 1y)", s))?;
+    let weeks = match unit.trim() {
+        "" | "w" | "wk" | "week" | "weeks" => n,
+        "d" | "day" | "days" => n.div_ceil(7),
+        "m" | "mo" | "month" | "months" => (n * 52).div_ceil(12),
+        "y" | "yr" | "year" | "years" => n * 52,
+        _ => return Err(format!("invalid duration `{}` (e.g. 12, 12w, 30d, 3m, 1y)", s)),
+    };
+    if weeks == 0 {
+        return Err("duration must be at least a week".to_string());
+    }
+    Ok(weeks)
+}
@@ -1,21 +1,68 @@
-use std::str::FromStr;
+use std::{str::FromStr, time::Duration};
 
-use anyhow::Result;
+use anyhow::{Context, Result};
 use sqlx::{
     SqlitePool,
-    sqlite::{SqliteConnectOptions, SqlitePoolOptions},
+    sqlite::{SqliteConnectOptions, SqliteJournalMode, SqlitePoolOptions},
 };
 
 pub type DB = SqlitePool;
 
-pub async fn open(path: &str) -> Result<DB> {
-    let opts = SqliteConnectOptions::from_str(path)?.create_if_missing(true).foreign_keys(true).to_owned();
+/// How long a statement waits on a locked database before giving up.
+const BUSY_TIMEOUT: Duration = Duration::from_secs(5);
 
-    let pool = SqlitePoolOptions::new()
-        .max_connections(5)
+/// How long we wait for a free pooled connection.
+const ACQUIRE_TIMEOUT: Duration = Duration::from_secs(10);
+
+/// Where the data lives, picked with `database = <path> | :memory:` in config.
+#[derive(Debug, Clone, PartialEq)]
+pub enum Backend {
+    /// A SQLite file, created on first use.
+    File(String),
+    /// A throwaway in-memory database, gone when the command exits.
+    Memory,
+}
+
+impl Backend {
+    pub fn parse(s: &str) -> Result<Self> {
+        match s.trim() {
+            "" => anyhow::bail!("database path must not be empty"),
+            ":memory:" | "memory" => Ok(Backend::Memory),
+            s if s.starts_with("libsql:") || s.starts_with("http://") || s.starts_with("https://") => {
+                anyhow::bail!("remote databases (`{}`) are not supported yet, use a file path or `:memory:`", s)
+            }
+            s => Ok(Backend::File(s.strip_prefix("sqlite://").unwrap_or(s).to_string())),
+        }
+    }
+}
+
+pub async fn open(backend: &Backend) -> Result<DB> {
+    let (opts, max_connections) = match backend {
+        Backend::File(path) => (
+            SqliteConnectOptions::from_str(path)
+                .with_context(|| format!("invalid database path `{}`", path))?
+                .create_if_missing(true)
+                .journal_mode(SqliteJournalMode::Wal),
+            5,
+        ),
+        // Every connection would get its own empty database, so keep just one.
+        Backend::Memory => (SqliteConnectOptions::from_str(":memory:")?, 1),
+    };
+    let opts = opts.foreign_keys(true).busy_timeout(BUSY_TIMEOUT);
+
+    let mut pool_opts = SqlitePoolOptions::new()
+        .max_connections(max_connections)
+        .acquire_timeout(ACQUIRE_TIMEOUT);
+    if *backend == Backend::Memory {
+        // Closing the only connection would drop the data with it.
+        pool_opts = pool_opts.idle_timeout(None).max_lifetime(None);
+    }
+
+    let pool = pool_opts
         .connect_with(opts)
-        .await?;
+        .await
+        .with_context(|| format!("could not open database {:?}", backend))?;
 
-    sqlx::migrate!().run(&pool).await?;
+    sqlx::migrate!().run(&pool).await.context("failed to run migrations")?;
     Ok(pool)
 }
@@ -0,0 +1,45 @@
+use std::fmt;
+
+use crate::i18n::{tf, tr};
+
+/// Failures a script may want to tell apart from a real error. Each kind has
+/// its own exit code; anything else exits with 1.
+#[derive(Debug)]
+pub enum AppError {
+    ProgramNotFound(String),
+    ExerciseNotFound(String),
+    /// Anything else that doesn't exist, with the message to show.
+    NotFound(String),
+    /// Bad input, with the message to show.
+    Invalid(String),
+    NoActiveSession,
+}
+
+impl AppError {
+    pub fn exit_code(&self) -> i32 {
+        match self {
+            AppError::ProgramNotFound(_) | AppError::ExerciseNotFound(_) | AppError::NotFound(_) => 2,
+            AppError::Invalid(_) => 3,
+            AppError::NoActiveSession => 4,
+        }
+    }
+}
+
+impl fmt::Display for AppError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            AppError::ProgramNotFound(p) if p.parse::<i64>().is_ok() => {
+                write!(f, "{}", tf("no program at index {} (see `program list`)", &[p]))
+            }
+            AppError::ProgramNotFound(p) => write!(f, "{}", tf("no program named `{}` (see `program list`)", &[p])),
+            AppError::ExerciseNotFound(e) if e.parse::<i64>().is_ok() => {
+                write!(f, "{}", tf("no exercise at index {} (see `exercise list`)", &[e]))
+            }
+            AppError::ExerciseNotFound(e) => write!(f, "{}", tf("no exercise named `{}` (see `exercise list`)", &[e])),
+            AppError::NotFound(msg) | AppError::Invalid(msg) => write!(f, "{}", msg),
+            AppError::NoActiveSession => write!(f, "{}", tr("no active session (start one with `session start`)")),
+        }
+    }
+}
+
+impl std::error::Error for AppError {}
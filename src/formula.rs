@@ -0,0 +1,258 @@
+//! The e1RM formula. Epley unless `e1rm_formula` in the config holds an
+//! expression over `weight` and `reps`, e.g. `weight * (1 + reps / 28)`.
+//! The same expression is evaluated in Rust and rendered into SQL, so every
+//! estimate in the app agrees.
+
+use std::sync::OnceLock;
+
+use colored::Colorize;
+
+use crate::{
+    i18n::{tf, tr},
+    types::Config,
+    ui::Themed,
+};
+
+pub const EPLEY: &str = "weight * (1 + reps / 30)";
+
+static FORMULA: OnceLock<Expr> = OnceLock::new();
+
+#[derive(Debug, Clone)]
+pub enum Expr {
+    Num(f64),
+    Weight,
+    Reps,
+    Neg(Box<Expr>),
+    Bin(char, Box<Expr>, Box<Expr>),
+}
+
+impl Expr {
+    /// The value for one set; `None` when it divides by zero, where SQLite
+    /// gives NULL.
+    pub fn eval(&self, weight: f64, reps: f64) -> Option<f64> {
+        Some(match self {
+            Expr::Num(n) => *n,
+            Expr::Weight => weight,
+            Expr::Reps => reps,
+            Expr::Neg(e) => -e.eval(weight, reps)?,
+            Expr::Bin(op, a, b) => {
+                let (a, b) = (a.eval(weight, reps)?, b.eval(weight, reps)?);
+                match op {
+                    '+' => a + b,
+                    '-' => a - b,
+                    '*' => a * b,
+                    _ if b == 0.0 => return None,
+                    _ => a / b,
+                }
+            }
+        })
+    }
+
+    /// SQL for the expression over two column expressions. Columns are cast
+    /// to REAL so `reps / 30` never turns into integer division.
+    pub fn sql(&self, weight: &str, reps: &str) -> String {
+        match self {
+            Expr::Num(n) => format!("{:?}", n),
+            Expr::Weight => format!("CAST({} AS REAL)", weight),
+            Expr::Reps => format!("CAST({} AS REAL)", reps),
+            Expr::Neg(e) => format!("(-{})", e.sql(weight, reps)),
+            Expr::Bin(op, a, b) => format!("({} {} {})", a.sql(weight, reps), op, b.sql(weight, reps)),
+        }
+    }
+}
+
+/// Recursive descent over `+ - * /`, parentheses, numbers and the variables
+/// `weight` (`w`) and `reps` (`r`).
+pub fn parse(src: &str) -> Result<Expr, String> {
+    let tokens = tokenize(src)?;
+    let mut pos = 0;
+    let expr = sum(&tokens, &mut pos)?;
+    match tokens.get(pos) {
+        None => Ok(expr),
+        Some(t) => Err(format!("unexpected `{}`", t)),
+    }
+}
+
+#[derive(Debug, PartialEq)]
+enum Token {
+    Num(f64),
+    Var(String),
+    Op(char),
+}
+
+impl std::fmt::Display for Token {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            Token::Num(n) => write!(f, "{}", n),
+            Token::Var(v) => write!(f, "{}", v),
+            Token::Op(c) => write!(f, "{}", c),
+        }
+    }
+}
+
+fn tokenize(src: &str) -> Result<Vec<Token>, String> {
+    let mut out = Vec::new();
+    let mut chars = src.chars().peekable();
+    while let Some(&c) = chars.peek() {
+        if c.is_whitespace() {
+            chars.next();
+        } else if c.is_ascii_digit() || c == '.' {
+            let mut s = String::new();
+            while let Some(&d) = chars.peek().filter(|d| d.is_ascii_digit() || **d == '.') {
+                s.push(d);
+                chars.next();
+            }
+            out.push(Token::Num(s.parse().map_err(|_| format!("bad number `{}`", s))?));
+        } else if c.is_ascii_alphabetic() {
+            let mut s = String::new();
+            while let Some(&d) = chars.peek().filter(|d| d.is_ascii_alphanumeric() || **d == '_') {
+                s.push(d);
+                chars.next();
+            }
+            out.push(Token::Var(s.to_lowercase()));
+        } else if "+-*/()".contains(c) {
+            out.push(Token::Op(c));
+            chars.next();
+        } else {
+            return Err(format!("unexpected `{}`", c));
+        }
+    }
+    Ok(out)
+}
+
+fn sum(tokens: &[Token], pos: &mut usize) -> Result<Expr, String> {
+    let mut left = product(tokens, pos)?;
+    while let Some(Token::Op(op @ ('+' | '-'))) = tokens.get(*pos) {
+        *pos += 1;
+        left = Expr::Bin(*op, Box::new(left), Box::new(product(tokens, pos)?));
+    }
+    Ok(left)
+}
+
+fn product(tokens: &[Token], pos: &mut usize) -> Result<Expr, String> {
+    let mut left = unary(tokens, pos)?;
+    while let Some(Token::Op(op @ ('*' | '/'))) = tokens.get(*pos) {
+        *pos += 1;
+        left = Expr::Bin(*op, Box::new(left), Box::new(unary(tokens, pos)?));
+    }
+    Ok(left)
+}
+
+fn unary(tokens: &[Token], pos: &mut usize) -> Result<Expr, String> {
+    let token = tokens.get(*pos).ok_or("unexpected end of formula")?;
+    *pos += 1;
+    match token {
+        Token::Num(n) => Ok(Expr::Num(*n)),
+        Token::Var(v) => match v.as_str() {
+            "weight" | "w" => Ok(Expr::Weight),
+            "reps" | "r" => Ok(Expr::Reps),
+            _ => Err(format!("unknown variable `{}` (use weight and reps)", v)),
+        },
+        Token::Op('-') => Ok(Expr::Neg(Box::new(unary(tokens, pos)?))),
+        Token::Op('(') => {
+            let inner = sum(tokens, pos)?;
+            match tokens.get(*pos) {
+                Some(Token::Op(')')) => {
+                    *pos += 1;
+                    Ok(inner)
+                }
+                _ => Err("missing `)`".into()),
+            }
+        }
+        Token::Op(c) => Err(format!("unexpected `{}`", c)),
+    }
+}
+
+fn formula() -> &'static Expr {
+    FORMULA.get_or_init(|| parse(EPLEY).expect("Epley parses"))
+}
+
+/// `e1rm_formula = <expression>` (defaults to Epley). A formula that doesn't
+/// parse is reported and Epley is used, so `config` can still fix it.
+pub fn init(cfg: &Config) {
+    let Some(src) = cfg.map.get("e1rm_formula") else { return };
+    match parse(src) {
+        Ok(expr) => {
+            let _ = FORMULA.set(expr);
+        }
+        Err(e) => eprintln!(
+            "{} {}",
+            tr("warning:").accent().bold(),
+            tf("e1rm_formula `{}`: {} — using Epley", &[&src, &e])
+        ),
+    }
+}
+
+fn estimate(expr: &Expr, weight: f64, reps: f64) -> f64 {
+    if reps <= 0.0 { 0.0 } else { expr.eval(weight, reps).unwrap_or(0.0) }
+}
+
+fn estimate_sql(expr: &Expr, weight: &str, reps: &str) -> String {
+    format!("(CASE WHEN {} <= 0 THEN 0.0 ELSE COALESCE({}, 0.0) END)", reps, expr.sql(weight, reps))
+}
+
+/// Estimated 1RM of a set; zero reps, or a formula dividing by zero,
+/// estimate nothing.
+pub fn e1rm(weight: f64, reps: f64) -> f64 {
+    estimate(formula(), weight, reps)
+}
+
+/// The estimate as SQL over the given weight and reps columns, giving what
+/// `e1rm` gives for every set.
+pub fn e1rm_sql(weight: &str, reps: &str) -> String {
+    estimate_sql(formula(), weight, reps)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn epley() {
+        assert_eq!(e1rm(100.0, 0.0), 0.0);
+        assert!((e1rm(100.0, 1.0) - 103.333).abs() < 0.001);
+        assert!((e1rm(100.0, 5.0) - 116.667).abs() < 0.001);
+    }
+
+    #[test]
+    fn parses_precedence_and_aliases() {
+        let expr = parse("w * (1 + r / 30)").unwrap();
+        assert_eq!(expr.eval(100.0, 30.0), Some(200.0));
+        assert_eq!(parse("-w + 2 * r").unwrap().eval(10.0, 3.0), Some(-4.0));
+        assert_eq!(parse("w / (r - 5)").unwrap().eval(100.0, 5.0), None);
+    }
+
+    #[test]
+    fn rejects_bad_input() {
+        assert!(parse("weight *").is_err());
+        assert!(parse("(weight").is_err());
+        assert!(parse("weight reps").is_err());
+        assert!(parse("bar").is_err());
+    }
+
+    #[test]
+    fn sql_casts_columns() {
+        let sql = parse(EPLEY).unwrap().sql("es.weight", "es.reps");
+        assert_eq!(sql, "(CAST(es.weight AS REAL) * (1.0 + (CAST(es.reps AS REAL) / 30.0)))");
+    }
+
+    #[test]
+    fn sql_guards_zero_reps() {
+        let sql = e1rm_sql("es.weight", "es.reps");
+        assert!(sql.starts_with("(CASE WHEN es.reps <= 0 THEN 0.0 ELSE "), "{}", sql);
+    }
+
+    #[tokio::test]
+    async fn rust_and_sql_agree() {
+        let pool = crate::testutil::memory_db().await;
+        for src in [EPLEY, "weight / (reps - 5)", "weight * 36 / (37 - reps)"] {
+            let expr = parse(src).unwrap();
+            let sql = format!("SELECT {} FROM (SELECT ? AS w, ? AS r)", estimate_sql(&expr, "w", "r"));
+            for (weight, reps) in [(100.0, 0), (100.0, 1), (100.0, 5), (82.5, 8), (60.0, 37)] {
+                let in_sql: f64 = sqlx::query_scalar(&sql).bind(weight).bind(reps).fetch_one(&pool).await.unwrap();
+                let in_rust = estimate(&expr, weight, reps as f64);
+                assert!((in_sql - in_rust).abs() < 1e-9, "{} at {}×{}: SQL {} vs Rust {}", src, weight, reps, in_sql, in_rust);
+            }
+        }
+    }
+}
@@ -0,0 +1,870 @@
+//! Translated output. Strings are looked up by their English text, so
+//! anything without a translation simply stays in English.
+
+use std::{fmt::Display, sync::OnceLock};
+
+use chrono::{Datelike, NaiveDate, Weekday};
+
+use crate::types::Config;
+
+#[derive(Clone, Copy, PartialEq)]
+pub enum Locale {
+    En,
+    PtBr,
+}
+
+static LOCALE: OnceLock<Locale> = OnceLock::new();
+static RELATIVE_DATES: OnceLock<bool> = OnceLock::new();
+static NUMBERS: OnceLock<Numbers> = OnceLock::new();
+
+/// How numbers are written: decimal separator and places for weights.
+#[derive(Clone, Copy)]
+struct Numbers {
+    comma: bool,
+    /// Places for kg; `None` keeps up to two and drops trailing zeros.
+    kg_places: Option<usize>,
+    lb_places: usize,
+}
+
+fn numbers() -> Numbers {
+    *NUMBERS.get_or_init(|| Numbers { comma: false, kg_places: None, lb_places: 0 })
+}
+
+fn locale() -> Locale {
+    *LOCALE.get_or_init(|| Locale::En)
+}
+
+/// `locale = en | pt-BR` (defaults to en), `dates = absolute | relative`
+/// (defaults to absolute), `decimal_separator = . | ,` (defaults to the
+/// locale's), `weight_decimals = <n>` and `lb_decimals = <n>` (defaults to 0).
+pub fn init(cfg: &Config) {
+    let locale = match cfg.map.get("locale").map(|v| v.to_ascii_lowercase().replace('_', "-")) {
+        Some(l) if l == "pt-br" || l == "pt" => Locale::PtBr,
+        _ => Locale::En,
+    };
+    let _ = LOCALE.set(locale);
+    let _ = RELATIVE_DATES.set(cfg.map.get("dates").is_some_and(|v| v == "relative"));
+
+    let comma = match cfg.map.get("decimal_separator").map(String::as_str) {
+        Some(sep) => parse_separator(sep).unwrap_or(false),
+        None => locale == Locale::PtBr,
+    };
+    let places = |key: &str| cfg.map.get(key).and_then(|v| parse_places(v));
+    let _ = NUMBERS.set(Numbers { comma, kg_places: places("weight_decimals"), lb_places: places("lb_decimals").unwrap_or(0) });
+}
+
+/// `decimal_separator`: whether it asks for a comma. `None` when invalid.
+pub fn parse_separator(s: &str) -> Option<bool> {
+    match s.trim() {
+        "," | "comma" => Some(true),
+        "." | "dot" | "point" => Some(false),
+        _ => None,
+    }
+}
+
+/// `weight_decimals`/`lb_decimals`: 0 to 3 places.
+pub fn parse_places(s: &str) -> Option<usize> {
+    s.trim().parse().ok().filter(|&n| n <= 3)
+}
+
+fn with_separator(s: String) -> String {
+    if numbers().comma { s.replace('.', ",") } else { s }
+}
+
+/// A number with `places` decimals and the configured separator.
+pub fn number(x: f64, places: usize) -> String {
+    with_separator(format!("{:.*}", places, x))
+}
+
+/// A weight in kg, without the unit: `weight_decimals` places, or up to two
+/// with trailing zeros dropped ("82.5", "100").
+pub fn kg(x: impl Into<f64>) -> String {
+    let x = x.into();
+    match numbers().kg_places {
+        Some(places) => number(x, places),
+        None => with_separator(format!("{}", (x * 100.0).round() / 100.0)),
+    }
+}
+
+/// [`kg`] where the output used to show a fixed number of places, e.g. an
+/// e1RM to one decimal; `weight_decimals` still wins when set.
+pub fn kg_places(x: impl Into<f64>, places: usize) -> String {
+    number(x.into(), numbers().kg_places.unwrap_or(places))
+}
+
+/// A weight in lb, without the unit, with `lb_decimals` places.
+pub fn lb(x: impl Into<f64>) -> String {
+    number(x.into(), numbers().lb_places)
+}
+
+/// BCP 47 tag of the current locale, for HTML output.
+pub fn language_tag() -> &'static str {
+    match locale() {
+        Locale::En => "en",
+        Locale::PtBr => "pt-BR",
+    }
+}
+
+/// Translate a fixed string.
+pub fn tr(en: &'static str) -> &'static str {
+    let table = match locale() {
+        Locale::En => return en,
+        Locale::PtBr => PT_BR,
+    };
+    table.iter().find(|(k, _)| *k == en).map(|(_, v)| *v).unwrap_or(en)
+}
+
+/// Translate a template and fill its `{}` placeholders in order.
+pub fn tf(en: &'static str, args: &[&dyn Display]) -> String {
+    let mut out = String::new();
+    let mut args = args.iter();
+    let mut parts = tr(en).split("{}").peekable();
+    while let Some(part) = parts.next() {
+        out.push_str(part);
+        if parts.peek().is_some() {
+            if let Some(arg) = args.next() {
+                out.push_str(&arg.to_string());
+            }
+        }
+    }
+    out
+}
+
+const MONTHS_EN: [&str; 12] = [
+    "January", "February", "March", "April", "May", "June",
+    "July", "August", "September", "October", "November", "December",
+];
+
+const MONTHS_PT: [&str; 12] = [
+    "janeiro", "fevereiro", "março", "abril", "maio", "junho",
+    "julho", "agosto", "setembro", "outubro", "novembro", "dezembro",
+];
+
+/// Full month name, `month` in 1..=12.
+pub fn month_name(month: u32) -> &'static str {
+    let i = (month.clamp(1, 12) - 1) as usize;
+    match locale() {
+        Locale::En => MONTHS_EN[i],
+        Locale::PtBr => MONTHS_PT[i],
+    }
+}
+
+pub fn weekday_name(day: Weekday) -> &'static str {
+    let i = day.num_days_from_monday() as usize;
+    match locale() {
+        Locale::En => ["Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"][i],
+        Locale::PtBr => [
+            "segunda-feira",
+            "terça-feira",
+            "quarta-feira",
+            "quinta-feira",
+            "sexta-feira",
+            "sábado",
+            "domingo",
+        ][i],
+    }
+}
+
+/// Two-letter weekday header for calendars, starting on Sunday.
+pub fn weekday_header() -> &'static str {
+    match locale() {
+        Locale::En => "Su Mo Tu We Th Fr Sa",
+        Locale::PtBr => "Do Se Te Qu Qu Se Sá",
+    }
+}
+
+/// "April 2025" / "abril de 2025".
+pub fn month_year(date: NaiveDate) -> String {
+    match locale() {
+        Locale::En => format!("{} {}", month_name(date.month()), date.year()),
+        Locale::PtBr => format!("{} de {}", month_name(date.month()), date.year()),
+    }
+}
+
+/// "Monday, April 7" / "segunda-feira, 7 de abril".
+pub fn long_date(date: NaiveDate) -> String {
+    let weekday = weekday_name(date.weekday());
+    match locale() {
+        Locale::En => format!("{}, {} {}", weekday, month_name(date.month()), date.day()),
+        Locale::PtBr => format!("{}, {} de {}", weekday, date.day(), month_name(date.month())),
+    }
+}
+
+/// Numeric date: 2025-04-07 / 07/04/2025.
+pub fn short_date(date: NaiveDate) -> String {
+    match locale() {
+        Locale::En => date.format("%Y-%m-%d").to_string(),
+        Locale::PtBr => date.format("%d/%m/%Y").to_string(),
+    }
+}
+
+/// "today", "yesterday", "3 days ago", "last Tuesday", "5 weeks ago"...
+pub fn relative_date(date: NaiveDate) -> String {
+    let days = (chrono::Local::now().date_naive() - date).num_days();
+    match days {
+        ..-1 => tf("in {} days", &[&-days]),
+        -1 => tr("tomorrow").to_string(),
+        0 => tr("today").to_string(),
+        1 => tr("yesterday").to_string(),
+        2..7 => tf("{} days ago", &[&days]),
+        7..14 => {
+            let weekday = weekday_name(date.weekday());
+            match (locale(), date.weekday()) {
+                (Locale::En, _) => format!("last {}", weekday),
+                (Locale::PtBr, Weekday::Sat | Weekday::Sun) => format!("{} passado", weekday),
+                (Locale::PtBr, _) => format!("{} passada", weekday),
+            }
+        }
+        14..60 => tf("{} weeks ago", &[&(days / 7)]),
+        60..730 => tf("{} months ago", &[&(days / 30)]),
+        _ => tf("{} years ago", &[&(days / 365)]),
+    }
+}
+
+/// A date the way `dates` asks for: [`relative_date`], or [`short_date`] by default.
+pub fn display_date(date: NaiveDate) -> String {
+    if *RELATIVE_DATES.get_or_init(|| false) {
+        relative_date(date)
+    } else {
+        short_date(date)
+    }
+}
+
+/// [`display_date`] for a "YYYY-MM-DD..." timestamp from the database; kept
+/// as is when it doesn't start with a date.
+pub fn display_db_date(timestamp: &str) -> String {
+    timestamp
+        .get(..10)
+        .and_then(|d| NaiveDate::parse_from_str(d, "%Y-%m-%d").ok())
+        .map(display_date)
+        .unwrap_or_else(|| timestamp.to_string())
+}
+
+const PT_BR: &[(&str, &str)] = &[
+    ("error:", "erro:"),
+    ("ok:", "ok:"),
+    ("warning:", "aviso:"),
+    ("warning", "aviso"),
+    ("info:", "info:"),
+    ("note:", "nota:"),
+    ("fixed:", "corrigido:"),
+    ("swap:", "troca:"),
+    ("NOTE:", "NOTA:"),
+    ("Note:", "Nota:"),
+    ("EQUIP:", "EQUIP:"),
+    ("new set", "série nova"),
+    ("skipped", "pulada"),
+    ("   ← PR", "   ← PR"),
+    ("warning: no [[exercise]] entries found", "aviso: nenhuma entrada [[exercise]] encontrada"),
+    ("30-day 1 RM change:", "Variação de 1RM em 30 dias:"),
+    ("30-day tonnage", "Tonelagem em 30 dias"),
+    ("AMRAP progression", "Progressão AMRAP"),
+    ("Active exercises", "Exercícios ativos"),
+    ("Active sessions:", "Sessões ativas:"),
+    ("Recovery:", "Recuperação:"),
+    ("Goals:", "Metas:"),
+    ("In progress:", "Em andamento:"),
+    ("Next up:", "Próximo:"),
+    ("Last session:", "Última sessão:"),
+    ("Streak:", "Sequência:"),
+    ("nothing left this week", "nada pendente nesta semana"),
+    ("none yet", "nenhuma ainda"),
+    ("Block:", "Bloco:"),
+    ("Sessions:", "Sessões:"),
+    ("Avg duration:", "Duração média:"),
+    ("Avg tonnage:", "Tonelagem média:"),
+    ("Progression:", "Progressão:"),
+    ("Most missed:", "Mais falhado:"),
+    ("  (no finished sessions of this block yet)", "  (nenhuma sessão concluída deste bloco ainda)"),
+    ("  (no open goals)", "  (nenhuma meta em aberto)"),
+    ("reached", "alcançada"),
+    ("overdue", "atrasada"),
+    ("on track", "no ritmo"),
+    ("behind", "atrás do ritmo"),
+    ("Nutrition:", "Nutrição:"),
+    ("Yesterday:", "Ontem:"),
+    ("training days", "dias de treino"),
+    ("rest days", "dias de descanso"),
+    ("missed", "perdidas"),
+    ("sessions", "sessões"),
+    ("sleep", "sono"),
+    ("week", "semana"),
+    ("Allowed muscles:", "Músculos permitidos:"),
+    ("Avg frequency (8 w)", "Frequência média (8 sem)"),
+    ("Avg frequency", "Frequência média"),
+    ("Avg tonnage/session", "Tonelagem média/sessão"),
+    ("Biggest PR jump:", "Maior salto de PR:"),
+    ("Blocks:", "Blocos:"),
+    ("Busiest month:", "Mês mais movimentado:"),
+    ("Compared to:", "Comparado com:"),
+    ("Config:", "Configuração:"),
+    ("Current PR", "PR atual"),
+    ("Exercise", "Exercício"),
+    ("Exercises:", "Exercícios:"),
+    ("Favorite hour:", "Horário favorito:"),
+    ("Favorite lift:", "Exercício favorito:"),
+    ("Global Training Status", "Status geral do treino"),
+    ("Gym:", "Academia:"),
+    ("Gyms:", "Academias:"),
+    ("Last 10 sets", "Últimas 10 séries"),
+    ("Left/right balance (8 w)", "Equilíbrio esquerda/direita (8 sem)"),
+    ("Lifetime volume", "Volume total"),
+    ("Longest gap", "Maior intervalo"),
+    ("Longest streak:", "Maior sequência:"),
+    ("Matches:", "Resultados:"),
+    ("Merge report:", "Relatório da mesclagem:"),
+    ("Moved:", "Movido:"),
+    ("Muscle Group Progress:", "Progresso por grupo muscular:"),
+    ("PR Progression", "Progressão de PR"),
+    ("Pace:", "Ritmo:"),
+    ("Periodization:", "Periodização:"),
+    ("Program weeks:", "Semanas dos programas:"),
+    ("Program:", "Programa:"),
+    ("Programs:", "Programas:"),
+    ("Session:", "Sessão:"),
+    ("Showed up:", "Compareceu:"),
+    ("Strength progression:", "Progressão de força:"),
+    ("Summary:", "Resumo:"),
+    ("Time:", "Tempo:"),
+    ("Top 5 heaviest sets", "As 5 séries mais pesadas"),
+    ("Top exercises by tonnage:", "Exercícios com mais tonelagem:"),
+    ("Total tonnage", "Tonelagem total"),
+    ("Total volume", "Volume total"),
+    ("Training sessions", "Sessões de treino"),
+    ("Unknown muscles:", "Músculos desconhecidos:"),
+    ("Volume trends over period:", "Tendência de volume no período:"),
+    ("({} weeks)", "({} semanas)"),
+    ("AMRAP set logged ({} reps)", "série AMRAP registrada ({} reps)"),
+    ("Exercise \"{}\" added", "Exercício \"{}\" adicionado"),
+    ("Exercise \"{}\" already exists — use `ex list` to view all exercises", "O exercício \"{}\" já existe — use `ex list` para ver todos os exercícios"),
+    ("Exercise \"{}\" was not inserted", "O exercício \"{}\" não foi inserido"),
+    ("Invalid config key `{}`", "Chave de configuração inválida `{}`"),
+    ("No data available for graph", "Sem dados para o gráfico"),
+    ("No training data found for muscle group: {}", "Nenhum treino encontrado para o grupo muscular: {}"),
+    ("No valid data available for graph", "Sem dados válidos para o gráfico"),
+    ("You can write in any case sensitive manner (e.g. `chest` == `CHEST` == `Chest`)", "Maiúsculas e minúsculas tanto faz (ex.: `chest` == `CHEST` == `Chest`)"),
+    ("`{}` (already exists)", "`{}` (já existe)"),
+    ("`{}` is already in block `{}`", "`{}` já está no bloco `{}`"),
+    ("`{}` is now on week {}", "`{}` agora está na semana {}"),
+    ("`{}` is now {}", "`{}` agora é {}"),
+    ("`{}` matches {} sessions, use more characters", "`{}` corresponde a {} sessões, use mais caracteres"),
+    ("`{}` skipped – unknown muscle `{}`", "`{}` ignorado – músculo desconhecido `{}`"),
+    ("`{}` skipped – unknown muscle `{}` -- did you mean: `{}`?", "`{}` ignorado – músculo desconhecido `{}` -- você quis dizer: `{}`?"),
+    ("`{}` updated", "`{}` atualizado"),
+    ("added `{}` to block `{}` ({} sets{})", "`{}` adicionado ao bloco `{}` ({} séries{})"),
+    ("added {} ({} sets)", "{} adicionado ({} séries)"),
+    ("all, {}", "todos, {}"),
+    ("cannot open `{}`", "não foi possível abrir `{}`"),
+    ("cleared settings for `{}`", "ajustes de `{}` apagados"),
+    ("database exported to {}", "banco de dados exportado para {}"),
+    ("database imported from {}", "banco de dados importado de {}"),
+    ("database is healthy", "o banco de dados está íntegro"),
+    ("deleted exercise `{}`", "exercício `{}` excluído"),
+    ("deleted gym `{}`", "academia `{}` excluída"),
+    ("deleted program `{}`", "programa `{}` excluído"),
+    ("duplicate `{}` in block `{}`—skipped", "`{}` duplicado no bloco `{}`—ignorado"),
+    ("empty note", "nota vazia"),
+    ("exercise {} is not unilateral (mark it with `ex unilateral`)", "o exercício {} não é unilateral (marque com `ex unilateral`)"),
+    ("foreign keys are disabled on this connection", "as chaves estrangeiras estão desativadas nesta conexão"),
+    ("interrupted, uncommitted changes were rolled back", "interrompido, alterações não confirmadas foram desfeitas"),
+    ("group `{}` needs at least two exercises", "o grupo `{}` precisa de pelo menos dois exercícios"),
+    ("invalid group for `{}` (expected a name like \"A\")", "grupo inválido para `{}` (esperado um nome como \"A\")"),
+    ("invalid rounding `{}` (e.g. barbell, dumbbell, lb, 2.5, 1kg, 10lb)", "arredondamento inválido `{}` (ex.: barbell, dumbbell, lb, 2.5, 1kg, 10lb)"),
+    ("`{}` rounds to {}", "`{}` arredonda para {}"),
+    ("`{}` uses the default rounding", "`{}` usa o arredondamento padrão"),
+    ("{}: {} kcal{}", "{}: {} kcal{}"),
+    ("({} days)", "({} dias)"),
+    ("by {}", "até {}"),
+    ("({} min so far — `session show`)", "({} min até agora — `session show`)"),
+    ("— {}, week {}", "— {}, semana {}"),
+    ("(+{} more)", "(+{} mais)"),
+    ("{} weeks", "{} semanas"),
+    ("({} min, {} sets, {} kg)", "({} min, {} séries, {} kg)"),
+    ("{} ({} → {})", "{} ({} → {})"),
+    ("({} sessions)", "({} sessões)"),
+    ("{} set {} ({} reps): missed {}/{} sessions", "{} série {} ({} reps): falhou {}/{} sessões"),
+    ("needs +{} kg/week", "precisa de +{} kg/semana"),
+    ("can't read goal `{}` (expected e.g. \"Squat 180x1 by 2025-12-01\")", "não foi possível ler a meta `{}` (esperado ex.: \"Squat 180x1 by 2025-12-01\")"),
+    ("goal set: {} {}kg × {} by {}", "meta definida: {} {}kg × {} até {}"),
+    ("(done {})", "(concluída {})"),
+    ("several goals for `{}`, use the index from `goal list`", "várias metas para `{}`, use o índice de `goal list`"),
+    ("no open goal `{}`", "nenhuma meta em aberto `{}`"),
+    ("goal done: {} {}kg × {}", "meta concluída: {} {}kg × {}"),
+    ("imported {} nights ({} – {})", "{} noites importadas ({} – {})"),
+    ("missed sets: {}% in short-sleep weeks vs {}% otherwise (avg sleep {}h)", "séries perdidas: {}% em semanas de pouco sono vs {}% nas demais (sono médio {}h)"),
+    ("no sleep or HRV data found in `{}`", "nenhum dado de sono ou VFC encontrado em `{}`"),
+    ("unknown format for `{}` (expected a Fitbit .csv or Oura .json export)", "formato desconhecido para `{}` (esperado um export .csv do Fitbit ou .json do Oura)"),
+    ("invalid dumbbell range `{}` (expected e.g. 2-30kg)", "faixa de halteres inválida `{}` (esperado ex.: 2-30kg)"),
+    ("invalid weight: {}", "peso inválido: {}"),
+    ("key `{}` not found", "chave `{}` não encontrada"),
+    ("logged {} set {}{} for exercise {} ({} × {})", "{} série {}{} registrada para o exercício {} ({} × {})"),
+    ("members of group `{}` must be consecutive", "os membros do grupo `{}` devem ser consecutivos"),
+    ("merged {} into the database", "{} mesclado ao banco de dados"),
+    ("migration complete – legacy exercises, sessions & PRs imported", "migração concluída – exercícios, sessões e PRs antigos importados"),
+    ("missing exercises: {}", "exercícios ausentes: {}"),
+    ("block `{}`: {}", "bloco `{}`: {}"),
+    ("month must be between 1 and 12", "o mês deve estar entre 1 e 12"),
+    ("moved `{}` to position {} in block `{}`", "`{}` movido para a posição {} no bloco `{}`"),
+    ("new personal record!", "novo recorde pessoal!"),
+    ("no active session (start one with `session start`)", "nenhuma sessão ativa (inicie uma com `session start`)"),
+    ("no active session tagged `{}`", "nenhuma sessão ativa com a tag `{}`"),
+    ("no active session to cancel", "nenhuma sessão ativa para cancelar"),
+    ("no block `{}` in this program", "nenhum bloco `{}` neste programa"),
+    ("no block at index {} in program `{}`", "nenhum bloco no índice {} do programa `{}`"),
+    ("no block named `{}` in program `{}`", "nenhum bloco chamado `{}` no programa `{}`"),
+    ("no blocks defined", "nenhum bloco definido"),
+    ("no blocks defined)", "nenhum bloco definido)"),
+    ("no completed session found for {}", "nenhuma sessão concluída em {}"),
+    ("no earlier session of the same block", "nenhuma sessão anterior do mesmo bloco"),
+    ("no exercise `{}` in block `{}`", "nenhum exercício `{}` no bloco `{}`"),
+    ("no exercise at index {}", "nenhum exercício no índice {}"),
+    ("no exercise at index {} in current session", "nenhum exercício no índice {} da sessão atual"),
+    ("no exercise at index {} (see `exercise list`)", "nenhum exercício no índice {} (veja `exercise list`)"),
+    ("no exercise named `{}` (see `exercise list`)", "nenhum exercício chamado `{}` (veja `exercise list`)"),
+    ("no finished sessions", "nenhuma sessão concluída"),
+    ("no finished sessions in {}", "nenhuma sessão concluída em {}"),
+    ("no gym named `{}`", "nenhuma academia chamada `{}`"),
+    ("no gym named `{}` (see `gym list`)", "nenhuma academia chamada `{}` (veja `gym list`)"),
+    ("no program at index {} (see `program list`)", "nenhum programa no índice {} (veja `program list`)"),
+    ("no program file provided", "nenhum arquivo de programa informado"),
+    ("no program named `{}` (see `program list`)", "nenhum programa chamado `{}` (veja `program list`)"),
+    ("no session `{}`", "nenhuma sessão `{}`"),
+    ("no sessions in {}", "nenhuma sessão em {}"),
+    ("no set at index {} (max: {})", "nenhuma série no índice {} (máx.: {})"),
+    ("no settings saved for `{}`", "nenhum ajuste salvo para `{}`"),
+    ("no such exercise `{}`", "exercício `{}` não existe"),
+    ("note saved for exercise {}", "nota salva para o exercício {}"),
+    ("nothing to search for", "nada para buscar"),
+    ("parsing `{}`: {}", "lendo `{}`: {}"),
+    ("position must be between 1 and {}", "a posição deve estar entre 1 e {}"),
+    ("removed `{}`", "`{}` removido"),
+    ("removed `{}` from block `{}`", "`{}` removido do bloco `{}`"),
+    ("serving metrics on http://{}/metrics (Ctrl-C to stop)", "servindo métricas em http://{}/metrics (Ctrl-C para parar)"),
+    ("metrics server stopped", "servidor de métricas parado"),
+    ("session cancelled (id: {})", "sessão cancelada (id: {})"),
+    ("session ended (id: {})", "sessão encerrada (id: {})"),
+    ("set `{}` = `{}`", "`{}` = `{}` definido"),
+    ("sets must be at least 1", "o número de séries deve ser pelo menos 1"),
+    ("sqlite integrity check: {}", "verificação de integridade do sqlite: {}"),
+    ("swapped {} with {} ({} sets{})", "{} trocado por {} ({} séries{})"),
+    ("there is already an active session{} (id: {}) — use `--tag` to start another", "já existe uma sessão ativa{} (id: {}) — use `--tag` para iniciar outra"),
+    ("training log for {} written to {}", "diário de treino de {} salvo em {}"),
+    ("unknown band `{}`, pass --band-tension to count it", "elástico desconhecido `{}`, use --band-tension para contabilizá-lo"),
+    ("unknown equipment `{}` (one of: {})", "equipamento desconhecido `{}` (um de: {})"),
+    ("unknown muscle `{}`", "músculo desconhecido `{}`"),
+    ("unknown muscle `{}` (one of: {})", "músculo desconhecido `{}` (um de: {})"),
+    ("week {} of `{}` complete — now on week {}", "semana {} de `{}` concluída — agora na semana {}"),
+    ("weeks start at 1", "as semanas começam em 1"),
+    ("{} (added {})", "{} ({} adicionados)"),
+    ("{} (expected {}m)", "{} (previsto {}m)"),
+    ("{} ({} sessions)", "{} ({} sessões)"),
+    ("{} ({} sets)", "{} ({} séries)"),
+    ("{} ({} weeks)", "{} ({} semanas)"),
+    ("{} ({}m over the expected {}m)", "{} ({}m acima dos {}m previstos)"),
+    ("{} foreign key violation(s)", "{} violação(ões) de chave estrangeira"),
+    ("{} inserted, {} skipped (already exist), {} without a known muscle", "{} inseridos, {} ignorados (já existem), {} sem músculo conhecido"),
+    ("1RM test:", "Teste de 1RM:"),
+    ("{} (planned around {})", "{} (planejado em torno de {})"),
+    ("Warm-up:", "Aquecimento:"),
+    ("Attempts:", "Tentativas:"),
+    ("opener — should move fast", "abertura — deve subir rápido"),
+    ("second — a heavy single you're sure of", "segunda — um single pesado garantido"),
+    ("third — only if the second moved well", "terceira — só se a segunda subiu bem"),
+    ("Rest 3-5 min between attempts, then record the heaviest good single with `test-1rm \"{}\" --result <kg>`.", "Descanse 3-5 min entre tentativas e registre o single mais pesado com `test-1rm \"{}\" --result <kg>`."),
+    ("no e1RM for `{}` yet, pass `--from <kg>` to plan around a guess", "ainda não há e1RM para `{}`, use `--from <kg>` para planejar a partir de um palpite"),
+    ("the tested 1RM must be above 0", "o 1RM testado deve ser maior que 0"),
+    ("recorded a tested 1RM of {} for {}", "1RM testado de {} registrado para {}"),
+    ("training max set to {} ({}%) in {} program block(s)", "training max definido em {} ({}%) em {} bloco(s) de programa"),
+    ("Tested 1RM", "1RM testado"),
+    ("Progress photos", "Fotos de progresso"),
+    ("Progress photos:", "Fotos de progresso:"),
+    ("{} photo for {} saved to {}", "foto {} de {} salva em {}"),
+    ("no progress photos yet, add one with `photo add <file> --tag front`", "nenhuma foto de progresso ainda, adicione com `photo add <arquivo> --tag front`"),
+    ("contact sheet with {} photos written to {}", "folha de contato com {} fotos salva em {}"),
+    ("no photo at index {}", "nenhuma foto no índice {}"),
+    ("removed {} photo from {}", "foto {} de {} removida"),
+    ("Squat", "Agachamento"),
+    ("Bench press", "Supino"),
+    ("Deadlift", "Levantamento terra"),
+    ("Overhead press", "Desenvolvimento"),
+    ("untrained", "destreinado"),
+    ("novice", "iniciante"),
+    ("intermediate", "intermediário"),
+    ("advanced", "avançado"),
+    ("elite", "elite"),
+    ("set your bodyweight and sex first, e.g. `config set bodyweight 82.5` and `config set sex male`", "defina seu peso e sexo antes, p.ex. `config set bodyweight 82.5` e `config set sex male`"),
+    ("Strength standards", "Padrões de força"),
+    ("({} kg bodyweight)", "({} kg de peso corporal)"),
+    ("no sets logged", "nenhuma série registrada"),
+    ("{} at {} kg (+{} kg)", "{} com {} kg (+{} kg)"),
+    ("top of the table", "topo da tabela"),
+    ("from {}", "de {}"),
+    ("Powerlifting total:", "Total de powerlifting:"),
+    ("DOTS {} · GL {}", "DOTS {} · GL {}"),
+    ("@ {} kg", "@ {} kg"),
+    ("pass `--bw <kg>` (or set `bodyweight`) and set `sex` in config first", "passe `--bw <kg>` (ou defina `bodyweight`) e defina `sex` na config antes"),
+    ("no {} logged yet", "nenhum {} registrado ainda"),
+    ("Total:", "Total:"),
+    ("{} kg ({} / {} / {}) at {} kg bodyweight", "{} kg ({} / {} / {}) com {} kg de peso corporal"),
+    ("Totals use current e1RMs, not meet lifts.", "Os totais usam os e1RMs atuais, não levantamentos de competição."),
+    ("Measurements:", "Medidas:"),
+    ("Measurements", "Medidas"),
+    ("since {} cm", "desde {} cm"),
+    ("no measurements yet, log some with e.g. `measure --waist 84 --arm 39.5`", "nenhuma medida ainda, registre com p.ex. `measure --waist 84 --arm 39.5`"),
+    ("Measurements (last {} weeks):", "Medidas (últimas {} semanas):"),
+    ("{} must be above 0", "{} deve ser maior que 0"),
+    ("`{} {}` failed, run it yourself", "`{} {}` falhou, rode manualmente"),
+    ("No session started yet today.", "Nenhuma sessão iniciada hoje ainda."),
+    ("No session started yet today. Next up: {} ({}).", "Nenhuma sessão iniciada hoje ainda. Próximo: {} ({})."),
+    ("reminder removed", "lembrete removido"),
+    ("no reminder installed", "nenhum lembrete instalado"),
+    ("pass `--at <HH:MM>`, e.g. `remind --at 18:00 --days mon,wed,fri`", "passe `--at <HH:MM>`, ex. `remind --at 18:00 --days mon,wed,fri`"),
+    ("invalid time `{}` (expected HH:MM)", "horário inválido `{}` (esperado HH:MM)"),
+    ("invalid days `{}` (expected e.g. mon,wed,fri)", "dias inválidos `{}` (esperado ex. mon,wed,fri)"),
+    ("reminder set for {} on {} ({})", "lembrete definido para {} em {} ({})"),
+    ("daemon listening on {} (Ctrl-C to stop)", "daemon escutando em {} (Ctrl-C para parar)"),
+    ("daemon stopped", "daemon parado"),
+    (
+        "install ImageMagick (or img2sixel on sixel terminals) to show this demo inline, or open the file above in an image viewer",
+        "instale o ImageMagick (ou o img2sixel em terminais sixel) para ver esta demonstração aqui, ou abra o arquivo acima num visualizador de imagens",
+    ),
+    (
+        "could not reach the remote `{}` (`config unset remote` to log locally)",
+        "não foi possível acessar o remoto `{}` (`config unset remote` para registrar localmente)",
+    ),
+    ("in {} days", "em {} dias"),
+    ("tomorrow", "amanhã"),
+    ("today", "hoje"),
+    ("yesterday", "ontem"),
+    ("{} days ago", "há {} dias"),
+    ("{} weeks ago", "há {} semanas"),
+    ("{} months ago", "há {} meses"),
+    ("{} years ago", "há {} anos"),
+    ("no finished sessions in the last {} weeks", "nenhuma sessão concluída nas últimas {} semanas"),
+    ("Performance by time:", "Desempenho por horário:"),
+    ("(last {} weeks, {} sessions)", "(últimas {} semanas, {} sessões)"),
+    ("% of best", "% do melhor"),
+    ("tonnage", "tonelagem"),
+    ("% of best: each session's top set per exercise against the best in the window; the delta is against your average.", "% do melhor: a melhor série de cada exercício na sessão contra a melhor do período; a diferença é contra a sua média."),
+    ("best {} kg e1RM on {}, top {} kg in {} sessions since", "melhor e1RM {} kg em {}, máximo de {} kg em {} sessões desde então"),
+    ("Stalled lifts:", "Levantamentos estagnados:"),
+    ("(no e1RM PR in {}+ weeks, `suggest` for what to do)", "(sem PR de e1RM há {}+ semanas, veja `suggest`)"),
+    ("no lift has gone {} weeks without an e1RM PR", "nenhum levantamento está há {} semanas sem PR de e1RM"),
+    ("Suggestions:", "Sugestões:"),
+    ("deload: a week around {} kg e1RM (90% of the current trend), then build back over 2-3 weeks", "deload: uma semana por volta de {} kg de e1RM (90% da tendência atual), depois retome em 2-3 semanas"),
+    ("swap for a variation: `{}` for a block, then come back to it", "troque por uma variação: `{}` por um bloco, depois volte a ele"),
+    ("swap for a variation for a block (change grip, stance or tempo)", "troque por uma variação por um bloco (mude pegada, base ou tempo)"),
+    ("rotation option `{}` is not an exercise—doing `{}` instead", "opção de rotação `{}` não é um exercício—fazendo `{}`"),
+    ("rotation of {}", "rotação de {}"),
+    ("sets", "séries"),
+    ("avg rest", "descanso médio"),
+    ("range", "faixa"),
+    ("target", "alvo"),
+    ("density", "densidade"),
+    ("Rest:", "Descanso:"),
+    ("Density:", "Densidade:"),
+    ("On target:", "No alvo:"),
+    ("no sessions yet", "nenhuma sessão ainda"),
+    ("no sets logged in this session", "nenhuma série registrada nesta sessão"),
+    ("{} kg in {} of working time ({})", "{} kg em {} de tempo de trabalho ({})"),
+    ("{}% of rests within 15s (or 15%) of the program", "{}% dos descansos a até 15s (ou 15%) do programa"),
+    ("e1RM trend", "Tendência de e1RM"),
+    ("(likely {}–{}kg over {} sessions)", "(provavelmente {}–{}kg em {} sessões)"),
+    ("trend {} kg e1RM (likely {}–{} kg)", "tendência {} kg de e1RM (provavelmente {}–{} kg)"),
+    ("anonymized database exported to {}", "banco de dados anonimizado exportado para {}"),
+    ("Database:", "Banco de dados:"),
+    ("({} free, reclaimed by `db maintain`)", "({} livres, recuperados com `db maintain`)"),
+    ("Rows:", "Linhas:"),
+    ("{} empty tables", "{} tabelas vazias"),
+    ("Indexes:", "Índices:"),
+    ("integrity check passed", "verificação de integridade ok"),
+    ("vacuumed and analyzed: {} → {}", "compactado e analisado: {} → {}"),
+    ("expected field=column in `{}`", "esperado campo=coluna em `{}`"),
+    ("unknown field `{}` (one of: {})", "campo desconhecido `{}` (um de: {})"),
+    ("no column `{}` in the file", "nenhuma coluna `{}` no arquivo"),
+    ("`--map` is missing {}", "falta {} no `--map`"),
+    ("pass `--map`, e.g. `--map \"date=A,exercise=B,weight=D,reps=E\"`", "passe `--map`, ex. `--map \"date=A,exercise=B,weight=D,reps=E\"`"),
+    ("Columns:", "Colunas:"),
+    ("Answer with a column letter, number or name; Enter skips optional fields.", "Responda com a letra, número ou nome da coluna; Enter pula campos opcionais."),
+    ("(optional)", "(opcional)"),
+    ("mapping cancelled", "mapeamento cancelado"),
+    ("no column `{}`", "nenhuma coluna `{}`"),
+    ("next time pass `--map \"{}\"`", "da próxima vez passe `--map \"{}\"`"),
+    ("`{}` is empty", "`{}` está vazio"),
+    ("no rows of `{}` could be read with this mapping", "nenhuma linha de `{}` pôde ser lida com esse mapeamento"),
+    ("skipped {} unreadable rows ({}{})", "{} linhas ilegíveis ignoradas ({}{})"),
+    ("{} days were already imported", "{} dias já tinham sido importados"),
+    ("added {} new exercises", "{} exercícios novos adicionados"),
+    ("imported {} sets in {} sessions ({} – {}) into `{}`", "{} séries importadas em {} sessões ({} – {}) em `{}`"),
+    ("({} sets)", "({} séries)"),
+    ("{} sets · {} kg moved", "{} séries · {} kg levantados"),
+    ("copied to the clipboard", "copiado para a área de transferência"),
+    ("no clipboard tool found (wl-copy, xclip, xsel, pbcopy), copy the text above", "nenhuma ferramenta de área de transferência encontrada (wl-copy, xclip, xsel, pbcopy), copie o texto acima"),
+    ("Target", "Alvo"),
+    ("Weight", "Peso"),
+    ("Date", "Data"),
+    ("Bodyweight", "Peso corporal"),
+    ("tempo {}", "cadência {}"),
+    ("block `{}` has no exercises", "o bloco `{}` não tem exercícios"),
+    ("can't tell the format of `{}` (use .pdf or .md)", "formato de `{}` desconhecido (use .pdf ou .md)"),
+    ("sheet for {} written to {}", "ficha de {} salva em {}"),
+    ("invalid rep target `{}` for `{}` (expected e.g. 5, 8-12, 5+, 3x3 cluster or 30s)", "alvo de repetições inválido `{}` para `{}` (esperado por ex. 5, 8-12, 5+, 3x3 cluster ou 30s)"),
+    ("RPE chart:", "Tabela de RPE:"),
+    ("e1RM", "1RM estimado"),
+    ("training max", "máximo de treino"),
+    ("given", "informado"),
+    ("no program sets a training max for `{}`", "nenhum programa define um máximo de treino para `{}`"),
+    ("no e1RM for `{}` yet, pass `--from <kg>` to use a guess", "ainda não há 1RM estimado para `{}`, passe `--from <kg>` para usar uma estimativa"),
+    ("moved {} to position {}", "{} movido para a posição {}"),
+    ("{} already has {} logged set(s), pass --force to remove them too", "{} já tem {} série(s) registrada(s), use --force para removê-las também"),
+    ("removed {} from the session", "{} removido da sessão"),
+    ("✗ failed", "✗ falhou"),
+    ("failed", "falhou"),
+    ("skipped set {}{} of exercise {}", "série {}{} do exercício {} pulada"),
+    ("logged failed {} set {}{} for exercise {} ({} × {})", "{} série {}{} falhada registrada para o exercício {} ({} × {})"),
+    ("invalid weeks `{}` (expected e.g. 5-8 or 3)", "semanas inválidas `{}` (esperado por ex. 5-8 ou 3)"),
+    ("`{}` has no blocks in weeks {}", "`{}` não tem blocos nas semanas {}"),
+    ("`{}` updated (weeks {})", "`{}` atualizado (semanas {})"),
+    ("  (no active programs)", "  (nenhum programa ativo)"),
+    ("Past programs:", "Programas anteriores:"),
+    ("`{}` is already active", "`{}` já está ativo"),
+    ("`{}` is active again", "`{}` está ativo novamente"),
+    ("`{}` was already archived on {}", "`{}` já foi arquivado em {}"),
+    ("archived `{}`", "`{}` arquivado"),
+    ("invalid span `{}` (expected e.g. 12w or 30d)", "período inválido `{}` (esperado p.ex. 12w ou 30d)"),
+    ("Exercise frequency:", "Frequência por exercício:"),
+    ("Muscle frequency:", "Frequência por músculo:"),
+    ("(last {} weeks)", "(últimas {} semanas)"),
+    ("several programs in {} of {} weeks", "vários programas em {} de {} semanas"),
+    ("⚠ trained by more than one program", "⚠ treinado em mais de um programa"),
+    ("substitution group `{}` needs at least two exercises", "grupo de substituição `{}` precisa de pelo menos dois exercícios"),
+    ("Substitutions:", "Substituições:"),
+    ("`{}` is in no substitution group of this program", "`{}` não está em nenhum grupo de substituição deste programa"),
+    ("Substitutes for {}:", "Substitutos para {}:"),
+    ("(swapped in {}×)", "(trocado {}×)"),
+    ("swap with `session swap {} \"<name>\"`", "troque com `session swap {} \"<nome>\"`"),
+    ("no unfinished sessions", "nenhuma sessão inacabada"),
+    ("Unfinished sessions:", "Sessões inacabadas:"),
+    ("stale, idle for {}h", "abandonada, parada há {}h"),
+    ("{} sets", "{} séries"),
+    ("(started {})", "(iniciada {})"),
+    ("rebuild one with `recover-session <id>`", "recupere uma com `recover-session <id>`"),
+    ("session {} is already finished", "a sessão {} já foi finalizada"),
+    ("recovered session {}: {} sets kept, {} replayed from the journal, {} exercises restored", "sessão {} recuperada: {} séries mantidas, {} refeitas a partir do diário, {} exercícios restaurados"),
+    ("carry on with `session show{}`, or close it with `session finish{}`", "continue com `session show{}` ou feche com `session finish{}`"),
+    ("invalid formula `{}`: {}", "fórmula inválida `{}`: {}"),
+    ("e1rm_formula `{}`: {} — using Epley", "e1rm_formula `{}`: {} — usando Epley"),
+    ("no weighted sets logged for `{}` yet", "nenhuma série com carga registrada para `{}` ainda"),
+    ("PR timeline:", "Linha do tempo de PRs:"),
+    ("{} ({} records)", "{} ({} recordes)"),
+    ("{}d later", "{}d depois"),
+    ("{} → {} kg e1RM", "{} → {} kg de e1RM"),
+    ("{} kg/month, a PR every {} days on average", "{} kg/mês, um PR a cada {} dias em média"),
+    ("last PR {} days ago", "último PR há {} dias"),
+    ("Training age", "Tempo de treino"),
+    ("since {} ({}) – {} sessions – {} / week", "desde {} ({}) – {} sessões – {} / semana"),
+    ("{}w", "{}sem"),
+    ("{}m", "{}m"),
+    ("{}y", "{}a"),
+    ("{}y {}m", "{}a {}m"),
+    ("Tags:", "Etiquetas:"),
+    ("no finished sessions tagged `{}` in the last {} weeks", "nenhuma sessão concluída com a etiqueta `{}` nas últimas {} semanas"),
+    ("tagged `{}` only", "somente com a etiqueta `{}`"),
+    ("no finished sessions tagged `{}` in {}", "nenhuma sessão concluída com a etiqueta `{}` em {}"),
+    ("Week comparison:", "Comparação de semanas:"),
+    ("W{} → W{}", "S{} → S{}"),
+    ("new", "novo"),
+    ("dropped", "removido"),
+    ("not in week {}", "ausente na semana {}"),
+    ("no load or volume increase", "sem aumento de carga ou volume"),
+    ("{} of {} blocks don't progress from week {} to {}", "{} de {} blocos não progridem da semana {} para a {}"),
+    ("the program has no blocks for week {}", "o programa não tem blocos na semana {}"),
+    ("invalid weeks `{}` (expected two weeks, e.g. 3,4)", "semanas inválidas `{}` (esperado duas semanas, ex.: 3,4)"),
+    ("--added-weight goes with a `bw` set, e.g. `session edit 2 bw 5 --added-weight 20`", "--added-weight vai com uma série `bw`, ex.: `session edit 2 bw 5 --added-weight 20`"),
+    ("bodyweight + {}kg", "peso corporal + {}kg"),
+    ("total load {}kg ({}× bodyweight)", "carga total {}kg ({}× o peso corporal)"),
+    ("set `bodyweight` in config to count the total load of weighted bodyweight sets", "defina `bodyweight` na config para contar a carga total das séries com peso corporal"),
+    ("Relative strength", "Força relativa"),
+    ("{}×BW e1RM", "e1RM {}×PC"),
+    ("({} at {}kg bodyweight on {})", "({} com {}kg de peso corporal em {})"),
+    ("invalid decimal separator `{}` (expected . or ,)", "separador decimal inválido `{}` (esperado . ou ,)"),
+    ("invalid number of decimals `{}` (expected 0 to 3)", "número de casas decimais inválido `{}` (esperado de 0 a 3)"),
+    ("Aliases:", "Atalhos:"),
+    ("built-in", "embutido"),
+    ("config", "configuração"),
+    ("Program complete:", "Programa concluído:"),
+    ("  (no finished sessions of this program yet)", "  (nenhuma sessão finalizada deste programa ainda)"),
+    ("Tonnage:", "Tonelagem:"),
+    ("{}% ({}/{} planned sessions)", "{}% ({}/{} sessões planejadas)"),
+    ("Adherence:", "Aderência:"),
+    ("Main lifts (e1RM, first → last session):", "Levantamentos principais (e1RM, primeira → última sessão):"),
+    ("PRs during the program:", "PRs durante o programa:"),
+    ("Sets per muscle (last 7 days):", "Séries por músculo (últimos 7 dias):"),
+    ("Sets per muscle (current week):", "Séries por músculo (semana atual):"),
+    ("(4-week avg)", "(média de 4 semanas)"),
+    ("accessories: can't choose {} of {} exercises", "acessórios: não dá para escolher {} de {} exercícios"),
+    ("{} accessories from the pool", "{} acessórios do grupo"),
+    ("[pool]", "[grupo]"),
+    ("`{}` is not in this block's accessory pool ({})", "`{}` não está no grupo de acessórios deste bloco ({})"),
+    ("  accessories picked from the pool: least recently trained first", "  acessórios escolhidos do grupo: os treinados há mais tempo primeiro"),
+    ("EMOM {} × {}s", "EMOM {} × {}s"),
+    ("Circuit {} rounds, {}s rest", "Circuito {} voltas, {}s de descanso"),
+    ("Circuit {} rounds", "Circuito {} voltas"),
+    ("this block has no EMOM or circuit", "este bloco não tem EMOM nem circuito"),
+    ("no circuit {} in this block (1-{})", "não há circuito {} neste bloco (1-{})"),
+    ("all {} rounds of {} are already logged", "todas as {} voltas de {} já foram registradas"),
+    ("logged rounds {}-{} of {}", "voltas {}-{} de {} registradas"),
+    ("  a round every interval — Ctrl-C stops, finished rounds are kept", "  uma volta a cada intervalo — Ctrl-C para, as voltas concluídas ficam salvas"),
+    ("  Enter when a round is done, q to stop", "  Enter ao terminar uma volta, q para parar"),
+    ("Round {}/{}", "Volta {}/{}"),
+    ("next round in", "próxima volta em"),
+    ("rest", "descanso"),
+    ("{}: {}/{} rounds logged", "{}: {}/{} voltas registradas"),
+    ("Circuits:", "Circuitos:"),
+    ("{}/{} rounds", "{}/{} voltas"),
+    ("unknown circuit kind `{}` (expected emom or circuit)", "tipo de circuito desconhecido `{}` (esperado emom ou circuit)"),
+    ("a circuit needs at least one round and one exercise", "um circuito precisa de pelo menos uma volta e um exercício"),
+    ("an EMOM interval must be at least 1 second", "o intervalo de um EMOM deve ser de pelo menos 1 segundo"),
+    ("Cues:", "Dicas:"),
+    ("Demo:", "Demonstração:"),
+    ("no cues for `{}` yet, add them with `exercise demo {} --cues \"...\"`", "ainda não há dicas para `{}`, adicione com `exercise demo {} --cues \"...\"`"),
+    ("demo for `{}` saved to {}", "demonstração de `{}` salva em {}"),
+    ("cues for `{}` updated", "dicas de `{}` atualizadas"),
+    ("target RPE must be between 6 and 10, got {}", "o RPE alvo deve estar entre 6 e 10, recebido {}"),
+    ("a top set is a single, double or triple (--reps 1-3)", "uma série top é um single, double ou triple (--reps 1-3)"),
+    ("invalid back-off drop: {}%", "queda de back-off inválida: {}%"),
+    ("Back-off sets:", "Séries back-off:"),
+    ("({}% of the top set)", "({}% da série top)"),
+    ("Top set @{}:", "Série top @{}:"),
+    ("  e1RM {}kg, aiming for about {}", "  e1RM {}kg, mirando em cerca de {}"),
+    ("  after each attempt type its RPE (or `<kg> <rpe>` for another weight), q to stop", "  depois de cada tentativa digite o RPE (ou `<kg> <rpe>` para outro peso), q para parar"),
+    ("Attempt {}: {} × {} — RPE?", "Tentativa {}: {} × {} — RPE?"),
+    ("Attempt {}: <kg> <rpe>?", "Tentativa {}: <kg> <rpe>?"),
+    ("  type an RPE like `7.5`, or a weight and an RPE like `140 8`", "  digite um RPE como `7.5`, ou um peso e um RPE como `140 8`"),
+    ("  overshot the target by {} RPE, take the back-offs a little lighter", "  passou do alvo em {} de RPE, faça os back-offs um pouco mais leves"),
+    ("stopped after {} attempts, no top set @{}", "parado após {} tentativas, sem série top @{}"),
+    ("top set: {}kg × {} @{}{}", "série top: {}kg × {} @{}{}"),
+    ("log them with `session edit`", "registre-as com `session edit`"),
+    ("calendar written to {}", "calendário salvo em {}"),
+    ("{} sessions", "{} sessões"),
+    ("{} days trained", "{} dias treinados"),
+    ("PNG export needs rsvg-convert or ImageMagick; export to .svg instead", "exportar PNG precisa do rsvg-convert ou do ImageMagick; exporte para .svg"),
+    ("{} exercises exported to {}", "{} exercícios exportados para {}"),
+    ("unknown muscle `{}` for `{}`", "músculo desconhecido `{}` para `{}`"),
+    ("{} exercises already exist ({}); pass --merge to combine the libraries", "{} exercícios já existem ({}); use --merge para combinar as bibliotecas"),
+    ("{} added, {} merged, {} aliases added", "{} adicionados, {} mesclados, {} apelidos adicionados"),
+    ("invalid month `{}` (expected YYYY-MM or a date)", "mês inválido `{}` (esperado AAAA-MM ou uma data)"),
+    ("week of {} → week of {}", "semana de {} → semana de {}"),
+    ("  (no finished sessions in either week)", "  (nenhuma sessão concluída em nenhuma das semanas)"),
+    ("Muscles (sets, tonnage):", "Músculos (séries, tonelagem):"),
+    ("Exercises (sets, tonnage):", "Exercícios (séries, tonelagem):"),
+    ("give at most two weeks", "informe no máximo duas semanas"),
+    ("best at {}–{} sets/week ({}%/week over {} weeks)", "melhor com {}–{} séries/semana ({}%/semana em {} semanas)"),
+    ("no range with {}+ weeks of progress yet", "nenhuma faixa com {}+ semanas de progresso ainda"),
+    ("and {} more", "e mais {}"),
+    ("▲ over it:", "▲ acima dela:"),
+    ("Volume landmarks:", "Marcos de volume:"),
+    ("Each week's working sets against how the muscle's e1RMs moved into the next week; ◆ marks the range that gained the most.", "As séries de trabalho de cada semana contra como os e1RMs do músculo mudaram na semana seguinte; ◆ marca a faixa que mais ganhou."),
+    ("Compliance — {}", "Aderência — {}"),
+    ("{} sessions ({} per week)", "{} sessões ({} por semana)"),
+    ("{} of {} planned sets done ({})", "{} de {} séries planejadas feitas ({})"),
+    ("{} on their rep target ({})", "{} na meta de repetições ({})"),
+    ("Sessions", "Sessões"),
+    ("Planned", "Planejadas"),
+    ("Done", "Feitas"),
+    ("On target", "Na meta"),
+    ("{} weeks of training written to {} ({} files)", "{} semanas de treino gravadas em {} ({} arquivos)"),
+    ("setup cancelled", "configuração cancelada"),
+    ("answer y or n", "responda s ou n"),
+    ("Database file (or :memory:)", "Arquivo do banco (ou :memory:)"),
+    ("Units (kg or lb)", "Unidades (kg ou lb)"),
+    ("unknown unit `{}`", "unidade desconhecida `{}`"),
+    ("Language (en or pt-BR)", "Idioma (en ou pt-BR)"),
+    ("unknown language `{}`", "idioma desconhecido `{}`"),
+    ("Bodyweight in kg (Enter to skip)", "Peso corporal em kg (Enter para pular)"),
+    ("invalid bodyweight `{}`", "peso corporal inválido `{}`"),
+    ("`setup` asks questions; from a script use `config set` instead", "`setup` faz perguntas; em scripts use `config set`"),
+    ("Setup:", "Configuração:"),
+    ("Enter keeps the value in brackets.", "Enter mantém o valor entre colchetes."),
+    ("config written to {}", "configuração gravada em {}"),
+    ("times follow the system timezone (TZ) and weeks start on Monday", "os horários seguem o fuso do sistema (TZ) e as semanas começam na segunda"),
+    ("database ready: {}", "banco pronto: {}"),
+    ("Import the starter exercise library?", "Importar a biblioteca inicial de exercícios?"),
+    ("Program templates:", "Modelos de programa:"),
+    ("Full body, 3 days a week", "Corpo inteiro, 3 dias por semana"),
+    ("Upper/lower, 4 days a week", "Superior/inferior, 4 dias por semana"),
+    ("First program (number, Enter for none)", "Primeiro programa (número, Enter para nenhum)"),
+    ("no template `{}`", "nenhum modelo `{}`"),
+    ("the template's exercises come from the starter library, adding the missing ones", "os exercícios do modelo vêm da biblioteca inicial, adicionando os que faltam"),
+    ("edit {} and run `p import` on it to change the program", "edite {} e rode `p import` nele para mudar o programa"),
+    ("all set, run `lazarus` for today's dashboard", "tudo pronto, rode `lazarus` para ver o painel de hoje"),
+    ("extra", "extra"),
+    ("no target", "sem meta"),
+    ("not logged", "não registrada"),
+    ("no planned sets", "nenhuma série planejada"),
+    ("compliance {}% ({}/{} sets on target)", "aderência {}% ({}/{} séries na meta)"),
+    ("Yellow beat the target, green met it, red missed it; a set is on target when nothing was missed.", "Amarelo superou a meta, verde cumpriu, vermelho ficou abaixo; uma série está na meta quando nada ficou abaixo."),
+    ("unknown muscle `{}` -- did you mean `{}`?", "músculo desconhecido `{}` -- você quis dizer `{}`?"),
+    ("no exercise named `{}` -- did you mean {}? (or add it with `--create --muscle <muscle>`)", "nenhum exercício chamado `{}` -- você quis dizer {}? (ou adicione com `--create --muscle <músculo>`)"),
+    ("no training max or e1RM for `{}` yet; its %1RM sets have no weight", "`{}` ainda não tem máximo de treino nem e1RM; suas séries em %1RM ficam sem carga"),
+    ("1RM of {} filled in as {}kg from its {}", "1RM de {} preenchido com {}kg a partir do seu {}"),
+    ("Checklist:", "Checklist:"),
+    ("  tick items off with `--check <item>` when starting", "  marque itens com `--check <item>` ao iniciar"),
+    ("unknown gear `{}` (expected one of {})", "equipamento desconhecido `{}` (esperado um de {})"),
+    ("with {}", "com {}"),
+    ("without {}", "sem {}"),
+    ("  only sets {}", "  apenas séries {}"),
+    ("a token named `{}` already exists (revoke it first)", "já existe um token chamado `{}` (revogue-o antes)"),
+    ("created {} token `{}`", "token {} `{}` criado"),
+    ("it won't be shown again; send it as `Authorization: Bearer <token>`", "ele não será mostrado de novo; envie como `Authorization: Bearer <token>`"),
+    ("Tokens:", "Tokens:"),
+    ("  (no tokens — the HTTP server is open to anyone who can reach it)", "  (nenhum token — o servidor HTTP está aberto a qualquer um que o alcance)"),
+    ("last used {}", "último uso {}"),
+    ("never used", "nunca usado"),
+    ("created {}", "criado {}"),
+    ("no token named `{}`", "nenhum token chamado `{}`"),
+    ("revoked token `{}`", "token `{}` revogado"),
+    ("no API tokens, so anyone on the network can read these metrics; add one with `lazarus token create <name>`", "nenhum token de API, então qualquer um na rede pode ler estas métricas; crie um com `lazarus token create <nome>`"),
+    ("(targets as prescribed then; the block has changed since)", "(metas como prescritas na época; o bloco mudou desde então)"),
+    ("database imported from {} ({} rows in {}s)", "banco de dados importado de {} ({} linhas em {}s)"),
+    ("{}/{} rows", "{}/{} linhas"),
+    ("({} rows/s)", "({} linhas/s)"),
+    ("MEDIA:", "MÍDIA:"),
+    ("set {}", "série {}"),
+    ("no file at `{}`", "nenhum arquivo em `{}`"),
+    ("set {} of exercise {} is not logged yet", "a série {} do exercício {} ainda não foi registrada"),
+    ("attached to set {} of exercise {}: {}", "anexado à série {} do exercício {}: {}"),
+    ("paused now", "pausada agora"),
+    ("Paused:", "Pausada:"),
+    ("session is already paused", "a sessão já está pausada"),
+    ("session is not paused", "a sessão não está pausada"),
+    ("session paused, `lazarus resume` to pick it back up", "sessão pausada, `lazarus resume` para retomar"),
+    ("session resumed ({} paused in total)", "sessão retomada ({} pausada no total)"),
+    ("{} problem(s) found — run `lazarus doctor --repair` to fix them", "{} problema(s) encontrado(s) — rode `lazarus doctor --repair` para corrigir"),
+    ("{} problem(s) handled", "{} problema(s) resolvido(s)"),
+    ("{} problem(s) left after repair", "{} problema(s) restante(s) após o reparo"),
+    ("{} sessions are active, pick one with `--session <tag>`: {}", "{} sessões ativas, escolha uma com `--session <tag>`: {}"),
+    ("{} sessions, {} sets, {} reps", "{} sessões, {} séries, {} reps"),
+    ("{} weeks in a row", "{} semanas seguidas"),
+    ("{} — {} (added {})", "{} — {} ({} adicionados)"),
+    ("{} — {} (duration: {})", "{} — {} (duração: {})"),
+    ("{} — {} (started {}, duration: {})", "{} — {} (início {}, duração: {})"),
+    ("{}: {} found, {} repaired", "{}: {} encontrado(s), {} corrigido(s)"),
+    ("{}: repair failed: {}", "{}: o reparo falhou: {}"),
+    ("{}{} — {} (started {}, duration: {})", "{}{} — {} (início {}, duração: {})"),
+    (" tagged `{}`", " com a tag `{}`"),
+    ("untagged", "sem tag"),
+    ("Training log — {}", "Diário de treino — {}"),
+    ("Set", "Série"),
+    ("Load", "Carga"),
+    ("Reps", "Reps"),
+    ("Notes", "Notas"),
+    ("planned off week", "semana de descanso planejada"),
+    ("week {} is a planned off week — the streak is frozen until you're back", "a semana {} é de descanso planejado — a sequência fica congelada até você voltar"),
+    ("Rest days:", "Dias de descanso:"),
+    ("  (none in the last 30 days)", "  (nenhum nos últimos 30 dias)"),
+    ("invalid date `{}` (expected {})", "data inválida `{}` (esperado {})"),
+    ("removed {} rest day(s)", "{} dia(s) de descanso removido(s)"),
+    ("week of {} marked as rest", "semana de {} marcada como descanso"),
+    ("{} marked as a rest day", "{} marcado como dia de descanso"),
+];
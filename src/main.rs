@@ -1,15 +1,27 @@
-use std::collections::HashMap;
+use std::{collections::HashMap, path::PathBuf};
 
 use anyhow::{Context, Result};
-use clap::Parser;
-use cli::{Cli, Commands};
-use db::open;
+use clap::{CommandFactory, Parser};
+use cli::{Cli, Commands, ExerciseCmd, ProgramCmd, SessionCmd, ShowCmd};
+use colored::Colorize;
+use db::{Backend, DB, open};
+use errors::AppError;
+use i18n::tr;
 use types::{Config, OutputFmt};
+use ui::Themed;
 
 mod cli;
 mod db;
+mod errors;
+mod formula;
+mod i18n;
 mod commands;
+mod dates;
 mod types;
+mod storage;
+mod ui;
+#[cfg(test)]
+mod testutil;
 
 #[tokio::main]
 async fn main() -> Result<()> {
@@ -20,25 +32,174 @@ async fn main() -> Result<()> {
 
     let new_args = rewrite_args(&alias_map);
     
-    let cli = Cli::parse_from(new_args);
+    let cli = Cli::parse_from(new_args.clone());
+    ui::init(&cfg, cli.no_color, cli.quiet);
+    i18n::init(&cfg);
+    formula::init(&cfg);
 
     let fmt = OutputFmt {
         json: cli.json || json_default,
     };
     
-    let db_path = "./lazarus.db";
-    assert!(!db_path.is_empty(), "database path must not be empty");
-    
-    let pool = open(&db_path).await?;
+    let res = if let Some(Commands::Setup) = cli.cmd {
+        // `setup` writes the config the database is opened from, so it opens it itself.
+        commands::setup::handle(cfg, config_path, fmt).await
+    } else if let Some(remote) = cfg.remote().filter(|_| commands::remote::forwards(&cli.cmd)) {
+        // The session lives in the remote's database, not this one.
+        commands::remote::run(&remote, cfg.remote_token().as_deref(), &new_args[1..]).await
+    } else {
+        let backend = Backend::parse(&cfg.database())?;
+        let pool = open(&backend).await?;
+        // Servers run until Ctrl-C by design: they stop on it themselves.
+        let serves = matches!(cli.cmd, Some(Commands::Daemon { .. } | Commands::Metrics { .. }));
+
+        // On Ctrl-C the running command is dropped, which rolls back any open
+        // transaction; closing the pool waits for those rollbacks to land.
+        let cmd = run(cli.cmd.unwrap_or(Commands::Today), &pool, fmt, cfg, config_path);
+        let res = if serves {
+            Some(cmd.await)
+        } else {
+            tokio::select! {
+                res = cmd => Some(res),
+                _ = tokio::signal::ctrl_c() => None,
+            }
+        };
+        pool.close().await;
+        match res {
+            Some(res) => res,
+            None => {
+                eprintln!(
+                    "{} {}",
+                    tr("warning:").accent().bold(),
+                    tr("interrupted, uncommitted changes were rolled back")
+                );
+                // 128 + SIGINT, as shells report an interrupted command.
+                std::process::exit(130);
+            }
+        }
+    };
+
+    // Typed failures get a plain message and their own exit code so scripts
+    // can tell "not found" from a real error.
+    if let Err(e) = &res {
+        if let Some(app) = e.downcast_ref::<AppError>() {
+            eprintln!("{} {}", tr("error:").bad().bold(), app);
+            std::process::exit(app.exit_code());
+        }
+    }
 
-    match cli.cmd {
-        Commands::Session(cmd) => commands::session::handle(cmd, &pool).await?,
-        Commands::Exercise(cmd) => commands::exercise::handle(cmd, &pool, fmt).await?,
+    res
+}
+
+async fn run(cmd: Commands, pool: &DB, fmt: OutputFmt, cfg: Config, config_path: PathBuf) -> Result<()> {
+    match cmd {
+        Commands::Today => commands::today::handle(pool).await?,
+        Commands::Session(args) => commands::session::run(args.cmd, pool, args.session, &cfg).await?,
+        Commands::Pause { session } => commands::session::run(SessionCmd::Pause, pool, session, &cfg).await?,
+        Commands::Resume { session } => commands::session::run(SessionCmd::Resume, pool, session, &cfg).await?,
+        Commands::MoveEx { exercise, to, session } => {
+            commands::session::run(SessionCmd::MoveEx { exercise, to }, pool, session, &cfg).await?
+        }
+        Commands::RemoveEx { exercise, force, session } => {
+            commands::session::run(SessionCmd::RemoveEx { exercise, force }, pool, session, &cfg).await?
+        }
+        Commands::Exercise(cmd) => commands::exercise::handle(cmd, pool, fmt, cfg.imbalance_threshold()).await?,
         Commands::Config(cmd) => commands::config::handle(cmd, cfg, config_path).await?,
-        Commands::Program(cmd) => commands::program::handle(cmd, &pool, fmt).await?,
-        Commands::Calendar { year, month } => commands::calendar::handle(&pool, year, month).await?,
-        Commands::Status { muscle, weeks, graph } => commands::status::handle_status(muscle, weeks, graph, &pool).await?,
-        Commands::Db(cmd) => commands::db::handle(cmd, &pool).await?
+        Commands::Setup => unreachable!("`setup` runs before the database is opened"),
+        Commands::Program(mut cmd) => {
+            if let ProgramCmd::Import { autofill_1rm, .. } = &mut cmd {
+                *autofill_1rm |= cfg.autofill_1rm();
+            }
+            commands::program::handle(cmd, pool, fmt).await?
+        }
+        Commands::Show(ShowCmd::Session { date, session, vs_target }) => {
+            let cmd = match date {
+                Some(date) => SessionCmd::Log { date, vs_target },
+                None => SessionCmd::Show,
+            };
+            commands::session::run(cmd, pool, session, &cfg).await?
+        }
+        Commands::Show(ShowCmd::Program { program, matrix, compare_weeks }) => {
+            let cmd = ProgramCmd::Show { program, matrix, compare_weeks };
+            commands::program::handle(cmd, pool, fmt).await?
+        }
+        Commands::Show(ShowCmd::Ex { exercise, graph, image }) => {
+            let cmd = ExerciseCmd::Show { exercise, graph, image };
+            commands::exercise::handle(cmd, pool, fmt, cfg.imbalance_threshold()).await?
+        }
+        Commands::Calendar { year, month, date, tag, heatmap, export } => {
+            commands::calendar::handle(pool, year, month, date, tag, heatmap, export).await?
+        }
+        Commands::Status { muscle, weeks, graph, rolling } => {
+            commands::status::handle_status(muscle, weeks, graph, rolling, cfg.stall_weeks(), pool).await?
+        }
+        Commands::Db(cmd) => commands::db::handle(cmd, pool, fmt).await?,
+        Commands::Gym(cmd) => commands::gym::handle(cmd, pool).await?,
+        Commands::Goal(cmd) => commands::goal::handle(cmd, pool).await?,
+        Commands::Photo(cmd) => commands::photo::handle(cmd, pool).await?,
+        Commands::Doctor { repair } => commands::doctor::handle(pool, repair).await?,
+        Commands::Search { query, limit } => commands::search::handle(pool, query, limit, fmt).await?,
+        Commands::CompareSessions { first, second } => commands::compare::handle(pool, first, second).await?,
+        Commands::Share { session, clipboard } => commands::share::handle(pool, session, clipboard).await?,
+        Commands::Wrapped { year, markdown } => commands::wrapped::handle(pool, year, markdown, fmt).await?,
+        Commands::RestDay { date, reason, week, remove, list } => {
+            commands::rest::handle(pool, date, reason, week, remove, list).await?
+        }
+        Commands::LogCal { calories, protein, date } => {
+            commands::nutrition::handle(pool, calories, protein, date).await?
+        }
+        Commands::Standards => commands::standards::handle(pool, cfg.bodyweight(), cfg.sex(), fmt).await?,
+        Commands::Points { bw } => commands::points::handle(pool, bw.or(cfg.bodyweight()), cfg.sex(), fmt).await?,
+        Commands::Test1rm { exercise, result, from, tm } => {
+            commands::test_1rm::handle(pool, exercise, result, from, tm, cfg.rounding()).await?
+        }
+        Commands::PrTimeline { exercise, with, without } => {
+            commands::pr_timeline::handle(pool, exercise, with, without, fmt).await?
+        }
+        Commands::Freq { last } => commands::freq::handle(pool, last, fmt).await?,
+        Commands::VolumeLandmarks { weeks, muscle } => commands::landmarks::handle(pool, weeks, muscle, fmt).await?,
+        Commands::RpeChart { exercise, tm, from } => {
+            commands::rpe_chart::handle(pool, exercise, tm, from, cfg.rounding(), fmt).await?
+        }
+        Commands::Attach { exercise, set, file, session, date } => {
+            commands::attach::handle(pool, exercise, set, file.into(), session, date).await?
+        }
+        Commands::Measure { waist, hips, chest, arm, thigh, calf, neck, date, weeks } => {
+            let values = vec![
+                ("waist", waist),
+                ("hips", hips),
+                ("chest", chest),
+                ("arm", arm),
+                ("thigh", thigh),
+                ("calf", calf),
+                ("neck", neck),
+            ];
+            commands::measure::handle(pool, values, date, weeks).await?
+        }
+        Commands::ImportRecovery { file } => commands::recovery::handle_import(pool, file).await?,
+        Commands::ImportCsv { file, map, date_format } => commands::import_csv::handle(pool, file, map, date_format).await?,
+        Commands::ExportLog { month, format, out, tag } => commands::journal::handle(pool, month, format, out, tag).await?,
+        Commands::CoachExport { last, out } => commands::coach_export::handle(pool, last, out).await?,
+        Commands::Sheet { program, block, out } => commands::sheet::handle(pool, program, block, out).await?,
+        Commands::Remind { at, days, remove, check } => commands::remind::handle(pool, at, days, remove, check).await?,
+        Commands::Daemon { socket } => commands::daemon::handle(pool, socket, &cfg).await?,
+        Commands::Metrics { listen } => commands::metrics::handle(pool, listen, &cfg).await?,
+        Commands::Token(cmd) => commands::token::handle(cmd, pool, fmt).await?,
+        Commands::Suggest { weeks } => {
+            commands::stall::handle_suggest(pool, weeks.unwrap_or(cfg.stall_weeks()), fmt).await?
+        }
+        Commands::Analyze { by, weeks, tag } => commands::analyze::handle(pool, by, weeks, tag, fmt).await?,
+        Commands::AnalyzeRest { session } => commands::rest_analysis::handle(pool, session, fmt).await?,
+        Commands::BlockStats { program, block } => commands::block_stats::handle(pool, program, block, fmt).await?,
+        Commands::SetWeek { program, week } => commands::week::handle_set_week(pool, program, week).await?,
+        Commands::RecoverSession { session } => commands::recover::handle(pool, session).await?,
+        Commands::FinishProgram { program, archive } => commands::finish::handle(pool, program, archive, fmt).await?,
+        Commands::VolumeDiff { weeks } => commands::volume_diff::handle(pool, weeks, fmt).await?,
+        Commands::ArchiveProgram { program, restore } => commands::program::handle_archive(pool, program, restore).await?,
+        Commands::SetEquip { exercise, note, clear } => commands::equip::handle(pool, exercise, note, clear).await?,
+        Commands::ImportExdb { source, muscle, file } => commands::exdb::handle(pool, source, muscle, file).await?,
+        Commands::ExportExercises { out } => commands::library::handle_export(pool, out).await?,
+        Commands::ImportExercises { file, merge } => commands::library::handle_import(pool, file, merge).await?,
     }
 
     Ok(())
@@ -50,9 +211,22 @@ fn rewrite_args(alias_map: &HashMap<String, Vec<String>>) -> Vec<String> {
 
     rewritten.push(original[0].clone());
 
+    // Aliases only expand where a subcommand is expected, so an exercise or
+    // a note that happens to read "st" is passed through untouched.
+    let root = Cli::command();
+    let mut cmd = Some(root.clone());
     let mut cur_path: Vec<String> = Vec::new();
 
     for arg in original.into_iter().skip(1) {
+        let Some(cur) = cmd.as_ref().filter(|c| c.has_subcommands()) else {
+            rewritten.push(arg);
+            continue;
+        };
+        if arg.starts_with('-') {
+            rewritten.push(arg);
+            continue;
+        }
+
         if let Some(canon) = alias_map.get(&arg) {
             let missing = if canon.starts_with(&cur_path) {
                 &canon[cur_path.len()..]
@@ -61,9 +235,18 @@ fn rewrite_args(alias_map: &HashMap<String, Vec<String>>) -> Vec<String> {
             };
             rewritten.extend(missing.iter().cloned());
             cur_path = canon.clone();
+            cmd = canon
+                .iter()
+                .try_fold(&root, |c, seg| c.find_subcommand(seg))
+                .cloned();
         } else {
-            rewritten.push(arg.clone());
-            cur_path.push(arg);
+            // Track canonical names so `s st` lines up with a `session.start` alias.
+            let next = cur.find_subcommand(&arg).cloned();
+            if let Some(next) = &next {
+                cur_path.push(next.get_name().to_string());
+            }
+            cmd = next;
+            rewritten.push(arg);
         }
     }
 
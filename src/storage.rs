@@ -0,0 +1,121 @@
+//! What commands read from the database, split by area: programs, sessions
+//! and exercises. The SQLite pool [`db::open`](crate::db::open) returns
+//! implements all of them, for a file or an in-memory database alike; tests
+//! and other backends implement just the part a command takes.
+
+use anyhow::Result;
+use serde::Serialize;
+use sqlx::SqlitePool;
+
+/// A session that hasn't ended.
+#[derive(Debug, Clone, Serialize)]
+pub struct OpenSession {
+    pub id: String,
+    pub tag: Option<String>,
+    pub block: String,
+    pub program: String,
+    pub start_time: String,
+    /// Sets logged so far.
+    pub sets: i64,
+}
+
+pub trait ProgramStore {
+    /// The id of the program at `idx` (1-based, ordered by name, as in
+    /// `program list`) or, for anything that isn't a number, named `program`.
+    fn program_id(&self, program: &str) -> impl Future<Output = Result<Option<String>>> + Send;
+}
+
+pub trait SessionStore {
+    /// Sessions that haven't ended, oldest first.
+    fn open_sessions(&self) -> impl Future<Output = Result<Vec<OpenSession>>> + Send;
+}
+
+pub trait ExerciseStore {
+    /// `(id, name)` of the exercise with the global index `exercise` or, for
+    /// anything that isn't a number, named `exercise`.
+    fn exercise(&self, exercise: &str) -> impl Future<Output = Result<Option<(String, String)>>> + Send;
+}
+
+/// Everything a backend provides.
+pub trait Storage: ProgramStore + SessionStore + ExerciseStore {}
+
+impl<T: ProgramStore + SessionStore + ExerciseStore> Storage for T {}
+
+// The pool `db::open` returns is a complete backend.
+const _: fn() = || {
+    fn backend<S: Storage>() {}
+    backend::<SqlitePool>();
+};
+
+impl ProgramStore for SqlitePool {
+    async fn program_id(&self, program: &str) -> Result<Option<String>> {
+        Ok(match program.parse::<i64>() {
+            Ok(idx) => {
+                sqlx::query_scalar(
+                    r#"
+                    SELECT id
+                    FROM (
+                      SELECT id, ROW_NUMBER() OVER (ORDER BY name) AS rn
+                      FROM programs
+                    ) t
+                    WHERE t.rn = ?
+                    "#,
+                )
+                .bind(idx)
+                .fetch_optional(self)
+                .await?
+            }
+            Err(_) => {
+                sqlx::query_scalar("SELECT id FROM programs WHERE name = ?")
+                    .bind(program)
+                    .fetch_optional(self)
+                    .await?
+            }
+        })
+    }
+}
+
+impl SessionStore for SqlitePool {
+    async fn open_sessions(&self) -> Result<Vec<OpenSession>> {
+        let rows: Vec<(String, Option<String>, String, String, String, i64)> = sqlx::query_as(
+            r#"
+            SELECT ts.id, ts.tag, pb.name, p.name, ts.start_time,
+                   CAST((SELECT COUNT(*)
+                         FROM exercise_sets es
+                         JOIN training_session_exercises tse ON tse.id = es.session_exercise_id
+                         WHERE tse.training_session_id = ts.id) AS INTEGER)
+            FROM training_sessions ts
+            JOIN program_blocks pb ON pb.id = ts.program_block_id
+            JOIN programs p ON p.id = pb.program_id
+            WHERE ts.end_time IS NULL
+            ORDER BY ts.start_time
+            "#,
+        )
+        .fetch_all(self)
+        .await?;
+
+        Ok(rows
+            .into_iter()
+            .map(|(id, tag, block, program, start_time, sets)| OpenSession { id, tag, block, program, start_time, sets })
+            .collect())
+    }
+}
+
+impl ExerciseStore for SqlitePool {
+    async fn exercise(&self, exercise: &str) -> Result<Option<(String, String)>> {
+        Ok(match exercise.parse::<i64>() {
+            Ok(idx) => {
+                sqlx::query_as("SELECT id, name FROM exercises WHERE idx = ?")
+                    .bind(idx)
+                    .fetch_optional(self)
+                    .await?
+            }
+            Err(_) => {
+                sqlx::query_as("SELECT id, name FROM exercises WHERE name = ?")
+                    .bind(exercise)
+                    .fetch_optional(self)
+                    .await?
+            }
+        })
+    }
+}
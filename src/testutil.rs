@@ -0,0 +1,143 @@
+//! Helpers for tests: a migrated in-memory database, fixture builders and
+//! golden-file comparison.
+
+use std::{fs, path::PathBuf};
+
+use sqlx::SqlitePool;
+
+use crate::db::{Backend, open};
+
+/// A fresh, fully migrated in-memory database.
+pub async fn memory_db() -> SqlitePool {
+    open(&Backend::Memory).await.expect("in-memory database")
+}
+
+fn new_id() -> String {
+    uuid::Uuid::new_v4().to_string()
+}
+
+/// An exercise named `name`; returns its id.
+pub async fn exercise(pool: &SqlitePool, name: &str, muscle: &str) -> String {
+    let id = new_id();
+    sqlx::query("INSERT INTO exercises (id, name, primary_muscle, created_at) VALUES (?, ?, ?, datetime('now'))")
+        .bind(&id)
+        .bind(name)
+        .bind(muscle)
+        .execute(pool)
+        .await
+        .expect("insert exercise");
+    id
+}
+
+/// A program with one block prescribing 3×`reps` of each exercise, in
+/// order; returns the block id.
+pub async fn program_block(pool: &SqlitePool, program: &str, block: &str, exercise_ids: &[&str], reps: &str) -> String {
+    let program_id: String = match sqlx::query_scalar("SELECT id FROM programs WHERE name = ?")
+        .bind(program)
+        .fetch_optional(pool)
+        .await
+        .expect("look up program")
+    {
+        Some(id) => id,
+        None => {
+            let id = new_id();
+            sqlx::query("INSERT INTO programs (id, name, created_at) VALUES (?, ?, datetime('now'))")
+                .bind(&id)
+                .bind(program)
+                .execute(pool)
+                .await
+                .expect("insert program");
+            id
+        }
+    };
+
+    let block_id = new_id();
+    sqlx::query("INSERT INTO program_blocks (id, program_id, name) VALUES (?, ?, ?)")
+        .bind(&block_id)
+        .bind(&program_id)
+        .bind(block)
+        .execute(pool)
+        .await
+        .expect("insert block");
+    for (i, exercise_id) in exercise_ids.iter().enumerate() {
+        sqlx::query(
+            "INSERT INTO program_exercises (id, program_block_id, exercise_id, sets, reps, order_index) VALUES (?, ?, ?, 3, ?, ?)",
+        )
+        .bind(new_id())
+        .bind(&block_id)
+        .bind(*exercise_id)
+        .bind(reps)
+        .bind(i as i32)
+        .execute(pool)
+        .await
+        .expect("insert program exercise");
+    }
+    block_id
+}
+
+/// A session of `block` started at `start` ("YYYY-MM-DD HH:MM:SS", UTC), left
+/// open when `end` is `None`; returns its id.
+pub async fn session(pool: &SqlitePool, block_id: &str, start: &str, end: Option<&str>) -> String {
+    let id = new_id();
+    sqlx::query("INSERT INTO training_sessions (id, program_block_id, start_time, end_time) VALUES (?, ?, ?, ?)")
+        .bind(&id)
+        .bind(block_id)
+        .bind(start)
+        .bind(end)
+        .execute(pool)
+        .await
+        .expect("insert session");
+    id
+}
+
+/// `exercise_id` added to a session, after the ones already there; returns
+/// the session exercise id.
+pub async fn session_exercise(pool: &SqlitePool, session_id: &str, exercise_id: &str) -> String {
+    let id = new_id();
+    sqlx::query("INSERT INTO training_session_exercises (id, training_session_id, exercise_id) VALUES (?, ?, ?)")
+        .bind(&id)
+        .bind(session_id)
+        .bind(exercise_id)
+        .execute(pool)
+        .await
+        .expect("insert session exercise");
+    id
+}
+
+/// A set logged at `timestamp`, with `status` "completed", "failed" or
+/// "skipped"; returns its id.
+pub async fn set(pool: &SqlitePool, session_exercise_id: &str, weight: f32, reps: i32, status: &str, timestamp: &str) -> String {
+    let id = new_id();
+    sqlx::query(
+        "INSERT INTO exercise_sets (id, session_exercise_id, weight, reps, status, timestamp) VALUES (?, ?, ?, ?, ?, ?)",
+    )
+    .bind(&id)
+    .bind(session_exercise_id)
+    .bind(weight)
+    .bind(reps)
+    .bind(status)
+    .bind(timestamp)
+    .execute(pool)
+    .await
+    .expect("insert set");
+    id
+}
+
+/// A scratch file path under the system temp dir, unique to this call.
+pub fn temp_path(name: &str) -> PathBuf {
+    std::env::temp_dir().join(format!("lazarus-test-{}-{}", new_id(), name))
+}
+
+/// Compare `actual` with `testdata/golden/<name>`. Run with `UPDATE_GOLDEN=1`
+/// to write the file instead.
+pub fn assert_golden(name: &str, actual: &str) {
+    let path = PathBuf::from(env!("CARGO_MANIFEST_DIR")).join("testdata").join("golden").join(name);
+    if std::env::var_os("UPDATE_GOLDEN").is_some() {
+        fs::create_dir_all(path.parent().expect("golden dir")).expect("create golden dir");
+        fs::write(&path, actual).expect("write golden file");
+        return;
+    }
+    let expected = fs::read_to_string(&path)
+        .unwrap_or_else(|e| panic!("{}: {} (run with UPDATE_GOLDEN=1 to create it)", path.display(), e));
+    assert_eq!(actual, expected, "output differs from {}", path.display());
+}
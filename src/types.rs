@@ -12,7 +12,10 @@ use clap::{Command, CommandFactory, ValueEnum};
 use serde::{Deserialize, Serialize};
 use sqlx::prelude::Type;
 
-use crate::cli::Cli;
+use crate::{
+    cli::Cli,
+    i18n::{kg, kg_places, lb},
+};
 
 #[derive(Clone, Debug, ValueEnum, Serialize, Deserialize, Type)]
 #[sqlx(type_name = "TEXT")]
@@ -83,6 +86,65 @@ pub fn cannonical_muscle<S: AsRef<str>>(m: S) -> Option<String> {
     }
 }
 
+/// Equipment kinds an exercise can need; a gym profile says which it has.
+pub const EQUIPMENT: &[&str] = &[
+    "barbell",
+    "dumbbell",
+    "machine",
+    "cable",
+    "kettlebell",
+    "bodyweight",
+    "band",
+];
+
+/// Gear a set can be flagged with, in the order they're stored.
+pub const SET_FLAGS: &[&str] = &["belt", "straps", "sleeves", "wraps"];
+
+/// SQL condition: the set in `flags_col` was (or with `worn = false`, wasn't)
+/// done with `flag`.
+pub fn set_flag_sql(flags_col: &str, flag: &str, worn: bool) -> String {
+    format!(
+        "(',' || COALESCE({}, '') || ',') {} '%,{},%'",
+        flags_col,
+        if worn { "LIKE" } else { "NOT LIKE" },
+        flag
+    )
+}
+
+/// Best guess at the equipment from the exercise name, for exercises
+/// imported without an explicit `equipment`.
+pub fn guess_equipment(name: &str) -> Option<&'static str> {
+    let n = name.to_ascii_lowercase();
+    let table: &[(&str, &str)] = &[
+        ("dumbbell", "dumbbell"),
+        ("db ", "dumbbell"),
+        ("kettlebell", "kettlebell"),
+        ("cable", "cable"),
+        ("pulldown", "cable"),
+        ("machine", "machine"),
+        ("smith", "machine"),
+        ("leg press", "machine"),
+        ("leg extension", "machine"),
+        ("leg curl", "machine"),
+        ("band", "band"),
+        ("push-up", "bodyweight"),
+        ("pull-up", "bodyweight"),
+        ("chin-up", "bodyweight"),
+        ("dip", "bodyweight"),
+        ("barbell", "barbell"),
+        ("bench press", "barbell"),
+        ("squat", "barbell"),
+        ("deadlift", "barbell"),
+        ("overhead press", "barbell"),
+        ("row", "barbell"),
+    ];
+
+    table
+        .iter()
+        .find(|(needle, _)| n.contains(needle))
+        .map(|(_, kind)| *kind)
+}
+
 /// Return the closest allowed muscle for `input`
 /// if similarity ≥ 0.85 *and* clearly better than the runner-up.
 /// Otherwise return `None` (no suggestion shown).
@@ -127,6 +189,11 @@ pub struct ExerciseDef {
     pub name: String,
     pub description: Option<String>,
     pub primary_muscle: String,
+    #[serde(default)]
+    pub unilateral: bool,
+    pub equipment: Option<String>,
+    /// Rounding profile for calculated weights, e.g. "1kg" or "lb".
+    pub rounding: Option<String>,
 }
 
 #[derive(Deserialize)]
@@ -134,7 +201,14 @@ pub struct ExerciseImport {
     pub exercise: Vec<ExerciseDef>,
 }
 
-#[derive(Debug, Default)]
+/// Short aliases available without any config, as (alias, command path).
+pub const DEFAULT_ALIASES: &[(&str, &str)] = &[
+    ("ss", "show.session"),
+    ("es", "session.edit"),
+    ("st", "status"),
+];
+
+#[derive(Debug, Default, Clone)]
 pub struct Config {
     pub map: HashMap<String, String>,
 }
@@ -175,8 +249,12 @@ impl Config {
 
     /// Returns a map from alias - cannonical path segments.
     /// e.g. "st" -> ["session", "start"].
+    /// Starts from `DEFAULT_ALIASES`; an `aliases.*` key naming the same alias wins.
     pub fn aliases(&self) -> HashMap<String, Vec<String>> {
-        let mut m = HashMap::new();
+        let mut m: HashMap<String, Vec<String>> = DEFAULT_ALIASES
+            .iter()
+            .map(|(alias, path)| (alias.to_string(), path.split('.').map(String::from).collect()))
+            .collect();
         for (k, v) in &self.map {
             if let Some(rest) = k.strip_prefix("aliases.") {
                 // 'rest' is like "session" or "session.start".
@@ -192,6 +270,25 @@ impl Config {
     pub fn validate_key(&self, key: &str) -> bool {
         match key {
             "json" => true,
+            "accommodating" => true,
+            "imbalance_threshold" => true,
+            "color" => true,
+            "theme" => true,
+            "locale" => true,
+            "rounding" => true,
+            "database" => true,
+            "remote" => true,
+            "remote_token" => true,
+            "bodyweight" => true,
+            "sex" => true,
+            "dates" => true,
+            "stall_weeks" => true,
+            "e1rm_formula" => true,
+            "decimal_separator" => true,
+            "weight_decimals" => true,
+            "lb_decimals" => true,
+            _ if key.starts_with("rounding.") => EQUIPMENT.contains(&&key["rounding.".len()..]),
+            _ if key.starts_with("theme.") => crate::ui::ROLES.contains(&&key["theme.".len()..]),
             _ if key.starts_with("aliases.") => {
                 let rest = match key.strip_prefix("aliases.") {
                     Some(r) => r,
@@ -224,6 +321,351 @@ impl Config {
     pub fn json_default(&self) -> bool {
         matches!(self.map.get("json").map(|v| v.as_str()), Some("true" | "1"))
     }
+
+    /// `accommodating = exclude | adjust` (defaults to exclude).
+    pub fn accommodating(&self) -> Accommodating {
+        match self.map.get("accommodating").map(|v| v.as_str()) {
+            Some("adjust") => Accommodating::Adjust,
+            _ => Accommodating::Exclude,
+        }
+    }
+
+    /// `imbalance_threshold = <percent>`: left/right gap flagged in `ex show` (default 10).
+    pub fn imbalance_threshold(&self) -> f32 {
+        self.map
+            .get("imbalance_threshold")
+            .and_then(|v| v.parse().ok())
+            .unwrap_or(10.0)
+    }
+
+    /// `stall_weeks = <n>`: weeks without an e1RM PR before a lift counts as stalled (default 6).
+    pub fn stall_weeks(&self) -> u32 {
+        self.map
+            .get("stall_weeks")
+            .and_then(|v| v.parse().ok())
+            .filter(|w| *w > 0)
+            .unwrap_or(6)
+    }
+
+    /// `rounding = <profile>` and `rounding.<equipment> = <profile>`; see [`Rounding::parse`].
+    pub fn rounding(&self) -> RoundingRules {
+        let mut rules = RoundingRules::default();
+        for (k, v) in &self.map {
+            let Some(r) = Rounding::parse(v) else { continue };
+            if k == "rounding" {
+                rules.default = Some(r);
+            } else if let Some(eq) = k.strip_prefix("rounding.") {
+                rules.by_equipment.insert(eq.to_string(), r);
+            }
+        }
+        rules
+    }
+
+    /// `database = <path> | :memory:` (default `./lazarus.db`).
+    pub fn database(&self) -> String {
+        self.map
+            .get("database")
+            .cloned()
+            .unwrap_or_else(|| "./lazarus.db".to_string())
+    }
+
+    /// `remote = http://desktop:9104`: another machine's `metrics` server that
+    /// session commands run on, so its open session can be carried on here.
+    pub fn remote(&self) -> Option<String> {
+        self.map.get("remote").map(|v| v.trim().to_string()).filter(|v| !v.is_empty())
+    }
+
+    /// `remote_token = <token>`: a write token created on the `remote`.
+    pub fn remote_token(&self) -> Option<String> {
+        self.map.get("remote_token").cloned()
+    }
+
+    /// `checklist = belt, straps, pre-workout`: items shown at `session start`
+    /// to tick off with `--check`.
+    pub fn checklist(&self) -> Vec<String> {
+        self.map
+            .get("checklist")
+            .map(|v| v.split(',').map(|i| i.trim().to_string()).filter(|i| !i.is_empty()).collect())
+            .unwrap_or_default()
+    }
+
+    /// `autofill_1rm = true`: `p import` and `session start` fill in missing
+    /// `program_1rm` values as if given `--autofill-1rm`.
+    pub fn autofill_1rm(&self) -> bool {
+        matches!(self.map.get("autofill_1rm").map(|v| v.as_str()), Some("true" | "1"))
+    }
+
+    /// `bodyweight = <kg>`, used to scale strength standards and points.
+    pub fn bodyweight(&self) -> Option<f32> {
+        self.map
+            .get("bodyweight")
+            .and_then(|v| v.trim_end_matches("kg").trim().parse().ok())
+            .filter(|bw: &f32| *bw > 0.0)
+    }
+
+    /// `sex = male | female`; standards and points formulas differ by sex.
+    pub fn sex(&self) -> Option<Sex> {
+        match self.map.get("sex").map(|v| v.to_lowercase()).as_deref() {
+            Some("male" | "m") => Some(Sex::Male),
+            Some("female" | "f") => Some(Sex::Female),
+            _ => None,
+        }
+    }
+}
+
+#[derive(Clone, Copy, Debug, PartialEq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum Sex {
+    Male,
+    Female,
+}
+
+/// How PR/e1RM tracking treats sets done with bands or chains.
+#[derive(Clone, Copy, PartialEq)]
+pub enum Accommodating {
+    /// Leave them out of PRs entirely.
+    Exclude,
+    /// Count half of the band/chain load, roughly its average over the lift.
+    Adjust,
+}
+
+impl Accommodating {
+    /// Load used for e1RM, or `None` if the set should not count.
+    pub fn one_rm_weight(self, bar: f32, extra: f32) -> Option<f32> {
+        match (self, extra > 0.0) {
+            (_, false) => Some(bar),
+            (Self::Exclude, true) => None,
+            (Self::Adjust, true) => Some(bar + extra / 2.0),
+        }
+    }
+}
+
+/// How a calculated weight is rounded to something that can be loaded.
+#[derive(Clone, Copy, Debug, PartialEq)]
+pub enum Rounding {
+    /// Nearest multiple of this many kg.
+    Kg(f32),
+    /// Nearest multiple of this many lb (plates in pounds), still reported in kg.
+    Lb(f32),
+}
+
+const LB_IN_KG: f32 = 0.453_592_37;
+
+impl Rounding {
+    /// `barbell` (2.5kg), `dumbbell` (1kg pairs), `lb` (5lb), or a step like `2`, `1.25kg`, `10lb`.
+    pub fn parse(s: &str) -> Option<Self> {
+        let s = s.trim().to_ascii_lowercase();
+        let r = match s.as_str() {
+            "barbell" => Self::Kg(2.5),
+            "dumbbell" => Self::Kg(1.0),
+            "lb" => Self::Lb(5.0),
+            _ => match s.strip_suffix("lb") {
+                Some(n) => Self::Lb(n.trim().parse().ok()?),
+                None => Self::Kg(s.trim_end_matches("kg").trim().parse().ok()?),
+            },
+        };
+        match r {
+            Self::Kg(step) | Self::Lb(step) if step > 0.0 => Some(r),
+            _ => None,
+        }
+    }
+
+    /// Built-in profile for a kind of equipment.
+    pub fn for_equipment(equipment: Option<&str>) -> Self {
+        match equipment {
+            Some("dumbbell") => Self::Kg(1.0),
+            _ => Self::Kg(2.5),
+        }
+    }
+
+    pub fn round(self, kg: f32) -> f32 {
+        match self {
+            Self::Kg(step) => (kg / step).round() * step,
+            Self::Lb(step) => (kg / LB_IN_KG / step).round() * step * LB_IN_KG,
+        }
+    }
+
+    /// Round and format, e.g. "82.5kg" or "83.9kg (185lb)".
+    pub fn format(self, weight: f32) -> String {
+        let w = self.round(weight);
+        match self {
+            Self::Kg(_) => format!("{}kg", kg(w)),
+            Self::Lb(_) => format!("{}kg ({}lb)", kg_places(w, 1), lb((w / LB_IN_KG).round())),
+        }
+    }
+}
+
+impl Display for Rounding {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            Self::Kg(step) => write!(f, "{}kg", step),
+            Self::Lb(step) => write!(f, "{}lb", step),
+        }
+    }
+}
+
+/// How a logged set went. Sets that aren't logged yet have no row at all.
+#[derive(Clone, Copy, Debug, Default, PartialEq, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum SetStatus {
+    #[default]
+    Completed,
+    /// Attempted but short of the target; the reps logged are the ones done.
+    Failed,
+    /// Not attempted, logged as 0 × 0.
+    Skipped,
+}
+
+impl SetStatus {
+    /// From the `exercise_sets.status` column; anything unknown counts as completed.
+    pub fn parse(s: &str) -> Self {
+        match s {
+            "failed" => Self::Failed,
+            "skipped" => Self::Skipped,
+            _ => Self::Completed,
+        }
+    }
+
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::Completed => "completed",
+            Self::Failed => "failed",
+            Self::Skipped => "skipped",
+        }
+    }
+}
+
+/// A structured per-set rep target from a program.
+#[derive(Clone, Copy, Debug, PartialEq)]
+pub enum RepTarget {
+    /// "5"
+    Exact(u32),
+    /// "8-12"
+    Range(u32, u32),
+    /// "5+": at least this many, then as many as possible.
+    AtLeast(u32),
+    /// "3x3 cluster": mini-sets with short rests, logged as one set.
+    Cluster { clusters: u32, reps: u32 },
+    /// "30s", "2m", "1m30s": a hold or timed set, in seconds.
+    Time(u32),
+}
+
+impl RepTarget {
+    /// `5`, `8-12`, `5+`, `3x3 cluster` (or `3x3`), `30s`, `2m`, `1m30s`.
+    pub fn parse(s: &str) -> Option<Self> {
+        let s = s.trim().to_lowercase();
+        let num = |n: &str| n.trim().parse::<u32>().ok().filter(|&n| n > 0);
+
+        if let Some(n) = s.strip_suffix('+') {
+            return num(n).map(Self::AtLeast);
+        }
+        let cluster = s.strip_suffix("cluster").unwrap_or(&s).trim();
+        if let Some((c, r)) = cluster.split_once(['x', '×']) {
+            return Some(Self::Cluster { clusters: num(c)?, reps: num(r)? });
+        }
+        if s.ends_with('s') || s.ends_with('m') {
+            let (mins, rest) = match s.split_once('m') {
+                Some((m, rest)) => (num(m)?, rest.trim()),
+                None => (0, s.as_str()),
+            };
+            let secs = match rest.strip_suffix('s') {
+                Some(secs) => secs.trim().parse::<u32>().ok()?,
+                None if rest.is_empty() => 0,
+                None => return None,
+            };
+            return Some(Self::Time(mins * 60 + secs)).filter(|t| *t != Self::Time(0));
+        }
+        if let Some((lo, hi)) = s.split_once(['-', '–']) {
+            let (lo, hi) = (num(lo)?, num(hi)?);
+            return (lo < hi).then_some(Self::Range(lo, hi));
+        }
+        num(&s).map(Self::Exact)
+    }
+
+    /// Fewest reps that meet the target; a cluster counts all its reps.
+    /// Timed sets have none.
+    pub fn min_reps(self) -> Option<u32> {
+        match self {
+            Self::Exact(n) | Self::Range(n, _) | Self::AtLeast(n) => Some(n),
+            Self::Cluster { clusters, reps } => Some(clusters * reps),
+            Self::Time(_) => None,
+        }
+    }
+
+    pub fn is_amrap(self) -> bool {
+        matches!(self, Self::AtLeast(_))
+    }
+
+    /// The same target with its last set taken to failure: "5" and "5-8" become "5+".
+    pub fn amrap(self) -> Self {
+        match self {
+            Self::Exact(n) | Self::Range(n, _) => Self::AtLeast(n),
+            other => other,
+        }
+    }
+
+    /// How the target reads next to a set, e.g. "8-12 reps" or "5+ reps AMRAP".
+    pub fn label(self) -> String {
+        match self {
+            Self::Exact(_) | Self::Range(..) => format!("{} reps", self),
+            Self::AtLeast(_) => format!("{} reps AMRAP", self),
+            Self::Cluster { clusters, reps } => format!("{}×{} cluster", clusters, reps),
+            Self::Time(_) => format!("{} hold", self),
+        }
+    }
+}
+
+impl Display for RepTarget {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match *self {
+            Self::Exact(n) => write!(f, "{}", n),
+            Self::Range(lo, hi) => write!(f, "{}-{}", lo, hi),
+            Self::AtLeast(n) => write!(f, "{}+", n),
+            Self::Cluster { clusters, reps } => write!(f, "{}x{} cluster", clusters, reps),
+            Self::Time(secs) if secs % 60 == 0 => write!(f, "{}m", secs / 60),
+            Self::Time(secs) => write!(f, "{}s", secs),
+        }
+    }
+}
+
+/// Rounding from config: a global profile and per-equipment overrides.
+/// An exercise's own override beats both.
+#[derive(Clone, Debug, Default)]
+pub struct RoundingRules {
+    default: Option<Rounding>,
+    by_equipment: HashMap<String, Rounding>,
+}
+
+impl RoundingRules {
+    pub fn pick(&self, exercise: Option<&str>, equipment: Option<&str>) -> Rounding {
+        exercise
+            .and_then(Rounding::parse)
+            .or_else(|| equipment.and_then(|e| self.by_equipment.get(e).copied()))
+            .or(self.default)
+            .unwrap_or_else(|| Rounding::for_equipment(equipment))
+    }
+}
+
+/// Load of a set in SQL over `exercise_sets es`: a bodyweight set counts the
+/// bodyweight it was done at plus the added weight.
+pub const SET_LOAD: &str = "(es.weight + CASE WHEN es.bodyweight = 1 THEN COALESCE(es.body_mass, 0) ELSE 0 END)";
+
+/// How a bodyweight set reads: "bw × 8", or "bw+20kg × 5" with added weight.
+pub fn bodyweight_label(added: f32, reps: i32) -> String {
+    if added > 0.0 { format!("bw+{}kg × {}", kg(added), reps) } else { format!("bw × {}", reps) }
+}
+
+/// Rough lockout tension (kg) of common band colors; used when only the color is given.
+pub fn band_tension_kg(band: &str) -> Option<f32> {
+    match band.to_ascii_lowercase().as_str() {
+        "orange" | "micro" => Some(5.0),
+        "red" | "mini" => Some(15.0),
+        "blue" | "monster-mini" => Some(20.0),
+        "green" | "light" => Some(30.0),
+        "black" | "average" => Some(40.0),
+        "purple" | "strong" => Some(55.0),
+        _ => None,
+    }
 }
 
 /// How the user wants to see stuff.
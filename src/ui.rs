@@ -0,0 +1,282 @@
+//! Terminal styling. Commands color text through the roles of [`Themed`]
+//! instead of picking colors themselves, so a theme (or `--no-color`) applies
+//! everywhere at once.
+
+use std::{cell::RefCell, fmt::Display, sync::OnceLock};
+
+use colored::{Color, ColoredString, Colorize};
+
+use crate::{i18n::tr, types::Config};
+
+/// Colors for each role.
+#[derive(Clone, Copy)]
+pub struct Theme {
+    good: Color,
+    bad: Color,
+    accent: Color,
+    heading: Color,
+    info: Color,
+    highlight: Color,
+}
+
+impl Theme {
+    /// The original palette, made for dark terminals.
+    const DARK: Theme = Theme {
+        good: Color::Green,
+        bad: Color::Red,
+        accent: Color::Yellow,
+        heading: Color::Cyan,
+        info: Color::Blue,
+        highlight: Color::Magenta,
+    };
+
+    /// Yellow and cyan are unreadable on a white background.
+    const LIGHT: Theme = Theme {
+        good: Color::Green,
+        bad: Color::Red,
+        accent: Color::Magenta,
+        heading: Color::Blue,
+        info: Color::Blue,
+        highlight: Color::Magenta,
+    };
+
+    /// `theme = dark | light`, then any `theme.<role> = <color>` overrides.
+    fn from_config(cfg: &Config) -> Theme {
+        let mut theme = match cfg.map.get("theme").map(|v| v.as_str()) {
+            Some("light") => Theme::LIGHT,
+            _ => Theme::DARK,
+        };
+
+        for (key, value) in &cfg.map {
+            let Some(role) = key.strip_prefix("theme.") else { continue };
+            let Ok(color) = value.parse::<Color>() else { continue };
+            match role {
+                "good" => theme.good = color,
+                "bad" => theme.bad = color,
+                "accent" => theme.accent = color,
+                "heading" => theme.heading = color,
+                "info" => theme.info = color,
+                "highlight" => theme.highlight = color,
+                _ => {}
+            }
+        }
+
+        theme
+    }
+}
+
+/// Roles that can be overridden with `theme.<role>`.
+pub const ROLES: &[&str] = &["good", "bad", "accent", "heading", "info", "highlight"];
+
+static THEME: OnceLock<Theme> = OnceLock::new();
+static QUIET: OnceLock<bool> = OnceLock::new();
+
+fn theme() -> &'static Theme {
+    THEME.get_or_init(|| Theme::DARK)
+}
+
+/// Pick the theme from the config and decide whether to color at all.
+/// Color is off with `--no-color`, `color = false` or `NO_COLOR` set.
+pub fn init(cfg: &Config, no_color: bool, quiet: bool) {
+    let _ = THEME.set(Theme::from_config(cfg));
+    let _ = QUIET.set(quiet);
+
+    let off_in_config = matches!(cfg.map.get("color").map(|v| v.as_str()), Some("false" | "0"));
+    let off_in_env = std::env::var_os("NO_COLOR").is_some_and(|v| !v.is_empty());
+    if no_color || off_in_config || off_in_env {
+        colored::control::set_override(false);
+    }
+}
+
+/// `--quiet` was passed.
+pub fn quiet() -> bool {
+    QUIET.get().copied().unwrap_or(false)
+}
+
+tokio::task_local! {
+    static CAPTURED: RefCell<String>;
+}
+
+/// Write a line to stdout, or to the buffer of the [`capture`] it runs under.
+/// Use it through [`outln!`].
+pub fn out(line: std::fmt::Arguments) {
+    let captured = CAPTURED.try_with(|buf| {
+        let mut buf = buf.borrow_mut();
+        buf.push_str(&strip_colors(&line.to_string()));
+        buf.push('\n');
+    });
+    if captured.is_err() {
+        println!("{}", line);
+    }
+}
+
+/// `println!` for output that [`capture`] can collect: what session commands
+/// print when the daemon runs them.
+macro_rules! outln {
+    () => {
+        $crate::ui::out(format_args!(""))
+    };
+    ($($arg:tt)*) => {
+        $crate::ui::out(format_args!($($arg)*))
+    };
+}
+pub(crate) use outln;
+
+/// Run `fut`, collecting what it prints through [`outln!`], [`ok`] and
+/// [`info`] as plain text instead of writing it to stdout.
+pub async fn capture<F: Future>(fut: F) -> (F::Output, String) {
+    CAPTURED
+        .scope(RefCell::new(String::new()), async {
+            let value = fut.await;
+            (value, CAPTURED.with(|buf| buf.take()))
+        })
+        .await
+}
+
+/// `s` without ANSI color codes.
+fn strip_colors(s: &str) -> String {
+    let mut out = String::with_capacity(s.len());
+    let mut chars = s.chars();
+    while let Some(c) = chars.next() {
+        if c == '\x1b' {
+            // CSI: `ESC [` parameters, then a final byte in `@`..=`~`.
+            for c in chars.by_ref() {
+                if ('@'..='~').contains(&c) && c != '[' {
+                    break;
+                }
+            }
+        } else {
+            out.push(c);
+        }
+    }
+    out
+}
+
+/// `ok: <msg>`, a confirmation scripts can do without; skipped with `--quiet`.
+pub fn ok(msg: impl Display) {
+    if !quiet() {
+        outln!("{} {}", tr("ok:").good().bold(), msg);
+    }
+}
+
+/// `info: <msg>`; skipped with `--quiet`.
+pub fn info(msg: impl Display) {
+    if !quiet() {
+        outln!("{} {}", tr("info:").info().bold(), msg);
+    }
+}
+
+/// Semantic colors; use these instead of `Colorize`'s named colors.
+pub trait Themed: Colorize + Sized {
+    /// Success, improvements, `ok:`.
+    fn good(self) -> ColoredString {
+        self.color(theme().good)
+    }
+
+    /// Failures, regressions, `error:`.
+    fn bad(self) -> ColoredString {
+        self.color(theme().bad)
+    }
+
+    /// Indices, numbers worth a glance, `warning:`.
+    fn accent(self) -> ColoredString {
+        self.color(theme().accent)
+    }
+
+    /// Section titles.
+    fn heading(self) -> ColoredString {
+        self.color(theme().heading)
+    }
+
+    /// `info:` and inline labels.
+    fn info(self) -> ColoredString {
+        self.color(theme().info)
+    }
+
+    /// Suggestions and things that stand out.
+    fn highlight(self) -> ColoredString {
+        self.color(theme().highlight)
+    }
+}
+
+impl Themed for &str {}
+impl Themed for ColoredString {}
+
+/// `**bold**`, `*italic*`/`_italic_` and `` `code` `` within a line.
+fn inline_markdown(line: &str) -> String {
+    let mut out = String::new();
+    let mut rest = line;
+    let mut prev = ' ';
+    while !rest.is_empty() {
+        // `_` inside a word (snake_case) is not emphasis
+        let marker = ["**", "`", "*", "_"]
+            .into_iter()
+            .find(|m| rest.starts_with(m))
+            .filter(|m| *m != "_" || !prev.is_alphanumeric());
+        let span = marker.and_then(|m| {
+            let inner = &rest[m.len()..];
+            let end = inner.find(m).filter(|&e| e > 0)?;
+            Some((m, &inner[..end], m.len() * 2 + end))
+        });
+        match span {
+            Some((m, text, len)) => {
+                let styled = match m {
+                    "**" => text.bold(),
+                    "`" => text.highlight(),
+                    _ => text.italic(),
+                };
+                out.push_str(&styled.to_string());
+                rest = &rest[len..];
+                prev = ' ';
+            }
+            None => {
+                let ch = rest.chars().next().unwrap_or_default();
+                out.push(ch);
+                rest = &rest[ch.len_utf8()..];
+                prev = ch;
+            }
+        }
+    }
+    out
+}
+
+/// Render basic Markdown from program notes for the terminal, each line
+/// indented by `indent` spaces: `#` headings, `-`/`*`/`+` and numbered lists
+/// (nested by two spaces), `>` quotes, blank-line paragraphs and inline emphasis.
+pub fn markdown(text: &str, indent: usize) -> String {
+    let pad = " ".repeat(indent);
+    let mut lines = Vec::new();
+    let mut blank = false;
+
+    for raw in text.lines() {
+        let line = raw.trim_end();
+        let trimmed = line.trim_start();
+        if trimmed.is_empty() {
+            blank = !lines.is_empty();
+            continue;
+        }
+        if blank {
+            lines.push(String::new());
+            blank = false;
+        }
+
+        let nest = "  ".repeat((line.len() - trimmed.len()) / 2);
+        let numbered = trimmed
+            .split_once(". ")
+            .filter(|(n, _)| !n.is_empty() && n.chars().all(|c| c.is_ascii_digit()));
+        let rendered = if let Some(heading) = trimmed.strip_prefix('#') {
+            heading.trim_start_matches('#').trim().heading().bold().to_string()
+        } else if let Some(item) = ["- ", "* ", "+ "].iter().find_map(|b| trimmed.strip_prefix(b)) {
+            format!("{}{} {}", nest, "•".accent(), inline_markdown(item))
+        } else if let Some((n, item)) = numbered {
+            format!("{}{} {}", nest, format!("{}.", n).accent(), inline_markdown(item))
+        } else if let Some(quote) = trimmed.strip_prefix('>') {
+            format!("{} {}", "│".dimmed(), inline_markdown(quote.trim_start()).italic())
+        } else {
+            inline_markdown(trimmed)
+        };
+        lines.push(format!("{}{}", pad, rendered));
+    }
+
+    lines.join("\n")
+}